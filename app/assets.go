@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"gopkg.in/yaml.v3"
 )
 
 var (
@@ -15,22 +17,67 @@ var (
 	appManifest []byte
 )
 
+// Settings override the defaults of the migration app manifest. Zero values are left to the foundation's defaults.
+type Settings struct {
+	MemoryMB int
+	DiskMB   int
+	Stack    string
+}
+
 type Extractor struct{}
 
 func NewExtractor() Extractor {
 	return Extractor{}
 }
 
-func (Extractor) Unpack(directoryPath string) error {
+func (Extractor) Unpack(directoryPath string, settings Settings) error {
 	appPath := filepath.Join(directoryPath, "migration-app.zip")
 	if err := os.WriteFile(appPath, migrationApp, 0o444); err != nil {
 		return fmt.Errorf("failed to extract migration-app.zip to %q: %w", appPath, err)
 	}
 
+	manifest, err := Manifest(settings)
+	if err != nil {
+		return err
+	}
+
 	manifestPath := filepath.Join(directoryPath, "manifest.yml")
-	if err := os.WriteFile(manifestPath, appManifest, 0444); err != nil {
+	if err := os.WriteFile(manifestPath, manifest, 0444); err != nil {
 		return fmt.Errorf("failed to extract migration app manifest.yml to %q: %w", manifestPath, err)
 	}
 
 	return nil
 }
+
+// Manifest returns the migration app manifest with settings applied to it
+func Manifest(settings Settings) ([]byte, error) {
+	if settings == (Settings{}) {
+		return appManifest, nil
+	}
+
+	var manifest struct {
+		Applications []map[string]interface{} `yaml:"applications"`
+	}
+	if err := yaml.Unmarshal(appManifest, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse the migration app manifest: %w", err)
+	}
+
+	for _, application := range manifest.Applications {
+		if settings.MemoryMB > 0 {
+			application["memory"] = fmt.Sprintf("%dM", settings.MemoryMB)
+		}
+		if settings.DiskMB > 0 {
+			application["disk_quota"] = fmt.Sprintf("%dM", settings.DiskMB)
+		}
+		if settings.Stack != "" {
+			application["stack"] = settings.Stack
+		}
+	}
+
+	contents, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write the migration app manifest: %w", err)
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,48 @@
+package app_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+)
+
+var _ = Describe("Manifest", func() {
+	It("keeps the foundation's defaults without settings", func() {
+		manifest, err := app.Manifest(app.Settings{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(manifest)).NotTo(ContainSubstring("memory"))
+		Expect(string(manifest)).NotTo(ContainSubstring("stack"))
+	})
+
+	It("sets the memory, disk and stack of the migration app", func() {
+		manifest, err := app.Manifest(app.Settings{MemoryMB: 2048, DiskMB: 4096, Stack: "cflinuxfs4"})
+		Expect(err).NotTo(HaveOccurred())
+
+		var parsed struct {
+			Applications []map[string]interface{} `yaml:"applications"`
+		}
+		Expect(yaml.Unmarshal(manifest, &parsed)).To(Succeed())
+		Expect(parsed.Applications).To(HaveLen(1))
+		Expect(parsed.Applications[0]).To(SatisfyAll(
+			HaveKeyWithValue("memory", "2048M"),
+			HaveKeyWithValue("disk_quota", "4096M"),
+			HaveKeyWithValue("stack", "cflinuxfs4"),
+			HaveKeyWithValue("buildpack", "binary_buildpack"),
+			HaveKeyWithValue("command", "/bin/sleep infinity"),
+			HaveKeyWithValue("no-route", true),
+		))
+	})
+
+	It("is written next to the migration app when unpacking it", func() {
+		dir := GinkgoT().TempDir()
+		Expect(app.NewExtractor().Unpack(dir, app.Settings{MemoryMB: 512})).To(Succeed())
+
+		Expect(os.ReadFile(filepath.Join(dir, "manifest.yml"))).To(ContainSubstring("memory: 512M"))
+		Expect(filepath.Join(dir, "migration-app.zip")).To(BeAnExistingFile())
+	})
+})
@@ -19,6 +19,7 @@ import (
 	cliplugin "code.cloudfoundry.org/cli/plugin"
 
 	"github.com/pivotal-cf/mysql-cli-plugin/app"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/multisite"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin"
 )
@@ -26,6 +27,8 @@ import (
 func main() {
 	mysqlPlugin := &plugin.MySQLPlugin{
 		MigrationAppExtractor: app.NewExtractor(),
+		MigrationStateStore:   migrate.NewFileStateStore(),
+		CutoverStore:          migrate.NewFileCutoverStore(),
 		MultisiteConfig:       multisite.NewConfig(),
 	}
 
@@ -0,0 +1,80 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// sharedIsolationSegment is where apps run when neither their space nor its org is assigned an isolation segment
+const sharedIsolationSegment = "shared"
+
+type isolationSegmentRelationship struct {
+	Data *struct {
+		Guid string `json:"guid"`
+	} `json:"data"`
+}
+
+// StackExists reports whether apps can be pushed with the named stack
+func (c *MigratorClient) StackExists(name string) (bool, error) {
+	var stacks struct {
+		Resources []struct {
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := c.curl("/v3/stacks?"+url.Values{"names": {name}}.Encode(), &stacks); err != nil {
+		return false, err
+	}
+
+	return len(stacks.Resources) > 0, nil
+}
+
+// IsolationSegment returns the name of the isolation segment apps of the current space run in: the one assigned to
+// the space, or else the default isolation segment of its org
+func (c *MigratorClient) IsolationSegment() (string, error) {
+	space, err := c.pluginAPI.GetCurrentSpace()
+	if err != nil {
+		return "", fmt.Errorf("failed to lookup current space: %w", err)
+	}
+
+	var segment isolationSegmentRelationship
+	if err := c.curl("/v3/spaces/"+space.Guid+"/relationships/isolation_segment", &segment); err != nil {
+		return "", err
+	}
+
+	if segment.Data == nil {
+		var spaceInfo quotaRelationship
+		if err := c.curl("/v3/spaces/"+space.Guid, &spaceInfo); err != nil {
+			return "", err
+		}
+
+		orgGuid := spaceInfo.Relationships.Organization.Data.Guid
+		if err := c.curl("/v3/organizations/"+orgGuid+"/relationships/default_isolation_segment", &segment); err != nil {
+			return "", err
+		}
+	}
+
+	if segment.Data == nil {
+		return sharedIsolationSegment, nil
+	}
+
+	var isolationSegment struct {
+		Name string `json:"name"`
+	}
+	if err := c.curl("/v3/isolation_segments/"+segment.Data.Guid, &isolationSegment); err != nil {
+		return "", err
+	}
+
+	return isolationSegment.Name, nil
+}
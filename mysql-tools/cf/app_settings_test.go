@@ -0,0 +1,94 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf_test
+
+import (
+	"errors"
+	"strings"
+
+	"code.cloudfoundry.org/cli/plugin/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf/cffakes"
+)
+
+var _ = Describe("MigratorClient app settings", func() {
+	var (
+		client          *cf.MigratorClient
+		fakeCFPluginAPI *cffakes.FakeCFPluginAPI
+		responses       map[string]string
+	)
+
+	BeforeEach(func() {
+		fakeCFPluginAPI = new(cffakes.FakeCFPluginAPI)
+		client = cf.NewMigratorClient(fakeCFPluginAPI)
+		client.Log.SetOutput(GinkgoWriter)
+
+		fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{
+			SpaceFields: plugin_models.SpaceFields{Guid: "space-guid"},
+		}, nil)
+
+		responses = map[string]string{}
+		fakeCFPluginAPI.CliCommandWithoutTerminalOutputStub = func(args ...string) ([]string, error) {
+			response, ok := responses[strings.Join(args, " ")]
+			if !ok {
+				return nil, errors.New("unexpected command: " + strings.Join(args, " "))
+			}
+			return strings.Split(response, "\n"), nil
+		}
+	})
+
+	Context("StackExists", func() {
+		It("looks up the stack by name", func() {
+			responses["curl /v3/stacks?names=cflinuxfs4"] = `{"resources": [{"name": "cflinuxfs4"}]}`
+			responses["curl /v3/stacks?names=windows"] = `{"resources": []}`
+
+			Expect(client.StackExists("cflinuxfs4")).To(BeTrue())
+			Expect(client.StackExists("windows")).To(BeFalse())
+		})
+	})
+
+	Context("IsolationSegment", func() {
+		BeforeEach(func() {
+			responses["curl /v3/spaces/space-guid/relationships/isolation_segment"] = `{"data": null}`
+			responses["curl /v3/spaces/space-guid"] = `{"relationships": {"organization": {"data": {"guid": "org-guid"}}}}`
+			responses["curl /v3/organizations/org-guid/relationships/default_isolation_segment"] = `{"data": null}`
+			responses["curl /v3/isolation_segments/segment-guid"] = `{"guid": "segment-guid", "name": "data"}`
+		})
+
+		It("returns the isolation segment of the space", func() {
+			responses["curl /v3/spaces/space-guid/relationships/isolation_segment"] = `{"data": {"guid": "segment-guid"}}`
+
+			Expect(client.IsolationSegment()).To(Equal("data"))
+		})
+
+		It("falls back to the default isolation segment of the org", func() {
+			responses["curl /v3/organizations/org-guid/relationships/default_isolation_segment"] = `{"data": {"guid": "segment-guid"}}`
+
+			Expect(client.IsolationSegment()).To(Equal("data"))
+		})
+
+		It("returns the shared isolation segment otherwise", func() {
+			Expect(client.IsolationSegment()).To(Equal("shared"))
+		})
+
+		It("returns an error when the isolation segment can not be looked up", func() {
+			delete(responses, "curl /v3/spaces/space-guid/relationships/isolation_segment")
+
+			_, err := client.IsolationSegment()
+			Expect(err).To(MatchError(ContainSubstring("failed to request /v3/spaces/space-guid/relationships/isolation_segment")))
+		})
+	})
+})
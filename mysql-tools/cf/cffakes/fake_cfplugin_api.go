@@ -21,6 +21,18 @@ type FakeCFPluginAPI struct {
 		result1 string
 		result2 error
 	}
+	ApiEndpointStub        func() (string, error)
+	apiEndpointMutex       sync.RWMutex
+	apiEndpointArgsForCall []struct {
+	}
+	apiEndpointReturns struct {
+		result1 string
+		result2 error
+	}
+	apiEndpointReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	CliCommandStub        func(...string) ([]string, error)
 	cliCommandMutex       sync.RWMutex
 	cliCommandArgsForCall []struct {
@@ -132,6 +144,62 @@ func (fake *FakeCFPluginAPI) AccessTokenReturnsOnCall(i int, result1 string, res
 	}{result1, result2}
 }
 
+func (fake *FakeCFPluginAPI) ApiEndpoint() (string, error) {
+	fake.apiEndpointMutex.Lock()
+	ret, specificReturn := fake.apiEndpointReturnsOnCall[len(fake.apiEndpointArgsForCall)]
+	fake.apiEndpointArgsForCall = append(fake.apiEndpointArgsForCall, struct {
+	}{})
+	stub := fake.ApiEndpointStub
+	fakeReturns := fake.apiEndpointReturns
+	fake.recordInvocation("ApiEndpoint", []interface{}{})
+	fake.apiEndpointMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCFPluginAPI) ApiEndpointCallCount() int {
+	fake.apiEndpointMutex.RLock()
+	defer fake.apiEndpointMutex.RUnlock()
+	return len(fake.apiEndpointArgsForCall)
+}
+
+func (fake *FakeCFPluginAPI) ApiEndpointCalls(stub func() (string, error)) {
+	fake.apiEndpointMutex.Lock()
+	defer fake.apiEndpointMutex.Unlock()
+	fake.ApiEndpointStub = stub
+}
+
+func (fake *FakeCFPluginAPI) ApiEndpointReturns(result1 string, result2 error) {
+	fake.apiEndpointMutex.Lock()
+	defer fake.apiEndpointMutex.Unlock()
+	fake.ApiEndpointStub = nil
+	fake.apiEndpointReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCFPluginAPI) ApiEndpointReturnsOnCall(i int, result1 string, result2 error) {
+	fake.apiEndpointMutex.Lock()
+	defer fake.apiEndpointMutex.Unlock()
+	fake.ApiEndpointStub = nil
+	if fake.apiEndpointReturnsOnCall == nil {
+		fake.apiEndpointReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.apiEndpointReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeCFPluginAPI) CliCommand(arg1 ...string) ([]string, error) {
 	fake.cliCommandMutex.Lock()
 	ret, specificReturn := fake.cliCommandReturnsOnCall[len(fake.cliCommandArgsForCall)]
@@ -385,6 +453,8 @@ func (fake *FakeCFPluginAPI) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.accessTokenMutex.RLock()
 	defer fake.accessTokenMutex.RUnlock()
+	fake.apiEndpointMutex.RLock()
+	defer fake.apiEndpointMutex.RUnlock()
 	fake.cliCommandMutex.RLock()
 	defer fake.cliCommandMutex.RUnlock()
 	fake.cliCommandWithoutTerminalOutputMutex.RLock()
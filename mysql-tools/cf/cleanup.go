@@ -0,0 +1,152 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+// ListApps lists the apps of the current space, with the size of their web process
+func (c *MigratorClient) ListApps() ([]migrate.SpaceApp, error) {
+	space, err := c.pluginAPI.GetCurrentSpace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup current space: %w", err)
+	}
+
+	query := url.Values{
+		"space_guids": {space.Guid},
+		"per_page":    {"5000"},
+	}
+
+	var apps struct {
+		Resources []struct {
+			Guid      string    `json:"guid"`
+			Name      string    `json:"name"`
+			CreatedAt time.Time `json:"created_at"`
+		} `json:"resources"`
+	}
+	if err := c.curl("/v3/apps?"+query.Encode(), &apps); err != nil {
+		return nil, err
+	}
+
+	query.Set("types", "web")
+	var processes struct {
+		Resources []struct {
+			Instances     int `json:"instances"`
+			MemoryInMB    int `json:"memory_in_mb"`
+			Relationships struct {
+				App struct {
+					Data struct {
+						Guid string `json:"guid"`
+					} `json:"data"`
+				} `json:"app"`
+			} `json:"relationships"`
+		} `json:"resources"`
+	}
+	if err := c.curl("/v3/processes?"+query.Encode(), &processes); err != nil {
+		return nil, err
+	}
+
+	result := make([]migrate.SpaceApp, 0, len(apps.Resources))
+	for _, app := range apps.Resources {
+		spaceApp := migrate.SpaceApp{Name: app.Name, CreatedAt: app.CreatedAt}
+		for _, process := range processes.Resources {
+			if process.Relationships.App.Data.Guid == app.Guid {
+				spaceApp.Instances, spaceApp.MemoryMB = process.Instances, process.MemoryInMB
+			}
+		}
+		result = append(result, spaceApp)
+	}
+
+	return result, nil
+}
+
+// ListServiceInstances lists the managed service instances of the current space, with the name of their plan and
+// offering
+func (c *MigratorClient) ListServiceInstances() ([]migrate.SpaceServiceInstance, error) {
+	space, err := c.pluginAPI.GetCurrentSpace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup current space: %w", err)
+	}
+
+	query := url.Values{
+		"space_guids":                           {space.Guid},
+		"type":                                  {"managed"},
+		"fields[service_plan]":                  {"guid,name,relationships.service_offering"},
+		"fields[service_plan.service_offering]": {"guid,name"},
+		"per_page":                              {"5000"},
+	}
+
+	var instances struct {
+		Resources []struct {
+			Name          string    `json:"name"`
+			CreatedAt     time.Time `json:"created_at"`
+			Relationships struct {
+				ServicePlan struct {
+					Data struct {
+						Guid string `json:"guid"`
+					} `json:"data"`
+				} `json:"service_plan"`
+			} `json:"relationships"`
+		} `json:"resources"`
+		Included struct {
+			ServicePlans []struct {
+				Guid          string `json:"guid"`
+				Name          string `json:"name"`
+				Relationships struct {
+					ServiceOffering struct {
+						Data struct {
+							Guid string `json:"guid"`
+						} `json:"data"`
+					} `json:"service_offering"`
+				} `json:"relationships"`
+			} `json:"service_plans"`
+			ServiceOfferings []struct {
+				Guid string `json:"guid"`
+				Name string `json:"name"`
+			} `json:"service_offerings"`
+		} `json:"included"`
+	}
+	if err := c.curl("/v3/service_instances?"+query.Encode(), &instances); err != nil {
+		return nil, err
+	}
+
+	offerings := map[string]string{}
+	for _, offering := range instances.Included.ServiceOfferings {
+		offerings[offering.Guid] = offering.Name
+	}
+
+	plans := map[string]string{}
+	planOfferings := map[string]string{}
+	for _, plan := range instances.Included.ServicePlans {
+		plans[plan.Guid] = plan.Name
+		planOfferings[plan.Guid] = offerings[plan.Relationships.ServiceOffering.Data.Guid]
+	}
+
+	result := make([]migrate.SpaceServiceInstance, 0, len(instances.Resources))
+	for _, instance := range instances.Resources {
+		planGuid := instance.Relationships.ServicePlan.Data.Guid
+		result = append(result, migrate.SpaceServiceInstance{
+			Name:         instance.Name,
+			CreatedAt:    instance.CreatedAt,
+			PlanName:     plans[planGuid],
+			OfferingName: planOfferings[planGuid],
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli/plugin/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf/cffakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+var _ = Describe("MigratorClient cleanup", func() {
+	var (
+		client          *cf.MigratorClient
+		fakeCFPluginAPI *cffakes.FakeCFPluginAPI
+		responses       map[string]string
+	)
+
+	BeforeEach(func() {
+		fakeCFPluginAPI = new(cffakes.FakeCFPluginAPI)
+		client = cf.NewMigratorClient(fakeCFPluginAPI)
+		client.Log.SetOutput(GinkgoWriter)
+
+		fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{
+			SpaceFields: plugin_models.SpaceFields{Guid: "space-guid"},
+		}, nil)
+
+		responses = map[string]string{}
+		fakeCFPluginAPI.CliCommandWithoutTerminalOutputStub = func(args ...string) ([]string, error) {
+			response, ok := responses[strings.Join(args, " ")]
+			if !ok {
+				return nil, errors.New("unexpected command: " + strings.Join(args, " "))
+			}
+			return strings.Split(response, "\n"), nil
+		}
+	})
+
+	Context("ListApps", func() {
+		BeforeEach(func() {
+			responses["curl /v3/apps?per_page=5000&space_guids=space-guid"] = `{"resources": [
+				{"guid": "app-guid-1", "name": "migrate-app-some-guid", "created_at": "2024-01-01T00:00:00Z"},
+				{"guid": "app-guid-2", "name": "some-app", "created_at": "2024-01-02T00:00:00Z"}
+			]}`
+			responses["curl /v3/processes?per_page=5000&space_guids=space-guid&types=web"] = `{"resources": [
+				{"instances": 2, "memory_in_mb": 256, "relationships": {"app": {"data": {"guid": "app-guid-2"}}}},
+				{"instances": 1, "memory_in_mb": 1024, "relationships": {"app": {"data": {"guid": "app-guid-1"}}}}
+			]}`
+		})
+
+		It("returns the apps of the current space with the size of their web process", func() {
+			Expect(client.ListApps()).To(Equal([]migrate.SpaceApp{
+				{Name: "migrate-app-some-guid", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), MemoryMB: 1024, Instances: 1},
+				{Name: "some-app", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), MemoryMB: 256, Instances: 2},
+			}))
+		})
+
+		It("returns an error when the current space can not be looked up", func() {
+			fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{}, errors.New("some-error"))
+
+			_, err := client.ListApps()
+			Expect(err).To(MatchError("failed to lookup current space: some-error"))
+		})
+
+		It("returns an error when the processes can not be retrieved", func() {
+			responses["curl /v3/processes?per_page=5000&space_guids=space-guid&types=web"] = `{"errors": [{"code": 10002, "title": "CF-NotAuthenticated", "detail": "Authentication error"}]}`
+
+			_, err := client.ListApps()
+			Expect(err).To(MatchError("cc error code 10002: CF-NotAuthenticated - Authentication error"))
+		})
+	})
+
+	Context("ListServiceInstances", func() {
+		BeforeEach(func() {
+			responses["curl /v3/service_instances?fields%5Bservice_plan.service_offering%5D=guid%2Cname&fields%5Bservice_plan%5D=guid%2Cname%2Crelationships.service_offering&per_page=5000&space_guids=space-guid&type=managed"] = `{
+				"resources": [
+					{"name": "some-donor-new", "created_at": "2024-01-01T00:00:00Z", "relationships": {"service_plan": {"data": {"guid": "plan-guid"}}}}
+				],
+				"included": {
+					"service_plans": [{"guid": "plan-guid", "name": "db-small", "relationships": {"service_offering": {"data": {"guid": "offering-guid"}}}}],
+					"service_offerings": [{"guid": "offering-guid", "name": "p.mysql"}]
+				}
+			}`
+		})
+
+		It("returns the managed service instances of the current space with their plan and offering", func() {
+			Expect(client.ListServiceInstances()).To(Equal([]migrate.SpaceServiceInstance{
+				{Name: "some-donor-new", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), PlanName: "db-small", OfferingName: "p.mysql"},
+			}))
+		})
+
+		It("returns an error when the service instances can not be retrieved", func() {
+			responses = map[string]string{}
+
+			_, err := client.ListServiceInstances()
+			Expect(err).To(MatchError(ContainSubstring("failed to request /v3/service_instances")))
+		})
+	})
+})
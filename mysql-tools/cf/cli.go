@@ -0,0 +1,229 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	plugin_models "code.cloudfoundry.org/cli/plugin/models"
+)
+
+// CLI implements CFPluginAPI by running the cf binary with its own CF_HOME, so that the migrator client can operate
+// on a space other than the one targeted by the current cf session
+type CLI struct {
+	CFPath string
+	cfHome string
+}
+
+func NewCLI(cfHome string) *CLI {
+	return &CLI{
+		CFPath: "cf",
+		cfHome: cfHome,
+	}
+}
+
+// NewSpaceCLI copies the cf configuration in configFile to a temporary CF_HOME and targets space in it, leaving the
+// configuration it was copied from untouched. Close removes the temporary CF_HOME.
+func NewSpaceCLI(configFile, space string) (*CLI, error) {
+	config, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cf configuration: %w", err)
+	}
+
+	cfHome, err := os.MkdirTemp("", "mysql-tools-cf-home-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cf configuration directory: %w", err)
+	}
+
+	c := NewCLI(cfHome)
+
+	if err := os.Mkdir(filepath.Join(cfHome, ".cf"), 0700); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to create cf configuration directory: %w", err)
+	}
+
+	if err := os.WriteFile(c.configFile(), config, 0600); err != nil {
+		_ = c.Close()
+		return nil, fmt.Errorf("failed to copy cf configuration: %w", err)
+	}
+
+	if err := c.Target(space); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *CLI) Close() error {
+	return os.RemoveAll(c.cfHome)
+}
+
+func (c *CLI) Target(space string) error {
+	if _, err := c.run("target", "-s", space); err != nil {
+		return fmt.Errorf("failed to target space %q: %w", space, err)
+	}
+
+	return nil
+}
+
+func (c *CLI) CliCommand(args ...string) ([]string, error) {
+	output, err := c.run(args...)
+	for _, line := range output {
+		fmt.Println(line)
+	}
+
+	return output, err
+}
+
+func (c *CLI) CliCommandWithoutTerminalOutput(args ...string) ([]string, error) {
+	return c.run(args...)
+}
+
+func (c *CLI) GetCurrentSpace() (plugin_models.Space, error) {
+	config, err := c.readConfig()
+	if err != nil {
+		return plugin_models.Space{}, err
+	}
+
+	if config.SpaceFields.GUID == "" {
+		return plugin_models.Space{}, errors.New("no space targeted")
+	}
+
+	return plugin_models.Space{
+		SpaceFields: plugin_models.SpaceFields{
+			Guid: config.SpaceFields.GUID,
+			Name: config.SpaceFields.Name,
+		},
+	}, nil
+}
+
+func (c *CLI) ApiEndpoint() (string, error) {
+	config, err := c.readConfig()
+	if err != nil {
+		return "", err
+	}
+
+	return config.Target, nil
+}
+
+func (c *CLI) GetService(instanceName string) (plugin_models.GetService_Model, error) {
+	space, err := c.GetCurrentSpace()
+	if err != nil {
+		return plugin_models.GetService_Model{}, err
+	}
+
+	query := url.Values{
+		"names":       {instanceName},
+		"space_guids": {space.Guid},
+	}
+
+	output, err := c.run("curl", "/v3/service_instances?"+query.Encode())
+	if err != nil {
+		return plugin_models.GetService_Model{}, fmt.Errorf("failed to look up service instance %q: %w", instanceName, err)
+	}
+
+	var instances struct {
+		Resources []struct {
+			Guid          string `json:"guid"`
+			Name          string `json:"name"`
+			LastOperation struct {
+				Type        string `json:"type"`
+				State       string `json:"state"`
+				Description string `json:"description"`
+			} `json:"last_operation"`
+		} `json:"resources"`
+	}
+
+	jsonRaw := strings.Join(output, "\n")
+	if err := json.Unmarshal([]byte(jsonRaw), &instances); err != nil {
+		return plugin_models.GetService_Model{}, fmt.Errorf("failed to parse the following api response: %s", jsonRaw)
+	}
+
+	if len(instances.Resources) == 0 {
+		return plugin_models.GetService_Model{}, fmt.Errorf("service instance %q not found", instanceName)
+	}
+
+	instance := instances.Resources[0]
+
+	return plugin_models.GetService_Model{
+		Guid: instance.Guid,
+		Name: instance.Name,
+		LastOperation: plugin_models.GetService_LastOperation{
+			Type:        instance.LastOperation.Type,
+			State:       instance.LastOperation.State,
+			Description: instance.LastOperation.Description,
+		},
+	}, nil
+}
+
+func (c *CLI) AccessToken() (string, error) {
+	output, err := c.run("oauth-token")
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(output, ""), nil
+}
+
+type cliConfig struct {
+	Target      string
+	SpaceFields struct {
+		GUID string
+		Name string
+	}
+}
+
+func (c *CLI) readConfig() (cliConfig, error) {
+	var config cliConfig
+
+	contents, err := os.ReadFile(c.configFile())
+	if err != nil {
+		return cliConfig{}, fmt.Errorf("failed to read cf configuration: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, &config); err != nil {
+		return cliConfig{}, fmt.Errorf("failed to parse cf configuration: %w", err)
+	}
+
+	return config, nil
+}
+
+func (c *CLI) configFile() string {
+	return filepath.Join(c.cfHome, ".cf", "config.json")
+}
+
+func (c *CLI) run(args ...string) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command(c.CFPath, args...)
+	cmd.Env = append(os.Environ(), "CF_HOME="+c.cfHome)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	output := strings.Split(strings.TrimRight(stdout.String(), "\n"), "\n")
+	if err != nil {
+		return output, fmt.Errorf("cf %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()+stdout.String()))
+	}
+
+	return output, nil
+}
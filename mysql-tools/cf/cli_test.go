@@ -0,0 +1,90 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/go-binmock"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
+)
+
+var _ = Describe("CLI", func() {
+	var (
+		cli    *cf.CLI
+		cfHome string
+		cfMock *binmock.Mock
+	)
+
+	BeforeEach(func() {
+		cfHome = GinkgoT().TempDir()
+		Expect(os.Mkdir(filepath.Join(cfHome, ".cf"), 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(cfHome, ".cf", "config.json"),
+			[]byte(`{"Target": "https://api.example.com", "SpaceFields": {"GUID": "space-guid", "Name": "some-space"}}`), 0600)).To(Succeed())
+
+		cfMock = binmock.NewBinMock(Fail)
+		cli = cf.NewCLI(cfHome)
+		cli.CFPath = cfMock.Path
+	})
+
+	It("runs cf commands with its own CF_HOME", func() {
+		cfMock.WhenCalledWith("services").WillPrintToStdOut("line 1\nline 2\n")
+
+		Expect(cli.CliCommandWithoutTerminalOutput("services")).To(Equal([]string{"line 1", "line 2"}))
+		Expect(cfMock.Invocations()[0].Env()).To(HaveKeyWithValue("CF_HOME", cfHome))
+	})
+
+	It("returns the output of failed commands", func() {
+		cfMock.WhenCalledWith("target", "-s", "some-space").
+			WillPrintToStdErr("Space 'some-space' not found.").
+			WillExitWith(1)
+
+		Expect(cli.Target("some-space")).
+			To(MatchError(`failed to target space "some-space": cf target failed: exit status 1: Space 'some-space' not found.`))
+	})
+
+	It("reads the targeted space from its configuration", func() {
+		space, err := cli.GetCurrentSpace()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(space.Guid).To(Equal("space-guid"))
+		Expect(space.Name).To(Equal("some-space"))
+	})
+
+	It("reads the targeted API endpoint from its configuration", func() {
+		Expect(cli.ApiEndpoint()).To(Equal("https://api.example.com"))
+	})
+
+	Context("GetService", func() {
+		It("looks up the service instance in the targeted space", func() {
+			cfMock.WhenCalledWith("curl", "/v3/service_instances?names=some-instance&space_guids=space-guid").
+				WillPrintToStdOut(`{"resources": [{"guid": "instance-guid", "name": "some-instance", "last_operation": {"type": "create", "state": "in progress"}}]}`)
+
+			service, err := cli.GetService("some-instance")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.Guid).To(Equal("instance-guid"))
+			Expect(service.LastOperation.State).To(Equal("in progress"))
+		})
+
+		It("returns an error when the service instance does not exist", func() {
+			cfMock.WhenCalledWith("curl", "/v3/service_instances?names=some-instance&space_guids=space-guid").
+				WillPrintToStdOut(`{"resources": []}`)
+
+			_, err := cli.GetService("some-instance")
+			Expect(err).To(MatchError(`service instance "some-instance" not found`))
+		})
+	})
+})
@@ -105,6 +105,15 @@ func (c *FindBindingsClient) ListServiceInstancesByQuery(query url.Values) ([]cf
 	return c.cfClient.ListServiceInstancesByQuery(query)
 }
 
+func (c *FindBindingsClient) GetServiceInstanceByGuid(guid string) (cfclient.ServiceInstance, error) {
+	err := c.lazyInitializeCFClient()
+	if err != nil {
+		return cfclient.ServiceInstance{}, err
+	}
+
+	return c.cfClient.GetServiceInstanceByGuid(guid)
+}
+
 func (c *FindBindingsClient) lazyInitializeCFClient() error {
 	if c.cfClient != nil {
 		return nil
@@ -13,14 +13,19 @@
 package cf
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
 )
 
 type MigratorClient struct {
@@ -28,6 +33,8 @@ type MigratorClient struct {
 	MaxAttempts int
 	Log         *log.Logger
 	Sleep       SleepFunc
+	// CFPath is the cf binary used for commands whose output the plugin API can not stream
+	CFPath string
 }
 
 func NewMigratorClient(pluginAPI CFPluginAPI) *MigratorClient {
@@ -36,6 +43,7 @@ func NewMigratorClient(pluginAPI CFPluginAPI) *MigratorClient {
 		MaxAttempts: 3,
 		Log:         log.New(os.Stderr, "", log.LstdFlags),
 		Sleep:       time.Sleep,
+		CFPath:      "cf",
 	}
 }
 
@@ -49,7 +57,7 @@ func (c *MigratorClient) BindService(appName, serviceName string) error {
 	return nil
 }
 
-func (c *MigratorClient) CreateServiceInstance(planType, instanceName string) error {
+func (c *MigratorClient) CreateServiceInstance(ctx context.Context, planType, instanceName string, config migrate.ServiceInstanceConfig) error {
 	if _, err := c.pluginAPI.GetService(instanceName); err == nil {
 		return fmt.Errorf("service instance '%s' already exists", instanceName)
 	}
@@ -59,23 +67,47 @@ func (c *MigratorClient) CreateServiceInstance(planType, instanceName string) er
 		productName = "p.mysql"
 	}
 
-	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(
+	args := []string{
 		"create-service",
 		productName,
 		planType,
 		instanceName,
-	); err != nil {
+	}
+
+	if config.Parameters != "" {
+		args = append(args, "-c", config.Parameters)
+	}
+
+	if len(config.Tags) > 0 {
+		args = append(args, "-t", strings.Join(config.Tags, ","))
+	}
+
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(args...); err != nil {
 		return err
 	}
 
-	return c.waitForOperationCompletion("create service instance", instanceName)
+	return c.waitForOperationCompletion(ctx, "create service instance", instanceName)
+}
+
+// WaitForServiceInstance waits until the creation of a service instance that was already requested completes
+func (c *MigratorClient) WaitForServiceInstance(ctx context.Context, instanceName string) error {
+	return c.waitForOperationCompletion(ctx, "create service instance", instanceName)
 }
 
 func (c *MigratorClient) CreateTask(app App, command string) (*Task, error) {
+	body, err := json.Marshal(struct {
+		Command    string `json:"command"`
+		MemoryInMB int    `json:"memory_in_mb,omitempty"`
+		DiskInMB   int    `json:"disk_in_mb,omitempty"`
+	}{command, app.MemoryMB, app.DiskMB})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a task: %w", err)
+	}
+
 	cfArgs := []string{
 		"curl",
 		"-X", "POST",
-		"-d", fmt.Sprintf(`{"command":"%s"}`, command),
+		"-d", string(body),
 		"/v3/apps/" + app.Guid + "/tasks",
 	}
 
@@ -118,6 +150,21 @@ func (c *MigratorClient) DeleteServiceInstance(instanceName string) error {
 	return err
 }
 
+// CurrentSpace returns the targeted API endpoint and space
+func (c *MigratorClient) CurrentSpace() (migrate.Space, error) {
+	apiEndpoint, err := c.pluginAPI.ApiEndpoint()
+	if err != nil {
+		return migrate.Space{}, fmt.Errorf("failed to lookup api endpoint: %w", err)
+	}
+
+	space, err := c.pluginAPI.GetCurrentSpace()
+	if err != nil {
+		return migrate.Space{}, fmt.Errorf("failed to lookup current space: %w", err)
+	}
+
+	return migrate.Space{APIEndpoint: apiEndpoint, GUID: space.Guid}, nil
+}
+
 func (c *MigratorClient) GetAppByName(name string) (App, error) {
 	space, err := c.pluginAPI.GetCurrentSpace()
 	if err != nil {
@@ -193,23 +240,25 @@ func (c *MigratorClient) RenameService(oldName, newName string) error {
 }
 
 func (c *MigratorClient) RunTask(appName, command string) error {
-	app, err := c.GetAppByName(appName)
+	taskGUID, err := c.StartTask(appName, command)
 	if err != nil {
-		return fmt.Errorf("Error: %w", err)
+		return err
 	}
 
-	task, err := c.CreateTask(app, command)
-	if err != nil {
-		return fmt.Errorf("Error: %w", err)
-	}
+	return c.WaitForTask(context.Background(), taskGUID, nil)
+}
 
-	finalState, err := c.waitForTask(task)
-	if err != nil {
-		return fmt.Errorf("Error when waiting for task to complete: %w", err)
-	}
+// RunSSH runs a command in the first instance of an app, reading its input from stdin and writing its output to
+// stdout and stderr. The plugin API only returns output once a command completed, and as lines of text, so the cf
+// binary is run directly.
+func (c *MigratorClient) RunSSH(appName, command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.Command(c.CFPath, "ssh", appName, "--disable-pseudo-tty", "--command", command)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 
-	if finalState != "SUCCEEDED" {
-		return fmt.Errorf("task completed with status %q", finalState)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %q in application %q: %w", command, appName, err)
 	}
 
 	return nil
@@ -220,6 +269,16 @@ func (c *MigratorClient) ServiceExists(serviceName string) bool {
 	return err == nil
 }
 
+func (c *MigratorClient) SetEnv(appName, name, value string) error {
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(
+		"set-env", appName, name, value,
+	); err != nil {
+		return fmt.Errorf("failed to set %s for application %q: %w", name, appName, err)
+	}
+
+	return nil
+}
+
 func (c *MigratorClient) StartApp(appName string) error {
 	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(
 		"start", appName,
@@ -230,6 +289,66 @@ func (c *MigratorClient) StartApp(appName string) error {
 	return nil
 }
 
+func (c *MigratorClient) StartTask(appName, command string) (string, error) {
+	app, err := c.GetAppByName(appName)
+	if err != nil {
+		return "", fmt.Errorf("Error: %w", err)
+	}
+
+	// Tasks get the default memory and disk of the foundation rather than those the app was pushed with
+	var process struct {
+		MemoryInMB int `json:"memory_in_mb"`
+		DiskInMB   int `json:"disk_in_mb"`
+	}
+	if err := c.curl("/v3/apps/"+app.Guid+"/processes/web", &process); err != nil {
+		return "", fmt.Errorf("Error: %w", err)
+	}
+	app.MemoryMB, app.DiskMB = process.MemoryInMB, process.DiskInMB
+
+	task, err := c.CreateTask(app, command)
+	if err != nil {
+		return "", fmt.Errorf("Error: %w", err)
+	}
+
+	return task.Guid, nil
+}
+
+// WaitForTask polls the task until it completed. onPoll, when not nil, is called every time the task was found
+// to still be running. The task is cancelled when ctx is done before it completed.
+func (c *MigratorClient) WaitForTask(ctx context.Context, taskGUID string, onPoll func()) error {
+	finalState, err := c.waitForTask(ctx, &Task{Guid: taskGUID}, onPoll)
+	if err != nil {
+		return fmt.Errorf("Error when waiting for task to complete: %w", err)
+	}
+
+	if finalState != "SUCCEEDED" {
+		return fmt.Errorf("task completed with status %q", finalState)
+	}
+
+	return nil
+}
+
+// cancelTask asks the cloud controller to stop a task, which it does asynchronously
+func (c *MigratorClient) cancelTask(guid string) error {
+	output, err := c.pluginAPI.CliCommandWithoutTerminalOutput("curl", "-X", "POST", "/v3/tasks/"+guid+"/actions/cancel")
+	if err != nil {
+		return err
+	}
+
+	jsonRaw := strings.Join(output, "\n")
+
+	task := Task{}
+	if err := json.Unmarshal([]byte(jsonRaw), &task); err != nil {
+		return fmt.Errorf("failed to parse the following api response: %s", jsonRaw)
+	}
+
+	if len(task.Errors) != 0 {
+		return fmt.Errorf("cc error code %d: %s - %s", task.Errors[0].Code, task.Errors[0].Title, task.Errors[0].Detail)
+	}
+
+	return nil
+}
+
 func (c *MigratorClient) createServiceKey(instanceName, serviceKeyName string) error {
 	_, err := c.pluginAPI.CliCommandWithoutTerminalOutput("create-service-key", instanceName, serviceKeyName)
 	return err
@@ -301,10 +420,14 @@ func (c *MigratorClient) retryCliRequestWithBackoff(requestFunc cliTask, request
 	return nil, errors.New(failureMessage)
 }
 
-func (c *MigratorClient) waitForOperationCompletion(operationName, instanceName string) error {
+func (c *MigratorClient) waitForOperationCompletion(ctx context.Context, operationName, instanceName string) error {
 	attempt := 0
 
 	for {
+		if ctx.Err() != nil {
+			return fmt.Errorf("stopped waiting to %s '%s': %w", operationName, instanceName, context.Cause(ctx))
+		}
+
 		service, err := c.pluginAPI.GetService(instanceName)
 		if err != nil {
 			attempt++
@@ -313,7 +436,7 @@ func (c *MigratorClient) waitForOperationCompletion(operationName, instanceName
 				return fmt.Errorf("failed to look up status of service instance '%s'", instanceName)
 			}
 
-			c.Sleep(time.Second << uint(attempt))
+			c.sleep(ctx, time.Second<<uint(attempt))
 			continue
 		}
 
@@ -326,19 +449,48 @@ func (c *MigratorClient) waitForOperationCompletion(operationName, instanceName
 			return fmt.Errorf("failed to %s '%s': %s",
 				operationName, instanceName, service.LastOperation.Description)
 		case "in progress":
+			c.sleep(ctx, 5*time.Second)
 			continue
 		}
 	}
 }
 
-func (c *MigratorClient) waitForTask(task *Task) (string, error) {
+// sleep is c.Sleep, cut short once ctx is done
+func (c *MigratorClient) sleep(ctx context.Context, d time.Duration) {
+	slept := make(chan struct{})
+	go func() {
+		defer close(slept)
+		c.Sleep(d)
+	}()
+
+	select {
+	case <-slept:
+	case <-ctx.Done():
+	}
+}
+
+func (c *MigratorClient) waitForTask(ctx context.Context, task *Task, onPoll func()) (string, error) {
 	var (
 		taskGUID = task.Guid
 		err      error
 	)
 
 	for task.State != "SUCCEEDED" && task.State != "FAILED" {
+		if onPoll != nil && task.State != "" {
+			onPoll()
+		}
+
 		c.Sleep(time.Second)
+
+		if ctx.Err() != nil {
+			c.Log.Printf("Cancelling task %s", taskGUID)
+			if err := c.cancelTask(taskGUID); err != nil {
+				c.Log.Printf("failed to cancel task %s: %s", taskGUID, err)
+			}
+
+			return "", fmt.Errorf("cancelled task %s: %w", taskGUID, context.Cause(ctx))
+		}
+
 		task, err = c.GetTaskByGUID(taskGUID)
 
 		if err != nil {
@@ -13,28 +13,37 @@
 package cf_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"code.cloudfoundry.org/cli/plugin/models"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/go-binmock"
 
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf/cffakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
 )
 
 type FakeClock struct {
 	sleepCount    int
 	sleepCallArgs []time.Duration
+	onSleep       func()
 }
 
 func (c *FakeClock) Sleep(d time.Duration) {
 	c.sleepCallArgs = append(c.sleepCallArgs, d)
 	c.sleepCount++
+	if c.onSleep != nil {
+		c.onSleep()
+	}
 }
 
 func (c *FakeClock) SleepCallCount() int {
@@ -118,9 +127,32 @@ var _ = Describe("MigratorClient", func() {
 			})
 
 			It("We wait until the service instance has been successfully created", func() {
-				err := client.CreateServiceInstance("plan-type", "service-instance-name")
+				err := client.CreateServiceInstance(context.Background(), "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(4))
+				Expect(fakeClock.SleepCallCount()).To(Equal(2))
+			})
+
+			It("stops waiting once the context is done", func() {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				fakeClock.onSleep = func() { cancel(errors.New("timed out")) }
+
+				err := client.CreateServiceInstance(ctx, "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
+				Expect(err).To(MatchError("stopped waiting to create service instance 'service-instance-name': timed out"))
+				Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(2))
+			})
+
+			It("stops sleeping between polls once the context is done", func() {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				wake := make(chan struct{})
+				DeferCleanup(func() { close(wake) })
+				fakeClock.onSleep = func() {
+					cancel(errors.New("timed out"))
+					<-wake
+				}
+
+				err := client.CreateServiceInstance(ctx, "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
+				Expect(err).To(MatchError("stopped waiting to create service instance 'service-instance-name': timed out"))
 			})
 
 			Context("when the service polling fails continuously", func() {
@@ -130,7 +162,7 @@ var _ = Describe("MigratorClient", func() {
 				})
 
 				It("keeps trying until a timeout is reached", func() {
-					err := client.CreateServiceInstance("plan-type", "service-instance-name")
+					err := client.CreateServiceInstance(context.Background(), "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
 					Expect(err).To(MatchError("failed to look up status of service instance 'service-instance-name'"))
 					Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(6))
 				})
@@ -143,7 +175,7 @@ var _ = Describe("MigratorClient", func() {
 				})
 
 				It("keeps trying until a definitive answer is reached", func() {
-					err := client.CreateServiceInstance("plan-type", "service-instance-name")
+					err := client.CreateServiceInstance(context.Background(), "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(5))
 				})
@@ -162,12 +194,30 @@ var _ = Describe("MigratorClient", func() {
 				})
 
 				It("returns an error", func() {
-					err := client.CreateServiceInstance("plan-type", "service-instance-name")
+					err := client.CreateServiceInstance(context.Background(), "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
 					Expect(err).To(MatchError("failed to create service instance 'service-instance-name': description"))
 					Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(4))
 				})
 			})
 
+			It("passes arbitrary parameters and tags", func() {
+				err := client.CreateServiceInstance(context.Background(), "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{
+					Parameters: `{"enable_lower_case_table_names":true}`,
+					Tags:       []string{"some-tag", "other-tag"},
+				})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).
+					To(Equal([]string{
+						"create-service",
+						"p.mysql",
+						"plan-type",
+						"service-instance-name",
+						"-c", `{"enable_lower_case_table_names":true}`,
+						"-t", "some-tag,other-tag",
+					}))
+			})
+
 			Context("when RECIPIENT_PRODUCT_NAME is set", func() {
 				var originalProductName string
 				BeforeEach(func() {
@@ -176,7 +226,7 @@ var _ = Describe("MigratorClient", func() {
 				})
 
 				It("Uses the product name from RECIPIENT_PRODUCT_NAME when creating the service instance", func() {
-					err := client.CreateServiceInstance("plan-type", "service-instance-name")
+					err := client.CreateServiceInstance(context.Background(), "plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
 
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).
@@ -200,7 +250,7 @@ var _ = Describe("MigratorClient", func() {
 				fakeCFPluginAPI.GetServiceReturns(plugin_models.GetService_Model{}, errors.New("does not exist"))
 				fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns([]string{}, errors.New("Invalid service plan"))
 
-				err := client.CreateServiceInstance("invalid-plan-type", "service-instance-name")
+				err := client.CreateServiceInstance(context.Background(), "invalid-plan-type", "service-instance-name", migrate.ServiceInstanceConfig{})
 				Expect(err).To(MatchError("Invalid service plan"))
 				Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).
 					To(Equal([]string{
@@ -217,7 +267,7 @@ var _ = Describe("MigratorClient", func() {
 			It("Fails", func() {
 				fakeCFPluginAPI.GetServiceReturns(plugin_models.GetService_Model{Guid: "some-guid"}, nil)
 
-				err := client.CreateServiceInstance("plan-type", "preexisting-service-instance-name")
+				err := client.CreateServiceInstance(context.Background(), "plan-type", "preexisting-service-instance-name", migrate.ServiceInstanceConfig{})
 				Expect(err).To(MatchError("service instance 'preexisting-service-instance-name' already exists"))
 				Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(1))
 				Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).To(Equal(0))
@@ -247,6 +297,22 @@ var _ = Describe("MigratorClient", func() {
 		})
 	})
 
+	Context("WaitForServiceInstance", func() {
+		It("waits for a service instance whose creation was already requested", func() {
+			fakeCFPluginAPI.GetServiceReturnsOnCall(0, plugin_models.GetService_Model{
+				LastOperation: plugin_models.GetService_LastOperation{Type: "create", State: "in progress"},
+			}, nil)
+			fakeCFPluginAPI.GetServiceReturnsOnCall(1, plugin_models.GetService_Model{
+				LastOperation: plugin_models.GetService_LastOperation{Type: "create", State: "succeeded"},
+			}, nil)
+
+			Expect(client.WaitForServiceInstance(context.Background(), "service-instance-name")).To(Succeed())
+			Expect(fakeCFPluginAPI.GetServiceCallCount()).To(Equal(2))
+			Expect(fakeCFPluginAPI.GetServiceArgsForCall(0)).To(Equal("service-instance-name"))
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).To(BeZero())
+		})
+	})
+
 	Context("CreateTask", func() {
 		It("creates a task", func() {
 			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns([]string{
@@ -271,6 +337,16 @@ var _ = Describe("MigratorClient", func() {
 			}))
 		})
 
+		It("escapes the command in the request body", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns([]string{`{"guid": "abc-123"}`}, nil)
+
+			_, err := client.CreateTask(cf.App{Guid: "some-app-guid"}, `migrate -exclude-table='app.o'\''brien' "a" b`)
+			Expect(err).NotTo(HaveOccurred())
+
+			args := fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)
+			Expect(args[4]).To(Equal(`{"command":"migrate -exclude-table='app.o'\\''brien' \"a\" b"}`))
+		})
+
 		Context("when there is an error creating the task", func() {
 			It("returns an error", func() {
 				fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(
@@ -380,6 +456,24 @@ var _ = Describe("MigratorClient", func() {
 		})
 	})
 
+	Context("CurrentSpace", func() {
+		It("returns the targeted API endpoint and space", func() {
+			fakeCFPluginAPI.ApiEndpointReturns("https://api.example.com", nil)
+			fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{
+				SpaceFields: plugin_models.SpaceFields{Guid: "some-guid", Name: "some-name"},
+			}, nil)
+
+			Expect(client.CurrentSpace()).To(Equal(migrate.Space{APIEndpoint: "https://api.example.com", GUID: "some-guid"}))
+		})
+
+		It("returns an error when the API endpoint can not be looked up", func() {
+			fakeCFPluginAPI.ApiEndpointReturns("", errors.New("not logged in"))
+
+			_, err := client.CurrentSpace()
+			Expect(err).To(MatchError("failed to lookup api endpoint: not logged in"))
+		})
+	})
+
 	Context("GetAppByName", func() {
 		It("returns an application by its name", func() {
 			fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{
@@ -690,10 +784,13 @@ var _ = Describe("MigratorClient", func() {
 					`"name": "some-app"`,
 					`}]}`,
 				}, nil)
+
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+				[]string{`{"memory_in_mb": 2048, "disk_in_mb": 4096}`}, nil)
 		})
 
 		It("runs a task and waits for it to finish", func() {
-			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
 				[]string{
 					`{`,
 					`"guid": "be5077ed-abba-bea7-deb7-50f7ba110000",`,
@@ -701,7 +798,7 @@ var _ = Describe("MigratorClient", func() {
 					`}`,
 				}, nil)
 
-			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(3,
 				[]string{
 					`{`,
 					`"guid": "be5077ed-abba-bea7-deb7-50f7ba110000",`,
@@ -713,17 +810,17 @@ var _ = Describe("MigratorClient", func() {
 				To(Succeed())
 
 			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).
-				To(Equal(3))
+				To(Equal(4))
 
-			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(1)).
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(2)).
 				To(
 					Equal([]string{
 						"curl", "-X", "POST", "-d",
-						`{"command":"some-command"}`,
+						`{"command":"some-command","memory_in_mb":2048,"disk_in_mb":4096}`,
 						"/v3/apps/be5077ed-abba-bea7-deb7-50f7ba110000/tasks",
 					}))
 
-			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(2)).
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(3)).
 				To(Equal([]string{
 					"curl",
 					"/v3/tasks/be5077ed-abba-bea7-deb7-50f7ba110000",
@@ -740,7 +837,7 @@ var _ = Describe("MigratorClient", func() {
 		})
 
 		It("returns an error when creating a task fails", func() {
-			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
 				nil, errors.New("create task failed"),
 			)
 
@@ -749,7 +846,7 @@ var _ = Describe("MigratorClient", func() {
 		})
 
 		It("returns an error when waiting for a task fails", func() {
-			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
 				[]string{
 					`{`,
 					`"guid": "be5077ed-abba-bea7-deb7-50f7ba110000",`,
@@ -765,7 +862,7 @@ var _ = Describe("MigratorClient", func() {
 		})
 
 		It("returns an error when a tasks finishes with a failed state", func() {
-			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
 				[]string{
 					`{`,
 					`"guid": "be5077ed-abba-bea7-deb7-50f7ba110000",`,
@@ -773,7 +870,7 @@ var _ = Describe("MigratorClient", func() {
 					`}`,
 				}, nil)
 
-			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(3,
 				[]string{
 					`{`,
 					`"guid": "be5077ed-abba-bea7-deb7-50f7ba110000",`,
@@ -786,6 +883,143 @@ var _ = Describe("MigratorClient", func() {
 		})
 	})
 
+	Context("StartTask", func() {
+		BeforeEach(func() {
+			fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{
+				SpaceFields: plugin_models.SpaceFields{
+					Guid: "some-guid",
+					Name: "some-name",
+				},
+			}, nil)
+
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(0,
+				[]string{
+					`{"resources": [{`,
+					`"guid": "be5077ed-abba-bea7-deb7-50f7ba110000",`,
+					`"name": "some-app"`,
+					`}]}`,
+				}, nil)
+
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+				[]string{`{"memory_in_mb": 2048, "disk_in_mb": 4096}`}, nil)
+		})
+
+		It("creates a task and returns its guid without waiting for it", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
+				[]string{
+					`{`,
+					`"guid": "some-task-guid",`,
+					`"state": "RUNNING"`,
+					`}`,
+				}, nil)
+
+			Expect(client.StartTask("some-app", "some-command")).
+				To(Equal("some-task-guid"))
+
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).
+				To(Equal(3))
+		})
+
+		It("returns an error when creating a task fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
+				nil, errors.New("create task failed"),
+			)
+
+			_, err := client.StartTask("some-app", "some-command")
+			Expect(err).To(MatchError(`Error: failed to create a task: create task failed`))
+		})
+
+		It("gives the task the memory and disk of the app's web process", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
+				[]string{`{"guid": "some-task-guid", "state": "RUNNING"}`}, nil)
+
+			Expect(client.StartTask("some-app", "some-command")).To(Equal("some-task-guid"))
+
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(1)).
+				To(Equal([]string{"curl", "/v3/apps/be5077ed-abba-bea7-deb7-50f7ba110000/processes/web"}))
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(2)[4]).
+				To(Equal(`{"command":"some-command","memory_in_mb":2048,"disk_in_mb":4096}`))
+		})
+
+		It("returns an error when looking up the app's web process fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+				nil, errors.New("process not found"),
+			)
+
+			_, err := client.StartTask("some-app", "some-command")
+			Expect(err).To(MatchError(`Error: failed to request /v3/apps/be5077ed-abba-bea7-deb7-50f7ba110000/processes/web: process not found`))
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("WaitForTask", func() {
+		It("polls the task until it succeeds", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(0,
+				[]string{`{"guid": "some-task-guid", "state": "RUNNING"}`}, nil)
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+				[]string{`{"guid": "some-task-guid", "state": "SUCCEEDED"}`}, nil)
+
+			Expect(client.WaitForTask(context.Background(), "some-task-guid", nil)).To(Succeed())
+
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).
+				To(Equal(2))
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).
+				To(Equal([]string{"curl", "/v3/tasks/some-task-guid"}))
+		})
+
+		It("calls onPoll while the task is running", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(0,
+				[]string{`{"guid": "some-task-guid", "state": "PENDING"}`}, nil)
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(1,
+				[]string{`{"guid": "some-task-guid", "state": "RUNNING"}`}, nil)
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(2,
+				[]string{`{"guid": "some-task-guid", "state": "SUCCEEDED"}`}, nil)
+
+			var polls []int
+			onPoll := func() {
+				polls = append(polls, fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount())
+			}
+
+			Expect(client.WaitForTask(context.Background(), "some-task-guid", onPoll)).To(Succeed())
+			Expect(polls).To(Equal([]int{1, 2}))
+		})
+
+		It("returns an error when the task failed", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(
+				[]string{`{"guid": "some-task-guid", "state": "FAILED"}`}, nil)
+
+			err := client.WaitForTask(context.Background(), "some-task-guid", nil)
+			Expect(err).To(MatchError(`task completed with status "FAILED"`))
+		})
+
+		It("cancels the task once the context is done", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(
+				[]string{`{"guid": "some-task-guid", "state": "RUNNING"}`}, nil)
+
+			ctx, cancel := context.WithCancelCause(context.Background())
+			onPoll := func() { cancel(errors.New("interrupted")) }
+
+			err := client.WaitForTask(ctx, "some-task-guid", onPoll)
+			Expect(err).To(MatchError("Error when waiting for task to complete: cancelled task some-task-guid: interrupted"))
+
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).To(Equal(2))
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(1)).
+				To(Equal([]string{"curl", "-X", "POST", "/v3/tasks/some-task-guid/actions/cancel"}))
+		})
+
+		It("still returns the reason for stopping when the task can not be cancelled", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturnsOnCall(0,
+				[]string{`{"errors": [{"code": 10008, "title": "CF-UnprocessableEntity", "detail": "Task state is SUCCEEDED"}]}`}, nil)
+
+			ctx, cancel := context.WithCancelCause(context.Background())
+			cancel(errors.New("interrupted"))
+
+			err := client.WaitForTask(ctx, "some-task-guid", nil)
+			Expect(err).To(MatchError(ContainSubstring("cancelled task some-task-guid: interrupted")))
+			Expect(buffer).To(gbytes.Say("failed to cancel task some-task-guid: cc error code 10008"))
+		})
+	})
+
 	Context("ServiceExists", func() {
 		Context("When the service does not exist", func() {
 			It("Returns false", func() {
@@ -806,6 +1040,63 @@ var _ = Describe("MigratorClient", func() {
 		})
 	})
 
+	Context("SetEnv", func() {
+		It("sets an environment variable on an application", func() {
+			Expect(client.SetEnv("some-app", "SOME_NAME", "some-value")).
+				To(Succeed())
+
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputCallCount()).
+				To(Equal(1))
+
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).
+				To(Equal([]string{"set-env", "some-app", "SOME_NAME", "some-value"}))
+		})
+
+		It("returns an error without the value when setting the variable fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(
+				nil, errors.New("some-error"),
+			)
+
+			err := client.SetEnv("some-app", "SOME_NAME", "some-value")
+			Expect(err).To(MatchError(`failed to set SOME_NAME for application "some-app": some-error`))
+		})
+	})
+
+	Context("RunSSH", func() {
+		var (
+			cfMock         *binmock.Mock
+			stdout, stderr *bytes.Buffer
+		)
+
+		BeforeEach(func() {
+			cfMock = binmock.NewBinMock(Fail)
+			client.CFPath = cfMock.Path
+			stdout, stderr = &bytes.Buffer{}, &bytes.Buffer{}
+		})
+
+		It("runs the command in the application and streams its output", func() {
+			cfMock.WhenCalledWith("ssh", "some-app", "--disable-pseudo-tty", "--command", "some command").
+				WillPrintToStdOut("some-output").
+				WillPrintToStdErr("some-progress").
+				WillExitWith(0)
+
+			Expect(client.RunSSH("some-app", "some command", strings.NewReader("some-input"), stdout, stderr)).
+				To(Succeed())
+
+			Expect(cfMock.Invocations()).To(HaveLen(1))
+			Expect(cfMock.Invocations()[0].Stdin()).To(Equal([]string{"some-input"}))
+			Expect(stdout.String()).To(ContainSubstring("some-output"))
+			Expect(stderr.String()).To(ContainSubstring("some-progress"))
+		})
+
+		It("returns an error when the command fails", func() {
+			cfMock.WhenCalled().WillExitWith(1)
+
+			err := client.RunSSH("some-app", "some command", nil, stdout, stderr)
+			Expect(err).To(MatchError(`failed to run "some command" in application "some-app": exit status 1`))
+		})
+	})
+
 	Context("StartApp", func() {
 		It("starts an application", func() {
 			Expect(client.StartApp("some-app")).
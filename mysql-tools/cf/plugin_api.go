@@ -25,6 +25,7 @@ type CFPluginAPI interface {
 	CliCommand(...string) ([]string, error)
 	CliCommandWithoutTerminalOutput(args ...string) ([]string, error)
 	GetCurrentSpace() (plugin_models.Space, error)
+	ApiEndpoint() (string, error)
 	GetService(string) (plugin_models.GetService_Model, error)
 	AccessToken() (string, error)
 }
@@ -46,6 +47,9 @@ type Task struct {
 type App struct {
 	Name string
 	Guid string
+	// MemoryMB and DiskMB size the tasks of the app. Zero leaves them to the defaults of the foundation.
+	MemoryMB int `json:"-"`
+	DiskMB   int `json:"-"`
 }
 
 type cliTask func(string) (*Task, error)
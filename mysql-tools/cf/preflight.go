@@ -0,0 +1,295 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+type quotaLimits struct {
+	Apps struct {
+		TotalMemoryInMB      *int `json:"total_memory_in_mb"`
+		PerProcessMemoryInMB *int `json:"per_process_memory_in_mb"`
+		TotalInstances       *int `json:"total_instances"`
+	} `json:"apps"`
+	Services struct {
+		TotalServiceInstances *int `json:"total_service_instances"`
+	} `json:"services"`
+}
+
+type usageSummary struct {
+	UsageSummary struct {
+		StartedInstances int `json:"started_instances"`
+		MemoryInMB       int `json:"memory_in_mb"`
+	} `json:"usage_summary"`
+}
+
+type quotaRelationship struct {
+	Relationships struct {
+		Organization struct {
+			Data struct {
+				Guid string `json:"guid"`
+			} `json:"data"`
+		} `json:"organization"`
+		Quota struct {
+			Data *struct {
+				Guid string `json:"guid"`
+			} `json:"data"`
+		} `json:"quota"`
+	} `json:"relationships"`
+}
+
+func (c *MigratorClient) CreateServiceKey(instanceName, keyName string) (migrate.ServiceCredentials, error) {
+	if err := c.createServiceKey(instanceName, keyName); err != nil {
+		return migrate.ServiceCredentials{}, fmt.Errorf("failed to create service key %q: %w", keyName, err)
+	}
+
+	jsonRaw, err := c.serviceKey(instanceName, keyName)
+	if err != nil {
+		return migrate.ServiceCredentials{}, fmt.Errorf("failed to retrieve service key %q: %w", keyName, err)
+	}
+
+	type credentials struct {
+		Hostname string `json:"hostname"`
+		Name     string `json:"name"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Port     int    `json:"port"`
+		TLS      struct {
+			Cert struct {
+				CA string `json:"ca"`
+			} `json:"cert"`
+		} `json:"tls"`
+	}
+
+	// Newer cf CLI versions nest the credentials under a "credentials" key
+	var key struct {
+		credentials
+		Credentials *credentials `json:"credentials"`
+	}
+	if err := json.Unmarshal([]byte(jsonRaw), &key); err != nil {
+		return migrate.ServiceCredentials{}, fmt.Errorf("failed to parse service key %q: %w", keyName, err)
+	}
+
+	creds := key.credentials
+	if key.Credentials != nil {
+		creds = *key.Credentials
+	}
+
+	return migrate.ServiceCredentials{
+		Hostname: creds.Hostname,
+		Name:     creds.Name,
+		Username: creds.Username,
+		Password: creds.Password,
+		Port:     creds.Port,
+		CA:       creds.TLS.Cert.CA,
+	}, nil
+}
+
+// CreateUserProvidedService creates a service instance in the current space exposing the credentials of a service
+// instance in another space, in the format MySQL service bindings use
+func (c *MigratorClient) CreateUserProvidedService(instanceName string, credentials migrate.ServiceCredentials) error {
+	type cert struct {
+		CA string `json:"ca"`
+	}
+	type tls struct {
+		Cert cert `json:"cert"`
+	}
+
+	body, err := json.Marshal(struct {
+		Hostname string `json:"hostname"`
+		Name     string `json:"name"`
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Port     int    `json:"port"`
+		TLS      tls    `json:"tls"`
+	}{
+		Hostname: credentials.Hostname,
+		Name:     credentials.Name,
+		Username: credentials.Username,
+		Password: credentials.Password,
+		Port:     credentials.Port,
+		TLS:      tls{Cert: cert{CA: credentials.CA}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode the credentials of service instance %q: %w", instanceName, err)
+	}
+
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(
+		"create-user-provided-service", instanceName, "-p", string(body),
+	); err != nil {
+		return fmt.Errorf("failed to create user provided service %q: %w", instanceName, err)
+	}
+
+	return nil
+}
+
+func (c *MigratorClient) DeleteServiceKey(instanceName, keyName string) error {
+	if err := c.deleteServiceKey(instanceName, keyName); err != nil {
+		return fmt.Errorf("failed to delete service key %q: %w", keyName, err)
+	}
+
+	return nil
+}
+
+func (c *MigratorClient) ServicePlanExists(productName, planName string) (bool, error) {
+	space, err := c.pluginAPI.GetCurrentSpace()
+	if err != nil {
+		return false, fmt.Errorf("failed to lookup current space: %w", err)
+	}
+
+	query := url.Values{
+		"names":                  {planName},
+		"service_offering_names": {productName},
+		"space_guids":            {space.Guid},
+	}
+
+	var plans struct {
+		Resources []struct {
+			Guid string `json:"guid"`
+		} `json:"resources"`
+	}
+	if err := c.curl("/v3/service_plans?"+query.Encode(), &plans); err != nil {
+		return false, err
+	}
+
+	return len(plans.Resources) > 0, nil
+}
+
+// RemainingQuota returns how much of the org and space quotas can still be used in the current space,
+// whichever is lower
+func (c *MigratorClient) RemainingQuota() (migrate.Quota, error) {
+	space, err := c.pluginAPI.GetCurrentSpace()
+	if err != nil {
+		return migrate.Quota{}, fmt.Errorf("failed to lookup current space: %w", err)
+	}
+
+	var spaceInfo quotaRelationship
+	if err := c.curl("/v3/spaces/"+space.Guid, &spaceInfo); err != nil {
+		return migrate.Quota{}, err
+	}
+	orgGuid := spaceInfo.Relationships.Organization.Data.Guid
+
+	var orgInfo quotaRelationship
+	if err := c.curl("/v3/organizations/"+orgGuid, &orgInfo); err != nil {
+		return migrate.Quota{}, err
+	}
+
+	quota := migrate.Quota{
+		ServiceInstances: migrate.Unlimited,
+		AppInstances:     migrate.Unlimited,
+		MemoryMB:         migrate.Unlimited,
+		ProcessMemoryMB:  migrate.Unlimited,
+	}
+
+	if orgInfo.Relationships.Quota.Data != nil {
+		if err := c.applyQuota(&quota,
+			"/v3/organization_quotas/"+orgInfo.Relationships.Quota.Data.Guid,
+			"/v3/organizations/"+orgGuid+"/usage_summary",
+			url.Values{"organization_guids": {orgGuid}},
+		); err != nil {
+			return migrate.Quota{}, err
+		}
+	}
+
+	if spaceInfo.Relationships.Quota.Data != nil {
+		if err := c.applyQuota(&quota,
+			"/v3/space_quotas/"+spaceInfo.Relationships.Quota.Data.Guid,
+			"/v3/spaces/"+space.Guid+"/usage_summary",
+			url.Values{"space_guids": {space.Guid}},
+		); err != nil {
+			return migrate.Quota{}, err
+		}
+	}
+
+	return quota, nil
+}
+
+func (c *MigratorClient) applyQuota(quota *migrate.Quota, limitsPath, usagePath string, serviceInstanceQuery url.Values) error {
+	var limits quotaLimits
+	if err := c.curl(limitsPath, &limits); err != nil {
+		return err
+	}
+
+	var usage usageSummary
+	if err := c.curl(usagePath, &usage); err != nil {
+		return err
+	}
+
+	// Only managed service instances count against a quota
+	serviceInstanceQuery.Set("type", "managed")
+	serviceInstanceQuery.Set("per_page", "1")
+	var serviceInstances struct {
+		Pagination struct {
+			TotalResults int `json:"total_results"`
+		} `json:"pagination"`
+	}
+	if err := c.curl("/v3/service_instances?"+serviceInstanceQuery.Encode(), &serviceInstances); err != nil {
+		return err
+	}
+
+	quota.ServiceInstances = lowerRemaining(quota.ServiceInstances, limits.Services.TotalServiceInstances, serviceInstances.Pagination.TotalResults)
+	quota.AppInstances = lowerRemaining(quota.AppInstances, limits.Apps.TotalInstances, usage.UsageSummary.StartedInstances)
+	quota.MemoryMB = lowerRemaining(quota.MemoryMB, limits.Apps.TotalMemoryInMB, usage.UsageSummary.MemoryInMB)
+	quota.ProcessMemoryMB = lowerRemaining(quota.ProcessMemoryMB, limits.Apps.PerProcessMemoryInMB, 0)
+
+	return nil
+}
+
+func lowerRemaining(current int, limit *int, used int) int {
+	if limit == nil {
+		return current
+	}
+
+	remaining := *limit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if current == migrate.Unlimited || remaining < current {
+		return remaining
+	}
+
+	return current
+}
+
+func (c *MigratorClient) curl(path string, v interface{}) error {
+	output, err := c.pluginAPI.CliCommandWithoutTerminalOutput("curl", path)
+	if err != nil {
+		return fmt.Errorf("failed to request %s: %w", path, err)
+	}
+
+	jsonRaw := strings.Join(output, "\n")
+
+	var response struct {
+		Errors []Error `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(jsonRaw), &response); err != nil {
+		return fmt.Errorf("failed to parse the following api response: %s", jsonRaw)
+	}
+
+	if len(response.Errors) != 0 {
+		return fmt.Errorf("cc error code %d: %s - %s", response.Errors[0].Code, response.Errors[0].Title, response.Errors[0].Detail)
+	}
+
+	if err := json.Unmarshal([]byte(jsonRaw), v); err != nil {
+		return fmt.Errorf("failed to parse the following api response: %s", jsonRaw)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf_test
+
+import (
+	"errors"
+	"strings"
+
+	"code.cloudfoundry.org/cli/plugin/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf/cffakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+var _ = Describe("MigratorClient preflight", func() {
+	var (
+		client          *cf.MigratorClient
+		fakeCFPluginAPI *cffakes.FakeCFPluginAPI
+		responses       map[string]string
+	)
+
+	BeforeEach(func() {
+		fakeCFPluginAPI = new(cffakes.FakeCFPluginAPI)
+		client = cf.NewMigratorClient(fakeCFPluginAPI)
+		client.Log.SetOutput(GinkgoWriter)
+
+		fakeCFPluginAPI.GetCurrentSpaceReturns(plugin_models.Space{
+			SpaceFields: plugin_models.SpaceFields{Guid: "space-guid"},
+		}, nil)
+
+		responses = map[string]string{}
+		fakeCFPluginAPI.CliCommandWithoutTerminalOutputStub = func(args ...string) ([]string, error) {
+			response, ok := responses[strings.Join(args, " ")]
+			if !ok {
+				return nil, errors.New("unexpected command: " + strings.Join(args, " "))
+			}
+			return strings.Split(response, "\n"), nil
+		}
+	})
+
+	Context("CreateServiceKey", func() {
+		BeforeEach(func() {
+			responses["create-service-key some-donor some-key"] = "OK"
+		})
+
+		It("returns the credentials of the new key", func() {
+			responses["service-key some-donor some-key"] = `Getting key some-key for service instance some-donor as admin...
+
+{
+  "hostname": "some-host",
+  "name": "service_instance_db",
+  "password": "some-password",
+  "port": 3306,
+  "username": "some-user",
+  "tls": {"cert": {"ca": "some-ca"}}
+}`
+
+			Expect(client.CreateServiceKey("some-donor", "some-key")).To(Equal(migrate.ServiceCredentials{
+				Hostname: "some-host",
+				Name:     "service_instance_db",
+				Username: "some-user",
+				Password: "some-password",
+				Port:     3306,
+				CA:       "some-ca",
+			}))
+		})
+
+		It("understands credentials nested by newer cf CLI versions", func() {
+			responses["service-key some-donor some-key"] = `Getting key some-key for service instance some-donor as admin...
+
+{
+  "credentials": {
+    "hostname": "some-host",
+    "port": 3306
+  }
+}`
+
+			creds, err := client.CreateServiceKey("some-donor", "some-key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds.Hostname).To(Equal("some-host"))
+			Expect(creds.Port).To(Equal(3306))
+		})
+
+		It("returns an error when the key can not be created", func() {
+			delete(responses, "create-service-key some-donor some-key")
+
+			_, err := client.CreateServiceKey("some-donor", "some-key")
+			Expect(err).To(MatchError(ContainSubstring(`failed to create service key "some-key"`)))
+		})
+	})
+
+	Context("CreateUserProvidedService", func() {
+		It("exposes the credentials in the format of a MySQL service binding", func() {
+			responses[`create-user-provided-service some-source -p {"hostname":"some-host","name":"some-db","username":"some-user","password":"some-password","port":3306,"tls":{"cert":{"ca":"some-ca"}}}`] = "OK"
+
+			Expect(client.CreateUserProvidedService("some-source", migrate.ServiceCredentials{
+				Hostname: "some-host",
+				Name:     "some-db",
+				Username: "some-user",
+				Password: "some-password",
+				Port:     3306,
+				CA:       "some-ca",
+			})).To(Succeed())
+		})
+
+		It("returns an error when the service can not be created", func() {
+			err := client.CreateUserProvidedService("some-source", migrate.ServiceCredentials{})
+			Expect(err).To(MatchError(ContainSubstring(`failed to create user provided service "some-source"`)))
+		})
+	})
+
+	Context("DeleteServiceKey", func() {
+		It("deletes the key", func() {
+			responses["delete-service-key -f some-donor some-key"] = "OK"
+
+			Expect(client.DeleteServiceKey("some-donor", "some-key")).To(Succeed())
+		})
+	})
+
+	Context("ServicePlanExists", func() {
+		const plansPath = "curl /v3/service_plans?names=some-plan&service_offering_names=p.mysql&space_guids=space-guid"
+
+		It("finds plans available in the current space", func() {
+			responses[plansPath] = `{"resources": [{"guid": "plan-guid"}]}`
+
+			Expect(client.ServicePlanExists("p.mysql", "some-plan")).To(BeTrue())
+		})
+
+		It("reports a missing plan", func() {
+			responses[plansPath] = `{"resources": []}`
+
+			Expect(client.ServicePlanExists("p.mysql", "some-plan")).To(BeFalse())
+		})
+
+		It("returns api errors", func() {
+			responses[plansPath] = `{"errors": [{"code": 10002, "title": "CF-NotAuthenticated", "detail": "Authentication error"}]}`
+
+			_, err := client.ServicePlanExists("p.mysql", "some-plan")
+			Expect(err).To(MatchError("cc error code 10002: CF-NotAuthenticated - Authentication error"))
+		})
+	})
+
+	Context("RemainingQuota", func() {
+		BeforeEach(func() {
+			responses["curl /v3/spaces/space-guid"] = `{"relationships": {"organization": {"data": {"guid": "org-guid"}}, "quota": {"data": null}}}`
+			responses["curl /v3/organizations/org-guid"] = `{"relationships": {"quota": {"data": {"guid": "org-quota-guid"}}}}`
+			responses["curl /v3/organization_quotas/org-quota-guid"] = `{"apps": {"total_memory_in_mb": 10240, "total_instances": null}, "services": {"total_service_instances": 10}}`
+			responses["curl /v3/organizations/org-guid/usage_summary"] = `{"usage_summary": {"started_instances": 4, "memory_in_mb": 4096}}`
+			responses["curl /v3/service_instances?organization_guids=org-guid&per_page=1&type=managed"] = `{"pagination": {"total_results": 7}}`
+		})
+
+		It("returns what remains of the org quota", func() {
+			Expect(client.RemainingQuota()).To(Equal(migrate.Quota{
+				ServiceInstances: 3,
+				AppInstances:     migrate.Unlimited,
+				MemoryMB:         6144,
+				ProcessMemoryMB:  migrate.Unlimited,
+			}))
+		})
+
+		It("applies the space quota when it is lower", func() {
+			responses["curl /v3/spaces/space-guid"] = `{"relationships": {"organization": {"data": {"guid": "org-guid"}}, "quota": {"data": {"guid": "space-quota-guid"}}}}`
+			responses["curl /v3/space_quotas/space-quota-guid"] = `{"apps": {"total_memory_in_mb": 1024, "per_process_memory_in_mb": 768, "total_instances": 2}, "services": {"total_service_instances": null}}`
+			responses["curl /v3/spaces/space-guid/usage_summary"] = `{"usage_summary": {"started_instances": 2, "memory_in_mb": 512}}`
+			responses["curl /v3/service_instances?per_page=1&space_guids=space-guid&type=managed"] = `{"pagination": {"total_results": 1}}`
+
+			Expect(client.RemainingQuota()).To(Equal(migrate.Quota{
+				ServiceInstances: 3,
+				AppInstances:     0,
+				MemoryMB:         512,
+				ProcessMemoryMB:  768,
+			}))
+		})
+
+		It("returns an error when the quota can not be retrieved", func() {
+			delete(responses, "curl /v3/organization_quotas/org-quota-guid")
+
+			_, err := client.RemainingQuota()
+			Expect(err).To(MatchError(ContainSubstring("failed to request /v3/organization_quotas/org-quota-guid")))
+		})
+	})
+})
@@ -0,0 +1,93 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func (c *MigratorClient) ServiceInstanceGUID(instanceName string) (string, error) {
+	service, err := c.pluginAPI.GetService(instanceName)
+	if err != nil {
+		return "", err
+	}
+
+	return service.Guid, nil
+}
+
+// BindingParameters returns the parameters an app binding or service key was created with as compact JSON, or an
+// empty string when it has none
+func (c *MigratorClient) BindingParameters(bindingGUID string) (string, error) {
+	var parameters map[string]interface{}
+	if err := c.curl("/v3/service_credential_bindings/"+bindingGUID+"/parameters", &parameters); err != nil {
+		return "", fmt.Errorf("failed to retrieve parameters of binding %s: %w", bindingGUID, err)
+	}
+
+	if len(parameters) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+func (c *MigratorClient) BindServiceWithParameters(appName, serviceName, parameters string) error {
+	args := []string{"bind-service", appName, serviceName}
+	if parameters != "" {
+		args = append(args, "-c", parameters)
+	}
+
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(args...); err != nil {
+		return fmt.Errorf("failed to bind-service %q to application %q: %w", serviceName, appName, err)
+	}
+
+	return nil
+}
+
+func (c *MigratorClient) UnbindService(appName, serviceName string) error {
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(
+		"unbind-service", appName, serviceName,
+	); err != nil {
+		return fmt.Errorf("failed to unbind-service %q from application %q: %w", serviceName, appName, err)
+	}
+
+	return nil
+}
+
+func (c *MigratorClient) CreateServiceKeyWithParameters(instanceName, keyName, parameters string) error {
+	args := []string{"create-service-key", instanceName, keyName}
+	if parameters != "" {
+		args = append(args, "-c", parameters)
+	}
+
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(args...); err != nil {
+		return fmt.Errorf("failed to create-service-key %q for service %q: %w", keyName, instanceName, err)
+	}
+
+	return nil
+}
+
+func (c *MigratorClient) RestageApp(appName string) error {
+	if _, err := c.pluginAPI.CliCommandWithoutTerminalOutput(
+		"restage", appName,
+	); err != nil {
+		return fmt.Errorf("failed to restage application %q: %w", appName, err)
+	}
+
+	return nil
+}
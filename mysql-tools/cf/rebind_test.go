@@ -0,0 +1,152 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package cf_test
+
+import (
+	"errors"
+	"strings"
+
+	"code.cloudfoundry.org/cli/plugin/models"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf/cffakes"
+)
+
+var _ = Describe("MigratorClient rebind", func() {
+	var (
+		client          *cf.MigratorClient
+		fakeCFPluginAPI *cffakes.FakeCFPluginAPI
+	)
+
+	BeforeEach(func() {
+		fakeCFPluginAPI = new(cffakes.FakeCFPluginAPI)
+		client = cf.NewMigratorClient(fakeCFPluginAPI)
+		client.Log.SetOutput(GinkgoWriter)
+	})
+
+	Context("ServiceInstanceGUID", func() {
+		It("returns the guid of the service instance", func() {
+			fakeCFPluginAPI.GetServiceReturns(plugin_models.GetService_Model{Guid: "some-guid"}, nil)
+
+			Expect(client.ServiceInstanceGUID("some-instance")).To(Equal("some-guid"))
+			Expect(fakeCFPluginAPI.GetServiceArgsForCall(0)).To(Equal("some-instance"))
+		})
+
+		It("returns an error when the service instance can not be found", func() {
+			fakeCFPluginAPI.GetServiceReturns(plugin_models.GetService_Model{}, errors.New("not found"))
+
+			_, err := client.ServiceInstanceGUID("some-instance")
+			Expect(err).To(MatchError("not found"))
+		})
+	})
+
+	Context("BindingParameters", func() {
+		It("returns the parameters as compact JSON", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(strings.Split(`{
+  "read_only": true
+}`, "\n"), nil)
+
+			Expect(client.BindingParameters("some-binding-guid")).To(Equal(`{"read_only":true}`))
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{
+				"curl", "/v3/service_credential_bindings/some-binding-guid/parameters",
+			}))
+		})
+
+		It("returns an empty string when the binding has no parameters", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns([]string{`{}`}, nil)
+
+			Expect(client.BindingParameters("some-binding-guid")).To(BeEmpty())
+		})
+
+		It("returns an error when the broker does not support fetching binding parameters", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns([]string{
+				`{"errors": [{"code": 10008, "title": "CF-UnprocessableEntity", "detail": "This service does not support fetching service binding parameters."}]}`,
+			}, nil)
+
+			_, err := client.BindingParameters("some-binding-guid")
+			Expect(err).To(MatchError(ContainSubstring("failed to retrieve parameters of binding some-binding-guid: cc error code 10008")))
+		})
+	})
+
+	Context("BindServiceWithParameters", func() {
+		It("binds the app with the given parameters", func() {
+			Expect(client.BindServiceWithParameters("some-app", "some-instance", `{"read_only":true}`)).To(Succeed())
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{
+				"bind-service", "some-app", "some-instance", "-c", `{"read_only":true}`,
+			}))
+		})
+
+		It("omits empty parameters", func() {
+			Expect(client.BindServiceWithParameters("some-app", "some-instance", "")).To(Succeed())
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{
+				"bind-service", "some-app", "some-instance",
+			}))
+		})
+
+		It("returns an error when binding fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(nil, errors.New("some-error"))
+
+			Expect(client.BindServiceWithParameters("some-app", "some-instance", "")).
+				To(MatchError(`failed to bind-service "some-instance" to application "some-app": some-error`))
+		})
+	})
+
+	Context("UnbindService", func() {
+		It("unbinds the app", func() {
+			Expect(client.UnbindService("some-app", "some-instance")).To(Succeed())
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{
+				"unbind-service", "some-app", "some-instance",
+			}))
+		})
+
+		It("returns an error when unbinding fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(nil, errors.New("some-error"))
+
+			Expect(client.UnbindService("some-app", "some-instance")).
+				To(MatchError(`failed to unbind-service "some-instance" from application "some-app": some-error`))
+		})
+	})
+
+	Context("CreateServiceKeyWithParameters", func() {
+		It("creates the service key with the given parameters", func() {
+			Expect(client.CreateServiceKeyWithParameters("some-instance", "some-key", `{"read_only":true}`)).To(Succeed())
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{
+				"create-service-key", "some-instance", "some-key", "-c", `{"read_only":true}`,
+			}))
+		})
+
+		It("returns an error when creating the key fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(nil, errors.New("some-error"))
+
+			Expect(client.CreateServiceKeyWithParameters("some-instance", "some-key", "")).
+				To(MatchError(`failed to create-service-key "some-key" for service "some-instance": some-error`))
+		})
+	})
+
+	Context("RestageApp", func() {
+		It("restages the app", func() {
+			Expect(client.RestageApp("some-app")).To(Succeed())
+			Expect(fakeCFPluginAPI.CliCommandWithoutTerminalOutputArgsForCall(0)).To(Equal([]string{
+				"restage", "some-app",
+			}))
+		})
+
+		It("returns an error when restaging fails", func() {
+			fakeCFPluginAPI.CliCommandWithoutTerminalOutputReturns(nil, errors.New("some-error"))
+
+			Expect(client.RestageApp("some-app")).To(MatchError(`failed to restage application "some-app": some-error`))
+		})
+	})
+})
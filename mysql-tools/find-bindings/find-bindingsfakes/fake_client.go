@@ -36,6 +36,19 @@ type FakeClient struct {
 		result1 cfclient.Org
 		result2 error
 	}
+	GetServiceInstanceByGuidStub        func(string) (cfclient.ServiceInstance, error)
+	getServiceInstanceByGuidMutex       sync.RWMutex
+	getServiceInstanceByGuidArgsForCall []struct {
+		arg1 string
+	}
+	getServiceInstanceByGuidReturns struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}
+	getServiceInstanceByGuidReturnsOnCall map[int]struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}
 	GetSpaceByGuidStub        func(string) (cfclient.Space, error)
 	getSpaceByGuidMutex       sync.RWMutex
 	getSpaceByGuidArgsForCall []struct {
@@ -246,6 +259,70 @@ func (fake *FakeClient) GetOrgByGuidReturnsOnCall(i int, result1 cfclient.Org, r
 	}{result1, result2}
 }
 
+func (fake *FakeClient) GetServiceInstanceByGuid(arg1 string) (cfclient.ServiceInstance, error) {
+	fake.getServiceInstanceByGuidMutex.Lock()
+	ret, specificReturn := fake.getServiceInstanceByGuidReturnsOnCall[len(fake.getServiceInstanceByGuidArgsForCall)]
+	fake.getServiceInstanceByGuidArgsForCall = append(fake.getServiceInstanceByGuidArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetServiceInstanceByGuidStub
+	fakeReturns := fake.getServiceInstanceByGuidReturns
+	fake.recordInvocation("GetServiceInstanceByGuid", []interface{}{arg1})
+	fake.getServiceInstanceByGuidMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) GetServiceInstanceByGuidCallCount() int {
+	fake.getServiceInstanceByGuidMutex.RLock()
+	defer fake.getServiceInstanceByGuidMutex.RUnlock()
+	return len(fake.getServiceInstanceByGuidArgsForCall)
+}
+
+func (fake *FakeClient) GetServiceInstanceByGuidCalls(stub func(string) (cfclient.ServiceInstance, error)) {
+	fake.getServiceInstanceByGuidMutex.Lock()
+	defer fake.getServiceInstanceByGuidMutex.Unlock()
+	fake.GetServiceInstanceByGuidStub = stub
+}
+
+func (fake *FakeClient) GetServiceInstanceByGuidArgsForCall(i int) string {
+	fake.getServiceInstanceByGuidMutex.RLock()
+	defer fake.getServiceInstanceByGuidMutex.RUnlock()
+	argsForCall := fake.getServiceInstanceByGuidArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) GetServiceInstanceByGuidReturns(result1 cfclient.ServiceInstance, result2 error) {
+	fake.getServiceInstanceByGuidMutex.Lock()
+	defer fake.getServiceInstanceByGuidMutex.Unlock()
+	fake.GetServiceInstanceByGuidStub = nil
+	fake.getServiceInstanceByGuidReturns = struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) GetServiceInstanceByGuidReturnsOnCall(i int, result1 cfclient.ServiceInstance, result2 error) {
+	fake.getServiceInstanceByGuidMutex.Lock()
+	defer fake.getServiceInstanceByGuidMutex.Unlock()
+	fake.GetServiceInstanceByGuidStub = nil
+	if fake.getServiceInstanceByGuidReturnsOnCall == nil {
+		fake.getServiceInstanceByGuidReturnsOnCall = make(map[int]struct {
+			result1 cfclient.ServiceInstance
+			result2 error
+		})
+	}
+	fake.getServiceInstanceByGuidReturnsOnCall[i] = struct {
+		result1 cfclient.ServiceInstance
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) GetSpaceByGuid(arg1 string) (cfclient.Space, error) {
 	fake.getSpaceByGuidMutex.Lock()
 	ret, specificReturn := fake.getSpaceByGuidReturnsOnCall[len(fake.getSpaceByGuidArgsForCall)]
@@ -637,6 +714,8 @@ func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	defer fake.getAppByGuidMutex.RUnlock()
 	fake.getOrgByGuidMutex.RLock()
 	defer fake.getOrgByGuidMutex.RUnlock()
+	fake.getServiceInstanceByGuidMutex.RLock()
+	defer fake.getServiceInstanceByGuidMutex.RUnlock()
 	fake.getSpaceByGuidMutex.RLock()
 	defer fake.getSpaceByGuidMutex.RUnlock()
 	fake.listServiceBindingsByQueryMutex.RLock()
@@ -33,9 +33,11 @@ type Client interface {
 	ListServicePlansByQuery(query url.Values) ([]cfclient.ServicePlan, error)
 	ListServiceKeysByQuery(query url.Values) ([]cfclient.ServiceKey, error)
 	ListServiceInstancesByQuery(query url.Values) ([]cfclient.ServiceInstance, error)
+	GetServiceInstanceByGuid(guid string) (cfclient.ServiceInstance, error)
 }
 
 type Binding struct {
+	Guid                string
 	Name                string
 	ServiceInstanceName string
 	ServiceInstanceGuid string
@@ -92,6 +94,28 @@ func (bf *BindingFinder) FindBindings(serviceLabel string) ([]Binding, error) {
 	return result, errs
 }
 
+// FindBindingsForInstance lists the app bindings and service keys of a single service instance
+func (bf *BindingFinder) FindBindingsForInstance(instanceGUID string) ([]Binding, error) {
+	instance, err := bf.cfClient.GetServiceInstanceByGuid(instanceGUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup service instance (guid: %q): %w", instanceGUID, err)
+	}
+
+	var errs error
+
+	result, err := bf.listServiceBindingsForInstance(instance)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	keys, err := bf.listServiceKeysForInstance(instance)
+	if err != nil {
+		errs = multierror.Append(errs, err)
+	}
+
+	return append(result, keys...), errs
+}
+
 func (bf *BindingFinder) serviceGUIDForLabel(serviceLabel string) (serviceGUID string, err error) {
 	query := url.Values{}
 	query.Set("q", "label:"+serviceLabel)
@@ -167,6 +191,7 @@ func (bf *BindingFinder) listServiceBindingsForInstance(instance cfclient.Servic
 		}
 
 		result = append(result, Binding{
+			Guid:                b.Guid,
 			Name:                app.Name,
 			ServiceInstanceName: instance.Name,
 			ServiceInstanceGuid: instance.Guid,
@@ -201,6 +226,7 @@ func (bf *BindingFinder) listServiceKeysForInstance(instance cfclient.ServiceIns
 		}
 
 		result = append(result, Binding{
+			Guid:                k.Guid,
 			Name:                k.Name,
 			ServiceInstanceName: instance.Name,
 			ServiceInstanceGuid: instance.Guid,
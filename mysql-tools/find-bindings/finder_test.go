@@ -48,6 +48,7 @@ var _ = Describe("BindingFinder", func() {
 
 			expectedBindings = []find_bindings.Binding{
 				{
+					Guid:                "binding1-guid",
 					Name:                "app1",
 					ServiceInstanceName: "instance1",
 					ServiceInstanceGuid: "instance1-guid",
@@ -64,6 +65,7 @@ var _ = Describe("BindingFinder", func() {
 					Type:                "ServiceKeyBinding",
 				},
 				{
+					Guid:                "binding3-guid",
 					Name:                "app3",
 					ServiceInstanceName: "instance3",
 					ServiceInstanceGuid: "instance3-guid",
@@ -309,4 +311,78 @@ var _ = Describe("BindingFinder", func() {
 			})
 		})
 	})
+
+	Context("FindBindingsForInstance", func() {
+		var fakeClient *findbindingsfakes.FakeClient
+
+		BeforeEach(func() {
+			fakeClient = &findbindingsfakes.FakeClient{}
+
+			fakeClient.GetServiceInstanceByGuidReturns(cfclient.ServiceInstance{
+				Name: "instance1", Guid: "instance1-guid", SpaceGuid: "space1-guid",
+			}, nil)
+			fakeClient.ListServiceBindingsByQueryReturns([]cfclient.ServiceBinding{
+				{Guid: "binding1-guid", AppGuid: "app1-guid", ServiceInstanceGuid: "instance1-guid"},
+			}, nil)
+			fakeClient.ListServiceKeysByQueryReturns([]cfclient.ServiceKey{
+				{Name: "key1", Guid: "key1-guid"},
+			}, nil)
+			fakeClient.GetAppByGuidReturns(cfclient.App{
+				Name: "app1",
+				SpaceData: cfclient.SpaceResource{
+					Entity: cfclient.Space{
+						Name:    "space1",
+						OrgData: cfclient.OrgResource{Entity: cfclient.Org{Name: "org1"}},
+					},
+				},
+			}, nil)
+			fakeClient.GetSpaceByGuidReturns(cfclient.Space{Name: "space1", OrganizationGuid: "org1-guid"}, nil)
+			fakeClient.GetOrgByGuidReturns(cfclient.Org{Name: "org1"}, nil)
+		})
+
+		It("returns the app bindings and service keys of the instance", func() {
+			finder := find_bindings.NewBindingFinder(fakeClient)
+			bindings, err := finder.FindBindingsForInstance("instance1-guid")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeClient.GetServiceInstanceByGuidArgsForCall(0)).To(Equal("instance1-guid"))
+			Expect(bindings).To(Equal([]find_bindings.Binding{
+				{
+					Guid:                "binding1-guid",
+					Name:                "app1",
+					ServiceInstanceName: "instance1",
+					ServiceInstanceGuid: "instance1-guid",
+					OrgName:             "org1",
+					SpaceName:           "space1",
+					Type:                "AppBinding",
+				},
+				{
+					Guid:                "key1-guid",
+					Name:                "key1",
+					ServiceInstanceName: "instance1",
+					ServiceInstanceGuid: "instance1-guid",
+					OrgName:             "org1",
+					SpaceName:           "space1",
+					Type:                "ServiceKeyBinding",
+				},
+			}))
+		})
+
+		It("returns an error when the instance can not be found", func() {
+			fakeClient.GetServiceInstanceByGuidReturns(cfclient.ServiceInstance{}, errors.New("not found"))
+
+			finder := find_bindings.NewBindingFinder(fakeClient)
+			_, err := finder.FindBindingsForInstance("instance1-guid")
+			Expect(err).To(MatchError(`failed to lookup service instance (guid: "instance1-guid"): not found`))
+		})
+
+		It("still returns the bindings that could be listed", func() {
+			fakeClient.ListServiceKeysByQueryReturns(nil, errors.New("listServiceKeysByQueryError"))
+
+			finder := find_bindings.NewBindingFinder(fakeClient)
+			bindings, err := finder.FindBindingsForInstance("instance1-guid")
+			Expect(err).To(MatchError(ContainSubstring("listServiceKeysByQueryError")))
+			Expect(bindings).To(HaveLen(1))
+		})
+	})
 })
@@ -0,0 +1,106 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+)
+
+// defaultAppMemoryMB is the memory foundations give apps by default, which the migration app gets unless its memory
+// is set
+const defaultAppMemoryMB = 1024
+
+// ParseMegabytes parses a memory or disk size with an M, MB, G or GB unit, like `cf push -m` and `-k` take them
+func ParseMegabytes(value string) (int, error) {
+	size := strings.ToUpper(strings.TrimSpace(value))
+
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(size, "GB"), strings.HasSuffix(size, "G"):
+		multiplier = 1024
+		size = strings.TrimSuffix(strings.TrimSuffix(size, "B"), "G")
+	case strings.HasSuffix(size, "MB"), strings.HasSuffix(size, "M"):
+		size = strings.TrimSuffix(strings.TrimSuffix(size, "B"), "M")
+	default:
+		return 0, fmt.Errorf("invalid size %q, expected an integer with a unit of M, MB, G or GB", value)
+	}
+
+	megabytes, err := strconv.Atoi(size)
+	if err != nil || megabytes <= 0 {
+		return 0, fmt.Errorf("invalid size %q, expected an integer with a unit of M, MB, G or GB", value)
+	}
+
+	return megabytes * multiplier, nil
+}
+
+// CheckAppSettings checks that the migration app can be pushed with the stack, isolation segment and memory in opts,
+// so that a migration fails before a service instance is created for it rather than when pushing the app
+func (m *Migrator) CheckAppSettings(opts MigrateOptions) error {
+	if opts.App.Stack != "" {
+		exists, err := m.client.StackExists(opts.App.Stack)
+		if err != nil {
+			return fmt.Errorf("failed to look up stack %s: %w", opts.App.Stack, err)
+		}
+		if !exists {
+			return fmt.Errorf("stack %s not found", opts.App.Stack)
+		}
+	}
+
+	if opts.IsolationSegment != "" {
+		segment, err := m.client.IsolationSegment()
+		if err != nil {
+			return fmt.Errorf("failed to look up the isolation segment of the targeted space: %w", err)
+		}
+		// Apps run in the isolation segment of their space, which the manifest can not change
+		if segment != opts.IsolationSegment {
+			return fmt.Errorf("the migration app would run in isolation segment %s of the targeted space rather than in %s. "+
+				"Run 'cf set-space-isolation-segment' to change it, or target another space", segment, opts.IsolationSegment)
+		}
+	}
+
+	if opts.App.MemoryMB > 0 {
+		quota, err := m.client.RemainingQuota()
+		if err != nil {
+			log.Printf("Warning: unable to determine the remaining quota, not checking whether the migration app can use %d MB of memory: %s", opts.App.MemoryMB, err)
+			return nil
+		}
+
+		if quota.ProcessMemoryMB != Unlimited && opts.App.MemoryMB > quota.ProcessMemoryMB {
+			return fmt.Errorf("the migration app can not use %d MB of memory, since the quota allows at most %d MB per app instance",
+				opts.App.MemoryMB, quota.ProcessMemoryMB)
+		}
+
+		if needed := migrationMemoryMB(opts.App); quota.MemoryMB != Unlimited && needed > quota.MemoryMB {
+			return fmt.Errorf("the migration app and its task need %d MB of memory, but only %d MB remain in the quota",
+				needed, quota.MemoryMB)
+		}
+	}
+
+	return nil
+}
+
+// migrationMemoryMB is the memory the migration app and its task use together. The task runs next to the app
+// instance, with as much memory.
+func migrationMemoryMB(settings app.Settings) int {
+	memory := settings.MemoryMB
+	if memory == 0 {
+		memory = defaultAppMemoryMB
+	}
+
+	return 2 * memory
+}
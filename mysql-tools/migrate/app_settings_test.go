@@ -0,0 +1,108 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("CheckAppSettings", func() {
+	var (
+		fakeClient *migratefakes.FakeClient
+		migrator   *Migrator
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		migrator = NewMigrator(fakeClient, nil, nil, nil, nil, nil)
+
+		fakeClient.StackExistsReturns(true, nil)
+		fakeClient.IsolationSegmentReturns("shared", nil)
+		fakeClient.RemainingQuotaReturns(Quota{MemoryMB: 8192, ProcessMemoryMB: Unlimited}, nil)
+	})
+
+	It("checks nothing without settings", func() {
+		Expect(migrator.CheckAppSettings(MigrateOptions{})).To(Succeed())
+
+		Expect(fakeClient.StackExistsCallCount()).To(BeZero())
+		Expect(fakeClient.IsolationSegmentCallCount()).To(BeZero())
+		Expect(fakeClient.RemainingQuotaCallCount()).To(BeZero())
+	})
+
+	It("accepts settings the space allows", func() {
+		opts := MigrateOptions{App: app.Settings{MemoryMB: 4096, Stack: "cflinuxfs4"}, IsolationSegment: "shared"}
+		Expect(migrator.CheckAppSettings(opts)).To(Succeed())
+
+		Expect(fakeClient.StackExistsArgsForCall(0)).To(Equal("cflinuxfs4"))
+	})
+
+	It("rejects an unknown stack", func() {
+		fakeClient.StackExistsReturns(false, nil)
+
+		err := migrator.CheckAppSettings(MigrateOptions{App: app.Settings{Stack: "windows"}})
+		Expect(err).To(MatchError("stack windows not found"))
+	})
+
+	It("rejects a space running apps in another isolation segment", func() {
+		err := migrator.CheckAppSettings(MigrateOptions{IsolationSegment: "data"})
+		Expect(err).To(MatchError(ContainSubstring("the migration app would run in isolation segment shared of the targeted space rather than in data")))
+	})
+
+	It("rejects more memory than an app instance may use", func() {
+		fakeClient.RemainingQuotaReturns(Quota{MemoryMB: Unlimited, ProcessMemoryMB: 1024}, nil)
+
+		err := migrator.CheckAppSettings(MigrateOptions{App: app.Settings{MemoryMB: 2048}})
+		Expect(err).To(MatchError("the migration app can not use 2048 MB of memory, since the quota allows at most 1024 MB per app instance"))
+	})
+
+	It("rejects more memory than remains for the app and its task", func() {
+		err := migrator.CheckAppSettings(MigrateOptions{App: app.Settings{MemoryMB: 5120}})
+		Expect(err).To(MatchError("the migration app and its task need 10240 MB of memory, but only 8192 MB remain in the quota"))
+	})
+
+	It("does not fail when the quota can not be determined", func() {
+		fakeClient.RemainingQuotaReturns(Quota{}, errors.New("some-error"))
+
+		Expect(migrator.CheckAppSettings(MigrateOptions{App: app.Settings{MemoryMB: 5120}})).To(Succeed())
+	})
+})
+
+var _ = Describe("ParseMegabytes", func() {
+	DescribeTable("accepts sizes in megabytes or gigabytes",
+		func(value string, expected int) {
+			Expect(ParseMegabytes(value)).To(Equal(expected))
+		},
+		Entry("megabytes", "512M", 512),
+		Entry("megabytes with a B", "512MB", 512),
+		Entry("gigabytes", "2G", 2048),
+		Entry("lower case gigabytes with a B", "4gb", 4096),
+	)
+
+	DescribeTable("rejects anything else",
+		func(value string) {
+			_, err := ParseMegabytes(value)
+			Expect(err).To(MatchError(ContainSubstring("expected an integer with a unit of M, MB, G or GB")))
+		},
+		Entry("no unit", "1024"),
+		Entry("an unknown unit", "1T"),
+		Entry("a fraction", "1.5G"),
+		Entry("zero", "0M"),
+	)
+})
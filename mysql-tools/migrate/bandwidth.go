@@ -0,0 +1,45 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var bandwidthUnits = map[string]int64{
+	"":  1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+}
+
+// ParseBandwidth parses a number of bytes per second, optionally followed by a K, M or G binary suffix, such as 10M
+func ParseBandwidth(value string) (int64, error) {
+	trimmed := strings.ToUpper(strings.TrimSpace(value))
+	trimmed = strings.TrimSuffix(strings.TrimSuffix(trimmed, "/S"), "B")
+
+	number := strings.TrimRight(trimmed, "KMG")
+	unit, ok := bandwidthUnits[trimmed[len(number):]]
+	if !ok {
+		return 0, fmt.Errorf("invalid bandwidth %q, expected bytes per second such as 1048576, 512K or 10M", value)
+	}
+
+	bytesPerSecond, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || bytesPerSecond < 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q, expected bytes per second such as 1048576, 512K or 10M", value)
+	}
+
+	return bytesPerSecond * unit, nil
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+var _ = Describe("ParseBandwidth", func() {
+	DescribeTable("accepts bytes per second with an optional binary suffix",
+		func(value string, expected int64) {
+			Expect(ParseBandwidth(value)).To(Equal(expected))
+		},
+		Entry("bytes", "1048576", int64(1048576)),
+		Entry("kibibytes", "512K", int64(512*1024)),
+		Entry("mebibytes", "10M", int64(10*1024*1024)),
+		Entry("gibibytes with a unit", "1GB/s", int64(1024*1024*1024)),
+		Entry("lower case", "64k", int64(64*1024)),
+	)
+
+	DescribeTable("rejects anything else",
+		func(value string) {
+			_, err := ParseBandwidth(value)
+			Expect(err).To(MatchError(ContainSubstring("invalid bandwidth")))
+		},
+		Entry("an empty value", ""),
+		Entry("an unknown suffix", "10T"),
+		Entry("a fraction", "1.5M"),
+		Entry("a negative value", "-1M"),
+		Entry("several suffixes", "10KM"),
+	)
+})
@@ -0,0 +1,63 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+type CheckCompatOptions struct {
+	InstanceName      string
+	SkipTLSValidation bool
+	Cleanup           bool
+}
+
+// CheckCompat reports what in a service instance blocks or changes its migration to MySQL 8.0, by running a task of
+// a migration app bound to it. The task fails when it finds blockers.
+func (m *Migrator) CheckCompat(opts CheckCompatOptions) error {
+	if err := m.pushHelperApp(); err != nil {
+		return err
+	}
+
+	if opts.Cleanup {
+		defer m.deleteHelperApp()
+	}
+
+	if err := m.startHelperApp(opts.InstanceName, nil); err != nil {
+		return err
+	}
+
+	log.Print("Started to run compatibility check task")
+	if _, err := m.runTask(context.Background(), checkCompatTaskCommand(opts)); err != nil {
+		return fmt.Errorf("compatibility check of %s failed: %w", opts.InstanceName, err)
+	}
+
+	log.Printf("%s has no blockers to migrating to MySQL 8.0", opts.InstanceName)
+
+	return nil
+}
+
+func checkCompatTaskCommand(opts CheckCompatOptions) string {
+	args := []string{"migrate", "check-compat"}
+
+	if opts.SkipTLSValidation {
+		args = append(args, "-skip-tls-validation")
+	}
+
+	args = append(args, opts.InstanceName)
+
+	return strings.Join(args, " ")
+}
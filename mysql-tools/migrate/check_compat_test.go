@@ -0,0 +1,93 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("CheckCompat", func() {
+	var (
+		fakeClient   *migratefakes.FakeClient
+		fakeUnpacker *migratefakes.FakeUnpacker
+		migrator     *Migrator
+		opts         CheckCompatOptions
+	)
+
+	BeforeEach(func() {
+		opts = CheckCompatOptions{
+			InstanceName: "some-instance",
+			Cleanup:      true,
+		}
+		fakeClient = new(migratefakes.FakeClient)
+		fakeUnpacker = new(migratefakes.FakeUnpacker)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, nil, nil, nil, nil)
+		migrator.Sleep = func(time.Duration) {}
+
+		fakeClient.StartTaskReturns("some-task-guid", nil)
+		fakeClient.GetLogsReturns([]string{
+			`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT MySQL 8.0 compatibility report:`,
+		}, nil)
+	})
+
+	It("runs the check as a task of a migration app bound to the instance", func() {
+		opts.SkipTLSValidation = true
+		Expect(migrator.CheckCompat(opts)).To(Succeed())
+
+		Expect(fakeClient.PushAppCallCount()).To(Equal(1))
+		_, appName := fakeClient.PushAppArgsForCall(0)
+
+		Expect(fakeClient.BindServiceCallCount()).To(Equal(1))
+		boundApp, instance := fakeClient.BindServiceArgsForCall(0)
+		Expect(boundApp).To(Equal(appName))
+		Expect(instance).To(Equal("some-instance"))
+		Expect(fakeClient.SetEnvCallCount()).To(BeZero())
+		Expect(fakeClient.StartAppCallCount()).To(Equal(1))
+
+		Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+		taskApp, command := fakeClient.StartTaskArgsForCall(0)
+		Expect(taskApp).To(Equal(appName))
+		Expect(command).To(Equal("migrate check-compat -skip-tls-validation some-instance"))
+
+		Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+	})
+
+	It("returns an error when the check finds blockers", func() {
+		fakeClient.WaitForTaskReturns(errors.New("task failed"))
+
+		err := migrator.CheckCompat(opts)
+		Expect(err).To(MatchError("compatibility check of some-instance failed: task failed"))
+		Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+	})
+
+	It("does not cleanup when told not to", func() {
+		opts.Cleanup = false
+		Expect(migrator.CheckCompat(opts)).To(Succeed())
+		Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
+	})
+
+	It("returns an error when binding the instance fails", func() {
+		fakeClient.BindServiceReturns(errors.New("some-bind-error"))
+
+		err := migrator.CheckCompat(opts)
+		Expect(err).To(MatchError(ContainSubstring("some-bind-error")))
+		Expect(fakeClient.StartTaskCallCount()).To(BeZero())
+	})
+})
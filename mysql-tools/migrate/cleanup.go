@@ -0,0 +1,208 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// migrationAppPrefix starts the name of every app pushed to migrate data
+const migrationAppPrefix = "migrate-app-"
+
+// recipientSuffix ends the name of the service instance a migration creates, until it is renamed after the donor
+const recipientSuffix = "-new"
+
+type SpaceApp struct {
+	Name      string
+	CreatedAt time.Time
+	MemoryMB  int
+	Instances int
+}
+
+type SpaceServiceInstance struct {
+	Name         string
+	CreatedAt    time.Time
+	PlanName     string
+	OfferingName string
+}
+
+type LeftoverType string
+
+const (
+	LeftoverApp             LeftoverType = "app"
+	LeftoverServiceInstance LeftoverType = "service instance"
+)
+
+// Leftover is an app or service instance a migration that did not complete left behind
+type Leftover struct {
+	Type      LeftoverType
+	Name      string
+	CreatedAt time.Time
+	// Size is the memory of an app, or the plan of a service instance
+	Size string
+}
+
+// FindLeftovers looks up the migration apps and recipient service instances in the current space that were created
+// more than olderThan ago. Those of a migration that can still be resumed are kept. Service instances are only
+// recipients when a migration recorded creating them, they are of the offering migrations create, and the donor
+// named like them still exists.
+func (m *Migrator) FindLeftovers(olderThan time.Duration) ([]Leftover, error) {
+	store, err := m.spaceStore()
+	if err != nil {
+		return nil, err
+	}
+
+	states, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	recipients, err := store.Recipients()
+	if err != nil {
+		return nil, err
+	}
+
+	created := map[string]struct{}{}
+	for _, recipient := range recipients {
+		created[recipient] = struct{}{}
+	}
+
+	inUse := map[string]string{}
+	for _, state := range states {
+		if state.AppName != "" {
+			inUse[state.AppName] = state.DonorInstanceName
+		}
+		if state.RecipientInstanceName != "" {
+			inUse[state.RecipientInstanceName] = state.DonorInstanceName
+		}
+	}
+
+	cutoff := m.Now().Add(-olderThan)
+	var leftovers []Leftover
+
+	apps, err := m.client.ListApps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list apps: %w", err)
+	}
+
+	for _, app := range apps {
+		if !strings.HasPrefix(app.Name, migrationAppPrefix) || app.CreatedAt.After(cutoff) {
+			continue
+		}
+
+		if donor, ok := inUse[app.Name]; ok {
+			log.Printf("Keeping app %s, which the migration of %s can still resume with", app.Name, donor)
+			continue
+		}
+
+		leftovers = append(leftovers, Leftover{
+			Type:      LeftoverApp,
+			Name:      app.Name,
+			CreatedAt: app.CreatedAt,
+			Size:      fmt.Sprintf("%dM x %d", app.MemoryMB, app.Instances),
+		})
+	}
+
+	instances, err := m.client.ListServiceInstances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service instances: %w", err)
+	}
+
+	existing := map[string]struct{}{}
+	for _, instance := range instances {
+		existing[instance.Name] = struct{}{}
+	}
+
+	for _, instance := range instances {
+		if !strings.HasSuffix(instance.Name, recipientSuffix) || instance.CreatedAt.After(cutoff) {
+			continue
+		}
+
+		if instance.OfferingName != RecipientProductName() {
+			continue
+		}
+
+		if _, ok := existing[strings.TrimSuffix(instance.Name, recipientSuffix)]; !ok {
+			continue
+		}
+
+		if donor, ok := inUse[instance.Name]; ok {
+			log.Printf("Keeping service instance %s, which the migration of %s can still resume with", instance.Name, donor)
+			continue
+		}
+
+		if _, ok := created[instance.Name]; !ok {
+			log.Printf("Keeping service instance %s, which no migration run from here recorded creating. "+
+				"Run 'cf delete-service %s' if it was left behind", instance.Name, instance.Name)
+			continue
+		}
+
+		leftovers = append(leftovers, Leftover{
+			Type:      LeftoverServiceInstance,
+			Name:      instance.Name,
+			CreatedAt: instance.CreatedAt,
+			Size:      instance.PlanName,
+		})
+	}
+
+	return leftovers, nil
+}
+
+func (m *Migrator) ConfirmCleanup(count int) bool {
+	fmt.Printf("Type 'delete' to delete these %d apps and service instances: ", count)
+
+	answer, _ := bufio.NewReader(m.Input).ReadString('\n')
+
+	return strings.TrimSpace(answer) == "delete"
+}
+
+// CleanUp deletes leftovers, carrying on past failures
+func (m *Migrator) CleanUp(leftovers []Leftover) error {
+	store, err := m.spaceStore()
+	if err != nil {
+		return err
+	}
+
+	var failed int
+
+	for _, leftover := range leftovers {
+		log.Printf("Deleting %s %s", leftover.Type, leftover.Name)
+
+		var err error
+		switch leftover.Type {
+		case LeftoverApp:
+			err = m.client.DeleteApp(leftover.Name)
+		case LeftoverServiceInstance:
+			if err = m.client.DeleteServiceInstance(leftover.Name); err == nil {
+				err = store.ForgetRecipient(leftover.Name)
+			}
+		default:
+			err = fmt.Errorf("unknown type %q", leftover.Type)
+		}
+
+		if err != nil {
+			log.Printf("Failed to delete %s %s: %s", leftover.Type, leftover.Name, err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d apps and service instances", failed, len(leftovers))
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("Cleanup", func() {
+	var (
+		fakeClient     *migratefakes.FakeClient
+		fakeStateStore *migratefakes.FakeStateStore
+		migrator       *Migrator
+		now            time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+
+		fakeClient = new(migratefakes.FakeClient)
+		fakeClient.ListAppsReturns([]SpaceApp{
+			{Name: "migrate-app-old", CreatedAt: now.Add(-48 * time.Hour), MemoryMB: 1024, Instances: 1},
+			{Name: "migrate-app-recent", CreatedAt: now.Add(-time.Hour), MemoryMB: 1024, Instances: 1},
+			{Name: "migrate-app-resumable", CreatedAt: now.Add(-48 * time.Hour), MemoryMB: 1024, Instances: 1},
+			{Name: "some-app", CreatedAt: now.Add(-48 * time.Hour), MemoryMB: 256, Instances: 2},
+		}, nil)
+		fakeClient.ListServiceInstancesReturns([]SpaceServiceInstance{
+			{Name: "some-donor-new", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-small", OfferingName: "p.mysql"},
+			{Name: "resumable-donor-new", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-small", OfferingName: "p.mysql"},
+			{Name: "recent-donor-new", CreatedAt: now.Add(-time.Hour), PlanName: "db-small", OfferingName: "p.mysql"},
+			{Name: "unrecorded-donor-new", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-small", OfferingName: "p.mysql"},
+			{Name: "deleted-donor-new", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-small", OfferingName: "p.mysql"},
+			{Name: "cache-new", CreatedAt: now.Add(-72 * time.Hour), PlanName: "small", OfferingName: "p.redis"},
+			{Name: "some-donor", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-large", OfferingName: "p-mysql"},
+			{Name: "resumable-donor", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-large", OfferingName: "p-mysql"},
+			{Name: "recent-donor", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-large", OfferingName: "p-mysql"},
+			{Name: "unrecorded-donor", CreatedAt: now.Add(-72 * time.Hour), PlanName: "db-large", OfferingName: "p-mysql"},
+			{Name: "cache", CreatedAt: now.Add(-72 * time.Hour), PlanName: "small", OfferingName: "p.redis"},
+		}, nil)
+
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		fakeStateStore.ListReturns([]State{{
+			DonorInstanceName:     "resumable-donor",
+			RecipientInstanceName: "resumable-donor-new",
+			AppName:               "migrate-app-resumable",
+		}}, nil)
+		fakeStateStore.RecipientsReturns([]string{
+			"some-donor-new", "resumable-donor-new", "recent-donor-new", "deleted-donor-new", "cache-new",
+		}, nil)
+
+		migrator = NewMigrator(fakeClient, nil, fakeStateStore, nil, nil, nil)
+		migrator.Now = func() time.Time { return now }
+	})
+
+	Describe("FindLeftovers", func() {
+		It("only considers the recipients recorded in the targeted space", func() {
+			space := Space{APIEndpoint: "https://api.example.com", GUID: "some-space-guid"}
+			fakeClient.CurrentSpaceReturns(space, nil)
+			spaceStore := new(migratefakes.FakeStateStore)
+			spaceStore.RecipientsReturns([]string{"recent-donor-new"}, nil)
+			fakeStateStore.InSpaceReturns(spaceStore)
+
+			leftovers, err := migrator.FindLeftovers(24 * time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeStateStore.InSpaceArgsForCall(0)).To(Equal(space))
+			Expect(leftovers).To(ConsistOf(
+				HaveField("Name", "migrate-app-old"),
+				HaveField("Name", "migrate-app-resumable"),
+			))
+		})
+
+		It("finds the migration apps and recipients older than the given age that can not be resumed", func() {
+			leftovers, err := migrator.FindLeftovers(24 * time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(leftovers).To(Equal([]Leftover{
+				{Type: LeftoverApp, Name: "migrate-app-old", CreatedAt: now.Add(-48 * time.Hour), Size: "1024M x 1"},
+				{Type: LeftoverServiceInstance, Name: "some-donor-new", CreatedAt: now.Add(-72 * time.Hour), Size: "db-small"},
+			}))
+		})
+
+		When("the migration states can not be listed", func() {
+			BeforeEach(func() {
+				fakeStateStore.ListReturns(nil, errors.New("some-error"))
+			})
+
+			It("returns an error without looking anything up", func() {
+				_, err := migrator.FindLeftovers(24 * time.Hour)
+				Expect(err).To(MatchError("some-error"))
+				Expect(fakeClient.ListAppsCallCount()).To(BeZero())
+			})
+		})
+
+		When("the recorded recipients can not be listed", func() {
+			BeforeEach(func() {
+				fakeStateStore.RecipientsReturns(nil, errors.New("some-error"))
+			})
+
+			It("returns an error without looking anything up", func() {
+				_, err := migrator.FindLeftovers(24 * time.Hour)
+				Expect(err).To(MatchError("some-error"))
+				Expect(fakeClient.ListAppsCallCount()).To(BeZero())
+			})
+		})
+
+		When("the apps can not be listed", func() {
+			BeforeEach(func() {
+				fakeClient.ListAppsReturns(nil, errors.New("some-error"))
+			})
+
+			It("returns an error", func() {
+				_, err := migrator.FindLeftovers(24 * time.Hour)
+				Expect(err).To(MatchError("failed to list apps: some-error"))
+			})
+		})
+
+		When("the service instances can not be listed", func() {
+			BeforeEach(func() {
+				fakeClient.ListServiceInstancesReturns(nil, errors.New("some-error"))
+			})
+
+			It("returns an error", func() {
+				_, err := migrator.FindLeftovers(24 * time.Hour)
+				Expect(err).To(MatchError("failed to list service instances: some-error"))
+			})
+		})
+	})
+
+	Describe("ConfirmCleanup", func() {
+		It("confirms once 'delete' is typed", func() {
+			migrator.Input = strings.NewReader("delete\n")
+			Expect(migrator.ConfirmCleanup(2)).To(BeTrue())
+		})
+
+		It("does not confirm anything else", func() {
+			migrator.Input = strings.NewReader("y\n")
+			Expect(migrator.ConfirmCleanup(2)).To(BeFalse())
+		})
+	})
+
+	Describe("CleanUp", func() {
+		var leftovers []Leftover
+
+		BeforeEach(func() {
+			leftovers = []Leftover{
+				{Type: LeftoverApp, Name: "migrate-app-old"},
+				{Type: LeftoverServiceInstance, Name: "some-donor-new"},
+			}
+		})
+
+		It("deletes the apps and service instances", func() {
+			Expect(migrator.CleanUp(leftovers)).To(Succeed())
+
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+			Expect(fakeClient.DeleteAppArgsForCall(0)).To(Equal("migrate-app-old"))
+			Expect(fakeClient.DeleteServiceInstanceCallCount()).To(Equal(1))
+			Expect(fakeClient.DeleteServiceInstanceArgsForCall(0)).To(Equal("some-donor-new"))
+			Expect(fakeStateStore.ForgetRecipientCallCount()).To(Equal(1))
+			Expect(fakeStateStore.ForgetRecipientArgsForCall(0)).To(Equal("some-donor-new"))
+		})
+
+		When("deleting fails", func() {
+			BeforeEach(func() {
+				fakeClient.DeleteAppReturns(errors.New("some-error"))
+			})
+
+			It("carries on and returns an error", func() {
+				Expect(migrator.CleanUp(leftovers)).To(MatchError("failed to delete 1 of 2 apps and service instances"))
+				Expect(fakeClient.DeleteServiceInstanceCallCount()).To(Equal(1))
+			})
+		})
+	})
+})
@@ -0,0 +1,205 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Location is a service instance in a space of a saved target, or of the current target when Target is empty
+type Location struct {
+	Target       string
+	Space        string
+	InstanceName string
+}
+
+// ParseLocation parses a [<target>/]<space>/<instance> location
+func ParseLocation(value string) (Location, error) {
+	parts := strings.Split(value, "/")
+	for _, part := range parts {
+		if part == "" {
+			parts = nil
+			break
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return Location{Space: parts[0], InstanceName: parts[1]}, nil
+	case 3:
+		return Location{Target: parts[0], Space: parts[1], InstanceName: parts[2]}, nil
+	}
+
+	return Location{}, fmt.Errorf("invalid location %q, expected [<target>/]<space>/<instance>", value)
+}
+
+func (l Location) String() string {
+	if l.Target == "" {
+		return l.Space + "/" + l.InstanceName
+	}
+
+	return l.Target + "/" + l.Space + "/" + l.InstanceName
+}
+
+type CloneOptions struct {
+	Source      Location
+	Destination Location
+	// Force replaces the data of the destination without asking for confirmation
+	Force                 bool
+	Cleanup               bool
+	SkipTLSValidation     bool
+	IncludeStoredPrograms bool
+	// Definer is how the migration task rewrites the DEFINER of views and stored programs. Empty uses the task's
+	// default.
+	Definer string
+	// MaskingRules are the YAML masking rules applied to the rows copied
+	MaskingRules string
+}
+
+// Connector returns a Client operating on a space of a saved target, or of the current target when target is
+// empty, and a function releasing it
+type Connector func(target, space string) (client Client, release func(), err error)
+
+func NewCloner(connect Connector, unpacker Unpacker, store StateStore) *Cloner {
+	return &Cloner{
+		connect:  connect,
+		unpacker: unpacker,
+		store:    store,
+		Sleep:    time.Sleep,
+		Input:    os.Stdin,
+	}
+}
+
+// Cloner replaces the data of a service instance with a copy of another one, which may live in another space or
+// on another saved target
+type Cloner struct {
+	connect  Connector
+	unpacker Unpacker
+	store    StateStore
+	Sleep    func(time.Duration)
+	// Input is where the operator's confirmation of replacing the destination's data is read from
+	Input io.Reader
+}
+
+// Clone exposes the source to the destination's space as a user-provided service holding the credentials of a
+// service key, then runs the migration task there. The schemas of the destination are replaced by the ones of the
+// source, so the migration app must be able to reach the source's host. They are dropped before the copy, since the
+// destination's binding user can not create staging schemas to copy into, so a clone failing once the migration task
+// started leaves the destination empty or partially copied.
+func (c *Cloner) Clone(opts CloneOptions) error {
+	if opts.Source == opts.Destination {
+		return errors.New("the source and destination of a clone must be different service instances")
+	}
+
+	source, releaseSource, err := c.connect(opts.Source.Target, opts.Source.Space)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the source space: %w", err)
+	}
+	defer releaseSource()
+
+	destination, releaseDestination, err := c.connect(opts.Destination.Target, opts.Destination.Space)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the destination space: %w", err)
+	}
+	defer releaseDestination()
+
+	for _, l := range []struct {
+		client   Client
+		location Location
+	}{{source, opts.Source}, {destination, opts.Destination}} {
+		if !l.client.ServiceExists(l.location.InstanceName) {
+			return fmt.Errorf("Service instance %s not found", l.location)
+		}
+	}
+
+	if !opts.Force && !c.confirm(opts) {
+		return errors.New("clone cancelled")
+	}
+
+	id := uuid.NewString()
+	keyName := "clone-" + id
+	donorInstanceName := "clone-source-" + id
+
+	log.Printf("Creating service key %s for %s", keyName, opts.Source)
+	credentials, err := source.CreateServiceKey(opts.Source.InstanceName, keyName)
+	if err != nil {
+		return err
+	}
+
+	// Without cleanup, the migration app stays bound to the user-provided service, which needs the service key
+	if opts.Cleanup {
+		defer func() {
+			if err := source.DeleteServiceKey(opts.Source.InstanceName, keyName); err != nil {
+				log.Printf("Warning: %s", err)
+			}
+		}()
+	}
+
+	log.Printf("Creating user provided service %s in the destination space", donorInstanceName)
+	if err := destination.CreateUserProvidedService(donorInstanceName, credentials); err != nil {
+		return err
+	}
+
+	if opts.Cleanup {
+		defer func() {
+			if err := destination.DeleteServiceInstance(donorInstanceName); err != nil {
+				log.Printf("Warning: failed to delete user provided service %q: %s", donorInstanceName, err)
+			}
+		}()
+	}
+
+	migrator := NewMigrator(destination, c.unpacker, c.store, nil, nil, nil)
+	migrator.Sleep = c.Sleep
+	defer func() { _ = migrator.RemoveState(donorInstanceName) }()
+
+	err = migrator.MigrateData(context.Background(), MigrateOptions{
+		DonorInstanceName:     donorInstanceName,
+		RecipientInstanceName: opts.Destination.InstanceName,
+		Cleanup:               opts.Cleanup,
+		SkipTLSValidation:     opts.SkipTLSValidation,
+		IncludeStoredPrograms: opts.IncludeStoredPrograms,
+		Definer:               opts.Definer,
+		MaskingRules:          opts.MaskingRules,
+		ExistingRecipient:     true,
+		ReplaceRecipient:      true,
+	})
+	if err != nil {
+		if state, loadErr := migrator.LoadState(donorInstanceName); loadErr == nil && state.Reached(PhaseTaskStarted) {
+			return fmt.Errorf("%w. The data of %s was dropped before copying, so it may be empty or partially copied until the clone is run again or it is restored from a backup", err, opts.Destination)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (c *Cloner) confirm(opts CloneOptions) bool {
+	fmt.Printf("Cloning replaces the data of %s with a copy of %s.\n", opts.Destination, opts.Source)
+	fmt.Printf("The schemas of %s are dropped before copying. If the clone fails, its data is lost and can only be restored from a backup.\n", opts.Destination)
+	fmt.Printf("Type the name of the destination service instance to continue: ")
+
+	answer, _ := bufio.NewReader(c.Input).ReadString('\n')
+
+	return strings.TrimSpace(answer) == opts.Destination.InstanceName
+}
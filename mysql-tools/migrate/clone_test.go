@@ -0,0 +1,216 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("ParseLocation", func() {
+	It("parses a location in the current target", func() {
+		Expect(ParseLocation("some-space/some-instance")).
+			To(Equal(Location{Space: "some-space", InstanceName: "some-instance"}))
+	})
+
+	It("parses a location on a saved target", func() {
+		location, err := ParseLocation("some-target/some-space/some-instance")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(location).To(Equal(Location{Target: "some-target", Space: "some-space", InstanceName: "some-instance"}))
+		Expect(location.String()).To(Equal("some-target/some-space/some-instance"))
+	})
+
+	DescribeTable("rejects invalid locations",
+		func(value string) {
+			_, err := ParseLocation(value)
+			Expect(err).To(MatchError(`invalid location "` + value + `", expected [<target>/]<space>/<instance>`))
+		},
+		Entry("without a space", "some-instance"),
+		Entry("with too many parts", "a/b/c/d"),
+		Entry("with an empty part", "some-space/"),
+	)
+})
+
+var _ = Describe("Clone", func() {
+	var (
+		sourceClient      *migratefakes.FakeClient
+		destinationClient *migratefakes.FakeClient
+		fakeStateStore    *migratefakes.FakeStateStore
+		cloner            *Cloner
+		cloneOptions      CloneOptions
+		connections       []string
+		released          []string
+	)
+
+	BeforeEach(func() {
+		sourceClient = new(migratefakes.FakeClient)
+		sourceClient.ServiceExistsReturns(true)
+		sourceClient.CreateServiceKeyReturns(ServiceCredentials{Hostname: "some-host"}, nil)
+
+		destinationClient = new(migratefakes.FakeClient)
+		destinationClient.ServiceExistsReturns(true)
+		destinationClient.StartTaskReturns("some-task-guid", nil)
+		destinationClient.GetLogsReturns([]string{
+			`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"tables_copied":1,"tables_total":1,"done":true}`,
+		}, nil)
+
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		fakeStateStore.LoadReturns(State{}, ErrNoMigrationState)
+
+		connections, released = nil, nil
+		connect := func(target, space string) (Client, func(), error) {
+			connections = append(connections, target+"/"+space)
+			release := func() { released = append(released, target+"/"+space) }
+			if space == "production" {
+				return sourceClient, release, nil
+			}
+			return destinationClient, release, nil
+		}
+
+		cloner = NewCloner(connect, new(migratefakes.FakeUnpacker), fakeStateStore)
+		cloner.Sleep = func(time.Duration) {}
+		cloner.Input = strings.NewReader("staging-db\n")
+
+		cloneOptions = CloneOptions{
+			Source:      Location{Target: "prod-foundation", Space: "production", InstanceName: "prod-db"},
+			Destination: Location{Space: "staging", InstanceName: "staging-db"},
+			Cleanup:     true,
+		}
+	})
+
+	It("migrates the source into the destination through a service key", func() {
+		Expect(cloner.Clone(cloneOptions)).To(Succeed())
+
+		Expect(connections).To(Equal([]string{"prod-foundation/production", "/staging"}))
+		Expect(released).To(ConsistOf("prod-foundation/production", "/staging"))
+
+		Expect(sourceClient.CreateServiceKeyCallCount()).To(Equal(1))
+		instanceName, keyName := sourceClient.CreateServiceKeyArgsForCall(0)
+		Expect(instanceName).To(Equal("prod-db"))
+		Expect(keyName).To(HavePrefix("clone-"))
+
+		Expect(destinationClient.CreateUserProvidedServiceCallCount()).To(Equal(1))
+		donorName, credentials := destinationClient.CreateUserProvidedServiceArgsForCall(0)
+		Expect(donorName).To(HavePrefix("clone-source-"))
+		Expect(credentials).To(Equal(ServiceCredentials{Hostname: "some-host"}))
+
+		Expect(destinationClient.BindServiceCallCount()).To(Equal(2))
+		_, boundDonor := destinationClient.BindServiceArgsForCall(0)
+		Expect(boundDonor).To(Equal(donorName))
+		_, boundRecipient := destinationClient.BindServiceArgsForCall(1)
+		Expect(boundRecipient).To(Equal("staging-db"))
+
+		Expect(destinationClient.StartTaskCallCount()).To(Equal(1))
+		_, command := destinationClient.StartTaskArgsForCall(0)
+		Expect(command).To(Equal("migrate -replace-recipient " + donorName + " staging-db"))
+
+		By("cleaning up the app, the user provided service and the service key", func() {
+			Expect(destinationClient.DeleteAppCallCount()).To(Equal(1))
+			Expect(destinationClient.DeleteServiceInstanceCallCount()).To(Equal(1))
+			Expect(destinationClient.DeleteServiceInstanceArgsForCall(0)).To(Equal(donorName))
+			Expect(sourceClient.DeleteServiceKeyCallCount()).To(Equal(1))
+			_, deletedKey := sourceClient.DeleteServiceKeyArgsForCall(0)
+			Expect(deletedKey).To(Equal(keyName))
+			Expect(fakeStateStore.RemoveArgsForCall(0)).To(Equal(donorName))
+		})
+	})
+
+	It("passes the migration options to the task", func() {
+		cloneOptions.SkipTLSValidation = true
+		cloneOptions.IncludeStoredPrograms = true
+		cloneOptions.Definer = "invoker"
+
+		Expect(cloner.Clone(cloneOptions)).To(Succeed())
+
+		_, command := destinationClient.StartTaskArgsForCall(0)
+		Expect(command).To(MatchRegexp(`^migrate -skip-tls-validation -include-stored-programs -replace-recipient -definer='invoker' clone-source-\S+ staging-db$`))
+	})
+
+	It("tells the destination's data was dropped when the migration task failed", func() {
+		fakeStateStore.LoadReturnsOnCall(1, State{Phase: PhaseTaskStarted}, nil)
+		destinationClient.WaitForTaskReturns(errors.New("task failed"))
+
+		err := cloner.Clone(cloneOptions)
+		Expect(err).To(MatchError(ContainSubstring("task failed")))
+		Expect(err).To(MatchError(ContainSubstring("The data of staging/staging-db was dropped before copying")))
+	})
+
+	It("does not tell the destination's data was dropped when the migration task was not started", func() {
+		destinationClient.PushAppReturns(errors.New("push failed"))
+
+		err := cloner.Clone(cloneOptions)
+		Expect(err).To(MatchError(ContainSubstring("push failed")))
+		Expect(err).NotTo(MatchError(ContainSubstring("was dropped")))
+	})
+
+	It("does not replace the destination's data unless its name is confirmed", func() {
+		cloner.Input = strings.NewReader("yes\n")
+
+		Expect(cloner.Clone(cloneOptions)).To(MatchError("clone cancelled"))
+		Expect(sourceClient.CreateServiceKeyCallCount()).To(BeZero())
+	})
+
+	It("does not ask for confirmation when forced", func() {
+		cloner.Input = strings.NewReader("")
+		cloneOptions.Force = true
+
+		Expect(cloner.Clone(cloneOptions)).To(Succeed())
+	})
+
+	It("refuses to clone a service instance onto itself", func() {
+		cloneOptions.Destination = cloneOptions.Source
+
+		Expect(cloner.Clone(cloneOptions)).
+			To(MatchError("the source and destination of a clone must be different service instances"))
+		Expect(connections).To(BeEmpty())
+	})
+
+	It("returns an error when the destination does not exist", func() {
+		destinationClient.ServiceExistsReturns(false)
+
+		Expect(cloner.Clone(cloneOptions)).To(MatchError("Service instance staging/staging-db not found"))
+	})
+
+	It("returns an error when a space can not be connected to", func() {
+		cloner = NewCloner(func(string, string) (Client, func(), error) {
+			return nil, nil, errors.New("some-error")
+		}, nil, nil)
+
+		Expect(cloner.Clone(cloneOptions)).To(MatchError("failed to connect to the source space: some-error"))
+	})
+
+	It("cleans up when the migration fails", func() {
+		destinationClient.StartTaskReturns("", errors.New("some-task-error"))
+
+		Expect(cloner.Clone(cloneOptions)).To(MatchError("some-task-error"))
+		Expect(destinationClient.DeleteServiceInstanceCallCount()).To(Equal(1))
+		Expect(sourceClient.DeleteServiceKeyCallCount()).To(Equal(1))
+	})
+
+	It("keeps the user provided service and the service key without cleanup", func() {
+		cloneOptions.Cleanup = false
+
+		Expect(cloner.Clone(cloneOptions)).To(Succeed())
+		Expect(destinationClient.DeleteAppCallCount()).To(BeZero())
+		Expect(destinationClient.DeleteServiceInstanceCallCount()).To(BeZero())
+		Expect(sourceClient.DeleteServiceKeyCallCount()).To(BeZero())
+	})
+})
@@ -0,0 +1,105 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cli/cf/configuration/confighelpers"
+)
+
+var ErrNoCutover = errors.New("no cutover recorded")
+
+// Cutover records how a migration swapped the names of the donor and the recipient, so that it can be rolled back
+type Cutover struct {
+	// InstanceName is the name the recipient took over from the donor
+	InstanceName string
+	// OldInstanceName is the name the donor was renamed to
+	OldInstanceName string
+	// RecipientInstanceName is the name of the recipient before the cutover
+	RecipientInstanceName string
+	// RecipientFingerprint is the fingerprint of the recipient's data once it was migrated. It is empty when the
+	// recipient could not be fingerprinted.
+	RecipientFingerprint string
+	CutOverAt            time.Time
+}
+
+//counterfeiter:generate . CutoverStore
+type CutoverStore interface {
+	Load(instanceName string) (Cutover, error)
+	Save(cutover Cutover) error
+	Remove(instanceName string) error
+}
+
+// FileCutoverStore persists one json document per service instance that took over the name of a donor
+type FileCutoverStore struct {
+	Dir string
+}
+
+func NewFileCutoverStore() FileCutoverStore {
+	return FileCutoverStore{
+		Dir: filepath.Join(confighelpers.PluginRepoDir(), ".cf", ".mysql-tools-cutovers"),
+	}
+}
+
+func (s FileCutoverStore) Load(instanceName string) (Cutover, error) {
+	contents, err := os.ReadFile(s.path(instanceName))
+	if errors.Is(err, os.ErrNotExist) {
+		return Cutover{}, ErrNoCutover
+	}
+	if err != nil {
+		return Cutover{}, fmt.Errorf("failed to read the cutover of %s: %w", instanceName, err)
+	}
+
+	var cutover Cutover
+	if err := json.Unmarshal(contents, &cutover); err != nil {
+		return Cutover{}, fmt.Errorf("failed to parse the cutover of %s: %w", instanceName, err)
+	}
+
+	return cutover, nil
+}
+
+func (s FileCutoverStore) Save(cutover Cutover) error {
+	if err := os.MkdirAll(s.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cutover directory: %w", err)
+	}
+
+	contents, err := json.MarshalIndent(cutover, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cutover: %w", err)
+	}
+
+	if err := writeFileAtomically(s.path(cutover.InstanceName), contents); err != nil {
+		return fmt.Errorf("failed to write cutover: %w", err)
+	}
+
+	return nil
+}
+
+func (s FileCutoverStore) Remove(instanceName string) error {
+	if err := os.Remove(s.path(instanceName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the cutover of %s: %w", instanceName, err)
+	}
+
+	return nil
+}
+
+func (s FileCutoverStore) path(instanceName string) string {
+	return filepath.Join(s.Dir, url.PathEscape(instanceName)+".json")
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+var _ = Describe("FileCutoverStore", func() {
+	var store FileCutoverStore
+
+	BeforeEach(func() {
+		store = FileCutoverStore{Dir: filepath.Join(GinkgoT().TempDir(), "cutovers")}
+	})
+
+	It("saves, loads and removes the cutover of a migration", func() {
+		cutover := Cutover{
+			InstanceName:          "some-instance",
+			OldInstanceName:       "some-instance-old",
+			RecipientInstanceName: "some-instance-new",
+			RecipientFingerprint:  "some-fingerprint",
+			CutOverAt:             time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		Expect(store.Save(cutover)).To(Succeed())
+		Expect(store.Load("some-instance")).To(Equal(cutover))
+
+		info, err := os.Stat(filepath.Join(store.Dir, "some-instance.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		Expect(store.Remove("some-instance")).To(Succeed())
+		_, err = store.Load("some-instance")
+		Expect(err).To(MatchError(ErrNoCutover))
+	})
+
+	It("returns an error when the cutover file is corrupt", func() {
+		Expect(os.MkdirAll(store.Dir, 0700)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(store.Dir, "some-instance.json"), []byte("{"), 0600)).To(Succeed())
+
+		_, err := store.Load("some-instance")
+		Expect(err).To(MatchError(ContainSubstring("failed to parse the cutover of some-instance")))
+	})
+})
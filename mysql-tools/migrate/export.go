@@ -0,0 +1,124 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+type ExportOptions struct {
+	InstanceName string
+	// Destination is a local file, or an s3://<bucket>/<key> URL the migration app uploads the dump to
+	Destination string
+	// S3Endpoint and S3Region locate the object store of an s3:// destination. Empty uses the task's defaults.
+	S3Endpoint    string
+	S3Region      string
+	S3Credentials s3.Credentials
+	// Passphrase encrypts the dump. Empty only compresses it.
+	Passphrase        string
+	SkipTLSValidation bool
+	Cleanup           bool
+}
+
+// Export writes a compressed dump of a service instance to a local file or an S3-compatible object store using a
+// migration app. Dumps to S3 are uploaded by a task, while dumps to a local file are streamed out of the app over
+// cf ssh. The passphrase and S3 credentials are passed to the app as environment variables, rather than on the
+// command line.
+func (m *Migrator) Export(opts ExportOptions) error {
+	if err := m.pushHelperApp(); err != nil {
+		return err
+	}
+
+	if opts.Cleanup {
+		defer m.deleteHelperApp()
+	}
+
+	if err := m.startHelperApp(opts.InstanceName, secretEnv(opts.Passphrase, opts.Destination, opts.S3Credentials)); err != nil {
+		return err
+	}
+
+	var err error
+	if s3.IsURL(opts.Destination) {
+		log.Print("Started to run export task")
+		_, err = m.runTask(context.Background(), exportTaskCommand(opts, opts.Destination))
+	} else {
+		err = m.exportToFile(opts)
+	}
+
+	if err != nil {
+		log.Printf("Export failed: %s", err)
+		return err
+	}
+
+	log.Printf("Exported %s to %s", opts.InstanceName, opts.Destination)
+
+	return nil
+}
+
+// exportToFile streams the dump into a temporary file next to the destination, which replaces the destination
+// once the dump completed
+func (m *Migrator) exportToFile(opts ExportOptions) error {
+	f, err := os.CreateTemp(filepath.Dir(opts.Destination), filepath.Base(opts.Destination)+".*.partial")
+	if err != nil {
+		return fmt.Errorf("failed to create a file next to %s: %w", opts.Destination, err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	output := &taskOutput{}
+	log.Print("Started to stream the export")
+	err = m.client.RunSSH(m.appName, exportTaskCommand(opts, "-"), nil, f, output)
+	output.flush()
+	if err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Destination, err)
+	}
+
+	if err := os.Rename(f.Name(), opts.Destination); err != nil {
+		return fmt.Errorf("failed to write %s: %w", opts.Destination, err)
+	}
+
+	return nil
+}
+
+func exportTaskCommand(opts ExportOptions, destination string) string {
+	args := []string{"migrate", "export"}
+
+	if opts.SkipTLSValidation {
+		args = append(args, "-skip-tls-validation")
+	}
+
+	if opts.S3Endpoint != "" {
+		args = append(args, "-s3-endpoint="+shellQuote(opts.S3Endpoint))
+	}
+
+	if opts.S3Region != "" {
+		args = append(args, "-s3-region="+shellQuote(opts.S3Region))
+	}
+
+	args = append(args, "-to="+shellQuote(destination), opts.InstanceName)
+
+	return strings.Join(args, " ")
+}
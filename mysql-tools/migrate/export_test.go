@@ -0,0 +1,187 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		fakeClient    *migratefakes.FakeClient
+		fakeUnpacker  *migratefakes.FakeUnpacker
+		migrator      *Migrator
+		exportOptions ExportOptions
+		exportDir     string
+	)
+
+	BeforeEach(func() {
+		exportDir = GinkgoT().TempDir()
+		exportOptions = ExportOptions{
+			InstanceName: "some-instance",
+			Destination:  filepath.Join(exportDir, "backup.sql.gz"),
+			Cleanup:      true,
+		}
+		fakeClient = new(migratefakes.FakeClient)
+		fakeUnpacker = new(migratefakes.FakeUnpacker)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, nil, nil, nil, nil)
+		migrator.Sleep = func(time.Duration) {}
+
+		fakeClient.RunSSHStub = func(_, _ string, _ io.Reader, stdout, stderr io.Writer) error {
+			_, _ = io.WriteString(stdout, "some-dump")
+			_, _ = io.WriteString(stderr, "PROGRESS {\"tables_copied\":1,\"tables_total\":1,\"done\":true}\n")
+			return nil
+		}
+	})
+
+	Context("when exporting to a local file", func() {
+		It("streams the dump out of the migration app", func() {
+			Expect(migrator.Export(exportOptions)).To(Succeed())
+
+			By("pushing and binding the migration app", func() {
+				Expect(fakeUnpacker.UnpackCallCount()).To(Equal(1))
+				Expect(fakeClient.PushAppCallCount()).To(Equal(1))
+				_, appName := fakeClient.PushAppArgsForCall(0)
+				Expect(appName).To(HavePrefix("migrate-app-"))
+
+				Expect(fakeClient.BindServiceCallCount()).To(Equal(1))
+				boundApp, instance := fakeClient.BindServiceArgsForCall(0)
+				Expect(boundApp).To(Equal(appName))
+				Expect(instance).To(Equal("some-instance"))
+
+				Expect(fakeClient.SetEnvCallCount()).To(BeZero())
+				Expect(fakeClient.StartAppCallCount()).To(Equal(1))
+			})
+
+			By("running the export over ssh", func() {
+				Expect(fakeClient.StartTaskCallCount()).To(BeZero())
+				Expect(fakeClient.RunSSHCallCount()).To(Equal(1))
+				_, command, _, _, _ := fakeClient.RunSSHArgsForCall(0)
+				Expect(command).To(Equal("migrate export -to='-' some-instance"))
+			})
+
+			By("writing the dump to the destination", func() {
+				Expect(os.ReadFile(exportOptions.Destination)).To(BeEquivalentTo("some-dump"))
+				Expect(filepath.Glob(filepath.Join(exportDir, "*.partial"))).To(BeEmpty())
+			})
+
+			By("cleaning up the migration app", func() {
+				Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+			})
+		})
+
+		It("passes the passphrase to the migration app", func() {
+			exportOptions.Passphrase = "some-passphrase"
+
+			Expect(migrator.Export(exportOptions)).To(Succeed())
+
+			Expect(fakeClient.SetEnvCallCount()).To(Equal(1))
+			_, name, value := fakeClient.SetEnvArgsForCall(0)
+			Expect(name).To(Equal("MYSQL_TOOLS_PASSPHRASE"))
+			Expect(value).To(Equal("some-passphrase"))
+		})
+
+		It("does not write an incomplete dump when the export fails", func() {
+			fakeClient.RunSSHStub = func(_, _ string, _ io.Reader, stdout, _ io.Writer) error {
+				_, _ = io.WriteString(stdout, "some-partial-dump")
+				return errors.New("some-ssh-error")
+			}
+
+			err := migrator.Export(exportOptions)
+			Expect(err).To(MatchError("some-ssh-error"))
+
+			Expect(exportOptions.Destination).NotTo(BeAnExistingFile())
+			Expect(filepath.Glob(filepath.Join(exportDir, "*.partial"))).To(BeEmpty())
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		})
+
+		It("does not cleanup when told not to", func() {
+			exportOptions.Cleanup = false
+
+			Expect(migrator.Export(exportOptions)).To(Succeed())
+			Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when exporting to S3", func() {
+		BeforeEach(func() {
+			exportOptions.Destination = "s3://some-bucket/some/key.sql.gz"
+			exportOptions.S3Endpoint = "https://minio.example.com"
+			exportOptions.S3Region = "some-region"
+			exportOptions.S3Credentials = s3.Credentials{AccessKeyID: "some-key-id", SecretAccessKey: "some-secret"}
+			exportOptions.SkipTLSValidation = true
+
+			fakeClient.StartTaskReturns("some-task-guid", nil)
+			fakeClient.GetLogsReturns([]string{
+				`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"tables_copied":1,"tables_total":1,"done":true}`,
+			}, nil)
+		})
+
+		It("uploads the dump from a task", func() {
+			Expect(migrator.Export(exportOptions)).To(Succeed())
+
+			Expect(fakeClient.SetEnvCallCount()).To(Equal(2))
+			_, name, value := fakeClient.SetEnvArgsForCall(0)
+			Expect([]string{name, value}).To(Equal([]string{"AWS_ACCESS_KEY_ID", "some-key-id"}))
+			_, name, value = fakeClient.SetEnvArgsForCall(1)
+			Expect([]string{name, value}).To(Equal([]string{"AWS_SECRET_ACCESS_KEY", "some-secret"}))
+
+			Expect(fakeClient.RunSSHCallCount()).To(BeZero())
+			Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+			_, command := fakeClient.StartTaskArgsForCall(0)
+			Expect(command).To(Equal("migrate export -skip-tls-validation -s3-endpoint='https://minio.example.com' -s3-region='some-region' -to='s3://some-bucket/some/key.sql.gz' some-instance"))
+
+			Expect(fakeClient.WaitForTaskCallCount()).To(Equal(1))
+			_, taskGUID, _ := fakeClient.WaitForTaskArgsForCall(0)
+			Expect(taskGUID).To(Equal("some-task-guid"))
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when the task fails", func() {
+			fakeClient.WaitForTaskReturns(errors.New("some-task-error"))
+
+			err := migrator.Export(exportOptions)
+			Expect(err).To(MatchError("some-task-error"))
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		})
+	})
+
+	It("returns an error when setting the environment of the app fails", func() {
+		exportOptions.Passphrase = "some-passphrase"
+		fakeClient.SetEnvReturns(errors.New("some-set-env-error"))
+
+		err := migrator.Export(exportOptions)
+		Expect(err).To(MatchError("some-set-env-error"))
+		Expect(fakeClient.StartAppCallCount()).To(BeZero())
+		Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+	})
+
+	It("returns an error when binding the instance fails", func() {
+		fakeClient.BindServiceReturns(errors.New("some-bind-error"))
+
+		err := migrator.Export(exportOptions)
+		Expect(err).To(MatchError(ContainSubstring("some-bind-error")))
+		Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+	})
+})
@@ -0,0 +1,25 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import "os"
+
+// writeFileAtomically writes contents to a temporary file next to path, then renames it to path, so that an
+// interrupted write never leaves a truncated file behind. Only the current user can read the file.
+func writeFileAtomically(path string, contents []byte) error {
+	if err := os.WriteFile(path+".tmp", contents, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(path+".tmp", path)
+}
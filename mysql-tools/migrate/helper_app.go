@@ -0,0 +1,163 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+// envVar is an environment variable set on a migration app, whose migrate binary runs exports and imports against
+// the single service instance bound to it
+type envVar struct {
+	name, value string
+}
+
+// secretEnv is the environment passing the passphrase of a dump, and the credentials of the object store holding
+// it, to the migration app
+func secretEnv(passphrase, location string, s3Credentials s3.Credentials) []envVar {
+	var env []envVar
+
+	if passphrase != "" {
+		env = append(env, envVar{archive.PassphraseEnv, passphrase})
+	}
+
+	if s3.IsURL(location) {
+		env = append(env,
+			envVar{s3.AccessKeyIDEnv, s3Credentials.AccessKeyID},
+			envVar{s3.SecretAccessKeyEnv, s3Credentials.SecretAccessKey},
+		)
+	}
+
+	return env
+}
+
+// pushHelperApp pushes a migration app without starting it
+func (m *Migrator) pushHelperApp() error {
+	tmpDir, err := ioutil.TempDir(os.TempDir(), "migrate_app_")
+	if err != nil {
+		return fmt.Errorf("Error creating temp directory: %s", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	log.Printf("Unpacking assets for the migration app to %s", tmpDir)
+	if err = m.unpacker.Unpack(tmpDir, app.Settings{}); err != nil {
+		return fmt.Errorf("Error extracting migrate assets: %s", err)
+	}
+
+	m.appName = migrationAppPrefix + uuid.NewString()
+
+	log.Print("Started to push app")
+	if err = m.client.PushApp(tmpDir, m.appName); err != nil {
+		return fmt.Errorf("failed to push application: %s", err)
+	}
+	log.Print("Successfully pushed app")
+
+	return nil
+}
+
+// startHelperApp binds the migration app to a service instance and starts it. Secrets are set in its environment,
+// rather than passed on the command line.
+func (m *Migrator) startHelperApp(instanceName string, env []envVar) error {
+	if err := m.client.BindService(m.appName, instanceName); err != nil {
+		return fmt.Errorf("failed to bind-service %q to application %q: %s", m.appName, instanceName, err)
+	}
+	log.Printf("Successfully bound app to %s", instanceName)
+
+	for _, v := range env {
+		if err := m.client.SetEnv(m.appName, v.name, v.value); err != nil {
+			return err
+		}
+	}
+
+	log.Print("Starting migration app")
+	if err := m.client.StartApp(m.appName); err != nil {
+		return fmt.Errorf("failed to start application %q: %s", m.appName, err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) deleteHelperApp() {
+	m.client.DeleteApp(m.appName)
+	log.Print("Cleaning up...")
+}
+
+// runTask runs a command as a task of the migration app, printing its logs. It returns the progress the task
+// reported once it was done. The task is cancelled when ctx is done.
+func (m *Migrator) runTask(ctx context.Context, command string) (progress.Update, error) {
+	taskGUID, err := m.client.StartTask(m.appName, command)
+	if err != nil {
+		_ = m.outputMigrationLogs("")
+		return progress.Update{}, err
+	}
+
+	logs := m.newTaskLogs()
+	if err := m.client.WaitForTask(ctx, taskGUID, logs.poller()); err != nil {
+		logs.flush("")
+		return progress.Update{}, err
+	}
+
+	logs.flush(taskLogFilter)
+
+	return logs.final, nil
+}
+
+// taskOutput prints the lines a command run over cf ssh writes to stderr, rendering progress lines like the logs
+// of a task
+type taskOutput struct {
+	buf []byte
+}
+
+func (o *taskOutput) Write(p []byte) (int, error) {
+	o.buf = append(o.buf, p...)
+
+	for {
+		i := bytes.IndexByte(o.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		o.printLine(string(o.buf[:i]))
+		o.buf = o.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (o *taskOutput) flush() {
+	if len(o.buf) > 0 {
+		o.printLine(string(o.buf))
+		o.buf = nil
+	}
+}
+
+func (o *taskOutput) printLine(line string) {
+	if update, ok := progress.Parse(line); ok {
+		log.Print(FormatProgress(update))
+		return
+	}
+
+	log.Print(line)
+}
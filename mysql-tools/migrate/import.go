@@ -0,0 +1,165 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+type ImportOptions struct {
+	InstanceName string
+	// Source is a local dump file, or an s3://<bucket>/<key> URL the migration app downloads the dump from
+	Source string
+	// S3Endpoint and S3Region locate the object store of an s3:// source. Empty uses the task's defaults.
+	S3Endpoint    string
+	S3Region      string
+	S3Credentials s3.Credentials
+	// Passphrase decrypts a dump encrypted by an export
+	Passphrase string
+	// Definer is how the definers of views and stored programs are rewritten ("invoker" or <user>@<host>). Empty
+	// uses the task's default.
+	Definer string
+	// Schemas are <from>=<to> mappings renaming the schemas of the dump
+	Schemas           []string
+	SkipTLSValidation bool
+	Cleanup           bool
+}
+
+// Import loads a dump into a service instance using a migration app. Dumps in S3 are downloaded by a task, while
+// local dumps are streamed into the app over cf ssh.
+func (m *Migrator) Import(opts ImportOptions) error {
+	var (
+		dump *os.File
+		size int64
+	)
+
+	// A missing dump is reported before anything gets pushed
+	if !s3.IsURL(opts.Source) {
+		var err error
+		if dump, size, err = openDump(opts.Source); err != nil {
+			return err
+		}
+		defer dump.Close()
+	}
+
+	if err := m.pushHelperApp(); err != nil {
+		return err
+	}
+
+	if opts.Cleanup {
+		defer m.deleteHelperApp()
+	}
+
+	if err := m.startHelperApp(opts.InstanceName, secretEnv(opts.Passphrase, opts.Source, opts.S3Credentials)); err != nil {
+		return err
+	}
+
+	var err error
+	if dump == nil {
+		log.Print("Started to run import task")
+		_, err = m.runTask(context.Background(), importTaskCommand(opts, opts.Source))
+	} else {
+		err = m.importFromFile(opts, dump, size)
+	}
+
+	if err != nil {
+		log.Printf("Import failed: %s", err)
+		return err
+	}
+
+	log.Printf("Imported %s into %s", opts.Source, opts.InstanceName)
+
+	return nil
+}
+
+func openDump(path string) (*os.File, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+
+	return f, info.Size(), nil
+}
+
+func (m *Migrator) importFromFile(opts ImportOptions, dump io.Reader, size int64) error {
+	stdout, stderr := &taskOutput{}, &taskOutput{}
+	defer stdout.flush()
+	defer stderr.flush()
+
+	log.Print("Started to stream the import")
+	return m.client.RunSSH(m.appName, importTaskCommand(opts, "-"), &uploadProgress{r: dump, total: size}, stdout, stderr)
+}
+
+func importTaskCommand(opts ImportOptions, source string) string {
+	args := []string{"migrate", "import"}
+
+	if opts.SkipTLSValidation {
+		args = append(args, "-skip-tls-validation")
+	}
+
+	if opts.S3Endpoint != "" {
+		args = append(args, "-s3-endpoint="+shellQuote(opts.S3Endpoint))
+	}
+
+	if opts.S3Region != "" {
+		args = append(args, "-s3-region="+shellQuote(opts.S3Region))
+	}
+
+	if opts.Definer != "" {
+		args = append(args, "-definer="+shellQuote(opts.Definer))
+	}
+
+	for _, mapping := range opts.Schemas {
+		args = append(args, "-schema="+shellQuote(mapping))
+	}
+
+	args = append(args, "-from="+shellQuote(source), opts.InstanceName)
+
+	return strings.Join(args, " ")
+}
+
+// uploadProgress logs how much of a dump was streamed to the migration app every logPollInterval
+type uploadProgress struct {
+	r           io.Reader
+	read, total int64
+	lastLog     time.Time
+	done        bool
+}
+
+func (u *uploadProgress) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+
+	if (errors.Is(err, io.EOF) && !u.done) || time.Since(u.lastLog) >= logPollInterval {
+		u.done = errors.Is(err, io.EOF)
+		u.lastLog = time.Now()
+		log.Printf("Progress: uploaded %s of %s", ByteSize(u.read), ByteSize(u.total))
+	}
+
+	return n, err
+}
@@ -0,0 +1,161 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+var _ = Describe("Import", func() {
+	var (
+		fakeClient    *migratefakes.FakeClient
+		fakeUnpacker  *migratefakes.FakeUnpacker
+		migrator      *Migrator
+		importOptions ImportOptions
+		uploaded      []byte
+		logOutput     *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		dumpPath := filepath.Join(GinkgoT().TempDir(), "backup.sql.gz")
+		Expect(os.WriteFile(dumpPath, []byte("some-dump"), 0600)).To(Succeed())
+
+		importOptions = ImportOptions{
+			InstanceName: "some-instance",
+			Source:       dumpPath,
+			Cleanup:      true,
+		}
+		fakeClient = new(migratefakes.FakeClient)
+		fakeUnpacker = new(migratefakes.FakeUnpacker)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, nil, nil, nil, nil)
+		migrator.Sleep = func(time.Duration) {}
+
+		uploaded = nil
+		fakeClient.RunSSHStub = func(_, _ string, stdin io.Reader, _, _ io.Writer) error {
+			var err error
+			uploaded, err = io.ReadAll(stdin)
+			return err
+		}
+
+		logOutput = &bytes.Buffer{}
+		log.SetOutput(io.MultiWriter(GinkgoWriter, logOutput))
+		DeferCleanup(log.SetOutput, os.Stderr)
+	})
+
+	Context("when importing a local file", func() {
+		It("streams the dump into the migration app", func() {
+			Expect(migrator.Import(importOptions)).To(Succeed())
+
+			By("pushing and binding the migration app", func() {
+				Expect(fakeClient.PushAppCallCount()).To(Equal(1))
+				Expect(fakeClient.BindServiceCallCount()).To(Equal(1))
+				_, instance := fakeClient.BindServiceArgsForCall(0)
+				Expect(instance).To(Equal("some-instance"))
+				Expect(fakeClient.SetEnvCallCount()).To(BeZero())
+				Expect(fakeClient.StartAppCallCount()).To(Equal(1))
+			})
+
+			By("running the import over ssh", func() {
+				Expect(fakeClient.StartTaskCallCount()).To(BeZero())
+				Expect(fakeClient.RunSSHCallCount()).To(Equal(1))
+				_, command, _, _, _ := fakeClient.RunSSHArgsForCall(0)
+				Expect(command).To(Equal("migrate import -from='-' some-instance"))
+				Expect(uploaded).To(BeEquivalentTo("some-dump"))
+				Expect(logOutput.String()).To(ContainSubstring("Progress: uploaded 9 B of 9 B"))
+			})
+
+			By("cleaning up the migration app", func() {
+				Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+			})
+		})
+
+		It("passes the definer policy, schema mappings and passphrase to the migration app", func() {
+			importOptions.Definer = "`app`@`%`"
+			importOptions.Schemas = []string{"sakila=pagila", "app=app_v2"}
+			importOptions.Passphrase = "some-passphrase"
+			importOptions.SkipTLSValidation = true
+
+			Expect(migrator.Import(importOptions)).To(Succeed())
+
+			_, command, _, _, _ := fakeClient.RunSSHArgsForCall(0)
+			Expect(command).To(Equal("migrate import -skip-tls-validation -definer='`app`@`%`' -schema='sakila=pagila' -schema='app=app_v2' -from='-' some-instance"))
+
+			Expect(fakeClient.SetEnvCallCount()).To(Equal(1))
+			_, name, value := fakeClient.SetEnvArgsForCall(0)
+			Expect([]string{name, value}).To(Equal([]string{"MYSQL_TOOLS_PASSPHRASE", "some-passphrase"}))
+		})
+
+		It("fails before pushing anything when the dump does not exist", func() {
+			importOptions.Source = filepath.Join(GinkgoT().TempDir(), "missing.sql.gz")
+
+			err := migrator.Import(importOptions)
+			Expect(err).To(MatchError(os.ErrNotExist))
+			Expect(fakeClient.PushAppCallCount()).To(BeZero())
+		})
+
+		It("returns an error when the import fails", func() {
+			fakeClient.RunSSHReturns(errors.New("some-ssh-error"))
+			fakeClient.RunSSHStub = nil
+
+			err := migrator.Import(importOptions)
+			Expect(err).To(MatchError("some-ssh-error"))
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when importing from S3", func() {
+		BeforeEach(func() {
+			importOptions.Source = "s3://some-bucket/some/key.sql.gz"
+			importOptions.S3Endpoint = "https://minio.example.com"
+			importOptions.S3Credentials = s3.Credentials{AccessKeyID: "some-key-id", SecretAccessKey: "some-secret"}
+
+			fakeClient.StartTaskReturns("some-task-guid", nil)
+		})
+
+		It("downloads the dump from a task", func() {
+			Expect(migrator.Import(importOptions)).To(Succeed())
+
+			Expect(fakeClient.SetEnvCallCount()).To(Equal(2))
+			_, name, _ := fakeClient.SetEnvArgsForCall(0)
+			Expect(name).To(Equal("AWS_ACCESS_KEY_ID"))
+			_, name, _ = fakeClient.SetEnvArgsForCall(1)
+			Expect(name).To(Equal("AWS_SECRET_ACCESS_KEY"))
+
+			Expect(fakeClient.RunSSHCallCount()).To(BeZero())
+			_, command := fakeClient.StartTaskArgsForCall(0)
+			Expect(command).To(Equal("migrate import -s3-endpoint='https://minio.example.com' -from='s3://some-bucket/some/key.sql.gz' some-instance"))
+			Expect(fakeClient.WaitForTaskCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when the task fails", func() {
+			fakeClient.WaitForTaskReturns(errors.New("some-task-error"))
+
+			err := migrator.Import(importOptions)
+			Expect(err).To(MatchError("some-task-error"))
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -0,0 +1,124 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// MySQLDonorInspector connects to the donor directly, so it requires network access to the donor from this machine
+type MySQLDonorInspector struct {
+	Timeout time.Duration
+}
+
+func NewMySQLDonorInspector() MySQLDonorInspector {
+	return MySQLDonorInspector{Timeout: 10 * time.Second}
+}
+
+func (i MySQLDonorInspector) Inspect(credentials ServiceCredentials, opts MigrateOptions) (DonorSummary, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = credentials.Username
+	cfg.Passwd = credentials.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(credentials.Hostname, strconv.Itoa(credentials.Port))
+	cfg.DBName = credentials.Name
+	cfg.Timeout = i.Timeout
+
+	if credentials.CA != "" {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM([]byte(credentials.CA)) {
+			return DonorSummary{}, errors.New("failed to parse the CA certificate of the service key")
+		}
+
+		cfg.TLS = &tls.Config{
+			RootCAs:            rootCAs,
+			ServerName:         credentials.Hostname,
+			InsecureSkipVerify: opts.SkipTLSValidation,
+		}
+	}
+
+	connector, err := mysql.NewConnector(cfg)
+	if err != nil {
+		return DonorSummary{}, fmt.Errorf("failed to configure the donor connection: %w", err)
+	}
+
+	db := sql.OpenDB(connector)
+	defer func() { _ = db.Close() }()
+
+	return InspectDonor(db, opts)
+}
+
+// InspectDonor runs the same discovery as the migration task, and additionally reports on the data size
+// and on objects that the migration will not carry over
+func InspectDonor(db *sql.DB, opts MigrateOptions) (DonorSummary, error) {
+	var (
+		summary DonorSummary
+		err     error
+	)
+
+	schemas, err := discovery.DiscoverDatabases(db)
+	if err != nil {
+		return DonorSummary{}, fmt.Errorf("failed to discover schemas: %w", err)
+	}
+	summary.Schemas = opts.Filter.Schemas(schemas)
+
+	if summary.ExcludedTables, err = opts.Filter.ExcludedTables(db, summary.Schemas); err != nil {
+		return DonorSummary{}, fmt.Errorf("failed to apply the table filters: %w", err)
+	}
+
+	invalidViews, err := discovery.DiscoverInvalidViews(db, summary.Schemas)
+	if err != nil {
+		return DonorSummary{}, fmt.Errorf("failed to discover invalid views: %w", err)
+	}
+	for _, v := range invalidViews {
+		summary.InvalidViews = append(summary.InvalidViews, v.String())
+	}
+
+	if summary.DataSizeBytes, err = discovery.DiscoverDataSize(db, summary.Schemas); err != nil {
+		return DonorSummary{}, fmt.Errorf("failed to discover the data size: %w", err)
+	}
+
+	nonInnoDBTables, err := discovery.DiscoverNonInnoDBTables(db, summary.Schemas)
+	if err != nil {
+		return DonorSummary{}, fmt.Errorf("failed to discover storage engines: %w", err)
+	}
+	for _, t := range nonInnoDBTables {
+		summary.UnsupportedObjects = append(summary.UnsupportedObjects, "non-InnoDB table "+t)
+	}
+
+	if !opts.IncludeStoredPrograms {
+		programs, err := discovery.DiscoverStoredPrograms(db, summary.Schemas)
+		if err != nil {
+			return DonorSummary{}, fmt.Errorf("failed to discover stored programs: %w", err)
+		}
+		for _, p := range programs {
+			if p.Table != "" && !opts.Filter.TableIncluded(p.Schema, p.Table) {
+				continue
+			}
+			summary.UnsupportedObjects = append(summary.UnsupportedObjects, p.String()+" (pass --include-stored-programs)")
+		}
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+var _ = Describe("InspectDonor", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		mock.ExpectQuery(`SHOW DATABASES`).
+			WillReturnRows(sqlmock.NewRows([]string{"Database"}).AddRow("mysql").AddRow("foo"))
+		mock.ExpectQuery(`SELECT table_name from INFORMATION_SCHEMA.VIEWS`).
+			WithArgs("foo").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0)`)).
+			WithArgs("foo").
+			WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(4096))
+		mock.ExpectQuery(`SELECT TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES`).
+			WithArgs("foo").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "ENGINE"}).AddRow("legacy", "MyISAM"))
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("reports stored programs as unsupported unless they are migrated", func() {
+		mock.ExpectQuery(`SELECT ROUTINE_NAME, ROUTINE_TYPE, '' FROM INFORMATION_SCHEMA.ROUTINES`).
+			WithArgs("foo", "foo", "foo").
+			WillReturnRows(sqlmock.NewRows([]string{"name", "type", "table"}).AddRow("ins_film", "TRIGGER", "film"))
+
+		summary, err := InspectDonor(db, MigrateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(DonorSummary{
+			Schemas:       []string{"foo"},
+			DataSizeBytes: 4096,
+			UnsupportedObjects: []string{
+				"non-InnoDB table foo.legacy (MyISAM)",
+				"TRIGGER foo.ins_film (pass --include-stored-programs)",
+			},
+		}))
+	})
+
+	It("does not look up stored programs when they are migrated", func() {
+		summary, err := InspectDonor(db, MigrateOptions{IncludeStoredPrograms: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.UnsupportedObjects).To(Equal([]string{"non-InnoDB table foo.legacy (MyISAM)"}))
+	})
+
+	It("returns an error when discovery fails", func() {
+		mock.ExpectQuery(`SELECT ROUTINE_NAME`).WillReturnError(errors.New("some database error"))
+
+		_, err := InspectDonor(db, MigrateOptions{})
+		Expect(err).To(MatchError(ContainSubstring("failed to discover stored programs: ")))
+	})
+})
+
+var _ = Describe("InspectDonor with filters", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("reports the resolved schemas and excluded tables", func() {
+		mock.ExpectQuery(`SHOW DATABASES`).
+			WillReturnRows(sqlmock.NewRows([]string{"Database"}).AddRow("app").AddRow("app_audit"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES`)).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("audit_log").AddRow("users"))
+		mock.ExpectQuery(`SELECT table_name from INFORMATION_SCHEMA.VIEWS`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+		mock.ExpectQuery(`SELECT table_name from INFORMATION_SCHEMA.VIEWS`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name"}))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0)`)).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(4096))
+		mock.ExpectQuery(`SELECT TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "ENGINE"}))
+		mock.ExpectQuery(`SELECT ROUTINE_NAME`).
+			WithArgs("app", "app", "app").
+			WillReturnRows(sqlmock.NewRows([]string{"name", "type", "table"}).AddRow("audit_trigger", "TRIGGER", "audit_log"))
+
+		summary, err := InspectDonor(db, MigrateOptions{Filter: discovery.Filter{
+			ExcludeSchemas: []string{"*_audit"},
+			ExcludeTables:  []string{"app.audit_*"},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary).To(Equal(DonorSummary{
+			Schemas:        []string{"app"},
+			ExcludedTables: []string{"app.audit_log"},
+			DataSizeBytes:  4096,
+		}))
+	})
+})
@@ -15,45 +15,94 @@ package migrate
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
 )
 
 //counterfeiter:generate . Client
 type Client interface {
+	CurrentSpace() (Space, error)
 	ServiceExists(serviceName string) bool
-	CreateServiceInstance(planType, instanceName string) error
+	ListApps() ([]SpaceApp, error)
+	ListServiceInstances() ([]SpaceServiceInstance, error)
+	CreateServiceInstance(ctx context.Context, planType, instanceName string, config ServiceInstanceConfig) error
+	WaitForServiceInstance(ctx context.Context, instanceName string) error
+	CreateServiceKey(instanceName, keyName string) (ServiceCredentials, error)
+	DeleteServiceKey(instanceName, keyName string) error
+	ServicePlanExists(productName, planName string) (bool, error)
+	RemainingQuota() (Quota, error)
+	StackExists(name string) (bool, error)
+	IsolationSegment() (string, error)
 	BindService(appName, serviceName string) error
+	BindServiceWithParameters(appName, serviceName, parameters string) error
+	UnbindService(appName, serviceName string) error
+	BindingParameters(bindingGUID string) (string, error)
+	CreateServiceKeyWithParameters(instanceName, keyName, parameters string) error
+	CreateUserProvidedService(instanceName string, credentials ServiceCredentials) error
+	RestageApp(appName string) error
+	ServiceInstanceGUID(instanceName string) (string, error)
 	DeleteApp(appName string) error
 	DeleteServiceInstance(instanceName string) error
 	GetLogs(appName, filter string) ([]string, error)
 	PushApp(path, appName string) error
 	RenameService(oldName, newName string) error
-	RunTask(appName, command string) error
+	RunSSH(appName, command string, stdin io.Reader, stdout, stderr io.Writer) error
+	SetEnv(appName, name, value string) error
 	StartApp(appName string) error
+	StartTask(appName, command string) (taskGUID string, err error)
+	WaitForTask(ctx context.Context, taskGUID string, onPoll func()) error
 }
 
 //counterfeiter:generate . Unpacker
 type Unpacker interface {
-	Unpack(destDir string) error
+	Unpack(destDir string, settings app.Settings) error
 }
 
-func NewMigrator(client Client, unpacker Unpacker) *Migrator {
+func NewMigrator(client Client, unpacker Unpacker, store StateStore, inspector DonorInspector, finder BindingFinder, cutovers CutoverStore) *Migrator {
 	return &Migrator{
-		client:   client,
-		unpacker: unpacker,
+		client:    client,
+		unpacker:  unpacker,
+		store:     store,
+		inspector: inspector,
+		finder:    finder,
+		cutovers:  cutovers,
+		Sleep:     time.Sleep,
+		Now:       time.Now,
+		Input:     os.Stdin,
 	}
 }
 
 type Migrator struct {
-	appName  string
-	client   Client
-	unpacker Unpacker
+	appName   string
+	client    Client
+	unpacker  Unpacker
+	store     StateStore
+	inspector DonorInspector
+	finder    BindingFinder
+	cutovers  CutoverStore
+	// report is built while migrating, for WriteReport
+	report         MigrationReport
+	phaseStartedAt time.Time
+	// recipientFingerprint is the fingerprint of the recipient's data once it was migrated, recorded at cutover
+	recipientFingerprint string
+	Sleep                func(time.Duration)
+	Now                  func() time.Time
+	// Input is where the operator's confirmation of a cutover is read from
+	Input io.Reader
 }
 
 type MigrateOptions struct {
@@ -61,6 +110,55 @@ type MigrateOptions struct {
 	RecipientInstanceName string
 	Cleanup               bool
 	SkipTLSValidation     bool
+	IncludeStoredPrograms bool
+	// Verify is the verification mode passed to the migration task ("rows" or "checksum"). Empty disables verification.
+	Verify string
+	// Fingerprint has the migration task fingerprint the recipient's data once migrated, so that rolling back can
+	// tell whether the recipient was written to since. It checksums every table of the recipient.
+	Fingerprint bool
+	// ExistingRecipient is set when migrating into a service instance the migration did not create
+	ExistingRecipient bool
+	// ForceOverwrite allows migrating into an existing service instance that already contains tables
+	ForceOverwrite bool
+	// ReplaceRecipient drops the recipient schemas the data is copied into before copying it
+	ReplaceRecipient bool
+	// Rebind moves the donor's app bindings and service keys to the recipient once the data has been migrated
+	Rebind bool
+	// Restage restages apps after rebinding them
+	Restage bool
+	// Filter selects the schemas and tables to migrate
+	Filter discovery.Filter
+	// Parallelism is the number of tables the migration task copies concurrently. Zero or one copies all data
+	// in a single stream.
+	Parallelism int
+	// Online keeps applying the changes made to the donor after copying it, until the operator cuts over
+	Online bool
+	// Engine is the copy engine of the migration task ("go" or "exec"). Empty uses the task's default.
+	Engine string
+	// Definer is how the migration task rewrites the DEFINER of views and stored programs ("invoker" or
+	// <user>@<host>). Empty uses the task's default.
+	Definer string
+	// MaskingRules are the YAML masking rules the migration task applies to the rows it copies. They are set in
+	// the environment of the migration app, rather than in the task command.
+	MaskingRules string
+	// ConvertCharset is the character set the migration task converts the schemas, tables and columns it copies
+	// to. Empty keeps their character sets.
+	ConvertCharset string
+	// Collation is the collation of ConvertCharset. Empty uses the default collation of the character set.
+	Collation string
+	// MaxRowsPerSecond and MaxBandwidth, in bytes per second, limit how fast the migration task copies rows. Zero
+	// does not limit it.
+	MaxRowsPerSecond int64
+	MaxBandwidth     int64
+	// MaxThreadsRunning and MaxReplicationLag pause the copy while the donor is busier, or lags further behind its
+	// replication source, than this. Zero does not pause it.
+	MaxThreadsRunning int
+	MaxReplicationLag time.Duration
+	// App sets the memory, disk and stack of the migration app
+	App app.Settings
+	// IsolationSegment is the isolation segment the targeted space must run apps in. It is only checked, since the
+	// migration app runs in the isolation segment of its space. Empty allows any.
+	IsolationSegment string
 }
 
 func (m *Migrator) CheckServiceExists(donorInstanceName string) error {
@@ -71,81 +169,413 @@ func (m *Migrator) CheckServiceExists(donorInstanceName string) error {
 	return nil
 }
 
-func (m *Migrator) CreateServiceInstance(planType, serviceName string) error {
-	if err := m.client.CreateServiceInstance(planType, serviceName); err != nil {
+// CreateServiceInstance creates the recipient of a migration. It is recorded beforehand, so that cleanup can tell
+// it was created by a migration.
+func (m *Migrator) CreateServiceInstance(ctx context.Context, planType, serviceName string, config ServiceInstanceConfig) error {
+	if err := m.recordRecipient(serviceName); err != nil {
+		log.Printf("Warning: %s", err)
+	}
+
+	if err := m.client.CreateServiceInstance(ctx, planType, serviceName, config); err != nil {
 		return fmt.Errorf("Error creating service instance: %w", err)
 	}
 
 	return nil
 }
 
-func (m *Migrator) MigrateData(opts MigrateOptions) error {
+// WaitForServiceInstance waits for the creation of a service instance a previous attempt of the migration started
+func (m *Migrator) WaitForServiceInstance(ctx context.Context, serviceName string) error {
+	if err := m.client.WaitForServiceInstance(ctx, serviceName); err != nil {
+		return fmt.Errorf("Error creating service instance: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Migrator) LoadState(donorInstanceName string) (State, error) {
+	state, err := m.loadState(donorInstanceName)
+	if err != nil {
+		return state, err
+	}
+
+	if phaseIndex(state.Phase) > phaseIndex(m.report.Phase) {
+		// Phases completed before resuming are not reported
+		m.report.Phase = state.Phase
+	}
+
+	if state.RecipientFingerprint != "" {
+		m.recipientFingerprint = state.RecipientFingerprint
+	}
+
+	return state, nil
+}
+
+func (m *Migrator) SaveState(state State) error {
+	m.completePhase(state.Phase)
+
+	store, err := m.spaceStore()
+	if err != nil {
+		return err
+	}
+
+	state.UpdatedAt = time.Now().UTC()
+	return store.Save(state)
+}
+
+func (m *Migrator) RemoveState(donorInstanceName string) error {
+	store, err := m.spaceStore()
+	if err != nil {
+		return err
+	}
+
+	return store.Remove(donorInstanceName)
+}
+
+func (m *Migrator) loadState(donorInstanceName string) (State, error) {
+	store, err := m.spaceStore()
+	if err != nil {
+		return State{}, err
+	}
+
+	return store.Load(donorInstanceName)
+}
+
+// spaceStore returns the store of the migrations of the space the client targets, since service instance names are
+// only unique within a space
+func (m *Migrator) spaceStore() (StateStore, error) {
+	space, err := m.client.CurrentSpace()
+	if err != nil {
+		return nil, err
+	}
+
+	return m.store.InSpace(space), nil
+}
+
+// MigrateData copies data from the donor to the recipient using a migration app and task.
+// When a migration state was previously recorded for the donor, completed phases are skipped, and a task
+// that is still running is waited on rather than started again. The task is cancelled when ctx is done.
+func (m *Migrator) MigrateData(ctx context.Context, opts MigrateOptions) (err error) {
 	cleanup := opts.Cleanup
 	donorInstanceName := opts.DonorInstanceName
 	recipientInstanceName := opts.RecipientInstanceName
 
-	tmpDir, err := ioutil.TempDir(os.TempDir(), "migrate_app_")
+	state, err := m.loadState(donorInstanceName)
 	if err != nil {
-		return fmt.Errorf("Error creating temp directory: %s", err)
+		if !errors.Is(err, ErrNoMigrationState) {
+			return fmt.Errorf("failed to load migration state: %w", err)
+		}
+
+		state = State{
+			DonorInstanceName:     donorInstanceName,
+			RecipientInstanceName: recipientInstanceName,
+			Options:               opts,
+		}
 	}
-	defer os.RemoveAll(tmpDir)
 
-	log.Printf("Unpacking assets for migration to %s", tmpDir)
-	if err = m.unpacker.Unpack(tmpDir); err != nil {
-		return fmt.Errorf("Error extracting migrate assets: %s", err)
+	if !state.Reached(PhaseAppPushed) {
+		tmpDir, err := ioutil.TempDir(os.TempDir(), "migrate_app_")
+		if err != nil {
+			return fmt.Errorf("Error creating temp directory: %s", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		log.Printf("Unpacking assets for migration to %s", tmpDir)
+		if err = m.unpacker.Unpack(tmpDir, opts.App); err != nil {
+			return fmt.Errorf("Error extracting migrate assets: %s", err)
+		}
+
+		// Record the app name before pushing, so that an interrupted push can be resumed under the same name
+		if state.AppName == "" {
+			state.AppName = migrationAppPrefix + uuid.NewString()
+			m.saveState(state)
+		}
+
+		log.Print("Started to push app")
+		if err = m.client.PushApp(tmpDir, state.AppName); err != nil {
+			return fmt.Errorf("failed to push application: %s", err)
+		}
+		m.advance(&state, PhaseAppPushed)
+		log.Print("Successfully pushed app")
 	}
 
-	log.Print("Started to push app")
-	m.appName = "migrate-app-" + uuid.NewString()
-	if err = m.client.PushApp(tmpDir, m.appName); err != nil {
-		return fmt.Errorf("failed to push application: %s", err)
-	}
+	m.appName = state.AppName
 	if cleanup {
 		defer func() {
+			// Online migrations run the cutover task on the same app, and the logs of a task whose result is
+			// unavailable are retrieved from it again on resume
+			if (opts.Online && err == nil) || errors.Is(err, ErrTaskResultUnavailable) {
+				return
+			}
+
 			m.client.DeleteApp(m.appName)
 			log.Print("Cleaning up...")
 		}()
 	}
-	log.Print("Successfully pushed app")
 
-	if err = m.client.BindService(m.appName, donorInstanceName); err != nil {
-		return fmt.Errorf("failed to bind-service %q to application %q: %s", m.appName, donorInstanceName, err)
+	if !state.Reached(PhaseServicesBound) {
+		if err = m.client.BindService(m.appName, donorInstanceName); err != nil {
+			return fmt.Errorf("failed to bind-service %q to application %q: %s", m.appName, donorInstanceName, err)
+		}
+		log.Print("Successfully bound app to v1 instance")
+
+		if err = m.client.BindService(m.appName, recipientInstanceName); err != nil {
+			return fmt.Errorf("failed to bind-service %q to application %q: %s", m.appName, recipientInstanceName, err)
+		}
+		log.Print("Successfully bound app to v2 instance")
+		m.advance(&state, PhaseServicesBound)
+	}
+
+	if !state.Reached(PhaseAppStarted) {
+		if opts.MaskingRules != "" {
+			if err = m.client.SetEnv(m.appName, masking.RulesEnv, opts.MaskingRules); err != nil {
+				return err
+			}
+		}
+
+		log.Print("Starting migration app")
+		if err = m.client.StartApp(m.appName); err != nil {
+			return fmt.Errorf("failed to start application %q: %s", m.appName, err)
+		}
+		m.advance(&state, PhaseAppStarted)
+	}
+
+	if !state.Reached(PhaseTaskStarted) {
+		log.Print("Started to run migration task")
+		command := migrateTaskCommand(opts)
+
+		state.TaskGUID, err = m.client.StartTask(m.appName, command)
+		if err != nil {
+			log.Printf("Migration failed: %s", err)
+			_ = m.outputMigrationLogs("")
+			return err
+		}
+		m.advance(&state, PhaseTaskStarted)
+	} else {
+		log.Printf("Re-attaching to migration task %s", state.TaskGUID)
+	}
+
+	logs := m.newTaskLogs()
+	if err = m.client.WaitForTask(ctx, state.TaskGUID, logs.poller()); err != nil {
+		log.Printf("Migration failed: %s", err)
+		// A failed task can not be re-attached to, so a resumed migration must run the task again
+		state.Phase = PhaseAppStarted
+		state.TaskGUID = ""
+		m.saveState(state)
+
+		// Make best effort to retrieve logs in case of failure, but migration
+		// error has priority over logging errors.
+		logs.flush("")
+		return err
+	}
+
+	logs.flush(taskLogFilter)
+	// The binlog position an online migration caught up to is only reported in the final progress line, and can not
+	// be recovered once the migration gives up on it
+	if opts.Online && !logs.await(taskLogFilter) {
+		return fmt.Errorf("%w, so the binlog position it caught up to is unknown", ErrTaskResultUnavailable)
+	}
+
+	m.report.Schemas = logs.final.Schemas
+	m.report.Tables = logs.final.CopiedTables
+	m.report.SkippedViews = logs.final.SkippedViews
+	m.recipientFingerprint = logs.final.RecipientFingerprint
+	state.RecipientFingerprint = logs.final.RecipientFingerprint
+
+	if opts.Online {
+		if logs.final.BinlogPosition == "" {
+			return errors.New("the migration task did not report the binlog position it caught up to")
+		}
+
+		state.BinlogPosition = logs.final.BinlogPosition
+		log.Printf("Caught up with %s at binlog position %s", donorInstanceName, state.BinlogPosition)
+	}
+
+	m.advance(&state, PhaseDataMigrated)
+	log.Print("Migration completed successfully")
+
+	return nil
+}
+
+// ConfirmCutover asks the operator to stop writing to the donor before the last changes of an online migration
+// are applied
+func (m *Migrator) ConfirmCutover(donorInstanceName string) bool {
+	fmt.Printf("The new service instance has caught up with %s.\n", donorInstanceName)
+	fmt.Printf("Stop all apps writing to %s, then type 'cutover' to apply the remaining changes: ", donorInstanceName)
+
+	answer, _ := bufio.NewReader(m.Input).ReadString('\n')
+
+	return strings.TrimSpace(answer) == "cutover"
+}
+
+// CutOver applies the changes made to the donor since an online migration caught up with it, using the migration
+// app MigrateData left running. Writes to the donor must have been stopped. The fingerprint of the recipient's data
+// once the changes were applied is recorded in the migration state.
+func (m *Migrator) CutOver(ctx context.Context, opts MigrateOptions) error {
+	state, err := m.loadState(opts.DonorInstanceName)
+	if err != nil {
+		return fmt.Errorf("failed to load migration state: %w", err)
+	}
+
+	if state.BinlogPosition == "" {
+		return errors.New("no binlog position was recorded for the migration")
+	}
+
+	m.appName = state.AppName
+
+	log.Printf("Applying the changes made to %s since binlog position %s", opts.DonorInstanceName, state.BinlogPosition)
+	taskGUID, err := m.client.StartTask(m.appName, cutoverTaskCommand(opts, state.BinlogPosition))
+	if err != nil {
+		return err
+	}
+
+	logs := m.newTaskLogs()
+	if err := m.client.WaitForTask(ctx, taskGUID, logs.poller()); err != nil {
+		logs.flush("")
+		return err
+	}
+
+	logs.flush(taskLogFilter)
+	if logs.final.RecipientFingerprint != "" {
+		m.recipientFingerprint = logs.final.RecipientFingerprint
+		state.RecipientFingerprint = logs.final.RecipientFingerprint
 	}
-	log.Print("Successfully bound app to v1 instance")
+	m.advance(&state, PhaseCutOver)
+	log.Print("Cutover completed successfully")
 
-	if err = m.client.BindService(m.appName, recipientInstanceName); err != nil {
-		return fmt.Errorf("failed to bind-service %q to application %q: %s", m.appName, recipientInstanceName, err)
+	// The app is kept when the cutover fails, so that it can be retried with --resume
+	if opts.Cleanup {
+		log.Print("Cleaning up...")
+		m.client.DeleteApp(m.appName)
 	}
-	log.Print("Successfully bound app to v2 instance")
 
-	log.Print("Starting migration app")
-	if err = m.client.StartApp(m.appName); err != nil {
-		return fmt.Errorf("failed to start application %q: %s", m.appName, err)
+	return nil
+}
+
+func (m *Migrator) advance(state *State, phase Phase) {
+	state.Phase = phase
+	m.saveState(*state)
+}
+
+func (m *Migrator) saveState(state State) {
+	// Failing to record progress only affects the ability to resume, so it does not fail the migration
+	if err := m.SaveState(state); err != nil {
+		log.Printf("Warning: failed to save migration state: %s", err)
 	}
+}
 
-	log.Print("Started to run migration task")
-	command := fmt.Sprintf("migrate %s %s", donorInstanceName, recipientInstanceName)
+func migrateTaskCommand(opts MigrateOptions) string {
+	args := []string{"migrate"}
 
 	if opts.SkipTLSValidation {
-		command = fmt.Sprintf("migrate -skip-tls-validation %s %s", donorInstanceName, recipientInstanceName)
+		args = append(args, "-skip-tls-validation")
 	}
 
-	if err = m.client.RunTask(m.appName, command); err != nil {
-		log.Printf("Migration failed: %s", err)
-		// Make best effort to retrieve logs in case of failure, but migration
-		// error has priority over logging errors.
-		_ = m.outputMigrationLogs("")
-	} else {
-		log.Print("Migration completed successfully")
-		_ = m.outputMigrationLogs("APP/TASK/")
+	if opts.IncludeStoredPrograms {
+		args = append(args, "-include-stored-programs")
+	}
+
+	if opts.ReplaceRecipient {
+		args = append(args, "-replace-recipient")
+	} else if opts.ExistingRecipient && !opts.ForceOverwrite {
+		args = append(args, "-require-empty-recipient")
+	}
+
+	if opts.Verify != "" {
+		args = append(args, "-verify="+opts.Verify)
+	}
+
+	// The data of an online migration is only fingerprinted at cutover, once it stopped changing
+	if opts.Fingerprint && !opts.Online {
+		args = append(args, "-fingerprint")
+	}
+
+	if opts.Parallelism > 1 {
+		args = append(args, fmt.Sprintf("-parallel=%d", opts.Parallelism))
+	}
+
+	if opts.Online {
+		args = append(args, "-online")
+	}
+
+	if opts.Engine != "" {
+		args = append(args, "-engine="+opts.Engine)
+	}
+
+	if opts.Definer != "" {
+		args = append(args, "-definer="+shellQuote(opts.Definer))
+	}
+
+	if opts.ConvertCharset != "" {
+		args = append(args, "-convert-charset="+shellQuote(opts.ConvertCharset))
+	}
+
+	if opts.Collation != "" {
+		args = append(args, "-collation="+shellQuote(opts.Collation))
+	}
+
+	if opts.MaxRowsPerSecond > 0 {
+		args = append(args, fmt.Sprintf("-max-rows-per-second=%d", opts.MaxRowsPerSecond))
+	}
+
+	if opts.MaxBandwidth > 0 {
+		args = append(args, fmt.Sprintf("-max-bandwidth=%d", opts.MaxBandwidth))
+	}
+
+	if opts.MaxThreadsRunning > 0 {
+		args = append(args, fmt.Sprintf("-max-threads-running=%d", opts.MaxThreadsRunning))
+	}
+
+	if opts.MaxReplicationLag > 0 {
+		args = append(args, "-max-replication-lag="+opts.MaxReplicationLag.String())
+	}
+
+	for _, flag := range []struct {
+		name     string
+		patterns []string
+	}{
+		{"include-schema", opts.Filter.IncludeSchemas},
+		{"exclude-schema", opts.Filter.ExcludeSchemas},
+		{"include-table", opts.Filter.IncludeTables},
+		{"exclude-table", opts.Filter.ExcludeTables},
+	} {
+		for _, pattern := range flag.patterns {
+			args = append(args, "-"+flag.name+"="+shellQuote(pattern))
+		}
+	}
+
+	args = append(args, opts.DonorInstanceName, opts.RecipientInstanceName)
+
+	return strings.Join(args, " ")
+}
+
+func cutoverTaskCommand(opts MigrateOptions, binlogPosition string) string {
+	args := []string{"migrate"}
+
+	if opts.SkipTLSValidation {
+		args = append(args, "-skip-tls-validation")
 	}
 
-	return err
+	if opts.Verify != "" {
+		args = append(args, "-verify="+opts.Verify)
+	}
+
+	if opts.Fingerprint {
+		args = append(args, "-fingerprint")
+	}
+
+	args = append(args, "-catch-up-from="+shellQuote(binlogPosition), opts.DonorInstanceName, opts.RecipientInstanceName)
+
+	return strings.Join(args, " ")
+}
+
+// shellQuote keeps glob patterns and definers from being interpreted by the shell running the task
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 func (m *Migrator) outputMigrationLogs(filter string) error {
 	log.Print("Fetching log output...")
-	time.Sleep(5 * time.Second)
+	m.Sleep(5 * time.Second)
 	output, err := m.client.GetLogs(m.appName, filter)
 	if err != nil {
 		return err
@@ -157,9 +587,26 @@ func (m *Migrator) outputMigrationLogs(filter string) error {
 	return nil
 }
 
+// RenameServiceInstances renames the donor to <donor>-old and gives its name to the recipient. Both names are
+// checked beforehand, and the donor is renamed back when the recipient can not be renamed. The cutover is recorded,
+// so that it can be rolled back.
 func (m *Migrator) RenameServiceInstances(donorInstanceName, recipientInstanceName string) error {
 	newDonorInstanceName := donorInstanceName + "-old"
+	if m.client.ServiceExists(newDonorInstanceName) {
+		renameError := `Error renaming service instance %[1]s: a service instance named %[1]s-old already exists.
+The migration of data from %[1]s to a newly created service instance with name: %[2]s has successfully completed.
+
+In order to complete the data migration, please rename or delete %[1]s-old, then run 'cf mysql-tools migrate --resume %[1]s'.`
+
+		return fmt.Errorf(renameError, donorInstanceName, recipientInstanceName)
+	}
+
+	if !m.client.ServiceExists(recipientInstanceName) {
+		return fmt.Errorf("Error renaming service instance %s: service instance %s not found", donorInstanceName, recipientInstanceName)
+	}
+
 	if err := m.client.RenameService(donorInstanceName, newDonorInstanceName); err != nil {
+		m.report.Rename = RenameDonorFailed
 		renameError := `Error renaming service instance %[1]s: %[2]s.
 The migration of data from %[1]s to a newly created service instance with name: %[1]s-new has successfully completed.
 
@@ -170,17 +617,73 @@ In order to complete the data migration, please run 'cf rename-service %[1]s %[1
 	}
 
 	if err := m.client.RenameService(recipientInstanceName, donorInstanceName); err != nil {
-		renameError := `Error renaming service instance %[1]s: %[2]s.
+		if rollbackErr := m.client.RenameService(newDonorInstanceName, donorInstanceName); rollbackErr != nil {
+			m.report.Rename = RenameRecipientFailed
+			renameError := `Error renaming service instance %[1]s: %[2]s.
 The migration of data from %[1]s to a newly created service instance with name: %[1]s-new has successfully completed.
 
 In order to complete the data migration, please run 'cf rename-service %[1]s-new %[1]s' to complete the migration process.`
 
-		return fmt.Errorf(renameError, donorInstanceName, err)
+			return fmt.Errorf(renameError, donorInstanceName, err)
+		}
+
+		m.report.Rename = RenameRolledBack
+		renameError := `Error renaming service instance %[3]s to %[1]s: %[2]s.
+The migration of data from %[1]s to a newly created service instance with name: %[3]s has successfully completed.
+%[1]s-old was renamed back to %[1]s.
+
+In order to complete the data migration, please run 'cf mysql-tools migrate --resume %[1]s'.`
+
+		return fmt.Errorf(renameError, donorInstanceName, err, recipientInstanceName)
 	}
 
+	m.report.Rename = RenameCompleted
+	m.completePhase(PhaseRenamed)
+
+	if err := m.forgetRecipient(recipientInstanceName); err != nil {
+		log.Printf("Warning: %s", err)
+	}
+
+	cutover := Cutover{
+		InstanceName:          donorInstanceName,
+		OldInstanceName:       newDonorInstanceName,
+		RecipientInstanceName: recipientInstanceName,
+		RecipientFingerprint:  m.recipientFingerprint,
+		CutOverAt:             m.Now().UTC(),
+	}
+	if err := m.cutovers.Save(cutover); err != nil {
+		log.Printf("Warning: failed to record the cutover of %s, so it can not be rolled back: %s", donorInstanceName, err)
+		return nil
+	}
+	log.Printf("Run 'cf mysql-tools migrate-rollback %s' to swap %s and %s back", donorInstanceName, donorInstanceName, newDonorInstanceName)
+
 	return nil
 }
 
 func (m *Migrator) CleanupOnError(recipientServiceInstance string) error {
-	return m.client.DeleteServiceInstance(recipientServiceInstance)
+	if err := m.client.DeleteServiceInstance(recipientServiceInstance); err != nil {
+		return err
+	}
+
+	return m.forgetRecipient(recipientServiceInstance)
+}
+
+// recordRecipient and forgetRecipient keep track of the recipients created in the space the client targets, so that
+// cleanup never mistakes a service instance of the same name in another space for one
+func (m *Migrator) recordRecipient(instanceName string) error {
+	store, err := m.spaceStore()
+	if err != nil {
+		return err
+	}
+
+	return store.RecordRecipient(instanceName)
+}
+
+func (m *Migrator) forgetRecipient(instanceName string) error {
+	store, err := m.spaceStore()
+	if err != nil {
+		return err
+	}
+
+	return store.ForgetRecipient(instanceName)
 }
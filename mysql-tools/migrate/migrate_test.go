@@ -13,13 +13,18 @@
 package migrate_test
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
 	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
 )
 
 var _ = Describe("CheckServiceExists", func() {
@@ -32,7 +37,7 @@ var _ = Describe("CheckServiceExists", func() {
 	BeforeEach(func() {
 		donorInstanceName = "some-donor-instance"
 		fakeClient = new(migratefakes.FakeClient)
-		migrator = NewMigrator(fakeClient, nil)
+		migrator = NewMigrator(fakeClient, nil, nil, nil, nil, nil)
 	})
 
 	It("Confirms we have an existing donor service instance", func() {
@@ -56,11 +61,12 @@ var _ = Describe("CheckServiceExists", func() {
 
 var _ = Describe("CreateServiceInstance", func() {
 	var (
-		planType      string
-		recipientName string
-		fakeClient    *migratefakes.FakeClient
-		fakeUnpacker  *migratefakes.FakeUnpacker
-		migrator      *Migrator
+		planType       string
+		recipientName  string
+		fakeClient     *migratefakes.FakeClient
+		fakeUnpacker   *migratefakes.FakeUnpacker
+		fakeStateStore *migratefakes.FakeStateStore
+		migrator       *Migrator
 	)
 
 	BeforeEach(func() {
@@ -68,26 +74,45 @@ var _ = Describe("CreateServiceInstance", func() {
 		recipientName = "some-recipient-instance"
 		fakeClient = new(migratefakes.FakeClient)
 		fakeUnpacker = new(migratefakes.FakeUnpacker)
-		migrator = NewMigrator(fakeClient, fakeUnpacker)
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, fakeStateStore, nil, nil, nil)
 	})
 
 	It("Creates a new service instance", func() {
-		err := migrator.CreateServiceInstance(planType, recipientName)
+		config := ServiceInstanceConfig{Parameters: `{"service-tier":"gold"}`, Tags: []string{"some-tag"}}
+		err := migrator.CreateServiceInstance(context.Background(), planType, recipientName, config)
 
 		Expect(err).NotTo(HaveOccurred())
 
 		By("Creating a service instance", func() {
 			Expect(fakeClient.CreateServiceInstanceCallCount()).To(Equal(1))
+			_, createdPlan, createdName, createdConfig := fakeClient.CreateServiceInstanceArgsForCall(0)
+			Expect(createdPlan).To(Equal(planType))
+			Expect(createdName).To(Equal(recipientName))
+			Expect(createdConfig).To(Equal(config))
+		})
+
+		By("recording that the migration created it", func() {
+			Expect(fakeStateStore.RecordRecipientCallCount()).To(Equal(1))
+			Expect(fakeStateStore.RecordRecipientArgsForCall(0)).To(Equal(recipientName))
 		})
 	})
 
+	It("creates the service instance when it can not be recorded", func() {
+		fakeStateStore.RecordRecipientReturns(errors.New("some-error"))
+
+		Expect(migrator.CreateServiceInstance(context.Background(), planType, recipientName, ServiceInstanceConfig{})).To(Succeed())
+		Expect(fakeClient.CreateServiceInstanceCallCount()).To(Equal(1))
+	})
+
 	Context("When we cannot create a new service instance", func() {
 		BeforeEach(func() {
 			fakeClient.CreateServiceInstanceReturns(errors.New("create service failed"))
 		})
 
 		It("Fails", func() {
-			err := migrator.CreateServiceInstance(planType, recipientName)
+			err := migrator.CreateServiceInstance(context.Background(), planType, recipientName, ServiceInstanceConfig{})
 
 			Expect(err).To(MatchError("Error creating service instance: create service failed"))
 			Expect(fakeClient.CreateServiceInstanceCallCount()).To(Equal(1))
@@ -101,6 +126,7 @@ var _ = Describe("MigrateData", func() {
 		recipientName  string
 		fakeClient     *migratefakes.FakeClient
 		fakeUnpacker   *migratefakes.FakeUnpacker
+		fakeStateStore *migratefakes.FakeStateStore
 		migrator       *Migrator
 		migrateOptions MigrateOptions
 	)
@@ -115,19 +141,27 @@ var _ = Describe("MigrateData", func() {
 		}
 		fakeClient = new(migratefakes.FakeClient)
 		fakeUnpacker = new(migratefakes.FakeUnpacker)
-		migrator = NewMigrator(fakeClient, fakeUnpacker)
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		fakeStateStore.LoadReturns(State{}, ErrNoMigrationState)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, fakeStateStore, nil, nil, nil)
+		migrator.Sleep = func(time.Duration) {}
 	})
 
 	Context("Given valid parameters", func() {
 		BeforeEach(func() {
-			fakeUnpacker.UnpackStub = func(path string) error {
+			fakeClient.StartTaskReturns("some-task-guid", nil)
+			fakeClient.GetLogsReturns([]string{
+				`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"tables_copied":1,"tables_total":1,"done":true}`,
+			}, nil)
+			fakeUnpacker.UnpackStub = func(path string, _ app.Settings) error {
 				Expect(path).To(BeADirectory())
 				return nil
 			}
 		})
 
 		It("Migrates data from the donor instance to the recipient instance", func() {
-			err := migrator.MigrateData(migrateOptions)
+			err := migrator.MigrateData(context.Background(), migrateOptions)
 
 			By("Unpacking the migration app", func() {
 				Expect(fakeUnpacker.UnpackCallCount()).To(Equal(1))
@@ -146,12 +180,40 @@ var _ = Describe("MigrateData", func() {
 			})
 
 			By("Running the migration app", func() {
-				Expect(fakeClient.RunTaskCallCount()).To(Equal(1))
-				migrateAppName, migrateTaskCmd := fakeClient.RunTaskArgsForCall(0)
+				Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+				migrateAppName, migrateTaskCmd := fakeClient.StartTaskArgsForCall(0)
 				Expect(migrateAppName).To(HavePrefix(`migrate-app-`))
 				Expect(migrateTaskCmd).To(MatchRegexp(`^migrate %s %s`, donorName, recipientName))
 			})
 
+			By("Waiting for the migration task to complete", func() {
+				Expect(fakeClient.WaitForTaskCallCount()).To(Equal(1))
+				_, taskGUID, _ := fakeClient.WaitForTaskArgsForCall(0)
+				Expect(taskGUID).To(Equal("some-task-guid"))
+			})
+
+			By("Recording each completed phase", func() {
+				var phases []Phase
+				for i := 0; i < fakeStateStore.SaveCallCount(); i++ {
+					state := fakeStateStore.SaveArgsForCall(i)
+					Expect(state.DonorInstanceName).To(Equal(donorName))
+					Expect(state.RecipientInstanceName).To(Equal(recipientName))
+					Expect(state.AppName).To(HavePrefix(`migrate-app-`))
+					phases = append(phases, state.Phase)
+				}
+				Expect(phases).To(Equal([]Phase{
+					PhaseNotStarted,
+					PhaseAppPushed,
+					PhaseServicesBound,
+					PhaseAppStarted,
+					PhaseTaskStarted,
+					PhaseDataMigrated,
+				}))
+
+				lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+				Expect(lastState.TaskGUID).To(Equal("some-task-guid"))
+			})
+
 			By("filtering cf logs messages to just task output", func() {
 				Expect(fakeClient.GetLogsCallCount()).To(Equal(1))
 				migrateAppName, filter := fakeClient.GetLogsArgsForCall(0)
@@ -166,30 +228,82 @@ var _ = Describe("MigrateData", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("records the fingerprint of the recipient once the data was migrated", func() {
+			fakeClient.GetLogsReturns([]string{
+				`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"tables_copied":1,"tables_total":1,"done":true,"recipient_fingerprint":"some-fingerprint"}`,
+			}, nil)
+
+			Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+			lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+			Expect(lastState.Phase).To(Equal(PhaseDataMigrated))
+			Expect(lastState.RecipientFingerprint).To(Equal("some-fingerprint"))
+		})
+
+		Context("while the task is running", func() {
+			var logsRetrievedWhileRunning int
+
+			BeforeEach(func() {
+				fakeClient.WaitForTaskStub = func(_ context.Context, taskGUID string, onPoll func()) error {
+					onPoll()
+					onPoll()
+					logsRetrievedWhileRunning = fakeClient.GetLogsCallCount()
+					return nil
+				}
+			})
+
+			It("streams the output of the task, retrieving the logs at most every few seconds", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				Expect(logsRetrievedWhileRunning).To(Equal(1))
+				_, filter := fakeClient.GetLogsArgsForCall(0)
+				Expect(filter).To(Equal("APP/TASK/"))
+
+				By("not retrieving the logs again once the final progress line was seen", func() {
+					Expect(fakeClient.GetLogsCallCount()).To(Equal(1))
+				})
+			})
+		})
+
 		Context("when retrieving logs fails", func() {
 			BeforeEach(func() {
 				fakeClient.GetLogsReturns(nil, errors.New("failed logs"))
 			})
 
 			It("does not return an error", func() {
-				err := migrator.MigrateData(migrateOptions)
+				err := migrator.MigrateData(context.Background(), migrateOptions)
 				Expect(err).ToNot(HaveOccurred())
 			})
 		})
 
 		Context("when a task fails", func() {
 			BeforeEach(func() {
-				fakeClient.RunTaskReturns(errors.New("failed"))
+				fakeClient.WaitForTaskReturns(errors.New("failed"))
+			})
+
+			It("records that the task must be run again when resuming", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).NotTo(Succeed())
+
+				lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+				Expect(lastState.Phase).To(Equal(PhaseAppStarted))
+				Expect(lastState.TaskGUID).To(BeEmpty())
 			})
 
 			It("returns the full logs output of the migrate-app", func() {
-				err := migrator.MigrateData(migrateOptions)
+				fakeClient.GetLogsReturns([]string{"some log line"}, nil)
+
+				err := migrator.MigrateData(context.Background(), migrateOptions)
 				Expect(err).To(HaveOccurred())
 
-				Expect(fakeClient.GetLogsCallCount()).To(Equal(1))
-				migrateAppName, filter := fakeClient.GetLogsArgsForCall(0)
-				Expect(migrateAppName).To(HavePrefix(`migrate-app-`))
-				Expect(filter).To(BeEmpty())
+				By("waiting for the last logs of the task to become available", func() {
+					Expect(fakeClient.GetLogsCallCount()).To(Equal(5))
+				})
+
+				for i := 0; i < fakeClient.GetLogsCallCount(); i++ {
+					migrateAppName, filter := fakeClient.GetLogsArgsForCall(i)
+					Expect(migrateAppName).To(HavePrefix(`migrate-app-`))
+					Expect(filter).To(BeEmpty())
+				}
 			})
 		})
 
@@ -199,7 +313,7 @@ var _ = Describe("MigrateData", func() {
 			})
 
 			It("keeps the application around for inspection", func() {
-				err := migrator.MigrateData(migrateOptions)
+				err := migrator.MigrateData(context.Background(), migrateOptions)
 
 				Expect(err).NotTo(HaveOccurred())
 				Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
@@ -212,34 +326,540 @@ var _ = Describe("MigrateData", func() {
 			})
 
 			It("sets -skip-tls-validation when running the migrate task", func() {
-				err := migrator.MigrateData(migrateOptions)
+				err := migrator.MigrateData(context.Background(), migrateOptions)
 
 				Expect(err).NotTo(HaveOccurred())
 
-				Expect(fakeClient.RunTaskCallCount()).To(Equal(1))
-				_, command := fakeClient.RunTaskArgsForCall(0)
+				Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+				_, command := fakeClient.StartTaskArgsForCall(0)
 				Expect(command).
 					To(MatchRegexp(`^migrate -skip-tls-validation %s %s$`, donorName, recipientName))
 			})
 		})
+
+		Context("when starting the task fails", func() {
+			BeforeEach(func() {
+				fakeClient.StartTaskReturns("", errors.New("failed to create task"))
+			})
+
+			It("returns the error without waiting on a task", func() {
+				err := migrator.MigrateData(context.Background(), migrateOptions)
+				Expect(err).To(MatchError("failed to create task"))
+				Expect(fakeClient.WaitForTaskCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when a previous migration was interrupted while the task was running", func() {
+			BeforeEach(func() {
+				fakeStateStore.LoadReturns(State{
+					DonorInstanceName:     donorName,
+					RecipientInstanceName: recipientName,
+					Phase:                 PhaseTaskStarted,
+					AppName:               "migrate-app-some-previous-guid",
+					TaskGUID:              "some-previous-task-guid",
+				}, nil)
+			})
+
+			It("re-attaches to the running task without repeating completed phases", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				Expect(fakeUnpacker.UnpackCallCount()).To(BeZero())
+				Expect(fakeClient.PushAppCallCount()).To(BeZero())
+				Expect(fakeClient.BindServiceCallCount()).To(BeZero())
+				Expect(fakeClient.StartAppCallCount()).To(BeZero())
+				Expect(fakeClient.StartTaskCallCount()).To(BeZero())
+
+				Expect(fakeClient.WaitForTaskCallCount()).To(Equal(1))
+				_, taskGUID, _ := fakeClient.WaitForTaskArgsForCall(0)
+				Expect(taskGUID).To(Equal("some-previous-task-guid"))
+
+				lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+				Expect(lastState.Phase).To(Equal(PhaseDataMigrated))
+			})
+
+			It("cleans up the app pushed by the previous migration", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+				Expect(fakeClient.DeleteAppArgsForCall(0)).To(Equal("migrate-app-some-previous-guid"))
+			})
+		})
+
+		Context("when a previous migration was interrupted after pushing the app", func() {
+			BeforeEach(func() {
+				fakeStateStore.LoadReturns(State{
+					DonorInstanceName:     donorName,
+					RecipientInstanceName: recipientName,
+					Phase:                 PhaseAppPushed,
+					AppName:               "migrate-app-some-previous-guid",
+				}, nil)
+			})
+
+			It("binds the existing app and runs the task", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				Expect(fakeClient.PushAppCallCount()).To(BeZero())
+				Expect(fakeClient.BindServiceCallCount()).To(Equal(2))
+				appName, _ := fakeClient.BindServiceArgsForCall(0)
+				Expect(appName).To(Equal("migrate-app-some-previous-guid"))
+				Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when loading the migration state fails", func() {
+			BeforeEach(func() {
+				fakeStateStore.LoadReturns(State{}, errors.New("corrupt state"))
+			})
+
+			It("returns an error", func() {
+				err := migrator.MigrateData(context.Background(), migrateOptions)
+				Expect(err).To(MatchError("failed to load migration state: corrupt state"))
+				Expect(fakeClient.PushAppCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when saving the migration state fails", func() {
+			BeforeEach(func() {
+				fakeStateStore.SaveReturns(errors.New("disk full"))
+			})
+
+			It("still migrates the data", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+				Expect(fakeClient.WaitForTaskCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when told to migrate stored programs", func() {
+			BeforeEach(func() {
+				migrateOptions.IncludeStoredPrograms = true
+			})
+
+			It("sets -include-stored-programs when running the migrate task", func() {
+				err := migrator.MigrateData(context.Background(), migrateOptions)
+
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -include-stored-programs %s %s$`, donorName, recipientName))
+			})
+		})
+
+		Context("when migrating into an existing recipient", func() {
+			BeforeEach(func() {
+				migrateOptions.ExistingRecipient = true
+			})
+
+			It("requires the recipient to be empty", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -require-empty-recipient %s %s$`, donorName, recipientName))
+			})
+
+			It("does not check the recipient when told to overwrite it", func() {
+				migrateOptions.ForceOverwrite = true
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate %s %s$`, donorName, recipientName))
+			})
+		})
+
+		Context("when told to verify the migrated data", func() {
+			BeforeEach(func() {
+				migrateOptions.Verify = "rows"
+			})
+
+			It("sets -verify when running the migrate task", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -verify=rows %s %s$`, donorName, recipientName))
+			})
+		})
+
+		Context("when told to fingerprint the migrated data", func() {
+			BeforeEach(func() {
+				migrateOptions.Fingerprint = true
+			})
+
+			It("sets -fingerprint when running the migrate task", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -fingerprint %s %s$`, donorName, recipientName))
+			})
+
+			It("leaves fingerprinting the data of an online migration to the cutover", func() {
+				migrateOptions.Online = true
+				fakeClient.GetLogsReturns([]string{
+					`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"binlog_position":"mysql-bin.000003:154","done":true}`,
+				}, nil)
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -online %s %s$`, donorName, recipientName))
+			})
+		})
+
+		Context("when told to copy tables in parallel", func() {
+			It("sets -parallel when running the migrate task", func() {
+				migrateOptions.Parallelism = 4
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -parallel=4 %s %s$`, donorName, recipientName))
+			})
+
+			It("does not set -parallel for a single worker", func() {
+				migrateOptions.Parallelism = 1
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate %s %s$`, donorName, recipientName))
+			})
+		})
+
+		Context("when told which copy engine to use", func() {
+			It("sets -engine when running the migrate task", func() {
+				migrateOptions.Engine = "exec"
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(MatchRegexp(`^migrate -engine=exec %s %s$`, donorName, recipientName))
+			})
+		})
+
+		Context("when told how to rewrite definers", func() {
+			It("sets -definer when running the migrate task", func() {
+				migrateOptions.Definer = "`app`@`%`"
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(Equal("migrate -definer='`app`@`%`' " + donorName + " " + recipientName))
+			})
+		})
+
+		Context("when told to convert character sets", func() {
+			It("sets -convert-charset and -collation when running the migrate task", func() {
+				migrateOptions.ConvertCharset = "utf8mb4"
+				migrateOptions.Collation = "utf8mb4_0900_ai_ci"
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(Equal("migrate -convert-charset='utf8mb4' -collation='utf8mb4_0900_ai_ci' " + donorName + " " + recipientName))
+			})
+		})
+
+		Context("when given settings for the migration app", func() {
+			It("writes them into the manifest it pushes", func() {
+				migrateOptions.App = app.Settings{MemoryMB: 2048, DiskMB: 4096, Stack: "cflinuxfs4"}
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, settings := fakeUnpacker.UnpackArgsForCall(0)
+				Expect(settings).To(Equal(app.Settings{MemoryMB: 2048, DiskMB: 4096, Stack: "cflinuxfs4"}))
+			})
+		})
+
+		Context("when told to throttle the copy", func() {
+			It("sets the limits when running the migrate task", func() {
+				migrateOptions.MaxRowsPerSecond = 5000
+				migrateOptions.MaxBandwidth = 10 * 1024 * 1024
+				migrateOptions.MaxThreadsRunning = 32
+				migrateOptions.MaxReplicationLag = 30 * time.Second
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).
+					To(Equal("migrate -max-rows-per-second=5000 -max-bandwidth=10485760 -max-threads-running=32 -max-replication-lag=30s " + donorName + " " + recipientName))
+			})
+		})
+
+		Context("when given masking rules", func() {
+			It("sets them in the environment of the migration app before starting it", func() {
+				migrateOptions.MaskingRules = "tables: {sakila.customer: {email: hash}}"
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				Expect(fakeClient.SetEnvCallCount()).To(Equal(1))
+				_, name, value := fakeClient.SetEnvArgsForCall(0)
+				Expect(name).To(Equal("MASKING_RULES"))
+				Expect(value).To(Equal("tables: {sakila.customer: {email: hash}}"))
+				Expect(fakeClient.StartAppCallCount()).To(Equal(1))
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).To(Equal("migrate " + donorName + " " + recipientName))
+			})
+		})
+
+		Context("when told to filter schemas and tables", func() {
+			BeforeEach(func() {
+				migrateOptions.Filter = discovery.Filter{
+					IncludeSchemas: []string{"app*"},
+					ExcludeSchemas: []string{"app_audit"},
+					IncludeTables:  []string{"app.*"},
+					ExcludeTables:  []string{"app.audit_*", "app.o'brien"},
+				}
+			})
+
+			It("passes every pattern to the migrate task, quoted for the shell", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).To(Equal("migrate " +
+					`-include-schema='app*' -exclude-schema='app_audit' -include-table='app.*' ` +
+					`-exclude-table='app.audit_*' -exclude-table='app.o'\''brien' ` +
+					donorName + " " + recipientName))
+			})
+		})
+
+		Context("when migrating online", func() {
+			BeforeEach(func() {
+				migrateOptions.Online = true
+				fakeClient.GetLogsReturns([]string{
+					`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"binlog_position":"mysql-bin.000003:154","done":true}`,
+				}, nil)
+			})
+
+			It("sets -online when running the migrate task", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				_, command := fakeClient.StartTaskArgsForCall(0)
+				Expect(command).To(MatchRegexp(`^migrate -online %s %s$`, donorName, recipientName))
+			})
+
+			It("records the binlog position the task caught up to, and keeps the app for the cutover", func() {
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+				Expect(lastState.Phase).To(Equal(PhaseDataMigrated))
+				Expect(lastState.BinlogPosition).To(Equal("mysql-bin.000003:154"))
+
+				Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
+			})
+
+			It("fails when the task did not report a binlog position", func() {
+				fakeClient.GetLogsReturns([]string{
+					`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"done":true}`,
+				}, nil)
+
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).
+					To(MatchError("the migration task did not report the binlog position it caught up to"))
+				Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+			})
+
+			It("keeps retrieving the logs until the final progress line shows up", func() {
+				fakeClient.GetLogsReturns(nil, nil)
+				fakeClient.GetLogsReturnsOnCall(9, []string{
+					`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"binlog_position":"mysql-bin.000003:154","done":true}`,
+				}, nil)
+
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).To(Succeed())
+
+				lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+				Expect(lastState.BinlogPosition).To(Equal("mysql-bin.000003:154"))
+			})
+
+			When("the final progress line never shows up", func() {
+				BeforeEach(func() {
+					fakeClient.GetLogsReturns(nil, nil)
+				})
+
+				It("keeps the app and the task to retrieve the logs again on resume", func() {
+					err := migrator.MigrateData(context.Background(), migrateOptions)
+					Expect(err).To(MatchError(ErrTaskResultUnavailable))
+					Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
+
+					lastState := fakeStateStore.SaveArgsForCall(fakeStateStore.SaveCallCount() - 1)
+					Expect(lastState.Phase).To(Equal(PhaseTaskStarted))
+					Expect(lastState.TaskGUID).NotTo(BeEmpty())
+				})
+			})
+
+			It("deletes the app when the task fails", func() {
+				fakeClient.WaitForTaskReturns(errors.New("failed"))
+
+				Expect(migrator.MigrateData(context.Background(), migrateOptions)).NotTo(Succeed())
+				Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+			})
+		})
+	})
+})
+
+var _ = Describe("ConfirmCutover", func() {
+	var migrator *Migrator
+
+	BeforeEach(func() {
+		migrator = NewMigrator(new(migratefakes.FakeClient), nil, nil, nil, nil, nil)
+	})
+
+	It("is confirmed by typing cutover", func() {
+		migrator.Input = strings.NewReader("cutover\n")
+		Expect(migrator.ConfirmCutover("some-donor-instance")).To(BeTrue())
+	})
+
+	It("is not confirmed by any other answer", func() {
+		migrator.Input = strings.NewReader("yes\n")
+		Expect(migrator.ConfirmCutover("some-donor-instance")).To(BeFalse())
+	})
+
+	It("is not confirmed without an answer", func() {
+		migrator.Input = strings.NewReader("")
+		Expect(migrator.ConfirmCutover("some-donor-instance")).To(BeFalse())
+	})
+})
+
+var _ = Describe("CutOver", func() {
+	var (
+		fakeClient     *migratefakes.FakeClient
+		fakeStateStore *migratefakes.FakeStateStore
+		migrator       *Migrator
+		migrateOptions MigrateOptions
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		fakeClient.StartTaskReturns("some-task-guid", nil)
+		fakeClient.GetLogsReturns([]string{
+			`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"binlog_position":"mysql-bin.000003:2048","recipient_fingerprint":"some-fingerprint","done":true}`,
+		}, nil)
+
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		fakeStateStore.LoadReturns(State{
+			AppName:        "migrate-app-some-guid",
+			BinlogPosition: "mysql-bin.000003:154",
+			Phase:          PhaseDataMigrated,
+		}, nil)
+
+		migrator = NewMigrator(fakeClient, nil, fakeStateStore, nil, nil, nil)
+		migrator.Sleep = func(time.Duration) {}
+
+		migrateOptions = MigrateOptions{
+			DonorInstanceName:     "some-donor-instance",
+			RecipientInstanceName: "some-recipient-instance",
+			Cleanup:               true,
+			SkipTLSValidation:     true,
+			Verify:                "checksum",
+			Fingerprint:           true,
+			Online:                true,
+		}
+	})
+
+	It("applies the remaining changes using the migration app, and deletes it afterwards", func() {
+		Expect(migrator.CutOver(context.Background(), migrateOptions)).To(Succeed())
+
+		Expect(fakeStateStore.LoadArgsForCall(0)).To(Equal("some-donor-instance"))
+
+		Expect(fakeClient.StartTaskCallCount()).To(Equal(1))
+		appName, command := fakeClient.StartTaskArgsForCall(0)
+		Expect(appName).To(Equal("migrate-app-some-guid"))
+		Expect(command).To(Equal("migrate -skip-tls-validation -verify=checksum -fingerprint -catch-up-from='mysql-bin.000003:154' some-donor-instance some-recipient-instance"))
+
+		Expect(fakeClient.WaitForTaskCallCount()).To(Equal(1))
+		_, taskGUID, _ := fakeClient.WaitForTaskArgsForCall(0)
+		Expect(taskGUID).To(Equal("some-task-guid"))
+
+		Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		Expect(fakeClient.DeleteAppArgsForCall(0)).To(Equal("migrate-app-some-guid"))
+	})
+
+	It("records the position of the recipient's binlog once cut over", func() {
+		Expect(migrator.CutOver(context.Background(), migrateOptions)).To(Succeed())
+
+		Expect(fakeStateStore.SaveCallCount()).To(Equal(1))
+		saved := fakeStateStore.SaveArgsForCall(0)
+		Expect(saved.Phase).To(Equal(PhaseCutOver))
+		Expect(saved.BinlogPosition).To(Equal("mysql-bin.000003:154"))
+		Expect(saved.RecipientFingerprint).To(Equal("some-fingerprint"))
+	})
+
+	It("keeps the app when told not to clean up", func() {
+		migrateOptions.Cleanup = false
+
+		Expect(migrator.CutOver(context.Background(), migrateOptions)).To(Succeed())
+		Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
+	})
+
+	It("fails when the cutover task fails, keeping the app to retry it", func() {
+		fakeClient.WaitForTaskReturns(errors.New("task completed with status \"FAILED\""))
+
+		Expect(migrator.CutOver(context.Background(), migrateOptions)).To(MatchError(`task completed with status "FAILED"`))
+		Expect(fakeClient.DeleteAppCallCount()).To(BeZero())
+		Expect(fakeStateStore.SaveCallCount()).To(BeZero())
+	})
+
+	It("fails when no binlog position was recorded", func() {
+		fakeStateStore.LoadReturns(State{AppName: "migrate-app-some-guid"}, nil)
+
+		Expect(migrator.CutOver(context.Background(), migrateOptions)).To(MatchError("no binlog position was recorded for the migration"))
+		Expect(fakeClient.StartTaskCallCount()).To(BeZero())
 	})
 })
 
 var _ = Describe("RenameServiceInstances", func() {
 	var (
-		donorName     string
-		recipientName string
-		fakeClient    *migratefakes.FakeClient
-		fakeUnpacker  *migratefakes.FakeUnpacker
-		migrator      *Migrator
+		donorName        string
+		recipientName    string
+		fakeClient       *migratefakes.FakeClient
+		fakeUnpacker     *migratefakes.FakeUnpacker
+		fakeCutoverStore *migratefakes.FakeCutoverStore
+		fakeStateStore   *migratefakes.FakeStateStore
+		migrator         *Migrator
 	)
 
 	BeforeEach(func() {
 		donorName = "some-donor-instance"
 		recipientName = "some-recipient-instance"
 		fakeClient = new(migratefakes.FakeClient)
+		fakeClient.ServiceExistsStub = func(instanceName string) bool {
+			return instanceName != "some-donor-instance-old"
+		}
 		fakeUnpacker = new(migratefakes.FakeUnpacker)
-		migrator = NewMigrator(fakeClient, fakeUnpacker)
+		fakeCutoverStore = new(migratefakes.FakeCutoverStore)
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, fakeStateStore, nil, nil, fakeCutoverStore)
+		migrator.Now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+	})
+
+	Context("When the name the donor instance is renamed to is taken", func() {
+		It("does not rename either service instance", func() {
+			fakeClient.ServiceExistsReturns(true)
+			fakeClient.ServiceExistsStub = nil
+
+			err := migrator.RenameServiceInstances(donorName, recipientName)
+
+			renameError := `Error renaming service instance some-donor-instance: a service instance named some-donor-instance-old already exists.
+The migration of data from some-donor-instance to a newly created service instance with name: some-recipient-instance has successfully completed.
+
+In order to complete the data migration, please rename or delete some-donor-instance-old, then run 'cf mysql-tools migrate --resume some-donor-instance'.`
+			Expect(err).To(MatchError(renameError))
+			Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+		})
+	})
+
+	Context("When the recipient instance does not exist", func() {
+		It("does not rename either service instance", func() {
+			fakeClient.ServiceExistsStub = func(instanceName string) bool {
+				return instanceName == donorName
+			}
+
+			err := migrator.RenameServiceInstances(donorName, recipientName)
+			Expect(err).To(MatchError("Error renaming service instance some-donor-instance: service instance some-recipient-instance not found"))
+			Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+		})
 	})
 
 	Context("When renaming the donor instance fails", func() {
@@ -255,21 +875,45 @@ The migration of data from some-donor-instance to a newly created service instan
 In order to complete the data migration, please run 'cf rename-service some-donor-instance some-donor-instance-old' and
 'cf rename-service some-donor-instance-new some-donor-instance' to complete the migration process.`
 			Expect(err).To(MatchError(renameError))
+			Expect(fakeCutoverStore.SaveCallCount()).To(BeZero())
 		})
 	})
 
 	Context("When renaming the recipient instance fails", func() {
-		It("tells the operator what command to run to complete the migration", func() {
+		BeforeEach(func() {
 			fakeClient.RenameServiceReturnsOnCall(1,
 				errors.New("The service instance name is taken: some-donor-instance"))
+		})
 
+		It("renames the donor instance back", func() {
 			err := migrator.RenameServiceInstances(donorName, recipientName)
 
-			renameError := `Error renaming service instance some-donor-instance: The service instance name is taken: some-donor-instance.
+			renameError := `Error renaming service instance some-recipient-instance to some-donor-instance: The service instance name is taken: some-donor-instance.
+The migration of data from some-donor-instance to a newly created service instance with name: some-recipient-instance has successfully completed.
+some-donor-instance-old was renamed back to some-donor-instance.
+
+In order to complete the data migration, please run 'cf mysql-tools migrate --resume some-donor-instance'.`
+			Expect(err).To(MatchError(renameError))
+
+			Expect(fakeClient.RenameServiceCallCount()).To(Equal(3))
+			from, to := fakeClient.RenameServiceArgsForCall(2)
+			Expect(from).To(Equal("some-donor-instance-old"))
+			Expect(to).To(Equal(donorName))
+			Expect(fakeCutoverStore.SaveCallCount()).To(BeZero())
+		})
+
+		Context("and renaming the donor instance back fails", func() {
+			It("tells the operator what command to run to complete the migration", func() {
+				fakeClient.RenameServiceReturnsOnCall(2, errors.New("some-error"))
+
+				err := migrator.RenameServiceInstances(donorName, recipientName)
+
+				renameError := `Error renaming service instance some-donor-instance: The service instance name is taken: some-donor-instance.
 The migration of data from some-donor-instance to a newly created service instance with name: some-donor-instance-new has successfully completed.
 
 In order to complete the data migration, please run 'cf rename-service some-donor-instance-new some-donor-instance' to complete the migration process.`
-			Expect(err).To(MatchError(renameError))
+				Expect(err).To(MatchError(renameError))
+			})
 		})
 	})
 
@@ -286,6 +930,71 @@ In order to complete the data migration, please run 'cf rename-service some-dono
 		previousRecipientName, newRecipientName := fakeClient.RenameServiceArgsForCall(1)
 		Expect(previousRecipientName).To(Equal(recipientName))
 		Expect(newRecipientName).To(Equal(donorName))
+
+		By("forgetting the recipient, which cleanup must no longer delete", func() {
+			Expect(fakeStateStore.ForgetRecipientCallCount()).To(Equal(1))
+			Expect(fakeStateStore.ForgetRecipientArgsForCall(0)).To(Equal(recipientName))
+		})
+	})
+
+	It("records the cutover, including the fingerprint of the recipient once the data was migrated", func() {
+		fakeStateStore := new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		fakeStateStore.LoadReturns(State{Phase: PhaseDataMigrated, RecipientFingerprint: "some-fingerprint"}, nil)
+		migrator = NewMigrator(fakeClient, fakeUnpacker, fakeStateStore, nil, nil, fakeCutoverStore)
+		migrator.Now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+		_, err := migrator.LoadState(donorName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrator.RenameServiceInstances(donorName, recipientName)).To(Succeed())
+
+		Expect(fakeCutoverStore.SaveCallCount()).To(Equal(1))
+		Expect(fakeCutoverStore.SaveArgsForCall(0)).To(Equal(Cutover{
+			InstanceName:          donorName,
+			OldInstanceName:       "some-donor-instance-old",
+			RecipientInstanceName: recipientName,
+			RecipientFingerprint:  "some-fingerprint",
+			CutOverAt:             time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		}))
+	})
+
+	It("succeeds when the cutover can not be recorded", func() {
+		fakeCutoverStore.SaveReturns(errors.New("disk full"))
+
+		Expect(migrator.RenameServiceInstances(donorName, recipientName)).To(Succeed())
+	})
+})
+
+var _ = Describe("LoadState", func() {
+	var (
+		fakeClient     *migratefakes.FakeClient
+		fakeStateStore *migratefakes.FakeStateStore
+		migrator       *Migrator
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		migrator = NewMigrator(fakeClient, nil, fakeStateStore, nil, nil, nil)
+	})
+
+	It("loads the state of the migration of the donor in the targeted space", func() {
+		space := Space{APIEndpoint: "https://api.example.com", GUID: "some-space-guid"}
+		fakeClient.CurrentSpaceReturns(space, nil)
+		fakeStateStore.LoadReturns(State{DonorInstanceName: "some-donor"}, nil)
+
+		Expect(migrator.LoadState("some-donor")).To(HaveField("DonorInstanceName", "some-donor"))
+		Expect(fakeStateStore.InSpaceArgsForCall(0)).To(Equal(space))
+		Expect(fakeStateStore.LoadArgsForCall(0)).To(Equal("some-donor"))
+	})
+
+	It("returns an error when the targeted space can not be looked up", func() {
+		fakeClient.CurrentSpaceReturns(Space{}, errors.New("no space targeted"))
+
+		_, err := migrator.LoadState("some-donor")
+		Expect(err).To(MatchError("no space targeted"))
+		Expect(fakeStateStore.LoadCallCount()).To(BeZero())
 	})
 })
 
@@ -293,19 +1002,30 @@ var _ = Describe("CleanupOnError", func() {
 	var (
 		recipientServiceInstance string
 		fakeClient               *migratefakes.FakeClient
+		fakeStateStore           *migratefakes.FakeStateStore
 		migrator                 *Migrator
 	)
 
 	BeforeEach(func() {
 		recipientServiceInstance = "some-recipient-instance"
 		fakeClient = new(migratefakes.FakeClient)
-		migrator = NewMigrator(fakeClient, nil)
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		migrator = NewMigrator(fakeClient, nil, fakeStateStore, nil, nil, nil)
 	})
 
-	It("deletes the service instance", func() {
+	It("deletes the service instance and forgets it was created", func() {
 		err := migrator.CleanupOnError(recipientServiceInstance)
 
 		Expect(err).NotTo(HaveOccurred())
 		Expect(fakeClient.DeleteServiceInstanceCallCount()).To(Equal(1))
+		Expect(fakeStateStore.ForgetRecipientArgsForCall(0)).To(Equal(recipientServiceInstance))
+	})
+
+	It("keeps the record of a service instance it failed to delete", func() {
+		fakeClient.DeleteServiceInstanceReturns(errors.New("some-error"))
+
+		Expect(migrator.CleanupOnError(recipientServiceInstance)).To(MatchError("some-error"))
+		Expect(fakeStateStore.ForgetRecipientCallCount()).To(BeZero())
 	})
 })
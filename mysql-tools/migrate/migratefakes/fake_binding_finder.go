@@ -0,0 +1,117 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"sync"
+
+	find_bindings "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/find-bindings"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+type FakeBindingFinder struct {
+	FindBindingsForInstanceStub        func(string) ([]find_bindings.Binding, error)
+	findBindingsForInstanceMutex       sync.RWMutex
+	findBindingsForInstanceArgsForCall []struct {
+		arg1 string
+	}
+	findBindingsForInstanceReturns struct {
+		result1 []find_bindings.Binding
+		result2 error
+	}
+	findBindingsForInstanceReturnsOnCall map[int]struct {
+		result1 []find_bindings.Binding
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeBindingFinder) FindBindingsForInstance(arg1 string) ([]find_bindings.Binding, error) {
+	fake.findBindingsForInstanceMutex.Lock()
+	ret, specificReturn := fake.findBindingsForInstanceReturnsOnCall[len(fake.findBindingsForInstanceArgsForCall)]
+	fake.findBindingsForInstanceArgsForCall = append(fake.findBindingsForInstanceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FindBindingsForInstanceStub
+	fakeReturns := fake.findBindingsForInstanceReturns
+	fake.recordInvocation("FindBindingsForInstance", []interface{}{arg1})
+	fake.findBindingsForInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeBindingFinder) FindBindingsForInstanceCallCount() int {
+	fake.findBindingsForInstanceMutex.RLock()
+	defer fake.findBindingsForInstanceMutex.RUnlock()
+	return len(fake.findBindingsForInstanceArgsForCall)
+}
+
+func (fake *FakeBindingFinder) FindBindingsForInstanceCalls(stub func(string) ([]find_bindings.Binding, error)) {
+	fake.findBindingsForInstanceMutex.Lock()
+	defer fake.findBindingsForInstanceMutex.Unlock()
+	fake.FindBindingsForInstanceStub = stub
+}
+
+func (fake *FakeBindingFinder) FindBindingsForInstanceArgsForCall(i int) string {
+	fake.findBindingsForInstanceMutex.RLock()
+	defer fake.findBindingsForInstanceMutex.RUnlock()
+	argsForCall := fake.findBindingsForInstanceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeBindingFinder) FindBindingsForInstanceReturns(result1 []find_bindings.Binding, result2 error) {
+	fake.findBindingsForInstanceMutex.Lock()
+	defer fake.findBindingsForInstanceMutex.Unlock()
+	fake.FindBindingsForInstanceStub = nil
+	fake.findBindingsForInstanceReturns = struct {
+		result1 []find_bindings.Binding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBindingFinder) FindBindingsForInstanceReturnsOnCall(i int, result1 []find_bindings.Binding, result2 error) {
+	fake.findBindingsForInstanceMutex.Lock()
+	defer fake.findBindingsForInstanceMutex.Unlock()
+	fake.FindBindingsForInstanceStub = nil
+	if fake.findBindingsForInstanceReturnsOnCall == nil {
+		fake.findBindingsForInstanceReturnsOnCall = make(map[int]struct {
+			result1 []find_bindings.Binding
+			result2 error
+		})
+	}
+	fake.findBindingsForInstanceReturnsOnCall[i] = struct {
+		result1 []find_bindings.Binding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeBindingFinder) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.findBindingsForInstanceMutex.RLock()
+	defer fake.findBindingsForInstanceMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeBindingFinder) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.BindingFinder = new(FakeBindingFinder)
@@ -2,6 +2,8 @@
 package migratefakes
 
 import (
+	"context"
+	"io"
 	"sync"
 
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
@@ -20,11 +22,39 @@ type FakeClient struct {
 	bindServiceReturnsOnCall map[int]struct {
 		result1 error
 	}
-	CreateServiceInstanceStub        func(string, string) error
+	BindServiceWithParametersStub        func(string, string, string) error
+	bindServiceWithParametersMutex       sync.RWMutex
+	bindServiceWithParametersArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	bindServiceWithParametersReturns struct {
+		result1 error
+	}
+	bindServiceWithParametersReturnsOnCall map[int]struct {
+		result1 error
+	}
+	BindingParametersStub        func(string) (string, error)
+	bindingParametersMutex       sync.RWMutex
+	bindingParametersArgsForCall []struct {
+		arg1 string
+	}
+	bindingParametersReturns struct {
+		result1 string
+		result2 error
+	}
+	bindingParametersReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	CreateServiceInstanceStub        func(context.Context, string, string, migrate.ServiceInstanceConfig) error
 	createServiceInstanceMutex       sync.RWMutex
 	createServiceInstanceArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
+		arg4 migrate.ServiceInstanceConfig
 	}
 	createServiceInstanceReturns struct {
 		result1 error
@@ -32,6 +62,57 @@ type FakeClient struct {
 	createServiceInstanceReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CreateServiceKeyStub        func(string, string) (migrate.ServiceCredentials, error)
+	createServiceKeyMutex       sync.RWMutex
+	createServiceKeyArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	createServiceKeyReturns struct {
+		result1 migrate.ServiceCredentials
+		result2 error
+	}
+	createServiceKeyReturnsOnCall map[int]struct {
+		result1 migrate.ServiceCredentials
+		result2 error
+	}
+	CreateServiceKeyWithParametersStub        func(string, string, string) error
+	createServiceKeyWithParametersMutex       sync.RWMutex
+	createServiceKeyWithParametersArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	createServiceKeyWithParametersReturns struct {
+		result1 error
+	}
+	createServiceKeyWithParametersReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CreateUserProvidedServiceStub        func(string, migrate.ServiceCredentials) error
+	createUserProvidedServiceMutex       sync.RWMutex
+	createUserProvidedServiceArgsForCall []struct {
+		arg1 string
+		arg2 migrate.ServiceCredentials
+	}
+	createUserProvidedServiceReturns struct {
+		result1 error
+	}
+	createUserProvidedServiceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CurrentSpaceStub        func() (migrate.Space, error)
+	currentSpaceMutex       sync.RWMutex
+	currentSpaceArgsForCall []struct {
+	}
+	currentSpaceReturns struct {
+		result1 migrate.Space
+		result2 error
+	}
+	currentSpaceReturnsOnCall map[int]struct {
+		result1 migrate.Space
+		result2 error
+	}
 	DeleteAppStub        func(string) error
 	deleteAppMutex       sync.RWMutex
 	deleteAppArgsForCall []struct {
@@ -54,6 +135,18 @@ type FakeClient struct {
 	deleteServiceInstanceReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteServiceKeyStub        func(string, string) error
+	deleteServiceKeyMutex       sync.RWMutex
+	deleteServiceKeyArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	deleteServiceKeyReturns struct {
+		result1 error
+	}
+	deleteServiceKeyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	GetLogsStub        func(string, string) ([]string, error)
 	getLogsMutex       sync.RWMutex
 	getLogsArgsForCall []struct {
@@ -68,6 +161,42 @@ type FakeClient struct {
 		result1 []string
 		result2 error
 	}
+	IsolationSegmentStub        func() (string, error)
+	isolationSegmentMutex       sync.RWMutex
+	isolationSegmentArgsForCall []struct {
+	}
+	isolationSegmentReturns struct {
+		result1 string
+		result2 error
+	}
+	isolationSegmentReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ListAppsStub        func() ([]migrate.SpaceApp, error)
+	listAppsMutex       sync.RWMutex
+	listAppsArgsForCall []struct {
+	}
+	listAppsReturns struct {
+		result1 []migrate.SpaceApp
+		result2 error
+	}
+	listAppsReturnsOnCall map[int]struct {
+		result1 []migrate.SpaceApp
+		result2 error
+	}
+	ListServiceInstancesStub        func() ([]migrate.SpaceServiceInstance, error)
+	listServiceInstancesMutex       sync.RWMutex
+	listServiceInstancesArgsForCall []struct {
+	}
+	listServiceInstancesReturns struct {
+		result1 []migrate.SpaceServiceInstance
+		result2 error
+	}
+	listServiceInstancesReturnsOnCall map[int]struct {
+		result1 []migrate.SpaceServiceInstance
+		result2 error
+	}
 	PushAppStub        func(string, string) error
 	pushAppMutex       sync.RWMutex
 	pushAppArgsForCall []struct {
@@ -80,6 +209,18 @@ type FakeClient struct {
 	pushAppReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RemainingQuotaStub        func() (migrate.Quota, error)
+	remainingQuotaMutex       sync.RWMutex
+	remainingQuotaArgsForCall []struct {
+	}
+	remainingQuotaReturns struct {
+		result1 migrate.Quota
+		result2 error
+	}
+	remainingQuotaReturnsOnCall map[int]struct {
+		result1 migrate.Quota
+		result2 error
+	}
 	RenameServiceStub        func(string, string) error
 	renameServiceMutex       sync.RWMutex
 	renameServiceArgsForCall []struct {
@@ -92,16 +233,30 @@ type FakeClient struct {
 	renameServiceReturnsOnCall map[int]struct {
 		result1 error
 	}
-	RunTaskStub        func(string, string) error
-	runTaskMutex       sync.RWMutex
-	runTaskArgsForCall []struct {
+	RestageAppStub        func(string) error
+	restageAppMutex       sync.RWMutex
+	restageAppArgsForCall []struct {
+		arg1 string
+	}
+	restageAppReturns struct {
+		result1 error
+	}
+	restageAppReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RunSSHStub        func(string, string, io.Reader, io.Writer, io.Writer) error
+	runSSHMutex       sync.RWMutex
+	runSSHArgsForCall []struct {
 		arg1 string
 		arg2 string
+		arg3 io.Reader
+		arg4 io.Writer
+		arg5 io.Writer
 	}
-	runTaskReturns struct {
+	runSSHReturns struct {
 		result1 error
 	}
-	runTaskReturnsOnCall map[int]struct {
+	runSSHReturnsOnCall map[int]struct {
 		result1 error
 	}
 	ServiceExistsStub        func(string) bool
@@ -115,6 +270,59 @@ type FakeClient struct {
 	serviceExistsReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	ServiceInstanceGUIDStub        func(string) (string, error)
+	serviceInstanceGUIDMutex       sync.RWMutex
+	serviceInstanceGUIDArgsForCall []struct {
+		arg1 string
+	}
+	serviceInstanceGUIDReturns struct {
+		result1 string
+		result2 error
+	}
+	serviceInstanceGUIDReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ServicePlanExistsStub        func(string, string) (bool, error)
+	servicePlanExistsMutex       sync.RWMutex
+	servicePlanExistsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	servicePlanExistsReturns struct {
+		result1 bool
+		result2 error
+	}
+	servicePlanExistsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	SetEnvStub        func(string, string, string) error
+	setEnvMutex       sync.RWMutex
+	setEnvArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	setEnvReturns struct {
+		result1 error
+	}
+	setEnvReturnsOnCall map[int]struct {
+		result1 error
+	}
+	StackExistsStub        func(string) (bool, error)
+	stackExistsMutex       sync.RWMutex
+	stackExistsArgsForCall []struct {
+		arg1 string
+	}
+	stackExistsReturns struct {
+		result1 bool
+		result2 error
+	}
+	stackExistsReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
 	StartAppStub        func(string) error
 	startAppMutex       sync.RWMutex
 	startAppArgsForCall []struct {
@@ -126,6 +334,57 @@ type FakeClient struct {
 	startAppReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StartTaskStub        func(string, string) (string, error)
+	startTaskMutex       sync.RWMutex
+	startTaskArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	startTaskReturns struct {
+		result1 string
+		result2 error
+	}
+	startTaskReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	UnbindServiceStub        func(string, string) error
+	unbindServiceMutex       sync.RWMutex
+	unbindServiceArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	unbindServiceReturns struct {
+		result1 error
+	}
+	unbindServiceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForServiceInstanceStub        func(context.Context, string) error
+	waitForServiceInstanceMutex       sync.RWMutex
+	waitForServiceInstanceArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	waitForServiceInstanceReturns struct {
+		result1 error
+	}
+	waitForServiceInstanceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForTaskStub        func(context.Context, string, func()) error
+	waitForTaskMutex       sync.RWMutex
+	waitForTaskArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 func()
+	}
+	waitForTaskReturns struct {
+		result1 error
+	}
+	waitForTaskReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -192,19 +451,148 @@ func (fake *FakeClient) BindServiceReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeClient) CreateServiceInstance(arg1 string, arg2 string) error {
+func (fake *FakeClient) BindServiceWithParameters(arg1 string, arg2 string, arg3 string) error {
+	fake.bindServiceWithParametersMutex.Lock()
+	ret, specificReturn := fake.bindServiceWithParametersReturnsOnCall[len(fake.bindServiceWithParametersArgsForCall)]
+	fake.bindServiceWithParametersArgsForCall = append(fake.bindServiceWithParametersArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.BindServiceWithParametersStub
+	fakeReturns := fake.bindServiceWithParametersReturns
+	fake.recordInvocation("BindServiceWithParameters", []interface{}{arg1, arg2, arg3})
+	fake.bindServiceWithParametersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) BindServiceWithParametersCallCount() int {
+	fake.bindServiceWithParametersMutex.RLock()
+	defer fake.bindServiceWithParametersMutex.RUnlock()
+	return len(fake.bindServiceWithParametersArgsForCall)
+}
+
+func (fake *FakeClient) BindServiceWithParametersCalls(stub func(string, string, string) error) {
+	fake.bindServiceWithParametersMutex.Lock()
+	defer fake.bindServiceWithParametersMutex.Unlock()
+	fake.BindServiceWithParametersStub = stub
+}
+
+func (fake *FakeClient) BindServiceWithParametersArgsForCall(i int) (string, string, string) {
+	fake.bindServiceWithParametersMutex.RLock()
+	defer fake.bindServiceWithParametersMutex.RUnlock()
+	argsForCall := fake.bindServiceWithParametersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) BindServiceWithParametersReturns(result1 error) {
+	fake.bindServiceWithParametersMutex.Lock()
+	defer fake.bindServiceWithParametersMutex.Unlock()
+	fake.BindServiceWithParametersStub = nil
+	fake.bindServiceWithParametersReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) BindServiceWithParametersReturnsOnCall(i int, result1 error) {
+	fake.bindServiceWithParametersMutex.Lock()
+	defer fake.bindServiceWithParametersMutex.Unlock()
+	fake.BindServiceWithParametersStub = nil
+	if fake.bindServiceWithParametersReturnsOnCall == nil {
+		fake.bindServiceWithParametersReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.bindServiceWithParametersReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) BindingParameters(arg1 string) (string, error) {
+	fake.bindingParametersMutex.Lock()
+	ret, specificReturn := fake.bindingParametersReturnsOnCall[len(fake.bindingParametersArgsForCall)]
+	fake.bindingParametersArgsForCall = append(fake.bindingParametersArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.BindingParametersStub
+	fakeReturns := fake.bindingParametersReturns
+	fake.recordInvocation("BindingParameters", []interface{}{arg1})
+	fake.bindingParametersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) BindingParametersCallCount() int {
+	fake.bindingParametersMutex.RLock()
+	defer fake.bindingParametersMutex.RUnlock()
+	return len(fake.bindingParametersArgsForCall)
+}
+
+func (fake *FakeClient) BindingParametersCalls(stub func(string) (string, error)) {
+	fake.bindingParametersMutex.Lock()
+	defer fake.bindingParametersMutex.Unlock()
+	fake.BindingParametersStub = stub
+}
+
+func (fake *FakeClient) BindingParametersArgsForCall(i int) string {
+	fake.bindingParametersMutex.RLock()
+	defer fake.bindingParametersMutex.RUnlock()
+	argsForCall := fake.bindingParametersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) BindingParametersReturns(result1 string, result2 error) {
+	fake.bindingParametersMutex.Lock()
+	defer fake.bindingParametersMutex.Unlock()
+	fake.BindingParametersStub = nil
+	fake.bindingParametersReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) BindingParametersReturnsOnCall(i int, result1 string, result2 error) {
+	fake.bindingParametersMutex.Lock()
+	defer fake.bindingParametersMutex.Unlock()
+	fake.BindingParametersStub = nil
+	if fake.bindingParametersReturnsOnCall == nil {
+		fake.bindingParametersReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.bindingParametersReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CreateServiceInstance(arg1 context.Context, arg2 string, arg3 string, arg4 migrate.ServiceInstanceConfig) error {
 	fake.createServiceInstanceMutex.Lock()
 	ret, specificReturn := fake.createServiceInstanceReturnsOnCall[len(fake.createServiceInstanceArgsForCall)]
 	fake.createServiceInstanceArgsForCall = append(fake.createServiceInstanceArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+		arg4 migrate.ServiceInstanceConfig
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.CreateServiceInstanceStub
 	fakeReturns := fake.createServiceInstanceReturns
-	fake.recordInvocation("CreateServiceInstance", []interface{}{arg1, arg2})
+	fake.recordInvocation("CreateServiceInstance", []interface{}{arg1, arg2, arg3, arg4})
 	fake.createServiceInstanceMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -218,17 +606,17 @@ func (fake *FakeClient) CreateServiceInstanceCallCount() int {
 	return len(fake.createServiceInstanceArgsForCall)
 }
 
-func (fake *FakeClient) CreateServiceInstanceCalls(stub func(string, string) error) {
+func (fake *FakeClient) CreateServiceInstanceCalls(stub func(context.Context, string, string, migrate.ServiceInstanceConfig) error) {
 	fake.createServiceInstanceMutex.Lock()
 	defer fake.createServiceInstanceMutex.Unlock()
 	fake.CreateServiceInstanceStub = stub
 }
 
-func (fake *FakeClient) CreateServiceInstanceArgsForCall(i int) (string, string) {
+func (fake *FakeClient) CreateServiceInstanceArgsForCall(i int) (context.Context, string, string, migrate.ServiceInstanceConfig) {
 	fake.createServiceInstanceMutex.RLock()
 	defer fake.createServiceInstanceMutex.RUnlock()
 	argsForCall := fake.createServiceInstanceArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeClient) CreateServiceInstanceReturns(result1 error) {
@@ -254,79 +642,85 @@ func (fake *FakeClient) CreateServiceInstanceReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
-func (fake *FakeClient) DeleteApp(arg1 string) error {
-	fake.deleteAppMutex.Lock()
-	ret, specificReturn := fake.deleteAppReturnsOnCall[len(fake.deleteAppArgsForCall)]
-	fake.deleteAppArgsForCall = append(fake.deleteAppArgsForCall, struct {
+func (fake *FakeClient) CreateServiceKey(arg1 string, arg2 string) (migrate.ServiceCredentials, error) {
+	fake.createServiceKeyMutex.Lock()
+	ret, specificReturn := fake.createServiceKeyReturnsOnCall[len(fake.createServiceKeyArgsForCall)]
+	fake.createServiceKeyArgsForCall = append(fake.createServiceKeyArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.DeleteAppStub
-	fakeReturns := fake.deleteAppReturns
-	fake.recordInvocation("DeleteApp", []interface{}{arg1})
-	fake.deleteAppMutex.Unlock()
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CreateServiceKeyStub
+	fakeReturns := fake.createServiceKeyReturns
+	fake.recordInvocation("CreateServiceKey", []interface{}{arg1, arg2})
+	fake.createServiceKeyMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeClient) DeleteAppCallCount() int {
-	fake.deleteAppMutex.RLock()
-	defer fake.deleteAppMutex.RUnlock()
-	return len(fake.deleteAppArgsForCall)
+func (fake *FakeClient) CreateServiceKeyCallCount() int {
+	fake.createServiceKeyMutex.RLock()
+	defer fake.createServiceKeyMutex.RUnlock()
+	return len(fake.createServiceKeyArgsForCall)
 }
 
-func (fake *FakeClient) DeleteAppCalls(stub func(string) error) {
-	fake.deleteAppMutex.Lock()
-	defer fake.deleteAppMutex.Unlock()
-	fake.DeleteAppStub = stub
+func (fake *FakeClient) CreateServiceKeyCalls(stub func(string, string) (migrate.ServiceCredentials, error)) {
+	fake.createServiceKeyMutex.Lock()
+	defer fake.createServiceKeyMutex.Unlock()
+	fake.CreateServiceKeyStub = stub
 }
 
-func (fake *FakeClient) DeleteAppArgsForCall(i int) string {
-	fake.deleteAppMutex.RLock()
-	defer fake.deleteAppMutex.RUnlock()
-	argsForCall := fake.deleteAppArgsForCall[i]
-	return argsForCall.arg1
+func (fake *FakeClient) CreateServiceKeyArgsForCall(i int) (string, string) {
+	fake.createServiceKeyMutex.RLock()
+	defer fake.createServiceKeyMutex.RUnlock()
+	argsForCall := fake.createServiceKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeClient) DeleteAppReturns(result1 error) {
-	fake.deleteAppMutex.Lock()
-	defer fake.deleteAppMutex.Unlock()
-	fake.DeleteAppStub = nil
-	fake.deleteAppReturns = struct {
-		result1 error
-	}{result1}
+func (fake *FakeClient) CreateServiceKeyReturns(result1 migrate.ServiceCredentials, result2 error) {
+	fake.createServiceKeyMutex.Lock()
+	defer fake.createServiceKeyMutex.Unlock()
+	fake.CreateServiceKeyStub = nil
+	fake.createServiceKeyReturns = struct {
+		result1 migrate.ServiceCredentials
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeClient) DeleteAppReturnsOnCall(i int, result1 error) {
-	fake.deleteAppMutex.Lock()
-	defer fake.deleteAppMutex.Unlock()
-	fake.DeleteAppStub = nil
-	if fake.deleteAppReturnsOnCall == nil {
-		fake.deleteAppReturnsOnCall = make(map[int]struct {
-			result1 error
+func (fake *FakeClient) CreateServiceKeyReturnsOnCall(i int, result1 migrate.ServiceCredentials, result2 error) {
+	fake.createServiceKeyMutex.Lock()
+	defer fake.createServiceKeyMutex.Unlock()
+	fake.CreateServiceKeyStub = nil
+	if fake.createServiceKeyReturnsOnCall == nil {
+		fake.createServiceKeyReturnsOnCall = make(map[int]struct {
+			result1 migrate.ServiceCredentials
+			result2 error
 		})
 	}
-	fake.deleteAppReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+	fake.createServiceKeyReturnsOnCall[i] = struct {
+		result1 migrate.ServiceCredentials
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeClient) DeleteServiceInstance(arg1 string) error {
-	fake.deleteServiceInstanceMutex.Lock()
-	ret, specificReturn := fake.deleteServiceInstanceReturnsOnCall[len(fake.deleteServiceInstanceArgsForCall)]
-	fake.deleteServiceInstanceArgsForCall = append(fake.deleteServiceInstanceArgsForCall, struct {
+func (fake *FakeClient) CreateServiceKeyWithParameters(arg1 string, arg2 string, arg3 string) error {
+	fake.createServiceKeyWithParametersMutex.Lock()
+	ret, specificReturn := fake.createServiceKeyWithParametersReturnsOnCall[len(fake.createServiceKeyWithParametersArgsForCall)]
+	fake.createServiceKeyWithParametersArgsForCall = append(fake.createServiceKeyWithParametersArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.DeleteServiceInstanceStub
-	fakeReturns := fake.deleteServiceInstanceReturns
-	fake.recordInvocation("DeleteServiceInstance", []interface{}{arg1})
-	fake.deleteServiceInstanceMutex.Unlock()
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.CreateServiceKeyWithParametersStub
+	fakeReturns := fake.createServiceKeyWithParametersReturns
+	fake.recordInvocation("CreateServiceKeyWithParameters", []interface{}{arg1, arg2, arg3})
+	fake.createServiceKeyWithParametersMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
 		return ret.result1
@@ -334,10 +728,250 @@ func (fake *FakeClient) DeleteServiceInstance(arg1 string) error {
 	return fakeReturns.result1
 }
 
-func (fake *FakeClient) DeleteServiceInstanceCallCount() int {
-	fake.deleteServiceInstanceMutex.RLock()
-	defer fake.deleteServiceInstanceMutex.RUnlock()
-	return len(fake.deleteServiceInstanceArgsForCall)
+func (fake *FakeClient) CreateServiceKeyWithParametersCallCount() int {
+	fake.createServiceKeyWithParametersMutex.RLock()
+	defer fake.createServiceKeyWithParametersMutex.RUnlock()
+	return len(fake.createServiceKeyWithParametersArgsForCall)
+}
+
+func (fake *FakeClient) CreateServiceKeyWithParametersCalls(stub func(string, string, string) error) {
+	fake.createServiceKeyWithParametersMutex.Lock()
+	defer fake.createServiceKeyWithParametersMutex.Unlock()
+	fake.CreateServiceKeyWithParametersStub = stub
+}
+
+func (fake *FakeClient) CreateServiceKeyWithParametersArgsForCall(i int) (string, string, string) {
+	fake.createServiceKeyWithParametersMutex.RLock()
+	defer fake.createServiceKeyWithParametersMutex.RUnlock()
+	argsForCall := fake.createServiceKeyWithParametersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) CreateServiceKeyWithParametersReturns(result1 error) {
+	fake.createServiceKeyWithParametersMutex.Lock()
+	defer fake.createServiceKeyWithParametersMutex.Unlock()
+	fake.CreateServiceKeyWithParametersStub = nil
+	fake.createServiceKeyWithParametersReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) CreateServiceKeyWithParametersReturnsOnCall(i int, result1 error) {
+	fake.createServiceKeyWithParametersMutex.Lock()
+	defer fake.createServiceKeyWithParametersMutex.Unlock()
+	fake.CreateServiceKeyWithParametersStub = nil
+	if fake.createServiceKeyWithParametersReturnsOnCall == nil {
+		fake.createServiceKeyWithParametersReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createServiceKeyWithParametersReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) CreateUserProvidedService(arg1 string, arg2 migrate.ServiceCredentials) error {
+	fake.createUserProvidedServiceMutex.Lock()
+	ret, specificReturn := fake.createUserProvidedServiceReturnsOnCall[len(fake.createUserProvidedServiceArgsForCall)]
+	fake.createUserProvidedServiceArgsForCall = append(fake.createUserProvidedServiceArgsForCall, struct {
+		arg1 string
+		arg2 migrate.ServiceCredentials
+	}{arg1, arg2})
+	stub := fake.CreateUserProvidedServiceStub
+	fakeReturns := fake.createUserProvidedServiceReturns
+	fake.recordInvocation("CreateUserProvidedService", []interface{}{arg1, arg2})
+	fake.createUserProvidedServiceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) CreateUserProvidedServiceCallCount() int {
+	fake.createUserProvidedServiceMutex.RLock()
+	defer fake.createUserProvidedServiceMutex.RUnlock()
+	return len(fake.createUserProvidedServiceArgsForCall)
+}
+
+func (fake *FakeClient) CreateUserProvidedServiceCalls(stub func(string, migrate.ServiceCredentials) error) {
+	fake.createUserProvidedServiceMutex.Lock()
+	defer fake.createUserProvidedServiceMutex.Unlock()
+	fake.CreateUserProvidedServiceStub = stub
+}
+
+func (fake *FakeClient) CreateUserProvidedServiceArgsForCall(i int) (string, migrate.ServiceCredentials) {
+	fake.createUserProvidedServiceMutex.RLock()
+	defer fake.createUserProvidedServiceMutex.RUnlock()
+	argsForCall := fake.createUserProvidedServiceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) CreateUserProvidedServiceReturns(result1 error) {
+	fake.createUserProvidedServiceMutex.Lock()
+	defer fake.createUserProvidedServiceMutex.Unlock()
+	fake.CreateUserProvidedServiceStub = nil
+	fake.createUserProvidedServiceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) CreateUserProvidedServiceReturnsOnCall(i int, result1 error) {
+	fake.createUserProvidedServiceMutex.Lock()
+	defer fake.createUserProvidedServiceMutex.Unlock()
+	fake.CreateUserProvidedServiceStub = nil
+	if fake.createUserProvidedServiceReturnsOnCall == nil {
+		fake.createUserProvidedServiceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.createUserProvidedServiceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) CurrentSpace() (migrate.Space, error) {
+	fake.currentSpaceMutex.Lock()
+	ret, specificReturn := fake.currentSpaceReturnsOnCall[len(fake.currentSpaceArgsForCall)]
+	fake.currentSpaceArgsForCall = append(fake.currentSpaceArgsForCall, struct {
+	}{})
+	stub := fake.CurrentSpaceStub
+	fakeReturns := fake.currentSpaceReturns
+	fake.recordInvocation("CurrentSpace", []interface{}{})
+	fake.currentSpaceMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) CurrentSpaceCallCount() int {
+	fake.currentSpaceMutex.RLock()
+	defer fake.currentSpaceMutex.RUnlock()
+	return len(fake.currentSpaceArgsForCall)
+}
+
+func (fake *FakeClient) CurrentSpaceCalls(stub func() (migrate.Space, error)) {
+	fake.currentSpaceMutex.Lock()
+	defer fake.currentSpaceMutex.Unlock()
+	fake.CurrentSpaceStub = stub
+}
+
+func (fake *FakeClient) CurrentSpaceReturns(result1 migrate.Space, result2 error) {
+	fake.currentSpaceMutex.Lock()
+	defer fake.currentSpaceMutex.Unlock()
+	fake.CurrentSpaceStub = nil
+	fake.currentSpaceReturns = struct {
+		result1 migrate.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) CurrentSpaceReturnsOnCall(i int, result1 migrate.Space, result2 error) {
+	fake.currentSpaceMutex.Lock()
+	defer fake.currentSpaceMutex.Unlock()
+	fake.CurrentSpaceStub = nil
+	if fake.currentSpaceReturnsOnCall == nil {
+		fake.currentSpaceReturnsOnCall = make(map[int]struct {
+			result1 migrate.Space
+			result2 error
+		})
+	}
+	fake.currentSpaceReturnsOnCall[i] = struct {
+		result1 migrate.Space
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) DeleteApp(arg1 string) error {
+	fake.deleteAppMutex.Lock()
+	ret, specificReturn := fake.deleteAppReturnsOnCall[len(fake.deleteAppArgsForCall)]
+	fake.deleteAppArgsForCall = append(fake.deleteAppArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteAppStub
+	fakeReturns := fake.deleteAppReturns
+	fake.recordInvocation("DeleteApp", []interface{}{arg1})
+	fake.deleteAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) DeleteAppCallCount() int {
+	fake.deleteAppMutex.RLock()
+	defer fake.deleteAppMutex.RUnlock()
+	return len(fake.deleteAppArgsForCall)
+}
+
+func (fake *FakeClient) DeleteAppCalls(stub func(string) error) {
+	fake.deleteAppMutex.Lock()
+	defer fake.deleteAppMutex.Unlock()
+	fake.DeleteAppStub = stub
+}
+
+func (fake *FakeClient) DeleteAppArgsForCall(i int) string {
+	fake.deleteAppMutex.RLock()
+	defer fake.deleteAppMutex.RUnlock()
+	argsForCall := fake.deleteAppArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) DeleteAppReturns(result1 error) {
+	fake.deleteAppMutex.Lock()
+	defer fake.deleteAppMutex.Unlock()
+	fake.DeleteAppStub = nil
+	fake.deleteAppReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) DeleteAppReturnsOnCall(i int, result1 error) {
+	fake.deleteAppMutex.Lock()
+	defer fake.deleteAppMutex.Unlock()
+	fake.DeleteAppStub = nil
+	if fake.deleteAppReturnsOnCall == nil {
+		fake.deleteAppReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteAppReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) DeleteServiceInstance(arg1 string) error {
+	fake.deleteServiceInstanceMutex.Lock()
+	ret, specificReturn := fake.deleteServiceInstanceReturnsOnCall[len(fake.deleteServiceInstanceArgsForCall)]
+	fake.deleteServiceInstanceArgsForCall = append(fake.deleteServiceInstanceArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeleteServiceInstanceStub
+	fakeReturns := fake.deleteServiceInstanceReturns
+	fake.recordInvocation("DeleteServiceInstance", []interface{}{arg1})
+	fake.deleteServiceInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) DeleteServiceInstanceCallCount() int {
+	fake.deleteServiceInstanceMutex.RLock()
+	defer fake.deleteServiceInstanceMutex.RUnlock()
+	return len(fake.deleteServiceInstanceArgsForCall)
 }
 
 func (fake *FakeClient) DeleteServiceInstanceCalls(stub func(string) error) {
@@ -376,6 +1010,68 @@ func (fake *FakeClient) DeleteServiceInstanceReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *FakeClient) DeleteServiceKey(arg1 string, arg2 string) error {
+	fake.deleteServiceKeyMutex.Lock()
+	ret, specificReturn := fake.deleteServiceKeyReturnsOnCall[len(fake.deleteServiceKeyArgsForCall)]
+	fake.deleteServiceKeyArgsForCall = append(fake.deleteServiceKeyArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteServiceKeyStub
+	fakeReturns := fake.deleteServiceKeyReturns
+	fake.recordInvocation("DeleteServiceKey", []interface{}{arg1, arg2})
+	fake.deleteServiceKeyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) DeleteServiceKeyCallCount() int {
+	fake.deleteServiceKeyMutex.RLock()
+	defer fake.deleteServiceKeyMutex.RUnlock()
+	return len(fake.deleteServiceKeyArgsForCall)
+}
+
+func (fake *FakeClient) DeleteServiceKeyCalls(stub func(string, string) error) {
+	fake.deleteServiceKeyMutex.Lock()
+	defer fake.deleteServiceKeyMutex.Unlock()
+	fake.DeleteServiceKeyStub = stub
+}
+
+func (fake *FakeClient) DeleteServiceKeyArgsForCall(i int) (string, string) {
+	fake.deleteServiceKeyMutex.RLock()
+	defer fake.deleteServiceKeyMutex.RUnlock()
+	argsForCall := fake.deleteServiceKeyArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) DeleteServiceKeyReturns(result1 error) {
+	fake.deleteServiceKeyMutex.Lock()
+	defer fake.deleteServiceKeyMutex.Unlock()
+	fake.DeleteServiceKeyStub = nil
+	fake.deleteServiceKeyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) DeleteServiceKeyReturnsOnCall(i int, result1 error) {
+	fake.deleteServiceKeyMutex.Lock()
+	defer fake.deleteServiceKeyMutex.Unlock()
+	fake.DeleteServiceKeyStub = nil
+	if fake.deleteServiceKeyReturnsOnCall == nil {
+		fake.deleteServiceKeyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteServiceKeyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) GetLogs(arg1 string, arg2 string) ([]string, error) {
 	fake.getLogsMutex.Lock()
 	ret, specificReturn := fake.getLogsReturnsOnCall[len(fake.getLogsArgsForCall)]
@@ -441,6 +1137,174 @@ func (fake *FakeClient) GetLogsReturnsOnCall(i int, result1 []string, result2 er
 	}{result1, result2}
 }
 
+func (fake *FakeClient) IsolationSegment() (string, error) {
+	fake.isolationSegmentMutex.Lock()
+	ret, specificReturn := fake.isolationSegmentReturnsOnCall[len(fake.isolationSegmentArgsForCall)]
+	fake.isolationSegmentArgsForCall = append(fake.isolationSegmentArgsForCall, struct {
+	}{})
+	stub := fake.IsolationSegmentStub
+	fakeReturns := fake.isolationSegmentReturns
+	fake.recordInvocation("IsolationSegment", []interface{}{})
+	fake.isolationSegmentMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) IsolationSegmentCallCount() int {
+	fake.isolationSegmentMutex.RLock()
+	defer fake.isolationSegmentMutex.RUnlock()
+	return len(fake.isolationSegmentArgsForCall)
+}
+
+func (fake *FakeClient) IsolationSegmentCalls(stub func() (string, error)) {
+	fake.isolationSegmentMutex.Lock()
+	defer fake.isolationSegmentMutex.Unlock()
+	fake.IsolationSegmentStub = stub
+}
+
+func (fake *FakeClient) IsolationSegmentReturns(result1 string, result2 error) {
+	fake.isolationSegmentMutex.Lock()
+	defer fake.isolationSegmentMutex.Unlock()
+	fake.IsolationSegmentStub = nil
+	fake.isolationSegmentReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) IsolationSegmentReturnsOnCall(i int, result1 string, result2 error) {
+	fake.isolationSegmentMutex.Lock()
+	defer fake.isolationSegmentMutex.Unlock()
+	fake.IsolationSegmentStub = nil
+	if fake.isolationSegmentReturnsOnCall == nil {
+		fake.isolationSegmentReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.isolationSegmentReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListApps() ([]migrate.SpaceApp, error) {
+	fake.listAppsMutex.Lock()
+	ret, specificReturn := fake.listAppsReturnsOnCall[len(fake.listAppsArgsForCall)]
+	fake.listAppsArgsForCall = append(fake.listAppsArgsForCall, struct {
+	}{})
+	stub := fake.ListAppsStub
+	fakeReturns := fake.listAppsReturns
+	fake.recordInvocation("ListApps", []interface{}{})
+	fake.listAppsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ListAppsCallCount() int {
+	fake.listAppsMutex.RLock()
+	defer fake.listAppsMutex.RUnlock()
+	return len(fake.listAppsArgsForCall)
+}
+
+func (fake *FakeClient) ListAppsCalls(stub func() ([]migrate.SpaceApp, error)) {
+	fake.listAppsMutex.Lock()
+	defer fake.listAppsMutex.Unlock()
+	fake.ListAppsStub = stub
+}
+
+func (fake *FakeClient) ListAppsReturns(result1 []migrate.SpaceApp, result2 error) {
+	fake.listAppsMutex.Lock()
+	defer fake.listAppsMutex.Unlock()
+	fake.ListAppsStub = nil
+	fake.listAppsReturns = struct {
+		result1 []migrate.SpaceApp
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListAppsReturnsOnCall(i int, result1 []migrate.SpaceApp, result2 error) {
+	fake.listAppsMutex.Lock()
+	defer fake.listAppsMutex.Unlock()
+	fake.ListAppsStub = nil
+	if fake.listAppsReturnsOnCall == nil {
+		fake.listAppsReturnsOnCall = make(map[int]struct {
+			result1 []migrate.SpaceApp
+			result2 error
+		})
+	}
+	fake.listAppsReturnsOnCall[i] = struct {
+		result1 []migrate.SpaceApp
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListServiceInstances() ([]migrate.SpaceServiceInstance, error) {
+	fake.listServiceInstancesMutex.Lock()
+	ret, specificReturn := fake.listServiceInstancesReturnsOnCall[len(fake.listServiceInstancesArgsForCall)]
+	fake.listServiceInstancesArgsForCall = append(fake.listServiceInstancesArgsForCall, struct {
+	}{})
+	stub := fake.ListServiceInstancesStub
+	fakeReturns := fake.listServiceInstancesReturns
+	fake.recordInvocation("ListServiceInstances", []interface{}{})
+	fake.listServiceInstancesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ListServiceInstancesCallCount() int {
+	fake.listServiceInstancesMutex.RLock()
+	defer fake.listServiceInstancesMutex.RUnlock()
+	return len(fake.listServiceInstancesArgsForCall)
+}
+
+func (fake *FakeClient) ListServiceInstancesCalls(stub func() ([]migrate.SpaceServiceInstance, error)) {
+	fake.listServiceInstancesMutex.Lock()
+	defer fake.listServiceInstancesMutex.Unlock()
+	fake.ListServiceInstancesStub = stub
+}
+
+func (fake *FakeClient) ListServiceInstancesReturns(result1 []migrate.SpaceServiceInstance, result2 error) {
+	fake.listServiceInstancesMutex.Lock()
+	defer fake.listServiceInstancesMutex.Unlock()
+	fake.ListServiceInstancesStub = nil
+	fake.listServiceInstancesReturns = struct {
+		result1 []migrate.SpaceServiceInstance
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ListServiceInstancesReturnsOnCall(i int, result1 []migrate.SpaceServiceInstance, result2 error) {
+	fake.listServiceInstancesMutex.Lock()
+	defer fake.listServiceInstancesMutex.Unlock()
+	fake.ListServiceInstancesStub = nil
+	if fake.listServiceInstancesReturnsOnCall == nil {
+		fake.listServiceInstancesReturnsOnCall = make(map[int]struct {
+			result1 []migrate.SpaceServiceInstance
+			result2 error
+		})
+	}
+	fake.listServiceInstancesReturnsOnCall[i] = struct {
+		result1 []migrate.SpaceServiceInstance
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) PushApp(arg1 string, arg2 string) error {
 	fake.pushAppMutex.Lock()
 	ret, specificReturn := fake.pushAppReturnsOnCall[len(fake.pushAppArgsForCall)]
@@ -503,10 +1367,66 @@ func (fake *FakeClient) PushAppReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeClient) RenameService(arg1 string, arg2 string) error {
-	fake.renameServiceMutex.Lock()
-	ret, specificReturn := fake.renameServiceReturnsOnCall[len(fake.renameServiceArgsForCall)]
-	fake.renameServiceArgsForCall = append(fake.renameServiceArgsForCall, struct {
+func (fake *FakeClient) RemainingQuota() (migrate.Quota, error) {
+	fake.remainingQuotaMutex.Lock()
+	ret, specificReturn := fake.remainingQuotaReturnsOnCall[len(fake.remainingQuotaArgsForCall)]
+	fake.remainingQuotaArgsForCall = append(fake.remainingQuotaArgsForCall, struct {
+	}{})
+	stub := fake.RemainingQuotaStub
+	fakeReturns := fake.remainingQuotaReturns
+	fake.recordInvocation("RemainingQuota", []interface{}{})
+	fake.remainingQuotaMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) RemainingQuotaCallCount() int {
+	fake.remainingQuotaMutex.RLock()
+	defer fake.remainingQuotaMutex.RUnlock()
+	return len(fake.remainingQuotaArgsForCall)
+}
+
+func (fake *FakeClient) RemainingQuotaCalls(stub func() (migrate.Quota, error)) {
+	fake.remainingQuotaMutex.Lock()
+	defer fake.remainingQuotaMutex.Unlock()
+	fake.RemainingQuotaStub = stub
+}
+
+func (fake *FakeClient) RemainingQuotaReturns(result1 migrate.Quota, result2 error) {
+	fake.remainingQuotaMutex.Lock()
+	defer fake.remainingQuotaMutex.Unlock()
+	fake.RemainingQuotaStub = nil
+	fake.remainingQuotaReturns = struct {
+		result1 migrate.Quota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RemainingQuotaReturnsOnCall(i int, result1 migrate.Quota, result2 error) {
+	fake.remainingQuotaMutex.Lock()
+	defer fake.remainingQuotaMutex.Unlock()
+	fake.RemainingQuotaStub = nil
+	if fake.remainingQuotaReturnsOnCall == nil {
+		fake.remainingQuotaReturnsOnCall = make(map[int]struct {
+			result1 migrate.Quota
+			result2 error
+		})
+	}
+	fake.remainingQuotaReturnsOnCall[i] = struct {
+		result1 migrate.Quota
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) RenameService(arg1 string, arg2 string) error {
+	fake.renameServiceMutex.Lock()
+	ret, specificReturn := fake.renameServiceReturnsOnCall[len(fake.renameServiceArgsForCall)]
+	fake.renameServiceArgsForCall = append(fake.renameServiceArgsForCall, struct {
 		arg1 string
 		arg2 string
 	}{arg1, arg2})
@@ -565,19 +1485,83 @@ func (fake *FakeClient) RenameServiceReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeClient) RunTask(arg1 string, arg2 string) error {
-	fake.runTaskMutex.Lock()
-	ret, specificReturn := fake.runTaskReturnsOnCall[len(fake.runTaskArgsForCall)]
-	fake.runTaskArgsForCall = append(fake.runTaskArgsForCall, struct {
+func (fake *FakeClient) RestageApp(arg1 string) error {
+	fake.restageAppMutex.Lock()
+	ret, specificReturn := fake.restageAppReturnsOnCall[len(fake.restageAppArgsForCall)]
+	fake.restageAppArgsForCall = append(fake.restageAppArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RestageAppStub
+	fakeReturns := fake.restageAppReturns
+	fake.recordInvocation("RestageApp", []interface{}{arg1})
+	fake.restageAppMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) RestageAppCallCount() int {
+	fake.restageAppMutex.RLock()
+	defer fake.restageAppMutex.RUnlock()
+	return len(fake.restageAppArgsForCall)
+}
+
+func (fake *FakeClient) RestageAppCalls(stub func(string) error) {
+	fake.restageAppMutex.Lock()
+	defer fake.restageAppMutex.Unlock()
+	fake.RestageAppStub = stub
+}
+
+func (fake *FakeClient) RestageAppArgsForCall(i int) string {
+	fake.restageAppMutex.RLock()
+	defer fake.restageAppMutex.RUnlock()
+	argsForCall := fake.restageAppArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) RestageAppReturns(result1 error) {
+	fake.restageAppMutex.Lock()
+	defer fake.restageAppMutex.Unlock()
+	fake.RestageAppStub = nil
+	fake.restageAppReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) RestageAppReturnsOnCall(i int, result1 error) {
+	fake.restageAppMutex.Lock()
+	defer fake.restageAppMutex.Unlock()
+	fake.RestageAppStub = nil
+	if fake.restageAppReturnsOnCall == nil {
+		fake.restageAppReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.restageAppReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) RunSSH(arg1 string, arg2 string, arg3 io.Reader, arg4 io.Writer, arg5 io.Writer) error {
+	fake.runSSHMutex.Lock()
+	ret, specificReturn := fake.runSSHReturnsOnCall[len(fake.runSSHArgsForCall)]
+	fake.runSSHArgsForCall = append(fake.runSSHArgsForCall, struct {
 		arg1 string
 		arg2 string
-	}{arg1, arg2})
-	stub := fake.RunTaskStub
-	fakeReturns := fake.runTaskReturns
-	fake.recordInvocation("RunTask", []interface{}{arg1, arg2})
-	fake.runTaskMutex.Unlock()
+		arg3 io.Reader
+		arg4 io.Writer
+		arg5 io.Writer
+	}{arg1, arg2, arg3, arg4, arg5})
+	stub := fake.RunSSHStub
+	fakeReturns := fake.runSSHReturns
+	fake.recordInvocation("RunSSH", []interface{}{arg1, arg2, arg3, arg4, arg5})
+	fake.runSSHMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1
@@ -585,44 +1569,44 @@ func (fake *FakeClient) RunTask(arg1 string, arg2 string) error {
 	return fakeReturns.result1
 }
 
-func (fake *FakeClient) RunTaskCallCount() int {
-	fake.runTaskMutex.RLock()
-	defer fake.runTaskMutex.RUnlock()
-	return len(fake.runTaskArgsForCall)
+func (fake *FakeClient) RunSSHCallCount() int {
+	fake.runSSHMutex.RLock()
+	defer fake.runSSHMutex.RUnlock()
+	return len(fake.runSSHArgsForCall)
 }
 
-func (fake *FakeClient) RunTaskCalls(stub func(string, string) error) {
-	fake.runTaskMutex.Lock()
-	defer fake.runTaskMutex.Unlock()
-	fake.RunTaskStub = stub
+func (fake *FakeClient) RunSSHCalls(stub func(string, string, io.Reader, io.Writer, io.Writer) error) {
+	fake.runSSHMutex.Lock()
+	defer fake.runSSHMutex.Unlock()
+	fake.RunSSHStub = stub
 }
 
-func (fake *FakeClient) RunTaskArgsForCall(i int) (string, string) {
-	fake.runTaskMutex.RLock()
-	defer fake.runTaskMutex.RUnlock()
-	argsForCall := fake.runTaskArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *FakeClient) RunSSHArgsForCall(i int) (string, string, io.Reader, io.Writer, io.Writer) {
+	fake.runSSHMutex.RLock()
+	defer fake.runSSHMutex.RUnlock()
+	argsForCall := fake.runSSHArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
-func (fake *FakeClient) RunTaskReturns(result1 error) {
-	fake.runTaskMutex.Lock()
-	defer fake.runTaskMutex.Unlock()
-	fake.RunTaskStub = nil
-	fake.runTaskReturns = struct {
+func (fake *FakeClient) RunSSHReturns(result1 error) {
+	fake.runSSHMutex.Lock()
+	defer fake.runSSHMutex.Unlock()
+	fake.RunSSHStub = nil
+	fake.runSSHReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeClient) RunTaskReturnsOnCall(i int, result1 error) {
-	fake.runTaskMutex.Lock()
-	defer fake.runTaskMutex.Unlock()
-	fake.RunTaskStub = nil
-	if fake.runTaskReturnsOnCall == nil {
-		fake.runTaskReturnsOnCall = make(map[int]struct {
+func (fake *FakeClient) RunSSHReturnsOnCall(i int, result1 error) {
+	fake.runSSHMutex.Lock()
+	defer fake.runSSHMutex.Unlock()
+	fake.RunSSHStub = nil
+	if fake.runSSHReturnsOnCall == nil {
+		fake.runSSHReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.runTaskReturnsOnCall[i] = struct {
+	fake.runSSHReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -688,6 +1672,262 @@ func (fake *FakeClient) ServiceExistsReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeClient) ServiceInstanceGUID(arg1 string) (string, error) {
+	fake.serviceInstanceGUIDMutex.Lock()
+	ret, specificReturn := fake.serviceInstanceGUIDReturnsOnCall[len(fake.serviceInstanceGUIDArgsForCall)]
+	fake.serviceInstanceGUIDArgsForCall = append(fake.serviceInstanceGUIDArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ServiceInstanceGUIDStub
+	fakeReturns := fake.serviceInstanceGUIDReturns
+	fake.recordInvocation("ServiceInstanceGUID", []interface{}{arg1})
+	fake.serviceInstanceGUIDMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ServiceInstanceGUIDCallCount() int {
+	fake.serviceInstanceGUIDMutex.RLock()
+	defer fake.serviceInstanceGUIDMutex.RUnlock()
+	return len(fake.serviceInstanceGUIDArgsForCall)
+}
+
+func (fake *FakeClient) ServiceInstanceGUIDCalls(stub func(string) (string, error)) {
+	fake.serviceInstanceGUIDMutex.Lock()
+	defer fake.serviceInstanceGUIDMutex.Unlock()
+	fake.ServiceInstanceGUIDStub = stub
+}
+
+func (fake *FakeClient) ServiceInstanceGUIDArgsForCall(i int) string {
+	fake.serviceInstanceGUIDMutex.RLock()
+	defer fake.serviceInstanceGUIDMutex.RUnlock()
+	argsForCall := fake.serviceInstanceGUIDArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) ServiceInstanceGUIDReturns(result1 string, result2 error) {
+	fake.serviceInstanceGUIDMutex.Lock()
+	defer fake.serviceInstanceGUIDMutex.Unlock()
+	fake.ServiceInstanceGUIDStub = nil
+	fake.serviceInstanceGUIDReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ServiceInstanceGUIDReturnsOnCall(i int, result1 string, result2 error) {
+	fake.serviceInstanceGUIDMutex.Lock()
+	defer fake.serviceInstanceGUIDMutex.Unlock()
+	fake.ServiceInstanceGUIDStub = nil
+	if fake.serviceInstanceGUIDReturnsOnCall == nil {
+		fake.serviceInstanceGUIDReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.serviceInstanceGUIDReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ServicePlanExists(arg1 string, arg2 string) (bool, error) {
+	fake.servicePlanExistsMutex.Lock()
+	ret, specificReturn := fake.servicePlanExistsReturnsOnCall[len(fake.servicePlanExistsArgsForCall)]
+	fake.servicePlanExistsArgsForCall = append(fake.servicePlanExistsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ServicePlanExistsStub
+	fakeReturns := fake.servicePlanExistsReturns
+	fake.recordInvocation("ServicePlanExists", []interface{}{arg1, arg2})
+	fake.servicePlanExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) ServicePlanExistsCallCount() int {
+	fake.servicePlanExistsMutex.RLock()
+	defer fake.servicePlanExistsMutex.RUnlock()
+	return len(fake.servicePlanExistsArgsForCall)
+}
+
+func (fake *FakeClient) ServicePlanExistsCalls(stub func(string, string) (bool, error)) {
+	fake.servicePlanExistsMutex.Lock()
+	defer fake.servicePlanExistsMutex.Unlock()
+	fake.ServicePlanExistsStub = stub
+}
+
+func (fake *FakeClient) ServicePlanExistsArgsForCall(i int) (string, string) {
+	fake.servicePlanExistsMutex.RLock()
+	defer fake.servicePlanExistsMutex.RUnlock()
+	argsForCall := fake.servicePlanExistsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) ServicePlanExistsReturns(result1 bool, result2 error) {
+	fake.servicePlanExistsMutex.Lock()
+	defer fake.servicePlanExistsMutex.Unlock()
+	fake.ServicePlanExistsStub = nil
+	fake.servicePlanExistsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) ServicePlanExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.servicePlanExistsMutex.Lock()
+	defer fake.servicePlanExistsMutex.Unlock()
+	fake.ServicePlanExistsStub = nil
+	if fake.servicePlanExistsReturnsOnCall == nil {
+		fake.servicePlanExistsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.servicePlanExistsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) SetEnv(arg1 string, arg2 string, arg3 string) error {
+	fake.setEnvMutex.Lock()
+	ret, specificReturn := fake.setEnvReturnsOnCall[len(fake.setEnvArgsForCall)]
+	fake.setEnvArgsForCall = append(fake.setEnvArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.SetEnvStub
+	fakeReturns := fake.setEnvReturns
+	fake.recordInvocation("SetEnv", []interface{}{arg1, arg2, arg3})
+	fake.setEnvMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) SetEnvCallCount() int {
+	fake.setEnvMutex.RLock()
+	defer fake.setEnvMutex.RUnlock()
+	return len(fake.setEnvArgsForCall)
+}
+
+func (fake *FakeClient) SetEnvCalls(stub func(string, string, string) error) {
+	fake.setEnvMutex.Lock()
+	defer fake.setEnvMutex.Unlock()
+	fake.SetEnvStub = stub
+}
+
+func (fake *FakeClient) SetEnvArgsForCall(i int) (string, string, string) {
+	fake.setEnvMutex.RLock()
+	defer fake.setEnvMutex.RUnlock()
+	argsForCall := fake.setEnvArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) SetEnvReturns(result1 error) {
+	fake.setEnvMutex.Lock()
+	defer fake.setEnvMutex.Unlock()
+	fake.SetEnvStub = nil
+	fake.setEnvReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) SetEnvReturnsOnCall(i int, result1 error) {
+	fake.setEnvMutex.Lock()
+	defer fake.setEnvMutex.Unlock()
+	fake.SetEnvStub = nil
+	if fake.setEnvReturnsOnCall == nil {
+		fake.setEnvReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setEnvReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) StackExists(arg1 string) (bool, error) {
+	fake.stackExistsMutex.Lock()
+	ret, specificReturn := fake.stackExistsReturnsOnCall[len(fake.stackExistsArgsForCall)]
+	fake.stackExistsArgsForCall = append(fake.stackExistsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.StackExistsStub
+	fakeReturns := fake.stackExistsReturns
+	fake.recordInvocation("StackExists", []interface{}{arg1})
+	fake.stackExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) StackExistsCallCount() int {
+	fake.stackExistsMutex.RLock()
+	defer fake.stackExistsMutex.RUnlock()
+	return len(fake.stackExistsArgsForCall)
+}
+
+func (fake *FakeClient) StackExistsCalls(stub func(string) (bool, error)) {
+	fake.stackExistsMutex.Lock()
+	defer fake.stackExistsMutex.Unlock()
+	fake.StackExistsStub = stub
+}
+
+func (fake *FakeClient) StackExistsArgsForCall(i int) string {
+	fake.stackExistsMutex.RLock()
+	defer fake.stackExistsMutex.RUnlock()
+	argsForCall := fake.stackExistsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeClient) StackExistsReturns(result1 bool, result2 error) {
+	fake.stackExistsMutex.Lock()
+	defer fake.stackExistsMutex.Unlock()
+	fake.StackExistsStub = nil
+	fake.stackExistsReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) StackExistsReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.stackExistsMutex.Lock()
+	defer fake.stackExistsMutex.Unlock()
+	fake.StackExistsStub = nil
+	if fake.stackExistsReturnsOnCall == nil {
+		fake.stackExistsReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.stackExistsReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeClient) StartApp(arg1 string) error {
 	fake.startAppMutex.Lock()
 	ret, specificReturn := fake.startAppReturnsOnCall[len(fake.startAppArgsForCall)]
@@ -749,29 +1989,321 @@ func (fake *FakeClient) StartAppReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeClient) StartTask(arg1 string, arg2 string) (string, error) {
+	fake.startTaskMutex.Lock()
+	ret, specificReturn := fake.startTaskReturnsOnCall[len(fake.startTaskArgsForCall)]
+	fake.startTaskArgsForCall = append(fake.startTaskArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.StartTaskStub
+	fakeReturns := fake.startTaskReturns
+	fake.recordInvocation("StartTask", []interface{}{arg1, arg2})
+	fake.startTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeClient) StartTaskCallCount() int {
+	fake.startTaskMutex.RLock()
+	defer fake.startTaskMutex.RUnlock()
+	return len(fake.startTaskArgsForCall)
+}
+
+func (fake *FakeClient) StartTaskCalls(stub func(string, string) (string, error)) {
+	fake.startTaskMutex.Lock()
+	defer fake.startTaskMutex.Unlock()
+	fake.StartTaskStub = stub
+}
+
+func (fake *FakeClient) StartTaskArgsForCall(i int) (string, string) {
+	fake.startTaskMutex.RLock()
+	defer fake.startTaskMutex.RUnlock()
+	argsForCall := fake.startTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) StartTaskReturns(result1 string, result2 error) {
+	fake.startTaskMutex.Lock()
+	defer fake.startTaskMutex.Unlock()
+	fake.StartTaskStub = nil
+	fake.startTaskReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) StartTaskReturnsOnCall(i int, result1 string, result2 error) {
+	fake.startTaskMutex.Lock()
+	defer fake.startTaskMutex.Unlock()
+	fake.StartTaskStub = nil
+	if fake.startTaskReturnsOnCall == nil {
+		fake.startTaskReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.startTaskReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeClient) UnbindService(arg1 string, arg2 string) error {
+	fake.unbindServiceMutex.Lock()
+	ret, specificReturn := fake.unbindServiceReturnsOnCall[len(fake.unbindServiceArgsForCall)]
+	fake.unbindServiceArgsForCall = append(fake.unbindServiceArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.UnbindServiceStub
+	fakeReturns := fake.unbindServiceReturns
+	fake.recordInvocation("UnbindService", []interface{}{arg1, arg2})
+	fake.unbindServiceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) UnbindServiceCallCount() int {
+	fake.unbindServiceMutex.RLock()
+	defer fake.unbindServiceMutex.RUnlock()
+	return len(fake.unbindServiceArgsForCall)
+}
+
+func (fake *FakeClient) UnbindServiceCalls(stub func(string, string) error) {
+	fake.unbindServiceMutex.Lock()
+	defer fake.unbindServiceMutex.Unlock()
+	fake.UnbindServiceStub = stub
+}
+
+func (fake *FakeClient) UnbindServiceArgsForCall(i int) (string, string) {
+	fake.unbindServiceMutex.RLock()
+	defer fake.unbindServiceMutex.RUnlock()
+	argsForCall := fake.unbindServiceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) UnbindServiceReturns(result1 error) {
+	fake.unbindServiceMutex.Lock()
+	defer fake.unbindServiceMutex.Unlock()
+	fake.UnbindServiceStub = nil
+	fake.unbindServiceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) UnbindServiceReturnsOnCall(i int, result1 error) {
+	fake.unbindServiceMutex.Lock()
+	defer fake.unbindServiceMutex.Unlock()
+	fake.UnbindServiceStub = nil
+	if fake.unbindServiceReturnsOnCall == nil {
+		fake.unbindServiceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unbindServiceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WaitForServiceInstance(arg1 context.Context, arg2 string) error {
+	fake.waitForServiceInstanceMutex.Lock()
+	ret, specificReturn := fake.waitForServiceInstanceReturnsOnCall[len(fake.waitForServiceInstanceArgsForCall)]
+	fake.waitForServiceInstanceArgsForCall = append(fake.waitForServiceInstanceArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.WaitForServiceInstanceStub
+	fakeReturns := fake.waitForServiceInstanceReturns
+	fake.recordInvocation("WaitForServiceInstance", []interface{}{arg1, arg2})
+	fake.waitForServiceInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) WaitForServiceInstanceCallCount() int {
+	fake.waitForServiceInstanceMutex.RLock()
+	defer fake.waitForServiceInstanceMutex.RUnlock()
+	return len(fake.waitForServiceInstanceArgsForCall)
+}
+
+func (fake *FakeClient) WaitForServiceInstanceCalls(stub func(context.Context, string) error) {
+	fake.waitForServiceInstanceMutex.Lock()
+	defer fake.waitForServiceInstanceMutex.Unlock()
+	fake.WaitForServiceInstanceStub = stub
+}
+
+func (fake *FakeClient) WaitForServiceInstanceArgsForCall(i int) (context.Context, string) {
+	fake.waitForServiceInstanceMutex.RLock()
+	defer fake.waitForServiceInstanceMutex.RUnlock()
+	argsForCall := fake.waitForServiceInstanceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeClient) WaitForServiceInstanceReturns(result1 error) {
+	fake.waitForServiceInstanceMutex.Lock()
+	defer fake.waitForServiceInstanceMutex.Unlock()
+	fake.WaitForServiceInstanceStub = nil
+	fake.waitForServiceInstanceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WaitForServiceInstanceReturnsOnCall(i int, result1 error) {
+	fake.waitForServiceInstanceMutex.Lock()
+	defer fake.waitForServiceInstanceMutex.Unlock()
+	fake.WaitForServiceInstanceStub = nil
+	if fake.waitForServiceInstanceReturnsOnCall == nil {
+		fake.waitForServiceInstanceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForServiceInstanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WaitForTask(arg1 context.Context, arg2 string, arg3 func()) error {
+	fake.waitForTaskMutex.Lock()
+	ret, specificReturn := fake.waitForTaskReturnsOnCall[len(fake.waitForTaskArgsForCall)]
+	fake.waitForTaskArgsForCall = append(fake.waitForTaskArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 func()
+	}{arg1, arg2, arg3})
+	stub := fake.WaitForTaskStub
+	fakeReturns := fake.waitForTaskReturns
+	fake.recordInvocation("WaitForTask", []interface{}{arg1, arg2, arg3})
+	fake.waitForTaskMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeClient) WaitForTaskCallCount() int {
+	fake.waitForTaskMutex.RLock()
+	defer fake.waitForTaskMutex.RUnlock()
+	return len(fake.waitForTaskArgsForCall)
+}
+
+func (fake *FakeClient) WaitForTaskCalls(stub func(context.Context, string, func()) error) {
+	fake.waitForTaskMutex.Lock()
+	defer fake.waitForTaskMutex.Unlock()
+	fake.WaitForTaskStub = stub
+}
+
+func (fake *FakeClient) WaitForTaskArgsForCall(i int) (context.Context, string, func()) {
+	fake.waitForTaskMutex.RLock()
+	defer fake.waitForTaskMutex.RUnlock()
+	argsForCall := fake.waitForTaskArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeClient) WaitForTaskReturns(result1 error) {
+	fake.waitForTaskMutex.Lock()
+	defer fake.waitForTaskMutex.Unlock()
+	fake.WaitForTaskStub = nil
+	fake.waitForTaskReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeClient) WaitForTaskReturnsOnCall(i int, result1 error) {
+	fake.waitForTaskMutex.Lock()
+	defer fake.waitForTaskMutex.Unlock()
+	fake.WaitForTaskStub = nil
+	if fake.waitForTaskReturnsOnCall == nil {
+		fake.waitForTaskReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForTaskReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.bindServiceMutex.RLock()
 	defer fake.bindServiceMutex.RUnlock()
+	fake.bindServiceWithParametersMutex.RLock()
+	defer fake.bindServiceWithParametersMutex.RUnlock()
+	fake.bindingParametersMutex.RLock()
+	defer fake.bindingParametersMutex.RUnlock()
 	fake.createServiceInstanceMutex.RLock()
 	defer fake.createServiceInstanceMutex.RUnlock()
+	fake.createServiceKeyMutex.RLock()
+	defer fake.createServiceKeyMutex.RUnlock()
+	fake.createServiceKeyWithParametersMutex.RLock()
+	defer fake.createServiceKeyWithParametersMutex.RUnlock()
+	fake.createUserProvidedServiceMutex.RLock()
+	defer fake.createUserProvidedServiceMutex.RUnlock()
+	fake.currentSpaceMutex.RLock()
+	defer fake.currentSpaceMutex.RUnlock()
 	fake.deleteAppMutex.RLock()
 	defer fake.deleteAppMutex.RUnlock()
 	fake.deleteServiceInstanceMutex.RLock()
 	defer fake.deleteServiceInstanceMutex.RUnlock()
+	fake.deleteServiceKeyMutex.RLock()
+	defer fake.deleteServiceKeyMutex.RUnlock()
 	fake.getLogsMutex.RLock()
 	defer fake.getLogsMutex.RUnlock()
+	fake.isolationSegmentMutex.RLock()
+	defer fake.isolationSegmentMutex.RUnlock()
+	fake.listAppsMutex.RLock()
+	defer fake.listAppsMutex.RUnlock()
+	fake.listServiceInstancesMutex.RLock()
+	defer fake.listServiceInstancesMutex.RUnlock()
 	fake.pushAppMutex.RLock()
 	defer fake.pushAppMutex.RUnlock()
+	fake.remainingQuotaMutex.RLock()
+	defer fake.remainingQuotaMutex.RUnlock()
 	fake.renameServiceMutex.RLock()
 	defer fake.renameServiceMutex.RUnlock()
-	fake.runTaskMutex.RLock()
-	defer fake.runTaskMutex.RUnlock()
+	fake.restageAppMutex.RLock()
+	defer fake.restageAppMutex.RUnlock()
+	fake.runSSHMutex.RLock()
+	defer fake.runSSHMutex.RUnlock()
 	fake.serviceExistsMutex.RLock()
 	defer fake.serviceExistsMutex.RUnlock()
+	fake.serviceInstanceGUIDMutex.RLock()
+	defer fake.serviceInstanceGUIDMutex.RUnlock()
+	fake.servicePlanExistsMutex.RLock()
+	defer fake.servicePlanExistsMutex.RUnlock()
+	fake.setEnvMutex.RLock()
+	defer fake.setEnvMutex.RUnlock()
+	fake.stackExistsMutex.RLock()
+	defer fake.stackExistsMutex.RUnlock()
 	fake.startAppMutex.RLock()
 	defer fake.startAppMutex.RUnlock()
+	fake.startTaskMutex.RLock()
+	defer fake.startTaskMutex.RUnlock()
+	fake.unbindServiceMutex.RLock()
+	defer fake.unbindServiceMutex.RUnlock()
+	fake.waitForServiceInstanceMutex.RLock()
+	defer fake.waitForServiceInstanceMutex.RUnlock()
+	fake.waitForTaskMutex.RLock()
+	defer fake.waitForTaskMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
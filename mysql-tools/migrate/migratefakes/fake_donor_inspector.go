@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+type FakeDonorInspector struct {
+	InspectStub        func(migrate.ServiceCredentials, migrate.MigrateOptions) (migrate.DonorSummary, error)
+	inspectMutex       sync.RWMutex
+	inspectArgsForCall []struct {
+		arg1 migrate.ServiceCredentials
+		arg2 migrate.MigrateOptions
+	}
+	inspectReturns struct {
+		result1 migrate.DonorSummary
+		result2 error
+	}
+	inspectReturnsOnCall map[int]struct {
+		result1 migrate.DonorSummary
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeDonorInspector) Inspect(arg1 migrate.ServiceCredentials, arg2 migrate.MigrateOptions) (migrate.DonorSummary, error) {
+	fake.inspectMutex.Lock()
+	ret, specificReturn := fake.inspectReturnsOnCall[len(fake.inspectArgsForCall)]
+	fake.inspectArgsForCall = append(fake.inspectArgsForCall, struct {
+		arg1 migrate.ServiceCredentials
+		arg2 migrate.MigrateOptions
+	}{arg1, arg2})
+	stub := fake.InspectStub
+	fakeReturns := fake.inspectReturns
+	fake.recordInvocation("Inspect", []interface{}{arg1, arg2})
+	fake.inspectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDonorInspector) InspectCallCount() int {
+	fake.inspectMutex.RLock()
+	defer fake.inspectMutex.RUnlock()
+	return len(fake.inspectArgsForCall)
+}
+
+func (fake *FakeDonorInspector) InspectCalls(stub func(migrate.ServiceCredentials, migrate.MigrateOptions) (migrate.DonorSummary, error)) {
+	fake.inspectMutex.Lock()
+	defer fake.inspectMutex.Unlock()
+	fake.InspectStub = stub
+}
+
+func (fake *FakeDonorInspector) InspectArgsForCall(i int) (migrate.ServiceCredentials, migrate.MigrateOptions) {
+	fake.inspectMutex.RLock()
+	defer fake.inspectMutex.RUnlock()
+	argsForCall := fake.inspectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeDonorInspector) InspectReturns(result1 migrate.DonorSummary, result2 error) {
+	fake.inspectMutex.Lock()
+	defer fake.inspectMutex.Unlock()
+	fake.InspectStub = nil
+	fake.inspectReturns = struct {
+		result1 migrate.DonorSummary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDonorInspector) InspectReturnsOnCall(i int, result1 migrate.DonorSummary, result2 error) {
+	fake.inspectMutex.Lock()
+	defer fake.inspectMutex.Unlock()
+	fake.InspectStub = nil
+	if fake.inspectReturnsOnCall == nil {
+		fake.inspectReturnsOnCall = make(map[int]struct {
+			result1 migrate.DonorSummary
+			result2 error
+		})
+	}
+	fake.inspectReturnsOnCall[i] = struct {
+		result1 migrate.DonorSummary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDonorInspector) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.inspectMutex.RLock()
+	defer fake.inspectMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeDonorInspector) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.DonorInspector = new(FakeDonorInspector)
@@ -0,0 +1,626 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package migratefakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+type FakeStateStore struct {
+	ForgetRecipientStub        func(string) error
+	forgetRecipientMutex       sync.RWMutex
+	forgetRecipientArgsForCall []struct {
+		arg1 string
+	}
+	forgetRecipientReturns struct {
+		result1 error
+	}
+	forgetRecipientReturnsOnCall map[int]struct {
+		result1 error
+	}
+	InSpaceStub        func(migrate.Space) migrate.StateStore
+	inSpaceMutex       sync.RWMutex
+	inSpaceArgsForCall []struct {
+		arg1 migrate.Space
+	}
+	inSpaceReturns struct {
+		result1 migrate.StateStore
+	}
+	inSpaceReturnsOnCall map[int]struct {
+		result1 migrate.StateStore
+	}
+	ListStub        func() ([]migrate.State, error)
+	listMutex       sync.RWMutex
+	listArgsForCall []struct {
+	}
+	listReturns struct {
+		result1 []migrate.State
+		result2 error
+	}
+	listReturnsOnCall map[int]struct {
+		result1 []migrate.State
+		result2 error
+	}
+	LoadStub        func(string) (migrate.State, error)
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct {
+		arg1 string
+	}
+	loadReturns struct {
+		result1 migrate.State
+		result2 error
+	}
+	loadReturnsOnCall map[int]struct {
+		result1 migrate.State
+		result2 error
+	}
+	RecipientsStub        func() ([]string, error)
+	recipientsMutex       sync.RWMutex
+	recipientsArgsForCall []struct {
+	}
+	recipientsReturns struct {
+		result1 []string
+		result2 error
+	}
+	recipientsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	RecordRecipientStub        func(string) error
+	recordRecipientMutex       sync.RWMutex
+	recordRecipientArgsForCall []struct {
+		arg1 string
+	}
+	recordRecipientReturns struct {
+		result1 error
+	}
+	recordRecipientReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RemoveStub        func(string) error
+	removeMutex       sync.RWMutex
+	removeArgsForCall []struct {
+		arg1 string
+	}
+	removeReturns struct {
+		result1 error
+	}
+	removeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SaveStub        func(migrate.State) error
+	saveMutex       sync.RWMutex
+	saveArgsForCall []struct {
+		arg1 migrate.State
+	}
+	saveReturns struct {
+		result1 error
+	}
+	saveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeStateStore) ForgetRecipient(arg1 string) error {
+	fake.forgetRecipientMutex.Lock()
+	ret, specificReturn := fake.forgetRecipientReturnsOnCall[len(fake.forgetRecipientArgsForCall)]
+	fake.forgetRecipientArgsForCall = append(fake.forgetRecipientArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ForgetRecipientStub
+	fakeReturns := fake.forgetRecipientReturns
+	fake.recordInvocation("ForgetRecipient", []interface{}{arg1})
+	fake.forgetRecipientMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStateStore) ForgetRecipientCallCount() int {
+	fake.forgetRecipientMutex.RLock()
+	defer fake.forgetRecipientMutex.RUnlock()
+	return len(fake.forgetRecipientArgsForCall)
+}
+
+func (fake *FakeStateStore) ForgetRecipientCalls(stub func(string) error) {
+	fake.forgetRecipientMutex.Lock()
+	defer fake.forgetRecipientMutex.Unlock()
+	fake.ForgetRecipientStub = stub
+}
+
+func (fake *FakeStateStore) ForgetRecipientArgsForCall(i int) string {
+	fake.forgetRecipientMutex.RLock()
+	defer fake.forgetRecipientMutex.RUnlock()
+	argsForCall := fake.forgetRecipientArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStateStore) ForgetRecipientReturns(result1 error) {
+	fake.forgetRecipientMutex.Lock()
+	defer fake.forgetRecipientMutex.Unlock()
+	fake.ForgetRecipientStub = nil
+	fake.forgetRecipientReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) ForgetRecipientReturnsOnCall(i int, result1 error) {
+	fake.forgetRecipientMutex.Lock()
+	defer fake.forgetRecipientMutex.Unlock()
+	fake.ForgetRecipientStub = nil
+	if fake.forgetRecipientReturnsOnCall == nil {
+		fake.forgetRecipientReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.forgetRecipientReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) InSpace(arg1 migrate.Space) migrate.StateStore {
+	fake.inSpaceMutex.Lock()
+	ret, specificReturn := fake.inSpaceReturnsOnCall[len(fake.inSpaceArgsForCall)]
+	fake.inSpaceArgsForCall = append(fake.inSpaceArgsForCall, struct {
+		arg1 migrate.Space
+	}{arg1})
+	stub := fake.InSpaceStub
+	fakeReturns := fake.inSpaceReturns
+	fake.recordInvocation("InSpace", []interface{}{arg1})
+	fake.inSpaceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStateStore) InSpaceCallCount() int {
+	fake.inSpaceMutex.RLock()
+	defer fake.inSpaceMutex.RUnlock()
+	return len(fake.inSpaceArgsForCall)
+}
+
+func (fake *FakeStateStore) InSpaceCalls(stub func(migrate.Space) migrate.StateStore) {
+	fake.inSpaceMutex.Lock()
+	defer fake.inSpaceMutex.Unlock()
+	fake.InSpaceStub = stub
+}
+
+func (fake *FakeStateStore) InSpaceArgsForCall(i int) migrate.Space {
+	fake.inSpaceMutex.RLock()
+	defer fake.inSpaceMutex.RUnlock()
+	argsForCall := fake.inSpaceArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStateStore) InSpaceReturns(result1 migrate.StateStore) {
+	fake.inSpaceMutex.Lock()
+	defer fake.inSpaceMutex.Unlock()
+	fake.InSpaceStub = nil
+	fake.inSpaceReturns = struct {
+		result1 migrate.StateStore
+	}{result1}
+}
+
+func (fake *FakeStateStore) InSpaceReturnsOnCall(i int, result1 migrate.StateStore) {
+	fake.inSpaceMutex.Lock()
+	defer fake.inSpaceMutex.Unlock()
+	fake.InSpaceStub = nil
+	if fake.inSpaceReturnsOnCall == nil {
+		fake.inSpaceReturnsOnCall = make(map[int]struct {
+			result1 migrate.StateStore
+		})
+	}
+	fake.inSpaceReturnsOnCall[i] = struct {
+		result1 migrate.StateStore
+	}{result1}
+}
+
+func (fake *FakeStateStore) List() ([]migrate.State, error) {
+	fake.listMutex.Lock()
+	ret, specificReturn := fake.listReturnsOnCall[len(fake.listArgsForCall)]
+	fake.listArgsForCall = append(fake.listArgsForCall, struct {
+	}{})
+	stub := fake.ListStub
+	fakeReturns := fake.listReturns
+	fake.recordInvocation("List", []interface{}{})
+	fake.listMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStateStore) ListCallCount() int {
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	return len(fake.listArgsForCall)
+}
+
+func (fake *FakeStateStore) ListCalls(stub func() ([]migrate.State, error)) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = stub
+}
+
+func (fake *FakeStateStore) ListReturns(result1 []migrate.State, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	fake.listReturns = struct {
+		result1 []migrate.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) ListReturnsOnCall(i int, result1 []migrate.State, result2 error) {
+	fake.listMutex.Lock()
+	defer fake.listMutex.Unlock()
+	fake.ListStub = nil
+	if fake.listReturnsOnCall == nil {
+		fake.listReturnsOnCall = make(map[int]struct {
+			result1 []migrate.State
+			result2 error
+		})
+	}
+	fake.listReturnsOnCall[i] = struct {
+		result1 []migrate.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) Load(arg1 string) (migrate.State, error) {
+	fake.loadMutex.Lock()
+	ret, specificReturn := fake.loadReturnsOnCall[len(fake.loadArgsForCall)]
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LoadStub
+	fakeReturns := fake.loadReturns
+	fake.recordInvocation("Load", []interface{}{arg1})
+	fake.loadMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStateStore) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *FakeStateStore) LoadCalls(stub func(string) (migrate.State, error)) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = stub
+}
+
+func (fake *FakeStateStore) LoadArgsForCall(i int) string {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	argsForCall := fake.loadArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStateStore) LoadReturns(result1 migrate.State, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 migrate.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) LoadReturnsOnCall(i int, result1 migrate.State, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	if fake.loadReturnsOnCall == nil {
+		fake.loadReturnsOnCall = make(map[int]struct {
+			result1 migrate.State
+			result2 error
+		})
+	}
+	fake.loadReturnsOnCall[i] = struct {
+		result1 migrate.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) Recipients() ([]string, error) {
+	fake.recipientsMutex.Lock()
+	ret, specificReturn := fake.recipientsReturnsOnCall[len(fake.recipientsArgsForCall)]
+	fake.recipientsArgsForCall = append(fake.recipientsArgsForCall, struct {
+	}{})
+	stub := fake.RecipientsStub
+	fakeReturns := fake.recipientsReturns
+	fake.recordInvocation("Recipients", []interface{}{})
+	fake.recipientsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeStateStore) RecipientsCallCount() int {
+	fake.recipientsMutex.RLock()
+	defer fake.recipientsMutex.RUnlock()
+	return len(fake.recipientsArgsForCall)
+}
+
+func (fake *FakeStateStore) RecipientsCalls(stub func() ([]string, error)) {
+	fake.recipientsMutex.Lock()
+	defer fake.recipientsMutex.Unlock()
+	fake.RecipientsStub = stub
+}
+
+func (fake *FakeStateStore) RecipientsReturns(result1 []string, result2 error) {
+	fake.recipientsMutex.Lock()
+	defer fake.recipientsMutex.Unlock()
+	fake.RecipientsStub = nil
+	fake.recipientsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) RecipientsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.recipientsMutex.Lock()
+	defer fake.recipientsMutex.Unlock()
+	fake.RecipientsStub = nil
+	if fake.recipientsReturnsOnCall == nil {
+		fake.recipientsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.recipientsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStateStore) RecordRecipient(arg1 string) error {
+	fake.recordRecipientMutex.Lock()
+	ret, specificReturn := fake.recordRecipientReturnsOnCall[len(fake.recordRecipientArgsForCall)]
+	fake.recordRecipientArgsForCall = append(fake.recordRecipientArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RecordRecipientStub
+	fakeReturns := fake.recordRecipientReturns
+	fake.recordInvocation("RecordRecipient", []interface{}{arg1})
+	fake.recordRecipientMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStateStore) RecordRecipientCallCount() int {
+	fake.recordRecipientMutex.RLock()
+	defer fake.recordRecipientMutex.RUnlock()
+	return len(fake.recordRecipientArgsForCall)
+}
+
+func (fake *FakeStateStore) RecordRecipientCalls(stub func(string) error) {
+	fake.recordRecipientMutex.Lock()
+	defer fake.recordRecipientMutex.Unlock()
+	fake.RecordRecipientStub = stub
+}
+
+func (fake *FakeStateStore) RecordRecipientArgsForCall(i int) string {
+	fake.recordRecipientMutex.RLock()
+	defer fake.recordRecipientMutex.RUnlock()
+	argsForCall := fake.recordRecipientArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStateStore) RecordRecipientReturns(result1 error) {
+	fake.recordRecipientMutex.Lock()
+	defer fake.recordRecipientMutex.Unlock()
+	fake.RecordRecipientStub = nil
+	fake.recordRecipientReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) RecordRecipientReturnsOnCall(i int, result1 error) {
+	fake.recordRecipientMutex.Lock()
+	defer fake.recordRecipientMutex.Unlock()
+	fake.RecordRecipientStub = nil
+	if fake.recordRecipientReturnsOnCall == nil {
+		fake.recordRecipientReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.recordRecipientReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) Remove(arg1 string) error {
+	fake.removeMutex.Lock()
+	ret, specificReturn := fake.removeReturnsOnCall[len(fake.removeArgsForCall)]
+	fake.removeArgsForCall = append(fake.removeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RemoveStub
+	fakeReturns := fake.removeReturns
+	fake.recordInvocation("Remove", []interface{}{arg1})
+	fake.removeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStateStore) RemoveCallCount() int {
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	return len(fake.removeArgsForCall)
+}
+
+func (fake *FakeStateStore) RemoveCalls(stub func(string) error) {
+	fake.removeMutex.Lock()
+	defer fake.removeMutex.Unlock()
+	fake.RemoveStub = stub
+}
+
+func (fake *FakeStateStore) RemoveArgsForCall(i int) string {
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	argsForCall := fake.removeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStateStore) RemoveReturns(result1 error) {
+	fake.removeMutex.Lock()
+	defer fake.removeMutex.Unlock()
+	fake.RemoveStub = nil
+	fake.removeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) RemoveReturnsOnCall(i int, result1 error) {
+	fake.removeMutex.Lock()
+	defer fake.removeMutex.Unlock()
+	fake.RemoveStub = nil
+	if fake.removeReturnsOnCall == nil {
+		fake.removeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) Save(arg1 migrate.State) error {
+	fake.saveMutex.Lock()
+	ret, specificReturn := fake.saveReturnsOnCall[len(fake.saveArgsForCall)]
+	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
+		arg1 migrate.State
+	}{arg1})
+	stub := fake.SaveStub
+	fakeReturns := fake.saveReturns
+	fake.recordInvocation("Save", []interface{}{arg1})
+	fake.saveMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeStateStore) SaveCallCount() int {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	return len(fake.saveArgsForCall)
+}
+
+func (fake *FakeStateStore) SaveCalls(stub func(migrate.State) error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = stub
+}
+
+func (fake *FakeStateStore) SaveArgsForCall(i int) migrate.State {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	argsForCall := fake.saveArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeStateStore) SaveReturns(result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	fake.saveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) SaveReturnsOnCall(i int, result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	if fake.saveReturnsOnCall == nil {
+		fake.saveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeStateStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.forgetRecipientMutex.RLock()
+	defer fake.forgetRecipientMutex.RUnlock()
+	fake.inSpaceMutex.RLock()
+	defer fake.inSpaceMutex.RUnlock()
+	fake.listMutex.RLock()
+	defer fake.listMutex.RUnlock()
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	fake.recipientsMutex.RLock()
+	defer fake.recipientsMutex.RUnlock()
+	fake.recordRecipientMutex.RLock()
+	defer fake.recordRecipientMutex.RUnlock()
+	fake.removeMutex.RLock()
+	defer fake.removeMutex.RUnlock()
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeStateStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ migrate.StateStore = new(FakeStateStore)
@@ -4,14 +4,16 @@ package migratefakes
 import (
 	"sync"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
 )
 
 type FakeUnpacker struct {
-	UnpackStub        func(string) error
+	UnpackStub        func(string, app.Settings) error
 	unpackMutex       sync.RWMutex
 	unpackArgsForCall []struct {
 		arg1 string
+		arg2 app.Settings
 	}
 	unpackReturns struct {
 		result1 error
@@ -23,18 +25,19 @@ type FakeUnpacker struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeUnpacker) Unpack(arg1 string) error {
+func (fake *FakeUnpacker) Unpack(arg1 string, arg2 app.Settings) error {
 	fake.unpackMutex.Lock()
 	ret, specificReturn := fake.unpackReturnsOnCall[len(fake.unpackArgsForCall)]
 	fake.unpackArgsForCall = append(fake.unpackArgsForCall, struct {
 		arg1 string
-	}{arg1})
+		arg2 app.Settings
+	}{arg1, arg2})
 	stub := fake.UnpackStub
 	fakeReturns := fake.unpackReturns
-	fake.recordInvocation("Unpack", []interface{}{arg1})
+	fake.recordInvocation("Unpack", []interface{}{arg1, arg2})
 	fake.unpackMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -48,17 +51,17 @@ func (fake *FakeUnpacker) UnpackCallCount() int {
 	return len(fake.unpackArgsForCall)
 }
 
-func (fake *FakeUnpacker) UnpackCalls(stub func(string) error) {
+func (fake *FakeUnpacker) UnpackCalls(stub func(string, app.Settings) error) {
 	fake.unpackMutex.Lock()
 	defer fake.unpackMutex.Unlock()
 	fake.UnpackStub = stub
 }
 
-func (fake *FakeUnpacker) UnpackArgsForCall(i int) string {
+func (fake *FakeUnpacker) UnpackArgsForCall(i int) (string, app.Settings) {
 	fake.unpackMutex.RLock()
 	defer fake.unpackMutex.RUnlock()
 	argsForCall := fake.unpackArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeUnpacker) UnpackReturns(result1 error) {
@@ -0,0 +1,225 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+)
+
+type PreflightStatus string
+
+const (
+	PreflightPassed  PreflightStatus = "PASS"
+	PreflightWarning PreflightStatus = "WARN"
+	PreflightFailed  PreflightStatus = "FAIL"
+	PreflightSkipped PreflightStatus = "SKIP"
+)
+
+// Unlimited marks a quota without an upper bound
+const Unlimited = -1
+
+type PreflightCheck struct {
+	Name   string
+	Status PreflightStatus
+	Detail string
+}
+
+type DonorSummary struct {
+	Schemas            []string
+	ExcludedTables     []string
+	InvalidViews       []string
+	DataSizeBytes      int64
+	UnsupportedObjects []string
+}
+
+type PreflightReport struct {
+	Checks []PreflightCheck
+	Donor  *DonorSummary
+}
+
+// Passed reports whether no check failed. Warnings and skipped checks do not prevent a migration.
+func (r PreflightReport) Passed() bool {
+	for _, c := range r.Checks {
+		if c.Status == PreflightFailed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *PreflightReport) add(name string, status PreflightStatus, detail string, args ...interface{}) {
+	r.Checks = append(r.Checks, PreflightCheck{Name: name, Status: status, Detail: fmt.Sprintf(detail, args...)})
+}
+
+// Quota is what remains of the org and space quotas in the targeted space
+type Quota struct {
+	ServiceInstances int
+	AppInstances     int
+	MemoryMB         int
+	// ProcessMemoryMB is the most memory a single app instance or task may use
+	ProcessMemoryMB int
+}
+
+type ServiceCredentials struct {
+	Hostname string
+	Name     string
+	Username string
+	Password string
+	Port     int
+	CA       string
+}
+
+//counterfeiter:generate . DonorInspector
+type DonorInspector interface {
+	Inspect(credentials ServiceCredentials, opts MigrateOptions) (DonorSummary, error)
+}
+
+func RecipientProductName() string {
+	if productName := os.Getenv("RECIPIENT_PRODUCT_NAME"); productName != "" {
+		return productName
+	}
+
+	return "p.mysql"
+}
+
+// Preflight checks whether a migration is expected to succeed, without creating a recipient service
+// instance or pushing the migration app. The donor is inspected using a temporary service key.
+func (m *Migrator) Preflight(opts MigrateOptions, planName string) PreflightReport {
+	var report PreflightReport
+
+	donorExists := m.client.ServiceExists(opts.DonorInstanceName)
+	if donorExists {
+		report.add("donor service instance", PreflightPassed, "%s exists", opts.DonorInstanceName)
+	} else {
+		report.add("donor service instance", PreflightFailed, "%s not found", opts.DonorInstanceName)
+	}
+
+	recipientExists := m.client.ServiceExists(opts.RecipientInstanceName)
+	switch {
+	case opts.ExistingRecipient && recipientExists:
+		report.add("recipient service instance", PreflightPassed, "%s exists and will be migrated into", opts.RecipientInstanceName)
+	case opts.ExistingRecipient:
+		report.add("recipient service instance", PreflightFailed, "%s not found", opts.RecipientInstanceName)
+	case recipientExists:
+		report.add("recipient service instance", PreflightFailed, "%s already exists", opts.RecipientInstanceName)
+	default:
+		report.add("recipient service instance", PreflightPassed, "%s will be created", opts.RecipientInstanceName)
+	}
+
+	if opts.ExistingRecipient {
+		report.add("service plan", PreflightSkipped, "migrating into an existing service instance")
+	} else {
+		productName := RecipientProductName()
+		if exists, err := m.client.ServicePlanExists(productName, planName); err != nil {
+			report.add("service plan", PreflightFailed, "unable to look up plan %s of service %s: %s", planName, productName, err)
+		} else if !exists {
+			report.add("service plan", PreflightFailed, "plan %s of service %s not found in the marketplace", planName, productName)
+		} else {
+			report.add("service plan", PreflightPassed, "plan %s of service %s is available", planName, productName)
+		}
+	}
+
+	m.checkQuota(&report, opts)
+
+	if opts.App != (app.Settings{}) || opts.IsolationSegment != "" {
+		if err := m.CheckAppSettings(opts); err != nil {
+			report.add("migration app", PreflightFailed, "%s", err)
+		} else {
+			report.add("migration app", PreflightPassed, "can be pushed with the given stack, isolation segment and memory")
+		}
+	}
+
+	if !donorExists {
+		report.add("donor discovery", PreflightSkipped, "donor service instance not found")
+		return report
+	}
+
+	m.inspectDonor(&report, opts)
+
+	return report
+}
+
+func (m *Migrator) checkQuota(report *PreflightReport, opts MigrateOptions) {
+	quota, err := m.client.RemainingQuota()
+	if err != nil {
+		report.add("quota", PreflightWarning, "unable to determine the remaining quota: %s", err)
+		return
+	}
+
+	detail := fmt.Sprintf("remaining: %s service instances, %s app instances, %s MB memory",
+		quotaString(quota.ServiceInstances), quotaString(quota.AppInstances), quotaString(quota.MemoryMB))
+
+	needed := migrationMemoryMB(opts.App)
+
+	switch {
+	case !opts.ExistingRecipient && quota.ServiceInstances == 0:
+		report.add("quota", PreflightFailed, "no service instance can be created, %s", detail)
+	case quota.AppInstances == 0:
+		report.add("quota", PreflightFailed, "the migration app can not be started, %s", detail)
+	case quota.MemoryMB != Unlimited && needed > quota.MemoryMB:
+		report.add("quota", PreflightFailed, "the migration app and its task need %d MB of memory, %s", needed, detail)
+	default:
+		report.add("quota", PreflightPassed, "%s", detail)
+	}
+}
+
+func (m *Migrator) inspectDonor(report *PreflightReport, opts MigrateOptions) {
+	keyName := "migrate-preflight-" + uuid.NewString()
+
+	log.Printf("Creating temporary service key %s to inspect %s, which is deleted once done", keyName, opts.DonorInstanceName)
+	credentials, err := m.client.CreateServiceKey(opts.DonorInstanceName, keyName)
+	if err != nil {
+		report.add("donor discovery", PreflightFailed, "unable to create a service key for %s: %s", opts.DonorInstanceName, err)
+		return
+	}
+	defer func() {
+		if err := m.client.DeleteServiceKey(opts.DonorInstanceName, keyName); err != nil {
+			log.Printf("Warning: failed to delete service key %s of %s: %s", keyName, opts.DonorInstanceName, err)
+			return
+		}
+		log.Printf("Deleted temporary service key %s", keyName)
+	}()
+
+	summary, err := m.inspector.Inspect(credentials, opts)
+	if err != nil {
+		// The donor is often only reachable from within the foundation, which is why this does not fail the preflight
+		report.add("donor discovery", PreflightWarning, "unable to inspect %s from this machine: %s", opts.DonorInstanceName, err)
+		return
+	}
+	report.Donor = &summary
+
+	switch {
+	case len(summary.UnsupportedObjects) > 0:
+		report.add("donor discovery", PreflightWarning, "%d objects will not be migrated", len(summary.UnsupportedObjects))
+	case len(summary.InvalidViews) > 0:
+		report.add("donor discovery", PreflightWarning, "%d invalid views will not be migrated", len(summary.InvalidViews))
+	default:
+		report.add("donor discovery", PreflightPassed, "%d schemas can be migrated", len(summary.Schemas))
+	}
+}
+
+func quotaString(value int) string {
+	if value == Unlimited {
+		return "unlimited"
+	}
+
+	return strconv.Itoa(value)
+}
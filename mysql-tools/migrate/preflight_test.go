@@ -0,0 +1,294 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("Preflight", func() {
+	var (
+		fakeClient    *migratefakes.FakeClient
+		fakeInspector *migratefakes.FakeDonorInspector
+		migrator      *Migrator
+		opts          MigrateOptions
+		credentials   ServiceCredentials
+	)
+
+	check := func(report PreflightReport, name string) PreflightCheck {
+		for _, c := range report.Checks {
+			if c.Name == name {
+				return c
+			}
+		}
+		Fail("no check named " + name)
+		return PreflightCheck{}
+	}
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		fakeInspector = new(migratefakes.FakeDonorInspector)
+		migrator = NewMigrator(fakeClient, nil, nil, fakeInspector, nil, nil)
+
+		opts = MigrateOptions{
+			DonorInstanceName:     "some-donor",
+			RecipientInstanceName: "some-donor-new",
+		}
+		credentials = ServiceCredentials{Hostname: "some-host", Username: "some-user"}
+
+		fakeClient.ServiceExistsStub = func(name string) bool {
+			return name == "some-donor"
+		}
+		fakeClient.ServicePlanExistsReturns(true, nil)
+		fakeClient.RemainingQuotaReturns(Quota{ServiceInstances: 3, AppInstances: Unlimited, MemoryMB: 2048}, nil)
+		fakeClient.CreateServiceKeyReturns(credentials, nil)
+		fakeInspector.InspectReturns(DonorSummary{Schemas: []string{"foo"}, DataSizeBytes: 1024}, nil)
+	})
+
+	It("passes when the migration is expected to succeed", func() {
+		report := migrator.Preflight(opts, "some-plan")
+
+		Expect(report.Passed()).To(BeTrue())
+		Expect(report.Checks).To(Equal([]PreflightCheck{
+			{Name: "donor service instance", Status: PreflightPassed, Detail: "some-donor exists"},
+			{Name: "recipient service instance", Status: PreflightPassed, Detail: "some-donor-new will be created"},
+			{Name: "service plan", Status: PreflightPassed, Detail: "plan some-plan of service p.mysql is available"},
+			{Name: "quota", Status: PreflightPassed, Detail: "remaining: 3 service instances, unlimited app instances, 2048 MB memory"},
+			{Name: "donor discovery", Status: PreflightPassed, Detail: "1 schemas can be migrated"},
+		}))
+		Expect(report.Donor).To(Equal(&DonorSummary{Schemas: []string{"foo"}, DataSizeBytes: 1024}))
+	})
+
+	It("looks up the plan of the recipient product", func() {
+		GinkgoT().Setenv("RECIPIENT_PRODUCT_NAME", "some-product")
+
+		migrator.Preflight(opts, "some-plan")
+
+		product, plan := fakeClient.ServicePlanExistsArgsForCall(0)
+		Expect(product).To(Equal("some-product"))
+		Expect(plan).To(Equal("some-plan"))
+	})
+
+	It("inspects the donor using a temporary service key", func() {
+		migrator.Preflight(opts, "some-plan")
+
+		Expect(fakeClient.CreateServiceKeyCallCount()).To(Equal(1))
+		instance, keyName := fakeClient.CreateServiceKeyArgsForCall(0)
+		Expect(instance).To(Equal("some-donor"))
+		Expect(keyName).To(HavePrefix("migrate-preflight-"))
+
+		inspectedCredentials, inspectedOpts := fakeInspector.InspectArgsForCall(0)
+		Expect(inspectedCredentials).To(Equal(credentials))
+		Expect(inspectedOpts).To(Equal(opts))
+
+		Expect(fakeClient.DeleteServiceKeyCallCount()).To(Equal(1))
+		deletedInstance, deletedKey := fakeClient.DeleteServiceKeyArgsForCall(0)
+		Expect(deletedInstance).To(Equal("some-donor"))
+		Expect(deletedKey).To(Equal(keyName))
+	})
+
+	It("never creates a service instance or pushes the migration app", func() {
+		migrator.Preflight(opts, "some-plan")
+
+		Expect(fakeClient.CreateServiceInstanceCallCount()).To(BeZero())
+		Expect(fakeClient.PushAppCallCount()).To(BeZero())
+	})
+
+	When("the donor does not exist", func() {
+		BeforeEach(func() {
+			fakeClient.ServiceExistsReturns(false)
+			fakeClient.ServiceExistsStub = nil
+		})
+
+		It("fails and skips the donor discovery", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(report.Passed()).To(BeFalse())
+			Expect(check(report, "donor service instance").Status).To(Equal(PreflightFailed))
+			Expect(check(report, "donor discovery").Status).To(Equal(PreflightSkipped))
+			Expect(fakeClient.CreateServiceKeyCallCount()).To(BeZero())
+		})
+	})
+
+	When("the recipient service instance already exists", func() {
+		BeforeEach(func() {
+			fakeClient.ServiceExistsStub = nil
+			fakeClient.ServiceExistsReturns(true)
+		})
+
+		It("fails", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(report.Passed()).To(BeFalse())
+			Expect(check(report, "recipient service instance")).To(Equal(PreflightCheck{
+				Name: "recipient service instance", Status: PreflightFailed, Detail: "some-donor-new already exists",
+			}))
+		})
+	})
+
+	When("migrating into an existing recipient", func() {
+		BeforeEach(func() {
+			opts.ExistingRecipient = true
+			opts.RecipientInstanceName = "some-recipient"
+			fakeClient.ServiceExistsReturns(true)
+			fakeClient.ServiceExistsStub = nil
+			fakeClient.RemainingQuotaReturns(Quota{ServiceInstances: 0, AppInstances: 1, MemoryMB: 2048}, nil)
+		})
+
+		It("requires the recipient to exist and does not need a plan or service instance quota", func() {
+			report := migrator.Preflight(opts, "")
+
+			Expect(report.Passed()).To(BeTrue())
+			Expect(check(report, "recipient service instance").Detail).To(Equal("some-recipient exists and will be migrated into"))
+			Expect(check(report, "service plan").Status).To(Equal(PreflightSkipped))
+			Expect(fakeClient.ServicePlanExistsCallCount()).To(BeZero())
+		})
+
+		It("fails when the recipient does not exist", func() {
+			fakeClient.ServiceExistsStub = func(name string) bool {
+				return name == "some-donor"
+			}
+
+			report := migrator.Preflight(opts, "")
+			Expect(check(report, "recipient service instance").Status).To(Equal(PreflightFailed))
+		})
+	})
+
+	When("the plan is not in the marketplace", func() {
+		BeforeEach(func() {
+			fakeClient.ServicePlanExistsReturns(false, nil)
+		})
+
+		It("fails", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(report.Passed()).To(BeFalse())
+			Expect(check(report, "service plan").Detail).To(Equal("plan some-plan of service p.mysql not found in the marketplace"))
+		})
+	})
+
+	When("the quota is exhausted", func() {
+		It("fails when no service instance can be created", func() {
+			fakeClient.RemainingQuotaReturns(Quota{ServiceInstances: 0, AppInstances: 1, MemoryMB: 1024}, nil)
+
+			report := migrator.Preflight(opts, "some-plan")
+			Expect(check(report, "quota").Status).To(Equal(PreflightFailed))
+		})
+
+		It("fails when the migration app can not be started", func() {
+			fakeClient.RemainingQuotaReturns(Quota{ServiceInstances: 1, AppInstances: 0, MemoryMB: 4096}, nil)
+
+			report := migrator.Preflight(opts, "some-plan")
+			Expect(check(report, "quota").Status).To(Equal(PreflightFailed))
+		})
+
+		It("fails when the memory left does not fit the migration app and its task", func() {
+			fakeClient.RemainingQuotaReturns(Quota{ServiceInstances: 1, AppInstances: 2, MemoryMB: 1024}, nil)
+
+			report := migrator.Preflight(opts, "some-plan")
+			Expect(check(report, "quota")).To(Equal(PreflightCheck{
+				Name:   "quota",
+				Status: PreflightFailed,
+				Detail: "the migration app and its task need 2048 MB of memory, remaining: 1 service instances, 2 app instances, 1024 MB memory",
+			}))
+		})
+
+		It("counts the memory set for the migration app", func() {
+			opts.App.MemoryMB = 2048
+			fakeClient.RemainingQuotaReturns(Quota{ServiceInstances: 1, AppInstances: 2, MemoryMB: 3072, ProcessMemoryMB: Unlimited}, nil)
+
+			report := migrator.Preflight(opts, "some-plan")
+			Expect(check(report, "quota").Status).To(Equal(PreflightFailed))
+			Expect(check(report, "quota").Detail).To(HavePrefix("the migration app and its task need 4096 MB of memory"))
+		})
+	})
+
+	When("the quota can not be determined", func() {
+		BeforeEach(func() {
+			fakeClient.RemainingQuotaReturns(Quota{}, errors.New("some cc error"))
+		})
+
+		It("warns", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(report.Passed()).To(BeTrue())
+			Expect(check(report, "quota").Status).To(Equal(PreflightWarning))
+		})
+	})
+
+	When("the service key can not be created", func() {
+		BeforeEach(func() {
+			fakeClient.CreateServiceKeyReturns(ServiceCredentials{}, errors.New("some broker error"))
+		})
+
+		It("fails", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(report.Passed()).To(BeFalse())
+			Expect(check(report, "donor discovery").Detail).
+				To(Equal("unable to create a service key for some-donor: some broker error"))
+			Expect(fakeClient.DeleteServiceKeyCallCount()).To(BeZero())
+		})
+	})
+
+	When("the donor can not be reached from this machine", func() {
+		BeforeEach(func() {
+			fakeInspector.InspectReturns(DonorSummary{}, errors.New("dial tcp: i/o timeout"))
+		})
+
+		It("warns and still deletes the service key", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(report.Passed()).To(BeTrue())
+			Expect(check(report, "donor discovery").Status).To(Equal(PreflightWarning))
+			Expect(report.Donor).To(BeNil())
+			Expect(fakeClient.DeleteServiceKeyCallCount()).To(Equal(1))
+		})
+	})
+
+	When("the donor has objects that will not be migrated", func() {
+		BeforeEach(func() {
+			fakeInspector.InspectReturns(DonorSummary{
+				Schemas:            []string{"foo"},
+				UnsupportedObjects: []string{"TRIGGER foo.bar (pass --include-stored-programs)"},
+			}, nil)
+		})
+
+		It("warns", func() {
+			report := migrator.Preflight(opts, "some-plan")
+
+			Expect(check(report, "donor discovery")).To(Equal(PreflightCheck{
+				Name: "donor discovery", Status: PreflightWarning, Detail: "1 objects will not be migrated",
+			}))
+		})
+	})
+
+	It("checks the settings of the migration app when given", func() {
+		opts.App = app.Settings{Stack: "windows"}
+		fakeClient.StackExistsReturns(false, nil)
+
+		report := migrator.Preflight(opts, "some-plan")
+
+		Expect(check(report, "migration app")).To(Equal(PreflightCheck{
+			Name: "migration app", Status: PreflightFailed, Detail: "stack windows not found",
+		}))
+		Expect(report.Passed()).To(BeFalse())
+	})
+})
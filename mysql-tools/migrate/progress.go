@@ -0,0 +1,215 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+)
+
+const (
+	// taskLogFilter selects the output of the migration task from the logs of the migration app
+	taskLogFilter = "APP/TASK/"
+
+	// logPollInterval limits how often the logs are retrieved while waiting for the migration task
+	logPollInterval = 5 * time.Second
+
+	// maxLogFlushAttempts bounds how long to wait for the last logs of the task to become available
+	maxLogFlushAttempts = 5
+
+	// finalLogTimeout bounds how long to keep retrieving the logs of a completed task whose final progress line the
+	// migration can not do without
+	finalLogTimeout = 2 * time.Minute
+
+	progressBarWidth = 20
+)
+
+// taskLogs prints the log lines of the migration task as they become available. Progress lines are rendered as a
+// progress bar instead of being printed.
+type taskLogs struct {
+	client  Client
+	appName string
+	sleep   func(time.Duration)
+	printed map[string]bool
+	// final is the last progress line of the task, once it completed
+	final progress.Update
+	done  bool
+}
+
+func (m *Migrator) newTaskLogs() *taskLogs {
+	return &taskLogs{
+		client:  m.client,
+		appName: m.appName,
+		sleep:   m.Sleep,
+		printed: map[string]bool{},
+	}
+}
+
+// print prints the lines that were not printed yet. cf logs only returns recent lines, so lines are remembered
+// rather than counted.
+func (l *taskLogs) print(filter string) error {
+	lines, err := l.client.GetLogs(l.appName, filter)
+	if err != nil {
+		return err
+	}
+
+	var (
+		latest      progress.Update
+		hasProgress bool
+	)
+
+	for _, line := range lines {
+		if l.printed[line] {
+			continue
+		}
+		l.printed[line] = true
+
+		if update, ok := progress.Parse(line); ok {
+			latest, hasProgress = update, true
+			continue
+		}
+
+		log.Print(line)
+	}
+
+	if hasProgress {
+		if latest.Done {
+			l.final, l.done = latest, true
+		}
+		log.Print(FormatProgress(latest))
+	}
+
+	return nil
+}
+
+// poller prints the lines of the running task every logPollInterval. It is meant to be called whenever the task
+// is polled.
+func (l *taskLogs) poller() func() {
+	var lastPoll time.Time
+
+	return func() {
+		if time.Since(lastPoll) < logPollInterval {
+			return
+		}
+		lastPoll = time.Now()
+
+		// Progress is only informational, so failing to retrieve it does not fail the migration
+		_ = l.print(taskLogFilter)
+	}
+}
+
+// flush prints the remaining lines once the task completed. Logs can take a few seconds to become available, so
+// they are retrieved until the final progress line of the task showed up.
+func (l *taskLogs) flush(filter string) {
+	for attempt := 0; attempt < maxLogFlushAttempts && !l.done; attempt++ {
+		if attempt > 0 {
+			l.sleep(time.Second)
+		}
+
+		if err := l.print(filter); err != nil {
+			return
+		}
+	}
+}
+
+// await retrieves the logs every logPollInterval until the final progress line of the task showed up, for up to
+// finalLogTimeout. It reports whether the line showed up.
+func (l *taskLogs) await(filter string) bool {
+	for waited := time.Duration(0); !l.done && waited < finalLogTimeout; waited += logPollInterval {
+		l.sleep(logPollInterval)
+
+		// Logs that can not be retrieved are retried like lines that are not available yet
+		_ = l.print(filter)
+	}
+
+	return l.done
+}
+
+// FormatProgress renders a progress line of the migration task as a progress bar followed by its details. Once
+// an online migration copied the data, it renders how far the recipient is behind the donor instead.
+func FormatProgress(update progress.Update) string {
+	if update.BinlogPosition != "" {
+		if update.LagBytes == 0 {
+			return fmt.Sprintf("Progress: caught up with the donor at binlog position %s", update.BinlogPosition)
+		}
+
+		return fmt.Sprintf("Progress: applying changes, %s of binlog behind the donor at binlog position %s", ByteSize(update.LagBytes), update.BinlogPosition)
+	}
+
+	// Tasks that copy nothing only report the fingerprint of the data of the service instance
+	if update.RecipientFingerprint != "" && update.TablesTotal == 0 && len(update.Schemas) == 0 {
+		return fmt.Sprintf("Progress: the data has fingerprint %s", update.RecipientFingerprint)
+	}
+
+	fraction := progressFraction(update)
+	filled := int(fraction * progressBarWidth)
+
+	details := []string{
+		fmt.Sprintf("[%s%s] %3d%%", strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), int(fraction*100)),
+		fmt.Sprintf("%d of %d tables", update.TablesCopied, update.TablesTotal),
+		fmt.Sprintf("%s, %d rows", ByteSize(update.Bytes), update.Rows),
+		fmt.Sprintf("elapsed %s", time.Duration(update.ElapsedSeconds)*time.Second),
+	}
+
+	switch {
+	case fraction == 1:
+	case update.ETASeconds > 0:
+		details = append(details, fmt.Sprintf("ETA %s", time.Duration(update.ETASeconds)*time.Second))
+	default:
+		details = append(details, "ETA unknown")
+	}
+
+	if len(update.Tables) > 0 {
+		details = append(details, "copying "+strings.Join(update.Tables, ", "))
+	}
+
+	return "Progress: " + strings.Join(details, " | ")
+}
+
+func progressFraction(update progress.Update) float64 {
+	if update.Done || (update.TablesTotal > 0 && update.TablesCopied == update.TablesTotal) {
+		return 1
+	}
+
+	var fraction float64
+	switch {
+	case update.EstimatedRows > 0:
+		fraction = float64(update.Rows) / float64(update.EstimatedRows)
+	case update.TablesTotal > 0:
+		fraction = float64(update.TablesCopied) / float64(update.TablesTotal)
+	}
+
+	// Row counts are estimated, so completion is only shown once every table has been copied
+	return math.Min(fraction, 0.99)
+}
+
+// ByteSize renders a number of bytes with a binary unit, such as 1.5 GiB
+func ByteSize(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
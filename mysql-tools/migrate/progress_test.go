@@ -0,0 +1,83 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+)
+
+var _ = Describe("FormatProgress", func() {
+	It("renders the progress of the copy with the tables being copied and the remaining time", func() {
+		Expect(FormatProgress(progress.Update{
+			Tables:         []string{"app.orders", "app.users"},
+			TablesCopied:   3,
+			TablesTotal:    10,
+			Bytes:          3 * 1024 * 1024,
+			Rows:           2500,
+			EstimatedRows:  10000,
+			ElapsedSeconds: 130,
+			ETASeconds:     390,
+		})).To(Equal("Progress: [#####---------------]  25% | 3 of 10 tables | 3.0 MiB, 2500 rows | elapsed 2m10s | ETA 6m30s | copying app.orders, app.users"))
+	})
+
+	It("does not show completion before every table was copied", func() {
+		Expect(FormatProgress(progress.Update{
+			TablesCopied:  9,
+			TablesTotal:   10,
+			Rows:          12000,
+			EstimatedRows: 10000,
+		})).To(Equal("Progress: [###################-]  99% | 9 of 10 tables | 0 B, 12000 rows | elapsed 0s | ETA unknown"))
+	})
+
+	It("falls back to the tables copied when the row count is unknown", func() {
+		Expect(FormatProgress(progress.Update{
+			TablesCopied: 1,
+			TablesTotal:  2,
+		})).To(HavePrefix("Progress: [##########----------]  50% |"))
+	})
+
+	It("renders a completed copy", func() {
+		Expect(FormatProgress(progress.Update{
+			TablesCopied:   2,
+			TablesTotal:    2,
+			Bytes:          2048,
+			Rows:           40,
+			EstimatedRows:  42,
+			ElapsedSeconds: 61,
+			Done:           true,
+		})).To(Equal("Progress: [####################] 100% | 2 of 2 tables | 2.0 KiB, 40 rows | elapsed 1m1s"))
+	})
+
+	It("renders how far an online migration is behind the donor", func() {
+		Expect(FormatProgress(progress.Update{
+			TablesCopied:   2,
+			TablesTotal:    2,
+			BinlogPosition: "mysql-bin.000003:154",
+			LagBytes:       2048,
+		})).To(Equal("Progress: applying changes, 2.0 KiB of binlog behind the donor at binlog position mysql-bin.000003:154"))
+
+		Expect(FormatProgress(progress.Update{
+			BinlogPosition: "mysql-bin.000003:2202",
+		})).To(Equal("Progress: caught up with the donor at binlog position mysql-bin.000003:2202"))
+	})
+	It("renders the fingerprint reported by tasks that copy nothing", func() {
+		Expect(FormatProgress(progress.Update{
+			RecipientFingerprint: "some-fingerprint",
+			Done:                 true,
+		})).To(Equal("Progress: the data has fingerprint some-fingerprint"))
+	})
+})
@@ -0,0 +1,111 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	findbindings "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/find-bindings"
+)
+
+const (
+	AppBinding        = "AppBinding"
+	ServiceKeyBinding = "ServiceKeyBinding"
+)
+
+//counterfeiter:generate . BindingFinder
+type BindingFinder interface {
+	FindBindingsForInstance(instanceGUID string) ([]findbindings.Binding, error)
+}
+
+// RecordedBinding is an app binding or service key of the donor, recorded so that it can be recreated on the recipient
+type RecordedBinding struct {
+	Type       string
+	Name       string
+	Parameters string
+	// ParametersError is set when the binding parameters could not be retrieved, in which case it is recreated without them
+	ParametersError string
+}
+
+type RebindResult struct {
+	Binding RecordedBinding
+	Err     error
+}
+
+// RecordBindings looks up the app bindings and service keys of a service instance, including their parameters
+func (m *Migrator) RecordBindings(instanceName string) ([]RecordedBinding, error) {
+	instanceGUID, err := m.client.ServiceInstanceGUID(instanceName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup service instance %s: %w", instanceName, err)
+	}
+
+	bindings, err := m.finder.FindBindingsForInstance(instanceGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded []RecordedBinding
+	for _, b := range bindings {
+		// A migration app left behind by --no-cleanup is not worth rebinding
+		if b.Type == AppBinding && strings.HasPrefix(b.Name, migrationAppPrefix) {
+			continue
+		}
+
+		binding := RecordedBinding{Type: b.Type, Name: b.Name}
+		if binding.Parameters, err = m.client.BindingParameters(b.Guid); err != nil {
+			binding.ParametersError = err.Error()
+		}
+
+		recorded = append(recorded, binding)
+	}
+
+	return recorded, nil
+}
+
+// Rebind moves app bindings from one service instance to another and recreates service keys under the same names.
+// Every binding is attempted, and failures are collected in the results rather than stopping at the first one.
+func (m *Migrator) Rebind(bindings []RecordedBinding, fromInstanceName, toInstanceName string, restage bool) []RebindResult {
+	results := make([]RebindResult, 0, len(bindings))
+
+	for _, b := range bindings {
+		var errs error
+
+		switch b.Type {
+		case AppBinding:
+			if err := m.client.UnbindService(b.Name, fromInstanceName); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+
+			if err := m.client.BindServiceWithParameters(b.Name, toInstanceName, b.Parameters); err != nil {
+				errs = multierror.Append(errs, err)
+			} else if restage {
+				if err := m.client.RestageApp(b.Name); err != nil {
+					errs = multierror.Append(errs, err)
+				}
+			}
+		case ServiceKeyBinding:
+			if err := m.client.CreateServiceKeyWithParameters(toInstanceName, b.Name, b.Parameters); err != nil {
+				errs = multierror.Append(errs, err)
+			}
+		default:
+			errs = fmt.Errorf("unknown binding type %q", b.Type)
+		}
+
+		results = append(results, RebindResult{Binding: b, Err: errs})
+	}
+
+	return results
+}
@@ -0,0 +1,148 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	findbindings "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/find-bindings"
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("RecordBindings", func() {
+	var (
+		fakeClient *migratefakes.FakeClient
+		fakeFinder *migratefakes.FakeBindingFinder
+		migrator   *Migrator
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		fakeFinder = new(migratefakes.FakeBindingFinder)
+		migrator = NewMigrator(fakeClient, nil, nil, nil, fakeFinder, nil)
+
+		fakeClient.ServiceInstanceGUIDReturns("donor-guid", nil)
+		fakeFinder.FindBindingsForInstanceReturns([]findbindings.Binding{
+			{Guid: "binding-guid", Name: "some-app", Type: AppBinding},
+			{Guid: "migrate-app-guid", Name: "migrate-app-some-uuid", Type: AppBinding},
+			{Guid: "key-guid", Name: "some-key", Type: ServiceKeyBinding},
+		}, nil)
+		fakeClient.BindingParametersStub = func(guid string) (string, error) {
+			if guid == "key-guid" {
+				return "", errors.New("parameters not supported")
+			}
+			return `{"read_only":true}`, nil
+		}
+	})
+
+	It("records app bindings and service keys together with their parameters", func() {
+		bindings, err := migrator.RecordBindings("some-donor")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeClient.ServiceInstanceGUIDArgsForCall(0)).To(Equal("some-donor"))
+		Expect(fakeFinder.FindBindingsForInstanceArgsForCall(0)).To(Equal("donor-guid"))
+
+		Expect(bindings).To(Equal([]RecordedBinding{
+			{Type: AppBinding, Name: "some-app", Parameters: `{"read_only":true}`},
+			{Type: ServiceKeyBinding, Name: "some-key", ParametersError: "parameters not supported"},
+		}))
+	})
+
+	It("returns an error when the service instance can not be found", func() {
+		fakeClient.ServiceInstanceGUIDReturns("", errors.New("not found"))
+
+		_, err := migrator.RecordBindings("some-donor")
+		Expect(err).To(MatchError("failed to lookup service instance some-donor: not found"))
+	})
+
+	It("returns an error when the bindings can not be looked up", func() {
+		fakeFinder.FindBindingsForInstanceReturns(nil, errors.New("some-error"))
+
+		_, err := migrator.RecordBindings("some-donor")
+		Expect(err).To(MatchError("some-error"))
+	})
+})
+
+var _ = Describe("Rebind", func() {
+	var (
+		fakeClient *migratefakes.FakeClient
+		migrator   *Migrator
+		bindings   []RecordedBinding
+	)
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		migrator = NewMigrator(fakeClient, nil, nil, nil, nil, nil)
+
+		bindings = []RecordedBinding{
+			{Type: AppBinding, Name: "app-1", Parameters: `{"read_only":true}`},
+			{Type: AppBinding, Name: "app-2"},
+			{Type: ServiceKeyBinding, Name: "some-key"},
+		}
+	})
+
+	It("moves app bindings and recreates service keys", func() {
+		results := migrator.Rebind(bindings, "some-donor-old", "some-donor", false)
+
+		Expect(results).To(HaveLen(3))
+		for _, result := range results {
+			Expect(result.Err).NotTo(HaveOccurred())
+		}
+
+		Expect(fakeClient.UnbindServiceCallCount()).To(Equal(2))
+		app, svc := fakeClient.UnbindServiceArgsForCall(0)
+		Expect([]string{app, svc}).To(Equal([]string{"app-1", "some-donor-old"}))
+
+		Expect(fakeClient.BindServiceWithParametersCallCount()).To(Equal(2))
+		app, svc, params := fakeClient.BindServiceWithParametersArgsForCall(0)
+		Expect([]string{app, svc, params}).To(Equal([]string{"app-1", "some-donor", `{"read_only":true}`}))
+
+		Expect(fakeClient.CreateServiceKeyWithParametersCallCount()).To(Equal(1))
+		instance, key, params := fakeClient.CreateServiceKeyWithParametersArgsForCall(0)
+		Expect([]string{instance, key, params}).To(Equal([]string{"some-donor", "some-key", ""}))
+
+		Expect(fakeClient.RestageAppCallCount()).To(BeZero())
+	})
+
+	It("restages apps when asked to", func() {
+		migrator.Rebind(bindings, "some-donor-old", "some-donor", true)
+
+		Expect(fakeClient.RestageAppCallCount()).To(Equal(2))
+		Expect(fakeClient.RestageAppArgsForCall(1)).To(Equal("app-2"))
+	})
+
+	It("collects failures and continues with the remaining bindings", func() {
+		fakeClient.BindServiceWithParametersStub = func(app, _, _ string) error {
+			if app == "app-1" {
+				return errors.New("bind failed")
+			}
+			return nil
+		}
+		fakeClient.CreateServiceKeyWithParametersReturns(errors.New("key failed"))
+
+		results := migrator.Rebind(bindings, "some-donor-old", "some-donor", true)
+
+		Expect(results).To(HaveLen(3))
+		Expect(results[0].Err).To(MatchError(ContainSubstring("bind failed")))
+		Expect(results[1].Err).NotTo(HaveOccurred())
+		Expect(results[2].Err).To(MatchError(ContainSubstring("key failed")))
+
+		By("not restaging an app that could not be bound")
+		Expect(fakeClient.RestageAppCallCount()).To(Equal(1))
+		Expect(fakeClient.RestageAppArgsForCall(0)).To(Equal("app-2"))
+	})
+})
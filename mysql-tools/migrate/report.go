@@ -0,0 +1,147 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RenameStatus is how far RenameServiceInstances got
+type RenameStatus string
+
+const (
+	RenameNotAttempted    RenameStatus = "not-attempted"
+	RenameSkipped         RenameStatus = "skipped"
+	RenameDonorFailed     RenameStatus = "donor-rename-failed"
+	RenameRecipientFailed RenameStatus = "recipient-rename-failed"
+	// RenameRolledBack is when the recipient could not be renamed, and the donor was renamed back
+	RenameRolledBack RenameStatus = "rolled-back"
+	RenameCompleted  RenameStatus = "completed"
+)
+
+// MigrationReport describes a migration for change-management records. It is written even when the migration failed.
+type MigrationReport struct {
+	Donor      ReportedInstance `json:"donor" yaml:"donor"`
+	Recipient  ReportedInstance `json:"recipient" yaml:"recipient"`
+	Plan       string           `json:"plan,omitempty" yaml:"plan,omitempty"`
+	StartedAt  time.Time        `json:"started_at" yaml:"started_at"`
+	FinishedAt time.Time        `json:"finished_at" yaml:"finished_at"`
+	Succeeded  bool             `json:"succeeded" yaml:"succeeded"`
+	Error      string           `json:"error,omitempty" yaml:"error,omitempty"`
+	// Phase is the last phase the migration completed
+	Phase        Phase           `json:"phase" yaml:"phase"`
+	Phases       []ReportedPhase `json:"phases" yaml:"phases"`
+	Schemas      []string        `json:"schemas" yaml:"schemas"`
+	Tables       []string        `json:"tables" yaml:"tables"`
+	SkippedViews []string        `json:"skipped_views" yaml:"skipped_views"`
+	Rename       RenameStatus    `json:"rename" yaml:"rename"`
+}
+
+type ReportedInstance struct {
+	Name string `json:"name" yaml:"name"`
+	// CurrentName is the name of the service instance once the migration stopped, after renaming it
+	CurrentName string `json:"current_name" yaml:"current_name"`
+	// GUID is empty when the service instance no longer exists
+	GUID string `json:"guid,omitempty" yaml:"guid,omitempty"`
+}
+
+type ReportedPhase struct {
+	Phase           Phase     `json:"phase" yaml:"phase"`
+	FinishedAt      time.Time `json:"finished_at" yaml:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds" yaml:"duration_seconds"`
+}
+
+// BeginReport starts timing the migration being reported
+func (m *Migrator) BeginReport() {
+	now := m.Now().UTC()
+	m.report = MigrationReport{StartedAt: now, Rename: RenameNotAttempted}
+	m.phaseStartedAt = now
+}
+
+// WriteReport completes the report of the migration described by state, which failed with migrationErr unless it
+// is nil, and writes it to path. The report is written as YAML when path ends in .yml or .yaml, and as JSON
+// otherwise.
+func (m *Migrator) WriteReport(path string, state State, migrationErr error) error {
+	report := m.report
+	report.FinishedAt = m.Now().UTC()
+	report.Succeeded = migrationErr == nil
+	if migrationErr != nil {
+		report.Error = migrationErr.Error()
+	}
+	report.Plan = state.PlanName
+
+	donorName, recipientName := state.DonorInstanceName, state.RecipientInstanceName
+	report.Donor = ReportedInstance{Name: donorName, CurrentName: donorName}
+	report.Recipient = ReportedInstance{Name: recipientName, CurrentName: recipientName}
+
+	switch report.Rename {
+	case RenameCompleted:
+		report.Donor.CurrentName, report.Recipient.CurrentName = donorName+"-old", donorName
+	case RenameRecipientFailed:
+		report.Donor.CurrentName = donorName + "-old"
+	}
+
+	if state.Options.ExistingRecipient {
+		report.Rename = RenameSkipped
+	}
+
+	// The recipient was deleted when a failed migration was cleaned up, so a missing GUID is not an error
+	for _, instance := range []*ReportedInstance{&report.Donor, &report.Recipient} {
+		if instance.CurrentName != "" {
+			instance.GUID, _ = m.client.ServiceInstanceGUID(instance.CurrentName)
+		}
+	}
+
+	var (
+		contents []byte
+		err      error
+	)
+	switch filepath.Ext(path) {
+	case ".yml", ".yaml":
+		contents, err = yaml.Marshal(report)
+	default:
+		contents, err = json.MarshalIndent(report, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode the migration report: %w", err)
+	}
+
+	if err := os.WriteFile(path, contents, 0644); err != nil {
+		return fmt.Errorf("failed to write the migration report: %w", err)
+	}
+
+	return nil
+}
+
+// completePhase records how long a phase took, once the migration completed it. Phases completed before a
+// migration was resumed, and phases a failed task went back to, are not recorded again.
+func (m *Migrator) completePhase(phase Phase) {
+	if m.report.StartedAt.IsZero() || phaseIndex(phase) <= phaseIndex(m.report.Phase) {
+		return
+	}
+
+	now := m.Now().UTC()
+	m.report.Phases = append(m.report.Phases, ReportedPhase{
+		Phase:           phase,
+		FinishedAt:      now,
+		DurationSeconds: now.Sub(m.phaseStartedAt).Seconds(),
+	})
+	m.report.Phase = phase
+	m.phaseStartedAt = now
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("WriteReport", func() {
+	var (
+		fakeClient     *migratefakes.FakeClient
+		fakeStateStore *migratefakes.FakeStateStore
+		fakeCutovers   *migratefakes.FakeCutoverStore
+		migrator       *Migrator
+		now            time.Time
+		state          State
+		reportPath     string
+	)
+
+	readReport := func() MigrationReport {
+		contents, err := os.ReadFile(reportPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		var report MigrationReport
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+		return report
+	}
+
+	BeforeEach(func() {
+		fakeClient = new(migratefakes.FakeClient)
+		fakeClient.ServiceExistsStub = func(instanceName string) bool {
+			return instanceName != "some-donor-old"
+		}
+		fakeClient.ServiceInstanceGUIDStub = func(instanceName string) (string, error) {
+			return instanceName + "-guid", nil
+		}
+		fakeClient.StartTaskReturns("some-task-guid", nil)
+		fakeClient.GetLogsReturns([]string{
+			`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"tables_copied":2,"tables_total":2,"done":true,` +
+				`"schemas":["app"],"copied_tables":["app.users","app.orders"],"skipped_views":["app.broken"]}`,
+		}, nil)
+		fakeStateStore = new(migratefakes.FakeStateStore)
+		fakeStateStore.InSpaceReturns(fakeStateStore)
+		fakeStateStore.LoadReturns(State{}, ErrNoMigrationState)
+		fakeCutovers = new(migratefakes.FakeCutoverStore)
+
+		migrator = NewMigrator(fakeClient, new(migratefakes.FakeUnpacker), fakeStateStore, nil, nil, fakeCutovers)
+		migrator.Sleep = func(time.Duration) {}
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		migrator.Now = func() time.Time {
+			now = now.Add(10 * time.Second)
+			return now
+		}
+
+		state = State{
+			DonorInstanceName:     "some-donor",
+			RecipientInstanceName: "some-donor-new",
+			PlanName:              "some-plan",
+			Options: MigrateOptions{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+			},
+		}
+		reportPath = filepath.Join(GinkgoT().TempDir(), "report.json")
+	})
+
+	It("reports a migration that completed", func() {
+		migrator.BeginReport()
+		state.Phase = PhaseRecipientCreated
+		Expect(migrator.SaveState(state)).To(Succeed())
+		Expect(migrator.MigrateData(context.Background(), state.Options)).To(Succeed())
+		Expect(migrator.RenameServiceInstances("some-donor", "some-donor-new")).To(Succeed())
+
+		Expect(migrator.WriteReport(reportPath, state, nil)).To(Succeed())
+
+		report := readReport()
+		Expect(report.Donor).To(Equal(ReportedInstance{Name: "some-donor", CurrentName: "some-donor-old", GUID: "some-donor-old-guid"}))
+		Expect(report.Recipient).To(Equal(ReportedInstance{Name: "some-donor-new", CurrentName: "some-donor", GUID: "some-donor-guid"}))
+		Expect(report.Plan).To(Equal("some-plan"))
+		Expect(report.StartedAt).To(Equal(time.Date(2024, 1, 1, 0, 0, 10, 0, time.UTC)))
+		Expect(report.FinishedAt).To(BeTemporally(">", report.StartedAt))
+		Expect(report.Succeeded).To(BeTrue())
+		Expect(report.Error).To(BeEmpty())
+		Expect(report.Phase).To(Equal(PhaseRenamed))
+		Expect(report.Schemas).To(Equal([]string{"app"}))
+		Expect(report.Tables).To(Equal([]string{"app.users", "app.orders"}))
+		Expect(report.SkippedViews).To(Equal([]string{"app.broken"}))
+		Expect(report.Rename).To(Equal(RenameCompleted))
+
+		var phases []Phase
+		for _, phase := range report.Phases {
+			Expect(phase.DurationSeconds).To(BeEquivalentTo(10))
+			phases = append(phases, phase.Phase)
+		}
+		Expect(phases).To(Equal([]Phase{
+			PhaseRecipientCreated,
+			PhaseAppPushed,
+			PhaseServicesBound,
+			PhaseAppStarted,
+			PhaseTaskStarted,
+			PhaseDataMigrated,
+			PhaseRenamed,
+		}))
+	})
+
+	It("reports a migration that failed", func() {
+		fakeClient.WaitForTaskReturns(errors.New("task failed"))
+		fakeClient.ServiceInstanceGUIDStub = func(instanceName string) (string, error) {
+			if instanceName == "some-donor-new" {
+				return "", errors.New("service instance not found")
+			}
+			return instanceName + "-guid", nil
+		}
+
+		migrator.BeginReport()
+		err := migrator.MigrateData(context.Background(), state.Options)
+		Expect(err).To(HaveOccurred())
+
+		Expect(migrator.WriteReport(reportPath, state, err)).To(Succeed())
+
+		report := readReport()
+		Expect(report.Succeeded).To(BeFalse())
+		Expect(report.Error).To(Equal("task failed"))
+		Expect(report.Phase).To(Equal(PhaseTaskStarted))
+		Expect(report.Phases).To(HaveLen(4))
+		Expect(report.Donor).To(Equal(ReportedInstance{Name: "some-donor", CurrentName: "some-donor", GUID: "some-donor-guid"}))
+		Expect(report.Recipient).To(Equal(ReportedInstance{Name: "some-donor-new", CurrentName: "some-donor-new"}))
+		Expect(report.Tables).To(BeEmpty())
+		Expect(report.Rename).To(Equal(RenameNotAttempted))
+	})
+
+	It("reports which service instance failed to be renamed", func() {
+		fakeClient.RenameServiceReturnsOnCall(1, errors.New("name taken"))
+		fakeClient.RenameServiceReturnsOnCall(2, errors.New("name taken"))
+
+		migrator.BeginReport()
+		err := migrator.RenameServiceInstances("some-donor", "some-donor-new")
+		Expect(migrator.WriteReport(reportPath, state, err)).To(Succeed())
+
+		report := readReport()
+		Expect(report.Rename).To(Equal(RenameRecipientFailed))
+		Expect(report.Donor.CurrentName).To(Equal("some-donor-old"))
+		Expect(report.Recipient.CurrentName).To(Equal("some-donor-new"))
+	})
+
+	It("reports that the donor was renamed back when the recipient could not be renamed", func() {
+		fakeClient.RenameServiceReturnsOnCall(1, errors.New("name taken"))
+
+		migrator.BeginReport()
+		err := migrator.RenameServiceInstances("some-donor", "some-donor-new")
+		Expect(migrator.WriteReport(reportPath, state, err)).To(Succeed())
+
+		report := readReport()
+		Expect(report.Rename).To(Equal(RenameRolledBack))
+		Expect(report.Donor.CurrentName).To(Equal("some-donor"))
+		Expect(report.Recipient.CurrentName).To(Equal("some-donor-new"))
+	})
+
+	It("does not report the phases completed before resuming", func() {
+		fakeStateStore.LoadReturns(State{Phase: PhaseDataMigrated}, nil)
+
+		migrator.BeginReport()
+		_, err := migrator.LoadState("some-donor")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(migrator.RenameServiceInstances("some-donor", "some-donor-new")).To(Succeed())
+		Expect(migrator.WriteReport(reportPath, state, nil)).To(Succeed())
+
+		report := readReport()
+		Expect(report.Phases).To(HaveLen(1))
+		Expect(report.Phases[0].Phase).To(Equal(PhaseRenamed))
+	})
+
+	It("reports that service instances are not renamed when migrating into an existing one", func() {
+		state.Options.ExistingRecipient = true
+
+		migrator.BeginReport()
+		Expect(migrator.WriteReport(reportPath, state, nil)).To(Succeed())
+
+		Expect(readReport().Rename).To(Equal(RenameSkipped))
+	})
+
+	It("writes YAML when the file ends in .yml or .yaml", func() {
+		reportPath = filepath.Join(GinkgoT().TempDir(), "report.yml")
+
+		migrator.BeginReport()
+		Expect(migrator.WriteReport(reportPath, state, nil)).To(Succeed())
+
+		contents, err := os.ReadFile(reportPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		var report map[string]interface{}
+		Expect(yaml.Unmarshal(contents, &report)).To(Succeed())
+		Expect(report).To(HaveKeyWithValue("plan", "some-plan"))
+		Expect(report).To(HaveKeyWithValue("rename", "not-attempted"))
+	})
+
+	It("fails when the report can not be written", func() {
+		migrator.BeginReport()
+		err := migrator.WriteReport(filepath.Join(reportPath, "missing", "report.json"), state, nil)
+		Expect(err).To(MatchError(ContainSubstring("failed to write the migration report")))
+	})
+})
@@ -0,0 +1,130 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+type RollbackOptions struct {
+	// InstanceName is the name the recipient of the migration took over from the donor
+	InstanceName      string
+	SkipTLSValidation bool
+	Cleanup           bool
+	// Force rolls back even when the recipient may have been written to since the cutover, losing those writes
+	Force bool
+}
+
+// Rollback undoes the cutover of a migration, giving its name back to the donor. Unless forced, it refuses when
+// the fingerprint of the recipient's data changed since it was migrated, which a task of a migration app bound to
+// the recipient computes.
+func (m *Migrator) Rollback(opts RollbackOptions) error {
+	cutover, err := m.cutovers.Load(opts.InstanceName)
+	if errors.Is(err, ErrNoCutover) {
+		return fmt.Errorf("no migration to %s was recorded, so it can not be rolled back", opts.InstanceName)
+	}
+	if err != nil {
+		return err
+	}
+
+	if !m.client.ServiceExists(cutover.OldInstanceName) {
+		return fmt.Errorf("service instance %s not found, so %s can not be rolled back", cutover.OldInstanceName, opts.InstanceName)
+	}
+
+	if m.client.ServiceExists(cutover.RecipientInstanceName) {
+		return fmt.Errorf("a service instance named %s already exists, so %s can not be renamed back to it", cutover.RecipientInstanceName, opts.InstanceName)
+	}
+
+	if !opts.Force {
+		if err := m.checkNotWrittenTo(cutover, opts); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Renaming %s to %s", opts.InstanceName, cutover.RecipientInstanceName)
+	if err := m.client.RenameService(opts.InstanceName, cutover.RecipientInstanceName); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %w", opts.InstanceName, cutover.RecipientInstanceName, err)
+	}
+
+	log.Printf("Renaming %s to %s", cutover.OldInstanceName, opts.InstanceName)
+	if err := m.client.RenameService(cutover.OldInstanceName, opts.InstanceName); err != nil {
+		if undoErr := m.client.RenameService(cutover.RecipientInstanceName, opts.InstanceName); undoErr != nil {
+			return fmt.Errorf("failed to rename %s to %s: %w. "+
+				"Run 'cf rename-service %s %s' to complete the rollback",
+				cutover.OldInstanceName, opts.InstanceName, err, cutover.OldInstanceName, opts.InstanceName)
+		}
+
+		return fmt.Errorf("failed to rename %s to %s: %w. %s was renamed back to %s",
+			cutover.OldInstanceName, opts.InstanceName, err, cutover.RecipientInstanceName, opts.InstanceName)
+	}
+
+	if err := m.cutovers.Remove(opts.InstanceName); err != nil {
+		log.Printf("Warning: %s", err)
+	}
+
+	log.Printf("Rolled back the migration to %s. The migrated service instance is now named %s", opts.InstanceName, cutover.RecipientInstanceName)
+
+	return nil
+}
+
+// checkNotWrittenTo fails when the fingerprint of the recipient's data no longer matches the one recorded at
+// cutover. The fingerprint only covers the user schemas, so the accounts created and dropped by binding and unbinding
+// apps, including the migration app bound to run the check, do not change it.
+func (m *Migrator) checkNotWrittenTo(cutover Cutover, opts RollbackOptions) error {
+	if cutover.RecipientFingerprint == "" {
+		return fmt.Errorf("the data of %s was not fingerprinted at cutover, which migrating with --fingerprint does, so whether it was written to since can not be checked. "+
+			"Pass --force to roll back anyway", opts.InstanceName)
+	}
+
+	if err := m.pushHelperApp(); err != nil {
+		return err
+	}
+
+	if opts.Cleanup {
+		defer m.deleteHelperApp()
+	}
+
+	if err := m.startHelperApp(opts.InstanceName, nil); err != nil {
+		return err
+	}
+
+	log.Printf("Checking whether %s was written to since %s", opts.InstanceName, cutover.CutOverAt.Format("2006-01-02 15:04:05 MST"))
+	final, err := m.runTask(context.Background(), fingerprintTaskCommand(opts))
+	if err != nil {
+		return fmt.Errorf("failed to fingerprint %s: %w", opts.InstanceName, err)
+	}
+
+	if final.RecipientFingerprint != cutover.RecipientFingerprint {
+		return fmt.Errorf("%s was written to since the cutover, the fingerprint of its data changed from %s to %s. "+
+			"Rolling back loses those writes, pass --force to roll back anyway",
+			opts.InstanceName, cutover.RecipientFingerprint, final.RecipientFingerprint)
+	}
+
+	return nil
+}
+
+func fingerprintTaskCommand(opts RollbackOptions) string {
+	args := []string{"migrate", "fingerprint"}
+
+	if opts.SkipTLSValidation {
+		args = append(args, "-skip-tls-validation")
+	}
+
+	args = append(args, opts.InstanceName)
+
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,187 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate/migratefakes"
+)
+
+var _ = Describe("Rollback", func() {
+	var (
+		fakeClient       *migratefakes.FakeClient
+		fakeCutoverStore *migratefakes.FakeCutoverStore
+		migrator         *Migrator
+		opts             RollbackOptions
+	)
+
+	BeforeEach(func() {
+		opts = RollbackOptions{
+			InstanceName: "some-instance",
+			Cleanup:      true,
+		}
+		fakeClient = new(migratefakes.FakeClient)
+		fakeClient.ServiceExistsStub = func(instanceName string) bool {
+			return instanceName != "some-instance-new"
+		}
+		fakeClient.StartTaskReturns("some-task-guid", nil)
+		fakeClient.GetLogsReturns([]string{
+			`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"tables_copied":0,"tables_total":0,"done":true,"recipient_fingerprint":"some-fingerprint"}`,
+		}, nil)
+
+		fakeCutoverStore = new(migratefakes.FakeCutoverStore)
+		fakeCutoverStore.LoadReturns(Cutover{
+			InstanceName:          "some-instance",
+			OldInstanceName:       "some-instance-old",
+			RecipientInstanceName: "some-instance-new",
+			RecipientFingerprint:  "some-fingerprint",
+		}, nil)
+
+		migrator = NewMigrator(fakeClient, new(migratefakes.FakeUnpacker), nil, nil, nil, fakeCutoverStore)
+		migrator.Sleep = func(time.Duration) {}
+	})
+
+	It("swaps the names of the service instances back once it checked the recipient was not written to", func() {
+		opts.SkipTLSValidation = true
+		Expect(migrator.Rollback(opts)).To(Succeed())
+
+		Expect(fakeCutoverStore.LoadArgsForCall(0)).To(Equal("some-instance"))
+
+		By("fingerprinting the data of the recipient", func() {
+			Expect(fakeClient.BindServiceCallCount()).To(Equal(1))
+			_, instance := fakeClient.BindServiceArgsForCall(0)
+			Expect(instance).To(Equal("some-instance"))
+
+			_, command := fakeClient.StartTaskArgsForCall(0)
+			Expect(command).To(Equal("migrate fingerprint -skip-tls-validation some-instance"))
+			Expect(fakeClient.DeleteAppCallCount()).To(Equal(1))
+		})
+
+		By("renaming the service instances", func() {
+			Expect(fakeClient.RenameServiceCallCount()).To(Equal(2))
+
+			from, to := fakeClient.RenameServiceArgsForCall(0)
+			Expect(from).To(Equal("some-instance"))
+			Expect(to).To(Equal("some-instance-new"))
+
+			from, to = fakeClient.RenameServiceArgsForCall(1)
+			Expect(from).To(Equal("some-instance-old"))
+			Expect(to).To(Equal("some-instance"))
+		})
+
+		Expect(fakeCutoverStore.RemoveCallCount()).To(Equal(1))
+		Expect(fakeCutoverStore.RemoveArgsForCall(0)).To(Equal("some-instance"))
+	})
+
+	It("refuses when the recipient was written to since the cutover", func() {
+		fakeClient.GetLogsReturns([]string{
+			`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"done":true,"recipient_fingerprint":"other-fingerprint"}`,
+		}, nil)
+
+		err := migrator.Rollback(opts)
+		Expect(err).To(MatchError("some-instance was written to since the cutover, the fingerprint of its data changed from some-fingerprint to other-fingerprint. " +
+			"Rolling back loses those writes, pass --force to roll back anyway"))
+		Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+		Expect(fakeCutoverStore.RemoveCallCount()).To(BeZero())
+	})
+
+	It("refuses when the data of the recipient was not fingerprinted", func() {
+		fakeCutoverStore.LoadReturns(Cutover{
+			InstanceName:          "some-instance",
+			OldInstanceName:       "some-instance-old",
+			RecipientInstanceName: "some-instance-new",
+		}, nil)
+
+		err := migrator.Rollback(opts)
+		Expect(err).To(MatchError(ContainSubstring("the data of some-instance was not fingerprinted at cutover")))
+		Expect(fakeClient.PushAppCallCount()).To(BeZero())
+		Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+	})
+
+	It("fails when the recipient can not be fingerprinted", func() {
+		fakeClient.WaitForTaskReturns(errors.New("task failed"))
+
+		err := migrator.Rollback(opts)
+		Expect(err).To(MatchError("failed to fingerprint some-instance: task failed"))
+		Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+	})
+
+	Context("when forced", func() {
+		It("does not check whether the recipient was written to", func() {
+			opts.Force = true
+			fakeClient.GetLogsReturns([]string{
+				`2024-01-01T00:00:00.00+0000 [APP/TASK/migrate/0] OUT PROGRESS {"done":true,"recipient_fingerprint":"other-fingerprint"}`,
+			}, nil)
+
+			Expect(migrator.Rollback(opts)).To(Succeed())
+			Expect(fakeClient.PushAppCallCount()).To(BeZero())
+			Expect(fakeClient.RenameServiceCallCount()).To(Equal(2))
+		})
+	})
+
+	It("fails when no cutover was recorded", func() {
+		fakeCutoverStore.LoadReturns(Cutover{}, ErrNoCutover)
+
+		err := migrator.Rollback(opts)
+		Expect(err).To(MatchError("no migration to some-instance was recorded, so it can not be rolled back"))
+	})
+
+	It("fails before renaming anything when the old service instance no longer exists", func() {
+		fakeClient.ServiceExistsReturns(false)
+		fakeClient.ServiceExistsStub = nil
+
+		err := migrator.Rollback(opts)
+		Expect(err).To(MatchError("service instance some-instance-old not found, so some-instance can not be rolled back"))
+		Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+	})
+
+	It("fails before renaming anything when the name of the migrated service instance was taken", func() {
+		fakeClient.ServiceExistsReturns(true)
+		fakeClient.ServiceExistsStub = nil
+
+		err := migrator.Rollback(opts)
+		Expect(err).To(MatchError("a service instance named some-instance-new already exists, so some-instance can not be renamed back to it"))
+		Expect(fakeClient.RenameServiceCallCount()).To(BeZero())
+	})
+
+	Context("when the old service instance can not be renamed back", func() {
+		BeforeEach(func() {
+			fakeClient.RenameServiceReturnsOnCall(1, errors.New("some-error"))
+		})
+
+		It("undoes renaming the migrated service instance", func() {
+			err := migrator.Rollback(opts)
+			Expect(err).To(MatchError("failed to rename some-instance-old to some-instance: some-error. some-instance-new was renamed back to some-instance"))
+
+			Expect(fakeClient.RenameServiceCallCount()).To(Equal(3))
+			from, to := fakeClient.RenameServiceArgsForCall(2)
+			Expect(from).To(Equal("some-instance-new"))
+			Expect(to).To(Equal("some-instance"))
+			Expect(fakeCutoverStore.RemoveCallCount()).To(BeZero())
+		})
+
+		It("tells the operator how to complete the rollback when that fails too", func() {
+			fakeClient.RenameServiceReturnsOnCall(2, errors.New("some-other-error"))
+
+			err := migrator.Rollback(opts)
+			Expect(err).To(MatchError("failed to rename some-instance-old to some-instance: some-error. " +
+				"Run 'cf rename-service some-instance-old some-instance' to complete the rollback"))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ServiceInstanceConfig holds the arbitrary parameters and tags used to create the recipient service instance
+type ServiceInstanceConfig struct {
+	Parameters string
+	Tags       []string
+}
+
+// ParseServiceParameters accepts a JSON object, or the path to a file containing one, like `cf create-service -c` does.
+// The parameters are returned as compact JSON.
+func ParseServiceParameters(value string) (string, error) {
+	contents := []byte(value)
+	if fileContents, err := os.ReadFile(value); err == nil {
+		contents = fileContents
+	} else if !errors.Is(err, os.ErrNotExist) && !strings.HasPrefix(strings.TrimSpace(value), "{") {
+		return "", fmt.Errorf("failed to read service parameters from %s: %w", value, err)
+	}
+
+	var params map[string]interface{}
+	if err := json.Unmarshal(contents, &params); err != nil {
+		return "", fmt.Errorf("service parameters must be a JSON object or a file containing one: %w", err)
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, contents); err != nil {
+		return "", fmt.Errorf("service parameters must be a JSON object or a file containing one: %w", err)
+	}
+
+	return compacted.String(), nil
+}
+
+// ParseTags splits a comma-separated list of tags, like `cf create-service -t` does
+func ParseTags(value string) []string {
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
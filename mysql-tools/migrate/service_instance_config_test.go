@@ -0,0 +1,67 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+var _ = Describe("ServiceInstanceConfig", func() {
+	Context("ParseServiceParameters", func() {
+		It("accepts a JSON object", func() {
+			Expect(ParseServiceParameters(`{ "enable_lower_case_table_names": true }`)).
+				To(Equal(`{"enable_lower_case_table_names":true}`))
+		})
+
+		It("reads a JSON object from a file", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "params.json")
+			Expect(os.WriteFile(path, []byte("{\n  \"service-tier\": \"gold\"\n}\n"), 0600)).To(Succeed())
+
+			Expect(ParseServiceParameters(path)).To(Equal(`{"service-tier":"gold"}`))
+		})
+
+		It("rejects invalid JSON", func() {
+			_, err := ParseServiceParameters(`{"service-tier":`)
+			Expect(err).To(MatchError(ContainSubstring("service parameters must be a JSON object or a file containing one")))
+		})
+
+		It("rejects JSON that is not an object", func() {
+			_, err := ParseServiceParameters(`["service-tier"]`)
+			Expect(err).To(MatchError(ContainSubstring("service parameters must be a JSON object or a file containing one")))
+		})
+
+		It("rejects a file that does not contain a JSON object", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "params.json")
+			Expect(os.WriteFile(path, []byte("service-tier: gold"), 0600)).To(Succeed())
+
+			_, err := ParseServiceParameters(path)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ParseTags", func() {
+		It("splits a comma-separated list", func() {
+			Expect(ParseTags("mysql, some-tag,,other-tag ")).To(Equal([]string{"mysql", "some-tag", "other-tag"}))
+		})
+
+		It("returns no tags for an empty list", func() {
+			Expect(ParseTags("")).To(BeEmpty())
+		})
+	})
+})
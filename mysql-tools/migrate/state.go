@@ -0,0 +1,269 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cli/cf/configuration/confighelpers"
+)
+
+// Phase is the last step a migration completed successfully
+type Phase string
+
+const (
+	PhaseNotStarted       Phase = ""
+	PhaseRecipientCreated Phase = "recipient-created"
+	PhaseAppPushed        Phase = "app-pushed"
+	PhaseServicesBound    Phase = "services-bound"
+	PhaseAppStarted       Phase = "app-started"
+	PhaseTaskStarted      Phase = "task-started"
+	PhaseDataMigrated     Phase = "data-migrated"
+	PhaseCutOver          Phase = "cut-over"
+	PhaseBindingsRecorded Phase = "bindings-recorded"
+	PhaseRenamed          Phase = "renamed"
+)
+
+var phaseOrder = []Phase{
+	PhaseNotStarted,
+	PhaseRecipientCreated,
+	PhaseAppPushed,
+	PhaseServicesBound,
+	PhaseAppStarted,
+	PhaseTaskStarted,
+	PhaseDataMigrated,
+	PhaseCutOver,
+	PhaseBindingsRecorded,
+	PhaseRenamed,
+}
+
+var ErrNoMigrationState = errors.New("no migration in progress")
+
+// ErrTaskResultUnavailable is returned when the migration task completed, but its final progress line could not be
+// retrieved from its logs. The migration can be resumed to retrieve them again.
+var ErrTaskResultUnavailable = errors.New("the result of the migration task could not be retrieved from its logs")
+
+// Space identifies a space of a Cloud Foundry foundation, since service instance names are only unique within one
+type Space struct {
+	APIEndpoint string
+	GUID        string
+}
+
+type State struct {
+	DonorInstanceName     string
+	RecipientInstanceName string
+	PlanName              string
+	RecipientConfig       ServiceInstanceConfig
+	Phase                 Phase
+	AppName               string
+	TaskGUID              string
+	// BinlogPosition is the position of the donor binlog an online migration caught up to
+	BinlogPosition string
+	// RecipientFingerprint is the fingerprint of the recipient's data once it was migrated, recorded at cutover to
+	// tell whether the recipient was written to since
+	RecipientFingerprint string
+	Options              MigrateOptions
+	Bindings             []RecordedBinding
+	UpdatedAt            time.Time
+}
+
+// Reached reports whether the migration has completed the given phase
+func (s State) Reached(phase Phase) bool {
+	return phaseIndex(s.Phase) >= phaseIndex(phase)
+}
+
+func phaseIndex(phase Phase) int {
+	for i, p := range phaseOrder {
+		if p == phase {
+			return i
+		}
+	}
+
+	return -1
+}
+
+//counterfeiter:generate . StateStore
+type StateStore interface {
+	Load(donorInstanceName string) (State, error)
+	Save(state State) error
+	Remove(donorInstanceName string) error
+	List() ([]State, error)
+	// InSpace returns the store of the migrations of the given space
+	InSpace(space Space) StateStore
+	// RecordRecipient, ForgetRecipient and Recipients keep track of the service instances migrations created, so
+	// that only those are cleaned up
+	RecordRecipient(instanceName string) error
+	ForgetRecipient(instanceName string) error
+	Recipients() ([]string, error)
+}
+
+// FileStateStore persists one json document per donor service instance of a space
+type FileStateStore struct {
+	Dir   string
+	Space Space
+}
+
+func NewFileStateStore() FileStateStore {
+	return FileStateStore{
+		Dir: filepath.Join(confighelpers.PluginRepoDir(), ".cf", ".mysql-tools-migrations"),
+	}
+}
+
+func (s FileStateStore) InSpace(space Space) StateStore {
+	s.Space = space
+	return s
+}
+
+func (s FileStateStore) Load(donorInstanceName string) (State, error) {
+	contents, err := os.ReadFile(s.path(donorInstanceName))
+	if errors.Is(err, os.ErrNotExist) {
+		return State{}, ErrNoMigrationState
+	}
+	if err != nil {
+		return State{}, fmt.Errorf("failed to read migration state for %s: %w", donorInstanceName, err)
+	}
+
+	var state State
+	if err := json.Unmarshal(contents, &state); err != nil {
+		return State{}, fmt.Errorf("failed to parse migration state for %s: %w", donorInstanceName, err)
+	}
+
+	return state, nil
+}
+
+func (s FileStateStore) Save(state State) error {
+	if err := os.MkdirAll(s.spaceDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create migration state directory: %w", err)
+	}
+
+	contents, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode migration state: %w", err)
+	}
+
+	if err := writeFileAtomically(s.path(state.DonorInstanceName), contents); err != nil {
+		return fmt.Errorf("failed to write migration state: %w", err)
+	}
+
+	return nil
+}
+
+func (s FileStateStore) Remove(donorInstanceName string) error {
+	if err := os.Remove(s.path(donorInstanceName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove migration state for %s: %w", donorInstanceName, err)
+	}
+
+	return nil
+}
+
+// List returns the state of every migration that has not completed
+func (s FileStateStore) List() ([]State, error) {
+	entries, err := os.ReadDir(s.spaceDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration states: %w", err)
+	}
+
+	var states []State
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		donorInstanceName, err := url.PathUnescape(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+
+		state, err := s.Load(donorInstanceName)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// RecordRecipient records a service instance a migration is about to create in the space of the store. The record
+// outlives the migration state, so that a recipient left behind by a migration is still known once its state was
+// removed.
+func (s FileStateStore) RecordRecipient(instanceName string) error {
+	if err := os.MkdirAll(s.recipientsDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create recipient directory: %w", err)
+	}
+
+	if err := os.WriteFile(s.recipientPath(instanceName), nil, 0600); err != nil {
+		return fmt.Errorf("failed to record recipient %s: %w", instanceName, err)
+	}
+
+	return nil
+}
+
+// ForgetRecipient removes the record of a service instance that was deleted, or renamed after its donor
+func (s FileStateStore) ForgetRecipient(instanceName string) error {
+	if err := os.Remove(s.recipientPath(instanceName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to forget recipient %s: %w", instanceName, err)
+	}
+
+	return nil
+}
+
+func (s FileStateStore) Recipients() ([]string, error) {
+	entries, err := os.ReadDir(s.recipientsDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded recipients: %w", err)
+	}
+
+	var recipients []string
+	for _, entry := range entries {
+		instanceName, err := url.PathUnescape(entry.Name())
+		if entry.IsDir() || err != nil {
+			continue
+		}
+		recipients = append(recipients, instanceName)
+	}
+
+	return recipients, nil
+}
+
+func (s FileStateStore) recipientsDir() string {
+	return filepath.Join(s.spaceDir(), "recipients")
+}
+
+func (s FileStateStore) recipientPath(instanceName string) string {
+	return filepath.Join(s.recipientsDir(), url.PathEscape(instanceName))
+}
+
+func (s FileStateStore) path(donorInstanceName string) string {
+	return filepath.Join(s.spaceDir(), url.PathEscape(donorInstanceName)+".json")
+}
+
+// spaceDir keeps the migrations of every space apart, so that a donor is not confused with a service instance of the
+// same name in another space or foundation. The API endpoint is query escaped, since a colon is not valid in a
+// Windows file name.
+func (s FileStateStore) spaceDir() string {
+	return filepath.Join(s.Dir, url.QueryEscape(s.Space.APIEndpoint), url.QueryEscape(s.Space.GUID))
+}
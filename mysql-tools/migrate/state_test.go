@@ -0,0 +1,175 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package migrate_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+var _ = Describe("State", func() {
+	Describe("Reached", func() {
+		It("reports whether a phase has been completed", func() {
+			state := State{Phase: PhaseServicesBound}
+
+			Expect(state.Reached(PhaseNotStarted)).To(BeTrue())
+			Expect(state.Reached(PhaseAppPushed)).To(BeTrue())
+			Expect(state.Reached(PhaseServicesBound)).To(BeTrue())
+			Expect(state.Reached(PhaseAppStarted)).To(BeFalse())
+			Expect(state.Reached(PhaseDataMigrated)).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("FileStateStore", func() {
+	var store FileStateStore
+
+	BeforeEach(func() {
+		store = FileStateStore{Dir: filepath.Join(GinkgoT().TempDir(), "migrations")}
+	})
+
+	It("saves, loads and removes the state of a migration", func() {
+		state := State{
+			DonorInstanceName:     "some-donor",
+			RecipientInstanceName: "some-donor-new",
+			PlanName:              "some-plan",
+			Phase:                 PhaseTaskStarted,
+			AppName:               "migrate-app-some-guid",
+			TaskGUID:              "some-task-guid",
+			Options: MigrateOptions{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Cleanup:               true,
+			},
+		}
+
+		Expect(store.Save(state)).To(Succeed())
+		Expect(store.Load("some-donor")).To(Equal(state))
+
+		Expect(store.Remove("some-donor")).To(Succeed())
+		_, err := store.Load("some-donor")
+		Expect(err).To(MatchError(ErrNoMigrationState))
+	})
+
+	It("only allows the current user to read the state", func() {
+		Expect(store.Save(State{DonorInstanceName: "some-donor"})).To(Succeed())
+
+		info, err := os.Stat(filepath.Join(store.Dir, "some-donor.json"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("keeps instance names from escaping the state directory", func() {
+		Expect(store.Save(State{DonorInstanceName: "../some-donor"})).To(Succeed())
+
+		entries, err := os.ReadDir(store.Dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(entries).To(HaveLen(1))
+		Expect(store.Load("../some-donor")).To(HaveField("DonorInstanceName", "../some-donor"))
+	})
+
+	It("lists the state of every migration", func() {
+		Expect(store.Save(State{DonorInstanceName: "some-donor"})).To(Succeed())
+		Expect(store.Save(State{DonorInstanceName: "../other-donor"})).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(store.Dir, "some-donor.json.tmp"), []byte("{"), 0600)).To(Succeed())
+
+		states, err := store.List()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(ConsistOf(
+			HaveField("DonorInstanceName", "some-donor"),
+			HaveField("DonorInstanceName", "../other-donor"),
+		))
+	})
+
+	It("records the recipients migrations created, apart from their state", func() {
+		Expect(store.Save(State{DonorInstanceName: "some-donor"})).To(Succeed())
+		Expect(store.RecordRecipient("some-donor-new")).To(Succeed())
+		Expect(store.RecordRecipient("../other-donor-new")).To(Succeed())
+
+		Expect(store.Recipients()).To(ConsistOf("some-donor-new", "../other-donor-new"))
+		Expect(store.List()).To(HaveLen(1))
+
+		Expect(store.Remove("some-donor")).To(Succeed())
+		Expect(store.ForgetRecipient("../other-donor-new")).To(Succeed())
+		Expect(store.Recipients()).To(ConsistOf("some-donor-new"))
+	})
+
+	It("keeps the migrations of donors of the same name in other spaces apart", func() {
+		space := store.InSpace(Space{APIEndpoint: "https://api.example.com", GUID: "some-space-guid"})
+		otherSpace := store.InSpace(Space{APIEndpoint: "https://api.example.com", GUID: "other-space-guid"})
+		otherFoundation := store.InSpace(Space{APIEndpoint: "https://api.other.example.com", GUID: "some-space-guid"})
+
+		Expect(space.Save(State{DonorInstanceName: "some-donor", AppName: "some-app"})).To(Succeed())
+		Expect(otherSpace.Save(State{DonorInstanceName: "some-donor", AppName: "other-app"})).To(Succeed())
+
+		Expect(space.Load("some-donor")).To(HaveField("AppName", "some-app"))
+		Expect(otherSpace.Load("some-donor")).To(HaveField("AppName", "other-app"))
+		_, err := otherFoundation.Load("some-donor")
+		Expect(err).To(MatchError(ErrNoMigrationState))
+
+		Expect(otherSpace.Remove("some-donor")).To(Succeed())
+		Expect(space.List()).To(ConsistOf(HaveField("AppName", "some-app")))
+		Expect(otherSpace.List()).To(BeEmpty())
+	})
+
+	It("keeps the recipients created in other spaces apart", func() {
+		space := store.InSpace(Space{APIEndpoint: "https://api.example.com", GUID: "some-space-guid"})
+		otherSpace := store.InSpace(Space{APIEndpoint: "https://api.example.com", GUID: "other-space-guid"})
+
+		Expect(space.RecordRecipient("some-donor-new")).To(Succeed())
+		Expect(otherSpace.RecordRecipient("other-donor-new")).To(Succeed())
+
+		Expect(space.Recipients()).To(ConsistOf("some-donor-new"))
+		Expect(otherSpace.Recipients()).To(ConsistOf("other-donor-new"))
+	})
+
+	When("there is no migration state", func() {
+		It("lists no migrations", func() {
+			Expect(store.List()).To(BeEmpty())
+		})
+
+		It("lists no recipients", func() {
+			Expect(store.Recipients()).To(BeEmpty())
+		})
+
+		It("forgetting a recipient succeeds", func() {
+			Expect(store.ForgetRecipient("some-donor-new")).To(Succeed())
+		})
+
+		It("returns ErrNoMigrationState", func() {
+			_, err := store.Load("some-donor")
+			Expect(err).To(MatchError(ErrNoMigrationState))
+		})
+
+		It("removing the state succeeds", func() {
+			Expect(store.Remove("some-donor")).To(Succeed())
+		})
+	})
+
+	When("the state file is corrupt", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(store.Dir, 0700)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(store.Dir, "some-donor.json"), []byte("{"), 0600)).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			_, err := store.Load("some-donor")
+			Expect(err).To(MatchError(ContainSubstring("failed to parse migration state for some-donor")))
+		})
+	})
+})
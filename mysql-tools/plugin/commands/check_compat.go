@@ -0,0 +1,63 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+//counterfeiter:generate -o fakes/fake_compat_checker.go . CompatChecker
+type CompatChecker interface {
+	CheckServiceExists(instanceName string) error
+	CheckCompat(opts migrate.CheckCompatOptions) error
+}
+
+func CheckCompat(args []string, checker CompatChecker) error {
+	const (
+		checkCompatUsage = `cf mysql-tools check-compat [-h] [--no-cleanup] [--skip-tls-validation] <service-instance>`
+	)
+
+	var opts struct {
+		Args struct {
+			InstanceName string `positional-arg-name:"<service-instance>"`
+		} `positional-args:"yes" required:"yes"`
+		NoCleanup         bool `long:"no-cleanup" description:"don't clean up the migration app after checking"`
+		SkipTLSValidation bool `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server certificate. Not recommended!"`
+	}
+
+	parser := flags.NewParser(&opts, flags.None)
+	parser.Name = "cf mysql-tools check-compat"
+	args, err := parser.ParseArgs(args)
+	if err != nil || len(args) != 0 {
+		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
+		if err != nil {
+			msg = err.Error()
+		}
+		return fmt.Errorf("Usage: %s\n\n%s", checkCompatUsage, msg)
+	}
+
+	if err := checker.CheckServiceExists(opts.Args.InstanceName); err != nil {
+		return err
+	}
+
+	return checker.CheckCompat(migrate.CheckCompatOptions{
+		InstanceName:      opts.Args.InstanceName,
+		SkipTLSValidation: opts.SkipTLSValidation,
+		Cleanup:           !opts.NoCleanup,
+	})
+}
@@ -0,0 +1,72 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+)
+
+var _ = Describe("CheckCompat", func() {
+	var fakeChecker *fakes.FakeCompatChecker
+
+	const (
+		checkCompatUsage = `cf mysql-tools check-compat [-h] [--no-cleanup] [--skip-tls-validation] <service-instance>`
+	)
+
+	BeforeEach(func() {
+		fakeChecker = new(fakes.FakeCompatChecker)
+	})
+
+	It("checks the compatibility of a service instance", func() {
+		Expect(commands.CheckCompat([]string{"some-instance"}, fakeChecker)).To(Succeed())
+
+		Expect(fakeChecker.CheckServiceExistsCallCount()).To(Equal(1))
+		Expect(fakeChecker.CheckServiceExistsArgsForCall(0)).To(Equal("some-instance"))
+
+		Expect(fakeChecker.CheckCompatCallCount()).To(Equal(1))
+		Expect(fakeChecker.CheckCompatArgsForCall(0)).To(Equal(migrate.CheckCompatOptions{
+			InstanceName: "some-instance",
+			Cleanup:      true,
+		}))
+	})
+
+	It("passes the --skip-tls-validation and --no-cleanup options", func() {
+		Expect(commands.CheckCompat([]string{"-k", "--no-cleanup", "some-instance"}, fakeChecker)).To(Succeed())
+
+		Expect(fakeChecker.CheckCompatArgsForCall(0)).To(Equal(migrate.CheckCompatOptions{
+			InstanceName:      "some-instance",
+			SkipTLSValidation: true,
+		}))
+	})
+
+	It("requires a service instance", func() {
+		err := commands.CheckCompat(nil, fakeChecker)
+		Expect(err).To(MatchError("Usage: " + checkCompatUsage + "\n\nthe required argument `<service-instance>` was not provided"))
+		Expect(fakeChecker.CheckCompatCallCount()).To(BeZero())
+	})
+
+	It("rejects extra arguments", func() {
+		err := commands.CheckCompat([]string{"some-instance", "extra"}, fakeChecker)
+		Expect(err).To(MatchError("Usage: " + checkCompatUsage + "\n\nunexpected arguments: extra"))
+	})
+
+	It("does not check a service instance that does not exist", func() {
+		fakeChecker.CheckServiceExistsReturns(errors.New("Service instance some-instance not found"))
+
+		err := commands.CheckCompat([]string{"some-instance"}, fakeChecker)
+		Expect(err).To(MatchError("Service instance some-instance not found"))
+		Expect(fakeChecker.CheckCompatCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the check fails", func() {
+		fakeChecker.CheckCompatReturns(errors.New("compatibility check of some-instance failed"))
+
+		err := commands.CheckCompat([]string{"some-instance"}, fakeChecker)
+		Expect(err).To(MatchError("compatibility check of some-instance failed"))
+	})
+})
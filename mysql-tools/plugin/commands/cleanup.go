@@ -0,0 +1,82 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/presentation"
+)
+
+//counterfeiter:generate -o fakes/fake_cleaner.go . Cleaner
+type Cleaner interface {
+	FindLeftovers(olderThan time.Duration) ([]migrate.Leftover, error)
+	ConfirmCleanup(count int) bool
+	CleanUp(leftovers []migrate.Leftover) error
+}
+
+func Cleanup(args []string, cleaner Cleaner) error {
+	const (
+		cleanupUsage = `cf mysql-tools cleanup [-h] [--older-than <duration>] [--dry-run]`
+	)
+
+	var opts struct {
+		OlderThan time.Duration `long:"older-than" default:"24h" description:"only clean up what was created longer ago than this, e.g. 72h"`
+		DryRun    bool          `long:"dry-run" description:"list what would be cleaned up without deleting anything"`
+	}
+
+	parser := flags.NewParser(&opts, flags.None)
+	parser.Name = "cf mysql-tools cleanup"
+	args, err := parser.ParseArgs(args)
+	if err != nil || len(args) != 0 {
+		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
+		if err != nil {
+			msg = err.Error()
+		}
+		return fmt.Errorf("Usage: %s\n\n%s", cleanupUsage, msg)
+	}
+
+	if opts.OlderThan < 0 {
+		return fmt.Errorf("Usage: %s\n\n--older-than must not be negative", cleanupUsage)
+	}
+
+	leftovers, err := cleaner.FindLeftovers(opts.OlderThan)
+	if err != nil {
+		return fmt.Errorf("failed to find leftover migration apps and service instances: %w", err)
+	}
+
+	if len(leftovers) == 0 {
+		log.Printf("No migration apps or service instances older than %s left behind in the current space", opts.OlderThan)
+		return nil
+	}
+
+	presentation.Leftovers(os.Stdout, leftovers, time.Now())
+
+	if opts.DryRun {
+		log.Printf("Dry run: %d apps and service instances would be deleted", len(leftovers))
+		return nil
+	}
+
+	if !cleaner.ConfirmCleanup(len(leftovers)) {
+		return fmt.Errorf("cleanup was not confirmed. Nothing was deleted")
+	}
+
+	return cleaner.CleanUp(leftovers)
+}
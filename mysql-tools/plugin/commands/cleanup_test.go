@@ -0,0 +1,101 @@
+package commands_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+)
+
+var _ = Describe("Cleanup", func() {
+	var (
+		fakeCleaner *fakes.FakeCleaner
+		leftovers   []migrate.Leftover
+	)
+
+	const (
+		cleanupUsage = `cf mysql-tools cleanup [-h] [--older-than <duration>] [--dry-run]`
+	)
+
+	BeforeEach(func() {
+		leftovers = []migrate.Leftover{
+			{Type: migrate.LeftoverApp, Name: "migrate-app-some-guid", CreatedAt: time.Now().Add(-48 * time.Hour), Size: "1024M x 1"},
+			{Type: migrate.LeftoverServiceInstance, Name: "some-donor-new", CreatedAt: time.Now().Add(-48 * time.Hour), Size: "db-small"},
+		}
+
+		fakeCleaner = new(fakes.FakeCleaner)
+		fakeCleaner.FindLeftoversReturns(leftovers, nil)
+		fakeCleaner.ConfirmCleanupReturns(true)
+	})
+
+	It("deletes what migrations left behind over a day ago once confirmed", func() {
+		Expect(commands.Cleanup(nil, fakeCleaner)).To(Succeed())
+
+		Expect(fakeCleaner.FindLeftoversArgsForCall(0)).To(Equal(24 * time.Hour))
+		Expect(fakeCleaner.ConfirmCleanupArgsForCall(0)).To(Equal(2))
+		Expect(fakeCleaner.CleanUpCallCount()).To(Equal(1))
+		Expect(fakeCleaner.CleanUpArgsForCall(0)).To(Equal(leftovers))
+	})
+
+	It("passes --older-than", func() {
+		Expect(commands.Cleanup([]string{"--older-than", "90m"}, fakeCleaner)).To(Succeed())
+		Expect(fakeCleaner.FindLeftoversArgsForCall(0)).To(Equal(90 * time.Minute))
+	})
+
+	It("does not delete anything in a dry run", func() {
+		Expect(commands.Cleanup([]string{"--dry-run"}, fakeCleaner)).To(Succeed())
+
+		Expect(fakeCleaner.ConfirmCleanupCallCount()).To(BeZero())
+		Expect(fakeCleaner.CleanUpCallCount()).To(BeZero())
+	})
+
+	It("does not ask for confirmation when nothing was left behind", func() {
+		fakeCleaner.FindLeftoversReturns(nil, nil)
+
+		Expect(commands.Cleanup(nil, fakeCleaner)).To(Succeed())
+		Expect(fakeCleaner.ConfirmCleanupCallCount()).To(BeZero())
+	})
+
+	It("does not delete anything unless confirmed", func() {
+		fakeCleaner.ConfirmCleanupReturns(false)
+
+		err := commands.Cleanup(nil, fakeCleaner)
+		Expect(err).To(MatchError("cleanup was not confirmed. Nothing was deleted"))
+		Expect(fakeCleaner.CleanUpCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the leftovers can not be found", func() {
+		fakeCleaner.FindLeftoversReturns(nil, errors.New("some-error"))
+
+		err := commands.Cleanup(nil, fakeCleaner)
+		Expect(err).To(MatchError("failed to find leftover migration apps and service instances: some-error"))
+	})
+
+	It("returns the error of a failed cleanup", func() {
+		fakeCleaner.CleanUpReturns(errors.New("failed to delete 1 of 2 apps and service instances"))
+
+		err := commands.Cleanup(nil, fakeCleaner)
+		Expect(err).To(MatchError("failed to delete 1 of 2 apps and service instances"))
+	})
+
+	It("rejects an invalid duration", func() {
+		err := commands.Cleanup([]string{"--older-than", "1 day"}, fakeCleaner)
+		Expect(err).To(MatchError(HavePrefix("Usage: " + cleanupUsage + "\n\n")))
+		Expect(fakeCleaner.FindLeftoversCallCount()).To(BeZero())
+	})
+
+	It("rejects a negative duration", func() {
+		err := commands.Cleanup([]string{"--older-than=-1h"}, fakeCleaner)
+		Expect(err).To(MatchError("Usage: " + cleanupUsage + "\n\n--older-than must not be negative"))
+	})
+
+	It("rejects arguments", func() {
+		err := commands.Cleanup([]string{"some-instance"}, fakeCleaner)
+		Expect(err).To(MatchError("Usage: " + cleanupUsage + "\n\nunexpected arguments: some-instance"))
+	})
+})
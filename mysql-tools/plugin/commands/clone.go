@@ -0,0 +1,84 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+)
+
+//counterfeiter:generate -o fakes/fake_cloner.go . Cloner
+type Cloner interface {
+	Clone(opts migrate.CloneOptions) error
+}
+
+func Clone(args []string, cloner Cloner) error {
+	const (
+		cloneUsage = `cf mysql-tools clone [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--definer <invoker|user@host>] [--masking-rules <file>] [--force] --from [<target>/]<space>/<instance> --to [<target>/]<space>/<instance>`
+	)
+
+	var opts struct {
+		From                  string `long:"from" value-name:"[<target>/]<space>/<instance>" required:"yes" description:"Service instance to copy, in a space of the current target or of a saved target"`
+		To                    string `long:"to" value-name:"[<target>/]<space>/<instance>" required:"yes" description:"Service instance whose data is replaced, in a space of the current target or of a saved target"`
+		IncludeStoredPrograms bool   `long:"include-stored-programs" description:"Copy stored routines, triggers and events"`
+		Definer               string `long:"definer" value-name:"<invoker|user@host>" description:"Convert views to SQL SECURITY INVOKER and make stored programs owned by the destination's binding user (invoker, the default), or map every DEFINER to this account"`
+		MaskingRules          string `long:"masking-rules" value-name:"<file>" description:"Mask the values of columns while copying them, according to the rules in this YAML file"`
+		Force                 bool   `long:"force" short:"f" description:"Replace the data of the destination without asking for confirmation. Its data is dropped before copying, and lost if the clone fails"`
+		NoCleanup             bool   `long:"no-cleanup" description:"don't clean up the migration app, user provided service and service key after cloning"`
+		SkipTLSValidation     bool   `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server certificates. Not recommended!"`
+	}
+
+	parser := flags.NewParser(&opts, flags.None)
+	parser.Name = "cf mysql-tools clone"
+	args, err := parser.ParseArgs(args)
+	if err != nil || len(args) != 0 {
+		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
+		if err != nil {
+			msg = err.Error()
+		}
+		return fmt.Errorf("Usage: %s\n\n%s", cloneUsage, msg)
+	}
+
+	cloneOptions := migrate.CloneOptions{
+		Force:                 opts.Force,
+		Cleanup:               !opts.NoCleanup,
+		SkipTLSValidation:     opts.SkipTLSValidation,
+		IncludeStoredPrograms: opts.IncludeStoredPrograms,
+		Definer:               opts.Definer,
+	}
+
+	if cloneOptions.Source, err = migrate.ParseLocation(opts.From); err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+
+	if cloneOptions.Destination, err = migrate.ParseLocation(opts.To); err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	if opts.Definer != "" {
+		if _, err := definer.ParsePolicy(opts.Definer); err != nil {
+			return fmt.Errorf("invalid --definer: %w", err)
+		}
+	}
+
+	if cloneOptions.MaskingRules, err = readMaskingRules(opts.MaskingRules); err != nil {
+		return err
+	}
+
+	return cloner.Clone(cloneOptions)
+}
@@ -0,0 +1,93 @@
+package commands_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+)
+
+var _ = Describe("Clone", func() {
+	var fakeCloner *fakes.FakeCloner
+
+	const (
+		cloneUsage = `cf mysql-tools clone [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--definer <invoker|user@host>] [--masking-rules <file>] [--force] --from [<target>/]<space>/<instance> --to [<target>/]<space>/<instance>`
+	)
+
+	BeforeEach(func() {
+		fakeCloner = new(fakes.FakeCloner)
+	})
+
+	It("clones a service instance into another space", func() {
+		args := []string{"--from", "prod-foundation/production/prod-db", "--to", "staging/staging-db"}
+		Expect(commands.Clone(args, fakeCloner)).To(Succeed())
+
+		Expect(fakeCloner.CloneCallCount()).To(Equal(1))
+		Expect(fakeCloner.CloneArgsForCall(0)).To(Equal(migrate.CloneOptions{
+			Source:      migrate.Location{Target: "prod-foundation", Space: "production", InstanceName: "prod-db"},
+			Destination: migrate.Location{Space: "staging", InstanceName: "staging-db"},
+			Cleanup:     true,
+		}))
+	})
+
+	It("passes the clone options", func() {
+		args := []string{
+			"--from", "production/prod-db",
+			"--to", "staging/staging-db",
+			"--include-stored-programs",
+			"--definer", "invoker",
+			"--force",
+			"--no-cleanup",
+			"-k",
+		}
+		Expect(commands.Clone(args, fakeCloner)).To(Succeed())
+
+		Expect(fakeCloner.CloneArgsForCall(0)).To(Equal(migrate.CloneOptions{
+			Source:                migrate.Location{Space: "production", InstanceName: "prod-db"},
+			Destination:           migrate.Location{Space: "staging", InstanceName: "staging-db"},
+			Force:                 true,
+			SkipTLSValidation:     true,
+			IncludeStoredPrograms: true,
+			Definer:               "invoker",
+		}))
+	})
+
+	It("returns the usage when the destination is missing", func() {
+		err := commands.Clone([]string{"--from", "production/prod-db"}, fakeCloner)
+		Expect(err).To(MatchError("Usage: " + cloneUsage + "\n\nthe required flag `--to' was not specified"))
+		Expect(fakeCloner.CloneCallCount()).To(BeZero())
+	})
+
+	It("returns an error when a location is invalid", func() {
+		err := commands.Clone([]string{"--from", "prod-db", "--to", "staging/staging-db"}, fakeCloner)
+		Expect(err).To(MatchError(`invalid --from: invalid location "prod-db", expected [<target>/]<space>/<instance>`))
+		Expect(fakeCloner.CloneCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the definer is invalid", func() {
+		err := commands.Clone([]string{"--from", "production/prod-db", "--to", "staging/staging-db", "--definer", "nobody"}, fakeCloner)
+		Expect(err).To(MatchError(ContainSubstring("invalid --definer: ")))
+	})
+
+	It("passes masking rules on to the clone", func() {
+		rulesFile := filepath.Join(GinkgoT().TempDir(), "masking.yml")
+		Expect(os.WriteFile(rulesFile, []byte("tables: {sakila.customer: {email: hash}}"), 0600)).To(Succeed())
+
+		err := commands.Clone([]string{"--from", "production/prod-db", "--to", "staging/staging-db", "--masking-rules", rulesFile}, fakeCloner)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeCloner.CloneArgsForCall(0).MaskingRules).To(Equal("tables: {sakila.customer: {email: hash}}"))
+	})
+
+	It("returns clone errors", func() {
+		fakeCloner.CloneReturns(errors.New("some-error"))
+
+		err := commands.Clone([]string{"--from", "production/prod-db", "--to", "staging/staging-db"}, fakeCloner)
+		Expect(err).To(MatchError("some-error"))
+	})
+})
@@ -0,0 +1,100 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+//counterfeiter:generate -o fakes/fake_exporter.go . Exporter
+type Exporter interface {
+	CheckServiceExists(instanceName string) error
+	Export(opts migrate.ExportOptions) error
+}
+
+func Export(args []string, exporter Exporter) error {
+	const (
+		exportUsage = `cf mysql-tools export [-h] [--no-cleanup] [--skip-tls-validation] [--encrypt] [--s3-endpoint <url>] [--s3-region <region>] --to <file|s3://bucket/key> <service-instance>`
+	)
+
+	var opts struct {
+		Args struct {
+			InstanceName string `positional-arg-name:"<service-instance>"`
+		} `positional-args:"yes" required:"yes"`
+		To                string `long:"to" value-name:"<file|s3://bucket/key>" required:"yes" description:"Write the dump to this local file, or upload it to this object of an S3-compatible object store using the credentials in AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY"`
+		S3Endpoint        string `long:"s3-endpoint" value-name:"<url>" description:"URL of the S3-compatible object store. Defaults to AWS S3"`
+		S3Region          string `long:"s3-region" value-name:"<region>" description:"Region of the S3 bucket. Defaults to us-east-1"`
+		Encrypt           bool   `long:"encrypt" description:"Encrypt the dump with the passphrase in MYSQL_TOOLS_PASSPHRASE"`
+		NoCleanup         bool   `long:"no-cleanup" description:"don't clean up the migration app after exporting"`
+		SkipTLSValidation bool   `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server and object store certificates. Not recommended!"`
+	}
+
+	parser := flags.NewParser(&opts, flags.None)
+	parser.Name = "cf mysql-tools export"
+	args, err := parser.ParseArgs(args)
+	if err == nil && len(args) == 0 {
+		switch {
+		case opts.To == "-":
+			err = errors.New("--to must be a file or an s3://<bucket>/<key> URL")
+		case (opts.S3Endpoint != "" || opts.S3Region != "") && !s3.IsURL(opts.To):
+			err = errors.New("--s3-endpoint and --s3-region can only be used when exporting to S3")
+		}
+	}
+	if err != nil || len(args) != 0 {
+		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
+		if err != nil {
+			msg = err.Error()
+		}
+		return fmt.Errorf("Usage: %s\n\n%s", exportUsage, msg)
+	}
+
+	exportOptions := migrate.ExportOptions{
+		InstanceName:      opts.Args.InstanceName,
+		Destination:       opts.To,
+		S3Endpoint:        opts.S3Endpoint,
+		S3Region:          opts.S3Region,
+		SkipTLSValidation: opts.SkipTLSValidation,
+		Cleanup:           !opts.NoCleanup,
+	}
+
+	if opts.Encrypt {
+		if exportOptions.Passphrase = os.Getenv(archive.PassphraseEnv); exportOptions.Passphrase == "" {
+			return fmt.Errorf("%s must be set to encrypt the dump", archive.PassphraseEnv)
+		}
+	}
+
+	if s3.IsURL(opts.To) {
+		if _, err := s3.ParseURL(opts.To); err != nil {
+			return fmt.Errorf("invalid --to: %w", err)
+		}
+
+		if exportOptions.S3Credentials, err = s3.CredentialsFromEnv(); err != nil {
+			return err
+		}
+	}
+
+	if err := exporter.CheckServiceExists(exportOptions.InstanceName); err != nil {
+		return err
+	}
+
+	return exporter.Export(exportOptions)
+}
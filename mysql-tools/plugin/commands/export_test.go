@@ -0,0 +1,123 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+var _ = Describe("Export", func() {
+	var fakeExporter *fakes.FakeExporter
+
+	const (
+		exportUsage = `cf mysql-tools export [-h] [--no-cleanup] [--skip-tls-validation] [--encrypt] [--s3-endpoint <url>] [--s3-region <region>] --to <file|s3://bucket/key> <service-instance>`
+	)
+
+	BeforeEach(func() {
+		fakeExporter = new(fakes.FakeExporter)
+		GinkgoT().Setenv("MYSQL_TOOLS_PASSPHRASE", "")
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "")
+	})
+
+	It("exports a service instance to a local file", func() {
+		Expect(commands.Export([]string{"--to", "backup.sql.gz", "some-instance"}, fakeExporter)).To(Succeed())
+
+		Expect(fakeExporter.CheckServiceExistsCallCount()).To(Equal(1))
+		Expect(fakeExporter.CheckServiceExistsArgsForCall(0)).To(Equal("some-instance"))
+
+		Expect(fakeExporter.ExportCallCount()).To(Equal(1))
+		Expect(fakeExporter.ExportArgsForCall(0)).To(Equal(migrate.ExportOptions{
+			InstanceName: "some-instance",
+			Destination:  "backup.sql.gz",
+			Cleanup:      true,
+		}))
+	})
+
+	It("exports a service instance to S3 using the credentials in the environment", func() {
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "some-key-id")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "some-secret")
+
+		args := []string{
+			"--to", "s3://some-bucket/some-key",
+			"--s3-endpoint", "https://minio.example.com",
+			"--s3-region", "some-region",
+			"--skip-tls-validation",
+			"--no-cleanup",
+			"some-instance",
+		}
+		Expect(commands.Export(args, fakeExporter)).To(Succeed())
+
+		Expect(fakeExporter.ExportArgsForCall(0)).To(Equal(migrate.ExportOptions{
+			InstanceName:      "some-instance",
+			Destination:       "s3://some-bucket/some-key",
+			S3Endpoint:        "https://minio.example.com",
+			S3Region:          "some-region",
+			S3Credentials:     s3.Credentials{AccessKeyID: "some-key-id", SecretAccessKey: "some-secret"},
+			SkipTLSValidation: true,
+		}))
+	})
+
+	It("encrypts the dump with the passphrase in the environment", func() {
+		GinkgoT().Setenv("MYSQL_TOOLS_PASSPHRASE", "some-passphrase")
+
+		Expect(commands.Export([]string{"--encrypt", "--to", "backup.sql.gz.enc", "some-instance"}, fakeExporter)).To(Succeed())
+
+		Expect(fakeExporter.ExportArgsForCall(0).Passphrase).To(Equal("some-passphrase"))
+	})
+
+	It("returns an error when asked to encrypt without a passphrase", func() {
+		err := commands.Export([]string{"--encrypt", "--to", "backup.sql.gz.enc", "some-instance"}, fakeExporter)
+		Expect(err).To(MatchError("MYSQL_TOOLS_PASSPHRASE must be set to encrypt the dump"))
+		Expect(fakeExporter.ExportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when exporting to S3 without credentials", func() {
+		err := commands.Export([]string{"--to", "s3://some-bucket/some-key", "some-instance"}, fakeExporter)
+		Expect(err).To(MatchError("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to access S3"))
+		Expect(fakeExporter.ExportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the S3 URL is invalid", func() {
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "some-key-id")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "some-secret")
+
+		err := commands.Export([]string{"--to", "s3://some-bucket", "some-instance"}, fakeExporter)
+		Expect(err).To(MatchError(HavePrefix("invalid --to: ")))
+		Expect(fakeExporter.ExportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the service instance does not exist", func() {
+		fakeExporter.CheckServiceExistsReturns(errors.New("Service instance some-instance not found"))
+
+		err := commands.Export([]string{"--to", "backup.sql.gz", "some-instance"}, fakeExporter)
+		Expect(err).To(MatchError("Service instance some-instance not found"))
+		Expect(fakeExporter.ExportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the export fails", func() {
+		fakeExporter.ExportReturns(errors.New("some-export-error"))
+
+		err := commands.Export([]string{"--to", "backup.sql.gz", "some-instance"}, fakeExporter)
+		Expect(err).To(MatchError("some-export-error"))
+	})
+
+	DescribeTable("invalid arguments",
+		func(args []string, message string) {
+			err := commands.Export(args, fakeExporter)
+			Expect(err).To(MatchError("Usage: " + exportUsage + "\n\n" + message))
+			Expect(fakeExporter.ExportCallCount()).To(BeZero())
+		},
+		Entry("no destination", []string{"some-instance"}, "the required flag `--to' was not specified"),
+		Entry("no service instance", []string{"--to", "backup.sql.gz"}, "the required argument `<service-instance>` was not provided"),
+		Entry("stdout", []string{"--to", "-", "some-instance"}, "--to must be a file or an s3://<bucket>/<key> URL"),
+		Entry("an S3 endpoint for a local file", []string{"--to", "backup.sql.gz", "--s3-endpoint", "https://minio.example.com", "some-instance"}, "--s3-endpoint and --s3-region can only be used when exporting to S3"),
+		Entry("extra arguments", []string{"--to", "backup.sql.gz", "some-instance", "extra"}, "unexpected arguments: extra"),
+	)
+})
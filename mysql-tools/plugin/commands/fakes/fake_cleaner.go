@@ -0,0 +1,271 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+)
+
+type FakeCleaner struct {
+	CleanUpStub        func([]migrate.Leftover) error
+	cleanUpMutex       sync.RWMutex
+	cleanUpArgsForCall []struct {
+		arg1 []migrate.Leftover
+	}
+	cleanUpReturns struct {
+		result1 error
+	}
+	cleanUpReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ConfirmCleanupStub        func(int) bool
+	confirmCleanupMutex       sync.RWMutex
+	confirmCleanupArgsForCall []struct {
+		arg1 int
+	}
+	confirmCleanupReturns struct {
+		result1 bool
+	}
+	confirmCleanupReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	FindLeftoversStub        func(time.Duration) ([]migrate.Leftover, error)
+	findLeftoversMutex       sync.RWMutex
+	findLeftoversArgsForCall []struct {
+		arg1 time.Duration
+	}
+	findLeftoversReturns struct {
+		result1 []migrate.Leftover
+		result2 error
+	}
+	findLeftoversReturnsOnCall map[int]struct {
+		result1 []migrate.Leftover
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCleaner) CleanUp(arg1 []migrate.Leftover) error {
+	var arg1Copy []migrate.Leftover
+	if arg1 != nil {
+		arg1Copy = make([]migrate.Leftover, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.cleanUpMutex.Lock()
+	ret, specificReturn := fake.cleanUpReturnsOnCall[len(fake.cleanUpArgsForCall)]
+	fake.cleanUpArgsForCall = append(fake.cleanUpArgsForCall, struct {
+		arg1 []migrate.Leftover
+	}{arg1Copy})
+	stub := fake.CleanUpStub
+	fakeReturns := fake.cleanUpReturns
+	fake.recordInvocation("CleanUp", []interface{}{arg1Copy})
+	fake.cleanUpMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCleaner) CleanUpCallCount() int {
+	fake.cleanUpMutex.RLock()
+	defer fake.cleanUpMutex.RUnlock()
+	return len(fake.cleanUpArgsForCall)
+}
+
+func (fake *FakeCleaner) CleanUpCalls(stub func([]migrate.Leftover) error) {
+	fake.cleanUpMutex.Lock()
+	defer fake.cleanUpMutex.Unlock()
+	fake.CleanUpStub = stub
+}
+
+func (fake *FakeCleaner) CleanUpArgsForCall(i int) []migrate.Leftover {
+	fake.cleanUpMutex.RLock()
+	defer fake.cleanUpMutex.RUnlock()
+	argsForCall := fake.cleanUpArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCleaner) CleanUpReturns(result1 error) {
+	fake.cleanUpMutex.Lock()
+	defer fake.cleanUpMutex.Unlock()
+	fake.CleanUpStub = nil
+	fake.cleanUpReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCleaner) CleanUpReturnsOnCall(i int, result1 error) {
+	fake.cleanUpMutex.Lock()
+	defer fake.cleanUpMutex.Unlock()
+	fake.CleanUpStub = nil
+	if fake.cleanUpReturnsOnCall == nil {
+		fake.cleanUpReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cleanUpReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCleaner) ConfirmCleanup(arg1 int) bool {
+	fake.confirmCleanupMutex.Lock()
+	ret, specificReturn := fake.confirmCleanupReturnsOnCall[len(fake.confirmCleanupArgsForCall)]
+	fake.confirmCleanupArgsForCall = append(fake.confirmCleanupArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.ConfirmCleanupStub
+	fakeReturns := fake.confirmCleanupReturns
+	fake.recordInvocation("ConfirmCleanup", []interface{}{arg1})
+	fake.confirmCleanupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCleaner) ConfirmCleanupCallCount() int {
+	fake.confirmCleanupMutex.RLock()
+	defer fake.confirmCleanupMutex.RUnlock()
+	return len(fake.confirmCleanupArgsForCall)
+}
+
+func (fake *FakeCleaner) ConfirmCleanupCalls(stub func(int) bool) {
+	fake.confirmCleanupMutex.Lock()
+	defer fake.confirmCleanupMutex.Unlock()
+	fake.ConfirmCleanupStub = stub
+}
+
+func (fake *FakeCleaner) ConfirmCleanupArgsForCall(i int) int {
+	fake.confirmCleanupMutex.RLock()
+	defer fake.confirmCleanupMutex.RUnlock()
+	argsForCall := fake.confirmCleanupArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCleaner) ConfirmCleanupReturns(result1 bool) {
+	fake.confirmCleanupMutex.Lock()
+	defer fake.confirmCleanupMutex.Unlock()
+	fake.ConfirmCleanupStub = nil
+	fake.confirmCleanupReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeCleaner) ConfirmCleanupReturnsOnCall(i int, result1 bool) {
+	fake.confirmCleanupMutex.Lock()
+	defer fake.confirmCleanupMutex.Unlock()
+	fake.ConfirmCleanupStub = nil
+	if fake.confirmCleanupReturnsOnCall == nil {
+		fake.confirmCleanupReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.confirmCleanupReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeCleaner) FindLeftovers(arg1 time.Duration) ([]migrate.Leftover, error) {
+	fake.findLeftoversMutex.Lock()
+	ret, specificReturn := fake.findLeftoversReturnsOnCall[len(fake.findLeftoversArgsForCall)]
+	fake.findLeftoversArgsForCall = append(fake.findLeftoversArgsForCall, struct {
+		arg1 time.Duration
+	}{arg1})
+	stub := fake.FindLeftoversStub
+	fakeReturns := fake.findLeftoversReturns
+	fake.recordInvocation("FindLeftovers", []interface{}{arg1})
+	fake.findLeftoversMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCleaner) FindLeftoversCallCount() int {
+	fake.findLeftoversMutex.RLock()
+	defer fake.findLeftoversMutex.RUnlock()
+	return len(fake.findLeftoversArgsForCall)
+}
+
+func (fake *FakeCleaner) FindLeftoversCalls(stub func(time.Duration) ([]migrate.Leftover, error)) {
+	fake.findLeftoversMutex.Lock()
+	defer fake.findLeftoversMutex.Unlock()
+	fake.FindLeftoversStub = stub
+}
+
+func (fake *FakeCleaner) FindLeftoversArgsForCall(i int) time.Duration {
+	fake.findLeftoversMutex.RLock()
+	defer fake.findLeftoversMutex.RUnlock()
+	argsForCall := fake.findLeftoversArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCleaner) FindLeftoversReturns(result1 []migrate.Leftover, result2 error) {
+	fake.findLeftoversMutex.Lock()
+	defer fake.findLeftoversMutex.Unlock()
+	fake.FindLeftoversStub = nil
+	fake.findLeftoversReturns = struct {
+		result1 []migrate.Leftover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCleaner) FindLeftoversReturnsOnCall(i int, result1 []migrate.Leftover, result2 error) {
+	fake.findLeftoversMutex.Lock()
+	defer fake.findLeftoversMutex.Unlock()
+	fake.FindLeftoversStub = nil
+	if fake.findLeftoversReturnsOnCall == nil {
+		fake.findLeftoversReturnsOnCall = make(map[int]struct {
+			result1 []migrate.Leftover
+			result2 error
+		})
+	}
+	fake.findLeftoversReturnsOnCall[i] = struct {
+		result1 []migrate.Leftover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCleaner) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cleanUpMutex.RLock()
+	defer fake.cleanUpMutex.RUnlock()
+	fake.confirmCleanupMutex.RLock()
+	defer fake.confirmCleanupMutex.RUnlock()
+	fake.findLeftoversMutex.RLock()
+	defer fake.findLeftoversMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCleaner) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commands.Cleaner = new(FakeCleaner)
@@ -0,0 +1,186 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+)
+
+type FakeCompatChecker struct {
+	CheckCompatStub        func(migrate.CheckCompatOptions) error
+	checkCompatMutex       sync.RWMutex
+	checkCompatArgsForCall []struct {
+		arg1 migrate.CheckCompatOptions
+	}
+	checkCompatReturns struct {
+		result1 error
+	}
+	checkCompatReturnsOnCall map[int]struct {
+		result1 error
+	}
+	CheckServiceExistsStub        func(string) error
+	checkServiceExistsMutex       sync.RWMutex
+	checkServiceExistsArgsForCall []struct {
+		arg1 string
+	}
+	checkServiceExistsReturns struct {
+		result1 error
+	}
+	checkServiceExistsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCompatChecker) CheckCompat(arg1 migrate.CheckCompatOptions) error {
+	fake.checkCompatMutex.Lock()
+	ret, specificReturn := fake.checkCompatReturnsOnCall[len(fake.checkCompatArgsForCall)]
+	fake.checkCompatArgsForCall = append(fake.checkCompatArgsForCall, struct {
+		arg1 migrate.CheckCompatOptions
+	}{arg1})
+	stub := fake.CheckCompatStub
+	fakeReturns := fake.checkCompatReturns
+	fake.recordInvocation("CheckCompat", []interface{}{arg1})
+	fake.checkCompatMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCompatChecker) CheckCompatCallCount() int {
+	fake.checkCompatMutex.RLock()
+	defer fake.checkCompatMutex.RUnlock()
+	return len(fake.checkCompatArgsForCall)
+}
+
+func (fake *FakeCompatChecker) CheckCompatCalls(stub func(migrate.CheckCompatOptions) error) {
+	fake.checkCompatMutex.Lock()
+	defer fake.checkCompatMutex.Unlock()
+	fake.CheckCompatStub = stub
+}
+
+func (fake *FakeCompatChecker) CheckCompatArgsForCall(i int) migrate.CheckCompatOptions {
+	fake.checkCompatMutex.RLock()
+	defer fake.checkCompatMutex.RUnlock()
+	argsForCall := fake.checkCompatArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCompatChecker) CheckCompatReturns(result1 error) {
+	fake.checkCompatMutex.Lock()
+	defer fake.checkCompatMutex.Unlock()
+	fake.CheckCompatStub = nil
+	fake.checkCompatReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompatChecker) CheckCompatReturnsOnCall(i int, result1 error) {
+	fake.checkCompatMutex.Lock()
+	defer fake.checkCompatMutex.Unlock()
+	fake.CheckCompatStub = nil
+	if fake.checkCompatReturnsOnCall == nil {
+		fake.checkCompatReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkCompatReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompatChecker) CheckServiceExists(arg1 string) error {
+	fake.checkServiceExistsMutex.Lock()
+	ret, specificReturn := fake.checkServiceExistsReturnsOnCall[len(fake.checkServiceExistsArgsForCall)]
+	fake.checkServiceExistsArgsForCall = append(fake.checkServiceExistsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CheckServiceExistsStub
+	fakeReturns := fake.checkServiceExistsReturns
+	fake.recordInvocation("CheckServiceExists", []interface{}{arg1})
+	fake.checkServiceExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeCompatChecker) CheckServiceExistsCallCount() int {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	return len(fake.checkServiceExistsArgsForCall)
+}
+
+func (fake *FakeCompatChecker) CheckServiceExistsCalls(stub func(string) error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = stub
+}
+
+func (fake *FakeCompatChecker) CheckServiceExistsArgsForCall(i int) string {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	argsForCall := fake.checkServiceExistsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCompatChecker) CheckServiceExistsReturns(result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	fake.checkServiceExistsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompatChecker) CheckServiceExistsReturnsOnCall(i int, result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	if fake.checkServiceExistsReturnsOnCall == nil {
+		fake.checkServiceExistsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkServiceExistsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeCompatChecker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkCompatMutex.RLock()
+	defer fake.checkCompatMutex.RUnlock()
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCompatChecker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commands.CompatChecker = new(FakeCompatChecker)
@@ -0,0 +1,186 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+)
+
+type FakeExporter struct {
+	CheckServiceExistsStub        func(string) error
+	checkServiceExistsMutex       sync.RWMutex
+	checkServiceExistsArgsForCall []struct {
+		arg1 string
+	}
+	checkServiceExistsReturns struct {
+		result1 error
+	}
+	checkServiceExistsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ExportStub        func(migrate.ExportOptions) error
+	exportMutex       sync.RWMutex
+	exportArgsForCall []struct {
+		arg1 migrate.ExportOptions
+	}
+	exportReturns struct {
+		result1 error
+	}
+	exportReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeExporter) CheckServiceExists(arg1 string) error {
+	fake.checkServiceExistsMutex.Lock()
+	ret, specificReturn := fake.checkServiceExistsReturnsOnCall[len(fake.checkServiceExistsArgsForCall)]
+	fake.checkServiceExistsArgsForCall = append(fake.checkServiceExistsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CheckServiceExistsStub
+	fakeReturns := fake.checkServiceExistsReturns
+	fake.recordInvocation("CheckServiceExists", []interface{}{arg1})
+	fake.checkServiceExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExporter) CheckServiceExistsCallCount() int {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	return len(fake.checkServiceExistsArgsForCall)
+}
+
+func (fake *FakeExporter) CheckServiceExistsCalls(stub func(string) error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = stub
+}
+
+func (fake *FakeExporter) CheckServiceExistsArgsForCall(i int) string {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	argsForCall := fake.checkServiceExistsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExporter) CheckServiceExistsReturns(result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	fake.checkServiceExistsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExporter) CheckServiceExistsReturnsOnCall(i int, result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	if fake.checkServiceExistsReturnsOnCall == nil {
+		fake.checkServiceExistsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkServiceExistsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExporter) Export(arg1 migrate.ExportOptions) error {
+	fake.exportMutex.Lock()
+	ret, specificReturn := fake.exportReturnsOnCall[len(fake.exportArgsForCall)]
+	fake.exportArgsForCall = append(fake.exportArgsForCall, struct {
+		arg1 migrate.ExportOptions
+	}{arg1})
+	stub := fake.ExportStub
+	fakeReturns := fake.exportReturns
+	fake.recordInvocation("Export", []interface{}{arg1})
+	fake.exportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeExporter) ExportCallCount() int {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	return len(fake.exportArgsForCall)
+}
+
+func (fake *FakeExporter) ExportCalls(stub func(migrate.ExportOptions) error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = stub
+}
+
+func (fake *FakeExporter) ExportArgsForCall(i int) migrate.ExportOptions {
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	argsForCall := fake.exportArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeExporter) ExportReturns(result1 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	fake.exportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExporter) ExportReturnsOnCall(i int, result1 error) {
+	fake.exportMutex.Lock()
+	defer fake.exportMutex.Unlock()
+	fake.ExportStub = nil
+	if fake.exportReturnsOnCall == nil {
+		fake.exportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.exportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeExporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	fake.exportMutex.RLock()
+	defer fake.exportMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeExporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commands.Exporter = new(FakeExporter)
@@ -0,0 +1,186 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+)
+
+type FakeImporter struct {
+	CheckServiceExistsStub        func(string) error
+	checkServiceExistsMutex       sync.RWMutex
+	checkServiceExistsArgsForCall []struct {
+		arg1 string
+	}
+	checkServiceExistsReturns struct {
+		result1 error
+	}
+	checkServiceExistsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ImportStub        func(migrate.ImportOptions) error
+	importMutex       sync.RWMutex
+	importArgsForCall []struct {
+		arg1 migrate.ImportOptions
+	}
+	importReturns struct {
+		result1 error
+	}
+	importReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeImporter) CheckServiceExists(arg1 string) error {
+	fake.checkServiceExistsMutex.Lock()
+	ret, specificReturn := fake.checkServiceExistsReturnsOnCall[len(fake.checkServiceExistsArgsForCall)]
+	fake.checkServiceExistsArgsForCall = append(fake.checkServiceExistsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CheckServiceExistsStub
+	fakeReturns := fake.checkServiceExistsReturns
+	fake.recordInvocation("CheckServiceExists", []interface{}{arg1})
+	fake.checkServiceExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeImporter) CheckServiceExistsCallCount() int {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	return len(fake.checkServiceExistsArgsForCall)
+}
+
+func (fake *FakeImporter) CheckServiceExistsCalls(stub func(string) error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = stub
+}
+
+func (fake *FakeImporter) CheckServiceExistsArgsForCall(i int) string {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	argsForCall := fake.checkServiceExistsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImporter) CheckServiceExistsReturns(result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	fake.checkServiceExistsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImporter) CheckServiceExistsReturnsOnCall(i int, result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	if fake.checkServiceExistsReturnsOnCall == nil {
+		fake.checkServiceExistsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkServiceExistsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImporter) Import(arg1 migrate.ImportOptions) error {
+	fake.importMutex.Lock()
+	ret, specificReturn := fake.importReturnsOnCall[len(fake.importArgsForCall)]
+	fake.importArgsForCall = append(fake.importArgsForCall, struct {
+		arg1 migrate.ImportOptions
+	}{arg1})
+	stub := fake.ImportStub
+	fakeReturns := fake.importReturns
+	fake.recordInvocation("Import", []interface{}{arg1})
+	fake.importMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeImporter) ImportCallCount() int {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	return len(fake.importArgsForCall)
+}
+
+func (fake *FakeImporter) ImportCalls(stub func(migrate.ImportOptions) error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = stub
+}
+
+func (fake *FakeImporter) ImportArgsForCall(i int) migrate.ImportOptions {
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	argsForCall := fake.importArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImporter) ImportReturns(result1 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	fake.importReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImporter) ImportReturnsOnCall(i int, result1 error) {
+	fake.importMutex.Lock()
+	defer fake.importMutex.Unlock()
+	fake.ImportStub = nil
+	if fake.importReturnsOnCall == nil {
+		fake.importReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.importReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeImporter) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	fake.importMutex.RLock()
+	defer fake.importMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeImporter) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commands.Importer = new(FakeImporter)
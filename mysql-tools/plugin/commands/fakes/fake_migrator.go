@@ -2,6 +2,7 @@
 package fakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
@@ -9,6 +10,21 @@ import (
 )
 
 type FakeMigrator struct {
+	BeginReportStub        func()
+	beginReportMutex       sync.RWMutex
+	beginReportArgsForCall []struct {
+	}
+	CheckAppSettingsStub        func(migrate.MigrateOptions) error
+	checkAppSettingsMutex       sync.RWMutex
+	checkAppSettingsArgsForCall []struct {
+		arg1 migrate.MigrateOptions
+	}
+	checkAppSettingsReturns struct {
+		result1 error
+	}
+	checkAppSettingsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CheckServiceExistsStub        func(string) error
 	checkServiceExistsMutex       sync.RWMutex
 	checkServiceExistsArgsForCall []struct {
@@ -31,11 +47,24 @@ type FakeMigrator struct {
 	cleanupOnErrorReturnsOnCall map[int]struct {
 		result1 error
 	}
-	CreateServiceInstanceStub        func(string, string) error
+	ConfirmCutoverStub        func(string) bool
+	confirmCutoverMutex       sync.RWMutex
+	confirmCutoverArgsForCall []struct {
+		arg1 string
+	}
+	confirmCutoverReturns struct {
+		result1 bool
+	}
+	confirmCutoverReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	CreateServiceInstanceStub        func(context.Context, string, string, migrate.ServiceInstanceConfig) error
 	createServiceInstanceMutex       sync.RWMutex
 	createServiceInstanceArgsForCall []struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
+		arg3 string
+		arg4 migrate.ServiceInstanceConfig
 	}
 	createServiceInstanceReturns struct {
 		result1 error
@@ -43,10 +72,36 @@ type FakeMigrator struct {
 	createServiceInstanceReturnsOnCall map[int]struct {
 		result1 error
 	}
-	MigrateDataStub        func(migrate.MigrateOptions) error
+	CutOverStub        func(context.Context, migrate.MigrateOptions) error
+	cutOverMutex       sync.RWMutex
+	cutOverArgsForCall []struct {
+		arg1 context.Context
+		arg2 migrate.MigrateOptions
+	}
+	cutOverReturns struct {
+		result1 error
+	}
+	cutOverReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LoadStateStub        func(string) (migrate.State, error)
+	loadStateMutex       sync.RWMutex
+	loadStateArgsForCall []struct {
+		arg1 string
+	}
+	loadStateReturns struct {
+		result1 migrate.State
+		result2 error
+	}
+	loadStateReturnsOnCall map[int]struct {
+		result1 migrate.State
+		result2 error
+	}
+	MigrateDataStub        func(context.Context, migrate.MigrateOptions) error
 	migrateDataMutex       sync.RWMutex
 	migrateDataArgsForCall []struct {
-		arg1 migrate.MigrateOptions
+		arg1 context.Context
+		arg2 migrate.MigrateOptions
 	}
 	migrateDataReturns struct {
 		result1 error
@@ -54,6 +109,56 @@ type FakeMigrator struct {
 	migrateDataReturnsOnCall map[int]struct {
 		result1 error
 	}
+	PreflightStub        func(migrate.MigrateOptions, string) migrate.PreflightReport
+	preflightMutex       sync.RWMutex
+	preflightArgsForCall []struct {
+		arg1 migrate.MigrateOptions
+		arg2 string
+	}
+	preflightReturns struct {
+		result1 migrate.PreflightReport
+	}
+	preflightReturnsOnCall map[int]struct {
+		result1 migrate.PreflightReport
+	}
+	RebindStub        func([]migrate.RecordedBinding, string, string, bool) []migrate.RebindResult
+	rebindMutex       sync.RWMutex
+	rebindArgsForCall []struct {
+		arg1 []migrate.RecordedBinding
+		arg2 string
+		arg3 string
+		arg4 bool
+	}
+	rebindReturns struct {
+		result1 []migrate.RebindResult
+	}
+	rebindReturnsOnCall map[int]struct {
+		result1 []migrate.RebindResult
+	}
+	RecordBindingsStub        func(string) ([]migrate.RecordedBinding, error)
+	recordBindingsMutex       sync.RWMutex
+	recordBindingsArgsForCall []struct {
+		arg1 string
+	}
+	recordBindingsReturns struct {
+		result1 []migrate.RecordedBinding
+		result2 error
+	}
+	recordBindingsReturnsOnCall map[int]struct {
+		result1 []migrate.RecordedBinding
+		result2 error
+	}
+	RemoveStateStub        func(string) error
+	removeStateMutex       sync.RWMutex
+	removeStateArgsForCall []struct {
+		arg1 string
+	}
+	removeStateReturns struct {
+		result1 error
+	}
+	removeStateReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RenameServiceInstancesStub        func(string, string) error
 	renameServiceInstancesMutex       sync.RWMutex
 	renameServiceInstancesArgsForCall []struct {
@@ -66,10 +171,131 @@ type FakeMigrator struct {
 	renameServiceInstancesReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SaveStateStub        func(migrate.State) error
+	saveStateMutex       sync.RWMutex
+	saveStateArgsForCall []struct {
+		arg1 migrate.State
+	}
+	saveStateReturns struct {
+		result1 error
+	}
+	saveStateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WaitForServiceInstanceStub        func(context.Context, string) error
+	waitForServiceInstanceMutex       sync.RWMutex
+	waitForServiceInstanceArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+	}
+	waitForServiceInstanceReturns struct {
+		result1 error
+	}
+	waitForServiceInstanceReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WriteReportStub        func(string, migrate.State, error) error
+	writeReportMutex       sync.RWMutex
+	writeReportArgsForCall []struct {
+		arg1 string
+		arg2 migrate.State
+		arg3 error
+	}
+	writeReportReturns struct {
+		result1 error
+	}
+	writeReportReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeMigrator) BeginReport() {
+	fake.beginReportMutex.Lock()
+	fake.beginReportArgsForCall = append(fake.beginReportArgsForCall, struct {
+	}{})
+	stub := fake.BeginReportStub
+	fake.recordInvocation("BeginReport", []interface{}{})
+	fake.beginReportMutex.Unlock()
+	if stub != nil {
+		fake.BeginReportStub()
+	}
+}
+
+func (fake *FakeMigrator) BeginReportCallCount() int {
+	fake.beginReportMutex.RLock()
+	defer fake.beginReportMutex.RUnlock()
+	return len(fake.beginReportArgsForCall)
+}
+
+func (fake *FakeMigrator) BeginReportCalls(stub func()) {
+	fake.beginReportMutex.Lock()
+	defer fake.beginReportMutex.Unlock()
+	fake.BeginReportStub = stub
+}
+
+func (fake *FakeMigrator) CheckAppSettings(arg1 migrate.MigrateOptions) error {
+	fake.checkAppSettingsMutex.Lock()
+	ret, specificReturn := fake.checkAppSettingsReturnsOnCall[len(fake.checkAppSettingsArgsForCall)]
+	fake.checkAppSettingsArgsForCall = append(fake.checkAppSettingsArgsForCall, struct {
+		arg1 migrate.MigrateOptions
+	}{arg1})
+	stub := fake.CheckAppSettingsStub
+	fakeReturns := fake.checkAppSettingsReturns
+	fake.recordInvocation("CheckAppSettings", []interface{}{arg1})
+	fake.checkAppSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) CheckAppSettingsCallCount() int {
+	fake.checkAppSettingsMutex.RLock()
+	defer fake.checkAppSettingsMutex.RUnlock()
+	return len(fake.checkAppSettingsArgsForCall)
+}
+
+func (fake *FakeMigrator) CheckAppSettingsCalls(stub func(migrate.MigrateOptions) error) {
+	fake.checkAppSettingsMutex.Lock()
+	defer fake.checkAppSettingsMutex.Unlock()
+	fake.CheckAppSettingsStub = stub
+}
+
+func (fake *FakeMigrator) CheckAppSettingsArgsForCall(i int) migrate.MigrateOptions {
+	fake.checkAppSettingsMutex.RLock()
+	defer fake.checkAppSettingsMutex.RUnlock()
+	argsForCall := fake.checkAppSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMigrator) CheckAppSettingsReturns(result1 error) {
+	fake.checkAppSettingsMutex.Lock()
+	defer fake.checkAppSettingsMutex.Unlock()
+	fake.CheckAppSettingsStub = nil
+	fake.checkAppSettingsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) CheckAppSettingsReturnsOnCall(i int, result1 error) {
+	fake.checkAppSettingsMutex.Lock()
+	defer fake.checkAppSettingsMutex.Unlock()
+	fake.CheckAppSettingsStub = nil
+	if fake.checkAppSettingsReturnsOnCall == nil {
+		fake.checkAppSettingsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkAppSettingsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeMigrator) CheckServiceExists(arg1 string) error {
 	fake.checkServiceExistsMutex.Lock()
 	ret, specificReturn := fake.checkServiceExistsReturnsOnCall[len(fake.checkServiceExistsArgsForCall)]
@@ -192,19 +418,82 @@ func (fake *FakeMigrator) CleanupOnErrorReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *FakeMigrator) CreateServiceInstance(arg1 string, arg2 string) error {
+func (fake *FakeMigrator) ConfirmCutover(arg1 string) bool {
+	fake.confirmCutoverMutex.Lock()
+	ret, specificReturn := fake.confirmCutoverReturnsOnCall[len(fake.confirmCutoverArgsForCall)]
+	fake.confirmCutoverArgsForCall = append(fake.confirmCutoverArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConfirmCutoverStub
+	fakeReturns := fake.confirmCutoverReturns
+	fake.recordInvocation("ConfirmCutover", []interface{}{arg1})
+	fake.confirmCutoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) ConfirmCutoverCallCount() int {
+	fake.confirmCutoverMutex.RLock()
+	defer fake.confirmCutoverMutex.RUnlock()
+	return len(fake.confirmCutoverArgsForCall)
+}
+
+func (fake *FakeMigrator) ConfirmCutoverCalls(stub func(string) bool) {
+	fake.confirmCutoverMutex.Lock()
+	defer fake.confirmCutoverMutex.Unlock()
+	fake.ConfirmCutoverStub = stub
+}
+
+func (fake *FakeMigrator) ConfirmCutoverArgsForCall(i int) string {
+	fake.confirmCutoverMutex.RLock()
+	defer fake.confirmCutoverMutex.RUnlock()
+	argsForCall := fake.confirmCutoverArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMigrator) ConfirmCutoverReturns(result1 bool) {
+	fake.confirmCutoverMutex.Lock()
+	defer fake.confirmCutoverMutex.Unlock()
+	fake.ConfirmCutoverStub = nil
+	fake.confirmCutoverReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeMigrator) ConfirmCutoverReturnsOnCall(i int, result1 bool) {
+	fake.confirmCutoverMutex.Lock()
+	defer fake.confirmCutoverMutex.Unlock()
+	fake.ConfirmCutoverStub = nil
+	if fake.confirmCutoverReturnsOnCall == nil {
+		fake.confirmCutoverReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.confirmCutoverReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeMigrator) CreateServiceInstance(arg1 context.Context, arg2 string, arg3 string, arg4 migrate.ServiceInstanceConfig) error {
 	fake.createServiceInstanceMutex.Lock()
 	ret, specificReturn := fake.createServiceInstanceReturnsOnCall[len(fake.createServiceInstanceArgsForCall)]
 	fake.createServiceInstanceArgsForCall = append(fake.createServiceInstanceArgsForCall, struct {
-		arg1 string
+		arg1 context.Context
 		arg2 string
-	}{arg1, arg2})
+		arg3 string
+		arg4 migrate.ServiceInstanceConfig
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.CreateServiceInstanceStub
 	fakeReturns := fake.createServiceInstanceReturns
-	fake.recordInvocation("CreateServiceInstance", []interface{}{arg1, arg2})
+	fake.recordInvocation("CreateServiceInstance", []interface{}{arg1, arg2, arg3, arg4})
 	fake.createServiceInstanceMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -218,17 +507,17 @@ func (fake *FakeMigrator) CreateServiceInstanceCallCount() int {
 	return len(fake.createServiceInstanceArgsForCall)
 }
 
-func (fake *FakeMigrator) CreateServiceInstanceCalls(stub func(string, string) error) {
+func (fake *FakeMigrator) CreateServiceInstanceCalls(stub func(context.Context, string, string, migrate.ServiceInstanceConfig) error) {
 	fake.createServiceInstanceMutex.Lock()
 	defer fake.createServiceInstanceMutex.Unlock()
 	fake.CreateServiceInstanceStub = stub
 }
 
-func (fake *FakeMigrator) CreateServiceInstanceArgsForCall(i int) (string, string) {
+func (fake *FakeMigrator) CreateServiceInstanceArgsForCall(i int) (context.Context, string, string, migrate.ServiceInstanceConfig) {
 	fake.createServiceInstanceMutex.RLock()
 	defer fake.createServiceInstanceMutex.RUnlock()
 	argsForCall := fake.createServiceInstanceArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeMigrator) CreateServiceInstanceReturns(result1 error) {
@@ -254,18 +543,145 @@ func (fake *FakeMigrator) CreateServiceInstanceReturnsOnCall(i int, result1 erro
 	}{result1}
 }
 
-func (fake *FakeMigrator) MigrateData(arg1 migrate.MigrateOptions) error {
+func (fake *FakeMigrator) CutOver(arg1 context.Context, arg2 migrate.MigrateOptions) error {
+	fake.cutOverMutex.Lock()
+	ret, specificReturn := fake.cutOverReturnsOnCall[len(fake.cutOverArgsForCall)]
+	fake.cutOverArgsForCall = append(fake.cutOverArgsForCall, struct {
+		arg1 context.Context
+		arg2 migrate.MigrateOptions
+	}{arg1, arg2})
+	stub := fake.CutOverStub
+	fakeReturns := fake.cutOverReturns
+	fake.recordInvocation("CutOver", []interface{}{arg1, arg2})
+	fake.cutOverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) CutOverCallCount() int {
+	fake.cutOverMutex.RLock()
+	defer fake.cutOverMutex.RUnlock()
+	return len(fake.cutOverArgsForCall)
+}
+
+func (fake *FakeMigrator) CutOverCalls(stub func(context.Context, migrate.MigrateOptions) error) {
+	fake.cutOverMutex.Lock()
+	defer fake.cutOverMutex.Unlock()
+	fake.CutOverStub = stub
+}
+
+func (fake *FakeMigrator) CutOverArgsForCall(i int) (context.Context, migrate.MigrateOptions) {
+	fake.cutOverMutex.RLock()
+	defer fake.cutOverMutex.RUnlock()
+	argsForCall := fake.cutOverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMigrator) CutOverReturns(result1 error) {
+	fake.cutOverMutex.Lock()
+	defer fake.cutOverMutex.Unlock()
+	fake.CutOverStub = nil
+	fake.cutOverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) CutOverReturnsOnCall(i int, result1 error) {
+	fake.cutOverMutex.Lock()
+	defer fake.cutOverMutex.Unlock()
+	fake.CutOverStub = nil
+	if fake.cutOverReturnsOnCall == nil {
+		fake.cutOverReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cutOverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) LoadState(arg1 string) (migrate.State, error) {
+	fake.loadStateMutex.Lock()
+	ret, specificReturn := fake.loadStateReturnsOnCall[len(fake.loadStateArgsForCall)]
+	fake.loadStateArgsForCall = append(fake.loadStateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LoadStateStub
+	fakeReturns := fake.loadStateReturns
+	fake.recordInvocation("LoadState", []interface{}{arg1})
+	fake.loadStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMigrator) LoadStateCallCount() int {
+	fake.loadStateMutex.RLock()
+	defer fake.loadStateMutex.RUnlock()
+	return len(fake.loadStateArgsForCall)
+}
+
+func (fake *FakeMigrator) LoadStateCalls(stub func(string) (migrate.State, error)) {
+	fake.loadStateMutex.Lock()
+	defer fake.loadStateMutex.Unlock()
+	fake.LoadStateStub = stub
+}
+
+func (fake *FakeMigrator) LoadStateArgsForCall(i int) string {
+	fake.loadStateMutex.RLock()
+	defer fake.loadStateMutex.RUnlock()
+	argsForCall := fake.loadStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMigrator) LoadStateReturns(result1 migrate.State, result2 error) {
+	fake.loadStateMutex.Lock()
+	defer fake.loadStateMutex.Unlock()
+	fake.LoadStateStub = nil
+	fake.loadStateReturns = struct {
+		result1 migrate.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMigrator) LoadStateReturnsOnCall(i int, result1 migrate.State, result2 error) {
+	fake.loadStateMutex.Lock()
+	defer fake.loadStateMutex.Unlock()
+	fake.LoadStateStub = nil
+	if fake.loadStateReturnsOnCall == nil {
+		fake.loadStateReturnsOnCall = make(map[int]struct {
+			result1 migrate.State
+			result2 error
+		})
+	}
+	fake.loadStateReturnsOnCall[i] = struct {
+		result1 migrate.State
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMigrator) MigrateData(arg1 context.Context, arg2 migrate.MigrateOptions) error {
 	fake.migrateDataMutex.Lock()
 	ret, specificReturn := fake.migrateDataReturnsOnCall[len(fake.migrateDataArgsForCall)]
 	fake.migrateDataArgsForCall = append(fake.migrateDataArgsForCall, struct {
-		arg1 migrate.MigrateOptions
-	}{arg1})
+		arg1 context.Context
+		arg2 migrate.MigrateOptions
+	}{arg1, arg2})
 	stub := fake.MigrateDataStub
 	fakeReturns := fake.migrateDataReturns
-	fake.recordInvocation("MigrateData", []interface{}{arg1})
+	fake.recordInvocation("MigrateData", []interface{}{arg1, arg2})
 	fake.migrateDataMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -279,17 +695,17 @@ func (fake *FakeMigrator) MigrateDataCallCount() int {
 	return len(fake.migrateDataArgsForCall)
 }
 
-func (fake *FakeMigrator) MigrateDataCalls(stub func(migrate.MigrateOptions) error) {
+func (fake *FakeMigrator) MigrateDataCalls(stub func(context.Context, migrate.MigrateOptions) error) {
 	fake.migrateDataMutex.Lock()
 	defer fake.migrateDataMutex.Unlock()
 	fake.MigrateDataStub = stub
 }
 
-func (fake *FakeMigrator) MigrateDataArgsForCall(i int) migrate.MigrateOptions {
+func (fake *FakeMigrator) MigrateDataArgsForCall(i int) (context.Context, migrate.MigrateOptions) {
 	fake.migrateDataMutex.RLock()
 	defer fake.migrateDataMutex.RUnlock()
 	argsForCall := fake.migrateDataArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
 func (fake *FakeMigrator) MigrateDataReturns(result1 error) {
@@ -315,6 +731,262 @@ func (fake *FakeMigrator) MigrateDataReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeMigrator) Preflight(arg1 migrate.MigrateOptions, arg2 string) migrate.PreflightReport {
+	fake.preflightMutex.Lock()
+	ret, specificReturn := fake.preflightReturnsOnCall[len(fake.preflightArgsForCall)]
+	fake.preflightArgsForCall = append(fake.preflightArgsForCall, struct {
+		arg1 migrate.MigrateOptions
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.PreflightStub
+	fakeReturns := fake.preflightReturns
+	fake.recordInvocation("Preflight", []interface{}{arg1, arg2})
+	fake.preflightMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) PreflightCallCount() int {
+	fake.preflightMutex.RLock()
+	defer fake.preflightMutex.RUnlock()
+	return len(fake.preflightArgsForCall)
+}
+
+func (fake *FakeMigrator) PreflightCalls(stub func(migrate.MigrateOptions, string) migrate.PreflightReport) {
+	fake.preflightMutex.Lock()
+	defer fake.preflightMutex.Unlock()
+	fake.PreflightStub = stub
+}
+
+func (fake *FakeMigrator) PreflightArgsForCall(i int) (migrate.MigrateOptions, string) {
+	fake.preflightMutex.RLock()
+	defer fake.preflightMutex.RUnlock()
+	argsForCall := fake.preflightArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMigrator) PreflightReturns(result1 migrate.PreflightReport) {
+	fake.preflightMutex.Lock()
+	defer fake.preflightMutex.Unlock()
+	fake.PreflightStub = nil
+	fake.preflightReturns = struct {
+		result1 migrate.PreflightReport
+	}{result1}
+}
+
+func (fake *FakeMigrator) PreflightReturnsOnCall(i int, result1 migrate.PreflightReport) {
+	fake.preflightMutex.Lock()
+	defer fake.preflightMutex.Unlock()
+	fake.PreflightStub = nil
+	if fake.preflightReturnsOnCall == nil {
+		fake.preflightReturnsOnCall = make(map[int]struct {
+			result1 migrate.PreflightReport
+		})
+	}
+	fake.preflightReturnsOnCall[i] = struct {
+		result1 migrate.PreflightReport
+	}{result1}
+}
+
+func (fake *FakeMigrator) Rebind(arg1 []migrate.RecordedBinding, arg2 string, arg3 string, arg4 bool) []migrate.RebindResult {
+	var arg1Copy []migrate.RecordedBinding
+	if arg1 != nil {
+		arg1Copy = make([]migrate.RecordedBinding, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.rebindMutex.Lock()
+	ret, specificReturn := fake.rebindReturnsOnCall[len(fake.rebindArgsForCall)]
+	fake.rebindArgsForCall = append(fake.rebindArgsForCall, struct {
+		arg1 []migrate.RecordedBinding
+		arg2 string
+		arg3 string
+		arg4 bool
+	}{arg1Copy, arg2, arg3, arg4})
+	stub := fake.RebindStub
+	fakeReturns := fake.rebindReturns
+	fake.recordInvocation("Rebind", []interface{}{arg1Copy, arg2, arg3, arg4})
+	fake.rebindMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) RebindCallCount() int {
+	fake.rebindMutex.RLock()
+	defer fake.rebindMutex.RUnlock()
+	return len(fake.rebindArgsForCall)
+}
+
+func (fake *FakeMigrator) RebindCalls(stub func([]migrate.RecordedBinding, string, string, bool) []migrate.RebindResult) {
+	fake.rebindMutex.Lock()
+	defer fake.rebindMutex.Unlock()
+	fake.RebindStub = stub
+}
+
+func (fake *FakeMigrator) RebindArgsForCall(i int) ([]migrate.RecordedBinding, string, string, bool) {
+	fake.rebindMutex.RLock()
+	defer fake.rebindMutex.RUnlock()
+	argsForCall := fake.rebindArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeMigrator) RebindReturns(result1 []migrate.RebindResult) {
+	fake.rebindMutex.Lock()
+	defer fake.rebindMutex.Unlock()
+	fake.RebindStub = nil
+	fake.rebindReturns = struct {
+		result1 []migrate.RebindResult
+	}{result1}
+}
+
+func (fake *FakeMigrator) RebindReturnsOnCall(i int, result1 []migrate.RebindResult) {
+	fake.rebindMutex.Lock()
+	defer fake.rebindMutex.Unlock()
+	fake.RebindStub = nil
+	if fake.rebindReturnsOnCall == nil {
+		fake.rebindReturnsOnCall = make(map[int]struct {
+			result1 []migrate.RebindResult
+		})
+	}
+	fake.rebindReturnsOnCall[i] = struct {
+		result1 []migrate.RebindResult
+	}{result1}
+}
+
+func (fake *FakeMigrator) RecordBindings(arg1 string) ([]migrate.RecordedBinding, error) {
+	fake.recordBindingsMutex.Lock()
+	ret, specificReturn := fake.recordBindingsReturnsOnCall[len(fake.recordBindingsArgsForCall)]
+	fake.recordBindingsArgsForCall = append(fake.recordBindingsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RecordBindingsStub
+	fakeReturns := fake.recordBindingsReturns
+	fake.recordInvocation("RecordBindings", []interface{}{arg1})
+	fake.recordBindingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeMigrator) RecordBindingsCallCount() int {
+	fake.recordBindingsMutex.RLock()
+	defer fake.recordBindingsMutex.RUnlock()
+	return len(fake.recordBindingsArgsForCall)
+}
+
+func (fake *FakeMigrator) RecordBindingsCalls(stub func(string) ([]migrate.RecordedBinding, error)) {
+	fake.recordBindingsMutex.Lock()
+	defer fake.recordBindingsMutex.Unlock()
+	fake.RecordBindingsStub = stub
+}
+
+func (fake *FakeMigrator) RecordBindingsArgsForCall(i int) string {
+	fake.recordBindingsMutex.RLock()
+	defer fake.recordBindingsMutex.RUnlock()
+	argsForCall := fake.recordBindingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMigrator) RecordBindingsReturns(result1 []migrate.RecordedBinding, result2 error) {
+	fake.recordBindingsMutex.Lock()
+	defer fake.recordBindingsMutex.Unlock()
+	fake.RecordBindingsStub = nil
+	fake.recordBindingsReturns = struct {
+		result1 []migrate.RecordedBinding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMigrator) RecordBindingsReturnsOnCall(i int, result1 []migrate.RecordedBinding, result2 error) {
+	fake.recordBindingsMutex.Lock()
+	defer fake.recordBindingsMutex.Unlock()
+	fake.RecordBindingsStub = nil
+	if fake.recordBindingsReturnsOnCall == nil {
+		fake.recordBindingsReturnsOnCall = make(map[int]struct {
+			result1 []migrate.RecordedBinding
+			result2 error
+		})
+	}
+	fake.recordBindingsReturnsOnCall[i] = struct {
+		result1 []migrate.RecordedBinding
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeMigrator) RemoveState(arg1 string) error {
+	fake.removeStateMutex.Lock()
+	ret, specificReturn := fake.removeStateReturnsOnCall[len(fake.removeStateArgsForCall)]
+	fake.removeStateArgsForCall = append(fake.removeStateArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RemoveStateStub
+	fakeReturns := fake.removeStateReturns
+	fake.recordInvocation("RemoveState", []interface{}{arg1})
+	fake.removeStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) RemoveStateCallCount() int {
+	fake.removeStateMutex.RLock()
+	defer fake.removeStateMutex.RUnlock()
+	return len(fake.removeStateArgsForCall)
+}
+
+func (fake *FakeMigrator) RemoveStateCalls(stub func(string) error) {
+	fake.removeStateMutex.Lock()
+	defer fake.removeStateMutex.Unlock()
+	fake.RemoveStateStub = stub
+}
+
+func (fake *FakeMigrator) RemoveStateArgsForCall(i int) string {
+	fake.removeStateMutex.RLock()
+	defer fake.removeStateMutex.RUnlock()
+	argsForCall := fake.removeStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMigrator) RemoveStateReturns(result1 error) {
+	fake.removeStateMutex.Lock()
+	defer fake.removeStateMutex.Unlock()
+	fake.RemoveStateStub = nil
+	fake.removeStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) RemoveStateReturnsOnCall(i int, result1 error) {
+	fake.removeStateMutex.Lock()
+	defer fake.removeStateMutex.Unlock()
+	fake.RemoveStateStub = nil
+	if fake.removeStateReturnsOnCall == nil {
+		fake.removeStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.removeStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeMigrator) RenameServiceInstances(arg1 string, arg2 string) error {
 	fake.renameServiceInstancesMutex.Lock()
 	ret, specificReturn := fake.renameServiceInstancesReturnsOnCall[len(fake.renameServiceInstancesArgsForCall)]
@@ -377,19 +1049,229 @@ func (fake *FakeMigrator) RenameServiceInstancesReturnsOnCall(i int, result1 err
 	}{result1}
 }
 
+func (fake *FakeMigrator) SaveState(arg1 migrate.State) error {
+	fake.saveStateMutex.Lock()
+	ret, specificReturn := fake.saveStateReturnsOnCall[len(fake.saveStateArgsForCall)]
+	fake.saveStateArgsForCall = append(fake.saveStateArgsForCall, struct {
+		arg1 migrate.State
+	}{arg1})
+	stub := fake.SaveStateStub
+	fakeReturns := fake.saveStateReturns
+	fake.recordInvocation("SaveState", []interface{}{arg1})
+	fake.saveStateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) SaveStateCallCount() int {
+	fake.saveStateMutex.RLock()
+	defer fake.saveStateMutex.RUnlock()
+	return len(fake.saveStateArgsForCall)
+}
+
+func (fake *FakeMigrator) SaveStateCalls(stub func(migrate.State) error) {
+	fake.saveStateMutex.Lock()
+	defer fake.saveStateMutex.Unlock()
+	fake.SaveStateStub = stub
+}
+
+func (fake *FakeMigrator) SaveStateArgsForCall(i int) migrate.State {
+	fake.saveStateMutex.RLock()
+	defer fake.saveStateMutex.RUnlock()
+	argsForCall := fake.saveStateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeMigrator) SaveStateReturns(result1 error) {
+	fake.saveStateMutex.Lock()
+	defer fake.saveStateMutex.Unlock()
+	fake.SaveStateStub = nil
+	fake.saveStateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) SaveStateReturnsOnCall(i int, result1 error) {
+	fake.saveStateMutex.Lock()
+	defer fake.saveStateMutex.Unlock()
+	fake.SaveStateStub = nil
+	if fake.saveStateReturnsOnCall == nil {
+		fake.saveStateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveStateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) WaitForServiceInstance(arg1 context.Context, arg2 string) error {
+	fake.waitForServiceInstanceMutex.Lock()
+	ret, specificReturn := fake.waitForServiceInstanceReturnsOnCall[len(fake.waitForServiceInstanceArgsForCall)]
+	fake.waitForServiceInstanceArgsForCall = append(fake.waitForServiceInstanceArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.WaitForServiceInstanceStub
+	fakeReturns := fake.waitForServiceInstanceReturns
+	fake.recordInvocation("WaitForServiceInstance", []interface{}{arg1, arg2})
+	fake.waitForServiceInstanceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) WaitForServiceInstanceCallCount() int {
+	fake.waitForServiceInstanceMutex.RLock()
+	defer fake.waitForServiceInstanceMutex.RUnlock()
+	return len(fake.waitForServiceInstanceArgsForCall)
+}
+
+func (fake *FakeMigrator) WaitForServiceInstanceCalls(stub func(context.Context, string) error) {
+	fake.waitForServiceInstanceMutex.Lock()
+	defer fake.waitForServiceInstanceMutex.Unlock()
+	fake.WaitForServiceInstanceStub = stub
+}
+
+func (fake *FakeMigrator) WaitForServiceInstanceArgsForCall(i int) (context.Context, string) {
+	fake.waitForServiceInstanceMutex.RLock()
+	defer fake.waitForServiceInstanceMutex.RUnlock()
+	argsForCall := fake.waitForServiceInstanceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMigrator) WaitForServiceInstanceReturns(result1 error) {
+	fake.waitForServiceInstanceMutex.Lock()
+	defer fake.waitForServiceInstanceMutex.Unlock()
+	fake.WaitForServiceInstanceStub = nil
+	fake.waitForServiceInstanceReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) WaitForServiceInstanceReturnsOnCall(i int, result1 error) {
+	fake.waitForServiceInstanceMutex.Lock()
+	defer fake.waitForServiceInstanceMutex.Unlock()
+	fake.WaitForServiceInstanceStub = nil
+	if fake.waitForServiceInstanceReturnsOnCall == nil {
+		fake.waitForServiceInstanceReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForServiceInstanceReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) WriteReport(arg1 string, arg2 migrate.State, arg3 error) error {
+	fake.writeReportMutex.Lock()
+	ret, specificReturn := fake.writeReportReturnsOnCall[len(fake.writeReportArgsForCall)]
+	fake.writeReportArgsForCall = append(fake.writeReportArgsForCall, struct {
+		arg1 string
+		arg2 migrate.State
+		arg3 error
+	}{arg1, arg2, arg3})
+	stub := fake.WriteReportStub
+	fakeReturns := fake.writeReportReturns
+	fake.recordInvocation("WriteReport", []interface{}{arg1, arg2, arg3})
+	fake.writeReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMigrator) WriteReportCallCount() int {
+	fake.writeReportMutex.RLock()
+	defer fake.writeReportMutex.RUnlock()
+	return len(fake.writeReportArgsForCall)
+}
+
+func (fake *FakeMigrator) WriteReportCalls(stub func(string, migrate.State, error) error) {
+	fake.writeReportMutex.Lock()
+	defer fake.writeReportMutex.Unlock()
+	fake.WriteReportStub = stub
+}
+
+func (fake *FakeMigrator) WriteReportArgsForCall(i int) (string, migrate.State, error) {
+	fake.writeReportMutex.RLock()
+	defer fake.writeReportMutex.RUnlock()
+	argsForCall := fake.writeReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeMigrator) WriteReportReturns(result1 error) {
+	fake.writeReportMutex.Lock()
+	defer fake.writeReportMutex.Unlock()
+	fake.WriteReportStub = nil
+	fake.writeReportReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMigrator) WriteReportReturnsOnCall(i int, result1 error) {
+	fake.writeReportMutex.Lock()
+	defer fake.writeReportMutex.Unlock()
+	fake.WriteReportStub = nil
+	if fake.writeReportReturnsOnCall == nil {
+		fake.writeReportReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.writeReportReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeMigrator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.beginReportMutex.RLock()
+	defer fake.beginReportMutex.RUnlock()
+	fake.checkAppSettingsMutex.RLock()
+	defer fake.checkAppSettingsMutex.RUnlock()
 	fake.checkServiceExistsMutex.RLock()
 	defer fake.checkServiceExistsMutex.RUnlock()
 	fake.cleanupOnErrorMutex.RLock()
 	defer fake.cleanupOnErrorMutex.RUnlock()
+	fake.confirmCutoverMutex.RLock()
+	defer fake.confirmCutoverMutex.RUnlock()
 	fake.createServiceInstanceMutex.RLock()
 	defer fake.createServiceInstanceMutex.RUnlock()
+	fake.cutOverMutex.RLock()
+	defer fake.cutOverMutex.RUnlock()
+	fake.loadStateMutex.RLock()
+	defer fake.loadStateMutex.RUnlock()
 	fake.migrateDataMutex.RLock()
 	defer fake.migrateDataMutex.RUnlock()
+	fake.preflightMutex.RLock()
+	defer fake.preflightMutex.RUnlock()
+	fake.rebindMutex.RLock()
+	defer fake.rebindMutex.RUnlock()
+	fake.recordBindingsMutex.RLock()
+	defer fake.recordBindingsMutex.RUnlock()
+	fake.removeStateMutex.RLock()
+	defer fake.removeStateMutex.RUnlock()
 	fake.renameServiceInstancesMutex.RLock()
 	defer fake.renameServiceInstancesMutex.RUnlock()
+	fake.saveStateMutex.RLock()
+	defer fake.saveStateMutex.RUnlock()
+	fake.waitForServiceInstanceMutex.RLock()
+	defer fake.waitForServiceInstanceMutex.RUnlock()
+	fake.writeReportMutex.RLock()
+	defer fake.writeReportMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
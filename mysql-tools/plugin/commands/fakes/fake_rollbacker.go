@@ -0,0 +1,186 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+)
+
+type FakeRollbacker struct {
+	CheckServiceExistsStub        func(string) error
+	checkServiceExistsMutex       sync.RWMutex
+	checkServiceExistsArgsForCall []struct {
+		arg1 string
+	}
+	checkServiceExistsReturns struct {
+		result1 error
+	}
+	checkServiceExistsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RollbackStub        func(migrate.RollbackOptions) error
+	rollbackMutex       sync.RWMutex
+	rollbackArgsForCall []struct {
+		arg1 migrate.RollbackOptions
+	}
+	rollbackReturns struct {
+		result1 error
+	}
+	rollbackReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeRollbacker) CheckServiceExists(arg1 string) error {
+	fake.checkServiceExistsMutex.Lock()
+	ret, specificReturn := fake.checkServiceExistsReturnsOnCall[len(fake.checkServiceExistsArgsForCall)]
+	fake.checkServiceExistsArgsForCall = append(fake.checkServiceExistsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CheckServiceExistsStub
+	fakeReturns := fake.checkServiceExistsReturns
+	fake.recordInvocation("CheckServiceExists", []interface{}{arg1})
+	fake.checkServiceExistsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRollbacker) CheckServiceExistsCallCount() int {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	return len(fake.checkServiceExistsArgsForCall)
+}
+
+func (fake *FakeRollbacker) CheckServiceExistsCalls(stub func(string) error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = stub
+}
+
+func (fake *FakeRollbacker) CheckServiceExistsArgsForCall(i int) string {
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	argsForCall := fake.checkServiceExistsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRollbacker) CheckServiceExistsReturns(result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	fake.checkServiceExistsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRollbacker) CheckServiceExistsReturnsOnCall(i int, result1 error) {
+	fake.checkServiceExistsMutex.Lock()
+	defer fake.checkServiceExistsMutex.Unlock()
+	fake.CheckServiceExistsStub = nil
+	if fake.checkServiceExistsReturnsOnCall == nil {
+		fake.checkServiceExistsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.checkServiceExistsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRollbacker) Rollback(arg1 migrate.RollbackOptions) error {
+	fake.rollbackMutex.Lock()
+	ret, specificReturn := fake.rollbackReturnsOnCall[len(fake.rollbackArgsForCall)]
+	fake.rollbackArgsForCall = append(fake.rollbackArgsForCall, struct {
+		arg1 migrate.RollbackOptions
+	}{arg1})
+	stub := fake.RollbackStub
+	fakeReturns := fake.rollbackReturns
+	fake.recordInvocation("Rollback", []interface{}{arg1})
+	fake.rollbackMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeRollbacker) RollbackCallCount() int {
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	return len(fake.rollbackArgsForCall)
+}
+
+func (fake *FakeRollbacker) RollbackCalls(stub func(migrate.RollbackOptions) error) {
+	fake.rollbackMutex.Lock()
+	defer fake.rollbackMutex.Unlock()
+	fake.RollbackStub = stub
+}
+
+func (fake *FakeRollbacker) RollbackArgsForCall(i int) migrate.RollbackOptions {
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	argsForCall := fake.rollbackArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeRollbacker) RollbackReturns(result1 error) {
+	fake.rollbackMutex.Lock()
+	defer fake.rollbackMutex.Unlock()
+	fake.RollbackStub = nil
+	fake.rollbackReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRollbacker) RollbackReturnsOnCall(i int, result1 error) {
+	fake.rollbackMutex.Lock()
+	defer fake.rollbackMutex.Unlock()
+	fake.RollbackStub = nil
+	if fake.rollbackReturnsOnCall == nil {
+		fake.rollbackReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rollbackReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeRollbacker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkServiceExistsMutex.RLock()
+	defer fake.checkServiceExistsMutex.RUnlock()
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeRollbacker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ commands.Rollbacker = new(FakeRollbacker)
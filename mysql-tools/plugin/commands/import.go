@@ -0,0 +1,112 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+//counterfeiter:generate -o fakes/fake_importer.go . Importer
+type Importer interface {
+	CheckServiceExists(instanceName string) error
+	Import(opts migrate.ImportOptions) error
+}
+
+func Import(args []string, importer Importer) error {
+	const (
+		importUsage = `cf mysql-tools import [-h] [--no-cleanup] [--skip-tls-validation] [--definer <invoker|user@host>] [--schema <from>=<to>]... [--s3-endpoint <url>] [--s3-region <region>] <service-instance> <file|s3://bucket/key>`
+	)
+
+	var opts struct {
+		Args struct {
+			InstanceName string `positional-arg-name:"<service-instance>"`
+			Source       string `positional-arg-name:"<file|s3://bucket/key>"`
+		} `positional-args:"yes" required:"yes"`
+		Definer           string   `long:"definer" value-name:"<invoker|user@host>" description:"Convert views to SQL SECURITY INVOKER and make stored programs owned by the service instance's binding user (invoker, the default), or map every DEFINER to this account"`
+		Schemas           []string `long:"schema" value-name:"<from>=<to>" description:"Load the schema <from> of the dump into the schema <to>. May be repeated"`
+		S3Endpoint        string   `long:"s3-endpoint" value-name:"<url>" description:"URL of the S3-compatible object store. Defaults to AWS S3"`
+		S3Region          string   `long:"s3-region" value-name:"<region>" description:"Region of the S3 bucket. Defaults to us-east-1"`
+		NoCleanup         bool     `long:"no-cleanup" description:"don't clean up the migration app after importing"`
+		SkipTLSValidation bool     `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server and object store certificates. Not recommended!"`
+	}
+
+	parser := flags.NewParser(&opts, flags.None)
+	parser.Name = "cf mysql-tools import"
+	args, err := parser.ParseArgs(args)
+	if err == nil && len(args) == 0 {
+		switch {
+		case opts.Args.Source == "-":
+			err = errors.New("the dump must be a file or an s3://<bucket>/<key> URL")
+		case (opts.S3Endpoint != "" || opts.S3Region != "") && !s3.IsURL(opts.Args.Source):
+			err = errors.New("--s3-endpoint and --s3-region can only be used when importing from S3")
+		}
+	}
+	if err != nil || len(args) != 0 {
+		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
+		if err != nil {
+			msg = err.Error()
+		}
+		return fmt.Errorf("Usage: %s\n\n%s", importUsage, msg)
+	}
+
+	importOptions := migrate.ImportOptions{
+		InstanceName: opts.Args.InstanceName,
+		Source:       opts.Args.Source,
+		S3Endpoint:   opts.S3Endpoint,
+		S3Region:     opts.S3Region,
+		// Encrypted dumps are detected by the migration app, which only needs the passphrase for them
+		Passphrase:        os.Getenv(archive.PassphraseEnv),
+		Definer:           opts.Definer,
+		Schemas:           opts.Schemas,
+		SkipTLSValidation: opts.SkipTLSValidation,
+		Cleanup:           !opts.NoCleanup,
+	}
+
+	if opts.Definer != "" {
+		if _, err := definer.ParsePolicy(opts.Definer); err != nil {
+			return fmt.Errorf("invalid --definer: %w", err)
+		}
+	}
+
+	for _, mapping := range opts.Schemas {
+		if _, _, err := definer.ParseSchemaMapping(mapping); err != nil {
+			return fmt.Errorf("invalid --schema: %w", err)
+		}
+	}
+
+	if s3.IsURL(importOptions.Source) {
+		if _, err := s3.ParseURL(importOptions.Source); err != nil {
+			return fmt.Errorf("invalid dump location: %w", err)
+		}
+
+		if importOptions.S3Credentials, err = s3.CredentialsFromEnv(); err != nil {
+			return err
+		}
+	}
+
+	if err := importer.CheckServiceExists(importOptions.InstanceName); err != nil {
+		return err
+	}
+
+	return importer.Import(importOptions)
+}
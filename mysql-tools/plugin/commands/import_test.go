@@ -0,0 +1,127 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+var _ = Describe("Import", func() {
+	var fakeImporter *fakes.FakeImporter
+
+	const (
+		importUsage = `cf mysql-tools import [-h] [--no-cleanup] [--skip-tls-validation] [--definer <invoker|user@host>] [--schema <from>=<to>]... [--s3-endpoint <url>] [--s3-region <region>] <service-instance> <file|s3://bucket/key>`
+	)
+
+	BeforeEach(func() {
+		fakeImporter = new(fakes.FakeImporter)
+		GinkgoT().Setenv("MYSQL_TOOLS_PASSPHRASE", "")
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "")
+	})
+
+	It("imports a local dump into a service instance", func() {
+		Expect(commands.Import([]string{"some-instance", "backup.sql.gz"}, fakeImporter)).To(Succeed())
+
+		Expect(fakeImporter.CheckServiceExistsCallCount()).To(Equal(1))
+		Expect(fakeImporter.CheckServiceExistsArgsForCall(0)).To(Equal("some-instance"))
+
+		Expect(fakeImporter.ImportCallCount()).To(Equal(1))
+		Expect(fakeImporter.ImportArgsForCall(0)).To(Equal(migrate.ImportOptions{
+			InstanceName: "some-instance",
+			Source:       "backup.sql.gz",
+			Cleanup:      true,
+		}))
+	})
+
+	It("passes the definer policy, schema mappings and passphrase", func() {
+		GinkgoT().Setenv("MYSQL_TOOLS_PASSPHRASE", "some-passphrase")
+
+		args := []string{
+			"--definer", "app@%",
+			"--schema", "sakila=pagila",
+			"--schema", "app=app_v2",
+			"--skip-tls-validation",
+			"--no-cleanup",
+			"some-instance", "backup.sql.gz.enc",
+		}
+		Expect(commands.Import(args, fakeImporter)).To(Succeed())
+
+		Expect(fakeImporter.ImportArgsForCall(0)).To(Equal(migrate.ImportOptions{
+			InstanceName:      "some-instance",
+			Source:            "backup.sql.gz.enc",
+			Passphrase:        "some-passphrase",
+			Definer:           "app@%",
+			Schemas:           []string{"sakila=pagila", "app=app_v2"},
+			SkipTLSValidation: true,
+		}))
+	})
+
+	It("imports a dump from S3 using the credentials in the environment", func() {
+		GinkgoT().Setenv("AWS_ACCESS_KEY_ID", "some-key-id")
+		GinkgoT().Setenv("AWS_SECRET_ACCESS_KEY", "some-secret")
+
+		args := []string{"--s3-endpoint", "https://minio.example.com", "--s3-region", "some-region", "some-instance", "s3://some-bucket/some-key"}
+		Expect(commands.Import(args, fakeImporter)).To(Succeed())
+
+		Expect(fakeImporter.ImportArgsForCall(0)).To(Equal(migrate.ImportOptions{
+			InstanceName:  "some-instance",
+			Source:        "s3://some-bucket/some-key",
+			S3Endpoint:    "https://minio.example.com",
+			S3Region:      "some-region",
+			S3Credentials: s3.Credentials{AccessKeyID: "some-key-id", SecretAccessKey: "some-secret"},
+			Cleanup:       true,
+		}))
+	})
+
+	It("returns an error when importing from S3 without credentials", func() {
+		err := commands.Import([]string{"some-instance", "s3://some-bucket/some-key"}, fakeImporter)
+		Expect(err).To(MatchError("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to access S3"))
+		Expect(fakeImporter.ImportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the definer is invalid", func() {
+		err := commands.Import([]string{"--definer", "app", "some-instance", "backup.sql.gz"}, fakeImporter)
+		Expect(err).To(MatchError(`invalid --definer: invalid definer "app", expected "invoker" or <user>@<host>`))
+		Expect(fakeImporter.ImportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when a schema mapping is invalid", func() {
+		err := commands.Import([]string{"--schema", "sakila", "some-instance", "backup.sql.gz"}, fakeImporter)
+		Expect(err).To(MatchError(`invalid --schema: invalid schema mapping "sakila", expected <from>=<to>`))
+		Expect(fakeImporter.ImportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the service instance does not exist", func() {
+		fakeImporter.CheckServiceExistsReturns(errors.New("Service instance some-instance not found"))
+
+		err := commands.Import([]string{"some-instance", "backup.sql.gz"}, fakeImporter)
+		Expect(err).To(MatchError("Service instance some-instance not found"))
+		Expect(fakeImporter.ImportCallCount()).To(BeZero())
+	})
+
+	It("returns an error when the import fails", func() {
+		fakeImporter.ImportReturns(errors.New("some-import-error"))
+
+		err := commands.Import([]string{"some-instance", "backup.sql.gz"}, fakeImporter)
+		Expect(err).To(MatchError("some-import-error"))
+	})
+
+	DescribeTable("invalid arguments",
+		func(args []string, message string) {
+			err := commands.Import(args, fakeImporter)
+			Expect(err).To(MatchError("Usage: " + importUsage + "\n\n" + message))
+			Expect(fakeImporter.ImportCallCount()).To(BeZero())
+		},
+		Entry("no dump", []string{"some-instance"}, "the required argument `<file|s3://bucket/key>` was not provided"),
+		Entry("stdin", []string{"some-instance", "-"}, "the dump must be a file or an s3://<bucket>/<key> URL"),
+		Entry("an S3 endpoint for a local file", []string{"--s3-region", "some-region", "some-instance", "backup.sql.gz"}, "--s3-endpoint and --s3-region can only be used when importing from S3"),
+		Entry("extra arguments", []string{"some-instance", "backup.sql.gz", "extra"}, "unexpected arguments: extra"),
+	)
+})
@@ -1,42 +1,157 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/jessevdk/go-flags"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/presentation"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
 )
 
 //counterfeiter:generate -o fakes/fake_migrator.go . Migrator
 type Migrator interface {
 	CheckServiceExists(instanceName string) error
-	CreateServiceInstance(planName, instanceName string) error
+	CreateServiceInstance(ctx context.Context, planName, instanceName string, config migrate.ServiceInstanceConfig) error
+	WaitForServiceInstance(ctx context.Context, instanceName string) error
 	CleanupOnError(instanceName string) error
-	MigrateData(opts migrate.MigrateOptions) error
+	CheckAppSettings(opts migrate.MigrateOptions) error
+	MigrateData(ctx context.Context, opts migrate.MigrateOptions) error
 	RenameServiceInstances(donorInstanceName, recipientInstanceName string) error
+	LoadState(donorInstanceName string) (migrate.State, error)
+	SaveState(state migrate.State) error
+	RemoveState(donorInstanceName string) error
+	Preflight(opts migrate.MigrateOptions, planName string) migrate.PreflightReport
+	RecordBindings(instanceName string) ([]migrate.RecordedBinding, error)
+	Rebind(bindings []migrate.RecordedBinding, fromInstanceName, toInstanceName string, restage bool) []migrate.RebindResult
+	ConfirmCutover(donorInstanceName string) bool
+	CutOver(ctx context.Context, opts migrate.MigrateOptions) error
+	BeginReport()
+	WriteReport(path string, state migrate.State, migrationErr error) error
 }
 
-func Migrate(args []string, migrator Migrator) error {
+func Migrate(args []string, migrator Migrator) (err error) {
 	const (
-		migrateUsage = `cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] <source-service-instance> <p.mysql-plan-type>`
+		migrateUsage = `cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--verify[=rows|checksum]] [--fingerprint] [--{include,exclude}-{schema,table} <pattern>]... [--parallel <workers>] [--engine <go|exec>] [--definer <invoker|user@host>] [--masking-rules <file>] [--convert-charset <charset> [--collation <collation>]] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--app-memory <size>] [--app-disk <size>] [--stack <stack>] [--require-isolation-segment <segment>] [--online] [--dry-run] [--rebind [--restage]] [--report <file>] [--task-timeout <duration>] [--provision-timeout <duration>] [--recipient-params <json|file>] [--recipient-tags <tags>] <source-service-instance> <p.mysql-plan-type>
+       cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--verify[=rows|checksum]] [--fingerprint] [--{include,exclude}-{schema,table} <pattern>]... [--parallel <workers>] [--engine <go|exec>] [--definer <invoker|user@host>] [--masking-rules <file>] [--convert-charset <charset> [--collation <collation>]] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--app-memory <size>] [--app-disk <size>] [--stack <stack>] [--require-isolation-segment <segment>] [--online] [--dry-run] [--rebind [--restage]] [--report <file>] [--task-timeout <duration>] [--force-overwrite] --recipient <recipient-service-instance> <source-service-instance>
+       cf mysql-tools migrate --resume [--force-overwrite] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--report <file>] [--task-timeout <duration>] [--provision-timeout <duration>] <source-service-instance>`
 	)
 
 	var opts struct {
 		Args struct {
-			Source   string `positional-arg-name:"<source-service-instance>"`
+			Source   string `positional-arg-name:"<source-service-instance>" required:"yes"`
 			PlanName string `positional-arg-name:"<p.mysql-plan-type>"`
-		} `positional-args:"yes" required:"yes"`
-		NoCleanup         bool `long:"no-cleanup" description:"don't clean up migration app and new service instance after a failed migration"`
-		SkipTLSValidation bool `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server certificate. Not recommended!"`
+		} `positional-args:"yes"`
+		NoCleanup             bool          `long:"no-cleanup" description:"don't clean up migration app and new service instance after a failed migration"`
+		SkipTLSValidation     bool          `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server certificate. Not recommended!"`
+		IncludeStoredPrograms bool          `long:"include-stored-programs" description:"Migrate stored routines, triggers and events. Their definer is rewritten to the recipient's binding user"`
+		Resume                bool          `long:"resume" description:"Resume an interrupted migration from the last completed phase"`
+		DryRun                bool          `long:"dry-run" description:"Check whether the migration can succeed without creating a service instance or pushing the migration app. A temporary service key is created on the source service instance to inspect it, and deleted afterwards"`
+		Recipient             string        `long:"recipient" value-name:"<recipient-service-instance>" description:"Migrate into an existing service instance instead of creating a new one. Service instances are not renamed afterwards"`
+		RecipientParams       string        `long:"recipient-params" value-name:"<json|file>" description:"Arbitrary parameters, as a JSON object or a file containing one, used to create the new service instance"`
+		RecipientTags         string        `long:"recipient-tags" value-name:"<tags>" description:"Comma-separated tags added to the new service instance"`
+		ForceOverwrite        bool          `long:"force-overwrite" description:"Migrate into an existing service instance even if it already contains tables"`
+		Rebind                bool          `long:"rebind" description:"Move the source's app bindings and service keys, including their parameters, to the new service instance after migrating"`
+		Restage               bool          `long:"restage" description:"Restage apps after rebinding them"`
+		IncludeSchemas        []string      `long:"include-schema" value-name:"<pattern>" description:"Only migrate schemas matching this glob pattern. May be repeated"`
+		ExcludeSchemas        []string      `long:"exclude-schema" value-name:"<pattern>" description:"Do not migrate schemas matching this glob pattern. May be repeated"`
+		IncludeTables         []string      `long:"include-table" value-name:"<pattern>" description:"Only migrate tables whose <schema>.<table> name matches this glob pattern. May be repeated"`
+		ExcludeTables         []string      `long:"exclude-table" value-name:"<pattern>" description:"Do not migrate tables whose <schema>.<table> name matches this glob pattern. May be repeated"`
+		Parallel              int           `long:"parallel" value-name:"<workers>" description:"Copy this many tables concurrently from a consistent snapshot. With --engine=exec, writes to the source are blocked while its tables are copied"`
+		Engine                string        `long:"engine" value-name:"<go|exec>" choice:"go" choice:"exec" description:"Copy data over SQL connections (go, the default), or by piping mysqldump into mysql (exec)"`
+		Definer               string        `long:"definer" value-name:"<invoker|user@host>" description:"Convert views to SQL SECURITY INVOKER and make stored programs owned by the recipient's binding user (invoker, the default), or map every DEFINER to this account"`
+		MaskingRules          string        `long:"masking-rules" value-name:"<file>" description:"Mask the values of columns while copying them, according to the rules in this YAML file"`
+		ConvertCharset        string        `long:"convert-charset" value-name:"<charset>" description:"Convert the schemas, tables and columns copied to this character set, such as utf8mb4, and compare the text of a sample of rows afterwards"`
+		Collation             string        `long:"collation" value-name:"<collation>" description:"Collation of the converted character set, such as utf8mb4_0900_ai_ci. Defaults to the default collation of the character set on the recipient"`
+		MaxRowsPerSecond      int64         `long:"max-rows-per-second" value-name:"<rows>" description:"Copy at most this many rows per second. Rows are estimated from the dump with --engine=exec"`
+		MaxBandwidth          string        `long:"max-bandwidth" value-name:"<bytes-per-second>" description:"Read at most this many bytes per second from the source, such as 10M"`
+		MaxThreadsRunning     int           `long:"max-threads-running" value-name:"<threads>" description:"Pause the copy while the source has more Threads_running than this, including the threads of the copy itself"`
+		MaxReplicationLag     time.Duration `long:"max-replication-lag" value-name:"<duration>" description:"Pause the copy while the source, when it is a replica, lags further behind than this, such as 30s"`
+		AppMemory             string        `long:"app-memory" value-name:"<size>" description:"Memory of the migration app and task, such as 2G. Defaults to the default memory of the foundation"`
+		AppDisk               string        `long:"app-disk" value-name:"<size>" description:"Disk of the migration app and task, such as 10G. Defaults to the default disk of the foundation"`
+		Stack                 string        `long:"stack" value-name:"<stack>" description:"Stack to push the migration app with, such as cflinuxfs4"`
+		IsolationSegment      string        `long:"require-isolation-segment" value-name:"<segment>" description:"Fail unless the targeted space, where the migration app runs, uses this isolation segment. The app is not placed in it"`
+		Online                bool          `long:"online" description:"Keep applying changes made to the source after copying it, and cut over once confirmed"`
+		Report                string        `long:"report" value-name:"<file>" description:"Write a report of the migration to this file, as YAML when it ends in .yml or .yaml and as JSON otherwise, even when the migration fails"`
+		TaskTimeout           time.Duration `long:"task-timeout" value-name:"<duration>" description:"Cancel the migration task when it has not completed after this long, such as 2h. Waits indefinitely by default"`
+		ProvisionTimeout      time.Duration `long:"provision-timeout" value-name:"<duration>" description:"Stop waiting for the new service instance to be created after this long, such as 30m. Waits indefinitely by default"`
+		Verify                string        `long:"verify" optional:"yes" optional-value:"checksum" choice:"rows" choice:"checksum" description:"Verify the migrated data by comparing the row count, and by default the checksum, of every table"`
+		Fingerprint           bool          `long:"fingerprint" description:"Checksum every table of the new service instance once migrated, so that migrate-rollback can tell whether it was written to since"`
 	}
 
 	parser := flags.NewParser(&opts, flags.None)
 	parser.Name = "cf mysql-tools migrate"
-	args, err := parser.ParseArgs(args)
+	args, err = parser.ParseArgs(args)
+	if err == nil && len(args) == 0 {
+		switch {
+		case opts.Resume && opts.Args.PlanName != "":
+			err = errors.New("a plan can not be specified when resuming a migration")
+		case opts.TaskTimeout < 0 || opts.ProvisionTimeout < 0:
+			err = errors.New("--task-timeout and --provision-timeout must not be negative")
+		case opts.MaxRowsPerSecond < 0 || opts.MaxThreadsRunning < 0 || opts.MaxReplicationLag < 0:
+			err = errors.New("--max-rows-per-second, --max-threads-running and --max-replication-lag must not be negative")
+		case opts.DryRun && opts.Report != "":
+			err = errors.New("--report can not be combined with --dry-run")
+		case opts.Resume && opts.DryRun:
+			err = errors.New("--dry-run can not be combined with --resume")
+		case opts.Resume && opts.Recipient != "":
+			err = errors.New("--recipient can not be combined with --resume")
+		case opts.Recipient != "" && opts.Args.PlanName != "":
+			err = errors.New("a plan can not be specified when migrating into an existing service instance")
+		case opts.Recipient != "" && opts.Recipient == opts.Args.Source:
+			err = errors.New("the recipient service instance must differ from the source service instance")
+		case (opts.RecipientParams != "" || opts.RecipientTags != "") && opts.Resume:
+			err = errors.New("--recipient-params and --recipient-tags can not be changed when resuming a migration")
+		case (opts.RecipientParams != "" || opts.RecipientTags != "") && opts.Recipient != "":
+			err = errors.New("--recipient-params and --recipient-tags can not be combined with --recipient")
+		case (opts.Rebind || opts.Restage) && opts.Resume:
+			err = errors.New("--rebind and --restage can not be changed when resuming a migration")
+		case opts.Resume && (len(opts.IncludeSchemas) > 0 || len(opts.ExcludeSchemas) > 0 || len(opts.IncludeTables) > 0 || len(opts.ExcludeTables) > 0):
+			err = errors.New("schema and table filters can not be changed when resuming a migration")
+		case opts.Resume && opts.Parallel != 0:
+			err = errors.New("--parallel can not be changed when resuming a migration")
+		case opts.Resume && opts.Engine != "":
+			err = errors.New("--engine can not be changed when resuming a migration")
+		case opts.Resume && opts.Definer != "":
+			err = errors.New("--definer can not be changed when resuming a migration")
+		case opts.Resume && (opts.AppMemory != "" || opts.AppDisk != "" || opts.Stack != "" || opts.IsolationSegment != ""):
+			err = errors.New("--app-memory, --app-disk, --stack and --require-isolation-segment can not be changed when resuming a migration")
+		case opts.Resume && opts.Online:
+			err = errors.New("--online can not be changed when resuming a migration")
+		case opts.Resume && opts.MaskingRules != "":
+			err = errors.New("--masking-rules can not be changed when resuming a migration")
+		case opts.MaskingRules != "" && (opts.Online || opts.Engine == "exec" || opts.Verify == "checksum"):
+			err = errors.New("--masking-rules can not be combined with --online, --engine=exec or --verify=checksum")
+		case opts.Resume && opts.ConvertCharset != "":
+			err = errors.New("--convert-charset can not be changed when resuming a migration")
+		case opts.Collation != "" && opts.ConvertCharset == "":
+			err = errors.New("--collation can only be used together with --convert-charset")
+		case opts.ConvertCharset != "" && (opts.Online || opts.Engine == "exec" || opts.Verify == "checksum"):
+			err = errors.New("--convert-charset can not be combined with --online, --engine=exec or --verify=checksum")
+		case opts.Online && (len(opts.IncludeSchemas) > 0 || len(opts.ExcludeSchemas) > 0 || len(opts.IncludeTables) > 0 || len(opts.ExcludeTables) > 0):
+			err = errors.New("--online can not be combined with schema or table filters")
+		case opts.Parallel < 1 && parser.FindOptionByLongName("parallel").IsSet():
+			err = errors.New("--parallel must be at least 1")
+		case opts.Restage && !opts.Rebind:
+			err = errors.New("--restage can only be used together with --rebind")
+		case !opts.Resume && opts.Recipient == "" && opts.ForceOverwrite:
+			err = errors.New("--force-overwrite can only be used together with --recipient")
+		case !opts.Resume && opts.Recipient == "" && opts.Args.PlanName == "":
+			err = errors.New("the required argument `<p.mysql-plan-type>` was not provided")
+		}
+	}
 	if err != nil || len(args) != 0 {
 		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
 		if err != nil {
@@ -45,60 +160,428 @@ func Migrate(args []string, migrator Migrator) error {
 		return fmt.Errorf("Usage: %s\n\n%s", migrateUsage, msg)
 	}
 	donorInstanceName := opts.Args.Source
-	tempRecipientInstanceName := donorInstanceName + "-new"
-	destPlan := opts.Args.PlanName
-	cleanup := !opts.NoCleanup
-	skipTLSValidation := opts.SkipTLSValidation
+
+	// Invalid parameters are rejected before anything gets provisioned
+	recipientConfig := migrate.ServiceInstanceConfig{Tags: migrate.ParseTags(opts.RecipientTags)}
+	if opts.RecipientParams != "" {
+		if recipientConfig.Parameters, err = migrate.ParseServiceParameters(opts.RecipientParams); err != nil {
+			return fmt.Errorf("invalid --recipient-params: %w", err)
+		}
+	}
+
+	if opts.Definer != "" {
+		if _, err := definer.ParsePolicy(opts.Definer); err != nil {
+			return fmt.Errorf("invalid --definer: %w", err)
+		}
+	}
+
+	var maxBandwidth int64
+	if opts.MaxBandwidth != "" {
+		if maxBandwidth, err = migrate.ParseBandwidth(opts.MaxBandwidth); err != nil {
+			return fmt.Errorf("invalid --max-bandwidth: %w", err)
+		}
+	}
+
+	appSettings := app.Settings{Stack: opts.Stack}
+	if opts.AppMemory != "" {
+		if appSettings.MemoryMB, err = migrate.ParseMegabytes(opts.AppMemory); err != nil {
+			return fmt.Errorf("invalid --app-memory: %w", err)
+		}
+	}
+	if opts.AppDisk != "" {
+		if appSettings.DiskMB, err = migrate.ParseMegabytes(opts.AppDisk); err != nil {
+			return fmt.Errorf("invalid --app-disk: %w", err)
+		}
+	}
+
+	maskingRules, err := readMaskingRules(opts.MaskingRules)
+	if err != nil {
+		return err
+	}
+
+	filter := discovery.Filter{
+		IncludeSchemas: opts.IncludeSchemas,
+		ExcludeSchemas: opts.ExcludeSchemas,
+		IncludeTables:  opts.IncludeTables,
+		ExcludeTables:  opts.ExcludeTables,
+	}
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("invalid schema or table filter: %w", err)
+	}
+
+	recipientInstanceName := donorInstanceName + "-new"
+	if opts.Recipient != "" {
+		recipientInstanceName = opts.Recipient
+	}
+
+	newMigrationOptions := migrate.MigrateOptions{
+		DonorInstanceName:     donorInstanceName,
+		RecipientInstanceName: recipientInstanceName,
+		Cleanup:               !opts.NoCleanup,
+		SkipTLSValidation:     opts.SkipTLSValidation,
+		IncludeStoredPrograms: opts.IncludeStoredPrograms,
+		Verify:                opts.Verify,
+		Fingerprint:           opts.Fingerprint,
+		ExistingRecipient:     opts.Recipient != "",
+		ForceOverwrite:        opts.ForceOverwrite,
+		Rebind:                opts.Rebind,
+		Restage:               opts.Restage,
+		Filter:                filter,
+		Parallelism:           opts.Parallel,
+		Online:                opts.Online,
+		Engine:                opts.Engine,
+		Definer:               opts.Definer,
+		MaskingRules:          maskingRules,
+		ConvertCharset:        opts.ConvertCharset,
+		Collation:             opts.Collation,
+		MaxRowsPerSecond:      opts.MaxRowsPerSecond,
+		MaxBandwidth:          maxBandwidth,
+		MaxThreadsRunning:     opts.MaxThreadsRunning,
+		MaxReplicationLag:     opts.MaxReplicationLag,
+		App:                   appSettings,
+		IsolationSegment:      opts.IsolationSegment,
+	}
+
+	if opts.DryRun {
+		report := migrator.Preflight(newMigrationOptions, opts.Args.PlanName)
+
+		presentation.PreflightReport(os.Stdout, report)
+
+		if !report.Passed() {
+			return fmt.Errorf("preflight checks for migrating %s failed", donorInstanceName)
+		}
+
+		return nil
+	}
+
+	state := migrate.State{
+		DonorInstanceName:     donorInstanceName,
+		RecipientInstanceName: recipientInstanceName,
+		PlanName:              opts.Args.PlanName,
+		Options:               newMigrationOptions,
+	}
+
+	if opts.Report != "" {
+		migrator.BeginReport()
+		defer func() {
+			if reportErr := migrator.WriteReport(opts.Report, state, err); reportErr != nil {
+				if err != nil {
+					log.Printf("Warning: %s", reportErr)
+					return
+				}
+				err = reportErr
+			}
+		}()
+	}
 
 	if err := migrator.CheckServiceExists(donorInstanceName); err != nil {
 		return err
 	}
 
-	log.Printf("Warning: The mysql-tools migrate command will not migrate any triggers, routines or events.")
-	productName := os.Getenv("RECIPIENT_PRODUCT_NAME")
-	if productName == "" {
-		productName = "p.mysql"
+	// Interrupting the plugin cancels the task it waits for, then cleans up as after any other failure
+	ctx, stop := interruptible()
+	defer stop()
+
+	if opts.Resume {
+		loaded, err := migrator.LoadState(donorInstanceName)
+		if err != nil {
+			return fmt.Errorf("unable to resume migration of %s: %w", donorInstanceName, err)
+		}
+		state = loaded
+		log.Printf("Resuming migration of %s to %s after phase %q", donorInstanceName, state.RecipientInstanceName, state.Phase)
+
+		if opts.ForceOverwrite {
+			state.Options.ForceOverwrite = true
+		}
+
+		// The copy can be slowed down or sped up when resuming it, such as after it put too much load on the source
+		if parser.FindOptionByLongName("max-rows-per-second").IsSet() {
+			state.Options.MaxRowsPerSecond = opts.MaxRowsPerSecond
+		}
+		if opts.MaxBandwidth != "" {
+			state.Options.MaxBandwidth = maxBandwidth
+		}
+		if parser.FindOptionByLongName("max-threads-running").IsSet() {
+			state.Options.MaxThreadsRunning = opts.MaxThreadsRunning
+		}
+		if parser.FindOptionByLongName("max-replication-lag").IsSet() {
+			state.Options.MaxReplicationLag = opts.MaxReplicationLag
+		}
+	} else {
+		previous, err := migrator.LoadState(donorInstanceName)
+		switch {
+		case err == nil && previous.Phase == migrate.PhaseNotStarted:
+			return fmt.Errorf("a previous migration of %s stopped while creating service instance %s. "+
+				"Run 'cf mysql-tools migrate --resume %s' to continue it",
+				donorInstanceName, previous.RecipientInstanceName, donorInstanceName)
+		case err == nil:
+			return fmt.Errorf("a previous migration of %s to %s stopped after phase %q. "+
+				"Run 'cf mysql-tools migrate --resume %s' to continue it",
+				donorInstanceName, previous.RecipientInstanceName, previous.Phase, donorInstanceName)
+		case !errors.Is(err, migrate.ErrNoMigrationState):
+			return fmt.Errorf("failed to load migration state: %w", err)
+		}
+
+		if newMigrationOptions.ExistingRecipient {
+			if err := migrator.CheckServiceExists(recipientInstanceName); err != nil {
+				return err
+			}
+		}
+
+		state.RecipientConfig = recipientConfig
+	}
+
+	tempRecipientInstanceName := state.RecipientInstanceName
+	destPlan := state.PlanName
+	migrationOptions := state.Options
+	cleanup := migrationOptions.Cleanup
+
+	if !migrationOptions.IncludeStoredPrograms {
+		log.Printf("Warning: The mysql-tools migrate command will not migrate any triggers, routines or events. Pass --include-stored-programs to migrate them.")
+	}
+
+	// The migration app is checked before a service instance gets provisioned for it
+	if !state.Reached(migrate.PhaseAppPushed) {
+		if err := migrator.CheckAppSettings(migrationOptions); err != nil {
+			return fmt.Errorf("the migration app can not be pushed: %w", err)
+		}
 	}
 
-	log.Printf("Creating new service instance %q for service %s using plan %s", tempRecipientInstanceName, productName, destPlan)
-	if err := migrator.CreateServiceInstance(destPlan, tempRecipientInstanceName); err != nil {
-		if cleanup {
-			_ = migrator.CleanupOnError(tempRecipientInstanceName)
-			return fmt.Errorf("error creating service instance: %v. Attempting to clean up service %s",
+	if !state.Reached(migrate.PhaseRecipientCreated) {
+		if !migrationOptions.ExistingRecipient {
+			// The recipient is recorded before it is created, so that a migration stopped while creating it can be
+			// resumed
+			if err := migrator.SaveState(state); err != nil {
+				log.Printf("Warning: failed to save migration state: %s", err)
+			}
+
+			provisionCtx, cancel := withTimeout(ctx, opts.ProvisionTimeout, "--provision-timeout")
+			if opts.Resume && migrator.CheckServiceExists(tempRecipientInstanceName) == nil {
+				log.Printf("Waiting for service instance %q created by the previous attempt", tempRecipientInstanceName)
+				err = migrator.WaitForServiceInstance(provisionCtx, tempRecipientInstanceName)
+			} else {
+				productName := migrate.RecipientProductName()
+				log.Printf("Creating new service instance %q for service %s using plan %s", tempRecipientInstanceName, productName, destPlan)
+				err = migrator.CreateServiceInstance(provisionCtx, destPlan, tempRecipientInstanceName, state.RecipientConfig)
+			}
+			stoppedWaiting := provisionCtx.Err() != nil
+			cancel()
+			if err != nil {
+				// The creation carries on after the plugin stopped waiting for it, and deleting the service instance
+				// would fail until it completes
+				if stoppedWaiting {
+					return fmt.Errorf("error creating service instance: %v. Service instance %s was left behind and may still be being created. "+
+						"Run 'cf mysql-tools migrate --resume %s' to wait for it and continue, or 'cf delete-service %s' once it was created",
+						err,
+						tempRecipientInstanceName,
+						donorInstanceName,
+						tempRecipientInstanceName,
+					)
+				}
+
+				if cleanup {
+					_ = migrator.CleanupOnError(tempRecipientInstanceName)
+					_ = migrator.RemoveState(donorInstanceName)
+					return fmt.Errorf("error creating service instance: %v. Attempting to clean up service %s",
+						err,
+						tempRecipientInstanceName,
+					)
+				}
+
+				return fmt.Errorf("error creating service instance: %v. Not cleaning up service %s. "+
+					"Run 'cf mysql-tools migrate --resume %s' to retry",
+					err,
+					tempRecipientInstanceName,
+					donorInstanceName,
+				)
+			}
+		}
+
+		state.Phase = migrate.PhaseRecipientCreated
+		if err := migrator.SaveState(state); err != nil {
+			log.Printf("Warning: failed to save migration state: %s", err)
+		}
+	}
+
+	if !state.Reached(migrate.PhaseDataMigrated) {
+		taskCtx, cancel := withTimeout(ctx, opts.TaskTimeout, "--task-timeout")
+		err := migrator.MigrateData(taskCtx, migrationOptions)
+		cancel()
+		if errors.Is(err, migrate.ErrTaskResultUnavailable) {
+			return fmt.Errorf("error migrating data: %w. Not cleaning up service %s. "+
+				"Run 'cf mysql-tools migrate --resume %s' to retrieve the logs of the migration task again",
 				err,
 				tempRecipientInstanceName,
+				donorInstanceName,
 			)
 		}
+		if err != nil {
+			// A service instance the migration did not create is never deleted
+			if cleanup && migrationOptions.ExistingRecipient {
+				_ = migrator.RemoveState(donorInstanceName)
 
-		return fmt.Errorf("error creating service instance: %v. Not cleaning up service %s",
-			err,
-			tempRecipientInstanceName,
-		)
-	}
+				return fmt.Errorf("error migrating data: %w. Not deleting existing service %s, which may contain partially migrated data",
+					err,
+					tempRecipientInstanceName,
+				)
+			}
 
-	migrationOptions := migrate.MigrateOptions{
-		DonorInstanceName:     donorInstanceName,
-		RecipientInstanceName: tempRecipientInstanceName,
-		Cleanup:               cleanup,
-		SkipTLSValidation:     skipTLSValidation,
+			if cleanup {
+				_ = migrator.CleanupOnError(tempRecipientInstanceName)
+				_ = migrator.RemoveState(donorInstanceName)
+
+				return fmt.Errorf(
+					"error migrating data: %w. Attempting to clean up service %s",
+					err,
+					tempRecipientInstanceName,
+				)
+			}
+
+			return fmt.Errorf("error migrating data: %v. Not cleaning up service %s. "+
+				"Run 'cf mysql-tools migrate --resume %s' to retry",
+				err,
+				tempRecipientInstanceName,
+				donorInstanceName,
+			)
+		}
 	}
 
-	if err := migrator.MigrateData(migrationOptions); err != nil {
-		if cleanup {
-			_ = migrator.CleanupOnError(tempRecipientInstanceName)
+	if migrationOptions.Online && !state.Reached(migrate.PhaseCutOver) {
+		// MigrateData records the binlog position the recipient caught up to
+		loaded, err := migrator.LoadState(donorInstanceName)
+		if err != nil {
+			return fmt.Errorf("failed to load migration state: %w", err)
+		}
+		state = loaded
+
+		if !migrator.ConfirmCutover(donorInstanceName) {
+			return fmt.Errorf("cutover of %s was not confirmed. "+
+				"Run 'cf mysql-tools migrate --resume %s' to cut over later",
+				donorInstanceName, donorInstanceName)
+		}
 
-			return fmt.Errorf(
-				"error migrating data: %w. Attempting to clean up service %s",
+		taskCtx, cancel := withTimeout(ctx, opts.TaskTimeout, "--task-timeout")
+		err = migrator.CutOver(taskCtx, migrationOptions)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error cutting over: %v. Not cleaning up service %s. "+
+				"Run 'cf mysql-tools migrate --resume %s' to retry",
 				err,
 				tempRecipientInstanceName,
+				donorInstanceName,
 			)
 		}
 
-		return fmt.Errorf("error migrating data: %v. Not cleaning up service %s",
-			err,
-			tempRecipientInstanceName,
-		)
+		// CutOver records the fingerprint of the recipient's data once the changes were applied
+		loaded, err = migrator.LoadState(donorInstanceName)
+		if err != nil {
+			return fmt.Errorf("failed to load migration state: %w", err)
+		}
+		state = loaded
+	}
+
+	// Bindings are recorded while the donor still has its original name
+	if migrationOptions.Rebind && !state.Reached(migrate.PhaseBindingsRecorded) {
+		bindings, err := migrator.RecordBindings(donorInstanceName)
+		if err != nil {
+			return fmt.Errorf("failed to record the bindings of %s: %w. "+
+				"Run 'cf mysql-tools migrate --resume %s' to retry",
+				donorInstanceName, err, donorInstanceName)
+		}
+
+		state.Bindings = bindings
+		state.Phase = migrate.PhaseBindingsRecorded
+		if err := migrator.SaveState(state); err != nil {
+			log.Printf("Warning: failed to save migration state: %s", err)
+		}
+	}
+
+	if !state.Reached(migrate.PhaseRenamed) {
+		if migrationOptions.ExistingRecipient {
+			log.Printf("Migrated data from %s into existing service instance %s. Service instances were not renamed", donorInstanceName, tempRecipientInstanceName)
+		} else if err := migrator.RenameServiceInstances(donorInstanceName, tempRecipientInstanceName); err != nil {
+			return err
+		}
+
+		if migrationOptions.Rebind {
+			state.Phase = migrate.PhaseRenamed
+			if err := migrator.SaveState(state); err != nil {
+				log.Printf("Warning: failed to save migration state: %s", err)
+			}
+		}
+	}
+
+	if !migrationOptions.Rebind {
+		return migrator.RemoveState(donorInstanceName)
+	}
+
+	fromInstanceName, toInstanceName := donorInstanceName+"-old", donorInstanceName
+	if migrationOptions.ExistingRecipient {
+		fromInstanceName, toInstanceName = donorInstanceName, tempRecipientInstanceName
+	}
+
+	log.Printf("Rebinding %d apps and service keys from %s to %s", len(state.Bindings), fromInstanceName, toInstanceName)
+	results := migrator.Rebind(state.Bindings, fromInstanceName, toInstanceName, migrationOptions.Restage)
+	failed := presentation.RebindReport(os.Stdout, results)
+
+	// Rebinding is not retried on resume, since bindings that were already moved must not be moved twice
+	if err := migrator.RemoveState(donorInstanceName); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to rebind %d of %d apps and service keys to %s. Rebind them manually", failed, len(results), toInstanceName)
+	}
+
+	return nil
+}
+
+// interruptible returns a context that is cancelled once the plugin is interrupted, such as by Ctrl-C. Interrupting
+// it a second time terminates the plugin.
+func interruptible() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-signals:
+			signal.Stop(signals)
+			log.Printf("Received %s, cancelling the migration", sig)
+			cancel(fmt.Errorf("interrupted by %s", sig))
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel(nil)
+	}
+}
+
+// withTimeout bounds ctx by timeout, unless it is zero, naming the flag that set it when it expires
+func withTimeout(ctx context.Context, timeout time.Duration, flag string) (context.Context, context.CancelFunc) {
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeoutCause(ctx, timeout, fmt.Errorf("timed out after %s, as set by %s", timeout, flag))
+}
+
+// readMaskingRules returns the masking rules in file once they were validated, or no rules when file is empty
+func readMaskingRules(file string) (string, error) {
+	if file == "" {
+		return "", nil
+	}
+
+	rules, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("invalid --masking-rules: %w", err)
+	}
+
+	if _, err := masking.Parse(rules); err != nil {
+		return "", fmt.Errorf("invalid --masking-rules: %w", err)
 	}
 
-	return migrator.RenameServiceInstances(donorInstanceName, tempRecipientInstanceName)
+	return string(rules), nil
 }
@@ -0,0 +1,65 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+//counterfeiter:generate -o fakes/fake_rollbacker.go . Rollbacker
+type Rollbacker interface {
+	CheckServiceExists(instanceName string) error
+	Rollback(opts migrate.RollbackOptions) error
+}
+
+func MigrateRollback(args []string, rollbacker Rollbacker) error {
+	const (
+		migrateRollbackUsage = `cf mysql-tools migrate-rollback [-h] [--no-cleanup] [--skip-tls-validation] [--force] <service-instance>`
+	)
+
+	var opts struct {
+		Args struct {
+			InstanceName string `positional-arg-name:"<service-instance>"`
+		} `positional-args:"yes" required:"yes"`
+		NoCleanup         bool `long:"no-cleanup" description:"don't clean up the migration app after checking whether the service instance was written to"`
+		SkipTLSValidation bool `long:"skip-tls-validation" short:"k" description:"Skip certificate validation of the MySQL server certificate. Not recommended!"`
+		Force             bool `long:"force" short:"f" description:"Roll back even when the migrated service instance was written to since the cutover, losing those writes"`
+	}
+
+	parser := flags.NewParser(&opts, flags.None)
+	parser.Name = "cf mysql-tools migrate-rollback"
+	args, err := parser.ParseArgs(args)
+	if err != nil || len(args) != 0 {
+		msg := fmt.Sprintf("unexpected arguments: %s", strings.Join(args, " "))
+		if err != nil {
+			msg = err.Error()
+		}
+		return fmt.Errorf("Usage: %s\n\n%s", migrateRollbackUsage, msg)
+	}
+
+	if err := rollbacker.CheckServiceExists(opts.Args.InstanceName); err != nil {
+		return err
+	}
+
+	return rollbacker.Rollback(migrate.RollbackOptions{
+		InstanceName:      opts.Args.InstanceName,
+		SkipTLSValidation: opts.SkipTLSValidation,
+		Cleanup:           !opts.NoCleanup,
+		Force:             opts.Force,
+	})
+}
@@ -0,0 +1,73 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+)
+
+var _ = Describe("MigrateRollback", func() {
+	var fakeRollbacker *fakes.FakeRollbacker
+
+	const (
+		migrateRollbackUsage = `cf mysql-tools migrate-rollback [-h] [--no-cleanup] [--skip-tls-validation] [--force] <service-instance>`
+	)
+
+	BeforeEach(func() {
+		fakeRollbacker = new(fakes.FakeRollbacker)
+	})
+
+	It("rolls back the migration to a service instance", func() {
+		Expect(commands.MigrateRollback([]string{"some-instance"}, fakeRollbacker)).To(Succeed())
+
+		Expect(fakeRollbacker.CheckServiceExistsCallCount()).To(Equal(1))
+		Expect(fakeRollbacker.CheckServiceExistsArgsForCall(0)).To(Equal("some-instance"))
+
+		Expect(fakeRollbacker.RollbackCallCount()).To(Equal(1))
+		Expect(fakeRollbacker.RollbackArgsForCall(0)).To(Equal(migrate.RollbackOptions{
+			InstanceName: "some-instance",
+			Cleanup:      true,
+		}))
+	})
+
+	It("passes the --force, --skip-tls-validation and --no-cleanup options", func() {
+		Expect(commands.MigrateRollback([]string{"--force", "-k", "--no-cleanup", "some-instance"}, fakeRollbacker)).To(Succeed())
+
+		Expect(fakeRollbacker.RollbackArgsForCall(0)).To(Equal(migrate.RollbackOptions{
+			InstanceName:      "some-instance",
+			SkipTLSValidation: true,
+			Force:             true,
+		}))
+	})
+
+	It("requires a service instance", func() {
+		err := commands.MigrateRollback(nil, fakeRollbacker)
+		Expect(err).To(MatchError("Usage: " + migrateRollbackUsage + "\n\nthe required argument `<service-instance>` was not provided"))
+		Expect(fakeRollbacker.RollbackCallCount()).To(BeZero())
+	})
+
+	It("rejects extra arguments", func() {
+		err := commands.MigrateRollback([]string{"some-instance", "extra"}, fakeRollbacker)
+		Expect(err).To(MatchError("Usage: " + migrateRollbackUsage + "\n\nunexpected arguments: extra"))
+	})
+
+	It("does not roll back a service instance that does not exist", func() {
+		fakeRollbacker.CheckServiceExistsReturns(errors.New("Service instance some-instance not found"))
+
+		err := commands.MigrateRollback([]string{"some-instance"}, fakeRollbacker)
+		Expect(err).To(MatchError("Service instance some-instance not found"))
+		Expect(fakeRollbacker.RollbackCallCount()).To(BeZero())
+	})
+
+	It("returns the error of a failed rollback", func() {
+		fakeRollbacker.RollbackReturns(errors.New("some-instance was written to since the cutover"))
+
+		err := commands.MigrateRollback([]string{"some-instance"}, fakeRollbacker)
+		Expect(err).To(MatchError("some-instance was written to since the cutover"))
+	})
+})
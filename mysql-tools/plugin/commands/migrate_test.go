@@ -2,15 +2,23 @@ package commands_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log"
+	"os"
+	"path/filepath"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/plugin/commands/fakes"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
 )
 
 var _ = Describe("Migrate", func() {
@@ -20,11 +28,14 @@ var _ = Describe("Migrate", func() {
 	)
 
 	const (
-		migrateUsage = `cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] <source-service-instance> <p.mysql-plan-type>`
+		migrateUsage = `cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--verify[=rows|checksum]] [--fingerprint] [--{include,exclude}-{schema,table} <pattern>]... [--parallel <workers>] [--engine <go|exec>] [--definer <invoker|user@host>] [--masking-rules <file>] [--convert-charset <charset> [--collation <collation>]] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--app-memory <size>] [--app-disk <size>] [--stack <stack>] [--require-isolation-segment <segment>] [--online] [--dry-run] [--rebind [--restage]] [--report <file>] [--task-timeout <duration>] [--provision-timeout <duration>] [--recipient-params <json|file>] [--recipient-tags <tags>] <source-service-instance> <p.mysql-plan-type>
+       cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--verify[=rows|checksum]] [--fingerprint] [--{include,exclude}-{schema,table} <pattern>]... [--parallel <workers>] [--engine <go|exec>] [--definer <invoker|user@host>] [--masking-rules <file>] [--convert-charset <charset> [--collation <collation>]] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--app-memory <size>] [--app-disk <size>] [--stack <stack>] [--require-isolation-segment <segment>] [--online] [--dry-run] [--rebind [--restage]] [--report <file>] [--task-timeout <duration>] [--force-overwrite] --recipient <recipient-service-instance> <source-service-instance>
+       cf mysql-tools migrate --resume [--force-overwrite] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--report <file>] [--task-timeout <duration>] [--provision-timeout <duration>] <source-service-instance>`
 	)
 
 	BeforeEach(func() {
 		fakeMigrator = new(fakes.FakeMigrator)
+		fakeMigrator.LoadStateReturns(migrate.State{}, migrate.ErrNoMigrationState)
 		logOutput = &bytes.Buffer{}
 		w := io.MultiWriter(GinkgoWriter, logOutput)
 		log.SetOutput(w)
@@ -51,15 +62,16 @@ var _ = Describe("Migrate", func() {
 			Expect(fakeMigrator.CreateServiceInstanceCallCount()).
 				To(Equal(1))
 
-			createdServicePlan, createdServiceInstanceName := fakeMigrator.CreateServiceInstanceArgsForCall(0)
+			_, createdServicePlan, createdServiceInstanceName, createdConfig := fakeMigrator.CreateServiceInstanceArgsForCall(0)
 			Expect(createdServicePlan).To(Equal("some-plan"))
 			Expect(createdServiceInstanceName).
 				To(Equal("some-donor-new"))
+			Expect(createdConfig).To(Equal(migrate.ServiceInstanceConfig{}))
 		})
 
 		By("migrating data from the donor to the recipient", func() {
 			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(1))
-			opts := fakeMigrator.MigrateDataArgsForCall(0)
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
 			Expect(opts.DonorInstanceName).To(Equal("some-donor"))
 			Expect(opts.RecipientInstanceName).To(Equal("some-donor-new"))
 			Expect(opts.Cleanup).To(BeTrue())
@@ -74,9 +86,173 @@ var _ = Describe("Migrate", func() {
 			Expect(recipientName).To(Equal("some-donor-new"))
 		})
 
+		By("recording the recipient before and after creating it", func() {
+			Expect(fakeMigrator.SaveStateCallCount()).To(Equal(2))
+			state := fakeMigrator.SaveStateArgsForCall(0)
+			Expect(state.Phase).To(Equal(migrate.PhaseNotStarted))
+			Expect(state.DonorInstanceName).To(Equal("some-donor"))
+			Expect(state.RecipientInstanceName).To(Equal("some-donor-new"))
+			Expect(state.PlanName).To(Equal("some-plan"))
+			Expect(fakeMigrator.SaveStateArgsForCall(1).Phase).To(Equal(migrate.PhaseRecipientCreated))
+		})
+
+		By("removing the migration state once the migration is complete", func() {
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RemoveStateArgsForCall(0)).To(Equal("some-donor"))
+		})
+
 		Expect(fakeMigrator.CleanupOnErrorCallCount()).To(BeZero())
 	})
 
+	Context("when a previous migration of the donor was interrupted", func() {
+		BeforeEach(func() {
+			fakeMigrator.LoadStateReturns(migrate.State{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Phase:                 migrate.PhaseAppPushed,
+			}, nil)
+		})
+
+		It("refuses to start a new migration", func() {
+			err := commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(`a previous migration of some-donor to some-donor-new stopped after phase "app-pushed". ` +
+				`Run 'cf mysql-tools migrate --resume some-donor' to continue it`))
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+		})
+
+		It("says so when it stopped while creating the recipient", func() {
+			fakeMigrator.LoadStateReturns(migrate.State{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Phase:                 migrate.PhaseNotStarted,
+			}, nil)
+
+			err := commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("a previous migration of some-donor stopped while creating service instance some-donor-new. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to continue it"))
+		})
+	})
+
+	It("returns an error when the state of previous migrations can not be loaded", func() {
+		fakeMigrator.LoadStateReturns(migrate.State{}, errors.New("permission denied"))
+
+		err := commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)
+		Expect(err).To(MatchError("failed to load migration state: permission denied"))
+		Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+	})
+
+	Context("when resuming a migration", func() {
+		var state migrate.State
+
+		BeforeEach(func() {
+			state = migrate.State{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				PlanName:              "some-plan",
+				Phase:                 migrate.PhaseTaskStarted,
+				AppName:               "migrate-app-some-guid",
+				TaskGUID:              "some-task-guid",
+				Options: migrate.MigrateOptions{
+					DonorInstanceName:     "some-donor",
+					RecipientInstanceName: "some-donor-new",
+					SkipTLSValidation:     true,
+				},
+			}
+			fakeMigrator.LoadStateReturns(state, nil)
+		})
+
+		It("continues from the last completed phase with the original options", func() {
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.LoadStateArgsForCall(0)).To(Equal("some-donor"))
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(1))
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0))).To(Equal(state.Options))
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+		})
+
+		It("allows overwriting an existing recipient that is no longer empty", func() {
+			state.Options.ExistingRecipient = true
+			fakeMigrator.LoadStateReturns(state, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "--force-overwrite", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).ForceOverwrite).To(BeTrue())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(BeZero())
+		})
+
+		It("changes the throttling limits that are given, and keeps the others", func() {
+			state.Options.MaxRowsPerSecond = 5000
+			state.Options.MaxThreadsRunning = 32
+			fakeMigrator.LoadStateReturns(state, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "--max-rows-per-second", "0", "--max-bandwidth", "1M", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			opts := migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0))
+			Expect(opts.MaxRowsPerSecond).To(BeZero())
+			Expect(opts.MaxBandwidth).To(Equal(int64(1024 * 1024)))
+			Expect(opts.MaxThreadsRunning).To(Equal(32))
+		})
+
+		It("creates the recipient if the previous migration did not", func() {
+			state.Phase = migrate.PhaseNotStarted
+			state.RecipientConfig = migrate.ServiceInstanceConfig{Tags: []string{"some-tag"}}
+			fakeMigrator.LoadStateReturns(state, nil)
+			fakeMigrator.CheckServiceExistsStub = func(name string) error {
+				if name == "some-donor-new" {
+					return errors.New("Service instance some-donor-new not found")
+				}
+				return nil
+			}
+
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.WaitForServiceInstanceCallCount()).To(BeZero())
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(Equal(1))
+			_, plan, name, config := fakeMigrator.CreateServiceInstanceArgsForCall(0)
+			Expect(plan).To(Equal("some-plan"))
+			Expect(name).To(Equal("some-donor-new"))
+			Expect(config).To(Equal(state.RecipientConfig))
+		})
+
+		It("waits for the recipient the previous migration started creating", func() {
+			state.Phase = migrate.PhaseNotStarted
+			fakeMigrator.LoadStateReturns(state, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+			Expect(fakeMigrator.WaitForServiceInstanceCallCount()).To(Equal(1))
+			_, name := fakeMigrator.WaitForServiceInstanceArgsForCall(0)
+			Expect(name).To(Equal("some-donor-new"))
+			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(1))
+		})
+
+		It("only renames the service instances if the data was already migrated", func() {
+			state.Phase = migrate.PhaseDataMigrated
+			fakeMigrator.LoadStateReturns(state, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.MigrateDataCallCount()).To(BeZero())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when there is no migration to resume", func() {
+			fakeMigrator.LoadStateReturns(migrate.State{}, migrate.ErrNoMigrationState)
+
+			err := commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("unable to resume migration of some-donor: no migration in progress"))
+			Expect(fakeMigrator.MigrateDataCallCount()).To(BeZero())
+		})
+
+		It("returns an error when a plan is specified", func() {
+			err := commands.Migrate([]string{"--resume", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\na plan can not be specified when resuming a migration"))
+		})
+	})
+
 	Context("when skip-tls-validation is specified", func() {
 		It("Requests that the data be migrated insecurely", func() {
 			args := []string{
@@ -85,13 +261,741 @@ var _ = Describe("Migrate", func() {
 			}
 			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
 
-			opts := fakeMigrator.MigrateDataArgsForCall(0)
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
 			Expect(opts.SkipTLSValidation).To(
 				BeTrue(),
 				`Expected MigrateOptions to have SkipTLSValidation set to true, but it was false`)
 		})
 	})
 
+	Context("when include-stored-programs is specified", func() {
+		It("requests that routines, triggers and events be migrated", func() {
+			args := []string{
+				"--include-stored-programs",
+				"some-donor", "some-plan",
+			}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
+			Expect(opts.IncludeStoredPrograms).To(BeTrue())
+		})
+
+		It("does not warn that triggers, routines and events will be skipped", func() {
+			args := []string{
+				"--include-stored-programs",
+				"some-donor", "some-plan",
+			}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			Expect(logOutput.String()).NotTo(ContainSubstring(`will not migrate any triggers, routines or events`))
+		})
+	})
+
+	It("fingerprints the migrated data when --fingerprint is specified", func() {
+		args := []string{"--fingerprint", "some-donor", "some-plan"}
+		Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+		_, opts := fakeMigrator.MigrateDataArgsForCall(0)
+		Expect(opts.Fingerprint).To(BeTrue())
+	})
+
+	Context("when verify is specified", func() {
+		It("verifies checksums by default", func() {
+			args := []string{"--verify", "some-donor", "some-plan"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
+			Expect(opts.Verify).To(Equal("checksum"))
+		})
+
+		It("accepts a verification mode", func() {
+			args := []string{"--verify=rows", "some-donor", "some-plan"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
+			Expect(opts.Verify).To(Equal("rows"))
+		})
+
+		It("rejects an unknown verification mode", func() {
+			args := []string{"--verify=md5", "some-donor", "some-plan"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("Usage: " + migrateUsage)))
+			Expect(fakeMigrator.MigrateDataCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when recipient parameters and tags are specified", func() {
+		It("creates the recipient with them", func() {
+			args := []string{
+				"--recipient-params", `{"enable_lower_case_table_names": true}`,
+				"--recipient-tags", "some-tag,other-tag",
+				"some-donor", "some-plan",
+			}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			_, _, _, config := fakeMigrator.CreateServiceInstanceArgsForCall(0)
+			Expect(config).To(Equal(migrate.ServiceInstanceConfig{
+				Parameters: `{"enable_lower_case_table_names":true}`,
+				Tags:       []string{"some-tag", "other-tag"},
+			}))
+			Expect(fakeMigrator.SaveStateArgsForCall(0).RecipientConfig).To(Equal(config))
+		})
+
+		It("rejects invalid parameters before provisioning anything", func() {
+			args := []string{"--recipient-params", `{"service-tier":`, "some-donor", "some-plan"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("invalid --recipient-params: service parameters must be a JSON object")))
+
+			Expect(fakeMigrator.CheckServiceExistsCallCount()).To(BeZero())
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+		})
+
+		It("can not be combined with an existing recipient", func() {
+			args := []string{"--recipient-tags", "some-tag", "--recipient", "some-recipient", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--recipient-params and --recipient-tags can not be combined with --recipient"))
+		})
+
+		It("can not be changed when resuming", func() {
+			args := []string{"--recipient-tags", "some-tag", "--resume", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--recipient-params and --recipient-tags can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when a recipient is specified", func() {
+		It("migrates into the existing service instance without creating or renaming instances", func() {
+			args := []string{"--recipient", "some-recipient", "some-donor"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.CheckServiceExistsCallCount()).To(Equal(2))
+			Expect(fakeMigrator.CheckServiceExistsArgsForCall(1)).To(Equal("some-recipient"))
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(BeZero())
+
+			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(1))
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
+			Expect(opts.RecipientInstanceName).To(Equal("some-recipient"))
+			Expect(opts.ExistingRecipient).To(BeTrue())
+			Expect(opts.ForceOverwrite).To(BeFalse())
+
+			Expect(fakeMigrator.SaveStateCallCount()).To(Equal(1))
+			Expect(fakeMigrator.SaveStateArgsForCall(0).Phase).To(Equal(migrate.PhaseRecipientCreated))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+		})
+
+		It("passes on force-overwrite", func() {
+			args := []string{"--recipient", "some-recipient", "--force-overwrite", "some-donor"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).ForceOverwrite).To(BeTrue())
+		})
+
+		It("returns an error if the recipient does not exist", func() {
+			fakeMigrator.CheckServiceExistsReturnsOnCall(1, errors.New("Service instance some-recipient not found"))
+
+			args := []string{"--recipient", "some-recipient", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Service instance some-recipient not found"))
+			Expect(fakeMigrator.MigrateDataCallCount()).To(BeZero())
+		})
+
+		It("never deletes the recipient when migrating data fails", func() {
+			fakeMigrator.MigrateDataReturns(errors.New("some-error"))
+
+			args := []string{"--recipient", "some-recipient", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("error migrating data: some-error. Not deleting existing service some-recipient, which may contain partially migrated data"))
+			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(BeZero())
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+		})
+
+		It("does not accept a plan", func() {
+			args := []string{"--recipient", "some-recipient", "some-donor", "some-plan"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\na plan can not be specified when migrating into an existing service instance"))
+		})
+
+		It("does not accept the source instance as recipient", func() {
+			args := []string{"--recipient", "some-donor", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\nthe recipient service instance must differ from the source service instance"))
+		})
+
+		It("can not be combined with resume", func() {
+			args := []string{"--recipient", "some-recipient", "--resume", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--recipient can not be combined with --resume"))
+		})
+	})
+
+	It("only accepts force-overwrite together with a recipient", func() {
+		args := []string{"--force-overwrite", "some-donor", "some-plan"}
+		err := commands.Migrate(args, fakeMigrator)
+		Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--force-overwrite can only be used together with --recipient"))
+	})
+
+	Context("when parallel is specified", func() {
+		It("passes the number of workers on to the migration", func() {
+			Expect(commands.Migrate([]string{"--parallel", "4", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Parallelism).To(Equal(4))
+		})
+
+		It("requires at least one worker", func() {
+			err := commands.Migrate([]string{"--parallel", "0", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--parallel must be at least 1"))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--parallel", "4", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--parallel can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when timeouts are specified", func() {
+		It("bounds creating the recipient and the migration task by them", func() {
+			Expect(commands.Migrate([]string{"--provision-timeout", "30m", "--task-timeout", "2h", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			provisionCtx, _, _, _ := fakeMigrator.CreateServiceInstanceArgsForCall(0)
+			deadline, ok := provisionCtx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(30*time.Minute), time.Minute))
+
+			taskCtx, _ := fakeMigrator.MigrateDataArgsForCall(0)
+			deadline, ok = taskCtx.Deadline()
+			Expect(ok).To(BeTrue())
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(2*time.Hour), time.Minute))
+		})
+
+		It("waits indefinitely by default", func() {
+			Expect(commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			taskCtx, _ := fakeMigrator.MigrateDataArgsForCall(0)
+			_, ok := taskCtx.Deadline()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("cleans up after the migration task timed out", func() {
+			fakeMigrator.MigrateDataStub = func(ctx context.Context, _ migrate.MigrateOptions) error {
+				<-ctx.Done()
+				return context.Cause(ctx)
+			}
+
+			err := commands.Migrate([]string{"--task-timeout", "1ms", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("error migrating data: timed out after 1ms, as set by --task-timeout. Attempting to clean up service some-donor-new"))
+			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(Equal(1))
+		})
+
+		It("can be used when resuming", func() {
+			fakeMigrator.LoadStateReturns(migrate.State{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Phase:                 migrate.PhaseRecipientCreated,
+			}, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "--task-timeout", "2h", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			taskCtx, _ := fakeMigrator.MigrateDataArgsForCall(0)
+			_, ok := taskCtx.Deadline()
+			Expect(ok).To(BeTrue())
+		})
+
+		It("rejects negative timeouts", func() {
+			err := commands.Migrate([]string{"--task-timeout=-1h", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--task-timeout and --provision-timeout must not be negative"))
+		})
+	})
+
+	Context("when an engine is specified", func() {
+		It("passes it on to the migration", func() {
+			Expect(commands.Migrate([]string{"--engine", "exec", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Engine).To(Equal("exec"))
+		})
+
+		It("rejects an unknown engine", func() {
+			err := commands.Migrate([]string{"--engine", "rsync", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("Invalid value `rsync' for option `--engine'")))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--engine", "exec", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--engine can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when a definer is specified", func() {
+		It("passes it on to the migration", func() {
+			Expect(commands.Migrate([]string{"--definer", "app@%", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Definer).To(Equal("app@%"))
+		})
+
+		It("rejects an invalid definer before migrating", func() {
+			err := commands.Migrate([]string{"--definer", "app", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(`invalid --definer: invalid definer "app", expected "invoker" or <user>@<host>`))
+			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(0))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--definer", "invoker", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--definer can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when masking rules are specified", func() {
+		var rulesFile string
+
+		BeforeEach(func() {
+			rulesFile = filepath.Join(GinkgoT().TempDir(), "masking.yml")
+			Expect(os.WriteFile(rulesFile, []byte("tables: {sakila.customer: {email: fake_email}}"), 0600)).To(Succeed())
+		})
+
+		It("passes them on to the migration", func() {
+			Expect(commands.Migrate([]string{"--masking-rules", rulesFile, "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).MaskingRules).To(Equal("tables: {sakila.customer: {email: fake_email}}"))
+		})
+
+		It("rejects invalid rules before migrating", func() {
+			Expect(os.WriteFile(rulesFile, []byte("tables: {sakila.customer: {email: scramble}}"), 0600)).To(Succeed())
+
+			err := commands.Migrate([]string{"--masking-rules", rulesFile, "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("invalid --masking-rules: invalid masking rules: ")))
+			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(0))
+		})
+
+		It("can not be combined with online migrations", func() {
+			err := commands.Migrate([]string{"--masking-rules", rulesFile, "--online", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--masking-rules can not be combined with --online, --engine=exec or --verify=checksum"))
+		})
+	})
+
+	Context("when a character set conversion is specified", func() {
+		It("passes it on to the migration", func() {
+			Expect(commands.Migrate([]string{"--convert-charset", "utf8mb4", "--collation", "utf8mb4_0900_ai_ci", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).ConvertCharset).To(Equal("utf8mb4"))
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Collation).To(Equal("utf8mb4_0900_ai_ci"))
+		})
+
+		It("requires a character set for a collation", func() {
+			err := commands.Migrate([]string{"--collation", "utf8mb4_0900_ai_ci", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--collation can only be used together with --convert-charset"))
+		})
+
+		It("can not be combined with the exec engine", func() {
+			err := commands.Migrate([]string{"--convert-charset", "utf8mb4", "--engine", "exec", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--convert-charset can not be combined with --online, --engine=exec or --verify=checksum"))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--convert-charset", "utf8mb4", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--convert-charset can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when throttling is specified", func() {
+		It("passes the limits on to the migration", func() {
+			Expect(commands.Migrate([]string{"--max-rows-per-second", "5000", "--max-bandwidth", "10M", "--max-threads-running", "32", "--max-replication-lag", "30s", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			opts := migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0))
+			Expect(opts.MaxRowsPerSecond).To(Equal(int64(5000)))
+			Expect(opts.MaxBandwidth).To(Equal(int64(10 * 1024 * 1024)))
+			Expect(opts.MaxThreadsRunning).To(Equal(32))
+			Expect(opts.MaxReplicationLag).To(Equal(30 * time.Second))
+		})
+
+		It("rejects negative limits", func() {
+			err := commands.Migrate([]string{"--max-threads-running", "-1", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--max-rows-per-second, --max-threads-running and --max-replication-lag must not be negative"))
+		})
+
+		It("rejects an invalid bandwidth", func() {
+			err := commands.Migrate([]string{"--max-bandwidth", "fast", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(`invalid --max-bandwidth: invalid bandwidth "fast", expected bytes per second such as 1048576, 512K or 10M`))
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when settings for the migration app are specified", func() {
+		It("checks and passes them on to the migration", func() {
+			Expect(commands.Migrate([]string{"--app-memory", "2G", "--app-disk", "10240M", "--stack", "cflinuxfs4", "--require-isolation-segment", "data", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			opts := migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0))
+			Expect(opts.App).To(Equal(app.Settings{MemoryMB: 2048, DiskMB: 10240, Stack: "cflinuxfs4"}))
+			Expect(opts.IsolationSegment).To(Equal("data"))
+			Expect(fakeMigrator.CheckAppSettingsArgsForCall(0)).To(Equal(opts))
+		})
+
+		It("does not create a service instance when the migration app can not be pushed", func() {
+			fakeMigrator.CheckAppSettingsReturns(errors.New("stack windows not found"))
+
+			err := commands.Migrate([]string{"--stack", "windows", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("the migration app can not be pushed: stack windows not found"))
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+		})
+
+		It("rejects an invalid size", func() {
+			err := commands.Migrate([]string{"--app-memory", "2048", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(`invalid --app-memory: invalid size "2048", expected an integer with a unit of M, MB, G or GB`))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--app-memory", "2G", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--app-memory, --app-disk, --stack and --require-isolation-segment can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when online is specified", func() {
+		var migratedState migrate.State
+
+		BeforeEach(func() {
+			migratedState = migrate.State{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Phase:                 migrate.PhaseDataMigrated,
+				BinlogPosition:        "mysql-bin.000003:154",
+				Options: migrate.MigrateOptions{
+					DonorInstanceName:     "some-donor",
+					RecipientInstanceName: "some-donor-new",
+					Cleanup:               true,
+					Online:                true,
+				},
+			}
+			fakeMigrator.LoadStateReturnsOnCall(1, migratedState, nil)
+			cutOverState := migratedState
+			cutOverState.Phase = migrate.PhaseCutOver
+			cutOverState.RecipientFingerprint = "some-fingerprint"
+			fakeMigrator.LoadStateReturnsOnCall(2, cutOverState, nil)
+			fakeMigrator.ConfirmCutoverReturns(true)
+		})
+
+		It("cuts over once confirmed, before renaming the service instances", func() {
+			Expect(commands.Migrate([]string{"--online", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Online).To(BeTrue())
+			Expect(fakeMigrator.ConfirmCutoverArgsForCall(0)).To(Equal("some-donor"))
+			Expect(fakeMigrator.CutOverCallCount()).To(Equal(1))
+			Expect(migrateOptionsOf(fakeMigrator.CutOverArgsForCall(0)).Online).To(BeTrue())
+
+			// CutOver records the phase, so the state is loaded again afterwards
+			Expect(fakeMigrator.LoadStateCallCount()).To(Equal(3))
+
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(Equal(1))
+		})
+
+		It("keeps the fingerprint of the recipient recorded by the cutover", func() {
+			Expect(commands.Migrate([]string{"--online", "--rebind", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			lastState := fakeMigrator.SaveStateArgsForCall(fakeMigrator.SaveStateCallCount() - 1)
+			Expect(lastState.Phase).To(Equal(migrate.PhaseRenamed))
+			Expect(lastState.RecipientFingerprint).To(Equal("some-fingerprint"))
+		})
+
+		It("stops without cutting over when the cutover is not confirmed", func() {
+			fakeMigrator.ConfirmCutoverReturns(false)
+
+			err := commands.Migrate([]string{"--online", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("cutover of some-donor was not confirmed. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to cut over later"))
+
+			Expect(fakeMigrator.CutOverCallCount()).To(BeZero())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(BeZero())
+			Expect(fakeMigrator.RemoveStateCallCount()).To(BeZero())
+		})
+
+		It("returns an error without cleaning up when the cutover fails", func() {
+			fakeMigrator.CutOverReturns(errors.New("some-error"))
+
+			err := commands.Migrate([]string{"--online", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("error cutting over: some-error. Not cleaning up service some-donor-new. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to retry"))
+
+			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(BeZero())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(BeZero())
+		})
+
+		It("asks to cut over again when resuming", func() {
+			fakeMigrator.LoadStateReturns(migratedState, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.MigrateDataCallCount()).To(BeZero())
+			Expect(fakeMigrator.ConfirmCutoverCallCount()).To(Equal(1))
+			Expect(fakeMigrator.CutOverCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(Equal(1))
+		})
+
+		It("does not cut over again once it has", func() {
+			migratedState.Phase = migrate.PhaseCutOver
+			fakeMigrator.LoadStateReturns(migratedState, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.ConfirmCutoverCallCount()).To(BeZero())
+			Expect(fakeMigrator.CutOverCallCount()).To(BeZero())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(Equal(1))
+		})
+
+		It("can not be combined with schema or table filters", func() {
+			err := commands.Migrate([]string{"--online", "--exclude-table", "app.audit", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--online can not be combined with schema or table filters"))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--online", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--online can not be changed when resuming a migration"))
+		})
+	})
+
+	It("does not migrate online by default", func() {
+		Expect(commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+		Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Online).To(BeFalse())
+		Expect(fakeMigrator.CutOverCallCount()).To(BeZero())
+	})
+
+	Context("when schema and table filters are specified", func() {
+		It("passes them on to the migration and the preflight checks", func() {
+			args := []string{
+				"--include-schema", "app*",
+				"--exclude-schema", "app_audit",
+				"--include-table", "app.*",
+				"--exclude-table", "app.audit_*",
+				"--exclude-table", "app.sessions",
+				"some-donor", "some-plan",
+			}
+			expected := discovery.Filter{
+				IncludeSchemas: []string{"app*"},
+				ExcludeSchemas: []string{"app_audit"},
+				IncludeTables:  []string{"app.*"},
+				ExcludeTables:  []string{"app.audit_*", "app.sessions"},
+			}
+
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+			Expect(migrateOptionsOf(fakeMigrator.MigrateDataArgsForCall(0)).Filter).To(Equal(expected))
+
+			fakeMigrator.PreflightReturns(migrate.PreflightReport{})
+			Expect(commands.Migrate(append([]string{"--dry-run"}, args...), fakeMigrator)).To(Succeed())
+			opts, _ := fakeMigrator.PreflightArgsForCall(0)
+			Expect(opts.Filter).To(Equal(expected))
+		})
+
+		It("rejects malformed patterns before provisioning anything", func() {
+			err := commands.Migrate([]string{"--exclude-table", "app.[audit", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(`invalid schema or table filter: invalid pattern "app.[audit": syntax error in pattern`))
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--include-schema", "app", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\nschema and table filters can not be changed when resuming a migration"))
+		})
+	})
+
+	Context("when a report is requested", func() {
+		It("writes the report once the migration completed", func() {
+			Expect(commands.Migrate([]string{"--report", "report.json", "some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.BeginReportCallCount()).To(Equal(1))
+			Expect(fakeMigrator.WriteReportCallCount()).To(Equal(1))
+			path, state, migrationErr := fakeMigrator.WriteReportArgsForCall(0)
+			Expect(path).To(Equal("report.json"))
+			Expect(state.DonorInstanceName).To(Equal("some-donor"))
+			Expect(state.RecipientInstanceName).To(Equal("some-donor-new"))
+			Expect(state.PlanName).To(Equal("some-plan"))
+			Expect(migrationErr).NotTo(HaveOccurred())
+		})
+
+		It("writes the report when the migration fails", func() {
+			fakeMigrator.MigrateDataReturns(errors.New("some-error"))
+
+			err := commands.Migrate([]string{"--report", "report.json", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("some-error")))
+
+			Expect(fakeMigrator.WriteReportCallCount()).To(Equal(1))
+			_, _, migrationErr := fakeMigrator.WriteReportArgsForCall(0)
+			Expect(migrationErr).To(Equal(err))
+		})
+
+		It("fails when the report can not be written", func() {
+			fakeMigrator.WriteReportReturns(errors.New("disk full"))
+
+			err := commands.Migrate([]string{"--report", "report.json", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("disk full"))
+		})
+
+		It("keeps the error of a failed migration when the report can not be written", func() {
+			fakeMigrator.MigrateDataReturns(errors.New("some-error"))
+			fakeMigrator.WriteReportReturns(errors.New("disk full"))
+
+			err := commands.Migrate([]string{"--report", "report.json", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("some-error")))
+			Expect(logOutput.String()).To(ContainSubstring("Warning: disk full"))
+		})
+
+		It("can not be combined with dry-run", func() {
+			err := commands.Migrate([]string{"--report", "report.json", "--dry-run", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--report can not be combined with --dry-run"))
+		})
+
+		It("does not write a report unless requested", func() {
+			Expect(commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+			Expect(fakeMigrator.WriteReportCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when rebind is specified", func() {
+		var bindings []migrate.RecordedBinding
+
+		BeforeEach(func() {
+			bindings = []migrate.RecordedBinding{
+				{Type: migrate.AppBinding, Name: "some-app", Parameters: `{"read_only":true}`},
+				{Type: migrate.ServiceKeyBinding, Name: "some-key"},
+			}
+			fakeMigrator.RecordBindingsReturns(bindings, nil)
+			fakeMigrator.RebindReturns([]migrate.RebindResult{{Binding: bindings[0]}, {Binding: bindings[1]}})
+		})
+
+		It("records the donor's bindings before renaming and moves them to the new instance afterwards", func() {
+			args := []string{"--rebind", "--restage", "some-donor", "some-plan"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.RecordBindingsCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RecordBindingsArgsForCall(0)).To(Equal("some-donor"))
+
+			Expect(fakeMigrator.SaveStateCallCount()).To(Equal(4))
+			recorded := fakeMigrator.SaveStateArgsForCall(2)
+			Expect(recorded.Phase).To(Equal(migrate.PhaseBindingsRecorded))
+			Expect(recorded.Bindings).To(Equal(bindings))
+			Expect(fakeMigrator.SaveStateArgsForCall(3).Phase).To(Equal(migrate.PhaseRenamed))
+
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RebindCallCount()).To(Equal(1))
+			rebound, from, to, restage := fakeMigrator.RebindArgsForCall(0)
+			Expect(rebound).To(Equal(bindings))
+			Expect(from).To(Equal("some-donor-old"))
+			Expect(to).To(Equal("some-donor"))
+			Expect(restage).To(BeTrue())
+
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+		})
+
+		It("moves the bindings to an existing recipient", func() {
+			args := []string{"--rebind", "--recipient", "some-recipient", "some-donor"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			_, from, to, restage := fakeMigrator.RebindArgsForCall(0)
+			Expect(from).To(Equal("some-donor"))
+			Expect(to).To(Equal("some-recipient"))
+			Expect(restage).To(BeFalse())
+		})
+
+		It("returns an error summarising the bindings that could not be moved", func() {
+			fakeMigrator.RebindReturns([]migrate.RebindResult{
+				{Binding: bindings[0], Err: errors.New("bind failed")},
+				{Binding: bindings[1]},
+			})
+
+			err := commands.Migrate([]string{"--rebind", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("failed to rebind 1 of 2 apps and service keys to some-donor. Rebind them manually"))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+		})
+
+		It("does not rename the service instances when the bindings can not be recorded", func() {
+			fakeMigrator.RecordBindingsReturns(nil, errors.New("some-error"))
+
+			err := commands.Migrate([]string{"--rebind", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("failed to record the bindings of some-donor: some-error. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to retry"))
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(BeZero())
+			Expect(fakeMigrator.RemoveStateCallCount()).To(BeZero())
+		})
+
+		It("rebinds the recorded bindings when resuming after the rename", func() {
+			fakeMigrator.LoadStateReturns(migrate.State{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Phase:                 migrate.PhaseRenamed,
+				Bindings:              bindings,
+				Options: migrate.MigrateOptions{
+					DonorInstanceName:     "some-donor",
+					RecipientInstanceName: "some-donor-new",
+					Rebind:                true,
+				},
+			}, nil)
+
+			Expect(commands.Migrate([]string{"--resume", "some-donor"}, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.RecordBindingsCallCount()).To(BeZero())
+			Expect(fakeMigrator.RenameServiceInstancesCallCount()).To(BeZero())
+			rebound, _, _, _ := fakeMigrator.RebindArgsForCall(0)
+			Expect(rebound).To(Equal(bindings))
+		})
+
+		It("only accepts restage together with rebind", func() {
+			err := commands.Migrate([]string{"--restage", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--restage can only be used together with --rebind"))
+		})
+
+		It("can not be changed when resuming", func() {
+			err := commands.Migrate([]string{"--rebind", "--resume", "some-donor"}, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--rebind and --restage can not be changed when resuming a migration"))
+		})
+	})
+
+	It("does not rebind apps by default", func() {
+		Expect(commands.Migrate([]string{"some-donor", "some-plan"}, fakeMigrator)).To(Succeed())
+
+		Expect(fakeMigrator.RecordBindingsCallCount()).To(BeZero())
+		Expect(fakeMigrator.RebindCallCount()).To(BeZero())
+	})
+
+	Context("when dry-run is specified", func() {
+		It("runs the preflight checks without creating or migrating anything", func() {
+			fakeMigrator.PreflightReturns(migrate.PreflightReport{
+				Checks: []migrate.PreflightCheck{{Name: "quota", Status: migrate.PreflightPassed}},
+			})
+
+			args := []string{"--dry-run", "--include-stored-programs", "some-donor", "some-plan"}
+			Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+			Expect(fakeMigrator.PreflightCallCount()).To(Equal(1))
+			opts, plan := fakeMigrator.PreflightArgsForCall(0)
+			Expect(plan).To(Equal("some-plan"))
+			Expect(opts).To(Equal(migrate.MigrateOptions{
+				DonorInstanceName:     "some-donor",
+				RecipientInstanceName: "some-donor-new",
+				Cleanup:               true,
+				IncludeStoredPrograms: true,
+			}))
+
+			Expect(fakeMigrator.CreateServiceInstanceCallCount()).To(BeZero())
+			Expect(fakeMigrator.MigrateDataCallCount()).To(BeZero())
+			Expect(fakeMigrator.SaveStateCallCount()).To(BeZero())
+		})
+
+		It("returns an error when a preflight check fails", func() {
+			fakeMigrator.PreflightReturns(migrate.PreflightReport{
+				Checks: []migrate.PreflightCheck{{Name: "quota", Status: migrate.PreflightFailed}},
+			})
+
+			args := []string{"--dry-run", "some-donor", "some-plan"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("preflight checks for migrating some-donor failed"))
+		})
+
+		It("can not be combined with resume", func() {
+			args := []string{"--dry-run", "--resume", "some-donor"}
+			err := commands.Migrate(args, fakeMigrator)
+			Expect(err).To(MatchError("Usage: " + migrateUsage + "\n\n--dry-run can not be combined with --resume"))
+		})
+	})
+
+	It("does not verify the migrated data by default", func() {
+		args := []string{"some-donor", "some-plan"}
+		Expect(commands.Migrate(args, fakeMigrator)).To(Succeed())
+
+		_, opts := fakeMigrator.MigrateDataArgsForCall(0)
+		Expect(opts.Verify).To(BeEmpty())
+	})
+
 	It("returns an error if the donor service instance does not exist", func() {
 		fakeMigrator.CheckServiceExistsReturns(errors.New("some-donor does not exist"))
 
@@ -129,6 +1033,21 @@ var _ = Describe("Migrate", func() {
 			err := commands.Migrate(args, fakeMigrator)
 			Expect(err).To(MatchError(MatchRegexp("error creating service instance: some-cf-error. Attempting to clean up service some-donor-new")))
 			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
+		})
+
+		It("leaves the service instance behind when waiting for it timed out", func() {
+			fakeMigrator.CreateServiceInstanceStub = func(ctx context.Context, _, _ string, _ migrate.ServiceInstanceConfig) error {
+				<-ctx.Done()
+				return context.Cause(ctx)
+			}
+
+			err := commands.Migrate([]string{"--provision-timeout", "1ms", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError("error creating service instance: timed out after 1ms, as set by --provision-timeout. " +
+				"Service instance some-donor-new was left behind and may still be being created. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to wait for it and continue, or 'cf delete-service some-donor-new' once it was created"))
+			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(BeZero())
+			Expect(fakeMigrator.RemoveStateCallCount()).To(BeZero())
 		})
 
 		It("returns an error and doesn't clean up when the --no-cleanup flag is passed", func() {
@@ -137,8 +1056,10 @@ var _ = Describe("Migrate", func() {
 			}
 
 			err := commands.Migrate(args, fakeMigrator)
-			Expect(err).To(MatchError("error creating service instance: some-cf-error. Not cleaning up service some-donor-new"))
+			Expect(err).To(MatchError("error creating service instance: some-cf-error. Not cleaning up service some-donor-new. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to retry"))
 			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(Equal(0))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(BeZero())
 		})
 	})
 
@@ -151,9 +1072,10 @@ var _ = Describe("Migrate", func() {
 			args := []string{"some-donor", "some-plan"}
 			err := commands.Migrate(args, fakeMigrator)
 			Expect(err).To(MatchError(MatchRegexp("error migrating data: some-cf-error. Attempting to clean up service some-donor-new")))
-			opts := fakeMigrator.MigrateDataArgsForCall(0)
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
 			Expect(opts.Cleanup).To(BeTrue())
 			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(Equal(1))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(1))
 		})
 
 		It("returns an error and doesn't clean up when the --no-cleanup flag is passed", func() {
@@ -163,12 +1085,24 @@ var _ = Describe("Migrate", func() {
 
 			err := commands.Migrate(args, fakeMigrator)
 
-			Expect(err).To(MatchError("error migrating data: some-cf-error. Not cleaning up service some-donor-new"))
+			Expect(err).To(MatchError("error migrating data: some-cf-error. Not cleaning up service some-donor-new. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to retry"))
+			Expect(fakeMigrator.RemoveStateCallCount()).To(Equal(0))
 			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(1))
-			opts := fakeMigrator.MigrateDataArgsForCall(0)
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
 			Expect(opts.Cleanup).To(BeFalse())
 			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(Equal(0))
 		})
+
+		It("keeps everything to resume with when the result of the migration task could not be retrieved", func() {
+			fakeMigrator.MigrateDataReturns(fmt.Errorf("%w, so the binlog position it caught up to is unknown", migrate.ErrTaskResultUnavailable))
+
+			err := commands.Migrate([]string{"--online", "some-donor", "some-plan"}, fakeMigrator)
+			Expect(err).To(MatchError(ContainSubstring("Not cleaning up service some-donor-new. " +
+				"Run 'cf mysql-tools migrate --resume some-donor' to retrieve the logs of the migration task again")))
+			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(BeZero())
+			Expect(fakeMigrator.RemoveStateCallCount()).To(BeZero())
+		})
 	})
 
 	Context("when renaming the service instances fail", func() {
@@ -181,10 +1115,14 @@ var _ = Describe("Migrate", func() {
 			err := commands.Migrate(args, fakeMigrator)
 			Expect(err).To(MatchError("some-cf-error"))
 			Expect(fakeMigrator.MigrateDataCallCount()).To(Equal(1))
-			opts := fakeMigrator.MigrateDataArgsForCall(0)
+			_, opts := fakeMigrator.MigrateDataArgsForCall(0)
 			Expect(opts.Cleanup).To(BeTrue())
 			Expect(fakeMigrator.CleanupOnErrorCallCount()).To(Equal(0))
 		})
 	})
 
 })
+
+func migrateOptionsOf(_ context.Context, opts migrate.MigrateOptions) migrate.MigrateOptions {
+	return opts
+}
@@ -15,10 +15,13 @@ package plugin
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"code.cloudfoundry.org/cli/cf/configuration/confighelpers"
 	"code.cloudfoundry.org/cli/plugin"
 	"github.com/blang/semver/v4"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/app"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/cf"
 	findbindings "github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/find-bindings"
 	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
@@ -31,7 +34,15 @@ const (
 mysql-tools - Plugin to manage mysql instances
 
 USAGE:
-cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] <source-service-instance> <p.mysql-plan-type>
+cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--verify[=rows|checksum]] [--fingerprint] [--{include,exclude}-{schema,table} <pattern>]... [--parallel <workers>] [--engine <go|exec>] [--definer <invoker|user@host>] [--masking-rules <file>] [--convert-charset <charset> [--collation <collation>]] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--app-memory <size>] [--app-disk <size>] [--stack <stack>] [--require-isolation-segment <segment>] [--online] [--dry-run] [--rebind [--restage]] [--report <file>] [--task-timeout <duration>] [--provision-timeout <duration>] [--recipient-params <json|file>] [--recipient-tags <tags>] <source-service-instance> <p.mysql-plan-type>
+cf mysql-tools migrate [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--verify[=rows|checksum]] [--fingerprint] [--{include,exclude}-{schema,table} <pattern>]... [--parallel <workers>] [--engine <go|exec>] [--definer <invoker|user@host>] [--masking-rules <file>] [--convert-charset <charset> [--collation <collation>]] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--app-memory <size>] [--app-disk <size>] [--stack <stack>] [--require-isolation-segment <segment>] [--online] [--dry-run] [--rebind [--restage]] [--report <file>] [--task-timeout <duration>] [--force-overwrite] --recipient <recipient-service-instance> <source-service-instance>
+cf mysql-tools migrate --resume [--force-overwrite] [--max-rows-per-second <rows>] [--max-bandwidth <bytes-per-second>] [--max-threads-running <threads>] [--max-replication-lag <duration>] [--report <file>] [--task-timeout <duration>] [--provision-timeout <duration>] <source-service-instance>
+cf mysql-tools migrate-rollback [-h] [--no-cleanup] [--skip-tls-validation] [--force] <service-instance>
+cf mysql-tools export [-h] [--no-cleanup] [--skip-tls-validation] [--encrypt] [--s3-endpoint <url>] [--s3-region <region>] --to <file|s3://bucket/key> <service-instance>
+cf mysql-tools import [-h] [--no-cleanup] [--skip-tls-validation] [--definer <invoker|user@host>] [--schema <from>=<to>]... [--s3-endpoint <url>] [--s3-region <region>] <service-instance> <file|s3://bucket/key>
+cf mysql-tools clone [-h] [--no-cleanup] [--skip-tls-validation] [--include-stored-programs] [--definer <invoker|user@host>] [--masking-rules <file>] [--force] --from [<target>/]<space>/<instance> --to [<target>/]<space>/<instance>
+cf mysql-tools check-compat [-h] [--no-cleanup] [--skip-tls-validation] <service-instance>
+cf mysql-tools cleanup [-h] [--older-than <duration>] [--dry-run]
 cf mysql-tools find-bindings [-h] <mysql-v1-service-name>
 cf mysql-tools save-target <target-name>
 cf mysql-tools remove-target <target-name>
@@ -42,11 +53,13 @@ cf mysql-tools version`
 )
 
 type MigrationAppExtractor interface {
-	Unpack(directoryPath string) error
+	Unpack(directoryPath string, settings app.Settings) error
 }
 
 type MySQLPlugin struct {
 	MigrationAppExtractor MigrationAppExtractor
+	MigrationStateStore   migrate.StateStore
+	CutoverStore          migrate.CutoverStore
 	MultisiteConfig       commands.MultisiteConfig
 	err                   error
 }
@@ -81,8 +94,27 @@ func (c *MySQLPlugin) Run(cliConnection plugin.CliConnection, args []string) {
 	case "migrate":
 		c.err = commands.Migrate(
 			options,
-			migrate.NewMigrator(cf.NewMigratorClient(cliConnection), c.MigrationAppExtractor),
+			c.newMigrator(
+				cliConnection,
+				migrate.NewMySQLDonorInspector(),
+				findbindings.NewBindingFinder(cf.NewFindBindingsClient(cliConnection)),
+			),
 		)
+	case "migrate-rollback":
+		c.err = commands.MigrateRollback(options, c.newMigrator(cliConnection, nil, nil))
+	case "export":
+		c.err = commands.Export(options, c.newMigrator(cliConnection, nil, nil))
+	case "import":
+		c.err = commands.Import(options, c.newMigrator(cliConnection, nil, nil))
+	case "clone":
+		c.err = commands.Clone(
+			options,
+			migrate.NewCloner(c.connect, c.MigrationAppExtractor, c.MigrationStateStore),
+		)
+	case "check-compat":
+		c.err = commands.CheckCompat(options, c.newMigrator(cliConnection, nil, nil))
+	case "cleanup":
+		c.err = commands.Cleanup(options, c.newMigrator(cliConnection, nil, nil))
 	case "save-target":
 		c.err = commands.SaveTarget(options, c.MultisiteConfig)
 	case "list-targets":
@@ -96,6 +128,42 @@ func (c *MySQLPlugin) Run(cliConnection plugin.CliConnection, args []string) {
 	}
 }
 
+// newMigrator returns a Migrator for the current target. Only migrate inspects donors and rebinds apps, so the
+// other commands pass a nil inspector and finder.
+func (c *MySQLPlugin) newMigrator(cliConnection plugin.CliConnection, inspector migrate.DonorInspector, finder migrate.BindingFinder) *migrate.Migrator {
+	return migrate.NewMigrator(
+		cf.NewMigratorClient(cliConnection),
+		c.MigrationAppExtractor,
+		c.MigrationStateStore,
+		inspector,
+		finder,
+		c.CutoverStore,
+	)
+}
+
+// connect returns a client for a space of a saved target, or of the current target when target is empty. The
+// client uses a copy of the cf configuration, so that neither the current session nor the saved target change.
+func (c *MySQLPlugin) connect(target, space string) (migrate.Client, func(), error) {
+	configFile, err := confighelpers.DefaultFilePath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if target != "" {
+		configFile = filepath.Join(c.MultisiteConfig.ConfigDir(target), ".cf", "config.json")
+		if _, err := os.Stat(configFile); err != nil {
+			return nil, nil, fmt.Errorf("saved target %q not found", target)
+		}
+	}
+
+	cli, err := cf.NewSpaceCLI(configFile, space)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return cf.NewMigratorClient(cli), func() { _ = cli.Close() }, nil
+}
+
 func (c *MySQLPlugin) GetMetadata() plugin.PluginMetadata {
 	return plugin.PluginMetadata{
 		Name:    "MysqlTools",
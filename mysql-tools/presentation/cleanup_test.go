@@ -0,0 +1,47 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package presentation_test
+
+import (
+	"bytes"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/presentation"
+)
+
+var _ = Describe("Leftovers", func() {
+	BeforeEach(func() {
+		format.TruncatedDiff = false
+	})
+
+	It("prints the leftovers with their age", func() {
+		content, err := os.ReadFile("fixtures/leftovers.txt")
+		Expect(err).NotTo(HaveOccurred())
+
+		now := time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC)
+		writer := bytes.Buffer{}
+		presentation.Leftovers(&writer, []migrate.Leftover{
+			{Type: migrate.LeftoverApp, Name: "migrate-app-some-guid", CreatedAt: now.Add(-50 * time.Hour), Size: "1024M x 1"},
+			{Type: migrate.LeftoverServiceInstance, Name: "some-donor-new", CreatedAt: now.Add(-90 * time.Minute), Size: "db-small"},
+			{Type: migrate.LeftoverServiceInstance, Name: "other-donor-new", CreatedAt: now.Add(-5 * time.Minute), Size: "db-large"},
+		}, now)
+
+		Expect(writer.String()).To(Equal(string(content)))
+	})
+})
@@ -0,0 +1,59 @@
+// Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package presentation
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+func PreflightReport(w io.Writer, report migrate.PreflightReport) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Check", "Status", "Details"})
+	table.SetAutoWrapText(false)
+	for _, c := range report.Checks {
+		table.Append([]string{c.Name, string(c.Status), c.Detail})
+	}
+	table.Render()
+
+	if report.Donor != nil {
+		donor := report.Donor
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Donor:")
+		fmt.Fprintf(w, "  Schemas: %s\n", listOrNone(donor.Schemas))
+		fmt.Fprintf(w, "  Excluded tables: %s\n", listOrNone(donor.ExcludedTables))
+		fmt.Fprintf(w, "  Data size: %s\n", migrate.ByteSize(donor.DataSizeBytes))
+		fmt.Fprintf(w, "  Invalid views (will not be migrated): %s\n", listOrNone(donor.InvalidViews))
+		fmt.Fprintf(w, "  Unsupported objects (will not be migrated): %s\n", listOrNone(donor.UnsupportedObjects))
+	}
+
+	fmt.Fprintln(w)
+	if report.Passed() {
+		fmt.Fprintln(w, "Preflight checks passed.")
+	} else {
+		fmt.Fprintln(w, "Preflight checks failed.")
+	}
+}
+
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "none"
+	}
+
+	return strings.Join(items, ", ")
+}
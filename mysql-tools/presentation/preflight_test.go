@@ -0,0 +1,63 @@
+// Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package presentation_test
+
+import (
+	"bytes"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/presentation"
+)
+
+var _ = Describe("PreflightReport", func() {
+	BeforeEach(func() {
+		format.TruncatedDiff = false
+	})
+
+	It("prints the checks and the donor summary", func() {
+		content, err := os.ReadFile("fixtures/preflight.txt")
+		Expect(err).NotTo(HaveOccurred())
+
+		writer := bytes.Buffer{}
+		presentation.PreflightReport(&writer, migrate.PreflightReport{
+			Checks: []migrate.PreflightCheck{
+				{Name: "donor service instance", Status: migrate.PreflightPassed, Detail: "some-donor exists"},
+				{Name: "quota", Status: migrate.PreflightFailed, Detail: "no service instance can be created"},
+			},
+			Donor: &migrate.DonorSummary{
+				Schemas:       []string{"foo", "bar"},
+				DataSizeBytes: 5 * 1024 * 1024,
+				InvalidViews:  []string{"foo.broken"},
+			},
+		})
+
+		Expect(writer.String()).To(Equal(string(content)))
+	})
+
+	It("omits the donor summary when the donor could not be inspected", func() {
+		writer := bytes.Buffer{}
+		presentation.PreflightReport(&writer, migrate.PreflightReport{
+			Checks: []migrate.PreflightCheck{
+				{Name: "donor discovery", Status: migrate.PreflightWarning, Detail: "unable to inspect some-donor"},
+			},
+		})
+
+		Expect(writer.String()).NotTo(ContainSubstring("Donor:"))
+		Expect(writer.String()).To(HaveSuffix("Preflight checks passed.\n"))
+	})
+})
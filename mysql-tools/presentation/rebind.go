@@ -0,0 +1,51 @@
+// Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package presentation
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+)
+
+// RebindReport prints the outcome of every rebound app and service key and returns the number of failures
+func RebindReport(w io.Writer, results []migrate.RebindResult) (failed int) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Type", "Name", "Status", "Details"})
+	table.SetAutoWrapText(false)
+	for _, r := range results {
+		kind := "app"
+		if r.Binding.Type == migrate.ServiceKeyBinding {
+			kind = "service key"
+		}
+
+		status, detail := "OK", ""
+		if r.Err != nil {
+			status, detail = "FAILED", r.Err.Error()
+			failed++
+		} else if r.Binding.ParametersError != "" {
+			status, detail = "WARN", "recreated without parameters: "+r.Binding.ParametersError
+		}
+
+		table.Append([]string{kind, r.Binding.Name, status, detail})
+	}
+	table.Render()
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Rebound %d of %d bindings.\n", len(results)-failed, len(results))
+
+	return failed
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2019-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package presentation_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/format"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/migrate"
+	"github.com/pivotal-cf/mysql-cli-plugin/mysql-tools/presentation"
+)
+
+var _ = Describe("RebindReport", func() {
+	BeforeEach(func() {
+		format.TruncatedDiff = false
+	})
+
+	It("prints the outcome of every binding and returns the number of failures", func() {
+		content, err := os.ReadFile("fixtures/rebind.txt")
+		Expect(err).NotTo(HaveOccurred())
+
+		writer := bytes.Buffer{}
+		failed := presentation.RebindReport(&writer, []migrate.RebindResult{
+			{Binding: migrate.RecordedBinding{Type: migrate.AppBinding, Name: "app-1"}},
+			{Binding: migrate.RecordedBinding{Type: migrate.AppBinding, Name: "app-2"}, Err: errors.New("bind failed")},
+			{Binding: migrate.RecordedBinding{Type: migrate.ServiceKeyBinding, Name: "some-key", ParametersError: "not supported"}},
+		})
+
+		Expect(failed).To(Equal(1))
+		Expect(writer.String()).To(Equal(string(content)))
+	})
+})
@@ -0,0 +1,281 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package archive reads and writes the dump files of exported service instances. Dumps are compressed with gzip and,
+// when a passphrase is given, encrypted with AES-256-GCM using a key derived from the passphrase with scrypt. Dumps
+// compressed with zstd, or not compressed at all, can be read as well.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PassphraseEnv is the environment variable holding the passphrase of encrypted dumps
+const PassphraseEnv = "MYSQL_TOOLS_PASSPHRASE"
+
+const (
+	// chunkSize is the amount of plaintext sealed at once. Each chunk is authenticated on its own, so that a dump
+	// can be decrypted while it is streamed.
+	chunkSize = 64 * 1024
+	saltSize  = 16
+	// finalChunk is set in the length of the last chunk, which is also sealed with a different nonce, so that a
+	// truncated dump is detected
+	finalChunk = 1 << 31
+)
+
+// magic starts every encrypted dump. Compressed dumps start with the gzip header.
+var magic = []byte("MYSQLTOOLS-AES256GCM\x00\x01")
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+var (
+	ErrPassphraseRequired = errors.New("the dump is encrypted, a passphrase is required")
+	ErrDecryptionFailed   = errors.New("failed to decrypt the dump: wrong passphrase or corrupted dump")
+	ErrTruncated          = errors.New("the dump is truncated")
+)
+
+// NewWriter returns a writer compressing a dump into w, and encrypting it when passphrase is not empty. Closing
+// it completes the dump, but does not close w.
+func NewWriter(w io.Writer, passphrase string) (io.WriteCloser, error) {
+	if passphrase == "" {
+		return gzip.NewWriter(w), nil
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate a salt: %w", err)
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(append(append([]byte{}, magic...), salt...)); err != nil {
+		return nil, err
+	}
+
+	e := &encrypter{w: w, aead: aead}
+
+	return &compressor{Writer: gzip.NewWriter(e), encrypter: e}, nil
+}
+
+// NewReader returns a reader decrypting and decompressing a dump written by NewWriter. Dumps compressed with gzip or
+// zstd by other tools are decompressed, and uncompressed dumps are read as is.
+func NewReader(r io.Reader, passphrase string) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+
+	header, err := br.Peek(len(magic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(header, zstdMagic):
+		d, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return d.IOReadCloser(), nil
+	case !bytes.Equal(header, magic):
+		return io.NopCloser(br), nil
+	}
+
+	if passphrase == "" {
+		return nil, ErrPassphraseRequired
+	}
+
+	salt := make([]byte, saltSize)
+	_, _ = br.Discard(len(magic))
+	if _, err := io.ReadFull(br, salt); err != nil {
+		return nil, ErrTruncated
+	}
+
+	aead, err := newAEAD(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return gzip.NewReader(&decrypter{r: br, aead: aead})
+}
+
+func newAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the encryption key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// nonce numbers the chunks, so that they can not be reordered or dropped
+func nonce(aead cipher.AEAD, counter uint64, final bool) []byte {
+	n := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(n, counter)
+	if final {
+		n[len(n)-1] = 1
+	}
+
+	return n
+}
+
+// compressor closes the gzip stream before sealing the last chunk
+type compressor struct {
+	*gzip.Writer
+	encrypter *encrypter
+}
+
+func (c *compressor) Close() error {
+	if err := c.Writer.Close(); err != nil {
+		return err
+	}
+
+	return c.encrypter.Close()
+}
+
+type encrypter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+}
+
+func (e *encrypter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	for len(p) > 0 {
+		free := chunkSize - len(e.buf)
+		if free == 0 {
+			if err := e.seal(false); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		if free > len(p) {
+			free = len(p)
+		}
+		e.buf = append(e.buf, p[:free]...)
+		p = p[free:]
+	}
+
+	return n, nil
+}
+
+// Close seals the remaining plaintext as the final chunk
+func (e *encrypter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encrypter) seal(final bool) error {
+	sealed := e.aead.Seal(nil, nonce(e.aead, e.counter, final), e.buf, nil)
+	e.counter++
+	e.buf = e.buf[:0]
+
+	length := uint32(len(sealed))
+	if final {
+		length |= finalChunk
+	}
+
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, length)
+	if _, err := e.w.Write(header); err != nil {
+		return err
+	}
+
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+type decrypter struct {
+	r       io.Reader
+	aead    cipher.AEAD
+	buf     []byte
+	counter uint64
+	final   bool
+}
+
+func (d *decrypter) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		if d.final {
+			return 0, io.EOF
+		}
+
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+
+	return n, nil
+}
+
+func (d *decrypter) open() error {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrTruncated
+		}
+		return err
+	}
+
+	length := binary.BigEndian.Uint32(header)
+	final := length&finalChunk != 0
+	length &^= finalChunk
+
+	if length > chunkSize+uint32(d.aead.Overhead()) {
+		return ErrDecryptionFailed
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrTruncated
+		}
+		return err
+	}
+
+	plaintext, err := d.aead.Open(nil, nonce(d.aead, d.counter, final), sealed, nil)
+	if err != nil {
+		return ErrDecryptionFailed
+	}
+
+	d.counter++
+	d.buf = plaintext
+	d.final = final
+
+	return nil
+}
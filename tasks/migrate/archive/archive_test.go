@@ -0,0 +1,144 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package archive_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+)
+
+var _ = Describe("Archive", func() {
+	var dump string
+
+	BeforeEach(func() {
+		// Random enough to span several encrypted chunks once compressed
+		random := rand.New(rand.NewSource(GinkgoRandomSeed()))
+		var b strings.Builder
+		for b.Len() < 1024*1024 {
+			fmt.Fprintf(&b, "INSERT INTO `t` VALUES (%d,'%x');\n", random.Int63(), random.Int63())
+		}
+		dump = b.String()
+	})
+
+	write := func(passphrase string) []byte {
+		var out bytes.Buffer
+		w, err := archive.NewWriter(&out, passphrase)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.Copy(w, strings.NewReader(dump))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		return out.Bytes()
+	}
+
+	read := func(written []byte, passphrase string) (string, error) {
+		r, err := archive.NewReader(bytes.NewReader(written), passphrase)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+
+		out, err := io.ReadAll(r)
+		return string(out), err
+	}
+
+	It("compresses a dump with gzip", func() {
+		written := write("")
+
+		r, err := gzip.NewReader(bytes.NewReader(written))
+		Expect(err).NotTo(HaveOccurred())
+		out, err := io.ReadAll(r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(out)).To(Equal(dump))
+
+		Expect(read(written, "")).To(Equal(dump))
+	})
+
+	It("ignores the passphrase of a dump that is not encrypted", func() {
+		Expect(read(write(""), "secret")).To(Equal(dump))
+	})
+
+	It("reads a dump compressed with zstd", func() {
+		var compressed bytes.Buffer
+		w, err := zstd.NewWriter(&compressed)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = io.Copy(w, strings.NewReader(dump))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w.Close()).To(Succeed())
+
+		Expect(read(compressed.Bytes(), "")).To(Equal(dump))
+	})
+
+	It("reads a dump that is not compressed", func() {
+		Expect(read([]byte(dump), "")).To(Equal(dump))
+		Expect(read(nil, "")).To(BeEmpty())
+	})
+
+	Context("with a passphrase", func() {
+		var written []byte
+
+		BeforeEach(func() {
+			written = write("secret")
+		})
+
+		It("encrypts the compressed dump", func() {
+			Expect(written).NotTo(ContainSubstring("INSERT INTO"))
+			_, err := gzip.NewReader(bytes.NewReader(written))
+			Expect(err).To(HaveOccurred())
+
+			Expect(read(written, "secret")).To(Equal(dump))
+		})
+
+		It("uses a different salt every time", func() {
+			Expect(write("secret")).NotTo(Equal(written))
+		})
+
+		It("requires the passphrase", func() {
+			_, err := read(written, "")
+			Expect(err).To(MatchError(archive.ErrPassphraseRequired))
+		})
+
+		It("rejects a wrong passphrase", func() {
+			_, err := read(written, "wrong")
+			Expect(err).To(MatchError(archive.ErrDecryptionFailed))
+		})
+
+		It("detects a corrupted dump", func() {
+			corrupted := append([]byte{}, written...)
+			corrupted[len(corrupted)/2] ^= 0xff
+
+			_, err := read(corrupted, "secret")
+			Expect(err).To(MatchError(archive.ErrDecryptionFailed))
+		})
+
+		It("detects a dump truncated at a chunk boundary", func() {
+			// Each full chunk is a 4 byte length followed by 64 KiB of ciphertext and a 16 byte tag
+			const chunk = 4 + 64*1024 + 16
+			header := len("MYSQLTOOLS-AES256GCM\x00\x01") + 16
+			Expect(len(written)).To(BeNumerically(">", header+chunk))
+
+			_, err := read(written[:header+chunk], "secret")
+			Expect(err).To(MatchError(archive.ErrTruncated))
+		})
+	})
+})
@@ -15,12 +15,16 @@ package main
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
 	"time"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
 	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/throttle"
 )
 
 func baseCmd(cmdName string, credentials Credentials) *exec.Cmd {
@@ -45,7 +49,31 @@ func baseCmd(cmdName string, credentials Credentials) *exec.Cmd {
 	return cmd
 }
 
-func MySQLDumpCmd(credentials Credentials, invalidViews []discovery.View, schemas ...string) *exec.Cmd {
+func MySQLDumpCmd(credentials Credentials, invalidViews []discovery.View, excludedTables []string, schemas ...string) *exec.Cmd {
+	return mysqlDumpCmd(credentials, nil, invalidViews, excludedTables, schemas)
+}
+
+// MySQLDumpWithBinlogPositionCmd is MySQLDumpCmd also writing the binlog position the dump is consistent with as
+// a comment, so that the changes made since can be applied afterwards
+func MySQLDumpWithBinlogPositionCmd(credentials Credentials, invalidViews []discovery.View, excludedTables []string, schemas ...string) *exec.Cmd {
+	return mysqlDumpCmd(credentials, []string{"--master-data=2"}, invalidViews, excludedTables, schemas)
+}
+
+// MySQLDumpSchemaCmd dumps the structure of the given schemas, including views, without any data.
+// It is used by parallel migrations, which copy the data of each table separately afterwards.
+func MySQLDumpSchemaCmd(credentials Credentials, invalidViews []discovery.View, excludedTables []string, schemas ...string) *exec.Cmd {
+	return mysqlDumpCmd(credentials, []string{"--no-data"}, invalidViews, excludedTables, schemas)
+}
+
+// MySQLDumpTableDataCmd dumps only the rows of a single table
+func MySQLDumpTableDataCmd(credentials Credentials, schema, table string) *exec.Cmd {
+	cmd := mysqlDumpCmd(credentials, []string{"--no-create-info"}, nil, nil, nil)
+	cmd.Args = append(cmd.Args, schema, table)
+
+	return cmd
+}
+
+func mysqlDumpCmd(credentials Credentials, extraArgs []string, invalidViews []discovery.View, excludedTables []string, schemas []string) *exec.Cmd {
 	cmd := baseCmd("mysqldump", credentials)
 
 	cmd.Args = append(cmd.Args,
@@ -57,11 +85,16 @@ func MySQLDumpCmd(credentials Credentials, invalidViews []discovery.View, schema
 		"--skip-triggers",
 		"--no-tablespaces",
 	)
+	cmd.Args = append(cmd.Args, extraArgs...)
 
 	for _, view := range invalidViews {
 		cmd.Args = append(cmd.Args, fmt.Sprintf("--ignore-table=%s", view))
 	}
 
+	for _, table := range excludedTables {
+		cmd.Args = append(cmd.Args, "--ignore-table="+table)
+	}
+
 	if len(schemas) > 1 {
 		cmd.Args = append(cmd.Args, "--databases")
 	}
@@ -70,15 +103,144 @@ func MySQLDumpCmd(credentials Credentials, invalidViews []discovery.View, schema
 	return cmd
 }
 
-func MySQLCmd(credentials Credentials) *exec.Cmd {
+// MySQLDumpStoredProgramsCmd dumps only the routines, triggers and events of the given schemas.
+// Table structure and data are expected to be copied by a preceding MySQLDumpCmd, and triggers of excluded tables
+// are skipped.
+func MySQLDumpStoredProgramsCmd(credentials Credentials, excludedTables []string, schemas ...string) *exec.Cmd {
+	cmd := baseCmd("mysqldump", credentials)
+
+	cmd.Args = append(cmd.Args,
+		"--max-allowed-packet=1G",
+		"--single-transaction",
+		"--routines",
+		"--events",
+		"--triggers",
+		"--set-gtid-purged=off",
+		"--no-create-db",
+		"--no-create-info",
+		"--no-data",
+		"--no-tablespaces",
+	)
+
+	for _, table := range excludedTables {
+		cmd.Args = append(cmd.Args, "--ignore-table="+table)
+	}
+
+	if len(schemas) > 1 {
+		cmd.Args = append(cmd.Args, "--databases")
+	}
+	cmd.Args = append(cmd.Args, schemas...)
+
+	return cmd
+}
+
+// MySQLDumpExportCmd dumps the given schemas, including their stored programs, as a dump that creates each of
+// them by name when it is loaded
+func MySQLDumpExportCmd(credentials Credentials, invalidViews []discovery.View, schemas ...string) *exec.Cmd {
+	cmd := baseCmd("mysqldump", credentials)
+
+	cmd.Args = append(cmd.Args,
+		"--max-allowed-packet=1G",
+		"--single-transaction",
+		"--routines",
+		"--events",
+		"--triggers",
+		"--set-gtid-purged=off",
+		"--no-tablespaces",
+	)
+
+	for _, view := range invalidViews {
+		cmd.Args = append(cmd.Args, fmt.Sprintf("--ignore-table=%s", view))
+	}
+
+	cmd.Args = append(cmd.Args, "--databases")
+	cmd.Args = append(cmd.Args, schemas...)
+
+	return cmd
+}
+
+// MySQLBinlogCmd decodes the changes to a single schema recorded in the given binlog files of the server, from the
+// start position in the first file up to the stop position in the last one. The changes are applied to
+// recipientSchema.
+func MySQLBinlogCmd(credentials Credentials, files []string, from, to BinlogPosition, schema, recipientSchema string) *exec.Cmd {
+	cmd := baseCmd("mysqlbinlog", credentials)
+
+	cmd.Args = append(cmd.Args,
+		"--read-from-remote-server",
+		"--skip-gtids",
+		fmt.Sprintf("--start-position=%d", from.Position),
+		fmt.Sprintf("--stop-position=%d", to.Position),
+	)
+
+	// --database filters on the schema name --rewrite-db produced
+	if recipientSchema != schema {
+		cmd.Args = append(cmd.Args, "--rewrite-db="+schema+"->"+recipientSchema)
+	}
+	cmd.Args = append(cmd.Args, "--database="+recipientSchema)
+	cmd.Args = append(cmd.Args, files...)
+
+	return cmd
+}
+
+func MySQLCmd(credentials Credentials, extraArgs ...string) *exec.Cmd {
 	cmd := baseCmd("mysql", credentials)
 
+	cmd.Args = append(cmd.Args, extraArgs...)
 	cmd.Args = append(cmd.Args, credentials.Name)
 	cmd.Stdout = os.Stdout
 
 	return cmd
 }
 
+// CopyTableData pipes the output of mysqldump straight into mysql. Unlike CopyData it does not rewrite definers,
+// since a dump of table rows contains no DDL.
+func CopyTableData(mysqldump, mysql *exec.Cmd, dumpProgress io.Writer, throttler *throttle.Throttle) error {
+	return pipeInto("mysqldump", mysqldump, mysql, dumpProgress, throttler, nil)
+}
+
+// ApplyBinlog pipes the changes decoded by mysqlbinlog into mysql
+func ApplyBinlog(mysqlbinlog, mysql *exec.Cmd) error {
+	return pipeInto("mysqlbinlog", mysqlbinlog, mysql, nil, nil, nil)
+}
+
+// pipeInto pipes the output of source into mysql, as fast as throttler allows. When filter is not nil, the output is
+// passed through it first, while sourceProgress still receives the unfiltered output.
+func pipeInto(sourceName string, source, mysql *exec.Cmd, sourceProgress io.Writer, throttler *throttle.Throttle, filter func(io.Reader) io.ReadCloser) error {
+	sourceOut, err := source.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("couldn't pipe the output of %s: %w", sourceName, err)
+	}
+
+	mysql.Stdin = teeProgress(throttler.Reader(sourceOut), sourceProgress)
+	if filter != nil {
+		filtered := filter(mysql.Stdin)
+		defer filtered.Close()
+		mysql.Stdin = filtered
+	}
+
+	if err := source.Start(); err != nil {
+		return fmt.Errorf("couldn't start %s: %w", sourceName, err)
+	}
+
+	if err := mysql.Start(); err != nil {
+		_ = source.Process.Kill()
+		_ = source.Wait()
+		return fmt.Errorf("couldn't start mysql: %w", err)
+	}
+
+	if err := mysql.Wait(); err != nil {
+		_ = source.Process.Kill()
+		_ = source.Wait()
+		return fmt.Errorf("mysql command failed: %w", err)
+	}
+
+	if err := source.Wait(); err != nil {
+		return fmt.Errorf("%s command failed: %w", sourceName, err)
+	}
+
+	return nil
+}
+
 func ValidateHost(credentials Credentials, timeout time.Duration) ([]string, error) {
 	timer := time.NewTimer(timeout)
 	defer timer.Stop()
@@ -107,55 +269,69 @@ func ValidateHost(credentials Credentials, timeout time.Duration) ([]string, err
 	}
 }
 
-func ReplaceDefinerCmd() *exec.Cmd {
-	args := []string{
-		"-e",
-		"s/DEFINER=.* SQL SECURITY .*/SQL SECURITY INVOKER/",
-	}
-
-	cmd := exec.Command("sed", args...)
-	cmd.Stderr = os.Stderr
-	return cmd
-}
-
-func CopyData(mysqldump, replaceDefinerCmd, mysql *exec.Cmd) error {
-	dumpOut, err := mysqldump.StdoutPipe()
+// ExportDump writes the output of mysqldump to w as a compressed dump, encrypted when passphrase is not empty. When
+// dumpProgress is not nil, the output of mysqldump is also written to it.
+func ExportDump(mysqldump *exec.Cmd, w io.Writer, passphrase string, dumpProgress io.Writer) error {
+	dump, err := archive.NewWriter(w, passphrase)
 	if err != nil {
-		return fmt.Errorf("couldn't pipe the output of mysqldump: %w", err)
+		return fmt.Errorf("failed to write the dump: %w", err)
 	}
 
-	replaceDefinerCmd.Stdin = dumpOut
-
-	replaceOut, err := replaceDefinerCmd.StdoutPipe()
-	if err != nil {
-		return fmt.Errorf("couldn't pipe the output of sed: %w", err)
+	mysqldump.Stdout = dump
+	if dumpProgress != nil {
+		mysqldump.Stdout = io.MultiWriter(dump, dumpProgress)
 	}
 
-	mysql.Stdin = replaceOut
-
 	if err := mysqldump.Start(); err != nil {
 		return fmt.Errorf("couldn't start mysqldump: %w", err)
 	}
 
-	if err := replaceDefinerCmd.Start(); err != nil {
-		return fmt.Errorf("couldn't start sed: %w", err)
+	if err := mysqldump.Wait(); err != nil {
+		return fmt.Errorf("mysqldump command failed: %w", err)
 	}
 
+	if err := dump.Close(); err != nil {
+		return fmt.Errorf("failed to write the dump: %w", err)
+	}
+
+	return nil
+}
+
+// ImportDump loads a dump into mysql, rewriting its definers and schemas. Statements read before a failure to read
+// the dump remain applied.
+func ImportDump(dump io.Reader, mysql *exec.Cmd, definers definer.Policy) error {
+	filtered := definer.NewReader(dump, definers)
+	defer filtered.Close()
+	mysql.Stdin = filtered
+
 	if err := mysql.Start(); err != nil {
 		return fmt.Errorf("couldn't start mysql: %w", err)
 	}
 
+	// Wait also returns the error reading the dump, once mysql exited successfully
 	if err := mysql.Wait(); err != nil {
-		return fmt.Errorf("mysql command failed: %w", err)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("mysql command failed: %w", err)
+		}
+		return fmt.Errorf("failed to read the dump: %w", err)
 	}
 
-	if err := replaceDefinerCmd.Wait(); err != nil {
-		return fmt.Errorf("sed command failed: %w", err)
-	}
+	return nil
+}
 
-	if err := mysqldump.Wait(); err != nil {
-		return fmt.Errorf("mysqldump command failed: %w", err)
+// CopyData pipes the output of mysqldump into mysql, rewriting the DEFINER clauses of views and stored programs
+// according to definers. When dumpProgress is not nil, the output of mysqldump is also written to it.
+func CopyData(mysqldump, mysql *exec.Cmd, definers definer.Policy, dumpProgress io.Writer, throttler *throttle.Throttle) error {
+	return pipeInto("mysqldump", mysqldump, mysql, dumpProgress, throttler, func(r io.Reader) io.ReadCloser {
+		return definer.NewReader(r, definers)
+	})
+}
+
+func teeProgress(dumpOut io.Reader, dumpProgress io.Writer) io.Reader {
+	if dumpProgress == nil {
+		return dumpOut
 	}
 
-	return nil
+	return io.TeeReader(dumpOut, dumpProgress)
 }
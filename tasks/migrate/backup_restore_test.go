@@ -14,13 +14,18 @@ package main
 
 import (
 	"bytes"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
+	"testing/iotest"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/go-binmock"
 
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
 	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
 )
 
@@ -41,7 +46,7 @@ var _ = Describe("Backup / Restore API", func() {
 	})
 
 	It("configures stdio", func() {
-		mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+		mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 		By("directing stderr to os.Stderr", func() {
 			Expect(mysqldump.Stderr).To(Equal(os.Stderr))
 		})
@@ -57,7 +62,7 @@ var _ = Describe("Backup / Restore API", func() {
 		})
 
 		It("adds the mysqldump --databases option", func() {
-			mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+			mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 			Expect(mysqldump).ToNot(BeNil())
 			Expect(mysqldump.Args).To(Equal([]string{
 				"mysqldump",
@@ -86,7 +91,7 @@ var _ = Describe("Backup / Restore API", func() {
 
 			When("SkipTLSValidation is not set", func() {
 				It("specifies the TLS options in the mysqldump command", func() {
-					mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+					mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 					Expect(mysqldump).ToNot(BeNil())
 					Expect(mysqldump.Args).To(Equal([]string{
 						"mysqldump",
@@ -117,7 +122,7 @@ var _ = Describe("Backup / Restore API", func() {
 				})
 
 				It("does not specify TLS options for mysqldump command", func() {
-					mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+					mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 					Expect(mysqldump).ToNot(BeNil())
 					Expect(mysqldump.Args).To(Equal([]string{
 						"mysqldump",
@@ -153,7 +158,7 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("add the mysqldump --ignore-table option the correct number of times", func() {
-				mysqldump := MySQLDumpCmd(credentials, invalidViews, schemas...)
+				mysqldump := MySQLDumpCmd(credentials, invalidViews, nil, schemas...)
 				Expect(mysqldump).ToNot(BeNil())
 				Expect(mysqldump.Args).To(Equal([]string{
 					"mysqldump",
@@ -178,6 +183,30 @@ var _ = Describe("Backup / Restore API", func() {
 				Expect(mysqldump.Env).To(ContainElement("MYSQL_PWD=some-password"))
 			})
 		})
+		When("tables are excluded", func() {
+			It("adds an --ignore-table option for each of them", func() {
+				mysqldump := MySQLDumpCmd(credentials, nil, []string{"foo.audit_log", "bar.audit_log"}, schemas...)
+				Expect(mysqldump.Args).To(Equal([]string{
+					"mysqldump",
+					"--user=some-user-name",
+					"--host=some-hostname",
+					"--port=3307",
+					"--max-allowed-packet=1G",
+					"--single-transaction",
+					"--skip-routines",
+					"--skip-events",
+					"--set-gtid-purged=off",
+					"--skip-triggers",
+					"--no-tablespaces",
+					"--ignore-table=foo.audit_log",
+					"--ignore-table=bar.audit_log",
+					"--databases",
+					"foo",
+					"bar",
+					"baz",
+				}))
+			})
+		})
 	})
 
 	When("dumping a single schema", func() {
@@ -186,7 +215,7 @@ var _ = Describe("Backup / Restore API", func() {
 		})
 
 		It("dumps only a single database without the --databases option", func() {
-			mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+			mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 			Expect(mysqldump).ToNot(BeNil())
 			Expect(mysqldump.Args).To(Equal([]string{
 				"mysqldump",
@@ -212,7 +241,7 @@ var _ = Describe("Backup / Restore API", func() {
 
 			When("SkipTLSValidation is not set", func() {
 				It("specifies the TLS options in the mysqldump command", func() {
-					mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+					mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 					Expect(mysqldump).ToNot(BeNil())
 					Expect(mysqldump.Args).To(Equal([]string{
 						"mysqldump",
@@ -240,7 +269,7 @@ var _ = Describe("Backup / Restore API", func() {
 				})
 
 				It("does not specify TLS options for the mysqldump command", func() {
-					mysqldump := MySQLDumpCmd(credentials, nil, schemas...)
+					mysqldump := MySQLDumpCmd(credentials, nil, nil, schemas...)
 					Expect(mysqldump).ToNot(BeNil())
 					Expect(mysqldump.Args).To(Equal([]string{
 						"mysqldump",
@@ -262,6 +291,165 @@ var _ = Describe("Backup / Restore API", func() {
 		})
 	})
 
+	Describe("MySQLDumpStoredProgramsCmd", func() {
+		It("dumps only routines, triggers and events", func() {
+			mysqldump := MySQLDumpStoredProgramsCmd(credentials, nil, "one-database")
+			Expect(mysqldump.Args).To(Equal([]string{
+				"mysqldump",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--max-allowed-packet=1G",
+				"--single-transaction",
+				"--routines",
+				"--events",
+				"--triggers",
+				"--set-gtid-purged=off",
+				"--no-create-db",
+				"--no-create-info",
+				"--no-data",
+				"--no-tablespaces",
+				"one-database",
+			}))
+			Expect(mysqldump.Env).To(ContainElement("MYSQL_PWD=some-password"))
+		})
+
+		When("dumping multiple schemas", func() {
+			It("adds the mysqldump --databases option", func() {
+				mysqldump := MySQLDumpStoredProgramsCmd(credentials, nil, "foo", "bar")
+				Expect(mysqldump.Args[len(mysqldump.Args)-3:]).To(Equal([]string{"--databases", "foo", "bar"}))
+			})
+		})
+	})
+
+	Describe("MySQLDumpExportCmd", func() {
+		It("dumps every schema by name, including stored programs", func() {
+			mysqldump := MySQLDumpExportCmd(credentials, []discovery.View{{Schema: "foo", TableName: "broken_view"}}, "foo")
+			Expect(mysqldump.Args).To(Equal([]string{
+				"mysqldump",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--max-allowed-packet=1G",
+				"--single-transaction",
+				"--routines",
+				"--events",
+				"--triggers",
+				"--set-gtid-purged=off",
+				"--no-tablespaces",
+				"--ignore-table=foo.broken_view",
+				"--databases",
+				"foo",
+			}))
+			Expect(mysqldump.Env).To(ContainElement("MYSQL_PWD=some-password"))
+		})
+	})
+
+	Describe("MySQLDumpSchemaCmd", func() {
+		It("dumps the structure of the schemas without any data", func() {
+			mysqldump := MySQLDumpSchemaCmd(credentials, nil, []string{"foo.audit_log"}, "foo", "bar")
+			Expect(mysqldump.Args).To(Equal([]string{
+				"mysqldump",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--max-allowed-packet=1G",
+				"--single-transaction",
+				"--skip-routines",
+				"--skip-events",
+				"--set-gtid-purged=off",
+				"--skip-triggers",
+				"--no-tablespaces",
+				"--no-data",
+				"--ignore-table=foo.audit_log",
+				"--databases",
+				"foo",
+				"bar",
+			}))
+		})
+	})
+
+	Describe("MySQLDumpTableDataCmd", func() {
+		It("dumps only the rows of a single table", func() {
+			mysqldump := MySQLDumpTableDataCmd(credentials, "foo", "actor")
+			Expect(mysqldump.Args).To(Equal([]string{
+				"mysqldump",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--max-allowed-packet=1G",
+				"--single-transaction",
+				"--skip-routines",
+				"--skip-events",
+				"--set-gtid-purged=off",
+				"--skip-triggers",
+				"--no-tablespaces",
+				"--no-create-info",
+				"foo",
+				"actor",
+			}))
+			Expect(mysqldump.Env).To(ContainElement("MYSQL_PWD=some-password"))
+		})
+	})
+
+	Describe("MySQLDumpWithBinlogPositionCmd", func() {
+		It("writes the binlog position of the dump as a comment", func() {
+			mysqldump := MySQLDumpWithBinlogPositionCmd(credentials, nil, nil, "foo")
+			Expect(mysqldump.Args).To(Equal([]string{
+				"mysqldump",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--max-allowed-packet=1G",
+				"--single-transaction",
+				"--skip-routines",
+				"--skip-events",
+				"--set-gtid-purged=off",
+				"--skip-triggers",
+				"--no-tablespaces",
+				"--master-data=2",
+				"foo",
+			}))
+		})
+	})
+
+	Describe("MySQLBinlogCmd", func() {
+		It("decodes the changes between two positions from the server", func() {
+			mysqlbinlog := MySQLBinlogCmd(credentials,
+				[]string{"mysql-bin.000001", "mysql-bin.000002"},
+				BinlogPosition{File: "mysql-bin.000001", Position: 154},
+				BinlogPosition{File: "mysql-bin.000002", Position: 2048},
+				"foo", "service_instance_db",
+			)
+			Expect(mysqlbinlog.Args).To(Equal([]string{
+				"mysqlbinlog",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--read-from-remote-server",
+				"--skip-gtids",
+				"--start-position=154",
+				"--stop-position=2048",
+				"--rewrite-db=foo->service_instance_db",
+				"--database=service_instance_db",
+				"mysql-bin.000001",
+				"mysql-bin.000002",
+			}))
+			Expect(mysqlbinlog.Env).To(ContainElement("MYSQL_PWD=some-password"))
+		})
+
+		It("only filters the schema when it keeps its name", func() {
+			mysqlbinlog := MySQLBinlogCmd(credentials,
+				[]string{"mysql-bin.000001"},
+				BinlogPosition{File: "mysql-bin.000001", Position: 154},
+				BinlogPosition{File: "mysql-bin.000001", Position: 2048},
+				"foo", "foo",
+			)
+			Expect(mysqlbinlog.Args).To(ContainElement("--database=foo"))
+			Expect(mysqlbinlog.Args).NotTo(ContainElement(HavePrefix("--rewrite-db")))
+		})
+	})
+
 	Describe("MySQLCmd", func() {
 		var credentials Credentials
 
@@ -299,6 +487,18 @@ var _ = Describe("Backup / Restore API", func() {
 			Expect(mysql.Env).To(ContainElement("MYSQL_PWD=some-password"))
 		})
 
+		It("places extra arguments before the database name", func() {
+			mysql := MySQLCmd(credentials, "--force")
+			Expect(mysql.Args).To(Equal([]string{
+				"mysql",
+				"--user=some-user-name",
+				"--host=some-hostname",
+				"--port=3307",
+				"--force",
+				"some-db-name",
+			}))
+		})
+
 		When("TLS is enabled", func() {
 			BeforeEach(func() {
 				credentials.CA = "-----BEGIN CERTIFICATE-----\nMIIDcTCCAlmgAwIBAgIUdOO5sOa14a6sjU8/3BdrH5wgY7wwDQYJKoZIhvcNAQEL\nBQAwJjEkMCIGA1UEAxMbZG0tcm9vdC5kZWRpY2F0ZWQtbXlzcWwuY29tMB4XDTE4\nMDEyNTE3NDI0M1oXDTE5MDEyNTE3NDI0M1owJjEkMCIGA1UEAxMbZG0tcm9vdC5k\nZWRpY2F0ZWQtbXlzcWwuY29tMIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKC\nAQEAw2f9mjCtEHnjNUrNPxk9K2GXrBEOd6FT5RQOzQ9hN64OQp2q9sqJ3sQDuxhv\nqj8H5neaKmpz9yYQERUol1j+lIcZz2XSySAIEl9gwj2Ifj7W8RZZ2zLgu2atqXjG\n0/Kx74gwT3DssktXctDmTA9qvRHggvkafUJDsqFixAVtd3vuX+73qfonn79ACnBR\n8w5/wCoh5JW449w7v7Ix1tlPEaN1PK82yUgJdW2jOSQ3FQfgwJGCt45qFQSpNYok\n1CmmZ9m0ZtMNCYThsfInU4kWPNigH6dekmrJQwO4Q84h0EmMMebUeaP6havS7gT3\nEsNpeIQvm+aUdDLXllFCx52npwIDAQABo4GWMIGTMB0GA1UdDgQWBBTSVUBoLjWu\n1axkj373gNzrq1QGuzBhBgNVHSMEWjBYgBTSVUBoLjWu1axkj373gNzrq1QGu6Eq\npCgwJjEkMCIGA1UEAxMbZG0tcm9vdC5kZWRpY2F0ZWQtbXlzcWwuY29tghR047mw\n5rXhrqyNTz/cF2sfnCBjvDAPBgNVHRMBAf8EBTADAQH/MA0GCSqGSIb3DQEBCwUA\nA4IBAQBgoGK9SOECIEssWcd0bQrJrTJGH6ZXzDLMxalpXoGockpvX0awAFNDJ654\nGezOBAJ7TPmDLdRDZFtITwP6Bjaz0HeLz5bkaFsiDyJxkULRgI2kYI9pADu9Uo74\nk6CgIaupoBHrRXR7aVGrWYeN840IFSZB1TCnrCuPne4UVEzGsTnFfUjyOgs0Mqo6\nqAkD6ZTVUPu0SwBDoY2TWD1UuH4rOIDwWzVV7u3vY6HY7rFtOGhiNHdiav7RjpsY\nXmsHnVSaa+5iOgi04VsOF3JhpxuvbdMmEe+sOfBmjv+NwNR+ngXelyCzvR7w74NQ\ndjPvEZQgEt7w6DpovGp8cwtKIMAx\n-----END CERTIFICATE-----\n"
@@ -342,59 +542,162 @@ var _ = Describe("Backup / Restore API", func() {
 		})
 	})
 
-	Describe("ReplaceDefinerCmd", func() {
-		It("builds the sed command", func() {
-			replace := ReplaceDefinerCmd()
-			Expect(replace).ToNot(BeNil())
-			Expect(replace.Args).To(Equal([]string{
-				"sed",
-				"-e",
-				"s/DEFINER=.* SQL SECURITY .*/SQL SECURITY INVOKER/",
+	Describe("ExportDump", func() {
+		var (
+			mySQLDumpMock *binmock.Mock
+			mySQLDumpCmd  *exec.Cmd
+		)
+
+		BeforeEach(func() {
+			mySQLDumpMock = binmock.NewBinMock(Fail)
+			mySQLDumpMock.
+				WhenCalled().
+				WillPrintToStdOut("CREATE DATABASE `foo`;").
+				WillExitWith(0)
+			mySQLDumpCmd = exec.Command(mySQLDumpMock.Path)
+		})
+
+		readDump := func(dump []byte, passphrase string) string {
+			r, err := archive.NewReader(bytes.NewReader(dump), passphrase)
+			Expect(err).NotTo(HaveOccurred())
+			out, err := io.ReadAll(r)
+			Expect(err).NotTo(HaveOccurred())
+			return string(out)
+		}
+
+		It("writes the output of mysqldump as a compressed dump", func() {
+			var dump, dumpProgress bytes.Buffer
+			Expect(ExportDump(mySQLDumpCmd, &dump, "", &dumpProgress)).To(Succeed())
+
+			Expect(readDump(dump.Bytes(), "")).To(Equal("CREATE DATABASE `foo`;"))
+			Expect(dumpProgress.String()).To(Equal("CREATE DATABASE `foo`;"))
+		})
+
+		It("encrypts the dump with a passphrase", func() {
+			var dump bytes.Buffer
+			Expect(ExportDump(mySQLDumpCmd, &dump, "secret", nil)).To(Succeed())
+
+			_, err := archive.NewReader(bytes.NewReader(dump.Bytes()), "")
+			Expect(err).To(MatchError(archive.ErrPassphraseRequired))
+			Expect(readDump(dump.Bytes(), "secret")).To(Equal("CREATE DATABASE `foo`;"))
+		})
+
+		When("the mysqldump command fails", func() {
+			BeforeEach(func() {
+				mySQLDumpMock.Reset()
+				mySQLDumpMock.WhenCalled().WillExitWith(1)
+			})
+
+			It("returns an error", func() {
+				Expect(ExportDump(mySQLDumpCmd, io.Discard, "", nil)).
+					To(MatchError("mysqldump command failed: exit status 1"))
+			})
+		})
+
+		When("starting the mysqldump command fails", func() {
+			BeforeEach(func() {
+				mySQLDumpCmd.Path = "/invalid/path/to/mysqldump"
+			})
+
+			It("returns an error", func() {
+				Expect(ExportDump(mySQLDumpCmd, io.Discard, "", nil)).
+					To(MatchError(`couldn't start mysqldump: fork/exec /invalid/path/to/mysqldump: no such file or directory`))
+			})
+		})
+	})
+
+	Describe("ImportDump", func() {
+		var (
+			mySQLMock *binmock.Mock
+			mySQLCmd  *exec.Cmd
+			dump      string
+		)
+
+		BeforeEach(func() {
+			dump = "USE `sakila`;\nCREATE DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v` AS SELECT * FROM `sakila`.`t`;"
+
+			mySQLMock = binmock.NewBinMock(Fail)
+			mySQLMock.WhenCalled().WillExitWith(0)
+			mySQLCmd = exec.Command(mySQLMock.Path)
+		})
+
+		It("loads the dump into mysql, rewriting definers and schemas", func() {
+			policy := definer.Policy{Schemas: map[string]string{"sakila": "pagila"}}
+			Expect(ImportDump(strings.NewReader(dump), mySQLCmd, policy)).To(Succeed())
+
+			Expect(mySQLMock.Invocations()).To(HaveLen(1))
+			Expect(mySQLMock.Invocations()[0].Stdin()).To(Equal([]string{
+				"USE `pagila`;",
+				"CREATE SQL SECURITY INVOKER VIEW `v` AS SELECT * FROM `pagila`.`t`;",
 			}))
 		})
+
+		It("returns an error when the dump can not be read", func() {
+			err := ImportDump(iotest.ErrReader(archive.ErrTruncated), mySQLCmd, definer.Policy{})
+			Expect(err).To(MatchError("failed to read the dump: the dump is truncated"))
+			Expect(err).To(MatchError(archive.ErrTruncated))
+		})
+
+		It("returns an error when starting mysql fails", func() {
+			mySQLCmd.Path = "/invalid/path/to/mysql"
+
+			Expect(ImportDump(strings.NewReader(dump), mySQLCmd, definer.Policy{})).
+				To(MatchError(`couldn't start mysql: fork/exec /invalid/path/to/mysql: no such file or directory`))
+		})
+
+		It("returns an error when mysql fails", func() {
+			mySQLMock.Reset()
+			mySQLMock.WhenCalled().WillExitWith(1)
+
+			Expect(ImportDump(strings.NewReader(dump), mySQLCmd, definer.Policy{})).
+				To(MatchError("mysql command failed: exit status 1"))
+		})
 	})
 
 	Describe("CopyData", func() {
 		var (
-			mySQLDumpMock      *binmock.Mock
-			mySQLDumpCmd       *exec.Cmd
-			mySQLMock          *binmock.Mock
-			mySQLCmd           *exec.Cmd
-			replaceDefinerMock *binmock.Mock
-			replaceDefinerCmd  *exec.Cmd
+			mySQLDumpMock *binmock.Mock
+			mySQLDumpCmd  *exec.Cmd
+			mySQLMock     *binmock.Mock
+			mySQLCmd      *exec.Cmd
 		)
 
 		BeforeEach(func() {
 			mySQLDumpMock = binmock.NewBinMock(Fail)
 			mySQLDumpMock.
 				WhenCalled().
-				WillPrintToStdOut(`something`).
+				WillPrintToStdOut("CREATE DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v` AS SELECT 'DEFINER=`root`@`%`';").
 				WillExitWith(0)
 			mySQLDumpCmd = exec.Command(mySQLDumpMock.Path)
 
-			replaceDefinerMock = binmock.NewBinMock(Fail)
-			replaceDefinerMock.
-				WhenCalled().
-				WillPrintToStdOut(`replaced`).
-				WillExitWith(0)
-			replaceDefinerCmd = exec.Command(replaceDefinerMock.Path)
-
 			mySQLMock = binmock.NewBinMock(Fail)
 			mySQLMock.WhenCalled().WillExitWith(0)
 			mySQLCmd = exec.Command(mySQLMock.Path)
 		})
 
-		It("Pipes the output of the mySQLDumpCmd command into the mySQLCmd command", func() {
-			Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).To(Succeed())
+		It("pipes the output of mysqldump into mysql, converting views to SQL SECURITY INVOKER", func() {
+			Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).To(Succeed())
 
 			Expect(mySQLDumpMock.Invocations()).To(HaveLen(1))
-			Expect(replaceDefinerMock.Invocations()).To(HaveLen(1))
 			Expect(mySQLMock.Invocations()).To(HaveLen(1))
 
-			Expect(replaceDefinerMock.Invocations()[0].Stdin()).
-				To(ConsistOf(`something`))
 			Expect(mySQLMock.Invocations()[0].Stdin()).
-				To(ConsistOf(`replaced`))
+				To(ConsistOf("CREATE SQL SECURITY INVOKER VIEW `v` AS SELECT 'DEFINER=`root`@`%`';"))
+		})
+
+		It("maps definers to the account of the policy", func() {
+			Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{Definer: "`app`@`%`"}, nil, nil)).To(Succeed())
+
+			Expect(mySQLMock.Invocations()[0].Stdin()).
+				To(ConsistOf("CREATE DEFINER=`app`@`%` SQL SECURITY DEFINER VIEW `v` AS SELECT 'DEFINER=`root`@`%`';"))
+		})
+
+		It("writes the unfiltered output of mysqldump to dumpProgress", func() {
+			var dumpProgress bytes.Buffer
+			Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, &dumpProgress, nil)).To(Succeed())
+
+			Expect(dumpProgress.String()).
+				To(Equal("CREATE DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v` AS SELECT 'DEFINER=`root`@`%`';"))
 		})
 
 		When("piping the output of mysqldump fails", func() {
@@ -411,7 +714,7 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("returns an error", func() {
-				Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).
+				Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).
 					To(MatchError(`mysqldump command failed: exit status 1`))
 			})
 		})
@@ -422,7 +725,7 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("returns an error", func() {
-				Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).
+				Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).
 					To(MatchError(`couldn't start mysqldump: fork/exec /invalid/path/to/mysqldump: no such file or directory`))
 			})
 		})
@@ -433,7 +736,7 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("returns an error", func() {
-				Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).
+				Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).
 					To(MatchError(`couldn't start mysql: fork/exec /invalid/path/to/mysql: no such file or directory`))
 			})
 		})
@@ -445,7 +748,7 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("returns an error", func() {
-				Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).
+				Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).
 					To(MatchError("mysqldump command failed: exit status 1"))
 			})
 		})
@@ -457,7 +760,7 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("returns an error", func() {
-				Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).
+				Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).
 					To(MatchError("mysql command failed: exit status 1"))
 			})
 		})
@@ -468,9 +771,117 @@ var _ = Describe("Backup / Restore API", func() {
 			})
 
 			It("returns an error", func() {
-				Expect(CopyData(mySQLDumpCmd, replaceDefinerCmd, mySQLCmd)).
+				Expect(CopyData(mySQLDumpCmd, mySQLCmd, definer.Policy{}, nil, nil)).
 					To(MatchError("couldn't pipe the output of mysqldump: exec: Stdout already set"))
 			})
 		})
 	})
+
+	Describe("CopyTableData", func() {
+		var (
+			mySQLDumpMock *binmock.Mock
+			mySQLDumpCmd  *exec.Cmd
+			mySQLMock     *binmock.Mock
+			mySQLCmd      *exec.Cmd
+		)
+
+		BeforeEach(func() {
+			mySQLDumpMock = binmock.NewBinMock(Fail)
+			mySQLDumpMock.
+				WhenCalled().
+				WillPrintToStdOut(`INSERT INTO actor VALUES (1)`).
+				WillExitWith(0)
+			mySQLDumpCmd = exec.Command(mySQLDumpMock.Path)
+
+			mySQLMock = binmock.NewBinMock(Fail)
+			mySQLMock.WhenCalled().WillExitWith(0)
+			mySQLCmd = exec.Command(mySQLMock.Path)
+		})
+
+		It("pipes the output of mysqldump into mysql", func() {
+			Expect(CopyTableData(mySQLDumpCmd, mySQLCmd, nil, nil)).To(Succeed())
+
+			Expect(mySQLMock.Invocations()).To(HaveLen(1))
+			Expect(mySQLMock.Invocations()[0].Stdin()).To(ConsistOf(`INSERT INTO actor VALUES (1)`))
+		})
+
+		It("writes the output of mysqldump to dumpProgress", func() {
+			var dumpProgress bytes.Buffer
+			Expect(CopyTableData(mySQLDumpCmd, mySQLCmd, &dumpProgress, nil)).To(Succeed())
+
+			Expect(dumpProgress.String()).To(Equal(`INSERT INTO actor VALUES (1)`))
+		})
+
+		When("the mysqldump command fails", func() {
+			BeforeEach(func() {
+				mySQLDumpMock.Reset()
+				mySQLDumpMock.WhenCalled().WillExitWith(1)
+			})
+
+			It("returns an error", func() {
+				Expect(CopyTableData(mySQLDumpCmd, mySQLCmd, nil, nil)).
+					To(MatchError("mysqldump command failed: exit status 1"))
+			})
+		})
+
+		When("the mysql command fails", func() {
+			BeforeEach(func() {
+				mySQLMock.Reset()
+				mySQLMock.WhenCalled().WillExitWith(1)
+			})
+
+			It("returns an error", func() {
+				Expect(CopyTableData(mySQLDumpCmd, mySQLCmd, nil, nil)).
+					To(MatchError("mysql command failed: exit status 1"))
+			})
+		})
+
+		When("starting the mysql command fails", func() {
+			BeforeEach(func() {
+				mySQLCmd.Path = "/invalid/path/to/mysql"
+			})
+
+			It("returns an error", func() {
+				Expect(CopyTableData(mySQLDumpCmd, mySQLCmd, nil, nil)).
+					To(MatchError(`couldn't start mysql: fork/exec /invalid/path/to/mysql: no such file or directory`))
+			})
+		})
+	})
+
+	Describe("ApplyBinlog", func() {
+		var (
+			mySQLBinlogMock *binmock.Mock
+			mySQLMock       *binmock.Mock
+		)
+
+		BeforeEach(func() {
+			mySQLBinlogMock = binmock.NewBinMock(Fail)
+			mySQLBinlogMock.
+				WhenCalled().
+				WillPrintToStdOut(`BINLOG 'some-event'`).
+				WillExitWith(0)
+
+			mySQLMock = binmock.NewBinMock(Fail)
+			mySQLMock.WhenCalled().WillExitWith(0)
+		})
+
+		It("pipes the output of mysqlbinlog into mysql", func() {
+			Expect(ApplyBinlog(exec.Command(mySQLBinlogMock.Path), exec.Command(mySQLMock.Path))).To(Succeed())
+
+			Expect(mySQLMock.Invocations()).To(HaveLen(1))
+			Expect(mySQLMock.Invocations()[0].Stdin()).To(ConsistOf(`BINLOG 'some-event'`))
+		})
+
+		When("the mysqlbinlog command fails", func() {
+			BeforeEach(func() {
+				mySQLBinlogMock.Reset()
+				mySQLBinlogMock.WhenCalled().WillExitWith(1)
+			})
+
+			It("returns an error", func() {
+				Expect(ApplyBinlog(exec.Command(mySQLBinlogMock.Path), exec.Command(mySQLMock.Path))).
+					To(MatchError("mysqlbinlog command failed: exit status 1"))
+			})
+		})
+	})
 })
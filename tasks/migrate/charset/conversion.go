@@ -0,0 +1,190 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package charset
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// Conversion converts the character set and collation of the schemas, tables and columns copied to the recipient.
+// Columns with a binary collation keep comparing as binary, using the _bin collation of the new character set.
+type Conversion struct {
+	Charset   string
+	Collation string
+	// MaxBytesPerChar is the width of the widest character of Charset
+	MaxBytesPerChar int
+}
+
+// Load validates the character set and collation against those the recipient supports. An empty collation is the
+// default collation of the character set on the recipient.
+func Load(recipient *sql.DB, charset, collation string) (*Conversion, error) {
+	c := Conversion{Charset: charset}
+
+	var defaultCollation string
+	err := recipient.QueryRow(`SELECT MAXLEN, DEFAULT_COLLATE_NAME FROM INFORMATION_SCHEMA.CHARACTER_SETS WHERE CHARACTER_SET_NAME = ?`, charset).
+		Scan(&c.MaxBytesPerChar, &defaultCollation)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("character set %q is not supported by the recipient", charset)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up character set %q on the recipient: %w", charset, err)
+	}
+
+	if collation == "" {
+		c.Collation = defaultCollation
+		return &c, nil
+	}
+
+	var found int
+	if err := recipient.QueryRow(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.COLLATIONS WHERE COLLATION_NAME = ? AND CHARACTER_SET_NAME = ?`, collation, charset).Scan(&found); err != nil {
+		return nil, fmt.Errorf("failed to look up collation %q on the recipient: %w", collation, err)
+	}
+
+	if found == 0 {
+		return nil, fmt.Errorf("collation %q of character set %q is not supported by the recipient", collation, charset)
+	}
+	c.Collation = collation
+
+	return &c, nil
+}
+
+func (c *Conversion) String() string {
+	return c.Charset + " (" + c.Collation + ")"
+}
+
+// Rewrite converts the CHARACTER SET, CHARSET and COLLATE clauses of a CREATE DATABASE or CREATE TABLE statement,
+// such as the output of SHOW CREATE TABLE. Strings and quoted identifiers, like comments and defaults, are left
+// unchanged. A nil Conversion returns the statement unchanged.
+func (c *Conversion) Rewrite(statement string) string {
+	if c == nil {
+		return statement
+	}
+
+	tokens := tokenize(statement)
+
+	var out strings.Builder
+	for i := 0; i < len(tokens); i++ {
+		out.WriteString(tokens[i].text)
+
+		if tokens[i].kind != word {
+			continue
+		}
+
+		switch strings.ToUpper(tokens[i].text) {
+		case "CHARSET", "CHARACTER":
+			if strings.ToUpper(tokens[i].text) == "CHARACTER" {
+				next := nextWord(tokens, i+1)
+				if next < 0 || strings.ToUpper(tokens[next].text) != "SET" {
+					continue
+				}
+				copyRange(&out, tokens, i+1, next+1)
+				i = next
+			}
+
+			name := nextWord(tokens, i+1)
+			if name < 0 || strings.EqualFold(tokens[name].text, "binary") {
+				continue
+			}
+			copyRange(&out, tokens, i+1, name)
+			out.WriteString(c.Charset)
+			i = name
+
+			// Without a COLLATE clause, the column or table uses the default collation of its character set
+			if next := nextWord(tokens, i+1); next < 0 || strings.ToUpper(tokens[next].text) != "COLLATE" {
+				out.WriteString(" COLLATE " + c.Collation)
+			}
+		case "COLLATE":
+			name := nextWord(tokens, i+1)
+			if name < 0 {
+				continue
+			}
+			copyRange(&out, tokens, i+1, name)
+			out.WriteString(c.collationFor(tokens[name].text))
+			i = name
+		}
+	}
+
+	return out.String()
+}
+
+// collationFor returns the collation replacing collation, keeping binary collations binary
+func (c *Conversion) collationFor(collation string) string {
+	if strings.HasSuffix(strings.ToLower(collation), "_bin") {
+		return c.Charset + "_bin"
+	}
+
+	return c.Collation
+}
+
+type tokenKind int
+
+const (
+	other tokenKind = iota
+	word
+	quoted
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(statement string) []token {
+	var tokens []token
+
+	for i := 0; i < len(statement); {
+		start := i
+		switch c := statement[i]; {
+		case discovery.IsQuote(c):
+			i = discovery.SkipQuoted(statement, i)
+			tokens = append(tokens, token{quoted, statement[start:i]})
+		case discovery.IsWordByte(c):
+			for i < len(statement) && discovery.IsWordByte(statement[i]) {
+				i++
+			}
+			tokens = append(tokens, token{word, statement[start:i]})
+		default:
+			i++
+			tokens = append(tokens, token{other, statement[start:i]})
+		}
+	}
+
+	return tokens
+}
+
+// nextWord returns the index of the first token from i on that is not whitespace or an equals sign, if it is a word
+func nextWord(tokens []token, i int) int {
+	for ; i < len(tokens); i++ {
+		switch {
+		case tokens[i].kind == word:
+			return i
+		case tokens[i].kind == other && (tokens[i].text == "=" || strings.TrimSpace(tokens[i].text) == ""):
+			continue
+		}
+
+		return -1
+	}
+
+	return -1
+}
+
+func copyRange(out *strings.Builder, tokens []token, from, to int) {
+	for _, t := range tokens[from:to] {
+		out.WriteString(t.text)
+	}
+}
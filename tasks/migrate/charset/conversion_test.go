@@ -0,0 +1,171 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package charset_test
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+)
+
+var _ = Describe("Conversion", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("Load", func() {
+		It("validates the character set and collation on the recipient", func() {
+			mock.ExpectQuery(`SELECT MAXLEN, DEFAULT_COLLATE_NAME FROM INFORMATION_SCHEMA.CHARACTER_SETS WHERE CHARACTER_SET_NAME = \?`).
+				WithArgs("utf8mb4").
+				WillReturnRows(sqlmock.NewRows([]string{"MAXLEN", "DEFAULT_COLLATE_NAME"}).AddRow(4, "utf8mb4_0900_ai_ci"))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM INFORMATION_SCHEMA.COLLATIONS WHERE COLLATION_NAME = \? AND CHARACTER_SET_NAME = \?`).
+				WithArgs("utf8mb4_unicode_ci", "utf8mb4").
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(1))
+
+			c, err := charset.Load(db, "utf8mb4", "utf8mb4_unicode_ci")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c).To(Equal(&charset.Conversion{Charset: "utf8mb4", Collation: "utf8mb4_unicode_ci", MaxBytesPerChar: 4}))
+		})
+
+		It("defaults to the default collation of the character set", func() {
+			mock.ExpectQuery(`SELECT MAXLEN, DEFAULT_COLLATE_NAME FROM INFORMATION_SCHEMA.CHARACTER_SETS`).
+				WithArgs("utf8mb4").
+				WillReturnRows(sqlmock.NewRows([]string{"MAXLEN", "DEFAULT_COLLATE_NAME"}).AddRow(4, "utf8mb4_0900_ai_ci"))
+
+			c, err := charset.Load(db, "utf8mb4", "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Collation).To(Equal("utf8mb4_0900_ai_ci"))
+		})
+
+		It("rejects a character set the recipient does not support", func() {
+			mock.ExpectQuery(`SELECT MAXLEN, DEFAULT_COLLATE_NAME FROM INFORMATION_SCHEMA.CHARACTER_SETS`).
+				WithArgs("utf9").
+				WillReturnRows(sqlmock.NewRows([]string{"MAXLEN", "DEFAULT_COLLATE_NAME"}))
+
+			_, err := charset.Load(db, "utf9", "")
+			Expect(err).To(MatchError(`character set "utf9" is not supported by the recipient`))
+		})
+
+		It("rejects a collation of another character set", func() {
+			mock.ExpectQuery(`SELECT MAXLEN, DEFAULT_COLLATE_NAME FROM INFORMATION_SCHEMA.CHARACTER_SETS`).
+				WithArgs("utf8mb4").
+				WillReturnRows(sqlmock.NewRows([]string{"MAXLEN", "DEFAULT_COLLATE_NAME"}).AddRow(4, "utf8mb4_general_ci"))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM INFORMATION_SCHEMA.COLLATIONS`).
+				WithArgs("latin1_swedish_ci", "utf8mb4").
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(0))
+
+			_, err := charset.Load(db, "utf8mb4", "latin1_swedish_ci")
+			Expect(err).To(MatchError(`collation "latin1_swedish_ci" of character set "utf8mb4" is not supported by the recipient`))
+		})
+
+		It("returns an error when the lookup fails", func() {
+			mock.ExpectQuery(`SELECT MAXLEN, DEFAULT_COLLATE_NAME FROM INFORMATION_SCHEMA.CHARACTER_SETS`).
+				WillReturnError(errors.New("some error"))
+
+			_, err := charset.Load(db, "utf8mb4", "")
+			Expect(err).To(MatchError(`failed to look up character set "utf8mb4" on the recipient: some error`))
+		})
+	})
+
+	Context("Rewrite", func() {
+		conversion := &charset.Conversion{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci", MaxBytesPerChar: 4}
+
+		DescribeTable("converts character set and collation clauses",
+			func(statement, expected string) {
+				Expect(conversion.Rewrite(statement)).To(Equal(expected))
+			},
+			Entry("a schema",
+				"CREATE DATABASE `app` /*!40100 DEFAULT CHARACTER SET latin1 */",
+				"CREATE DATABASE `app` /*!40100 DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci */",
+			),
+			Entry("a schema with a collation",
+				"CREATE DATABASE `app` /*!40100 DEFAULT CHARACTER SET utf8 COLLATE utf8_unicode_ci */",
+				"CREATE DATABASE `app` /*!40100 DEFAULT CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci */",
+			),
+			Entry("a table and its columns",
+				"CREATE TABLE `users` (\n"+
+					"  `id` int(11) NOT NULL,\n"+
+					"  `name` varchar(255) NOT NULL,\n"+
+					"  `login` varchar(64) CHARACTER SET latin1 COLLATE latin1_bin DEFAULT NULL,\n"+
+					"  `code` char(2) CHARACTER SET ascii NOT NULL,\n"+
+					"  `hash` varbinary(32) DEFAULT NULL,\n"+
+					"  PRIMARY KEY (`id`)\n"+
+					") ENGINE=InnoDB DEFAULT CHARSET=latin1 COMMENT='CHARSET=latin1'",
+				"CREATE TABLE `users` (\n"+
+					"  `id` int(11) NOT NULL,\n"+
+					"  `name` varchar(255) NOT NULL,\n"+
+					"  `login` varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin DEFAULT NULL,\n"+
+					"  `code` char(2) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL,\n"+
+					"  `hash` varbinary(32) DEFAULT NULL,\n"+
+					"  PRIMARY KEY (`id`)\n"+
+					") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_0900_ai_ci COMMENT='CHARSET=latin1'",
+			),
+			Entry("a table with a collation",
+				"CREATE TABLE `t` (`a` text COLLATE utf8_bin) ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_unicode_ci",
+				"CREATE TABLE `t` (`a` text COLLATE utf8mb4_bin) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
+			),
+			Entry("identifiers and strings named like the clauses",
+				"CREATE TABLE `charset` (`collate` varchar(3) DEFAULT 'CHARACTER SET latin1') ENGINE=InnoDB DEFAULT CHARSET=latin1",
+				"CREATE TABLE `charset` (`collate` varchar(3) DEFAULT 'CHARACTER SET latin1') ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+			),
+			Entry("the binary character set",
+				"CREATE TABLE `t` (`a` varchar(3) CHARACTER SET binary) ENGINE=InnoDB DEFAULT CHARSET=latin1",
+				"CREATE TABLE `t` (`a` varchar(3) CHARACTER SET binary) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+			),
+		)
+
+		It("leaves statements unchanged without a conversion", func() {
+			var none *charset.Conversion
+			Expect(none.Rewrite("CREATE DATABASE `app` /*!40100 DEFAULT CHARACTER SET latin1 */")).To(Equal("CREATE DATABASE `app` /*!40100 DEFAULT CHARACTER SET latin1 */"))
+		})
+	})
+
+	Context("FindIndexOverflows", func() {
+		It("returns the indexes over the InnoDB limits once converted", func() {
+			conversion := &charset.Conversion{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci", MaxBytesPerChar: 4}
+
+			mock.ExpectQuery(`SELECT s.TABLE_SCHEMA, s.TABLE_NAME, s.INDEX_NAME, COALESCE\(s.SUB_PART, c.CHARACTER_MAXIMUM_LENGTH\), t.CREATE_OPTIONS FROM INFORMATION_SCHEMA.STATISTICS s .* WHERE s.TABLE_SCHEMA IN \(\?,\?\) AND s.INDEX_TYPE = 'BTREE' AND c.CHARACTER_SET_NAME IS NOT NULL`).
+				WithArgs("app", "other").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "INDEX_NAME", "LENGTH", "CREATE_OPTIONS"}).
+					AddRow("app", "legacy", "name", 255, "row_format=COMPACT").
+					AddRow("app", "users", "PRIMARY", 255, "").
+					AddRow("app", "users", "name_email", 500, "").
+					AddRow("app", "users", "name_email", 300, "").
+					AddRow("other", "posts", "slug", 191, nil))
+
+			overflows, err := charset.FindIndexOverflows(db, []string{"app", "other"}, conversion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(overflows).To(Equal([]charset.IndexOverflow{
+				{Schema: "app", Table: "legacy", Index: "name", Bytes: 1020, Limit: 767},
+				{Schema: "app", Table: "users", Index: "name_email", Bytes: 3200, Limit: 3072},
+			}))
+			Expect(overflows[1].String()).To(Equal("index name_email of app.users is 3200 bytes once converted, over the limit of 3072 bytes"))
+		})
+	})
+})
@@ -0,0 +1,113 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package charset
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxIndexBytes is the size limit of an InnoDB index key, and of each of its columns, in the DYNAMIC and
+	// COMPRESSED row formats
+	maxIndexBytes = 3072
+	// maxCompactKeyPartBytes is the size limit of each column of an index in the COMPACT and REDUNDANT row formats
+	maxCompactKeyPartBytes = 767
+)
+
+// IndexOverflow is an index whose text columns grow over the size limit of InnoDB once converted
+type IndexOverflow struct {
+	Schema string
+	Table  string
+	Index  string
+	Bytes  int
+	Limit  int
+}
+
+func (o IndexOverflow) String() string {
+	return fmt.Sprintf("index %s of %s.%s is %d bytes once converted, over the limit of %d bytes", o.Index, o.Schema, o.Table, o.Bytes, o.Limit)
+}
+
+// FindIndexOverflows returns the indexes of the given schemas that exceed the size limits of InnoDB once their text
+// columns use the character set of the conversion
+func FindIndexOverflows(db *sql.DB, schemas []string, c *Conversion) ([]IndexOverflow, error) {
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(schemas))
+	for i, s := range schemas {
+		args[i] = s
+	}
+
+	rows, err := db.Query(`SELECT s.TABLE_SCHEMA, s.TABLE_NAME, s.INDEX_NAME, COALESCE(s.SUB_PART, c.CHARACTER_MAXIMUM_LENGTH), t.CREATE_OPTIONS `+
+		`FROM INFORMATION_SCHEMA.STATISTICS s `+
+		`JOIN INFORMATION_SCHEMA.COLUMNS c ON c.TABLE_SCHEMA = s.TABLE_SCHEMA AND c.TABLE_NAME = s.TABLE_NAME AND c.COLUMN_NAME = s.COLUMN_NAME `+
+		`JOIN INFORMATION_SCHEMA.TABLES t ON t.TABLE_SCHEMA = s.TABLE_SCHEMA AND t.TABLE_NAME = s.TABLE_NAME `+
+		`WHERE s.TABLE_SCHEMA IN (`+strings.TrimSuffix(strings.Repeat("?,", len(schemas)), ",")+`) AND s.INDEX_TYPE = 'BTREE' AND c.CHARACTER_SET_NAME IS NOT NULL `+
+		`ORDER BY s.TABLE_SCHEMA, s.TABLE_NAME, s.INDEX_NAME, s.SEQ_IN_INDEX`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the indexes on text columns: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var (
+		overflows []IndexOverflow
+		current   IndexOverflow
+		compact   bool
+		largest   int
+	)
+
+	check := func() {
+		switch {
+		case current.Index == "":
+		case compact && largest > maxCompactKeyPartBytes:
+			current.Bytes, current.Limit = largest, maxCompactKeyPartBytes
+			overflows = append(overflows, current)
+		case current.Bytes > maxIndexBytes:
+			current.Limit = maxIndexBytes
+			overflows = append(overflows, current)
+		}
+	}
+
+	for rows.Next() {
+		var (
+			schema, table, index string
+			chars                int
+			createOptions        sql.NullString
+		)
+		if err := rows.Scan(&schema, &table, &index, &chars, &createOptions); err != nil {
+			return nil, fmt.Errorf("failed to scan the indexes on text columns: %w", err)
+		}
+
+		if schema != current.Schema || table != current.Table || index != current.Index {
+			check()
+			current = IndexOverflow{Schema: schema, Table: table, Index: index}
+			options := strings.ToUpper(createOptions.String)
+			compact = strings.Contains(options, "ROW_FORMAT=COMPACT") || strings.Contains(options, "ROW_FORMAT=REDUNDANT")
+			largest = 0
+		}
+
+		bytes := chars * c.MaxBytesPerChar
+		current.Bytes += bytes
+		largest = max(largest, bytes)
+	}
+	check()
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the indexes on text columns: %w", err)
+	}
+
+	return overflows, nil
+}
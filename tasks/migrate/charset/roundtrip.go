@@ -0,0 +1,193 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package charset
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// DefaultSampleSize is the number of rows of each table whose text is compared after a conversion
+const DefaultSampleSize = 100
+
+// RoundTripResult compares the text of the rows sampled from a converted table with the rows of the recipient
+type RoundTripResult struct {
+	Schema          string
+	Table           string
+	RecipientSchema string
+	Sampled         int
+	// Mismatches are the sampled rows missing on the recipient, or whose text differs
+	Mismatches int
+	// Skipped is the reason the table was not sampled
+	Skipped string
+}
+
+func (r RoundTripResult) String() string {
+	return r.Schema + "." + r.Table
+}
+
+// VerifyRoundTrip compares the text columns the conversion changed in the first sampleSize rows of each table, by
+// primary key, with the same rows on the recipient. Both sides are read as utf8mb4, so text only differs if
+// characters were lost or changed by the conversion.
+func VerifyRoundTrip(source, dest *sql.DB, tables []discovery.Table, recipientSchema func(string) string, c *Conversion, sampleSize int) ([]RoundTripResult, error) {
+	var results []RoundTripResult
+
+	for _, t := range tables {
+		columns, err := queryColumn(source, `SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND CHARACTER_SET_NAME IS NOT NULL AND (CHARACTER_SET_NAME <> ? OR COLLATION_NAME NOT IN (?, ?)) ORDER BY ORDINAL_POSITION`,
+			t.Schema, t.Name, c.Charset, c.Collation, c.Charset+"_bin")
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the text columns of %s: %w", t, err)
+		}
+
+		if len(columns) == 0 {
+			continue
+		}
+
+		result := RoundTripResult{Schema: t.Schema, Table: t.Name, RecipientSchema: recipientSchema(t.Schema)}
+
+		primaryKey, err := queryColumn(source, `SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND INDEX_NAME = 'PRIMARY' ORDER BY SEQ_IN_INDEX`, t.Schema, t.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the primary key of %s: %w", t, err)
+		}
+
+		if len(primaryKey) == 0 {
+			result.Skipped = "no primary key"
+			results = append(results, result)
+			continue
+		}
+
+		if err := compareSample(source, dest, &result, primaryKey, columns, sampleSize); err != nil {
+			return nil, fmt.Errorf("failed to compare the text of %s: %w", t, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func compareSample(source, dest *sql.DB, result *RoundTripResult, primaryKey, columns []string, sampleSize int) error {
+	keyList := quoteAll(primaryKey)
+	columnList := quoteAll(columns)
+
+	rows, err := source.Query(fmt.Sprintf("SELECT %s, %s FROM %s.%s ORDER BY %s LIMIT %d",
+		keyList, columnList, discovery.QuoteIdentifier(result.Schema), discovery.QuoteIdentifier(result.Table), keyList, sampleSize))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var conditions []string
+	for _, k := range primaryKey {
+		conditions = append(conditions, discovery.QuoteIdentifier(k)+" = ?")
+	}
+	lookup := fmt.Sprintf("SELECT %s FROM %s.%s WHERE %s",
+		columnList, discovery.QuoteIdentifier(result.RecipientSchema), discovery.QuoteIdentifier(result.Table), strings.Join(conditions, " AND "))
+
+	for rows.Next() {
+		values := make([][]byte, len(primaryKey)+len(columns))
+		if err := rows.Scan(pointers(values)...); err != nil {
+			return err
+		}
+		result.Sampled++
+
+		key := make([]any, len(primaryKey))
+		for i := range primaryKey {
+			key[i] = values[i]
+		}
+
+		converted := make([][]byte, len(columns))
+		err := dest.QueryRow(lookup, key...).Scan(pointers(converted)...)
+		if errors.Is(err, sql.ErrNoRows) {
+			result.Mismatches++
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, v := range converted {
+			original := values[len(primaryKey)+i]
+			if !bytes.Equal(v, original) || (v == nil) != (original == nil) {
+				result.Mismatches++
+				break
+			}
+		}
+	}
+
+	return rows.Err()
+}
+
+// WriteRoundTripReport writes a line for every table whose text did not round-trip, followed by a summary
+func WriteRoundTripReport(w io.Writer, results []RoundTripResult) (mismatches int) {
+	_, _ = fmt.Fprintln(w, "Character set conversion report:")
+
+	sampled := 0
+	for _, r := range results {
+		switch {
+		case r.Skipped != "":
+			_, _ = fmt.Fprintf(w, "  SKIPPED  %s: %s\n", r, r.Skipped)
+		case r.Mismatches > 0:
+			mismatches++
+			_, _ = fmt.Fprintf(w, "  MISMATCH %s: the text of %d of %d sampled rows differs on the recipient\n", r, r.Mismatches, r.Sampled)
+		}
+		sampled += r.Sampled
+	}
+
+	_, _ = fmt.Fprintf(w, "  Compared %d rows of %d tables, %d tables mismatched\n", sampled, len(results), mismatches)
+
+	return mismatches
+}
+
+func queryColumn(db *sql.DB, query string, args ...any) ([]string, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+func quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = discovery.QuoteIdentifier(n)
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+func pointers(values [][]byte) []any {
+	dests := make([]any, len(values))
+	for i := range values {
+		dests[i] = &values[i]
+	}
+
+	return dests
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package charset_test
+
+import (
+	"bytes"
+	"database/sql"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+var _ = Describe("VerifyRoundTrip", func() {
+	var (
+		source, dest         *sql.DB
+		sourceMock, destMock sqlmock.Sqlmock
+		conversion           *charset.Conversion
+	)
+
+	BeforeEach(func() {
+		var err error
+		source, sourceMock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		dest, destMock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		conversion = &charset.Conversion{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci", MaxBytesPerChar: 4}
+	})
+
+	AfterEach(func() {
+		Expect(sourceMock.ExpectationsWereMet()).To(Succeed())
+		Expect(destMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	recipientSchema := func(schema string) string { return "service_instance_db" }
+
+	It("compares the converted text of sampled rows by primary key", func() {
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = \? AND TABLE_NAME = \? AND CHARACTER_SET_NAME IS NOT NULL`).
+			WithArgs("app", "users", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_bin").
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("name").AddRow("bio"))
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.STATISTICS WHERE TABLE_SCHEMA = \? AND TABLE_NAME = \? AND INDEX_NAME = 'PRIMARY'`).
+			WithArgs("app", "users").
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("id"))
+		sourceMock.ExpectQuery("SELECT `id`, `name`, `bio` FROM `app`.`users` ORDER BY `id` LIMIT 2").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "bio"}).
+				AddRow([]byte("1"), []byte("Zoë"), nil).
+				AddRow([]byte("2"), []byte("Jürgen"), []byte("")))
+
+		lookup := regexp.QuoteMeta("SELECT `name`, `bio` FROM `service_instance_db`.`users` WHERE `id` = ?")
+		destMock.ExpectQuery(lookup).WithArgs([]byte("1")).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "bio"}).AddRow([]byte("Zoë"), nil))
+		destMock.ExpectQuery(lookup).WithArgs([]byte("2")).
+			WillReturnRows(sqlmock.NewRows([]string{"name", "bio"}).AddRow([]byte("J?rgen"), []byte("")))
+
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS`).
+			WithArgs("app", "counters", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_bin").
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}))
+
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS`).
+			WithArgs("app", "logs", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_bin").
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("message"))
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.STATISTICS`).
+			WithArgs("app", "logs").
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}))
+
+		tables := []discovery.Table{{Schema: "app", Name: "users"}, {Schema: "app", Name: "counters"}, {Schema: "app", Name: "logs"}}
+		results, err := charset.VerifyRoundTrip(source, dest, tables, recipientSchema, conversion, 2)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(Equal([]charset.RoundTripResult{
+			{Schema: "app", Table: "users", RecipientSchema: "service_instance_db", Sampled: 2, Mismatches: 1},
+			{Schema: "app", Table: "logs", RecipientSchema: "service_instance_db", Skipped: "no primary key"},
+		}))
+
+		var out bytes.Buffer
+		Expect(charset.WriteRoundTripReport(&out, results)).To(Equal(1))
+		Expect(out.String()).To(Equal("Character set conversion report:\n" +
+			"  MISMATCH app.users: the text of 1 of 2 sampled rows differs on the recipient\n" +
+			"  SKIPPED  app.logs: no primary key\n" +
+			"  Compared 2 rows of 2 tables, 1 tables mismatched\n"))
+	})
+
+	It("counts rows missing on the recipient as mismatches", func() {
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS`).
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("name"))
+		sourceMock.ExpectQuery(`SELECT COLUMN_NAME FROM INFORMATION_SCHEMA.STATISTICS`).
+			WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME"}).AddRow("tenant").AddRow("id"))
+		sourceMock.ExpectQuery("SELECT `tenant`, `id`, `name` FROM `app`.`users` ORDER BY `tenant`, `id` LIMIT 100").
+			WillReturnRows(sqlmock.NewRows([]string{"tenant", "id", "name"}).AddRow([]byte("a"), []byte("1"), []byte("Zoë")))
+		destMock.ExpectQuery(regexp.QuoteMeta("SELECT `name` FROM `service_instance_db`.`users` WHERE `tenant` = ? AND `id` = ?")).
+			WithArgs([]byte("a"), []byte("1")).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}))
+
+		results, err := charset.VerifyRoundTrip(source, dest, []discovery.Table{{Schema: "app", Name: "users"}}, recipientSchema, conversion, charset.DefaultSampleSize)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(results).To(ConsistOf(charset.RoundTripResult{Schema: "app", Table: "users", RecipientSchema: "service_instance_db", Sampled: 1, Mismatches: 1}))
+	})
+})
@@ -0,0 +1,68 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/compat"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+const checkCompatUsage = "Usage: migrate check-compat [-skip-tls-validation] <service>"
+
+// runCheckCompat reports what in the schemas of a service instance blocks or changes its migration to MySQL 8.0, and
+// fails when there are blockers
+func runCheckCompat(args []string) {
+	var skipTLSValidation bool
+
+	flags := flag.NewFlagSet("check-compat", flag.ExitOnError)
+	flags.BoolVar(&skipTLSValidation, "skip-tls-validation", false, "Skip certificate validation of the MySQL server certificate.  Not recommended!")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatal(checkCompatUsage)
+	}
+	instance := flags.Arg(0)
+
+	credentials, err := InstanceCredentials(instance, VcapCredentials)
+	if err != nil {
+		log.Fatalf("Failed to lookup credentials: %v", err)
+	}
+	credentials.SkipTLSValidation = skipTLSValidation
+
+	db, err := sql.Open("mysql", credentials.DSN())
+	if err != nil {
+		log.Fatalf("Failed to initialize connection: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	schemas, err := discovery.DiscoverDatabases(db)
+	if err != nil {
+		log.Fatalf("Failed to discover schemas: %v", err)
+	}
+	log.Printf("Checking schemas: %s", strings.Join(schemas, ", "))
+
+	findings, err := compat.Check(db, schemas)
+	if err != nil {
+		log.Fatalf("Failed to check compatibility: %v", err)
+	}
+
+	if blockers := compat.WriteReport(os.Stdout, findings); blockers > 0 {
+		log.Fatalf("%s can not be migrated to MySQL 8.0 until the %d blockers are fixed", instance, blockers)
+	}
+}
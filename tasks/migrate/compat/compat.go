@@ -0,0 +1,354 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package compat
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+type Severity string
+
+const (
+	// Blocker is a problem that fails the migration, or breaks the recipient once migrated
+	Blocker Severity = "Blocker"
+	// Warning is a problem that the migration carries over, or that applications may run into
+	Warning Severity = "Warning"
+)
+
+// Categories of findings, in the order they are reported
+const (
+	StorageEngines = "Storage engines"
+	SQLModes       = "SQL modes"
+	ZeroDates      = "Zero dates"
+	ReservedWords  = "Reserved words"
+	CharacterSets  = "Character sets"
+	InvalidViews   = "Invalid views"
+)
+
+var categories = []string{StorageEngines, SQLModes, ZeroDates, ReservedWords, CharacterSets, InvalidViews}
+
+// Finding is a problem found in the donor that affects migrating it to MySQL 8.0
+type Finding struct {
+	Severity Severity
+	Category string
+	// Object is the schema, table, column or stored program affected
+	Object  string
+	Problem string
+	// Fix is a suggested fix, usually a statement to run on the donor
+	Fix string
+}
+
+type check func(db *sql.DB, schemas []string) ([]Finding, error)
+
+// Check analyses the given schemas of the donor for what MySQL 8.0 rejects or changes
+func Check(db *sql.DB, schemas []string) ([]Finding, error) {
+	var findings []Finding
+
+	for _, c := range []check{
+		checkStorageEngines,
+		checkSQLModes,
+		checkZeroDates,
+		checkReservedWords,
+		checkCharacterSets,
+		checkInvalidViews,
+	} {
+		found, err := c(db, schemas)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, found...)
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return findings[i].Severity == Blocker
+		}
+		return categoryIndex(findings[i].Category) < categoryIndex(findings[j].Category)
+	})
+
+	return findings, nil
+}
+
+func categoryIndex(category string) int {
+	for i, c := range categories {
+		if c == category {
+			return i
+		}
+	}
+
+	return len(categories)
+}
+
+// WriteReport writes the findings grouped by severity, and returns the number of blockers
+func WriteReport(w io.Writer, findings []Finding) (blockers int) {
+	_, _ = fmt.Fprintln(w, "MySQL 8.0 compatibility report:")
+
+	warnings := 0
+	for _, severity := range []Severity{Blocker, Warning} {
+		_, _ = fmt.Fprintf(w, "%ss:\n", severity)
+
+		found := false
+		for _, f := range findings {
+			if f.Severity != severity {
+				continue
+			}
+			found = true
+
+			_, _ = fmt.Fprintf(w, "  [%s] %s: %s\n", f.Category, f.Object, f.Problem)
+			if f.Fix != "" {
+				_, _ = fmt.Fprintf(w, "    Fix: %s\n", f.Fix)
+			}
+
+			if severity == Blocker {
+				blockers++
+			} else {
+				warnings++
+			}
+		}
+
+		if !found {
+			_, _ = fmt.Fprintln(w, "  None")
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "Found %d blockers and %d warnings\n", blockers, warnings)
+
+	return blockers
+}
+
+func checkStorageEngines(db *sql.DB, schemas []string) ([]Finding, error) {
+	rows, err := queryStrings(db, `SELECT TABLE_SCHEMA, TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA IN (%s) AND TABLE_TYPE = 'BASE TABLE' AND ENGINE <> 'InnoDB' ORDER BY TABLE_SCHEMA, TABLE_NAME`, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the storage engines: %w", err)
+	}
+
+	var findings []Finding
+	for _, row := range rows {
+		schema, table, engine := row[0], row[1], row[2]
+		findings = append(findings, Finding{
+			Severity: Blocker,
+			Category: StorageEngines,
+			Object:   schema + "." + table,
+			Problem:  fmt.Sprintf("uses the %s storage engine, while only InnoDB tables are replicated by highly available plans", engine),
+			Fix:      fmt.Sprintf("ALTER TABLE %s ENGINE=InnoDB", qualifiedName(schema, table)),
+		})
+	}
+
+	return findings, nil
+}
+
+// removedSQLModes are the sql_mode values MySQL 8.0 no longer accepts
+var removedSQLModes = map[string]bool{
+	"NO_AUTO_CREATE_USER": true,
+	"DB2":                 true,
+	"MAXDB":               true,
+	"MSSQL":               true,
+	"MYSQL323":            true,
+	"MYSQL40":             true,
+	"ORACLE":              true,
+	"POSTGRESQL":          true,
+	"NO_FIELD_OPTIONS":    true,
+	"NO_KEY_OPTIONS":      true,
+	"NO_TABLE_OPTIONS":    true,
+}
+
+func removedModes(sqlMode string) string {
+	var removed []string
+	for _, mode := range strings.Split(sqlMode, ",") {
+		if removedSQLModes[mode] {
+			removed = append(removed, mode)
+		}
+	}
+
+	return strings.Join(removed, ",")
+}
+
+// checkSQLModes finds stored programs created with a sql_mode that MySQL 8.0 rejects, since the migration re-creates
+// them with the sql_mode they were created with
+func checkSQLModes(db *sql.DB, schemas []string) ([]Finding, error) {
+	var findings []Finding
+
+	for _, p := range []struct {
+		kind  string
+		query string
+	}{
+		{"routine", `SELECT ROUTINE_SCHEMA, ROUTINE_NAME, SQL_MODE FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA IN (%s) ORDER BY ROUTINE_SCHEMA, ROUTINE_NAME`},
+		{"trigger", `SELECT TRIGGER_SCHEMA, TRIGGER_NAME, SQL_MODE FROM INFORMATION_SCHEMA.TRIGGERS WHERE TRIGGER_SCHEMA IN (%s) ORDER BY TRIGGER_SCHEMA, TRIGGER_NAME`},
+		{"event", `SELECT EVENT_SCHEMA, EVENT_NAME, SQL_MODE FROM INFORMATION_SCHEMA.EVENTS WHERE EVENT_SCHEMA IN (%s) ORDER BY EVENT_SCHEMA, EVENT_NAME`},
+	} {
+		rows, err := queryStrings(db, p.query, schemas)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the sql_mode of stored programs: %w", err)
+		}
+
+		for _, row := range rows {
+			schema, name, sqlMode := row[0], row[1], row[2]
+			if removed := removedModes(sqlMode); removed != "" {
+				findings = append(findings, Finding{
+					Severity: Blocker,
+					Category: SQLModes,
+					Object:   schema + "." + name,
+					Problem:  fmt.Sprintf("the %s was created with the sql_mode %s, removed in MySQL 8.0", p.kind, removed),
+					Fix:      fmt.Sprintf("Re-create the %s after removing %s from the session's sql_mode", p.kind, removed),
+				})
+			}
+		}
+	}
+
+	var globalMode string
+	if err := db.QueryRow(`SELECT @@GLOBAL.sql_mode`).Scan(&globalMode); err != nil {
+		return nil, fmt.Errorf("failed to retrieve the global sql_mode: %w", err)
+	}
+
+	if removed := removedModes(globalMode); removed != "" {
+		findings = append(findings, Finding{
+			Severity: Warning,
+			Category: SQLModes,
+			Object:   "server",
+			Problem:  fmt.Sprintf("the global sql_mode includes %s, removed in MySQL 8.0", removed),
+			Fix:      "Make sure applications do not set these modes in their sessions",
+		})
+	}
+
+	return findings, nil
+}
+
+func checkZeroDates(db *sql.DB, schemas []string) ([]Finding, error) {
+	rows, err := queryStrings(db, `SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA IN (%s) AND DATA_TYPE IN ('date', 'datetime', 'timestamp') AND COLUMN_DEFAULT LIKE '0000-00-00%%' ORDER BY TABLE_SCHEMA, TABLE_NAME, ORDINAL_POSITION`, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the column defaults: %w", err)
+	}
+
+	var findings []Finding
+	for _, row := range rows {
+		schema, table, column := row[0], row[1], row[2]
+		findings = append(findings, Finding{
+			Severity: Blocker,
+			Category: ZeroDates,
+			Object:   schema + "." + table + "." + column,
+			Problem:  "defaults to a zero date, which the default sql_mode of MySQL 8.0 rejects",
+			Fix:      fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT, or set a valid default", qualifiedName(schema, table), discovery.QuoteIdentifier(column)),
+		})
+	}
+
+	return findings, nil
+}
+
+func checkCharacterSets(db *sql.DB, schemas []string) ([]Finding, error) {
+	rows, err := queryStrings(db, `SELECT SCHEMA_NAME, DEFAULT_COLLATION_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME IN (%s) AND DEFAULT_CHARACTER_SET_NAME IN ('utf8', 'utf8mb3') ORDER BY SCHEMA_NAME`, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the character sets of schemas: %w", err)
+	}
+
+	var findings []Finding
+	for _, row := range rows {
+		schema, collation := row[0], row[1]
+		findings = append(findings, Finding{
+			Severity: Warning,
+			Category: CharacterSets,
+			Object:   schema,
+			Problem:  fmt.Sprintf("defaults to the %s collation of the deprecated utf8mb3 character set", collation),
+			Fix:      fmt.Sprintf("ALTER DATABASE %s CHARACTER SET utf8mb4", discovery.QuoteIdentifier(schema)),
+		})
+	}
+
+	rows, err = queryStrings(db, `SELECT TABLE_SCHEMA, TABLE_NAME, GROUP_CONCAT(DISTINCT COLLATION_NAME ORDER BY COLLATION_NAME) FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA IN (%s) AND CHARACTER_SET_NAME IN ('utf8', 'utf8mb3') GROUP BY TABLE_SCHEMA, TABLE_NAME ORDER BY TABLE_SCHEMA, TABLE_NAME`, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve the character sets of columns: %w", err)
+	}
+
+	for _, row := range rows {
+		schema, table, collations := row[0], row[1], row[2]
+		findings = append(findings, Finding{
+			Severity: Warning,
+			Category: CharacterSets,
+			Object:   schema + "." + table,
+			Problem:  fmt.Sprintf("has columns using the %s collations of the deprecated utf8mb3 character set", collations),
+			Fix:      fmt.Sprintf("ALTER TABLE %s CONVERT TO CHARACTER SET utf8mb4", qualifiedName(schema, table)),
+		})
+	}
+
+	return findings, nil
+}
+
+func checkInvalidViews(db *sql.DB, schemas []string) ([]Finding, error) {
+	views, err := discovery.DiscoverInvalidViews(db, schemas)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve invalid views: %w", err)
+	}
+
+	var findings []Finding
+	for _, v := range views {
+		findings = append(findings, Finding{
+			Severity: Warning,
+			Category: InvalidViews,
+			Object:   v.String(),
+			Problem:  "references tables or columns that do not exist, and will not be migrated",
+			Fix:      fmt.Sprintf("Fix the view, or DROP VIEW %s", qualifiedName(v.Schema, v.TableName)),
+		})
+	}
+
+	return findings, nil
+}
+
+// queryStrings runs a query whose %s is replaced with one placeholder per schema, and returns its rows as strings
+func queryStrings(db *sql.DB, format string, schemas []string, args ...any) ([][]string, error) {
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+
+	queryArgs := append(stringsToAny(schemas), args...)
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(schemas)), ",")
+	rows, err := db.Query(fmt.Sprintf(format, placeholders), queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var result [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		row := make([]string, len(columns))
+		for i, v := range values {
+			row[i] = v.String
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func qualifiedName(schema, name string) string {
+	return discovery.QuoteIdentifier(schema) + "." + discovery.QuoteIdentifier(name)
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package compat_test
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/compat"
+)
+
+var _ = Describe("Check", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	expectPrograms := func(column string, routines, triggers, events *sqlmock.Rows) {
+		mock.ExpectQuery(`SELECT ROUTINE_SCHEMA, ROUTINE_NAME, ` + column + ` FROM INFORMATION_SCHEMA.ROUTINES`).
+			WithArgs("app").WillReturnRows(routines)
+		mock.ExpectQuery(`SELECT TRIGGER_SCHEMA, TRIGGER_NAME, \w+ FROM INFORMATION_SCHEMA.TRIGGERS`).
+			WithArgs("app").WillReturnRows(triggers)
+		mock.ExpectQuery(`SELECT EVENT_SCHEMA, EVENT_NAME, \w+ FROM INFORMATION_SCHEMA.EVENTS`).
+			WithArgs("app").WillReturnRows(events)
+	}
+
+	programRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"schema", "name", "value"})
+	}
+
+	It("reports what MySQL 8.0 rejects or changes, blockers first", func() {
+		mock.ExpectQuery(`SELECT TABLE_SCHEMA, TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA IN \(\?\) AND TABLE_TYPE = 'BASE TABLE' AND ENGINE <> 'InnoDB'`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "ENGINE"}).AddRow("app", "logs", "MyISAM"))
+
+		expectPrograms("SQL_MODE",
+			programRows().AddRow("app", "legacy_proc", "STRICT_TRANS_TABLES,NO_AUTO_CREATE_USER").AddRow("app", "ok_proc", "STRICT_TRANS_TABLES"),
+			programRows(),
+			programRows().AddRow("app", "nightly", "MYSQL40"),
+		)
+		mock.ExpectQuery(`SELECT @@GLOBAL.sql_mode`).
+			WillReturnRows(sqlmock.NewRows([]string{"sql_mode"}).AddRow("STRICT_TRANS_TABLES,NO_AUTO_CREATE_USER"))
+
+		mock.ExpectQuery(`SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA IN \(\?\) AND DATA_TYPE IN \('date', 'datetime', 'timestamp'\) AND COLUMN_DEFAULT LIKE '0000-00-00%'`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME"}).AddRow("app", "users", "created_at"))
+
+		mock.ExpectQuery(`SELECT TABLE_SCHEMA, TABLE_NAME, '' FROM INFORMATION_SCHEMA.TABLES .* UNION ALL SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS`).
+			WithArgs(identifierArgs("app")...).
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "COLUMN_NAME"}).
+				AddRow("app", "groups", "").
+				AddRow("app", "scores", "rank"))
+		expectPrograms("ROUTINE_DEFINITION",
+			programRows().
+				AddRow("app", "top_scores", "BEGIN SELECT s.rank, `rank`, 'rank' FROM scores s WHERE rank > 1 AND groups.id -- rank\n; END").
+				AddRow("app", "legacy_proc", "BEGIN SELECT ROW(1, 2) = ROW(1, 2); /* window */ SELECT \"over\"; END"),
+			programRows().AddRow("app", "scores_bi", "SET NEW.cume_dist = Over.x + lag"),
+			programRows().AddRow("app", "nightly", nil),
+		)
+
+		mock.ExpectQuery(`SELECT SCHEMA_NAME, DEFAULT_COLLATION_NAME FROM INFORMATION_SCHEMA.SCHEMATA WHERE SCHEMA_NAME IN \(\?\) AND DEFAULT_CHARACTER_SET_NAME IN \('utf8', 'utf8mb3'\)`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"SCHEMA_NAME", "DEFAULT_COLLATION_NAME"}).AddRow("app", "utf8_general_ci"))
+		mock.ExpectQuery(`SELECT TABLE_SCHEMA, TABLE_NAME, GROUP_CONCAT\(DISTINCT COLLATION_NAME ORDER BY COLLATION_NAME\) FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA IN \(\?\) AND CHARACTER_SET_NAME IN \('utf8', 'utf8mb3'\)`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "COLLATIONS"}).AddRow("app", "users", "utf8_bin,utf8_general_ci"))
+
+		mock.ExpectQuery(`SELECT table_name from INFORMATION_SCHEMA.VIEWS WHERE table_schema = ?`).
+			WithArgs("app").
+			WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("broken_view"))
+		mock.ExpectExec("SHOW FIELDS FROM `broken_view` IN `app`").
+			WillReturnError(&mysql.MySQLError{Number: 1356, Message: "View 'app.broken_view' references invalid table(s)"})
+
+		findings, err := compat.Check(db, []string{"app"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(findings).To(Equal([]compat.Finding{
+			{
+				Severity: compat.Blocker,
+				Category: compat.StorageEngines,
+				Object:   "app.logs",
+				Problem:  "uses the MyISAM storage engine, while only InnoDB tables are replicated by highly available plans",
+				Fix:      "ALTER TABLE `app`.`logs` ENGINE=InnoDB",
+			},
+			{
+				Severity: compat.Blocker,
+				Category: compat.SQLModes,
+				Object:   "app.legacy_proc",
+				Problem:  "the routine was created with the sql_mode NO_AUTO_CREATE_USER, removed in MySQL 8.0",
+				Fix:      "Re-create the routine after removing NO_AUTO_CREATE_USER from the session's sql_mode",
+			},
+			{
+				Severity: compat.Blocker,
+				Category: compat.SQLModes,
+				Object:   "app.nightly",
+				Problem:  "the event was created with the sql_mode MYSQL40, removed in MySQL 8.0",
+				Fix:      "Re-create the event after removing MYSQL40 from the session's sql_mode",
+			},
+			{
+				Severity: compat.Blocker,
+				Category: compat.ZeroDates,
+				Object:   "app.users.created_at",
+				Problem:  "defaults to a zero date, which the default sql_mode of MySQL 8.0 rejects",
+				Fix:      "ALTER TABLE `app`.`users` ALTER COLUMN `created_at` DROP DEFAULT, or set a valid default",
+			},
+			{
+				Severity: compat.Blocker,
+				Category: compat.ReservedWords,
+				Object:   "app.top_scores",
+				Problem:  "the body of the routine uses GROUPS, RANK unquoted, reserved words in MySQL 8.0",
+				Fix:      "Re-create the routine quoting them with backticks",
+			},
+			{
+				Severity: compat.Blocker,
+				Category: compat.ReservedWords,
+				Object:   "app.scores_bi",
+				Problem:  "the body of the trigger uses LAG, OVER unquoted, reserved words in MySQL 8.0",
+				Fix:      "Re-create the trigger quoting them with backticks",
+			},
+			{
+				Severity: compat.Warning,
+				Category: compat.SQLModes,
+				Object:   "server",
+				Problem:  "the global sql_mode includes NO_AUTO_CREATE_USER, removed in MySQL 8.0",
+				Fix:      "Make sure applications do not set these modes in their sessions",
+			},
+			{
+				Severity: compat.Warning,
+				Category: compat.ReservedWords,
+				Object:   "app.groups",
+				Problem:  "GROUPS is a reserved word in MySQL 8.0, so queries referring to it unquoted fail",
+				Fix:      "Quote it as `groups` in the queries of applications, or rename it",
+			},
+			{
+				Severity: compat.Warning,
+				Category: compat.ReservedWords,
+				Object:   "app.scores.rank",
+				Problem:  "RANK is a reserved word in MySQL 8.0, so queries referring to it unquoted fail",
+				Fix:      "Quote it as `rank` in the queries of applications, or rename it",
+			},
+			{
+				Severity: compat.Warning,
+				Category: compat.CharacterSets,
+				Object:   "app",
+				Problem:  "defaults to the utf8_general_ci collation of the deprecated utf8mb3 character set",
+				Fix:      "ALTER DATABASE `app` CHARACTER SET utf8mb4",
+			},
+			{
+				Severity: compat.Warning,
+				Category: compat.CharacterSets,
+				Object:   "app.users",
+				Problem:  "has columns using the utf8_bin,utf8_general_ci collations of the deprecated utf8mb3 character set",
+				Fix:      "ALTER TABLE `app`.`users` CONVERT TO CHARACTER SET utf8mb4",
+			},
+			{
+				Severity: compat.Warning,
+				Category: compat.InvalidViews,
+				Object:   "app.broken_view",
+				Problem:  "references tables or columns that do not exist, and will not be migrated",
+				Fix:      "Fix the view, or DROP VIEW `app`.`broken_view`",
+			},
+		}))
+	})
+
+	It("returns an error when a query fails", func() {
+		mock.ExpectQuery(`SELECT TABLE_SCHEMA, TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES`).
+			WithArgs("app", "other").
+			WillReturnRows(sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "ENGINE"}))
+		mock.ExpectQuery(`SELECT ROUTINE_SCHEMA, ROUTINE_NAME, SQL_MODE FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA IN \(\?,\?\)`).
+			WithArgs("app", "other").
+			WillReturnError(errors.New("some error"))
+
+		_, err := compat.Check(db, []string{"app", "other"})
+		Expect(err).To(MatchError("failed to retrieve the sql_mode of stored programs: some error"))
+	})
+})
+
+var _ = Describe("WriteReport", func() {
+	It("writes the findings grouped by severity and returns the number of blockers", func() {
+		var out bytes.Buffer
+		blockers := compat.WriteReport(&out, []compat.Finding{
+			{Severity: compat.Blocker, Category: compat.StorageEngines, Object: "app.logs", Problem: "uses the MyISAM storage engine", Fix: "ALTER TABLE `app`.`logs` ENGINE=InnoDB"},
+			{Severity: compat.Warning, Category: compat.InvalidViews, Object: "app.broken_view", Problem: "will not be migrated"},
+		})
+
+		Expect(blockers).To(Equal(1))
+		Expect(out.String()).To(Equal(`MySQL 8.0 compatibility report:
+Blockers:
+  [Storage engines] app.logs: uses the MyISAM storage engine
+    Fix: ALTER TABLE ` + "`app`.`logs`" + ` ENGINE=InnoDB
+Warnings:
+  [Invalid views] app.broken_view: will not be migrated
+Found 1 blockers and 1 warnings
+`))
+	})
+
+	It("reports when nothing was found", func() {
+		var out bytes.Buffer
+		Expect(compat.WriteReport(&out, nil)).To(BeZero())
+		Expect(out.String()).To(Equal("MySQL 8.0 compatibility report:\nBlockers:\n  None\nWarnings:\n  None\nFound 0 blockers and 0 warnings\n"))
+	})
+})
+
+func identifierArgs(schema string) []driver.Value {
+	words := []driver.Value{
+		"ARRAY", "CUBE", "CUME_DIST", "DENSE_RANK", "EMPTY", "EXCEPT", "FIRST_VALUE", "FUNCTION", "GROUPING", "GROUPS",
+		"JSON_TABLE", "LAG", "LAST_VALUE", "LATERAL", "LEAD", "MEMBER", "NTH_VALUE", "NTILE", "OF", "OVER",
+		"PERCENT_RANK", "RANK", "RECURSIVE", "ROW", "ROWS", "ROW_NUMBER", "SYSTEM", "WINDOW",
+	}
+
+	args := append([]driver.Value{schema}, words...)
+	args = append(args, schema)
+	return append(args, words...)
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package compat
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// newReservedWords are the keywords that became reserved in MySQL 8.0
+var newReservedWords = []string{
+	"ARRAY", "CUBE", "CUME_DIST", "DENSE_RANK", "EMPTY", "EXCEPT", "FIRST_VALUE", "FUNCTION", "GROUPING", "GROUPS",
+	"JSON_TABLE", "LAG", "LAST_VALUE", "LATERAL", "LEAD", "MEMBER", "NTH_VALUE", "NTILE", "OF", "OVER",
+	"PERCENT_RANK", "RANK", "RECURSIVE", "ROW", "ROWS", "ROW_NUMBER", "SYSTEM", "WINDOW",
+}
+
+// keywordsIn57 are reserved words MySQL 5.7 already accepts unquoted in statements, e.g. FOR EACH ROW, so
+// finding them in the body of a stored program is expected
+var keywordsIn57 = map[string]bool{"FUNCTION": true, "ROW": true}
+
+func checkReservedWords(db *sql.DB, schemas []string) ([]Finding, error) {
+	words := stringsToAny(newReservedWords)
+	wordPlaceholders := strings.TrimSuffix(strings.Repeat("?,", len(words)), ",")
+
+	var findings []Finding
+
+	rows, err := queryStrings(db, `SELECT TABLE_SCHEMA, TABLE_NAME, '' FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA IN (%s) AND UPPER(TABLE_NAME) IN (`+wordPlaceholders+`) `+
+		`UNION ALL SELECT TABLE_SCHEMA, TABLE_NAME, COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA IN (%[1]s) AND UPPER(COLUMN_NAME) IN (`+wordPlaceholders+`) `+
+		`ORDER BY 1, 2, 3`, schemas, identifierArgs(words, schemas)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve identifiers: %w", err)
+	}
+
+	for _, row := range rows {
+		schema, table, column := row[0], row[1], row[2]
+		object, name := schema+"."+table, table
+		if column != "" {
+			object, name = object+"."+column, column
+		}
+
+		findings = append(findings, Finding{
+			Severity: Warning,
+			Category: ReservedWords,
+			Object:   object,
+			Problem:  fmt.Sprintf("%s is a reserved word in MySQL 8.0, so queries referring to it unquoted fail", strings.ToUpper(name)),
+			Fix:      fmt.Sprintf("Quote it as `%s` in the queries of applications, or rename it", name),
+		})
+	}
+
+	for _, p := range []struct {
+		kind  string
+		query string
+	}{
+		{"routine", `SELECT ROUTINE_SCHEMA, ROUTINE_NAME, ROUTINE_DEFINITION FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA IN (%s) ORDER BY ROUTINE_SCHEMA, ROUTINE_NAME`},
+		{"trigger", `SELECT TRIGGER_SCHEMA, TRIGGER_NAME, ACTION_STATEMENT FROM INFORMATION_SCHEMA.TRIGGERS WHERE TRIGGER_SCHEMA IN (%s) ORDER BY TRIGGER_SCHEMA, TRIGGER_NAME`},
+		{"event", `SELECT EVENT_SCHEMA, EVENT_NAME, EVENT_DEFINITION FROM INFORMATION_SCHEMA.EVENTS WHERE EVENT_SCHEMA IN (%s) ORDER BY EVENT_SCHEMA, EVENT_NAME`},
+	} {
+		rows, err := queryStrings(db, p.query, schemas)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the definition of stored programs: %w", err)
+		}
+
+		for _, row := range rows {
+			schema, name, body := row[0], row[1], row[2]
+			if used := unquotedReservedWords(body); len(used) > 0 {
+				findings = append(findings, Finding{
+					Severity: Blocker,
+					Category: ReservedWords,
+					Object:   schema + "." + name,
+					Problem:  fmt.Sprintf("the body of the %s uses %s unquoted, reserved words in MySQL 8.0", p.kind, strings.Join(used, ", ")),
+					Fix:      fmt.Sprintf("Re-create the %s quoting them with backticks", p.kind),
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// unquotedReservedWords returns the new reserved words used as bare words in a SQL statement, skipping strings,
+// quoted identifiers, comments and names qualified with a period, which may be reserved words
+func unquotedReservedWords(statement string) []string {
+	reserved := make(map[string]bool, len(newReservedWords))
+	for _, w := range newReservedWords {
+		reserved[w] = !keywordsIn57[w]
+	}
+
+	found := map[string]bool{}
+	for i := 0; i < len(statement); {
+		c := statement[i]
+		switch {
+		case discovery.IsQuote(c):
+			i = discovery.SkipQuoted(statement, i)
+		case c == '#' || (c == '-' && strings.HasPrefix(statement[i:], "-- ")):
+			if end := strings.IndexByte(statement[i:], '\n'); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(statement)
+			}
+		case c == '/' && strings.HasPrefix(statement[i:], "/*"):
+			if end := strings.Index(statement[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(statement)
+			}
+		case discovery.IsWordByte(c):
+			start := i
+			for i < len(statement) && discovery.IsWordByte(statement[i]) {
+				i++
+			}
+
+			word := strings.ToUpper(statement[start:i])
+			if reserved[word] && !(start > 0 && statement[start-1] == '.') {
+				found[word] = true
+			}
+		default:
+			i++
+		}
+	}
+
+	var words []string
+	for w := range found {
+		words = append(words, w)
+	}
+	sort.Strings(words)
+
+	return words
+}
+
+// identifierArgs returns the arguments following the schemas of the first SELECT of the identifiers query
+func identifierArgs(words []any, schemas []string) []any {
+	args := append([]any{}, words...)
+	args = append(args, stringsToAny(schemas)...)
+	return append(args, words...)
+}
+
+func stringsToAny(values []string) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+
+	return result
+}
@@ -0,0 +1,759 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package copier
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/throttle"
+)
+
+// DefaultMaxStatementBytes is the size at which the rows of a table are split into another INSERT statement. It is
+// well below the default max_allowed_packet of the recipient.
+const DefaultMaxStatementBytes = 1024 * 1024
+
+// Position is a position in the binary log of the source
+type Position struct {
+	File     string
+	Position int64
+}
+
+type Options struct {
+	// StructureOnly creates the tables and views without copying any rows
+	StructureOnly bool
+	// RecordPosition makes the copy consistent with a binlog position of the source, which is returned
+	RecordPosition bool
+}
+
+// Copier copies schemas from the source to the recipient over plain SQL connections, with the same result as
+// piping mysqldump into mysql
+type Copier struct {
+	source          *sql.DB
+	dest            *sql.DB
+	recipientSchema func(string) string
+	skipped         map[string]bool
+	definers        definer.Policy
+
+	MaxStatementBytes int
+	// Masker rewrites the values of masked columns before they are inserted. Nil copies every value unchanged.
+	Masker *masking.Masker
+	// Conversion changes the character set and collation of the schemas and tables created. Nil keeps them.
+	Conversion *charset.Conversion
+	// Throttle limits how fast rows are read from the source. Nil reads them as fast as possible.
+	Throttle *throttle.Throttle
+
+	// snapshots are the source connections sharing the snapshot taken by ShareSnapshot
+	snapshots chan *sql.Conn
+}
+
+// New returns a Copier loading each source schema into recipientSchema(schema). skippedTables are the tables and
+// views, as "schema.table", that are not copied. The DEFINER clauses of views and stored programs are rewritten
+// according to definers.
+func New(source, dest *sql.DB, recipientSchema func(string) string, skippedTables []string, definers definer.Policy) *Copier {
+	skipped := map[string]bool{}
+	for _, t := range skippedTables {
+		skipped[t] = true
+	}
+
+	return &Copier{
+		source:            source,
+		dest:              dest,
+		recipientSchema:   recipientSchema,
+		skipped:           skipped,
+		definers:          definers,
+		MaxStatementBytes: DefaultMaxStatementBytes,
+	}
+}
+
+type view struct {
+	schema string
+	name   string
+}
+
+// CopySchemas copies the tables and views of the given schemas from a consistent snapshot of the source. The
+// statements run on the recipient are written to progress in the format of mysqldump.
+func (c *Copier) CopySchemas(schemas []string, opts Options, progress io.Writer) (Position, error) {
+	ctx := context.Background()
+	if progress == nil {
+		progress = io.Discard
+	}
+
+	src, position, err := c.snapshot(ctx, opts.RecordPosition)
+	if err != nil {
+		return Position{}, err
+	}
+	defer release(src)
+
+	dest, err := c.destConn(ctx)
+	if err != nil {
+		return Position{}, err
+	}
+	defer release(dest)
+
+	var views []view
+	for _, schema := range schemas {
+		recipient := c.recipientSchema(schema)
+
+		_, _ = fmt.Fprintf(progress, "--\n-- Current Database: %s\n--\n\n", discovery.QuoteIdentifier(schema))
+
+		// Several schemas keep their names on the recipient, and are created like mysqldump --databases does
+		if len(schemas) > 1 {
+			if err := c.createDatabase(ctx, src, dest, schema, progress); err != nil {
+				return Position{}, err
+			}
+		} else if c.Conversion != nil {
+			alter := fmt.Sprintf("ALTER DATABASE %s CHARACTER SET %s COLLATE %s", discovery.QuoteIdentifier(recipient), c.Conversion.Charset, c.Conversion.Collation)
+			if err := execAll(ctx, dest, progress, alter); err != nil {
+				return Position{}, fmt.Errorf("failed to convert schema %s on the recipient: %w", recipient, err)
+			}
+		}
+
+		if err := execAll(ctx, dest, progress, "USE "+discovery.QuoteIdentifier(recipient)); err != nil {
+			return Position{}, fmt.Errorf("failed to use schema %s on the recipient: %w", recipient, err)
+		}
+
+		tables, err := listTables(ctx, src, schema)
+		if err != nil {
+			return Position{}, err
+		}
+
+		for _, t := range tables {
+			if c.skipped[schema+"."+t.name] {
+				continue
+			}
+
+			if t.view {
+				views = append(views, view{schema: schema, name: t.name})
+				continue
+			}
+
+			if err := c.createTable(ctx, src, dest, schema, t.name, progress); err != nil {
+				return Position{}, fmt.Errorf("failed to copy the structure of %s.%s: %w", schema, t.name, err)
+			}
+
+			if opts.StructureOnly {
+				continue
+			}
+
+			if err := c.copyRows(ctx, src, dest, schema, t.name, progress); err != nil {
+				return Position{}, fmt.Errorf("failed to copy the rows of %s.%s: %w", schema, t.name, err)
+			}
+		}
+	}
+
+	if err := c.createViews(ctx, src, dest, views, progress); err != nil {
+		return Position{}, err
+	}
+
+	if _, err := src.ExecContext(ctx, "COMMIT"); err != nil {
+		return Position{}, fmt.Errorf("failed to end the snapshot of the source: %w", err)
+	}
+
+	return position, nil
+}
+
+// CopyTableRows copies the rows of a table whose structure was already copied. It reads from the snapshot taken by
+// ShareSnapshot, if any. Otherwise it does not take a snapshot, so writes to the table have to be blocked while it is
+// copied.
+func (c *Copier) CopyTableRows(schema, table string, progress io.Writer) error {
+	ctx := context.Background()
+	if progress == nil {
+		progress = io.Discard
+	}
+
+	var src *sql.Conn
+	if c.snapshots != nil {
+		src = <-c.snapshots
+		defer func() { c.snapshots <- src }()
+	} else {
+		var err error
+		if src, err = c.sourceConn(ctx); err != nil {
+			return err
+		}
+		defer release(src)
+	}
+
+	dest, err := c.destConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(dest)
+
+	_, _ = fmt.Fprintf(progress, "--\n-- Current Database: %s\n--\n\n", discovery.QuoteIdentifier(schema))
+
+	if err := execAll(ctx, dest, progress, "USE "+discovery.QuoteIdentifier(c.recipientSchema(schema))); err != nil {
+		return fmt.Errorf("failed to use schema %s on the recipient: %w", c.recipientSchema(schema), err)
+	}
+
+	return c.copyRows(ctx, src, dest, schema, table, progress)
+}
+
+// CopyStoredPrograms creates the given routines, triggers and events on the recipient, owned by the user creating
+// them. Like mysql --force, every program is attempted, and the failures are returned together.
+func (c *Copier) CopyStoredPrograms(programs []discovery.StoredProgram) error {
+	ctx := context.Background()
+
+	dest, err := c.destConn(ctx)
+	if err != nil {
+		return err
+	}
+	defer release(dest)
+
+	var errs error
+	for _, p := range programs {
+		if err := c.createStoredProgram(ctx, dest, p); err != nil {
+			errs = multierror.Append(errs, fmt.Errorf("failed to create %s: %w", p, err))
+		}
+	}
+
+	return errs
+}
+
+// snapshot returns a source connection in a transaction with a consistent snapshot, the way mysqldump
+// --single-transaction --master-data takes it
+func (c *Copier) snapshot(ctx context.Context, recordPosition bool) (*sql.Conn, Position, error) {
+	conn, err := c.sourceConn(ctx)
+	if err != nil {
+		return nil, Position{}, err
+	}
+
+	// The global read lock only waits for running writes, so that the binlog position matches the snapshot
+	if recordPosition {
+		if _, err := conn.ExecContext(ctx, "FLUSH TABLES WITH READ LOCK"); err != nil {
+			release(conn)
+			return nil, Position{}, fmt.Errorf("failed to lock the source for a consistent snapshot: %w", err)
+		}
+	}
+
+	if err := execAll(ctx, conn, io.Discard,
+		"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+		"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+	); err != nil {
+		release(conn)
+		return nil, Position{}, fmt.Errorf("failed to take a snapshot of the source: %w", err)
+	}
+
+	var position Position
+	if recordPosition {
+		if position, err = masterStatus(ctx, conn); err != nil {
+			release(conn)
+			return nil, Position{}, err
+		}
+
+		if _, err := conn.ExecContext(ctx, "UNLOCK TABLES"); err != nil {
+			release(conn)
+			return nil, Position{}, fmt.Errorf("failed to unlock the source: %w", err)
+		}
+	}
+
+	return conn, position, nil
+}
+
+// ShareSnapshot starts workers transactions on the source that share one consistent snapshot, which up to workers
+// concurrent CopyTableRows calls read from until release is called. Like mydumper, writes are only blocked while the
+// transactions are started: by FLUSH TABLES WITH READ LOCK when the binlog position is recorded, since it needs the
+// RELOAD privilege online migrations require anyway, and by locking the given tables otherwise.
+func (c *Copier) ShareSnapshot(tables []discovery.Table, workers int, recordPosition bool) (Position, func() error, error) {
+	ctx := context.Background()
+
+	lock, err := c.sourceConn(ctx)
+	if err != nil {
+		return Position{}, nil, err
+	}
+	// Losing the connection releases its locks
+	defer release(lock)
+
+	if err := lockForSnapshot(ctx, lock, tables, recordPosition); err != nil {
+		return Position{}, nil, fmt.Errorf("failed to lock the source for a consistent snapshot: %w", err)
+	}
+
+	conns := make(chan *sql.Conn, workers)
+	releaseSnapshots := func() error {
+		c.snapshots = nil
+		close(conns)
+
+		var errs error
+		for conn := range conns {
+			if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+				errs = multierror.Append(errs, fmt.Errorf("failed to end a snapshot of the source: %w", err))
+			}
+			release(conn)
+		}
+
+		return errs
+	}
+
+	for i := 0; i < workers; i++ {
+		conn, err := c.sourceConn(ctx)
+		if err != nil {
+			_ = releaseSnapshots()
+			return Position{}, nil, err
+		}
+		conns <- conn
+
+		if err := execAll(ctx, conn, io.Discard,
+			"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		); err != nil {
+			_ = releaseSnapshots()
+			return Position{}, nil, fmt.Errorf("failed to take a snapshot of the source: %w", err)
+		}
+	}
+
+	var position Position
+	if recordPosition {
+		if position, err = masterStatus(ctx, lock); err != nil {
+			_ = releaseSnapshots()
+			return Position{}, nil, err
+		}
+	}
+
+	if _, err := lock.ExecContext(ctx, "UNLOCK TABLES"); err != nil {
+		_ = releaseSnapshots()
+		return Position{}, nil, fmt.Errorf("failed to unlock the source: %w", err)
+	}
+
+	c.snapshots = conns
+	return position, releaseSnapshots, nil
+}
+
+func lockForSnapshot(ctx context.Context, conn *sql.Conn, tables []discovery.Table, global bool) error {
+	if global {
+		_, err := conn.ExecContext(ctx, "FLUSH TABLES WITH READ LOCK")
+		return err
+	}
+
+	if len(tables) == 0 {
+		return nil
+	}
+
+	var locks []string
+	for _, t := range tables {
+		locks = append(locks, qualifiedName(t.Schema, t.Name)+" READ")
+	}
+
+	_, err := conn.ExecContext(ctx, "LOCK TABLES "+strings.Join(locks, ", "))
+	return err
+}
+
+func masterStatus(ctx context.Context, conn *sql.Conn) (Position, error) {
+	rows, err := conn.QueryContext(ctx, "SHOW MASTER STATUS")
+	if err != nil {
+		return Position{}, fmt.Errorf("failed to read the binlog position of the source: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	values, err := scanRow(rows)
+	if err != nil {
+		return Position{}, fmt.Errorf("failed to read the binlog position of the source: %w", err)
+	}
+
+	if values == nil || values["File"] == nil {
+		return Position{}, errors.New("binary logging is disabled on the source")
+	}
+
+	position := Position{File: string(values["File"])}
+	if _, err := fmt.Sscan(string(values["Position"]), &position.Position); err != nil {
+		return Position{}, fmt.Errorf("invalid binlog position %q: %w", values["Position"], err)
+	}
+
+	return position, nil
+}
+
+// Sessions use UTC and utf8mb4, so that TIMESTAMP columns and strings are copied unchanged
+func (c *Copier) sourceConn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := c.source.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the source: %w", err)
+	}
+
+	statements := []string{"SET NAMES utf8mb4", "SET SESSION time_zone = '+00:00'"}
+	// A throttled copy stops reading rows for a while, which the source must not take for a lost connection
+	if c.Throttle != nil {
+		statements = append(statements, "SET SESSION net_write_timeout = 86400")
+	}
+
+	if err := execAll(ctx, conn, io.Discard, statements...); err != nil {
+		release(conn)
+		return nil, fmt.Errorf("failed to configure the source session: %w", err)
+	}
+
+	return conn, nil
+}
+
+// The recipient session skips the checks mysqldump disables while loading a dump
+func (c *Copier) destConn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := c.dest.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the recipient: %w", err)
+	}
+
+	if err := execAll(ctx, conn, io.Discard,
+		"SET NAMES utf8mb4",
+		"SET SESSION time_zone = '+00:00'",
+		"SET SESSION foreign_key_checks = 0",
+		"SET SESSION unique_checks = 0",
+		"SET SESSION sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+	); err != nil {
+		release(conn)
+		return nil, fmt.Errorf("failed to configure the recipient session: %w", err)
+	}
+
+	return conn, nil
+}
+
+// release discards the connection instead of returning it to the pool, since its session settings, locks and
+// transaction must not leak into other queries
+func release(conn *sql.Conn) {
+	_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	_ = conn.Close()
+}
+
+func execAll(ctx context.Context, conn *sql.Conn, progress io.Writer, statements ...string) error {
+	for _, statement := range statements {
+		_, _ = io.WriteString(progress, statement+";\n")
+
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *Copier) createDatabase(ctx context.Context, src, dest *sql.Conn, schema string, progress io.Writer) error {
+	var name, ddl string
+	if err := src.QueryRowContext(ctx, "SHOW CREATE DATABASE "+discovery.QuoteIdentifier(schema)).Scan(&name, &ddl); err != nil {
+		return fmt.Errorf("failed to read the definition of schema %s: %w", schema, err)
+	}
+
+	ddl = strings.Replace(c.Conversion.Rewrite(ddl), "CREATE DATABASE ", "CREATE DATABASE IF NOT EXISTS ", 1)
+	if err := execAll(ctx, dest, progress, ddl); err != nil {
+		return fmt.Errorf("failed to create schema %s on the recipient: %w", schema, err)
+	}
+
+	return nil
+}
+
+type tableInfo struct {
+	name string
+	view bool
+}
+
+func listTables(ctx context.Context, conn *sql.Conn, schema string) ([]tableInfo, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tables for %s schema: %w", schema, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var tables []tableInfo
+	for rows.Next() {
+		var (
+			t         tableInfo
+			tableType string
+		)
+		if err := rows.Scan(&t.name, &tableType); err != nil {
+			return nil, fmt.Errorf("failed to scan the list of tables: %w", err)
+		}
+		t.view = tableType == "VIEW"
+
+		tables = append(tables, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to prepare the list of tables: %w", err)
+	}
+
+	return tables, nil
+}
+
+func (c *Copier) createTable(ctx context.Context, src, dest *sql.Conn, schema, table string, progress io.Writer) error {
+	var name, ddl string
+	if err := src.QueryRowContext(ctx, "SHOW CREATE TABLE "+qualifiedName(schema, table)).Scan(&name, &ddl); err != nil {
+		return fmt.Errorf("failed to read the table definition: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(progress, "\n--\n-- Table structure for table %s\n--\n\n", discovery.QuoteIdentifier(table))
+
+	return execAll(ctx, dest, progress, "DROP TABLE IF EXISTS "+discovery.QuoteIdentifier(table), c.Conversion.Rewrite(ddl))
+}
+
+type column struct {
+	name     string
+	dataType string
+}
+
+// copyRows reads the rows of a table on src and inserts them into the table of the same name in the schema dest
+// is using. Generated columns are left to the recipient to compute.
+func (c *Copier) copyRows(ctx context.Context, src, dest *sql.Conn, schema, table string, progress io.Writer) error {
+	columns, err := tableColumns(ctx, src, schema, table)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(progress, "\n--\n-- Dumping data for table %s\n--\n\n", discovery.QuoteIdentifier(table))
+
+	if len(columns) == 0 {
+		return nil
+	}
+
+	var names, quotedNames []string
+	for _, col := range columns {
+		names = append(names, col.name)
+		quotedNames = append(quotedNames, discovery.QuoteIdentifier(col.name))
+	}
+	columnList := strings.Join(quotedNames, ",")
+
+	// Masked values are strings, and are inserted as string literals whatever the type of their column
+	mask := c.Masker.Table(schema, table, names)
+	for i := range columns {
+		if mask.Masked(i) {
+			columns[i].dataType = ""
+		}
+	}
+
+	rows, err := src.QueryContext(ctx, "SELECT "+columnList+" FROM "+qualifiedName(schema, table))
+	if err != nil {
+		return fmt.Errorf("failed to read rows: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	values := make([][]byte, len(columns))
+	dests := make([]any, len(columns))
+	for i := range values {
+		dests[i] = &values[i]
+	}
+
+	insertPrefix := "INSERT INTO " + discovery.QuoteIdentifier(table) + " (" + columnList + ") VALUES "
+
+	var statement strings.Builder
+	flush := func() error {
+		if statement.Len() == 0 {
+			return nil
+		}
+
+		err := execAll(ctx, dest, progress, statement.String())
+		statement.Reset()
+
+		return err
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dests...); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+		c.throttleRow(values)
+		mask.Apply(values)
+
+		if statement.Len() == 0 {
+			statement.WriteString(insertPrefix)
+		} else {
+			statement.WriteByte(',')
+		}
+		writeRow(&statement, columns, values)
+
+		if statement.Len() >= c.MaxStatementBytes {
+			if err := flush(); err != nil {
+				return fmt.Errorf("failed to insert rows: %w", err)
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	if err := flush(); err != nil {
+		return fmt.Errorf("failed to insert rows: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Copier) throttleRow(values [][]byte) {
+	if c.Throttle == nil {
+		return
+	}
+
+	var n int
+	for _, v := range values {
+		n += len(v)
+	}
+	c.Throttle.Wait(1, n)
+}
+
+func tableColumns(ctx context.Context, conn *sql.Conn, schema, table string) ([]column, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT COLUMN_NAME, DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND GENERATION_EXPRESSION = '' ORDER BY ORDINAL_POSITION`, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve columns: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var columns []column
+	for rows.Next() {
+		var col column
+		if err := rows.Scan(&col.name, &col.dataType); err != nil {
+			return nil, fmt.Errorf("failed to scan the list of columns: %w", err)
+		}
+		col.dataType = strings.ToLower(col.dataType)
+
+		columns = append(columns, col)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to prepare the list of columns: %w", err)
+	}
+
+	return columns, nil
+}
+
+// createViews creates views once every table exists. A view selecting from another view fails until that one
+// was created, so views are retried as long as any of them could be created.
+func (c *Copier) createViews(ctx context.Context, src, dest *sql.Conn, views []view, progress io.Writer) error {
+	for len(views) > 0 {
+		var (
+			failed []view
+			errs   error
+		)
+
+		for _, v := range views {
+			if err := c.createView(ctx, src, dest, v, progress); err != nil {
+				failed = append(failed, v)
+				errs = multierror.Append(errs, fmt.Errorf("failed to create view %s.%s: %w", v.schema, v.name, err))
+			}
+		}
+
+		if len(failed) == len(views) {
+			return errs
+		}
+		views = failed
+	}
+
+	return nil
+}
+
+// createView reads the definition of the view while using its schema, so that references to that schema are not
+// qualified and resolve to the recipient schema
+func (c *Copier) createView(ctx context.Context, src, dest *sql.Conn, v view, progress io.Writer) error {
+	if _, err := src.ExecContext(ctx, "USE "+discovery.QuoteIdentifier(v.schema)); err != nil {
+		return err
+	}
+
+	var name, ddl, charset, collation string
+	if err := src.QueryRowContext(ctx, "SHOW CREATE VIEW "+discovery.QuoteIdentifier(v.name)).Scan(&name, &ddl, &charset, &collation); err != nil {
+		return fmt.Errorf("failed to read the view definition: %w", err)
+	}
+
+	_, _ = fmt.Fprintf(progress, "\n--\n-- Final view structure for view %s\n--\n\n", discovery.QuoteIdentifier(v.name))
+
+	return execAll(ctx, dest, progress,
+		"USE "+discovery.QuoteIdentifier(c.recipientSchema(v.schema)),
+		"DROP TABLE IF EXISTS "+discovery.QuoteIdentifier(v.name),
+		"DROP VIEW IF EXISTS "+discovery.QuoteIdentifier(v.name),
+		definer.Rewrite(ddl, c.definers),
+	)
+}
+
+// definitionColumns are the columns of SHOW CREATE holding the definition of each type of stored program
+var definitionColumns = map[string]string{
+	"PROCEDURE": "Create Procedure",
+	"FUNCTION":  "Create Function",
+	"TRIGGER":   "SQL Original Statement",
+	"EVENT":     "Create Event",
+}
+
+func (c *Copier) createStoredProgram(ctx context.Context, dest *sql.Conn, p discovery.StoredProgram) error {
+	definitionColumn, ok := definitionColumns[p.Type]
+	if !ok {
+		return fmt.Errorf("unsupported stored program type %q", p.Type)
+	}
+
+	rows, err := c.source.QueryContext(ctx, "SHOW CREATE "+p.Type+" "+qualifiedName(p.Schema, p.Name))
+	if err != nil {
+		return fmt.Errorf("failed to read the definition: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	values, err := scanRow(rows)
+	if err != nil {
+		return fmt.Errorf("failed to read the definition: %w", err)
+	}
+
+	// The definition of a routine is NULL for users that may not see it
+	if values[definitionColumn] == nil {
+		return errors.New("the definition is not visible to the migrating user")
+	}
+
+	// Stored programs run with the sql_mode they were created with, and events with their time zone
+	timeZone := []byte("+00:00")
+	if values["time_zone"] != nil {
+		timeZone = values["time_zone"]
+	}
+
+	return execAll(ctx, dest, io.Discard,
+		"USE "+discovery.QuoteIdentifier(c.recipientSchema(p.Schema)),
+		"SET SESSION sql_mode = "+quoteString(values["sql_mode"]),
+		"SET SESSION time_zone = "+quoteString(timeZone),
+		"DROP "+p.Type+" IF EXISTS "+discovery.QuoteIdentifier(p.Name),
+		definer.Rewrite(string(values[definitionColumn]), c.definers),
+		"SET SESSION sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+		"SET SESSION time_zone = '+00:00'",
+	)
+}
+
+// scanRow returns the first row of a SHOW statement by column name, or nil if there is none. SHOW statements
+// return different columns depending on the server version.
+func scanRow(rows *sql.Rows) (map[string][]byte, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([][]byte, len(columns))
+	dests := make([]any, len(columns))
+	for i := range values {
+		dests[i] = &values[i]
+	}
+
+	if err := rows.Scan(dests...); err != nil {
+		return nil, err
+	}
+
+	row := map[string][]byte{}
+	for i, name := range columns {
+		row[name] = values[i]
+	}
+
+	return row, nil
+}
+
+func qualifiedName(schema, name string) string {
+	return discovery.QuoteIdentifier(schema) + "." + discovery.QuoteIdentifier(name)
+}
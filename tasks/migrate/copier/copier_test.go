@@ -0,0 +1,428 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package copier_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+	. "github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/copier"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
+)
+
+var _ = Describe("Copier", func() {
+	const (
+		listTablesQuery  = `SELECT TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? ORDER BY TABLE_NAME`
+		listColumnsQuery = `SELECT COLUMN_NAME, DATA_TYPE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ? AND GENERATION_EXPRESSION = '' ORDER BY ORDINAL_POSITION`
+	)
+
+	var (
+		sourceDB   *sql.DB
+		sourceMock sqlmock.Sqlmock
+		destDB     *sql.DB
+		destMock   sqlmock.Sqlmock
+		progress   *bytes.Buffer
+		copier     *Copier
+	)
+
+	expectExecs := func(mock sqlmock.Sqlmock, statements ...string) {
+		for _, statement := range statements {
+			mock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+	}
+
+	expectSourceSession := func() {
+		expectExecs(sourceMock, "SET NAMES utf8mb4", "SET SESSION time_zone = '+00:00'")
+	}
+
+	expectSnapshot := func() {
+		expectSourceSession()
+		expectExecs(sourceMock,
+			"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+			"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+		)
+	}
+
+	expectDestSession := func() {
+		expectExecs(destMock,
+			"SET NAMES utf8mb4",
+			"SET SESSION time_zone = '+00:00'",
+			"SET SESSION foreign_key_checks = 0",
+			"SET SESSION unique_checks = 0",
+			"SET SESSION sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+		)
+	}
+
+	expectTables := func(schema string, tables ...[2]string) {
+		rows := sqlmock.NewRows([]string{"TABLE_NAME", "TABLE_TYPE"})
+		for _, t := range tables {
+			rows.AddRow(t[0], t[1])
+		}
+		sourceMock.ExpectQuery(listTablesQuery).WithArgs(schema).WillReturnRows(rows)
+	}
+
+	expectCreateTable := func(schema, table string) {
+		ddl := "CREATE TABLE `" + table + "` (\n  `id` int NOT NULL,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB"
+		sourceMock.ExpectQuery("SHOW CREATE TABLE `" + schema + "`.`" + table + "`").
+			WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow(table, ddl))
+		expectExecs(destMock, "DROP TABLE IF EXISTS `"+table+"`", ddl)
+	}
+
+	BeforeEach(func() {
+		var err error
+		sourceDB, sourceMock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		Expect(err).NotTo(HaveOccurred())
+		destDB, destMock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		Expect(err).NotTo(HaveOccurred())
+
+		progress = &bytes.Buffer{}
+		copier = New(sourceDB, destDB, func(string) string { return "service_instance_db" }, []string{"foo.excluded", "foo.invalid_view"}, definer.Policy{})
+	})
+
+	AfterEach(func() {
+		Expect(sourceMock.ExpectationsWereMet()).To(Succeed())
+		Expect(destMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("CopySchemas", func() {
+		It("copies the tables, rows and views of a schema from a consistent snapshot", func() {
+			expectSnapshot()
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			expectTables("foo",
+				[2]string{"excluded", "BASE TABLE"},
+				[2]string{"invalid_view", "VIEW"},
+				[2]string{"t1", "BASE TABLE"},
+				[2]string{"v1", "VIEW"},
+			)
+
+			expectCreateTable("foo", "t1")
+			sourceMock.ExpectQuery(listColumnsQuery).WithArgs("foo", "t1").
+				WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE"}).
+					AddRow("id", "int").
+					AddRow("name", "varchar").
+					AddRow("data", "blob").
+					AddRow("note", "text"))
+			sourceMock.ExpectQuery("SELECT `id`,`name`,`data`,`note` FROM `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "name", "data", "note"}).
+					AddRow(1, "it's", []byte{0x01, 0xff}, nil).
+					AddRow(2, `back\slash`, nil, "line\nbreak").
+					AddRow(3, "", []byte{}, "\x00"))
+			expectExecs(destMock, "INSERT INTO `t1` (`id`,`name`,`data`,`note`) VALUES "+
+				`(1,'it\'s',0x01ff,NULL),(2,'back\\slash',NULL,'line\nbreak'),(3,'','','\0')`)
+
+			expectExecs(sourceMock, "USE `foo`")
+			sourceMock.ExpectQuery("SHOW CREATE VIEW `v1`").
+				WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+					AddRow("v1", "CREATE ALGORITHM=UNDEFINED DEFINER=`admin`@`%` SQL SECURITY DEFINER VIEW `v1` AS select `t1`.`id` AS `id` from `t1`", "utf8mb4", "utf8mb4_0900_ai_ci"))
+			expectExecs(destMock,
+				"USE `service_instance_db`",
+				"DROP TABLE IF EXISTS `v1`",
+				"DROP VIEW IF EXISTS `v1`",
+				"CREATE ALGORITHM=UNDEFINED SQL SECURITY INVOKER VIEW `v1` AS select `t1`.`id` AS `id` from `t1`",
+			)
+			expectExecs(sourceMock, "COMMIT")
+
+			position, err := copier.CopySchemas([]string{"foo"}, Options{}, progress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(position).To(BeZero())
+
+			Expect(progress.String()).To(ContainSubstring("-- Current Database: `foo`\n"))
+			Expect(progress.String()).To(ContainSubstring("-- Dumping data for table `t1`\n"))
+			Expect(progress.String()).To(ContainSubstring("INSERT INTO `t1` (`id`,`name`,`data`,`note`) VALUES (1,"))
+		})
+
+		It("creates every schema when copying several", func() {
+			expectSnapshot()
+			expectDestSession()
+
+			copier = New(sourceDB, destDB, func(schema string) string { return schema }, nil, definer.Policy{})
+
+			for _, schema := range []string{"foo", "bar"} {
+				ddl := "CREATE DATABASE `" + schema + "` /*!40100 DEFAULT CHARACTER SET utf8mb4 */"
+				sourceMock.ExpectQuery("SHOW CREATE DATABASE `" + schema + "`").
+					WillReturnRows(sqlmock.NewRows([]string{"Database", "Create Database"}).AddRow(schema, ddl))
+				expectExecs(destMock,
+					"CREATE DATABASE IF NOT EXISTS `"+schema+"` /*!40100 DEFAULT CHARACTER SET utf8mb4 */",
+					"USE `"+schema+"`",
+				)
+				expectTables(schema, [2]string{"t1", "BASE TABLE"})
+				expectCreateTable(schema, "t1")
+			}
+			expectExecs(sourceMock, "COMMIT")
+
+			_, err := copier.CopySchemas([]string{"foo", "bar"}, Options{StructureOnly: true}, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("converts the character set of the schemas and tables it creates", func() {
+			copier.Conversion = &charset.Conversion{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci", MaxBytesPerChar: 4}
+
+			expectSnapshot()
+			expectDestSession()
+			expectExecs(destMock,
+				"ALTER DATABASE `service_instance_db` CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+				"USE `service_instance_db`",
+			)
+			expectTables("foo", [2]string{"t1", "BASE TABLE"})
+			sourceMock.ExpectQuery("SHOW CREATE TABLE `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).
+					AddRow("t1", "CREATE TABLE `t1` (`name` varchar(10) COLLATE latin1_bin) ENGINE=InnoDB DEFAULT CHARSET=latin1"))
+			expectExecs(destMock,
+				"DROP TABLE IF EXISTS `t1`",
+				"CREATE TABLE `t1` (`name` varchar(10) COLLATE utf8mb4_bin) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE utf8mb4_0900_ai_ci",
+			)
+			expectExecs(sourceMock, "COMMIT")
+
+			_, err := copier.CopySchemas([]string{"foo"}, Options{StructureOnly: true}, progress)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the binlog position the snapshot is consistent with", func() {
+			expectSourceSession()
+			expectExecs(sourceMock,
+				"FLUSH TABLES WITH READ LOCK",
+				"SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ",
+				"START TRANSACTION WITH CONSISTENT SNAPSHOT",
+			)
+			sourceMock.ExpectQuery("SHOW MASTER STATUS").
+				WillReturnRows(sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}).
+					AddRow("mysql-bin.000003", 154, "", "", ""))
+			expectExecs(sourceMock, "UNLOCK TABLES")
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			expectTables("foo")
+			expectExecs(sourceMock, "COMMIT")
+
+			position, err := copier.CopySchemas([]string{"foo"}, Options{RecordPosition: true}, progress)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(position).To(Equal(Position{File: "mysql-bin.000003", Position: 154}))
+		})
+
+		It("splits the rows of large tables into several statements", func() {
+			copier.MaxStatementBytes = 36
+
+			expectSnapshot()
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			expectTables("foo", [2]string{"t1", "BASE TABLE"})
+			expectCreateTable("foo", "t1")
+			sourceMock.ExpectQuery(listColumnsQuery).WithArgs("foo", "t1").
+				WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE"}).AddRow("id", "int"))
+			sourceMock.ExpectQuery("SELECT `id` FROM `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+			expectExecs(destMock,
+				"INSERT INTO `t1` (`id`) VALUES (1),(2)",
+				"INSERT INTO `t1` (`id`) VALUES (3)",
+			)
+			expectExecs(sourceMock, "COMMIT")
+
+			_, err := copier.CopySchemas([]string{"foo"}, Options{}, progress)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("retries views that depend on views created later", func() {
+			expectSnapshot()
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			expectTables("foo", [2]string{"a_view", "VIEW"}, [2]string{"b_view", "VIEW"})
+
+			expectView := func(name, ddl string) {
+				expectExecs(sourceMock, "USE `foo`")
+				sourceMock.ExpectQuery("SHOW CREATE VIEW `" + name + "`").
+					WillReturnRows(sqlmock.NewRows([]string{"View", "Create View", "character_set_client", "collation_connection"}).
+						AddRow(name, ddl, "utf8mb4", "utf8mb4_0900_ai_ci"))
+				expectExecs(destMock, "USE `service_instance_db`", "DROP TABLE IF EXISTS `"+name+"`", "DROP VIEW IF EXISTS `"+name+"`")
+			}
+
+			aView := "CREATE VIEW `a_view` AS select 1 AS `x` from `b_view`"
+			bView := "CREATE VIEW `b_view` AS select 1 AS `x`"
+
+			expectView("a_view", aView)
+			destMock.ExpectExec(aView).WillReturnError(errors.New("Table 'b_view' doesn't exist"))
+			expectView("b_view", bView)
+			expectExecs(destMock, bView)
+			expectView("a_view", aView)
+			expectExecs(destMock, aView)
+			expectExecs(sourceMock, "COMMIT")
+
+			_, err := copier.CopySchemas([]string{"foo"}, Options{}, progress)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error when a table can not be created", func() {
+			expectSnapshot()
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			expectTables("foo", [2]string{"t1", "BASE TABLE"})
+			sourceMock.ExpectQuery("SHOW CREATE TABLE `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"Table", "Create Table"}).AddRow("t1", "CREATE TABLE `t1` (`id` int)"))
+			expectExecs(destMock, "DROP TABLE IF EXISTS `t1`")
+			destMock.ExpectExec("CREATE TABLE `t1` (`id` int)").WillReturnError(errors.New("access denied"))
+
+			_, err := copier.CopySchemas([]string{"foo"}, Options{}, progress)
+			Expect(err).To(MatchError("failed to copy the structure of foo.t1: access denied"))
+		})
+	})
+
+	Context("CopyTableRows", func() {
+		It("copies the rows of a single table into its recipient schema", func() {
+			expectSourceSession()
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			sourceMock.ExpectQuery(listColumnsQuery).WithArgs("foo", "t1").
+				WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE"}).AddRow("id", "bigint").AddRow("flags", "bit"))
+			sourceMock.ExpectQuery("SELECT `id`,`flags` FROM `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "flags"}).AddRow(7, []byte{0x05}))
+			expectExecs(destMock, "INSERT INTO `t1` (`id`,`flags`) VALUES (7,0x05)")
+
+			Expect(copier.CopyTableRows("foo", "t1", progress)).To(Succeed())
+			Expect(progress.String()).To(ContainSubstring("-- Dumping data for table `t1`\n"))
+		})
+
+		It("masks the values of columns with masking rules", func() {
+			copier.Masker = masking.NewMasker(masking.Rules{Tables: map[string]map[string]masking.Transform{
+				"foo.t1": {"id": {Kind: masking.Fixed, Value: "0"}, "email": {Kind: masking.Null}},
+			}})
+
+			expectSourceSession()
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			sourceMock.ExpectQuery(listColumnsQuery).WithArgs("foo", "t1").
+				WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE"}).AddRow("id", "bigint").AddRow("email", "varchar").AddRow("name", "varchar"))
+			sourceMock.ExpectQuery("SELECT `id`,`email`,`name` FROM `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"id", "email", "name"}).AddRow(7, "jane@example.org", "Jane"))
+			expectExecs(destMock, "INSERT INTO `t1` (`id`,`email`,`name`) VALUES ('0',NULL,'Jane')")
+
+			Expect(copier.CopyTableRows("foo", "t1", progress)).To(Succeed())
+		})
+	})
+
+	Context("ShareSnapshot", func() {
+		It("starts a snapshot per worker under a global read lock and copies rows from them", func() {
+			expectSourceSession()
+			expectExecs(sourceMock, "FLUSH TABLES WITH READ LOCK")
+			expectSnapshot()
+			expectSnapshot()
+			sourceMock.ExpectQuery("SHOW MASTER STATUS").
+				WillReturnRows(sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}).
+					AddRow("mysql-bin.000003", 154, "", "", ""))
+			expectExecs(sourceMock, "UNLOCK TABLES")
+
+			position, release, err := copier.ShareSnapshot([]discovery.Table{{Schema: "foo", Name: "t1"}}, 2, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(position).To(Equal(Position{File: "mysql-bin.000003", Position: 154}))
+
+			expectDestSession()
+			expectExecs(destMock, "USE `service_instance_db`")
+			sourceMock.ExpectQuery(listColumnsQuery).WithArgs("foo", "t1").
+				WillReturnRows(sqlmock.NewRows([]string{"COLUMN_NAME", "DATA_TYPE"}).AddRow("id", "bigint"))
+			sourceMock.ExpectQuery("SELECT `id` FROM `foo`.`t1`").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+			expectExecs(destMock, "INSERT INTO `t1` (`id`) VALUES (7)")
+
+			Expect(copier.CopyTableRows("foo", "t1", progress)).To(Succeed())
+
+			expectExecs(sourceMock, "COMMIT", "COMMIT")
+			Expect(release()).To(Succeed())
+		})
+
+		It("only locks the copied tables when no binlog position is recorded", func() {
+			expectSourceSession()
+			expectExecs(sourceMock, "LOCK TABLES `foo`.`t1` READ, `foo`.`t2` READ")
+			expectSnapshot()
+			expectExecs(sourceMock, "UNLOCK TABLES")
+
+			position, release, err := copier.ShareSnapshot([]discovery.Table{{Schema: "foo", Name: "t1"}, {Schema: "foo", Name: "t2"}}, 1, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(position).To(Equal(Position{}))
+
+			expectExecs(sourceMock, "COMMIT")
+			Expect(release()).To(Succeed())
+		})
+
+		It("ends the snapshots already started when one of them fails", func() {
+			expectSourceSession()
+			expectExecs(sourceMock, "FLUSH TABLES WITH READ LOCK")
+			expectSnapshot()
+			expectSourceSession()
+			sourceMock.ExpectExec("SET SESSION TRANSACTION ISOLATION LEVEL REPEATABLE READ").WillReturnError(errors.New("too many connections"))
+			expectExecs(sourceMock, "COMMIT", "COMMIT")
+
+			_, _, err := copier.ShareSnapshot(nil, 2, true)
+			Expect(err).To(MatchError("failed to take a snapshot of the source: too many connections"))
+		})
+	})
+
+	Context("CopyStoredPrograms", func() {
+		It("creates every stored program owned by the current user", func() {
+			expectDestSession()
+
+			sourceMock.ExpectQuery("SHOW CREATE PROCEDURE `foo`.`p1`").
+				WillReturnRows(sqlmock.NewRows([]string{"Procedure", "sql_mode", "Create Procedure", "character_set_client", "collation_connection", "Database Collation"}).
+					AddRow("p1", "STRICT_TRANS_TABLES", "CREATE DEFINER=`admin`@`%` PROCEDURE `p1`() SELECT 'DEFINER=`x`@`y`'", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"))
+			expectExecs(destMock,
+				"USE `service_instance_db`",
+				"SET SESSION sql_mode = 'STRICT_TRANS_TABLES'",
+				"SET SESSION time_zone = '+00:00'",
+				"DROP PROCEDURE IF EXISTS `p1`",
+				"CREATE DEFINER=CURRENT_USER PROCEDURE `p1`() SELECT 'DEFINER=`x`@`y`'",
+				"SET SESSION sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+				"SET SESSION time_zone = '+00:00'",
+			)
+
+			sourceMock.ExpectQuery("SHOW CREATE EVENT `foo`.`e1`").
+				WillReturnRows(sqlmock.NewRows([]string{"Event", "sql_mode", "time_zone", "Create Event", "character_set_client", "collation_connection", "Database Collation"}).
+					AddRow("e1", "", "SYSTEM", "CREATE DEFINER=`admin`@`%` EVENT `e1` ON SCHEDULE EVERY 1 DAY DO DELETE FROM t1", "utf8mb4", "utf8mb4_0900_ai_ci", "utf8mb4_0900_ai_ci"))
+			expectExecs(destMock,
+				"USE `service_instance_db`",
+				"SET SESSION sql_mode = ''",
+				"SET SESSION time_zone = 'SYSTEM'",
+				"DROP EVENT IF EXISTS `e1`",
+				"CREATE DEFINER=CURRENT_USER EVENT `e1` ON SCHEDULE EVERY 1 DAY DO DELETE FROM t1",
+				"SET SESSION sql_mode = 'NO_AUTO_VALUE_ON_ZERO'",
+				"SET SESSION time_zone = '+00:00'",
+			)
+
+			Expect(copier.CopyStoredPrograms([]discovery.StoredProgram{
+				{Schema: "foo", Name: "p1", Type: "PROCEDURE"},
+				{Schema: "foo", Name: "e1", Type: "EVENT"},
+			})).To(Succeed())
+		})
+
+		It("attempts every stored program and returns the failures", func() {
+			expectDestSession()
+
+			sourceMock.ExpectQuery("SHOW CREATE FUNCTION `foo`.`f1`").
+				WillReturnRows(sqlmock.NewRows([]string{"Function", "sql_mode", "Create Function"}).AddRow("f1", "", nil))
+			sourceMock.ExpectQuery("SHOW CREATE TRIGGER `foo`.`t1`").
+				WillReturnError(errors.New("trigger does not exist"))
+
+			err := copier.CopyStoredPrograms([]discovery.StoredProgram{
+				{Schema: "foo", Name: "f1", Type: "FUNCTION"},
+				{Schema: "foo", Name: "t1", Type: "TRIGGER", Table: "orders"},
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("failed to create FUNCTION foo.f1: the definition is not visible to the migrating user"))
+			Expect(err.Error()).To(ContainSubstring("failed to create TRIGGER foo.t1: failed to read the definition: trigger does not exist"))
+		})
+	})
+})
@@ -0,0 +1,88 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package copier
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// Numbers are written as they are read, and binary strings as hex literals, so that they do not depend on the
+// character set of the connection
+var (
+	numericTypes = map[string]bool{
+		"tinyint": true, "smallint": true, "mediumint": true, "int": true, "bigint": true,
+		"decimal": true, "float": true, "double": true, "year": true,
+	}
+	binaryTypes = map[string]bool{
+		"binary": true, "varbinary": true, "tinyblob": true, "blob": true, "mediumblob": true, "longblob": true,
+		"bit": true, "geometry": true, "point": true, "linestring": true, "polygon": true, "multipoint": true,
+		"multilinestring": true, "multipolygon": true, "geometrycollection": true, "geomcollection": true,
+	}
+)
+
+func writeRow(b *strings.Builder, columns []column, values [][]byte) {
+	b.WriteByte('(')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		writeValue(b, columns[i].dataType, value)
+	}
+	b.WriteByte(')')
+}
+
+func writeValue(b *strings.Builder, dataType string, value []byte) {
+	switch {
+	case value == nil:
+		b.WriteString("NULL")
+	case numericTypes[dataType]:
+		b.Write(value)
+	case binaryTypes[dataType] && len(value) > 0:
+		b.WriteString("0x")
+		b.WriteString(hex.EncodeToString(value))
+	default:
+		b.WriteString(quoteString(value))
+	}
+}
+
+// quoteString quotes a string literal the way mysql_real_escape_string does
+func quoteString(value []byte) string {
+	var b strings.Builder
+	b.Grow(len(value) + 2)
+
+	b.WriteByte('\'')
+	for _, c := range value {
+		switch c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case 0x1a:
+			b.WriteString(`\Z`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('\'')
+
+	return b.String()
+}
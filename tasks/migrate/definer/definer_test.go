@@ -0,0 +1,168 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package definer_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/iotest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+)
+
+var _ = Describe("Definer", func() {
+	readFixture := func(name string) string {
+		contents, err := os.ReadFile(filepath.Join("fixtures", name))
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	mapped := Policy{Definer: "`app`@`%`"}
+	renamed := Policy{Definer: "`root`@`localhost`", Schemas: map[string]string{"sakila": "pagila", "unused": "other"}}
+
+	DescribeTable("Filter",
+		func(input, expected string, policy Policy) {
+			var out bytes.Buffer
+			Expect(Filter(&out, strings.NewReader(readFixture(input)), policy)).To(Succeed())
+			Expect(out.String()).To(Equal(readFixture(expected)))
+
+			By("reading the dump one byte at a time")
+			out.Reset()
+			Expect(Filter(&out, iotest.OneByteReader(strings.NewReader(readFixture(input))), policy)).To(Succeed())
+			Expect(out.String()).To(Equal(readFixture(expected)))
+		},
+		Entry("views converted to SQL SECURITY INVOKER", "views.sql", "views.invoker.sql", Policy{}),
+		Entry("views mapped to a definer", "views.sql", "views.mapped.sql", mapped),
+		Entry("stored programs owned by the loading user", "stored_programs.sql", "stored_programs.invoker.sql", Policy{}),
+		Entry("stored programs mapped to a definer", "stored_programs.sql", "stored_programs.mapped.sql", mapped),
+		Entry("tables and rows with the invoker policy", "data.sql", "data.sql", Policy{}),
+		Entry("tables and rows with a mapped definer", "data.sql", "data.sql", mapped),
+		Entry("schemas renamed", "schemas.sql", "schemas.renamed.sql", renamed),
+		Entry("schemas not renamed", "schemas.sql", "schemas.sql", Policy{Definer: renamed.Definer}),
+		Entry("tables and rows with renamed schemas", "data.sql", "data.sql", Policy{Schemas: map[string]string{"definers": "other", "root": "other"}}),
+	)
+
+	It("copies rows longer than its buffer", func() {
+		value := strings.Repeat("it''s \\' a `row`; -- ", 20000)
+		dump := "INSERT INTO `t` VALUES ('" + value + "'),(`sakila`.`x`);\nCREATE DEFINER=`root`@`%` VIEW v AS SELECT 1;\n"
+
+		var out bytes.Buffer
+		Expect(Filter(&out, strings.NewReader(dump), renamed)).To(Succeed())
+		Expect(out.String()).To(Equal("INSERT INTO `t` VALUES ('" + value + "'),(`pagila`.`x`);\nCREATE DEFINER=`root`@`localhost` VIEW v AS SELECT 1;\n"))
+	})
+
+	It("copies an unterminated statement", func() {
+		var out bytes.Buffer
+		Expect(Filter(&out, strings.NewReader("CREATE DEFINER=`root`@`%` VIEW v AS SELECT 'unterminated"), Policy{})).To(Succeed())
+		Expect(out.String()).To(Equal("CREATE SQL SECURITY INVOKER VIEW v AS SELECT 'unterminated"))
+	})
+
+	It("returns errors reading the dump", func() {
+		var out bytes.Buffer
+		err := Filter(&out, iotest.ErrReader(io.ErrUnexpectedEOF), Policy{})
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	Describe("NewReader", func() {
+		It("streams the filtered dump", func() {
+			r := NewReader(strings.NewReader(readFixture("views.sql")), mapped)
+			defer r.Close()
+
+			out, err := io.ReadAll(r)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(out)).To(Equal(readFixture("views.mapped.sql")))
+		})
+
+		It("returns errors reading the dump", func() {
+			r := NewReader(iotest.ErrReader(io.ErrUnexpectedEOF), Policy{})
+			defer r.Close()
+
+			_, err := io.ReadAll(r)
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		})
+	})
+
+	Describe("Rewrite", func() {
+		It("rewrites the output of SHOW CREATE", func() {
+			Expect(Rewrite("CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `v` AS select 1 AS `1`", Policy{})).
+				To(Equal("CREATE ALGORITHM=UNDEFINED SQL SECURITY INVOKER VIEW `v` AS select 1 AS `1`"))
+			Expect(Rewrite("CREATE DEFINER=`root`@`localhost` TRIGGER `t` BEFORE INSERT ON `x` FOR EACH ROW SET NEW.a = 'DEFINER=`root`@`localhost`'", mapped)).
+				To(Equal("CREATE DEFINER=`app`@`%` TRIGGER `t` BEFORE INSERT ON `x` FOR EACH ROW SET NEW.a = 'DEFINER=`root`@`localhost`'"))
+		})
+
+		It("only rewrites the header of a routine whose body has several statements", func() {
+			Expect(Rewrite("CREATE DEFINER=`root`@`localhost` EVENT `e` ON SCHEDULE EVERY 1 DAY DO BEGIN DELETE FROM t; CREATE DEFINER=`root`@`%` VIEW v AS SELECT 1; END", Policy{})).
+				To(Equal("CREATE DEFINER=CURRENT_USER EVENT `e` ON SCHEDULE EVERY 1 DAY DO BEGIN DELETE FROM t; CREATE DEFINER=`root`@`%` VIEW v AS SELECT 1; END"))
+		})
+	})
+
+	Describe("ParsePolicy", func() {
+		It("parses the invoker policy", func() {
+			policy, err := ParsePolicy("invoker")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(Policy{}))
+			Expect(policy.String()).To(Equal("invoker"))
+		})
+
+		DescribeTable("parses a definer",
+			func(value, expected string) {
+				policy, err := ParsePolicy(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(policy.Definer).To(Equal(expected))
+				Expect(policy.String()).To(Equal(expected))
+			},
+			Entry("unquoted", "app@%", "`app`@`%`"),
+			Entry("quoted with backticks", "`app`@`localhost`", "`app`@`localhost`"),
+			Entry("quoted with single quotes", "'o''brien'@'10.0.%'", "`o'brien`@`10.0.%`"),
+			Entry("with an @ in the user name", "me@example.com@%", "`me@example.com`@`%`"),
+			Entry("with a backtick in the user name", "a`b@%", "`a``b`@`%`"),
+		)
+
+		DescribeTable("parses a schema mapping",
+			func(value, from, to string) {
+				parsedFrom, parsedTo, err := ParseSchemaMapping(value)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parsedFrom).To(Equal(from))
+				Expect(parsedTo).To(Equal(to))
+			},
+			Entry("unquoted", "sakila=pagila", "sakila", "pagila"),
+			Entry("quoted with backticks", "`old`=`new db`", "old", "new db"),
+		)
+
+		DescribeTable("rejects invalid schema mappings",
+			func(value string) {
+				_, _, err := ParseSchemaMapping(value)
+				Expect(err).To(MatchError(`invalid schema mapping "` + value + `", expected <from>=<to>`))
+			},
+			Entry("without a target", "sakila"),
+			Entry("with an empty source", "=pagila"),
+			Entry("with an empty target", "sakila="),
+		)
+
+		DescribeTable("rejects invalid definers",
+			func(value string) {
+				_, err := ParsePolicy(value)
+				Expect(err).To(MatchError(`invalid definer "` + value + `", expected "invoker" or <user>@<host>`))
+			},
+			Entry("without a host", "app"),
+			Entry("with an empty user", "@%"),
+			Entry("with an empty host", "app@"),
+		)
+	})
+})
@@ -0,0 +1,493 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package definer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+const (
+	bufferSize = 64 * 1024
+
+	// maxHeaderTokens bounds how much of a statement is read ahead before it is known whether it creates a view or
+	// stored program. The DEFINER clause always comes within the first few tokens.
+	maxHeaderTokens = 64
+)
+
+// objectKeywords are the objects whose CREATE statements may have a DEFINER clause
+var objectKeywords = map[string]bool{
+	"VIEW":      true,
+	"TRIGGER":   true,
+	"PROCEDURE": true,
+	"FUNCTION":  true,
+	"EVENT":     true,
+}
+
+// headerKeywords may precede the DEFINER and SQL SECURITY clauses of such a statement
+var headerKeywords = map[string]bool{
+	"OR":        true,
+	"REPLACE":   true,
+	"ALGORITHM": true,
+	"UNDEFINED": true,
+	"MERGE":     true,
+	"TEMPTABLE": true,
+	"AGGREGATE": true,
+}
+
+// Filter copies the mysqldump output read from r to w, rewriting the DEFINER clause in the header of every CREATE
+// VIEW, TRIGGER, PROCEDURE, FUNCTION and EVENT statement and renaming schemas according to policy. Everything else,
+// including rows and routine bodies containing the same text, is copied unchanged.
+func Filter(w io.Writer, r io.Reader, policy Policy) error {
+	f := newFilter(w, r, policy)
+
+	for {
+		if err := f.statement(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return f.out.Flush()
+			}
+			return err
+		}
+	}
+}
+
+// NewReader returns the output of Filter applied to r. Closing it stops the filter.
+func NewReader(r io.Reader, policy Policy) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		pw.CloseWithError(Filter(pw, r, policy))
+	}()
+
+	return pr
+}
+
+// Rewrite applies the policy to a single statement, such as the output of SHOW CREATE VIEW. Semicolons in the body
+// of a routine do not end the statement.
+func Rewrite(statement string, policy Policy) string {
+	var out strings.Builder
+	f := newFilter(&out, strings.NewReader(statement), policy)
+
+	if err := f.statement(); err == nil {
+		_, _ = io.Copy(f.out, f.in)
+	}
+	_ = f.out.Flush()
+
+	return out.String()
+}
+
+type tokenKind int
+
+const (
+	blank tokenKind = iota
+	comment
+	// marker is the start or end of a versioned comment, whose content is executed like any other SQL
+	marker
+	word
+	quoted
+	symbol
+	delimiter
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) significant() bool {
+	return t.kind != blank && t.kind != comment && t.kind != marker
+}
+
+type filter struct {
+	in        *bufio.Reader
+	out       *bufio.Writer
+	policy    Policy
+	delimiter string
+	// namesSchema is set while copying a statement whose only identifier is a schema, such as USE
+	namesSchema bool
+}
+
+func newFilter(w io.Writer, r io.Reader, policy Policy) *filter {
+	return &filter{
+		in:        bufio.NewReaderSize(r, bufferSize),
+		out:       bufio.NewWriterSize(w, bufferSize),
+		policy:    policy,
+		delimiter: ";",
+	}
+}
+
+// statement reads the header of the next statement, rewrites it if it creates a view or stored program, and
+// copies the rest of the statement
+func (f *filter) statement() error {
+	var (
+		tokens        []token
+		h             = newHeader()
+		schemaPending bool
+	)
+
+	emit := func() {
+		for _, t := range tokens {
+			_, _ = f.out.WriteString(t.text)
+		}
+	}
+
+	for {
+		t, err := f.nextToken()
+		if err != nil {
+			emit()
+			return err
+		}
+
+		// Comments and blank lines between statements are never rewritten
+		if len(tokens) == 0 && !t.significant() {
+			_, _ = f.out.WriteString(t.text)
+			continue
+		}
+
+		tokens = append(tokens, t)
+
+		switch {
+		case t.kind == delimiter:
+			emit()
+			return nil
+		case !t.significant():
+			continue
+		case len(tokens) > maxHeaderTokens:
+			emit()
+			return f.copyBody()
+		}
+
+		// ALTER and DROP are only known to name a schema once the next word was read
+		if schemaPending {
+			f.namesSchema = namesSchema(tokens)
+			emit()
+			return f.copySchemaBody()
+		}
+
+		switch h.feed(t, len(tokens)-1) {
+		case undecided:
+			continue
+		case delimiterCommand:
+			emit()
+			return f.delimiterCommand()
+		case matched:
+			h.rewrite(tokens, f.policy)
+		case passThrough:
+			if upper := strings.ToUpper(t.text); len(tokens) == 1 && (upper == "ALTER" || upper == "DROP") {
+				schemaPending = true
+				continue
+			}
+			f.namesSchema = namesSchema(tokens)
+		}
+
+		emit()
+		return f.copySchemaBody()
+	}
+}
+
+// delimiterCommand reads the new delimiter from the rest of a DELIMITER line
+func (f *filter) delimiterCommand() error {
+	line, err := f.in.ReadString('\n')
+	_, _ = f.out.WriteString(line)
+
+	if d := strings.TrimSpace(line); d != "" {
+		f.delimiter = d
+	}
+
+	return err
+}
+
+func (f *filter) atDelimiter() bool {
+	next, _ := f.in.Peek(len(f.delimiter))
+	return string(next) == f.delimiter
+}
+
+// startsComment reports whether the next bytes start a comment, and whether it ends at the end of the line. A
+// double dash only starts a comment when followed by whitespace or a control character.
+func (f *filter) startsComment() (ok, toEndOfLine bool) {
+	next, _ := f.in.Peek(3)
+
+	switch {
+	case len(next) >= 1 && next[0] == '#':
+		return true, true
+	case len(next) >= 2 && next[0] == '-' && next[1] == '-':
+		return len(next) == 2 || next[2] <= ' ', true
+	case len(next) >= 2 && next[0] == '/' && next[1] == '*':
+		return len(next) == 2 || next[2] != '!', false
+	}
+
+	return false, false
+}
+
+func (f *filter) nextToken() (token, error) {
+	next, err := f.in.Peek(1)
+	if err != nil {
+		return token{}, err
+	}
+	c := next[0]
+
+	if c == f.delimiter[0] && f.atDelimiter() {
+		_, _ = f.in.Discard(len(f.delimiter))
+		return token{kind: delimiter, text: f.delimiter}, nil
+	}
+
+	if ok, toEndOfLine := f.startsComment(); ok {
+		var b bytes.Buffer
+		if toEndOfLine {
+			err = f.copyLine(&b)
+		} else {
+			err = f.copyBlockComment(&b)
+		}
+		return token{kind: comment, text: b.String()}, ignoreEOF(err)
+	}
+
+	switch {
+	case isSpace(c):
+		return token{kind: blank, text: f.readWhile(isSpace)}, nil
+	case discovery.IsWordByte(c):
+		return token{kind: word, text: f.readWhile(discovery.IsWordByte)}, nil
+	case discovery.IsQuote(c):
+		var b bytes.Buffer
+		_, _ = f.in.Discard(1)
+		b.WriteByte(c)
+		err := f.copyQuoted(&b, c)
+		return token{kind: quoted, text: b.String()}, ignoreEOF(err)
+	}
+
+	if next, _ := f.in.Peek(3); len(next) == 3 && string(next) == "/*!" {
+		_, _ = f.in.Discard(3)
+		return token{kind: marker, text: "/*!" + f.readWhile(isDigit)}, nil
+	}
+
+	if next, _ := f.in.Peek(2); len(next) == 2 && string(next) == "*/" {
+		_, _ = f.in.Discard(2)
+		return token{kind: marker, text: "*/"}, nil
+	}
+
+	_, _ = f.in.Discard(1)
+	return token{kind: symbol, text: string(c)}, nil
+}
+
+func (f *filter) readWhile(match func(byte) bool) string {
+	var b strings.Builder
+	for {
+		next, err := f.in.Peek(1)
+		if err != nil || !match(next[0]) {
+			return b.String()
+		}
+
+		b.WriteByte(next[0])
+		_, _ = f.in.Discard(1)
+	}
+}
+
+func (f *filter) copySchemaBody() error {
+	defer func() { f.namesSchema = false }()
+	return f.copyBody()
+}
+
+// copyBody copies the rest of a statement up to and including its delimiter. Text that cannot start a delimiter,
+// comment, string or quoted identifier is copied straight from the read buffer.
+func (f *filter) copyBody() error {
+	var afterDot bool
+	for {
+		buffered, err := f.peek(1)
+		if len(buffered) == 0 {
+			return err
+		}
+
+		if n := f.plainLength(buffered); n > 0 {
+			afterDot = buffered[n-1] == '.'
+			_, _ = f.out.Write(buffered[:n])
+			_, _ = f.in.Discard(n)
+			continue
+		}
+		c := buffered[0]
+
+		if c == f.delimiter[0] && f.atDelimiter() {
+			_, _ = f.in.Discard(len(f.delimiter))
+			_, _ = f.out.WriteString(f.delimiter)
+			return nil
+		}
+
+		if c == '#' || c == '-' || c == '/' {
+			if ok, toEndOfLine := f.startsComment(); ok {
+				if toEndOfLine {
+					err = f.copyLine(f.out)
+				} else {
+					err = f.copyBlockComment(f.out)
+				}
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		_, _ = f.in.Discard(1)
+
+		if c == '`' && len(f.policy.Schemas) > 0 {
+			if err := f.copyIdentifier(afterDot); err != nil {
+				return err
+			}
+			afterDot = false
+			continue
+		}
+
+		_ = f.out.WriteByte(c)
+		afterDot = false
+
+		if discovery.IsQuote(c) {
+			if err := f.copyQuoted(f.out, c); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// plainLength returns the length of the text at the start of b that cannot start a delimiter, comment, string or
+// quoted identifier
+func (f *filter) plainLength(b []byte) int {
+	for i, c := range b {
+		if c == f.delimiter[0] || c == '#' || c == '-' || c == '/' || discovery.IsQuote(c) {
+			return i
+		}
+	}
+
+	return len(b)
+}
+
+// peek returns the buffered input, reading more first when fewer than min bytes are buffered
+func (f *filter) peek(min int) ([]byte, error) {
+	return f.in.Peek(max(min, f.in.Buffered()))
+}
+
+// copyIdentifier copies the rest of a quoted identifier, renaming it when it is a schema. An identifier names a
+// schema when it starts a qualified name such as `schema`.`table`, or in statements like USE.
+func (f *filter) copyIdentifier(afterDot bool) error {
+	var b bytes.Buffer
+	b.WriteByte('`')
+	if err := f.copyQuoted(&b, '`'); err != nil {
+		_, _ = f.out.Write(b.Bytes())
+		return err
+	}
+
+	next, _ := f.in.Peek(1)
+	qualifies := len(next) == 1 && next[0] == '.' && !afterDot
+
+	if to, ok := f.policy.Schemas[unquote(b.String())]; ok && (qualifies || f.namesSchema) {
+		_, _ = f.out.WriteString(discovery.QuoteIdentifier(to))
+		return nil
+	}
+
+	_, _ = f.out.Write(b.Bytes())
+	return nil
+}
+
+// namesSchema reports whether the significant tokens starting a statement are USE, or CREATE, ALTER or DROP
+// followed by DATABASE or SCHEMA
+func namesSchema(tokens []token) bool {
+	var words []string
+	for _, t := range tokens {
+		if t.significant() {
+			words = append(words, strings.ToUpper(t.text))
+		}
+	}
+
+	switch {
+	case len(words) == 1:
+		return words[0] == "USE"
+	case len(words) == 2:
+		object := words[1] == "DATABASE" || words[1] == "SCHEMA"
+		return object && (words[0] == "CREATE" || words[0] == "ALTER" || words[0] == "DROP")
+	}
+
+	return false
+}
+
+type byteWriter interface {
+	io.Writer
+	WriteByte(c byte) error
+}
+
+func (f *filter) copyLine(w byteWriter) error {
+	line, err := f.in.ReadSlice('\n')
+	for errors.Is(err, bufio.ErrBufferFull) {
+		_, _ = w.Write(line)
+		line, err = f.in.ReadSlice('\n')
+	}
+	_, _ = w.Write(line)
+
+	return err
+}
+
+func (f *filter) copyBlockComment(w byteWriter) error {
+	_, _ = f.in.Discard(2)
+	_, _ = w.Write([]byte("/*"))
+
+	var previous byte
+	for {
+		c, err := f.in.ReadByte()
+		if err != nil {
+			return err
+		}
+		_ = w.WriteByte(c)
+
+		if previous == '*' && c == '/' {
+			return nil
+		}
+		previous = c
+	}
+}
+
+// copyQuoted copies the rest of a string or quoted identifier whose opening quote was already copied
+func (f *filter) copyQuoted(w io.Writer, quote byte) error {
+	for need := 1; ; {
+		buffered, err := f.peek(need)
+
+		n, closed := discovery.ScanQuoted(buffered, quote, err != nil)
+		_, _ = w.Write(buffered[:n])
+		_, _ = f.in.Discard(n)
+
+		switch {
+		case closed:
+			return nil
+		case err != nil:
+			return err
+		}
+
+		// Read past an escape or quote ending the buffer to tell what it means
+		need = len(buffered) - n + 1
+	}
+}
+
+func ignoreEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return nil
+	}
+
+	return err
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,28 @@
+--
+-- Table structure for table `definers`
+--
+
+DROP TABLE IF EXISTS `definers`;
+/*!40101 SET @saved_cs_client     = @@character_set_client */;
+/*!50503 SET character_set_client = utf8mb4 */;
+CREATE TABLE `definers` (
+  `id` int NOT NULL AUTO_INCREMENT,
+  `definer` varchar(255) DEFAULT 'DEFINER=`root`@`localhost`',
+  `ddl` text,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+/*!40101 SET character_set_client = @saved_cs_client */;
+
+--
+-- Dumping data for table `definers`
+--
+
+LOCK TABLES `definers` WRITE;
+/*!40000 ALTER TABLE `definers` DISABLE KEYS */;
+INSERT INTO `definers` VALUES (1,'root@localhost','CREATE DEFINER=`root`@`localhost` VIEW `v` AS SELECT 1'),(2,'it\'s','\';CREATE DEFINER=`root`@`%` TRIGGER t BEFORE INSERT ON x FOR EACH ROW SET @a = 1;'),(3,'a\\','\\\';CREATE DEFINER=root@localhost PROCEDURE p() SELECT 1;'),(4,'multi
+line','first line;
+CREATE ALGORITHM=UNDEFINED DEFINER=`root`@`localhost` SQL SECURITY DEFINER VIEW `w` AS SELECT 2;
+-- not a comment'),(5,"double \"quoted\" ;CREATE DEFINER=`x`@`y` EVENT e",0x3B435245415445);
+/*!40000 ALTER TABLE `definers` ENABLE KEYS */;
+UNLOCK TABLES;
+CREATE TABLE `create_definer` (`definer` int);
@@ -0,0 +1,32 @@
+--
+-- Current Database: `sakila`
+--
+
+/*!40000 DROP DATABASE IF EXISTS `pagila`*/;
+
+CREATE DATABASE /*!32312 IF NOT EXISTS*/ `pagila` /*!40100 DEFAULT CHARACTER SET utf8mb4 */;
+
+USE `pagila`;
+
+CREATE TABLE `sakila` (
+  `sakila` int NOT NULL,
+  `note` varchar(255) DEFAULT '`sakila`.`sakila`'
+) ENGINE=InnoDB;
+
+INSERT INTO `sakila` VALUES (1,'USE `sakila`;'),(2,"`sakila`.`x`");
+
+/*!50001 CREATE ALGORITHM=UNDEFINED */
+/*!50013 DEFINER=`root`@`localhost` SQL SECURITY DEFINER */
+/*!50001 VIEW `actor_info` AS select `pagila`.`actor`.`actor_id` AS `actor_id`,`a`.`sakila` AS `sakila` from (`pagila`.`actor` join `other`.`actor` `a`) */;
+
+ALTER DATABASE `pagila` CHARACTER SET latin1 ;
+DELIMITER ;;
+CREATE DEFINER=`root`@`localhost` PROCEDURE `film_in_stock`()
+BEGIN
+  SELECT `pagila`.`film`.`film_id` FROM `pagila`.`film`;
+  SELECT 'sakila.film';
+END ;;
+DELIMITER ;
+ALTER DATABASE `pagila` CHARACTER SET utf8mb4 ;
+DROP TABLE IF EXISTS `sakila`;
+use `other`;
@@ -0,0 +1,29 @@
+--
+-- Dumping routines for database 'app'
+--
+/*!50106 SET @save_time_zone= @@TIME_ZONE */ ;
+/*!50106 DROP EVENT IF EXISTS `cleanup` */;
+DELIMITER ;;
+/*!50003 SET time_zone             = 'SYSTEM' */ ;;
+/*!50106 CREATE*/ /*!50117 DEFINER=`app`@`%`*/ /*!50106 EVENT `cleanup` ON SCHEDULE EVERY 1 DAY STARTS '2020-01-01 00:00:00' ON COMPLETION NOT PRESERVE ENABLE DO DELETE FROM sessions WHERE note = 'DEFINER=`root`@`%`' */ ;;
+DELIMITER ;
+/*!50003 SET sql_mode              = 'STRICT_TRANS_TABLES,NO_ENGINE_SUBSTITUTION' */ ;
+DELIMITER ;;
+/*!50003 CREATE*/ /*!50017 DEFINER=`app`@`%`*/ /*!50003 TRIGGER `orders_bi` BEFORE INSERT ON `orders` FOR EACH ROW SET NEW.created_by = CURRENT_USER() */;;
+DELIMITER ;
+DELIMITER ;;
+CREATE DEFINER=`app`@`%` PROCEDURE `audit`(IN who VARCHAR(64))
+BEGIN
+  -- it's fine: CREATE DEFINER=`x`@`y` VIEW v AS SELECT 1;
+  # don't rewrite DEFINER=`x`@`y`
+  /* nor CREATE DEFINER=`x`@`y` TRIGGER */
+  INSERT INTO audit_log VALUES (who, 'it''s DEFINER=`x`@`y`', "say \"DEFINER\"");
+  SET @ddl = 'CREATE DEFINER=`x`@`y` PROCEDURE p() SELECT 1';
+END ;;
+DELIMITER ;
+DELIMITER $$
+CREATE DEFINER=`app`@`%` FUNCTION `answer`() RETURNS int
+    DETERMINISTIC
+RETURN 42 $$
+CREATE DEFINER = `app`@`%` AGGREGATE FUNCTION `total` RETURNS REAL SONAME 'total.so'$$
+DELIMITER ;
@@ -0,0 +1,28 @@
+--
+-- Temporary view structure for view `actor_info`
+--
+
+DROP TABLE IF EXISTS `actor_info`;
+/*!50001 DROP VIEW IF EXISTS `actor_info`*/;
+SET @saved_cs_client     = @@character_set_client;
+/*!50503 SET character_set_client = utf8mb4 */;
+/*!50001 CREATE VIEW `actor_info` AS SELECT
+ 1 AS `actor_id`*/;
+SET character_set_client = @saved_cs_client;
+
+--
+-- Final view structure for view `actor_info`
+--
+
+/*!50001 DROP VIEW IF EXISTS `actor_info`*/;
+/*!50001 SET @saved_cs_client          = @@character_set_client */;
+/*!50001 CREATE ALGORITHM=UNDEFINED */
+/*!50013 DEFINER=`app`@`%` SQL SECURITY DEFINER */
+/*!50001 VIEW `actor_info` AS select `a`.`actor_id` AS `actor_id`,'DEFINER=`x`@`y` SQL SECURITY DEFINER' AS `note` from `actor` `a` */;
+/*!50001 SET character_set_client      = @saved_cs_client */;
+
+CREATE OR REPLACE ALGORITHM = MERGE DEFINER = `app`@`%` VIEW customer_list AS SELECT * FROM customer;
+CREATE DEFINER=`app`@`%` SQL SECURITY INVOKER VIEW `staff_list` AS SELECT * FROM staff;
+create definer=`app`@`%` view `sales` as select 1;
+CREATE VIEW `plain` AS SELECT 'DEFINER=`root`@`%`';
+CREATE SQL SECURITY DEFINER VIEW `secured` AS SELECT 1;
@@ -0,0 +1,179 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package definer
+
+import "strings"
+
+type decision int
+
+const (
+	undecided decision = iota
+	passThrough
+	matched
+	delimiterCommand
+)
+
+type headerStep int
+
+const (
+	expectCreate headerStep = iota
+	expectClause
+	expectDefinerEquals
+	expectUser
+	expectAt
+	expectHost
+	expectCurrentUserOpen
+	expectCurrentUserClose
+	expectSecurity
+	expectSecurityValue
+)
+
+// header recognizes CREATE [OR REPLACE] [ALGORITHM = ...] [DEFINER = user] [SQL SECURITY ...] <object> from the
+// significant tokens of a statement, and records where its clauses are
+type header struct {
+	step headerStep
+
+	keyword       string
+	definerStart  int
+	userStart     int
+	userEnd       int
+	securityValue int
+}
+
+func newHeader() *header {
+	return &header{definerStart: -1, userStart: -1, userEnd: -1, securityValue: -1}
+}
+
+func (h *header) feed(t token, i int) decision {
+	upper := ""
+	if t.kind == word {
+		upper = strings.ToUpper(t.text)
+	}
+
+	switch h.step {
+	case expectCreate:
+		switch upper {
+		case "CREATE":
+			h.step = expectClause
+			return undecided
+		case "DELIMITER":
+			return delimiterCommand
+		}
+		return passThrough
+	case expectClause:
+		switch {
+		case t.kind == symbol && t.text == "=", headerKeywords[upper]:
+			return undecided
+		case upper == "DEFINER" && h.definerStart < 0:
+			h.definerStart = i
+			h.step = expectDefinerEquals
+			return undecided
+		case upper == "SQL" && h.securityValue < 0:
+			h.step = expectSecurity
+			return undecided
+		case objectKeywords[upper]:
+			h.keyword = upper
+			return matched
+		}
+	case expectDefinerEquals:
+		if t.kind == symbol && t.text == "=" {
+			h.step = expectUser
+			return undecided
+		}
+	case expectUser:
+		if t.kind == word || t.kind == quoted {
+			h.userStart, h.userEnd = i, i
+			h.step = expectAt
+			if upper == "CURRENT_USER" {
+				h.step = expectCurrentUserOpen
+			}
+			return undecided
+		}
+	case expectAt:
+		if t.kind == symbol && t.text == "@" {
+			h.step = expectHost
+			return undecided
+		}
+		// An account without a host
+		h.step = expectClause
+		return h.feed(t, i)
+	case expectHost:
+		if t.kind == word || t.kind == quoted {
+			h.userEnd = i
+			h.step = expectClause
+			return undecided
+		}
+	case expectCurrentUserOpen:
+		if t.kind == symbol && t.text == "(" {
+			h.step = expectCurrentUserClose
+			return undecided
+		}
+		h.step = expectClause
+		return h.feed(t, i)
+	case expectCurrentUserClose:
+		if t.kind == symbol && t.text == ")" {
+			h.userEnd = i
+			h.step = expectClause
+			return undecided
+		}
+	case expectSecurity:
+		if upper == "SECURITY" {
+			h.step = expectSecurityValue
+			return undecided
+		}
+	case expectSecurityValue:
+		if upper == "DEFINER" || upper == "INVOKER" {
+			h.securityValue = i
+			h.step = expectClause
+			return undecided
+		}
+	}
+
+	return passThrough
+}
+
+// rewrite applies the policy to the tokens of a recognized header
+func (h *header) rewrite(tokens []token, policy Policy) {
+	hasDefiner := h.userStart >= 0
+
+	switch {
+	case policy.Definer != "":
+		if hasDefiner {
+			replace(tokens, h.userStart, h.userEnd, policy.Definer)
+		}
+	case h.keyword != "VIEW":
+		if hasDefiner {
+			replace(tokens, h.userStart, h.userEnd, "CURRENT_USER")
+		}
+	case h.securityValue >= 0:
+		tokens[h.securityValue].text = "INVOKER"
+
+		if hasDefiner {
+			end := h.userEnd
+			if end+1 < len(tokens) && tokens[end+1].kind == blank {
+				end++
+			}
+			replace(tokens, h.definerStart, end, "")
+		}
+	case hasDefiner:
+		replace(tokens, h.definerStart, h.userEnd, "SQL SECURITY INVOKER")
+	}
+}
+
+// replace replaces the text of tokens[start:end+1], including any comments or whitespace between them
+func replace(tokens []token, start, end int, text string) {
+	tokens[start].text = text
+	for i := start + 1; i <= end; i++ {
+		tokens[i].text = ""
+	}
+}
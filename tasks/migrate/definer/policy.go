@@ -0,0 +1,82 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package definer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// Invoker is the policy value making views run with the privileges of their invoker, and stored programs owned by
+// the user loading them
+const Invoker = "invoker"
+
+// Policy decides how the DEFINER clauses of views, triggers, routines and events are rewritten. The definers of the
+// source usually do not exist on the recipient.
+type Policy struct {
+	// Definer is the account, quoted as `user`@`host`, every definer is mapped to. When empty, views are converted
+	// to SQL SECURITY INVOKER and stored programs are owned by the user loading them.
+	Definer string
+	// Schemas renames the schemas of a dump. The schema of CREATE DATABASE and USE statements, and schema-qualified
+	// references such as `schema`.`table`, are renamed from each key to its value.
+	Schemas map[string]string
+}
+
+// ParsePolicy parses "invoker", or the <user>@<host> account to map every definer to. The user and host may be
+// quoted with backticks or single quotes.
+func ParsePolicy(value string) (Policy, error) {
+	if value == Invoker {
+		return Policy{}, nil
+	}
+
+	i := strings.LastIndex(value, "@")
+	if i < 0 {
+		return Policy{}, fmt.Errorf("invalid definer %q, expected %q or <user>@<host>", value, Invoker)
+	}
+
+	user, host := unquote(value[:i]), unquote(value[i+1:])
+	if user == "" || host == "" {
+		return Policy{}, fmt.Errorf("invalid definer %q, expected %q or <user>@<host>", value, Invoker)
+	}
+
+	return Policy{Definer: discovery.QuoteIdentifier(user) + "@" + discovery.QuoteIdentifier(host)}, nil
+}
+
+// ParseSchemaMapping parses a <from>=<to> schema rename
+func ParseSchemaMapping(value string) (from, to string, err error) {
+	from, to, ok := strings.Cut(value, "=")
+	if from, to = unquote(from), unquote(to); !ok || from == "" || to == "" {
+		return "", "", fmt.Errorf("invalid schema mapping %q, expected <from>=<to>", value)
+	}
+
+	return from, to, nil
+}
+
+func (p Policy) String() string {
+	if p.Definer == "" {
+		return Invoker
+	}
+
+	return p.Definer
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '`' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		q := string(s[0])
+		return strings.ReplaceAll(s[1:len(s)-1], q+q, q)
+	}
+
+	return s
+}
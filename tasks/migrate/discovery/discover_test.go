@@ -16,6 +16,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	. "github.com/onsi/ginkgo/v2"
@@ -98,6 +99,51 @@ var _ = Describe("Discovery Unit Tests", func() {
 		})
 	})
 
+	Context("DiscoverTables", func() {
+		var tablesQuery string
+
+		BeforeEach(func() {
+			tablesQuery = regexp.QuoteMeta(`SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE'`)
+		})
+
+		It("returns the base tables of the schema", func() {
+			mock.ExpectQuery(tablesQuery).
+				WithArgs("service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).
+					AddRow("actor").
+					AddRow("film"),
+				)
+
+			Expect(DiscoverTables(mockDB, "service_instance_db")).To(Equal([]string{"actor", "film"}))
+		})
+
+		When("querying the tables fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(tablesQuery).
+					WithArgs("service_instance_db").
+					WillReturnError(errors.New("some database error"))
+			})
+
+			It("returns an error", func() {
+				_, err := DiscoverTables(mockDB, "service_instance_db")
+				Expect(err).To(MatchError("failed to retrieve tables for service_instance_db schema: some database error"))
+			})
+		})
+
+		When("scanning the list of tables fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(tablesQuery).
+					WithArgs("service_instance_db").
+					WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow(nil))
+			})
+
+			It("returns an error", func() {
+				_, err := DiscoverTables(mockDB, "service_instance_db")
+				Expect(err).To(MatchError(ContainSubstring("failed to scan the list of tables")))
+			})
+		})
+	})
+
 	Context("DiscoverInvalidViews", func() {
 		var schemasToMigrate []string
 
@@ -174,4 +220,167 @@ var _ = Describe("Discovery Unit Tests", func() {
 			})
 		})
 	})
+
+	Context("DiscoverStoredPrograms", func() {
+		var (
+			schemasToMigrate    []string
+			storedProgramsQuery string
+		)
+
+		BeforeEach(func() {
+			schemasToMigrate = []string{
+				"service_instance_db",
+				"custom_user_db",
+			}
+			storedProgramsQuery = regexp.QuoteMeta(`SELECT ROUTINE_NAME, ROUTINE_TYPE, '' FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA = ?`)
+		})
+
+		It("returns the routines, triggers and events for each schema", func() {
+			mock.ExpectQuery(storedProgramsQuery).
+				WithArgs("service_instance_db", "service_instance_db", "service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"ROUTINE_NAME", "ROUTINE_TYPE", "TABLE"}).
+					AddRow("some_procedure", "PROCEDURE", "").
+					AddRow("some_function", "FUNCTION", "").
+					AddRow("some_trigger", "TRIGGER", "some_table"),
+				)
+			mock.ExpectQuery(storedProgramsQuery).
+				WithArgs("custom_user_db", "custom_user_db", "custom_user_db").
+				WillReturnRows(sqlmock.NewRows([]string{"ROUTINE_NAME", "ROUTINE_TYPE", "TABLE"}).
+					AddRow("some_event", "EVENT", ""),
+				)
+
+			programs, err := DiscoverStoredPrograms(mockDB, schemasToMigrate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(programs).To(Equal([]StoredProgram{
+				{Schema: "service_instance_db", Name: "some_procedure", Type: "PROCEDURE"},
+				{Schema: "service_instance_db", Name: "some_function", Type: "FUNCTION"},
+				{Schema: "service_instance_db", Name: "some_trigger", Type: "TRIGGER", Table: "some_table"},
+				{Schema: "custom_user_db", Name: "some_event", Type: "EVENT"},
+			}))
+		})
+
+		Context("when querying stored programs fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(storedProgramsQuery).
+					WithArgs("service_instance_db", "service_instance_db", "service_instance_db").
+					WillReturnError(errors.New("some database error"))
+			})
+
+			It("returns the error", func() {
+				_, err := DiscoverStoredPrograms(mockDB, schemasToMigrate)
+				Expect(err).To(MatchError("failed to retrieve stored programs for service_instance_db schema: some database error"))
+			})
+		})
+
+		Context("when scanning the list of stored programs fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(storedProgramsQuery).
+					WithArgs("service_instance_db", "service_instance_db", "service_instance_db").
+					WillReturnRows(sqlmock.NewRows([]string{"ROUTINE_NAME", "ROUTINE_TYPE", "TABLE"}).
+						AddRow(nil, "PROCEDURE", ""),
+					)
+			})
+
+			It("returns the error", func() {
+				_, err := DiscoverStoredPrograms(mockDB, schemasToMigrate)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("failed to scan the list of stored programs"))
+			})
+		})
+
+		Context("when preparing the list of stored programs fails", func() {
+			BeforeEach(func() {
+				mock.ExpectQuery(storedProgramsQuery).
+					WithArgs("service_instance_db", "service_instance_db", "service_instance_db").
+					WillReturnRows(sqlmock.NewRows([]string{"ROUTINE_NAME", "ROUTINE_TYPE", "TABLE"}).
+						AddRow("some_procedure", "PROCEDURE", "").
+						RowError(0, errors.New("failed to prepare stored program")),
+					)
+			})
+
+			It("returns the error", func() {
+				_, err := DiscoverStoredPrograms(mockDB, schemasToMigrate)
+				Expect(err).To(MatchError("failed to prepare the list of stored programs: failed to prepare stored program"))
+			})
+		})
+	})
+
+	Context("DiscoverDataSize", func() {
+		const sizeQuery = `SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?`
+
+		It("adds up the size of every schema", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(sizeQuery)).
+				WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(1024))
+			mock.ExpectQuery(regexp.QuoteMeta(sizeQuery)).
+				WithArgs("bar").
+				WillReturnRows(sqlmock.NewRows([]string{"size"}).AddRow(2048))
+
+			Expect(DiscoverDataSize(mockDB, []string{"foo", "bar"})).To(Equal(int64(3072)))
+		})
+
+		It("returns an error when the size can not be retrieved", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(sizeQuery)).
+				WithArgs("foo").
+				WillReturnError(errors.New("some database error"))
+
+			_, err := DiscoverDataSize(mockDB, []string{"foo"})
+			Expect(err).To(MatchError("failed to retrieve the data size of foo schema: some database error"))
+		})
+	})
+
+	Context("DiscoverTableSizes", func() {
+		const tableSizesQuery = `SELECT TABLE_NAME, COALESCE(DATA_LENGTH + INDEX_LENGTH, 0), COALESCE(TABLE_ROWS, 0) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?`
+
+		It("lists the base tables of every schema, largest first", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(tableSizesQuery)).
+				WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "size", "rows"}).
+					AddRow("small", 16, 1).
+					AddRow("large", 4096, 200))
+			mock.ExpectQuery(regexp.QuoteMeta(tableSizesQuery)).
+				WithArgs("bar").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "size", "rows"}).
+					AddRow("medium", 1024, 50))
+
+			Expect(DiscoverTableSizes(mockDB, []string{"foo", "bar"})).To(Equal([]Table{
+				{Schema: "foo", Name: "large", SizeBytes: 4096, Rows: 200},
+				{Schema: "bar", Name: "medium", SizeBytes: 1024, Rows: 50},
+				{Schema: "foo", Name: "small", SizeBytes: 16, Rows: 1},
+			}))
+		})
+
+		It("returns an error when the table sizes can not be retrieved", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(tableSizesQuery)).
+				WithArgs("foo").
+				WillReturnError(errors.New("some database error"))
+
+			_, err := DiscoverTableSizes(mockDB, []string{"foo"})
+			Expect(err).To(MatchError("failed to retrieve the table sizes for foo schema: some database error"))
+		})
+	})
+
+	Context("DiscoverNonInnoDBTables", func() {
+		const enginesQuery = `SELECT TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?`
+
+		It("lists tables using another storage engine", func() {
+			mock.ExpectQuery(enginesQuery).
+				WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME", "ENGINE"}).
+					AddRow("legacy", "MyISAM").
+					AddRow("scratch", "MEMORY"))
+
+			Expect(DiscoverNonInnoDBTables(mockDB, []string{"foo"})).
+				To(Equal([]string{"foo.legacy (MyISAM)", "foo.scratch (MEMORY)"}))
+		})
+
+		It("returns an error when the storage engines can not be retrieved", func() {
+			mock.ExpectQuery(enginesQuery).
+				WithArgs("foo").
+				WillReturnError(errors.New("some database error"))
+
+			_, err := DiscoverNonInnoDBTables(mockDB, []string{"foo"})
+			Expect(err).To(MatchError("failed to retrieve the storage engines for foo schema: some database error"))
+		})
+	})
 })
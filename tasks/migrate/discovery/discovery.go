@@ -16,6 +16,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/go-sql-driver/mysql"
@@ -61,6 +62,30 @@ func DiscoverDatabases(db *sql.DB) ([]string, error) {
 	return dbs, nil
 }
 
+// DiscoverTables returns the names of the base tables in a schema, excluding views
+func DiscoverTables(db *sql.DB, schema string) ([]string, error) {
+	rows, err := db.Query(`SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME`, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tables for %s schema: %w", schema, err)
+	}
+
+	var tables []string
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, fmt.Errorf("failed to scan the list of tables: %w", err)
+		}
+
+		tables = append(tables, tableName)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to prepare the list of tables: %w", err)
+	}
+
+	return tables, nil
+}
+
 type View struct {
 	Schema    string
 	TableName string
@@ -121,3 +146,140 @@ func DiscoverInvalidViews(db *sql.DB, schemas []string) ([]View, error) {
 
 	return invalidViews, nil
 }
+
+type StoredProgram struct {
+	Schema string
+	Name   string
+	Type   string
+	// Table is the table a trigger is defined on, and empty for routines and events
+	Table string
+}
+
+func (p StoredProgram) String() string {
+	return fmt.Sprintf("%s %s.%s", p.Type, p.Schema, p.Name)
+}
+
+func discoverStoredPrograms(db *sql.DB, schema string) (programs []StoredProgram, err error) {
+	findStoredProgramsQuery := `SELECT ROUTINE_NAME, ROUTINE_TYPE, '' FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA = ?
+UNION ALL SELECT TRIGGER_NAME, 'TRIGGER', EVENT_OBJECT_TABLE FROM INFORMATION_SCHEMA.TRIGGERS WHERE TRIGGER_SCHEMA = ?
+UNION ALL SELECT EVENT_NAME, 'EVENT', '' FROM INFORMATION_SCHEMA.EVENTS WHERE EVENT_SCHEMA = ?`
+	rows, err := db.Query(findStoredProgramsQuery, schema, schema, schema)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve stored programs for %s schema: %w", schema, err)
+	}
+
+	for rows.Next() {
+		var program StoredProgram
+		if err := rows.Scan(&program.Name, &program.Type, &program.Table); err != nil {
+			return nil, fmt.Errorf("failed to scan the list of stored programs: %w", err)
+		}
+		program.Schema = schema
+
+		programs = append(programs, program)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to prepare the list of stored programs: %w", err)
+	}
+
+	return programs, nil
+}
+
+// DiscoverStoredPrograms returns the routines, triggers and events defined in the given schemas
+func DiscoverStoredPrograms(db *sql.DB, schemas []string) ([]StoredProgram, error) {
+	var storedPrograms []StoredProgram
+	for _, schema := range schemas {
+		programs, err := discoverStoredPrograms(db, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		storedPrograms = append(storedPrograms, programs...)
+	}
+
+	return storedPrograms, nil
+}
+
+// DiscoverDataSize returns the combined size in bytes of the data and indexes of the given schemas
+func DiscoverDataSize(db *sql.DB, schemas []string) (int64, error) {
+	var total int64
+	for _, schema := range schemas {
+		var size int64
+		if err := db.QueryRow(`SELECT COALESCE(SUM(DATA_LENGTH + INDEX_LENGTH), 0) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ?`, schema).
+			Scan(&size); err != nil {
+			return 0, fmt.Errorf("failed to retrieve the data size of %s schema: %w", schema, err)
+		}
+
+		total += size
+	}
+
+	return total, nil
+}
+
+type Table struct {
+	Schema    string
+	Name      string
+	SizeBytes int64
+	// Rows is the row count estimated by the table statistics
+	Rows int64
+}
+
+func (t Table) String() string {
+	return fmt.Sprintf("%s.%s", t.Schema, t.Name)
+}
+
+// DiscoverTableSizes returns the base tables of the given schemas, largest first
+func DiscoverTableSizes(db *sql.DB, schemas []string) ([]Table, error) {
+	var tables []Table
+	for _, schema := range schemas {
+		rows, err := db.Query(`SELECT TABLE_NAME, COALESCE(DATA_LENGTH + INDEX_LENGTH, 0), COALESCE(TABLE_ROWS, 0) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME`, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the table sizes for %s schema: %w", schema, err)
+		}
+
+		for rows.Next() {
+			table := Table{Schema: schema}
+			if err := rows.Scan(&table.Name, &table.SizeBytes, &table.Rows); err != nil {
+				return nil, fmt.Errorf("failed to scan the list of table sizes: %w", err)
+			}
+
+			tables = append(tables, table)
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to prepare the list of table sizes: %w", err)
+		}
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool {
+		return tables[i].SizeBytes > tables[j].SizeBytes
+	})
+
+	return tables, nil
+}
+
+// DiscoverNonInnoDBTables returns the base tables of the given schemas that do not use the InnoDB storage engine
+func DiscoverNonInnoDBTables(db *sql.DB, schemas []string) ([]string, error) {
+	var tables []string
+	for _, schema := range schemas {
+		rows, err := db.Query(`SELECT TABLE_NAME, ENGINE FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_TYPE = 'BASE TABLE' AND ENGINE <> 'InnoDB' ORDER BY TABLE_NAME`, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve the storage engines for %s schema: %w", schema, err)
+		}
+
+		for rows.Next() {
+			var tableName, engine string
+			if err := rows.Scan(&tableName, &engine); err != nil {
+				return nil, fmt.Errorf("failed to scan the list of storage engines: %w", err)
+			}
+
+			tables = append(tables, fmt.Sprintf("%s.%s (%s)", schema, tableName, engine))
+		}
+
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to prepare the list of storage engines: %w", err)
+		}
+	}
+
+	return tables, nil
+}
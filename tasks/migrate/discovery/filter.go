@@ -0,0 +1,110 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package discovery
+
+import (
+	"database/sql"
+	"fmt"
+	"path"
+)
+
+// Filter selects the schemas and tables to migrate using glob patterns, as understood by path.Match.
+// Table patterns are matched against "schema.table".
+type Filter struct {
+	IncludeSchemas []string
+	ExcludeSchemas []string
+	IncludeTables  []string
+	ExcludeTables  []string
+}
+
+func (f Filter) Validate() error {
+	for _, patterns := range [][]string{f.IncludeSchemas, f.ExcludeSchemas, f.IncludeTables, f.ExcludeTables} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (f Filter) FiltersTables() bool {
+	return len(f.IncludeTables) > 0 || len(f.ExcludeTables) > 0
+}
+
+// Schemas returns the schemas that match an include pattern, if any are given, and none of the exclude patterns
+func (f Filter) Schemas(schemas []string) []string {
+	var result []string
+	for _, schema := range schemas {
+		if included(schema, f.IncludeSchemas, f.ExcludeSchemas) {
+			result = append(result, schema)
+		}
+	}
+
+	return result
+}
+
+func (f Filter) TableIncluded(schema, table string) bool {
+	return included(schema+"."+table, f.IncludeTables, f.ExcludeTables)
+}
+
+// ExcludedTables returns the tables and views of the given schemas that are filtered out, as "schema.table"
+func (f Filter) ExcludedTables(db *sql.DB, schemas []string) ([]string, error) {
+	if !f.FiltersTables() {
+		return nil, nil
+	}
+
+	var excluded []string
+	for _, schema := range schemas {
+		tables, err := DiscoverTables(db, schema)
+		if err != nil {
+			return nil, err
+		}
+
+		views, err := discoverViews(db, schema)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range views {
+			tables = append(tables, v.TableName)
+		}
+
+		for _, table := range tables {
+			if !f.TableIncluded(schema, table) {
+				excluded = append(excluded, schema+"."+table)
+			}
+		}
+	}
+
+	return excluded, nil
+}
+
+func included(name string, includePatterns, excludePatterns []string) bool {
+	if len(includePatterns) > 0 && !matchesAny(name, includePatterns) {
+		return false
+	}
+
+	return !matchesAny(name, excludePatterns)
+}
+
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Patterns are validated up front, so a malformed one never matches
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package discovery_test
+
+import (
+	"database/sql"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+var _ = Describe("Filter", func() {
+	Context("Schemas", func() {
+		schemas := []string{"app", "app_audit", "reporting", "service_instance_db"}
+
+		It("keeps every schema by default", func() {
+			Expect(Filter{}.Schemas(schemas)).To(Equal(schemas))
+		})
+
+		It("keeps only the schemas matching an include pattern", func() {
+			filter := Filter{IncludeSchemas: []string{"app*", "reporting"}}
+			Expect(filter.Schemas(schemas)).To(Equal([]string{"app", "app_audit", "reporting"}))
+		})
+
+		It("drops the schemas matching an exclude pattern", func() {
+			filter := Filter{IncludeSchemas: []string{"app*"}, ExcludeSchemas: []string{"*_audit"}}
+			Expect(filter.Schemas(schemas)).To(Equal([]string{"app"}))
+		})
+	})
+
+	Context("TableIncluded", func() {
+		It("matches patterns against schema.table", func() {
+			filter := Filter{ExcludeTables: []string{"app.audit_*"}}
+			Expect(filter.TableIncluded("app", "audit_log")).To(BeFalse())
+			Expect(filter.TableIncluded("app", "users")).To(BeTrue())
+			Expect(filter.TableIncluded("other", "audit_log")).To(BeTrue())
+		})
+
+		It("only includes tables matching an include pattern when one is given", func() {
+			filter := Filter{IncludeTables: []string{"*.users"}}
+			Expect(filter.TableIncluded("app", "users")).To(BeTrue())
+			Expect(filter.TableIncluded("app", "orders")).To(BeFalse())
+		})
+	})
+
+	Context("Validate", func() {
+		It("accepts valid patterns", func() {
+			Expect(Filter{IncludeSchemas: []string{"app?"}, ExcludeTables: []string{"*.[a-c]*"}}.Validate()).To(Succeed())
+		})
+
+		It("rejects malformed patterns", func() {
+			Expect(Filter{ExcludeTables: []string{"app.[audit"}}.Validate()).
+				To(MatchError(`invalid pattern "app.[audit": syntax error in pattern`))
+		})
+	})
+
+	Context("ExcludedTables", func() {
+		var (
+			mockDB *sql.DB
+			mock   sqlmock.Sqlmock
+		)
+
+		BeforeEach(func() {
+			var err error
+			mockDB, mock, err = sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns the filtered out tables and views", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(`SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES`)).
+				WithArgs("app").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("audit_log").AddRow("users"))
+			mock.ExpectQuery(`SELECT table_name from INFORMATION_SCHEMA.VIEWS`).
+				WithArgs("app").
+				WillReturnRows(sqlmock.NewRows([]string{"table_name"}).AddRow("audit_summary"))
+
+			filter := Filter{ExcludeTables: []string{"app.audit_*"}}
+			Expect(filter.ExcludedTables(mockDB, []string{"app"})).To(Equal([]string{"app.audit_log", "app.audit_summary"}))
+		})
+
+		It("does not query anything without table patterns", func() {
+			Expect(Filter{IncludeSchemas: []string{"app"}}.ExcludedTables(mockDB, []string{"app"})).To(BeEmpty())
+		})
+	})
+})
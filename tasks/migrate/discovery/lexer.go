@@ -0,0 +1,59 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package discovery
+
+// IsWordByte reports whether c may appear in an unquoted identifier or keyword
+func IsWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '$' || c >= 0x80
+}
+
+// IsQuote reports whether c opens a string or quoted identifier
+func IsQuote(c byte) bool {
+	return c == '\'' || c == '"' || c == '`'
+}
+
+// SkipQuoted returns the position after the string or quoted identifier starting at i, or the length of the
+// statement when it is not closed
+func SkipQuoted(statement string, i int) int {
+	n, _ := ScanQuoted(statement[i+1:], statement[i], true)
+	return i + 1 + n
+}
+
+// ScanQuoted scans text following the opening quote of a string or quoted identifier. It returns the position after
+// the closing quote and true, or how much of text belongs to the quoted part and false when text ends before the
+// closing quote. Quotes are escaped by doubling them, and in strings also with a backslash. Unless atEOF, a
+// backslash or quote ending text is left for the next call, as its meaning depends on the byte following it.
+func ScanQuoted[T ~string | ~[]byte](text T, quote byte, atEOF bool) (int, bool) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && quote != '`':
+			if i+1 == len(text) && !atEOF {
+				return i, false
+			}
+			i++
+		case c == quote:
+			if i+1 == len(text) {
+				if atEOF {
+					return i + 1, true
+				}
+				return i, false
+			}
+			if text[i+1] != quote {
+				return i + 1, true
+			}
+			i++
+		}
+	}
+
+	return len(text), false
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package discovery_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+var _ = Describe("Lexer", func() {
+	Context("SkipQuoted", func() {
+		It("returns the position after the closing quote", func() {
+			Expect(SkipQuoted("SELECT 'a' FROM t", 7)).To(Equal(10))
+		})
+
+		It("skips quotes escaped by doubling them", func() {
+			Expect(SkipQuoted("`a``b` x", 0)).To(Equal(6))
+			Expect(SkipQuoted("'it''s' x", 0)).To(Equal(7))
+		})
+
+		It("skips quotes escaped with a backslash in strings only", func() {
+			Expect(SkipQuoted(`'it\'s' x`, 0)).To(Equal(7))
+			Expect(SkipQuoted("`a\\` x", 0)).To(Equal(4))
+		})
+
+		It("returns the length of the statement when the quote is not closed", func() {
+			Expect(SkipQuoted("'abc", 0)).To(Equal(4))
+		})
+	})
+
+	Context("ScanQuoted", func() {
+		It("returns the position after the closing quote", func() {
+			n, closed := ScanQuoted([]byte("a''b\\'c' x"), '\'', false)
+			Expect(n).To(Equal(8))
+			Expect(closed).To(BeTrue())
+		})
+
+		It("leaves an escape or quote ending the text for the next call", func() {
+			n, closed := ScanQuoted("abc\\", '\'', false)
+			Expect(n).To(Equal(3))
+			Expect(closed).To(BeFalse())
+
+			n, closed = ScanQuoted("abc'", '\'', false)
+			Expect(n).To(Equal(3))
+			Expect(closed).To(BeFalse())
+
+			n, closed = ScanQuoted("abc'", '\'', true)
+			Expect(n).To(Equal(4))
+			Expect(closed).To(BeTrue())
+		})
+	})
+
+	It("recognizes the bytes of unquoted identifiers", func() {
+		for _, c := range []byte("az_$09AZ\x80") {
+			Expect(IsWordByte(c)).To(BeTrue(), string(c))
+		}
+		for _, c := range []byte(" .`'-") {
+			Expect(IsWordByte(c)).To(BeFalse(), string(c))
+		}
+	})
+})
@@ -0,0 +1,198 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/copier"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/throttle"
+)
+
+const (
+	// EngineGo copies data over SQL connections, without any external binaries
+	EngineGo = "go"
+	// EngineExec pipes mysqldump into mysql
+	EngineExec = "exec"
+)
+
+// CopyEngine copies the schemas, rows and stored programs of the source to the recipient. Tables and views
+// excluded from the migration are never copied.
+type CopyEngine interface {
+	// CopySchemas copies the tables and views of the given schemas, and their rows unless structureOnly is set.
+	// When recordPosition is set, it returns the binlog position of the source the copy is consistent with.
+	// A mysqldump-style stream of the copied statements is written to progress.
+	CopySchemas(schemas []string, structureOnly, recordPosition bool, progress io.Writer) (BinlogPosition, error)
+	// ShareSnapshot makes up to workers concurrent CopyTableRows calls consistent with each other until release is
+	// called. When recordPosition is set, it returns the binlog position of the source they are consistent with.
+	ShareSnapshot(tables []discovery.Table, workers int, recordPosition bool) (position BinlogPosition, release func() error, err error)
+	// CopyTableRows copies the rows of a table whose structure was already copied
+	CopyTableRows(table discovery.Table, progress io.Writer) error
+	// CopyStoredPrograms copies the given routines, triggers and events of the given schemas
+	CopyStoredPrograms(schemas []string, programs []discovery.StoredProgram) error
+}
+
+func NewCopyEngine(name string, sourceDB, destDB *sql.DB, sourceCredentials, destCredentials Credentials, invalidViews []discovery.View, excludedTables []string, recipientSchema func(string) string, definers definer.Policy, masker *masking.Masker, conversion *charset.Conversion, throttler *throttle.Throttle) (CopyEngine, error) {
+	switch name {
+	case EngineGo:
+		skipped := append([]string{}, excludedTables...)
+		for _, v := range invalidViews {
+			skipped = append(skipped, v.String())
+		}
+
+		c := copier.New(sourceDB, destDB, recipientSchema, skipped, definers)
+		c.Masker = masker
+		c.Conversion = conversion
+		c.Throttle = throttler
+
+		return goEngine{copier: c}, nil
+	case EngineExec:
+		// The rows of a dump are not parsed, so they can not be masked
+		if masker != nil {
+			return nil, fmt.Errorf("masking rules require the %q engine", EngineGo)
+		}
+
+		// The DDL of a dump is not parsed, so it can not be converted
+		if conversion != nil {
+			return nil, fmt.Errorf("character set conversion requires the %q engine", EngineGo)
+		}
+
+		return execEngine{
+			sourceDB:          sourceDB,
+			sourceCredentials: sourceCredentials,
+			destCredentials:   destCredentials,
+			invalidViews:      invalidViews,
+			excludedTables:    excludedTables,
+			recipientSchema:   recipientSchema,
+			definers:          definers,
+			throttler:         throttler,
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid engine %q, expected %q or %q", name, EngineGo, EngineExec)
+	}
+}
+
+type goEngine struct {
+	copier *copier.Copier
+}
+
+func (e goEngine) CopySchemas(schemas []string, structureOnly, recordPosition bool, progress io.Writer) (BinlogPosition, error) {
+	position, err := e.copier.CopySchemas(schemas, copier.Options{StructureOnly: structureOnly, RecordPosition: recordPosition}, progress)
+
+	return BinlogPosition{File: position.File, Position: position.Position}, err
+}
+
+func (e goEngine) ShareSnapshot(tables []discovery.Table, workers int, recordPosition bool) (BinlogPosition, func() error, error) {
+	position, release, err := e.copier.ShareSnapshot(tables, workers, recordPosition)
+
+	return BinlogPosition{File: position.File, Position: position.Position}, release, err
+}
+
+func (e goEngine) CopyTableRows(table discovery.Table, progress io.Writer) error {
+	return e.copier.CopyTableRows(table.Schema, table.Name, progress)
+}
+
+func (e goEngine) CopyStoredPrograms(_ []string, programs []discovery.StoredProgram) error {
+	return e.copier.CopyStoredPrograms(programs)
+}
+
+type execEngine struct {
+	sourceDB          *sql.DB
+	sourceCredentials Credentials
+	destCredentials   Credentials
+	invalidViews      []discovery.View
+	excludedTables    []string
+	recipientSchema   func(string) string
+	definers          definer.Policy
+	throttler         *throttle.Throttle
+}
+
+func (e execEngine) CopySchemas(schemas []string, structureOnly, recordPosition bool, progress io.Writer) (BinlogPosition, error) {
+	mySQLDumpCmd := MySQLDumpCmd(e.sourceCredentials, e.invalidViews, e.excludedTables, schemas...)
+	switch {
+	case structureOnly:
+		mySQLDumpCmd = MySQLDumpSchemaCmd(e.sourceCredentials, e.invalidViews, e.excludedTables, schemas...)
+	case recordPosition:
+		mySQLDumpCmd = MySQLDumpWithBinlogPositionCmd(e.sourceCredentials, e.invalidViews, e.excludedTables, schemas...)
+	}
+
+	dumpPosition := &dumpBinlogPosition{}
+	if recordPosition {
+		progress = teeWriter(progress, dumpPosition)
+	}
+
+	if err := CopyData(mySQLDumpCmd, MySQLCmd(e.destCredentials), e.definers, progress, e.throttler); err != nil {
+		return BinlogPosition{}, err
+	}
+
+	if recordPosition && !dumpPosition.found {
+		return BinlogPosition{}, errors.New("mysqldump did not write the binlog position of the dump")
+	}
+
+	return dumpPosition.position, nil
+}
+
+// ShareSnapshot blocks writes to the tables until release is called, since every mysqldump of a table takes its own
+// snapshot
+func (e execEngine) ShareSnapshot(tables []discovery.Table, _ int, recordPosition bool) (BinlogPosition, func() error, error) {
+	unlock, err := LockTablesForRead(context.Background(), e.sourceDB, tables)
+	if err != nil {
+		return BinlogPosition{}, nil, fmt.Errorf("failed to lock the source tables for a consistent copy: %w", err)
+	}
+
+	// Writes are blocked while the tables are locked, so the binlog does not move until they are copied
+	var position BinlogPosition
+	if recordPosition {
+		if position, err = CurrentBinlogPosition(e.sourceDB); err != nil {
+			_ = unlock()
+			return BinlogPosition{}, nil, err
+		}
+	}
+
+	log.Printf("Blocking writes to the copied tables until they are all copied, since the %q engine can not share a snapshot between workers", EngineExec)
+
+	return position, unlock, nil
+}
+
+func (e execEngine) CopyTableRows(table discovery.Table, progress io.Writer) error {
+	tableDestCredentials := e.destCredentials
+	tableDestCredentials.Name = e.recipientSchema(table.Schema)
+
+	return CopyTableData(MySQLDumpTableDataCmd(e.sourceCredentials, table.Schema, table.Name), MySQLCmd(tableDestCredentials), progress, e.throttler)
+}
+
+// CopyStoredPrograms dumps every stored program of the schemas, since mysqldump can not select them individually.
+// mysql --force keeps loading the remaining stored programs if one of them fails.
+func (e execEngine) CopyStoredPrograms(schemas []string, _ []discovery.StoredProgram) error {
+	dumpCmd := MySQLDumpStoredProgramsCmd(e.sourceCredentials, e.excludedTables, schemas...)
+	loadCmd := MySQLCmd(e.destCredentials, "--force")
+
+	return CopyData(dumpCmd, loadCmd, e.definers, nil, nil)
+}
+
+func teeWriter(w, tee io.Writer) io.Writer {
+	if w == nil {
+		return tee
+	}
+
+	return io.MultiWriter(w, tee)
+}
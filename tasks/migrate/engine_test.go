@@ -0,0 +1,83 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/throttle"
+)
+
+var _ = Describe("NewCopyEngine", func() {
+	recipientSchema := func(schema string) string { return schema }
+
+	It("copies over SQL connections with the go engine", func() {
+		engine, err := NewCopyEngine(EngineGo, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engine).To(BeAssignableToTypeOf(goEngine{}))
+	})
+
+	It("pipes mysqldump into mysql with the exec engine", func() {
+		invalidViews := []discovery.View{{Schema: "foo", TableName: "broken_view"}}
+
+		engine, err := NewCopyEngine(EngineExec, nil, nil, Credentials{Name: "source"}, Credentials{Name: "dest"}, invalidViews, []string{"foo.t1"}, recipientSchema, definer.Policy{}, nil, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engine).To(BeAssignableToTypeOf(execEngine{}))
+		Expect(engine.(execEngine).invalidViews).To(Equal(invalidViews))
+		Expect(engine.(execEngine).excludedTables).To(Equal([]string{"foo.t1"}))
+	})
+
+	It("masks rows with the go engine only", func() {
+		masker := masking.NewMasker(masking.Rules{})
+
+		engine, err := NewCopyEngine(EngineGo, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, masker, nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engine.(goEngine).copier.Masker).To(BeIdenticalTo(masker))
+
+		_, err = NewCopyEngine(EngineExec, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, masker, nil, nil)
+		Expect(err).To(MatchError(`masking rules require the "go" engine`))
+	})
+
+	It("converts character sets with the go engine only", func() {
+		conversion := &charset.Conversion{Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci", MaxBytesPerChar: 4}
+
+		engine, err := NewCopyEngine(EngineGo, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, nil, conversion, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engine.(goEngine).copier.Conversion).To(BeIdenticalTo(conversion))
+
+		_, err = NewCopyEngine(EngineExec, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, nil, conversion, nil)
+		Expect(err).To(MatchError(`character set conversion requires the "go" engine`))
+	})
+
+	It("throttles both engines", func() {
+		throttler := throttle.New(throttle.Limits{RowsPerSecond: 100}, nil)
+
+		engine, err := NewCopyEngine(EngineGo, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, nil, nil, throttler)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engine.(goEngine).copier.Throttle).To(BeIdenticalTo(throttler))
+
+		engine, err = NewCopyEngine(EngineExec, nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, nil, nil, throttler)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(engine.(execEngine).throttler).To(BeIdenticalTo(throttler))
+	})
+
+	It("rejects an unknown engine", func() {
+		_, err := NewCopyEngine("rsync", nil, nil, Credentials{}, Credentials{}, nil, nil, recipientSchema, definer.Policy{}, nil, nil, nil)
+		Expect(err).To(MatchError(`invalid engine "rsync", expected "go" or "exec"`))
+	})
+})
@@ -0,0 +1,183 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+const exportUsage = "Usage: migrate export [-to <file|s3://bucket/key>] [-s3-endpoint <url>] [-s3-region <region>] [-skip-tls-validation] <service>"
+
+// runExport writes a compressed dump of every schema of a service instance to stdout, a file or an S3-compatible
+// object store. Stdout is reserved for the dump, so logs and progress lines are written to stderr.
+func runExport(args []string) {
+	var (
+		destination       string
+		s3Endpoint        string
+		s3Region          string
+		skipTLSValidation bool
+	)
+
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.StringVar(&destination, "to", "-", "Write the dump to this file, to an s3://<bucket>/<key> URL, or to stdout (-)")
+	flags.StringVar(&s3Endpoint, "s3-endpoint", s3.DefaultEndpoint, "URL of the S3-compatible object store")
+	flags.StringVar(&s3Region, "s3-region", s3.DefaultRegion, "Region of the S3 bucket")
+	flags.BoolVar(&skipTLSValidation, "skip-tls-validation", false, "Skip certificate validation of the MySQL server and object store certificates.  Not recommended!")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatal(exportUsage)
+	}
+	instance := flags.Arg(0)
+
+	credentials, err := InstanceCredentials(instance, VcapCredentials)
+	if err != nil {
+		log.Fatalf("Failed to lookup credentials: %v", err)
+	}
+	credentials.SkipTLSValidation = skipTLSValidation
+
+	db, err := sql.Open("mysql", credentials.DSN())
+	if err != nil {
+		log.Fatalf("Failed to initialize connection: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	schemas, err := discovery.DiscoverDatabases(db)
+	if err != nil {
+		log.Fatalf("Failed to discover schemas: %v", err)
+	}
+	log.Printf("Exporting schemas: %s", strings.Join(schemas, ", "))
+
+	invalidViews, err := discovery.DiscoverInvalidViews(db, schemas)
+	if err != nil {
+		log.Fatalf("Failed to retrieve invalid views: %v", err)
+	}
+
+	if len(invalidViews) > 0 {
+		log.Printf("The following views are invalid, and will not be exported: %s\n", invalidViews)
+	}
+
+	tables, err := includedTables(db, schemas, discovery.Filter{})
+	if err != nil {
+		log.Fatalf("Failed to discover the tables to export: %v", err)
+	}
+
+	dataSize, err := discovery.DiscoverDataSize(db, schemas)
+	if err != nil {
+		log.Fatalf("Failed to discover the size of the data to export: %v", err)
+	}
+
+	passphrase := os.Getenv(archive.PassphraseEnv)
+	if passphrase != "" {
+		log.Print("Encrypting the dump")
+	}
+
+	newS3Client := func() (*s3.Client, error) {
+		s3Credentials, err := s3.CredentialsFromEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		return s3.NewClient(s3Endpoint, s3Region, s3Credentials, skipTLSValidation)
+	}
+
+	tracker := progress.NewTracker(os.Stderr, tables, time.Now)
+	stopReporting := tracker.ReportEvery(progressInterval)
+
+	err = exportTo(destination, dataSize, newS3Client, func(w io.Writer) error {
+		// The dump names each schema before its tables
+		stream := tracker.Stream("")
+		defer func() { _ = stream.Close() }()
+
+		return ExportDump(MySQLDumpExportCmd(credentials, invalidViews, schemas...), w, passphrase, stream)
+	})
+	stopReporting()
+	if err != nil {
+		log.Fatalf("Failed to export %s: %v", instance, err)
+	}
+
+	if destination != "-" {
+		log.Printf("Exported %s to %s", instance, destination)
+	}
+
+	tracker.Finish()
+}
+
+// exportTo calls write with a writer to the destination of an export: stdout ("-"), an s3://<bucket>/<key> URL or
+// a local file. The object or file is only kept when write succeeded. dataSize is the size of the exported data, which
+// the dump is not expected to exceed.
+func exportTo(destination string, dataSize int64, newS3Client func() (*s3.Client, error), write func(io.Writer) error) error {
+	switch {
+	case destination == "-":
+		return write(os.Stdout)
+	case s3.IsURL(destination):
+		location, err := s3.ParseURL(destination)
+		if err != nil {
+			return err
+		}
+
+		client, err := newS3Client()
+		if err != nil {
+			return err
+		}
+
+		// Fail before dumping anything when the dump could not be uploaded
+		if _, err := client.UploadPartSize(dataSize); err != nil {
+			return fmt.Errorf("can not upload the dump to %s: %w", location, err)
+		}
+
+		pr, pw := io.Pipe()
+		uploaded := make(chan error, 1)
+		go func() {
+			err := client.Upload(location, pr, dataSize)
+			// Unblocks write when the upload failed before reading the whole dump
+			_ = pr.CloseWithError(err)
+			uploaded <- err
+		}()
+
+		// An error makes the upload fail, so that an incomplete dump is never stored
+		err = write(pw)
+		_ = pw.CloseWithError(err)
+
+		if uploadErr := <-uploaded; err == nil {
+			err = uploadErr
+		}
+
+		return err
+	default:
+		f, err := os.Create(destination)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", destination, err)
+		}
+
+		if err := write(f); err != nil {
+			_ = f.Close()
+			_ = os.Remove(destination)
+			return err
+		}
+
+		return f.Close()
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+var _ = Describe("exportTo", func() {
+	noS3 := func() (*s3.Client, error) {
+		Fail("unexpected S3 client")
+		return nil, nil
+	}
+
+	It("writes the dump to a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "app.sql.gz")
+
+		Expect(exportTo(path, 0, noS3, func(w io.Writer) error {
+			_, err := io.WriteString(w, "some dump")
+			return err
+		})).To(Succeed())
+
+		Expect(os.ReadFile(path)).To(Equal([]byte("some dump")))
+	})
+
+	It("removes the file of a failed dump", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "app.sql.gz")
+
+		err := exportTo(path, 0, noS3, func(w io.Writer) error {
+			_, _ = io.WriteString(w, "partial dump")
+			return errors.New("mysqldump command failed: exit status 2")
+		})
+		Expect(err).To(MatchError("mysqldump command failed: exit status 2"))
+		Expect(path).NotTo(BeAnExistingFile())
+	})
+
+	It("rejects invalid S3 URLs", func() {
+		err := exportTo("s3://backups", 0, noS3, func(io.Writer) error { return nil })
+		Expect(err).To(MatchError(`invalid S3 URL "s3://backups", expected s3://<bucket>/<key>`))
+	})
+
+	It("fails when the S3 credentials are missing", func() {
+		err := exportTo("s3://backups/app.sql.gz", 0, func() (*s3.Client, error) {
+			return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to access S3")
+		}, func(io.Writer) error { return nil })
+		Expect(err).To(MatchError("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to access S3"))
+	})
+
+	It("fails before dumping anything when the dump is too large to upload", func() {
+		client, err := s3.NewClient("http://localhost:9000", "", s3.Credentials{}, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = exportTo("s3://backups/app.sql.gz", 100*1024*1024*1024*1024, func() (*s3.Client, error) {
+			return client, nil
+		}, func(io.Writer) error {
+			Fail("unexpected dump")
+			return nil
+		})
+		Expect(err).To(MatchError(HavePrefix("can not upload the dump to s3://backups/app.sql.gz: an object of about 109951162777600 bytes needs parts of")))
+	})
+})
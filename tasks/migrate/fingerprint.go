@@ -0,0 +1,63 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/verification"
+)
+
+const fingerprintUsage = "Usage: migrate fingerprint [-skip-tls-validation] <service>"
+
+// runFingerprint reports the fingerprint of the data of a service instance, which rolling back a migration compares
+// to the fingerprint recorded once the data was migrated
+func runFingerprint(args []string) {
+	var skipTLSValidation bool
+
+	flags := flag.NewFlagSet("fingerprint", flag.ExitOnError)
+	flags.BoolVar(&skipTLSValidation, "skip-tls-validation", false, "Skip certificate validation of the MySQL server certificate.  Not recommended!")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatal(fingerprintUsage)
+	}
+	instance := flags.Arg(0)
+
+	credentials, err := InstanceCredentials(instance, VcapCredentials)
+	if err != nil {
+		log.Fatalf("Failed to lookup credentials: %v", err)
+	}
+	credentials.SkipTLSValidation = skipTLSValidation
+
+	db, err := sql.Open("mysql", credentials.DSN())
+	if err != nil {
+		log.Fatalf("Failed to initialize connection: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	fingerprint, err := verification.Fingerprint(db)
+	if err != nil {
+		log.Fatalf("Failed to fingerprint %s: %v", instance, err)
+	}
+	log.Printf("The data of %s has fingerprint %s", instance, fingerprint)
+
+	tracker := progress.NewTracker(os.Stdout, nil, time.Now)
+	tracker.RecipientFingerprint(fingerprint)
+	tracker.Finish()
+}
@@ -0,0 +1,149 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+const importUsage = "Usage: migrate import [-from <file|s3://bucket/key>] [-definer <invoker|user@host>] [-schema <from>=<to>]... [-s3-endpoint <url>] [-s3-region <region>] [-skip-tls-validation] <service>"
+
+// runImport loads a dump read from stdin, a file or an S3-compatible object store into a service instance. The
+// dump may be compressed with gzip or zstd, and encrypted by an export.
+func runImport(args []string) {
+	var (
+		source            string
+		definerValue      string
+		schemas           = schemaMap{}
+		s3Endpoint        string
+		s3Region          string
+		skipTLSValidation bool
+	)
+
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.StringVar(&source, "from", "-", "Read the dump from this file, from an s3://<bucket>/<key> URL, or from stdin (-)")
+	flags.StringVar(&definerValue, "definer", definer.Invoker, "Convert views to SQL SECURITY INVOKER and make stored programs owned by the service user (invoker), or map every DEFINER to <user>@<host>")
+	flags.Var(schemas, "schema", "Load the schema <from> of the dump into the schema <to>. May be repeated")
+	flags.StringVar(&s3Endpoint, "s3-endpoint", s3.DefaultEndpoint, "URL of the S3-compatible object store")
+	flags.StringVar(&s3Region, "s3-region", s3.DefaultRegion, "Region of the S3 bucket")
+	flags.BoolVar(&skipTLSValidation, "skip-tls-validation", false, "Skip certificate validation of the MySQL server and object store certificates.  Not recommended!")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatal(importUsage)
+	}
+	instance := flags.Arg(0)
+
+	definers, err := definer.ParsePolicy(definerValue)
+	if err != nil {
+		log.Fatal(err)
+	}
+	definers.Schemas = schemas
+
+	credentials, err := InstanceCredentials(instance, VcapCredentials)
+	if err != nil {
+		log.Fatalf("Failed to lookup credentials: %v", err)
+	}
+	credentials.SkipTLSValidation = skipTLSValidation
+
+	newS3Client := func() (*s3.Client, error) {
+		s3Credentials, err := s3.CredentialsFromEnv()
+		if err != nil {
+			return nil, err
+		}
+
+		return s3.NewClient(s3Endpoint, s3Region, s3Credentials, skipTLSValidation)
+	}
+
+	in, err := importFrom(source, newS3Client)
+	if err != nil {
+		log.Fatalf("Failed to import %s: %v", instance, err)
+	}
+	defer func() { _ = in.Close() }()
+
+	dump, err := archive.NewReader(in, os.Getenv(archive.PassphraseEnv))
+	if err != nil {
+		log.Fatalf("Failed to read the dump: %v", err)
+	}
+	defer func() { _ = dump.Close() }()
+
+	if len(schemas) > 0 {
+		log.Printf("Renaming schemas: %s", schemas)
+	}
+
+	if err := ImportDump(dump, MySQLCmd(credentials), definers); err != nil {
+		log.Fatalf("Failed to import %s: %v", instance, err)
+	}
+
+	log.Printf("Imported the dump into %s", instance)
+}
+
+// importFrom opens the source of an import: stdin ("-"), an s3://<bucket>/<key> URL or a local file
+func importFrom(source string, newS3Client func() (*s3.Client, error)) (io.ReadCloser, error) {
+	switch {
+	case source == "-":
+		return io.NopCloser(os.Stdin), nil
+	case s3.IsURL(source):
+		location, err := s3.ParseURL(source)
+		if err != nil {
+			return nil, err
+		}
+
+		client, err := newS3Client()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Download(location)
+	default:
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", source, err)
+		}
+
+		return f, nil
+	}
+}
+
+// schemaMap is a flag.Value collecting every <from>=<to> occurrence of a repeated flag
+type schemaMap map[string]string
+
+func (m schemaMap) String() string {
+	var mappings []string
+	for from, to := range m {
+		mappings = append(mappings, from+"="+to)
+	}
+	sort.Strings(mappings)
+
+	return strings.Join(mappings, ", ")
+}
+
+func (m schemaMap) Set(value string) error {
+	from, to, err := definer.ParseSchemaMapping(value)
+	if err != nil {
+		return err
+	}
+
+	m[from] = to
+	return nil
+}
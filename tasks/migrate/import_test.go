@@ -0,0 +1,85 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
+)
+
+var _ = Describe("importFrom", func() {
+	noS3 := func() (*s3.Client, error) {
+		Fail("unexpected S3 client")
+		return nil, nil
+	}
+
+	It("reads the dump from a file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "app.sql.gz")
+		Expect(os.WriteFile(path, []byte("some dump"), 0600)).To(Succeed())
+
+		r, err := importFrom(path, noS3)
+		Expect(err).NotTo(HaveOccurred())
+		defer r.Close()
+
+		Expect(io.ReadAll(r)).To(Equal([]byte("some dump")))
+	})
+
+	It("fails when the file does not exist", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "missing.sql.gz")
+
+		_, err := importFrom(path, noS3)
+		Expect(err).To(MatchError(HavePrefix("failed to open " + path + ": ")))
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+
+	It("rejects invalid S3 URLs", func() {
+		_, err := importFrom("s3://backups", noS3)
+		Expect(err).To(MatchError(`invalid S3 URL "s3://backups", expected s3://<bucket>/<key>`))
+	})
+
+	It("fails when the S3 credentials are missing", func() {
+		_, err := importFrom("s3://backups/app.sql.gz", func() (*s3.Client, error) {
+			return nil, errors.New("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to access S3")
+		})
+		Expect(err).To(MatchError("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set to access S3"))
+	})
+})
+
+var _ = Describe("schemaMap", func() {
+	It("collects every occurrence of the flag", func() {
+		schemas := schemaMap{}
+		flags := flag.NewFlagSet("import", flag.ContinueOnError)
+		flags.Var(schemas, "schema", "")
+
+		Expect(flags.Parse([]string{"-schema=sakila=pagila", "-schema", "`app`=`app_v2`"})).To(Succeed())
+		Expect(schemas).To(Equal(schemaMap{"sakila": "pagila", "app": "app_v2"}))
+		Expect(schemas.String()).To(Equal("app=app_v2, sakila=pagila"))
+	})
+
+	It("rejects invalid mappings", func() {
+		flags := flag.NewFlagSet("import", flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		flags.Var(schemaMap{}, "schema", "")
+
+		Expect(flags.Parse([]string{"-schema=sakila"})).
+			To(MatchError(`invalid value "sakila" for flag -schema: invalid schema mapping "sakila", expected <from>=<to>`))
+	})
+})
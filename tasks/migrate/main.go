@@ -15,24 +15,93 @@ package main
 import (
 	"database/sql"
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/charset"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/definer"
 	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/throttle"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/verification"
 )
 
 var VcapCredentials = os.Getenv("VCAP_SERVICES")
 
+// progressInterval is how often the progress of the copy is written to stdout as a progress line
+const progressInterval = 5 * time.Second
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		runExport(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		runImport(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "check-compat" {
+		runCheckCompat(os.Args[2:])
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "fingerprint" {
+		runFingerprint(os.Args[2:])
+		return
+	}
+
 	var (
-		sourceInstance    string
-		destInstance      string
-		skipTLSValidation bool
+		sourceInstance        string
+		destInstance          string
+		skipTLSValidation     bool
+		includeStoredPrograms bool
+		verifyMode            string
+		fingerprint           bool
+		requireEmptyRecipient bool
+		replaceRecipient      bool
+		filter                discovery.Filter
+		parallelism           int
+		online                bool
+		maxLagBytes           int64
+		catchUpFrom           string
+		engineName            string
+		definerValue          string
+		maskingRules          string
+		convertCharset        string
+		collation             string
+		limits                throttle.Limits
 	)
 
 	flag.BoolVar(&skipTLSValidation, "skip-tls-validation", false, "Skip certificate validation of the MySQL server certificate.  Not recommended!")
+	flag.BoolVar(&includeStoredPrograms, "include-stored-programs", false, "Migrate stored routines, triggers and events")
+	flag.BoolVar(&requireEmptyRecipient, "require-empty-recipient", false, "Fail if any schema of the target service already contains tables")
+	flag.BoolVar(&replaceRecipient, "replace-recipient", false, "Drop and re-create the schemas of the target service the data is copied into before copying it")
+	flag.Var((*patternList)(&filter.IncludeSchemas), "include-schema", "Only migrate schemas matching this glob pattern. May be repeated")
+	flag.Var((*patternList)(&filter.ExcludeSchemas), "exclude-schema", "Do not migrate schemas matching this glob pattern. May be repeated")
+	flag.Var((*patternList)(&filter.IncludeTables), "include-table", "Only migrate tables whose schema.table name matches this glob pattern. May be repeated")
+	flag.Var((*patternList)(&filter.ExcludeTables), "exclude-table", "Do not migrate tables whose schema.table name matches this glob pattern. May be repeated")
+	flag.IntVar(&parallelism, "parallel", 1, "Number of tables to copy concurrently from a consistent snapshot. With -engine=exec, writes to the source tables are blocked while they are copied")
+	flag.BoolVar(&online, "online", false, "Keep applying the changes made to the source after copying it, until the recipient is less than -max-lag-bytes behind")
+	flag.Int64Var(&maxLagBytes, "max-lag-bytes", 1024*1024, "Amount of binlog an online migration may be behind the source when it completes")
+	flag.StringVar(&catchUpFrom, "catch-up-from", "", "Only apply the changes made to the source since this <file>:<position> of its binlog, until none are left")
+	flag.StringVar(&engineName, "engine", EngineGo, "Copy data over SQL connections (go), or by piping mysqldump into mysql (exec)")
+	flag.StringVar(&definerValue, "definer", definer.Invoker, "Convert views to SQL SECURITY INVOKER and make stored programs owned by the recipient user (invoker), or map every DEFINER to <user>@<host>")
+	flag.StringVar(&maskingRules, "masking-rules", "", "Mask the values of columns while copying them, according to the rules in this YAML file. Defaults to the rules in $"+masking.RulesEnv)
+	flag.StringVar(&convertCharset, "convert-charset", "", "Convert the schemas, tables and columns copied to this character set, such as utf8mb4")
+	flag.StringVar(&collation, "collation", "", "Collation of the converted character set. Defaults to the default collation of the character set on the recipient")
+	flag.Int64Var(&limits.RowsPerSecond, "max-rows-per-second", 0, "Copy at most this many rows per second. 0 does not limit them")
+	flag.Int64Var(&limits.BytesPerSecond, "max-bandwidth", 0, "Read at most this many bytes per second from the source. 0 does not limit them")
+	flag.IntVar(&limits.MaxThreadsRunning, "max-threads-running", 0, "Pause the copy while the source has more than this many Threads_running. 0 does not pause it")
+	flag.DurationVar(&limits.MaxReplicationLag, "max-replication-lag", 0, "Pause the copy while the source, when it is a replica, lags further behind than this. 0 does not pause it")
+	flag.StringVar(&verifyMode, "verify", "", "Verify the migrated data by comparing row counts (rows) or row counts and table checksums (checksum)")
+	flag.BoolVar(&fingerprint, "fingerprint", false, "Report a fingerprint of the target service's data once migrated, which checksums every table of it")
 	flag.Parse()
 	args := flag.Args()
 
@@ -40,6 +109,73 @@ func main() {
 		log.Fatal("Usage: migrate <source service> <target service>")
 	}
 
+	if verifyMode != "" {
+		if _, err := verification.ParseMode(verifyMode); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := filter.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	if engineName != EngineGo && engineName != EngineExec {
+		log.Fatalf("invalid -engine value %q, expected %q or %q", engineName, EngineGo, EngineExec)
+	}
+
+	definers, err := definer.ParsePolicy(definerValue)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if parallelism < 1 {
+		log.Fatalf("invalid -parallel value %d, expected at least 1", parallelism)
+	}
+
+	if limits.RowsPerSecond < 0 || limits.BytesPerSecond < 0 || limits.MaxThreadsRunning < 0 || limits.MaxReplicationLag < 0 {
+		log.Fatal("-max-rows-per-second, -max-bandwidth, -max-threads-running and -max-replication-lag must not be negative")
+	}
+
+	if (online || catchUpFrom != "") && (len(filter.IncludeSchemas) > 0 || len(filter.ExcludeSchemas) > 0 || filter.FiltersTables()) {
+		log.Fatal("Changes can only be applied to the recipient when every table is migrated, remove the schema and table filters")
+	}
+
+	if replaceRecipient && (requireEmptyRecipient || catchUpFrom != "" || filter.FiltersTables()) {
+		log.Fatal("-replace-recipient can not be combined with -require-empty-recipient, -catch-up-from or table filters")
+	}
+
+	var masker *masking.Masker
+	if maskingRules != "" || os.Getenv(masking.RulesEnv) != "" {
+		rules, err := loadMaskingRules(maskingRules)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Changes applied from the binlog and table checksums would expose or compare the unmasked values
+		if online || catchUpFrom != "" || verifyMode == string(verification.ModeChecksum) {
+			log.Fatal("Masked data can not be migrated online, or verified with checksums")
+		}
+
+		masker = masking.NewMasker(rules)
+	}
+
+	if collation != "" && convertCharset == "" {
+		log.Fatal("-collation requires -convert-charset")
+	}
+
+	// Changes applied from the binlog carry the values of the source's character set, and checksums differ
+	if convertCharset != "" && (online || catchUpFrom != "" || verifyMode == string(verification.ModeChecksum)) {
+		log.Fatal("Converted data can not be migrated online, or verified with checksums")
+	}
+
+	var startPosition BinlogPosition
+	if catchUpFrom != "" {
+		var err error
+		if startPosition, err = ParseBinlogPosition(catchUpFrom); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	sourceInstance = args[0]
 	destInstance = args[1]
 
@@ -74,11 +210,58 @@ func main() {
 		log.Fatalf("Failed to initialize source connection: %v", err)
 	}
 
-	sourceSchemas, err := discovery.DiscoverDatabases(db)
+	destDB, err := sql.Open("mysql", destCredentials.DSN())
+	if err != nil {
+		log.Fatalf("Failed to initialize destination connection: %v", err)
+	}
+	defer func() { _ = destDB.Close() }()
+
+	var conversion *charset.Conversion
+	if convertCharset != "" {
+		if conversion, err = charset.Load(destDB, convertCharset, collation); err != nil {
+			log.Fatalf("Invalid -convert-charset: %v", err)
+		}
+		log.Printf("Converting character sets to %s", conversion)
+	}
+
+	var throttler *throttle.Throttle
+	if !limits.Unlimited() {
+		throttler = throttle.New(limits, throttle.SourceLoad(db, limits.MaxReplicationLag > 0))
+		log.Printf("Throttling the copy to %s", limits)
+	}
+
+	if online || catchUpFrom != "" {
+		if err := CheckBinlogSettings(db, destDB); err != nil {
+			log.Fatalf("Changes made to %s can not be applied to the recipient: %v", sourceInstance, err)
+		}
+	}
+
+	if requireEmptyRecipient && catchUpFrom == "" {
+		if err := checkRecipientEmpty(destDB); err != nil {
+			log.Fatalf("Refusing to migrate into %s: %v", destInstance, err)
+		}
+	}
+
+	allSchemas, err := discovery.DiscoverDatabases(db)
 	if err != nil {
 		log.Fatalf("Failed to discover schemas: %v", err)
 	}
 
+	sourceSchemas := filter.Schemas(allSchemas)
+	if len(sourceSchemas) == 0 {
+		log.Fatalf("None of the schemas %v match the schema filters", allSchemas)
+	}
+	log.Printf("Migrating schemas: %s", strings.Join(sourceSchemas, ", "))
+
+	excludedTables, err := filter.ExcludedTables(db, sourceSchemas)
+	if err != nil {
+		log.Fatalf("Failed to apply the table filters: %v", err)
+	}
+
+	if len(excludedTables) > 0 {
+		log.Printf("Excluding tables: %s", strings.Join(excludedTables, ", "))
+	}
+
 	invalidViews, err := discovery.DiscoverInvalidViews(db, sourceSchemas)
 	if err != nil {
 		log.Fatalf("Failed to retrieve invalid views: %v", err)
@@ -88,11 +271,272 @@ func main() {
 		log.Printf("The following views are invalid, and will not be migrated: %s\n", invalidViews)
 	}
 
-	mySQLDumpCmd := MySQLDumpCmd(sourceCredentials, invalidViews, sourceSchemas...)
-	mySQLCmd := MySQLCmd(destCredentials)
-	replaceCmd := ReplaceDefinerCmd()
+	if conversion != nil {
+		overflows, err := charset.FindIndexOverflows(db, sourceSchemas, conversion)
+		if err != nil {
+			log.Fatalf("Failed to check the length of indexes: %v", err)
+		}
 
-	if err := CopyData(mySQLDumpCmd, replaceCmd, mySQLCmd); err != nil {
+		// The recipient session is not strict, so indexes that are not unique are shortened to a prefix instead
+		for _, o := range overflows {
+			log.Printf("WARNING: %s. Unique indexes fail to be created, and others are shortened to a prefix", o)
+		}
+	}
+
+	recipientSchema := RecipientSchemaMapper(sourceSchemas, destCredentials.Name)
+
+	if replaceRecipient {
+		replaced := map[string]struct{}{}
+		var recipientSchemas []string
+		for _, schema := range sourceSchemas {
+			if _, ok := replaced[recipientSchema(schema)]; !ok {
+				replaced[recipientSchema(schema)] = struct{}{}
+				recipientSchemas = append(recipientSchemas, recipientSchema(schema))
+			}
+		}
+
+		log.Printf("Replacing schemas of %s: %s", destInstance, strings.Join(recipientSchemas, ", "))
+		if err := ReplaceSchemas(destDB, recipientSchemas); err != nil {
+			log.Fatalf("Failed to replace the schemas of %s: %v", destInstance, err)
+		}
+	}
+
+	engine, err := NewCopyEngine(engineName, db, destDB, sourceCredentials, destCredentials, invalidViews, excludedTables, recipientSchema, definers, masker, conversion, throttler)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if catchUpFrom != "" {
+		tracker := progress.NewTracker(os.Stdout, nil, time.Now)
+		position := catchUpWith(db, sourceCredentials, destCredentials, sourceInstance, startPosition, sourceSchemas, recipientSchema, 0, tracker)
+		log.Printf("Applied every change made to %s up to %s", sourceInstance, position)
+
+		if verifyMode != "" {
+			verifyData(db, destDB, sourceSchemas, filter, recipientSchema, verification.Mode(verifyMode))
+		}
+
+		if fingerprint {
+			reportRecipientFingerprint(destDB, tracker)
+		}
+		tracker.Finish()
+		return
+	}
+
+	tables, err := includedTables(db, sourceSchemas, filter)
+	if err != nil {
+		log.Fatalf("Failed to discover the tables to copy: %v", err)
+	}
+
+	tracker := progress.NewTracker(os.Stdout, tables, time.Now)
+	tracker.Migrating(sourceSchemas, invalidViews)
+	stopReporting := tracker.ReportEvery(progressInterval)
+
+	var position BinlogPosition
+	if parallelism > 1 {
+		position, err = copyDataInParallel(engine, sourceSchemas, tables, parallelism, tracker, online)
+	} else {
+		// A dump of several schemas names each of them before its tables
+		var dumpedSchema string
+		if len(sourceSchemas) == 1 {
+			dumpedSchema = sourceSchemas[0]
+		}
+
+		stream := tracker.Stream(dumpedSchema)
+		position, err = engine.CopySchemas(sourceSchemas, false, online, stream)
+		_ = stream.Close()
+	}
+	stopReporting()
+	if err != nil {
 		log.Fatalf("Failed to copy data: %v", err)
 	}
+
+	if masker != nil {
+		if unmatched := masker.WriteSummary(os.Stdout); unmatched > 0 {
+			log.Fatalf("%d masking rules did not match any column copied", unmatched)
+		}
+	}
+
+	// Masked values differ from the source anyway
+	if conversion != nil && masker == nil {
+		verifyConversion(db, destDB, tables, recipientSchema, conversion)
+	}
+
+	// The data of an online migration keeps changing until the writes to the source are stopped, so it is verified
+	// once the last changes were applied
+	if verifyMode != "" && !online {
+		verifyData(db, destDB, sourceSchemas, filter, recipientSchema, verification.Mode(verifyMode))
+	}
+
+	if includeStoredPrograms {
+		migrateStoredPrograms(db, destDB, engine, sourceSchemas, filter, recipientSchema)
+	}
+
+	if online {
+		position = catchUpWith(db, sourceCredentials, destCredentials, sourceInstance, position, sourceSchemas, recipientSchema, maxLagBytes, tracker)
+		log.Printf("Caught up with %s at %s. Stop writing to it before cutting over", sourceInstance, position)
+	}
+
+	if fingerprint {
+		reportRecipientFingerprint(destDB, tracker)
+	}
+	tracker.Finish()
+}
+
+// reportRecipientFingerprint reports the fingerprint of the data of the recipient, so that rolling back the migration
+// can tell whether the recipient was written to since
+func reportRecipientFingerprint(destDB *sql.DB, tracker *progress.Tracker) {
+	fingerprint, err := verification.Fingerprint(destDB)
+	if err != nil {
+		log.Printf("Warning: failed to fingerprint the recipient: %v. Rolling back the migration will not be able to tell whether the recipient was written to since", err)
+		return
+	}
+
+	tracker.RecipientFingerprint(fingerprint)
+}
+
+func catchUpWith(sourceDB *sql.DB, sourceCredentials, destCredentials Credentials, sourceInstance string, from BinlogPosition, sourceSchemas []string, recipientSchema func(string) string, maxLagBytes int64, tracker *progress.Tracker) BinlogPosition {
+	log.Printf("Applying the changes made to %s since %s", sourceInstance, from)
+
+	stopReporting := tracker.ReportEvery(progressInterval)
+	position, err := CatchUp(sourceDB, sourceCredentials, destCredentials, from, sourceSchemas, recipientSchema, maxLagBytes, tracker)
+	stopReporting()
+	if err != nil {
+		log.Fatalf("Failed to apply the changes made to %s: %v", sourceInstance, err)
+	}
+
+	return position
+}
+
+// includedTables returns the base tables to copy, largest first
+func includedTables(db *sql.DB, schemas []string, filter discovery.Filter) ([]discovery.Table, error) {
+	discovered, err := discovery.DiscoverTableSizes(db, schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	var tables []discovery.Table
+	for _, t := range discovered {
+		if filter.TableIncluded(t.Schema, t.Name) {
+			tables = append(tables, t)
+		}
+	}
+
+	return tables, nil
+}
+
+// loadMaskingRules reads the masking rules from file, or from the environment when no file is given
+func loadMaskingRules(file string) (masking.Rules, error) {
+	if file != "" {
+		return masking.Load(file)
+	}
+
+	return masking.Parse([]byte(os.Getenv(masking.RulesEnv)))
+}
+
+func checkRecipientEmpty(destDB *sql.DB) error {
+	schemas, err := discovery.DiscoverDatabases(destDB)
+	if err != nil {
+		return fmt.Errorf("failed to discover target schemas: %w", err)
+	}
+
+	for _, schema := range schemas {
+		tables, err := discovery.DiscoverTables(destDB, schema)
+		if err != nil {
+			return err
+		}
+
+		if len(tables) > 0 {
+			return fmt.Errorf("schema %s already contains %d tables. Pass --force-overwrite to migrate into it anyway", schema, len(tables))
+		}
+	}
+
+	return nil
+}
+
+// RecipientSchemaMapper maps a donor schema name to the schema it is loaded into on the recipient.
+// A single schema is always loaded into the recipient's default database.
+func RecipientSchemaMapper(sourceSchemas []string, recipientDefaultSchema string) func(string) string {
+	return func(schema string) string {
+		if len(sourceSchemas) == 1 {
+			return recipientDefaultSchema
+		}
+		return schema
+	}
+}
+
+func verifyData(sourceDB, destDB *sql.DB, sourceSchemas []string, filter discovery.Filter, recipientSchema func(string) string, mode verification.Mode) {
+	log.Printf("Verifying migrated data using %s", mode)
+
+	results, err := verification.VerifyTables(sourceDB, destDB, sourceSchemas, filter, recipientSchema, mode)
+	if err != nil {
+		log.Fatalf("Failed to verify migrated data: %v", err)
+	}
+
+	if mismatches := verification.WriteReport(os.Stdout, results); mismatches > 0 {
+		log.Fatalf("Data verification failed for %d of %d tables", mismatches, len(results))
+	}
+}
+
+func verifyConversion(sourceDB, destDB *sql.DB, tables []discovery.Table, recipientSchema func(string) string, conversion *charset.Conversion) {
+	log.Printf("Comparing the text of up to %d rows of each converted table", charset.DefaultSampleSize)
+
+	results, err := charset.VerifyRoundTrip(sourceDB, destDB, tables, recipientSchema, conversion, charset.DefaultSampleSize)
+	if err != nil {
+		log.Fatalf("Failed to verify the converted text: %v", err)
+	}
+
+	if mismatches := charset.WriteRoundTripReport(os.Stdout, results); mismatches > 0 {
+		log.Fatalf("Text did not round-trip to %s for %d of %d tables", conversion.Charset, mismatches, len(results))
+	}
+}
+
+func migrateStoredPrograms(sourceDB, destDB *sql.DB, engine CopyEngine, sourceSchemas []string, filter discovery.Filter, recipientSchema func(string) string) {
+	discoveredPrograms, err := discovery.DiscoverStoredPrograms(sourceDB, sourceSchemas)
+	if err != nil {
+		log.Fatalf("Failed to discover stored programs: %v", err)
+	}
+
+	// Triggers of excluded tables are not migrated along with their table
+	var storedPrograms []discovery.StoredProgram
+	for _, p := range discoveredPrograms {
+		if p.Table == "" || filter.TableIncluded(p.Schema, p.Table) {
+			storedPrograms = append(storedPrograms, p)
+		}
+	}
+
+	if len(storedPrograms) > 0 {
+		log.Printf("Migrating %d routines, triggers and events", len(storedPrograms))
+
+		// Failures are only logged here, since every one of them shows up in the report below
+		if err := engine.CopyStoredPrograms(sourceSchemas, storedPrograms); err != nil {
+			log.Printf("Failed to copy stored programs: %v", err)
+		}
+	}
+
+	var recipientSchemas []string
+	for _, schema := range sourceSchemas {
+		recipientSchemas = append(recipientSchemas, recipientSchema(schema))
+	}
+
+	migratedPrograms, err := discovery.DiscoverStoredPrograms(destDB, recipientSchemas)
+	if err != nil {
+		log.Fatalf("Failed to verify migrated stored programs: %v", err)
+	}
+
+	results := CompareStoredPrograms(storedPrograms, migratedPrograms, recipientSchema)
+	if failures := WriteStoredProgramReport(os.Stdout, results); failures > 0 {
+		log.Fatalf("Failed to migrate %d of %d stored programs", failures, len(results))
+	}
+}
+
+// patternList is a flag.Value collecting every occurrence of a repeated flag
+type patternList []string
+
+func (l *patternList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *patternList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
 }
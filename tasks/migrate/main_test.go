@@ -13,16 +13,25 @@
 package main_test
 
 import (
+	"bytes"
 	"database/sql"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/klauspost/compress/zstd"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gmeasure"
 
 	"github.com/pivotal-cf/mysql-cli-plugin/internal/testing/docker"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/archive"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/s3"
 )
 
 const dockerVcapServicesTemplate = `
@@ -92,6 +101,7 @@ var _ = Describe("Migrate Task", func() {
 			Volumes: []string{
 				filepath.Join(fixturesPath, "sakila-schema.sql:/docker-entrypoint-initdb.d/sakila-schema.sql"),
 			},
+			Args: []string{"--log-bin=mysql-bin", "--server-id=1", "--binlog-format=ROW"},
 		})).Error().NotTo(HaveOccurred())
 
 		Expect(docker.CreateContainer(docker.ContainerSpec{
@@ -133,7 +143,7 @@ var _ = Describe("Migrate Task", func() {
 	})
 
 	It("migrates data between the source and destination", func() {
-		_, err := docker.Run(
+		output, err := docker.Run(
 			"--env=VCAP_SERVICES="+vcapServices,
 			"--name=migrate.command."+uuid.NewString(),
 			"--network="+containerNetwork,
@@ -148,6 +158,402 @@ var _ = Describe("Migrate Task", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		Expect(destChecksums).To(Equal(sourceChecksums))
+
+		By("reporting the progress of the copy", func() {
+			var last progress.Update
+			for _, line := range strings.Split(output, "\n") {
+				if update, ok := progress.Parse(line); ok {
+					last = update
+				}
+			}
+
+			Expect(last.Done).To(BeTrue())
+			Expect(last.TablesCopied).To(Equal(last.TablesTotal))
+			Expect(last.Rows).To(BeNumerically(">", 0))
+			Expect(last.Bytes).To(BeNumerically(">", 0))
+		})
+	})
+
+	Context("when the recipient must be empty", func() {
+		It("refuses to migrate into a recipient that already contains tables", func() {
+			_, err := destDB.Exec(`CREATE TABLE service_instance_db.existing (id INT PRIMARY KEY)`)
+			Expect(err).NotTo(HaveOccurred())
+
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-require-empty-recipient", "source", "dest",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(ContainSubstring(`Refusing to migrate into dest: schema service_instance_db already contains 1 tables`))
+		})
+	})
+
+	Context("when migrating online", func() {
+		runMigration := func(args ...string) string {
+			output, err := docker.Run(append([]string{
+				"--env=VCAP_SERVICES=" + vcapServices,
+				"--name=migrate.command." + uuid.NewString(),
+				"--network=" + containerNetwork,
+				"--rm",
+				"--volume=" + migrateTaskBinPath + ":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate",
+			}, append(args, "source", "dest")...)...)
+			Expect(err).NotTo(HaveOccurred(), output)
+
+			return output
+		}
+
+		finalPosition := func(output string) string {
+			var last progress.Update
+			for _, line := range strings.Split(output, "\n") {
+				if update, ok := progress.Parse(line); ok {
+					last = update
+				}
+			}
+
+			Expect(last.Done).To(BeTrue())
+			return last.BinlogPosition
+		}
+
+		It("applies the changes made after the copy once writes were stopped", func() {
+			output := runMigration("-online")
+			position := finalPosition(output)
+			Expect(position).To(HavePrefix("mysql-bin."))
+
+			_, err := sourceDB.Exec(`UPDATE sakila.actor SET last_name = 'CUTOVER' WHERE actor_id = 1`)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sourceDB.Exec(`DELETE FROM sakila.payment WHERE payment_id = 1`)
+			Expect(err).NotTo(HaveOccurred())
+
+			sourceChecksums, err = schemaChecksum(sourceDB, "sakila")
+			Expect(err).NotTo(HaveOccurred())
+
+			output = runMigration("-catch-up-from="+position, "-verify=checksum")
+			Expect(finalPosition(output)).NotTo(Equal(position))
+
+			destChecksums, err := schemaChecksum(destDB, "sakila")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destChecksums).To(Equal(sourceChecksums))
+		})
+	})
+
+	Context("when copying tables in parallel", func() {
+		runMigration := func(args ...string) {
+			_, err := docker.Run(append([]string{
+				"--env=VCAP_SERVICES=" + vcapServices,
+				"--name=migrate.command." + uuid.NewString(),
+				"--network=" + containerNetwork,
+				"--rm",
+				"--volume=" + migrateTaskBinPath + ":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate",
+			}, append(args, "source", "dest")...)...)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("migrates the same data as a single pipeline", func() {
+			runMigration("-parallel=4")
+
+			destChecksums, err := schemaChecksum(destDB, "sakila")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destChecksums).To(Equal(sourceChecksums))
+		})
+
+		It("is benchmarked against piping mysqldump into mysql", Label("benchmark"), func() {
+			experiment := gmeasure.NewExperiment("copying sakila")
+			AddReportEntry(experiment.Name, experiment)
+
+			for _, mode := range []string{"-engine=exec", "-parallel=4"} {
+				experiment.Sample(func(int) {
+					_, err := destDB.Exec("DROP DATABASE IF EXISTS sakila")
+					Expect(err).NotTo(HaveOccurred())
+
+					experiment.MeasureDuration(mode, func() { runMigration(mode) })
+				}, gmeasure.SamplingConfig{N: 3})
+			}
+
+			destChecksums, err := schemaChecksum(destDB, "sakila")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destChecksums).To(Equal(sourceChecksums))
+		})
+	})
+
+	Context("when copying with the exec engine", func() {
+		It("migrates the same data by piping mysqldump into mysql", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-engine=exec", "-include-stored-programs", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			destChecksums, err := schemaChecksum(destDB, "sakila")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destChecksums).To(Equal(sourceChecksums))
+			Expect(output).To(MatchRegexp(`OK\s+PROCEDURE sakila.film_in_stock`))
+		})
+
+		It("rejects an unknown engine", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-engine=rsync", "source", "dest",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(ContainSubstring(`invalid -engine value "rsync"`))
+		})
+	})
+
+	Context("when filtering schemas and tables", func() {
+		It("only migrates the selected tables and logs the resolved set", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-include-schema=sakila", "-exclude-table=sakila.film_text", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("Migrating schemas: sakila"),
+				ContainSubstring("Excluding tables: sakila.film_text"),
+			))
+
+			var count int
+			Expect(destDB.QueryRow(`SELECT COUNT(*) FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = 'service_instance_db' AND TABLE_NAME = 'film_text'`).
+				Scan(&count)).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+	})
+
+	Context("when masking columns", func() {
+		It("rewrites the masked columns and summarizes them", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--env=MASKING_RULES=tables: {sakila.customer: {email: fake_email, last_name: hash}, sakila.staff: {password: null}}",
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-verify=rows", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("Masked columns:"),
+				ContainSubstring("sakila.customer.email: fake_email (599 rows)"),
+				ContainSubstring("sakila.staff.password: null (2 rows)"),
+			))
+
+			var unmasked int
+			Expect(destDB.QueryRow(`SELECT COUNT(*) FROM service_instance_db.customer WHERE email NOT LIKE 'user-%@example.com' OR LENGTH(last_name) <> 45`).
+				Scan(&unmasked)).To(Succeed())
+			Expect(unmasked).To(BeZero())
+
+			Expect(destDB.QueryRow(`SELECT COUNT(*) FROM service_instance_db.staff WHERE password IS NOT NULL`).
+				Scan(&unmasked)).To(Succeed())
+			Expect(unmasked).To(BeZero())
+		})
+
+		It("fails when a rule does not match any column", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--env=MASKING_RULES=tables: {sakila.customer: {e_mail: hash}}",
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "source", "dest",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("NOT FOUND sakila.customer.e_mail: no table copied has this column"),
+				ContainSubstring("1 masking rules did not match any column copied"),
+			))
+		})
+	})
+
+	Context("when converting character sets", func() {
+		It("converts the tables and columns copied, and compares their text with the source", func() {
+			_, err := sourceDB.Exec("CREATE TABLE sakila.latin1_notes (id INT PRIMARY KEY, note VARCHAR(255), code VARCHAR(10) COLLATE latin1_bin, KEY (note)) DEFAULT CHARSET=latin1 ROW_FORMAT=COMPACT")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sourceDB.Exec("INSERT INTO sakila.latin1_notes VALUES (1, 'Café crème', 'AbC'), (2, 'Jürgen', NULL)")
+			Expect(err).NotTo(HaveOccurred())
+
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-convert-charset=utf8mb4", "-collation=utf8mb4_unicode_ci", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("WARNING: index note of sakila.latin1_notes is 1020 bytes once converted, over the limit of 767 bytes"),
+				ContainSubstring("Character set conversion report:"),
+				MatchRegexp(`Compared \d+ rows of \d+ tables, 0 tables mismatched`),
+			))
+
+			var tableCollation, codeCollation, note string
+			Expect(destDB.QueryRow(`SELECT TABLE_COLLATION FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = 'service_instance_db' AND TABLE_NAME = 'latin1_notes'`).
+				Scan(&tableCollation)).To(Succeed())
+			Expect(tableCollation).To(Equal("utf8mb4_unicode_ci"))
+			Expect(destDB.QueryRow(`SELECT COLLATION_NAME FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = 'service_instance_db' AND TABLE_NAME = 'latin1_notes' AND COLUMN_NAME = 'code'`).
+				Scan(&codeCollation)).To(Succeed())
+			Expect(codeCollation).To(Equal("utf8mb4_bin"))
+			Expect(destDB.QueryRow(`SELECT note FROM service_instance_db.latin1_notes WHERE id = 1`).Scan(&note)).To(Succeed())
+			Expect(note).To(Equal("Café crème"))
+		})
+	})
+
+	Context("when throttling the copy", func() {
+		It("copies every row while holding to the limits", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-max-bandwidth=10485760", "-max-threads-running=1000", "-verify=rows", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("Throttling the copy to 10485760 bytes per second, at most 1000 threads running on the source"),
+				MatchRegexp(`Verified \d+ tables, 0 mismatched`),
+			))
+		})
+
+		It("rejects negative limits", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-max-rows-per-second=-1", "source", "dest",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(ContainSubstring("-max-rows-per-second, -max-bandwidth, -max-threads-running and -max-replication-lag must not be negative"))
+		})
+	})
+
+	Context("when verifying the migrated data", func() {
+		It("compares row counts and checksums of every table and reports no mismatches", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-verify=checksum", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("Data verification report:"),
+				MatchRegexp(`Verified \d+ tables, 0 mismatched`),
+			))
+		})
+
+		It("rejects an unknown verification mode", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-verify=md5", "source", "dest",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(ContainSubstring(`invalid verification mode "md5"`))
+		})
+	})
+
+	Context("when migrating stored programs", func() {
+		It("migrates routines, triggers and events and reports on each of them", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-include-stored-programs", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("Stored program migration report:"),
+				MatchRegexp(`OK\s+PROCEDURE sakila.film_in_stock`),
+				MatchRegexp(`OK\s+FUNCTION sakila.inventory_in_stock`),
+				MatchRegexp(`OK\s+TRIGGER sakila.ins_film`),
+			))
+
+			var definer string
+			Expect(destDB.QueryRow(`SELECT DEFINER FROM INFORMATION_SCHEMA.ROUTINES WHERE ROUTINE_SCHEMA = 'sakila' AND ROUTINE_NAME = 'film_in_stock'`).
+				Scan(&definer)).To(Succeed())
+			Expect(definer).To(Equal("root@%"))
+		})
+	})
+
+	Context("when mapping definers to an account", func() {
+		It("keeps views running with the privileges of that account", func() {
+			_, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-definer=root@%", "-include-stored-programs", "source", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			var definer, securityType string
+			Expect(destDB.QueryRow(`SELECT DEFINER, SECURITY_TYPE FROM INFORMATION_SCHEMA.VIEWS WHERE TABLE_SCHEMA = 'sakila' AND TABLE_NAME = 'actor_info'`).
+				Scan(&definer, &securityType)).To(Succeed())
+			Expect(definer).To(Equal("root@%"))
+			Expect(securityType).To(Equal("DEFINER"))
+		})
+
+		It("rejects an invalid definer", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "-definer=root", "source", "dest",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(ContainSubstring(`invalid definer "root"`))
+		})
 	})
 
 	Context("when resolving mysql host keep failing", func() {
@@ -175,6 +581,221 @@ var _ = Describe("Migrate Task", func() {
 		})
 	})
 
+	Context("when exporting", func() {
+		It("writes an encrypted dump of every schema to a file", func() {
+			exportDir := GinkgoT().TempDir()
+
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--env="+archive.PassphraseEnv+"=secret",
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"--volume="+exportDir+":/export",
+				"percona:5.7",
+				"migrate", "export", "-to=/export/source.sql.gz", "source",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("Exported source to /export/source.sql.gz"))
+
+			f, err := os.Open(filepath.Join(exportDir, "source.sql.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			dump := readDump(f, "secret")
+			Expect(dump).To(ContainSubstring("CREATE DATABASE /*!32312 IF NOT EXISTS*/ `sakila`"))
+			Expect(dump).To(ContainSubstring("INSERT INTO `actor` VALUES"))
+			Expect(dump).To(ContainSubstring("PROCEDURE `film_in_stock`"))
+		})
+
+		It("uploads the dump to an S3-compatible object store", func() {
+			minioContainer := "minio." + uuid.NewString()
+			Expect(docker.CreateContainer(docker.ContainerSpec{
+				Name:        minioContainer,
+				Image:       "minio/minio",
+				Network:     containerNetwork,
+				Env:         []string{"MINIO_ROOT_USER=minio-user", "MINIO_ROOT_PASSWORD=minio-password"},
+				Args:        []string{"server", "/data"},
+				ExposePorts: []string{"9000"},
+			})).Error().NotTo(HaveOccurred())
+			DeferCleanup(docker.RemoveContainer, minioContainer)
+
+			Eventually(func() error {
+				_, err := docker.Run(
+					"--network="+containerNetwork,
+					"--rm",
+					"--entrypoint=sh",
+					"minio/mc",
+					"-c", "mc alias set store http://"+minioContainer+":9000 minio-user minio-password && mc mb --ignore-existing store/backups",
+				)
+				return err
+			}, "1m", "2s").Should(Succeed())
+
+			_, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--env="+s3.AccessKeyIDEnv+"=minio-user",
+				"--env="+s3.SecretAccessKeyEnv+"=minio-password",
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "export", "-to=s3://backups/mysql/source.sql.gz", "-s3-endpoint=http://"+minioContainer+":9000", "source",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			minioPort, err := docker.ContainerPort(minioContainer, "9000/tcp")
+			Expect(err).NotTo(HaveOccurred())
+			client, err := s3.NewClient("http://localhost:"+minioPort, "", s3.Credentials{AccessKeyID: "minio-user", SecretAccessKey: "minio-password"}, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			object, err := client.Download(s3.Location{Bucket: "backups", Key: "mysql/source.sql.gz"})
+			Expect(err).NotTo(HaveOccurred())
+			defer object.Close()
+
+			Expect(readDump(object, "")).To(ContainSubstring("INSERT INTO `actor` VALUES"))
+		})
+	})
+
+	Context("when importing", func() {
+		var exportDir string
+
+		BeforeEach(func() {
+			exportDir = GinkgoT().TempDir()
+
+			_, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--env="+archive.PassphraseEnv+"=secret",
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"--volume="+exportDir+":/export",
+				"percona:5.7",
+				"migrate", "export", "-to=/export/source.sql.gz", "source",
+			)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("loads an exported dump into another schema", func() {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--env="+archive.PassphraseEnv+"=secret",
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"--volume="+exportDir+":/export",
+				"percona:5.7",
+				"migrate", "import", "-from=/export/source.sql.gz", "-schema=sakila=sakila_imported", "dest",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("Imported the dump into dest"))
+
+			importedChecksums, err := schemaChecksum(destDB, "sakila_imported")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.ReplaceAll(importedChecksums, "sakila_imported.", "sakila.")).To(Equal(sourceChecksums))
+
+			var viewDefinition string
+			Expect(destDB.QueryRow(`SELECT VIEW_DEFINITION FROM INFORMATION_SCHEMA.VIEWS WHERE TABLE_SCHEMA = 'sakila_imported' AND TABLE_NAME = 'actor_info'`).Scan(&viewDefinition)).To(Succeed())
+			Expect(viewDefinition).To(ContainSubstring("`sakila_imported`."))
+			Expect(viewDefinition).NotTo(ContainSubstring("`sakila`."))
+		})
+
+		It("loads a dump compressed with zstd from stdin", func() {
+			f, err := os.Open(filepath.Join(exportDir, "source.sql.gz"))
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+
+			var compressed bytes.Buffer
+			w, err := zstd.NewWriter(&compressed)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = io.WriteString(w, readDump(f, "secret"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(w.Close()).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(exportDir, "source.sql.zst"), compressed.Bytes(), 0644)).To(Succeed())
+
+			_, err = docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"--volume="+exportDir+":/export",
+				"--entrypoint=sh",
+				"percona:5.7",
+				"-c", "migrate import dest < /export/source.sql.zst",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			destChecksums, err := schemaChecksum(destDB, "sakila")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(destChecksums).To(Equal(sourceChecksums))
+		})
+	})
+
+	Context("when checking compatibility", func() {
+		It("reports blockers and warnings with suggested fixes, and fails when there are blockers", func() {
+			_, err := sourceDB.Exec("CREATE TABLE sakila.legacy_log (id INT PRIMARY KEY, `rank` INT) ENGINE=MyISAM")
+			Expect(err).NotTo(HaveOccurred())
+
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "check-compat", "source",
+			)
+			Expect(err).To(MatchError(`exit status 1`))
+			Expect(output).To(SatisfyAll(
+				ContainSubstring("[Storage engines] sakila.legacy_log: uses the MyISAM storage engine"),
+				ContainSubstring("Fix: ALTER TABLE `sakila`.`legacy_log` ENGINE=InnoDB"),
+				ContainSubstring("[Reserved words] sakila.legacy_log.rank: RANK is a reserved word in MySQL 8.0"),
+				ContainSubstring("source can not be migrated to MySQL 8.0 until the"),
+			))
+		})
+	})
+
+	Context("when fingerprinting the data", func() {
+		fingerprint := func() string {
+			output, err := docker.Run(
+				"--env=VCAP_SERVICES="+vcapServices,
+				"--name=migrate.command."+uuid.NewString(),
+				"--network="+containerNetwork,
+				"--rm",
+				"--volume="+migrateTaskBinPath+":/usr/local/bin/migrate",
+				"percona:5.7",
+				"migrate", "fingerprint", "source",
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			matches := regexp.MustCompile(`"recipient_fingerprint":"([0-9a-f]+)"`).FindStringSubmatch(output)
+			Expect(matches).To(HaveLen(2))
+			return matches[1]
+		}
+
+		It("ignores the accounts binding and unbinding apps create and drop, but not writes", func() {
+			before := fingerprint()
+
+			_, err := sourceDB.Exec("CREATE USER 'some-binding'@'%' IDENTIFIED BY 'some-password'")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = sourceDB.Exec("GRANT ALL PRIVILEGES ON `sakila`.* TO 'some-binding'@'%'")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fingerprint()).To(Equal(before))
+
+			_, err = sourceDB.Exec("DROP USER 'some-binding'@'%'")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fingerprint()).To(Equal(before))
+
+			_, err = sourceDB.Exec("INSERT INTO sakila.language (name) VALUES ('Esperanto')")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fingerprint()).NotTo(Equal(before))
+		})
+	})
+
 	Context("when a TLS CA certificate is provided", func() {
 		BeforeEach(func() {
 			vcapServices = fmt.Sprintf(dockerVcapServicesTemplate, sourceContainer, destContainer, "some-ca-cert")
@@ -247,3 +868,11 @@ func tableChecksum(db *sql.DB, schemaName, tableName string) (string, error) {
 
 	return result, nil
 }
+
+func readDump(r io.Reader, passphrase string) string {
+	dump, err := archive.NewReader(r, passphrase)
+	Expect(err).NotTo(HaveOccurred())
+	out, err := io.ReadAll(dump)
+	Expect(err).NotTo(HaveOccurred())
+	return string(out)
+}
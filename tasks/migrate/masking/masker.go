@@ -0,0 +1,157 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package masking
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Masker rewrites the values of the columns rules apply to, and counts the rows it masked
+type Masker struct {
+	rules Rules
+
+	mu      sync.Mutex
+	columns map[maskedColumn]*int64
+}
+
+type maskedColumn struct {
+	pattern string
+	table   string
+	column  string
+}
+
+func NewMasker(rules Rules) *Masker {
+	return &Masker{
+		rules:   rules,
+		columns: map[maskedColumn]*int64{},
+	}
+}
+
+// TableMask masks the rows of a table, whose values are in the order of the columns it was created for
+type TableMask struct {
+	salt       string
+	transforms []*Transform
+	rows       []*int64
+}
+
+// Table returns the mask of a table, or nil when no rule applies to any of its columns. A nil Masker masks nothing.
+func (m *Masker) Table(schema, table string, columns []string) *TableMask {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := schema + "." + table
+	mask := &TableMask{
+		salt:       m.rules.Salt,
+		transforms: make([]*Transform, len(columns)),
+		rows:       make([]*int64, len(columns)),
+	}
+
+	masked := false
+	for _, pattern := range m.rules.sortedPatterns() {
+		if ok, _ := path.Match(pattern, name); !ok {
+			continue
+		}
+
+		for i, column := range columns {
+			transform, ok := m.rules.Tables[pattern][column]
+			if !ok || mask.transforms[i] != nil {
+				continue
+			}
+
+			key := maskedColumn{pattern: pattern, table: name, column: column}
+			if m.columns[key] == nil {
+				m.columns[key] = new(int64)
+			}
+
+			mask.transforms[i] = &transform
+			mask.rows[i] = m.columns[key]
+			masked = true
+		}
+	}
+
+	if !masked {
+		return nil
+	}
+
+	return mask
+}
+
+// Masked reports whether the column at index i is masked
+func (t *TableMask) Masked(i int) bool {
+	return t != nil && t.transforms[i] != nil
+}
+
+// Apply masks the values of a row in place
+func (t *TableMask) Apply(values [][]byte) {
+	if t == nil {
+		return
+	}
+
+	for i, transform := range t.transforms {
+		if transform == nil {
+			continue
+		}
+
+		values[i] = transform.apply(values[i], t.salt)
+		atomic.AddInt64(t.rows[i], 1)
+	}
+}
+
+// WriteSummary writes the columns that were masked, and the rules that did not match any column copied. It returns
+// the number of rules that were not applied.
+func (m *Masker) WriteSummary(w io.Writer) (unmatched int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, _ = fmt.Fprintln(w, "Masked columns:")
+
+	for _, pattern := range m.rules.sortedPatterns() {
+		columns := make([]string, 0, len(m.rules.Tables[pattern]))
+		for column := range m.rules.Tables[pattern] {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+
+		for _, column := range columns {
+			var tables []string
+			for key := range m.columns {
+				if key.pattern == pattern && key.column == column {
+					tables = append(tables, key.table)
+				}
+			}
+			sort.Strings(tables)
+
+			transform := m.rules.Tables[pattern][column]
+			for _, table := range tables {
+				rows := atomic.LoadInt64(m.columns[maskedColumn{pattern: pattern, table: table, column: column}])
+				_, _ = fmt.Fprintf(w, "  %s.%s: %s (%d rows)\n", table, column, transform, rows)
+			}
+
+			if len(tables) == 0 {
+				unmatched++
+				_, _ = fmt.Fprintf(w, "  NOT FOUND %s.%s: no table copied has this column\n", pattern, column)
+			}
+		}
+	}
+
+	return unmatched
+}
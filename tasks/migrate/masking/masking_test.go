@@ -0,0 +1,148 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package masking_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/masking"
+)
+
+var _ = Describe("Masking", func() {
+	Context("Parse", func() {
+		It("reads every transform", func() {
+			rules, err := masking.Parse([]byte(`
+salt: some-salt
+tables:
+  sakila.customer:
+    email: fake_email
+    last_name: hash
+    phone: null
+    country: {fixed: XX}
+    notes: {truncate: 10}
+`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules).To(Equal(masking.Rules{
+				Salt: "some-salt",
+				Tables: map[string]map[string]masking.Transform{
+					"sakila.customer": {
+						"email":     {Kind: masking.FakeEmail},
+						"last_name": {Kind: masking.Hash},
+						"phone":     {Kind: masking.Null},
+						"country":   {Kind: masking.Fixed, Value: "XX"},
+						"notes":     {Kind: masking.Truncate, Length: 10},
+					},
+				},
+			}))
+		})
+
+		DescribeTable("rejects invalid rules",
+			func(rules, message string) {
+				_, err := masking.Parse([]byte(rules))
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("without tables", "salt: some-salt", "no tables are masked"),
+			Entry("with an unknown transform", "tables: {sakila.customer: {email: scramble}}", "invalid transform"),
+			Entry("with a fixed transform without a value", "tables: {sakila.customer: {email: fixed}}", "the fixed transform takes a value"),
+			Entry("with a negative length", "tables: {sakila.customer: {email: {truncate: -1}}}", "takes a number of characters"),
+			Entry("with a table without a schema", "tables: {customer: {email: hash}}", `invalid table pattern "customer"`),
+		)
+	})
+
+	Context("Masker", func() {
+		var masker *masking.Masker
+
+		BeforeEach(func() {
+			masker = masking.NewMasker(masking.Rules{
+				Salt: "some-salt",
+				Tables: map[string]map[string]masking.Transform{
+					"sakila.customer": {
+						"email":     {Kind: masking.FakeEmail},
+						"last_name": {Kind: masking.Hash},
+						"phone":     {Kind: masking.Null},
+						"country":   {Kind: masking.Fixed, Value: "XX"},
+						"notes":     {Kind: masking.Truncate, Length: 3},
+					},
+					"*.staff": {
+						"password": {Kind: masking.Null},
+					},
+				},
+			})
+		})
+
+		It("masks the values of the columns with rules", func() {
+			mask := masker.Table("sakila", "customer", []string{"id", "email", "last_name", "phone", "country", "notes"})
+			Expect(mask.Masked(0)).To(BeFalse())
+			Expect(mask.Masked(1)).To(BeTrue())
+
+			row := [][]byte{[]byte("1"), []byte("jane@example.org"), []byte("Doe"), []byte("555-0100"), []byte("FR"), []byte("Née à Paris")}
+			mask.Apply(row)
+
+			Expect(string(row[0])).To(Equal("1"))
+			Expect(string(row[1])).To(MatchRegexp(`^user-[0-9a-f]{16}@example\.com$`))
+			Expect(string(row[2])).To(MatchRegexp(`^[0-9a-f]{64}$`))
+			Expect(row[3]).To(BeNil())
+			Expect(string(row[4])).To(Equal("XX"))
+			Expect(string(row[5])).To(Equal("Née"))
+		})
+
+		It("masks equal values the same way, and leaves NULL values unchanged", func() {
+			mask := masker.Table("sakila", "customer", []string{"email", "last_name"})
+
+			first := [][]byte{[]byte("jane@example.org"), nil}
+			second := [][]byte{[]byte("jane@example.org"), nil}
+			mask.Apply(first)
+			mask.Apply(second)
+
+			Expect(first[0]).To(Equal(second[0]))
+			Expect(first[1]).To(BeNil())
+		})
+
+		It("matches tables by glob pattern", func() {
+			Expect(masker.Table("sakila", "staff", []string{"password"})).NotTo(BeNil())
+			Expect(masker.Table("sakila", "film", []string{"title"})).To(BeNil())
+		})
+
+		It("does not mask anything when there are no rules", func() {
+			var noMasker *masking.Masker
+			mask := noMasker.Table("sakila", "customer", []string{"email"})
+			Expect(mask).To(BeNil())
+			Expect(mask.Masked(0)).To(BeFalse())
+
+			row := [][]byte{[]byte("jane@example.org")}
+			mask.Apply(row)
+			Expect(string(row[0])).To(Equal("jane@example.org"))
+		})
+
+		It("summarizes the masked columns and the rules that did not apply", func() {
+			mask := masker.Table("sakila", "customer", []string{"email", "phone"})
+			mask.Apply([][]byte{[]byte("jane@example.org"), []byte("555-0100")})
+			mask.Apply([][]byte{[]byte("john@example.org"), nil})
+			masker.Table("sakila", "staff", []string{"password"})
+
+			summary := &bytes.Buffer{}
+			Expect(masker.WriteSummary(summary)).To(Equal(3))
+			Expect(summary.String()).To(Equal(`Masked columns:
+  sakila.staff.password: null (0 rows)
+  NOT FOUND sakila.customer.country: no table copied has this column
+  sakila.customer.email: fake_email (2 rows)
+  NOT FOUND sakila.customer.last_name: no table copied has this column
+  NOT FOUND sakila.customer.notes: no table copied has this column
+  sakila.customer.phone: null (2 rows)
+`))
+		})
+	})
+})
@@ -0,0 +1,189 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package masking
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RulesEnv is the environment variable the migration app reads masking rules from when no rules file is given
+const RulesEnv = "MASKING_RULES"
+
+// Transforms of a column value
+const (
+	Null      = "null"
+	Hash      = "hash"
+	Fixed     = "fixed"
+	FakeEmail = "fake_email"
+	Truncate  = "truncate"
+)
+
+// Rules declares how the columns of the tables matching each "<schema>.<table>" glob pattern are masked
+//
+//	salt: some-secret
+//	tables:
+//	  sakila.customer:
+//	    email: fake_email
+//	    last_name: hash
+//	    phone: null
+//	    country: {fixed: XX}
+//	    notes: {truncate: 10}
+type Rules struct {
+	// Salt is mixed into hashes and fake emails, so that they can not be reversed by hashing known values
+	Salt   string                          `yaml:"salt"`
+	Tables map[string]map[string]Transform `yaml:"tables"`
+}
+
+type Transform struct {
+	Kind string
+	// Value is the value of a fixed transform
+	Value string
+	// Length is the number of characters a truncate transform keeps
+	Length int
+}
+
+func (t Transform) String() string {
+	switch t.Kind {
+	case Fixed:
+		return fmt.Sprintf("fixed value %q", t.Value)
+	case Truncate:
+		return fmt.Sprintf("truncate to %d characters", t.Length)
+	}
+
+	return t.Kind
+}
+
+// UnmarshalYAML reads a transform written as its name, or as a mapping of its name to its parameter. yaml does not
+// call it for a null value, which leaves the zero Transform that Parse turns into a Null transform.
+func (t *Transform) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		switch node.Value {
+		case Null, Hash, FakeEmail:
+			t.Kind = node.Value
+			return nil
+		case Fixed, Truncate:
+			return fmt.Errorf("line %d: the %s transform takes a value, as {%s: <value>}", node.Line, node.Value, node.Value)
+		}
+	case yaml.MappingNode:
+		if len(node.Content) != 2 {
+			break
+		}
+
+		key, value := node.Content[0].Value, node.Content[1]
+		switch key {
+		case Fixed:
+			t.Kind, t.Value = Fixed, value.Value
+			return nil
+		case Truncate:
+			if err := value.Decode(&t.Length); err != nil || t.Length < 0 {
+				return fmt.Errorf("line %d: the truncate transform takes a number of characters", node.Line)
+			}
+			t.Kind = Truncate
+			return nil
+		}
+	}
+
+	return fmt.Errorf("line %d: invalid transform, expected %s, %s, %s, {%s: <value>} or {%s: <length>}",
+		node.Line, Null, Hash, FakeEmail, Fixed, Truncate)
+}
+
+// Load reads masking rules from a YAML file
+func Load(file string) (Rules, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read masking rules: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse reads and validates masking rules
+func Parse(data []byte) (Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return Rules{}, fmt.Errorf("invalid masking rules: %w", err)
+	}
+
+	if len(rules.Tables) == 0 {
+		return Rules{}, errors.New("invalid masking rules: no tables are masked")
+	}
+
+	for pattern, columns := range rules.Tables {
+		if _, err := path.Match(pattern, ""); err != nil || !strings.Contains(pattern, ".") {
+			return Rules{}, fmt.Errorf("invalid masking rules: invalid table pattern %q, expected <schema>.<table>", pattern)
+		}
+
+		for column, transform := range columns {
+			if transform.Kind == "" {
+				columns[column] = Transform{Kind: Null}
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// apply returns the masked value. NULL values are left unchanged.
+func (t Transform) apply(value []byte, salt string) []byte {
+	if value == nil {
+		return nil
+	}
+
+	switch t.Kind {
+	case Null:
+		return nil
+	case Hash:
+		return []byte(digest(value, salt))
+	case Fixed:
+		return []byte(t.Value)
+	case FakeEmail:
+		return []byte("user-" + digest(value, salt)[:16] + "@example.com")
+	case Truncate:
+		characters := 0
+		for i := range string(value) {
+			if characters == t.Length {
+				return value[:i]
+			}
+			characters++
+		}
+	}
+
+	return value
+}
+
+func digest(value []byte, salt string) string {
+	sum := sha256.Sum256(append([]byte(salt), value...))
+	return hex.EncodeToString(sum[:])
+}
+
+// sortedPatterns returns the table patterns in a stable order, so that a table matched by several patterns is
+// always masked the same way
+func (r Rules) sortedPatterns() []string {
+	patterns := make([]string, 0, len(r.Tables))
+	for pattern := range r.Tables {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	return patterns
+}
@@ -0,0 +1,330 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+)
+
+// BinlogPosition is a position in the binary log of the source
+type BinlogPosition struct {
+	File     string
+	Position int64
+}
+
+func (p BinlogPosition) String() string {
+	return fmt.Sprintf("%s:%d", p.File, p.Position)
+}
+
+func ParseBinlogPosition(s string) (BinlogPosition, error) {
+	i := strings.LastIndex(s, ":")
+	if i <= 0 {
+		return BinlogPosition{}, fmt.Errorf("invalid binlog position %q, expected <file>:<position>", s)
+	}
+
+	position, err := strconv.ParseInt(s[i+1:], 10, 64)
+	if err != nil || position < 0 {
+		return BinlogPosition{}, fmt.Errorf("invalid binlog position %q, expected <file>:<position>", s)
+	}
+
+	return BinlogPosition{File: s[:i], Position: position}, nil
+}
+
+// BinaryLog is a binary log file of the source, as listed by SHOW BINARY LOGS
+type BinaryLog struct {
+	Name string
+	Size int64
+}
+
+// CheckBinlogSettings verifies that the changes made to the source are recorded in a way they can be applied to
+// the recipient, and that the user of the recipient is allowed to apply them
+func CheckBinlogSettings(db, destDB *sql.DB) error {
+	var (
+		logBin       bool
+		binlogFormat string
+	)
+
+	if err := db.QueryRow(`SELECT @@global.log_bin, @@global.binlog_format`).Scan(&logBin, &binlogFormat); err != nil {
+		return fmt.Errorf("failed to look up the binary log settings: %w", err)
+	}
+
+	if !logBin {
+		return errors.New("binary logging is disabled")
+	}
+
+	if binlogFormat != "ROW" {
+		return fmt.Errorf("binlog_format is %s, but only ROW is supported", binlogFormat)
+	}
+
+	return checkApplyPrivileges(destDB)
+}
+
+// applyPrivileges lists the global privileges of which the recipient user needs one of each, since mysqlbinlog
+// decodes row events as BINLOG statements and sets session variables such as pseudo_thread_id
+var applyPrivileges = [][]string{
+	{"SUPER", "BINLOG_ADMIN", "REPLICATION_APPLIER"},
+	{"SUPER", "SESSION_VARIABLES_ADMIN", "SYSTEM_VARIABLES_ADMIN"},
+}
+
+func checkApplyPrivileges(db *sql.DB) error {
+	rows, err := db.Query(`SHOW GRANTS`)
+	if err != nil {
+		return fmt.Errorf("failed to look up the privileges of the recipient user: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	granted := map[string]bool{}
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return fmt.Errorf("failed to scan the privileges of the recipient user: %w", err)
+		}
+
+		privileges, ok := globalPrivileges(grant)
+		if !ok {
+			continue
+		}
+		for _, privilege := range privileges {
+			granted[privilege] = true
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to look up the privileges of the recipient user: %w", err)
+	}
+
+	if granted["ALL PRIVILEGES"] {
+		return nil
+	}
+
+	for _, anyOf := range applyPrivileges {
+		if !slices.ContainsFunc(anyOf, func(privilege string) bool { return granted[privilege] }) {
+			return fmt.Errorf("the recipient user can not apply binlog events, it needs one of the %s privileges", strings.Join(anyOf, ", "))
+		}
+	}
+
+	return nil
+}
+
+// globalPrivileges returns the privileges of a line of SHOW GRANTS granting them on *.*
+func globalPrivileges(grant string) ([]string, bool) {
+	privileges, rest, ok := strings.Cut(strings.TrimPrefix(grant, "GRANT "), " ON ")
+	if !ok || !strings.HasPrefix(rest, "*.* ") {
+		return nil, false
+	}
+
+	var result []string
+	for _, privilege := range strings.Split(privileges, ",") {
+		result = append(result, strings.ToUpper(strings.TrimSpace(privilege)))
+	}
+
+	return result, true
+}
+
+// CurrentBinlogPosition returns the position up to which changes have been written to the binary log
+func CurrentBinlogPosition(db *sql.DB) (BinlogPosition, error) {
+	rows, err := db.Query(`SHOW MASTER STATUS`)
+	if err != nil {
+		return BinlogPosition{}, fmt.Errorf("failed to look up the binlog position: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return BinlogPosition{}, fmt.Errorf("failed to look up the binlog position: %w", err)
+		}
+		return BinlogPosition{}, errors.New("failed to look up the binlog position: binary logging is disabled")
+	}
+
+	var position BinlogPosition
+	if err := scanLeadingColumns(rows, &position.File, &position.Position); err != nil {
+		return BinlogPosition{}, fmt.Errorf("failed to scan the binlog position: %w", err)
+	}
+
+	return position, nil
+}
+
+// BinaryLogs lists the binary log files of the source, oldest first
+func BinaryLogs(db *sql.DB) ([]BinaryLog, error) {
+	rows, err := db.Query(`SHOW BINARY LOGS`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the binary logs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var logs []BinaryLog
+	for rows.Next() {
+		var binaryLog BinaryLog
+		if err := scanLeadingColumns(rows, &binaryLog.Name, &binaryLog.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan the list of binary logs: %w", err)
+		}
+
+		logs = append(logs, binaryLog)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list the binary logs: %w", err)
+	}
+
+	return logs, nil
+}
+
+// scanLeadingColumns scans the first columns of a row, since the number of columns returned by SHOW statements
+// depends on the server version
+func scanLeadingColumns(rows *sql.Rows, dest ...interface{}) error {
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if len(columns) < len(dest) {
+		return fmt.Errorf("expected at least %d columns, got %d", len(dest), len(columns))
+	}
+
+	values := make([]interface{}, len(columns))
+	for i := range values {
+		if i < len(dest) {
+			values[i] = dest[i]
+		} else {
+			values[i] = new(sql.RawBytes)
+		}
+	}
+
+	return rows.Scan(values...)
+}
+
+// BinlogRange returns the binary log files containing the changes between two positions, and the number of bytes
+// of binlog between them
+func BinlogRange(logs []BinaryLog, from, to BinlogPosition) (files []string, lagBytes int64, err error) {
+	if from == to {
+		return nil, 0, nil
+	}
+
+	inRange := false
+	for _, binaryLog := range logs {
+		if binaryLog.Name == from.File {
+			inRange = true
+		}
+
+		if !inRange {
+			continue
+		}
+
+		files = append(files, binaryLog.Name)
+
+		switch {
+		case binaryLog.Name == from.File && binaryLog.Name == to.File:
+			return files, to.Position - from.Position, nil
+		case binaryLog.Name == from.File:
+			lagBytes += binaryLog.Size - from.Position
+		case binaryLog.Name == to.File:
+			return files, lagBytes + to.Position, nil
+		default:
+			lagBytes += binaryLog.Size
+		}
+	}
+
+	if !inRange {
+		return nil, 0, fmt.Errorf("binary log %s is no longer available on the source", from.File)
+	}
+
+	return nil, 0, fmt.Errorf("binary log %s is not available on the source", to.File)
+}
+
+// CatchUp applies the changes made to the given schemas of the source since the given position in rounds, until a
+// round applied at most maxLagBytes of binlog. It returns the position the recipient caught up to.
+func CatchUp(sourceDB *sql.DB, sourceCredentials, destCredentials Credentials, from BinlogPosition, schemas []string, recipientSchema func(string) string, maxLagBytes int64, tracker *progress.Tracker) (BinlogPosition, error) {
+	for {
+		to, err := CurrentBinlogPosition(sourceDB)
+		if err != nil {
+			return from, err
+		}
+
+		logs, err := BinaryLogs(sourceDB)
+		if err != nil {
+			return from, err
+		}
+
+		files, lagBytes, err := BinlogRange(logs, from, to)
+		if err != nil {
+			return from, err
+		}
+
+		tracker.CatchingUp(from.String(), lagBytes)
+
+		if lagBytes > 0 {
+			// mysqlbinlog only filters a single schema, so the changes of every schema are applied separately. The
+			// changes of other schemas, including the accounts in the mysql schema, are never applied.
+			for _, schema := range schemas {
+				mysqlbinlog := MySQLBinlogCmd(sourceCredentials, files, from, to, schema, recipientSchema(schema))
+				if err := ApplyBinlog(mysqlbinlog, MySQLCmd(destCredentials)); err != nil {
+					return from, fmt.Errorf("failed to apply the changes to %s from %s to %s: %w", schema, from, to, err)
+				}
+			}
+
+			log.Printf("Applied %d bytes of binlog up to %s", lagBytes, to)
+			from = to
+		}
+
+		if lagBytes <= maxLagBytes {
+			tracker.CatchingUp(from.String(), 0)
+			return from, nil
+		}
+	}
+}
+
+var dumpBinlogPositionPattern = regexp.MustCompile(`^-- CHANGE (?:MASTER|REPLICATION SOURCE) TO (?:MASTER|SOURCE)_LOG_FILE='([^']+)', (?:MASTER|SOURCE)_LOG_POS=(\d+);`)
+
+// dumpBinlogPosition records the binlog position mysqldump --master-data=2 writes as a comment at the start of
+// a dump
+type dumpBinlogPosition struct {
+	line     []byte
+	position BinlogPosition
+	found    bool
+}
+
+func (d *dumpBinlogPosition) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if d.found {
+			break
+		}
+
+		if b != '\n' {
+			if len(d.line) < maxLinePrefix {
+				d.line = append(d.line, b)
+			}
+			continue
+		}
+
+		if match := dumpBinlogPositionPattern.FindSubmatch(d.line); match != nil {
+			position, _ := strconv.ParseInt(string(match[2]), 10, 64)
+			d.position = BinlogPosition{File: string(match[1]), Position: position}
+			d.found = true
+		}
+		d.line = d.line[:0]
+	}
+
+	return len(p), nil
+}
+
+// maxLinePrefix bounds how much of each line of a dump is kept to recognize comments
+const maxLinePrefix = 512
@@ -0,0 +1,255 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Online migration", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("ParseBinlogPosition", func() {
+		It("parses a <file>:<position> pair", func() {
+			Expect(ParseBinlogPosition("mysql-bin.000002:154")).
+				To(Equal(BinlogPosition{File: "mysql-bin.000002", Position: 154}))
+			Expect(BinlogPosition{File: "mysql-bin.000002", Position: 154}.String()).
+				To(Equal("mysql-bin.000002:154"))
+		})
+
+		DescribeTable("rejects invalid positions",
+			func(position string) {
+				_, err := ParseBinlogPosition(position)
+				Expect(err).To(MatchError(`invalid binlog position "` + position + `", expected <file>:<position>`))
+			},
+			Entry("without a position", "mysql-bin.000002"),
+			Entry("without a file", ":154"),
+			Entry("with a position that is not a number", "mysql-bin.000002:start"),
+			Entry("with a negative position", "mysql-bin.000002:-1"),
+		)
+	})
+
+	Context("CheckBinlogSettings", func() {
+		const settingsQuery = `SELECT @@global.log_bin, @@global.binlog_format`
+
+		var (
+			destDB   *sql.DB
+			destMock sqlmock.Sqlmock
+		)
+
+		BeforeEach(func() {
+			var err error
+			destDB, destMock, err = sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(destMock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		expectGrants := func(grants ...string) {
+			rows := sqlmock.NewRows([]string{"Grants for some-user@%"})
+			for _, grant := range grants {
+				rows.AddRow(grant)
+			}
+			destMock.ExpectQuery(`SHOW GRANTS`).WillReturnRows(rows)
+		}
+
+		It("accepts row based binary logging applied by a user allowed to", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(settingsQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"log_bin", "binlog_format"}).AddRow(1, "ROW"))
+			expectGrants(
+				"GRANT USAGE ON *.* TO `some-user`@`%`",
+				"GRANT REPLICATION_APPLIER,SESSION_VARIABLES_ADMIN ON *.* TO `some-user`@`%`",
+				"GRANT ALL PRIVILEGES ON `service_instance_db`.* TO `some-user`@`%`",
+			)
+
+			Expect(CheckBinlogSettings(db, destDB)).To(Succeed())
+		})
+
+		It("accepts a user with every privilege", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(settingsQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"log_bin", "binlog_format"}).AddRow(1, "ROW"))
+			expectGrants("GRANT ALL PRIVILEGES ON *.* TO 'root'@'%' WITH GRANT OPTION")
+
+			Expect(CheckBinlogSettings(db, destDB)).To(Succeed())
+		})
+
+		It("rejects a recipient user that can not apply binlog events", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(settingsQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"log_bin", "binlog_format"}).AddRow(1, "ROW"))
+			expectGrants(
+				"GRANT USAGE ON *.* TO `some-user`@`%`",
+				"GRANT ALL PRIVILEGES ON `service_instance_db`.* TO `some-user`@`%`",
+			)
+
+			Expect(CheckBinlogSettings(db, destDB)).To(MatchError("the recipient user can not apply binlog events, it needs one of the SUPER, BINLOG_ADMIN, REPLICATION_APPLIER privileges"))
+		})
+
+		It("rejects a recipient user that can not set the session variables of binlog events", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(settingsQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"log_bin", "binlog_format"}).AddRow(1, "ROW"))
+			expectGrants("GRANT BINLOG_ADMIN ON *.* TO `some-user`@`%`")
+
+			Expect(CheckBinlogSettings(db, destDB)).To(MatchError(ContainSubstring("SESSION_VARIABLES_ADMIN")))
+		})
+
+		It("rejects a source without binary logging", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(settingsQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"log_bin", "binlog_format"}).AddRow(0, "ROW"))
+
+			Expect(CheckBinlogSettings(db, destDB)).To(MatchError("binary logging is disabled"))
+		})
+
+		It("rejects statement based binary logging", func() {
+			mock.ExpectQuery(regexp.QuoteMeta(settingsQuery)).
+				WillReturnRows(sqlmock.NewRows([]string{"log_bin", "binlog_format"}).AddRow(1, "MIXED"))
+
+			Expect(CheckBinlogSettings(db, destDB)).To(MatchError("binlog_format is MIXED, but only ROW is supported"))
+		})
+	})
+
+	Context("CurrentBinlogPosition", func() {
+		It("returns the position of the last change written to the binary log", func() {
+			mock.ExpectQuery(`SHOW MASTER STATUS`).
+				WillReturnRows(sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB", "Executed_Gtid_Set"}).
+					AddRow("mysql-bin.000003", 4096, "", "", "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5"))
+
+			Expect(CurrentBinlogPosition(db)).To(Equal(BinlogPosition{File: "mysql-bin.000003", Position: 4096}))
+		})
+
+		It("returns an error when binary logging is disabled", func() {
+			mock.ExpectQuery(`SHOW MASTER STATUS`).
+				WillReturnRows(sqlmock.NewRows([]string{"File", "Position", "Binlog_Do_DB", "Binlog_Ignore_DB"}))
+
+			_, err := CurrentBinlogPosition(db)
+			Expect(err).To(MatchError("failed to look up the binlog position: binary logging is disabled"))
+		})
+
+		It("returns an error when the position can not be looked up", func() {
+			mock.ExpectQuery(`SHOW MASTER STATUS`).WillReturnError(errors.New("access denied"))
+
+			_, err := CurrentBinlogPosition(db)
+			Expect(err).To(MatchError("failed to look up the binlog position: access denied"))
+		})
+	})
+
+	Context("BinaryLogs", func() {
+		It("lists the binary log files", func() {
+			mock.ExpectQuery(`SHOW BINARY LOGS`).
+				WillReturnRows(sqlmock.NewRows([]string{"Log_name", "File_size", "Encrypted"}).
+					AddRow("mysql-bin.000001", 1024, "No").
+					AddRow("mysql-bin.000002", 512, "No"))
+
+			Expect(BinaryLogs(db)).To(Equal([]BinaryLog{
+				{Name: "mysql-bin.000001", Size: 1024},
+				{Name: "mysql-bin.000002", Size: 512},
+			}))
+		})
+
+		It("returns an error when the binary logs can not be listed", func() {
+			mock.ExpectQuery(`SHOW BINARY LOGS`).WillReturnError(errors.New("access denied"))
+
+			_, err := BinaryLogs(db)
+			Expect(err).To(MatchError("failed to list the binary logs: access denied"))
+		})
+	})
+
+	Context("BinlogRange", func() {
+		logs := []BinaryLog{
+			{Name: "mysql-bin.000001", Size: 1000},
+			{Name: "mysql-bin.000002", Size: 2000},
+			{Name: "mysql-bin.000003", Size: 3000},
+		}
+
+		It("returns nothing when there are no changes", func() {
+			position := BinlogPosition{File: "mysql-bin.000002", Position: 154}
+
+			files, lagBytes, err := BinlogRange(logs, position, position)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(BeEmpty())
+			Expect(lagBytes).To(BeZero())
+		})
+
+		It("measures the changes within a single file", func() {
+			files, lagBytes, err := BinlogRange(logs,
+				BinlogPosition{File: "mysql-bin.000002", Position: 154},
+				BinlogPosition{File: "mysql-bin.000002", Position: 1154},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal([]string{"mysql-bin.000002"}))
+			Expect(lagBytes).To(BeEquivalentTo(1000))
+		})
+
+		It("measures the changes across several files", func() {
+			files, lagBytes, err := BinlogRange(logs,
+				BinlogPosition{File: "mysql-bin.000001", Position: 900},
+				BinlogPosition{File: "mysql-bin.000003", Position: 50},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(Equal([]string{"mysql-bin.000001", "mysql-bin.000002", "mysql-bin.000003"}))
+			Expect(lagBytes).To(BeEquivalentTo(100 + 2000 + 50))
+		})
+
+		It("returns an error when the start position was purged", func() {
+			_, _, err := BinlogRange(logs[1:],
+				BinlogPosition{File: "mysql-bin.000001", Position: 900},
+				BinlogPosition{File: "mysql-bin.000003", Position: 50},
+			)
+			Expect(err).To(MatchError("binary log mysql-bin.000001 is no longer available on the source"))
+		})
+	})
+
+	Context("dumpBinlogPosition", func() {
+		It("records the binlog position written by mysqldump --master-data=2", func() {
+			var position dumpBinlogPosition
+			_, err := io.WriteString(&position, "-- MySQL dump 10.13\n--\n-- Position to start replication or point-in-time recovery from\n--\n\n")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = io.WriteString(&position, "-- CHANGE MASTER TO MASTER_LOG_FILE='mysql-bin.000003', MAS")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = io.WriteString(&position, "TER_LOG_POS=154;\n\nCREATE TABLE `t` (\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(position.found).To(BeTrue())
+			Expect(position.position).To(Equal(BinlogPosition{File: "mysql-bin.000003", Position: 154}))
+		})
+
+		It("does not find a position in a dump without one", func() {
+			var position dumpBinlogPosition
+			_, err := io.WriteString(&position, "-- MySQL dump 10.13\nCREATE TABLE `t` (\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(position.found).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,136 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+)
+
+// LockTablesForRead blocks writes to the given tables until the returned function is called, so that tables
+// copied separately by parallel workers are consistent with each other
+func LockTablesForRead(ctx context.Context, db *sql.DB, tables []discovery.Table) (unlock func() error, err error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a connection: %w", err)
+	}
+
+	if len(tables) > 0 {
+		var locks []string
+		for _, t := range tables {
+			locks = append(locks, discovery.QuoteIdentifier(t.Schema)+"."+discovery.QuoteIdentifier(t.Name)+" READ")
+		}
+
+		if _, err := conn.ExecContext(ctx, "LOCK TABLES "+strings.Join(locks, ", ")); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+
+	return func() error {
+		defer func() { _ = conn.Close() }()
+
+		// Losing the connection also releases the locks, in which case writes may have slipped in
+		if _, err := conn.ExecContext(context.Background(), "UNLOCK TABLES"); err != nil {
+			return fmt.Errorf("lost the table locks, the copied tables may not be consistent: %w", err)
+		}
+
+		return nil
+	}, nil
+}
+
+// CopyTablesInParallel calls copyTable for every table using the given number of workers. Tables are handed out
+// in order, so passing the largest first keeps workers busy until the end. No new table is started once one failed.
+func CopyTablesInParallel(tables []discovery.Table, workers int, copyTable func(discovery.Table) error) error {
+	var (
+		mu     sync.Mutex
+		errs   error
+		failed bool
+		wg     sync.WaitGroup
+	)
+
+	queue := make(chan discovery.Table)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for table := range queue {
+				mu.Lock()
+				skip := failed
+				mu.Unlock()
+				if skip {
+					continue
+				}
+
+				if err := copyTable(table); err != nil {
+					mu.Lock()
+					errs = multierror.Append(errs, fmt.Errorf("failed to copy %s: %w", table, err))
+					failed = true
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, table := range tables {
+		queue <- table
+	}
+	close(queue)
+	wg.Wait()
+
+	return errs
+}
+
+// copyDataInParallel copies the given tables using several workers. When recordPosition is set, it returns the
+// binlog position the copy is consistent with.
+func copyDataInParallel(engine CopyEngine, sourceSchemas []string, tables []discovery.Table, workers int, tracker *progress.Tracker, recordPosition bool) (BinlogPosition, error) {
+	// Tables and views are created up front, so that workers only have to load rows
+	if _, err := engine.CopySchemas(sourceSchemas, true, false, nil); err != nil {
+		return BinlogPosition{}, fmt.Errorf("failed to copy the schema: %w", err)
+	}
+
+	position, release, err := engine.ShareSnapshot(tables, workers, recordPosition)
+	if err != nil {
+		return BinlogPosition{}, err
+	}
+
+	log.Printf("Copying %d tables using %d workers", len(tables), workers)
+
+	copyErr := CopyTablesInParallel(tables, workers, func(t discovery.Table) error {
+		stream := tracker.Stream(t.Schema)
+		defer func() { _ = stream.Close() }()
+
+		if err := engine.CopyTableRows(t, stream); err != nil {
+			return err
+		}
+
+		log.Printf("Copied %s", t)
+		return nil
+	})
+
+	if err := release(); err != nil {
+		copyErr = multierror.Append(copyErr, err)
+	}
+
+	return position, copyErr
+}
@@ -0,0 +1,130 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+var _ = Describe("Parallel copy", func() {
+	var tables []discovery.Table
+
+	BeforeEach(func() {
+		tables = []discovery.Table{
+			{Schema: "foo", Name: "large", SizeBytes: 4096},
+			{Schema: "bar", Name: "medium", SizeBytes: 1024},
+			{Schema: "foo", Name: "small", SizeBytes: 16},
+		}
+	})
+
+	Context("LockTablesForRead", func() {
+		It("holds a read lock on every table until unlocked", func() {
+			db, mock, err := sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+
+			mock.ExpectExec(regexp.QuoteMeta("LOCK TABLES `foo`.`large` READ, `bar`.`medium` READ, `foo`.`small` READ")).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("UNLOCK TABLES").WillReturnResult(sqlmock.NewResult(0, 0))
+
+			unlock, err := LockTablesForRead(context.Background(), db, tables)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unlock()).To(Succeed())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
+
+		It("returns an error when the tables can not be locked", func() {
+			db, mock, err := sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+
+			mock.ExpectExec("LOCK TABLES").WillReturnError(errors.New("access denied"))
+
+			_, err = LockTablesForRead(context.Background(), db, tables)
+			Expect(err).To(MatchError("access denied"))
+		})
+
+		It("reports when the locks were lost before unlocking", func() {
+			db, mock, err := sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+
+			mock.ExpectExec("LOCK TABLES").WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec("UNLOCK TABLES").WillReturnError(errors.New("connection reset"))
+
+			unlock, err := LockTablesForRead(context.Background(), db, tables)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unlock()).To(MatchError("lost the table locks, the copied tables may not be consistent: connection reset"))
+		})
+	})
+
+	Context("CopyTablesInParallel", func() {
+		It("copies every table using up to the given number of workers", func() {
+			var (
+				mu      sync.Mutex
+				copied  []string
+				running int32
+				maxSeen int32
+				release = make(chan struct{})
+			)
+
+			go func() {
+				defer GinkgoRecover()
+				Eventually(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(2)))
+				close(release)
+			}()
+
+			err := CopyTablesInParallel(tables, 2, func(t discovery.Table) error {
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					seen := atomic.LoadInt32(&maxSeen)
+					if n <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, n) {
+						break
+					}
+				}
+				<-release
+
+				mu.Lock()
+				defer mu.Unlock()
+				copied = append(copied, t.String())
+				return nil
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(copied).To(ConsistOf("foo.large", "bar.medium", "foo.small"))
+			Expect(maxSeen).To(Equal(int32(2)))
+		})
+
+		It("stops handing out tables after a failure and reports it", func() {
+			var copied []string
+			err := CopyTablesInParallel(tables, 1, func(t discovery.Table) error {
+				copied = append(copied, t.String())
+				if t.Name == "large" {
+					return errors.New("mysql command failed: exit status 1")
+				}
+				return nil
+			})
+
+			Expect(err).To(MatchError(ContainSubstring("failed to copy foo.large: mysql command failed: exit status 1")))
+			Expect(copied).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,390 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// Prefix marks the progress lines written by the migration task, so that they can be told apart from its other output
+const Prefix = "PROGRESS "
+
+// Update is a snapshot of the progress of a migration task, written as JSON after Prefix
+type Update struct {
+	// Tables lists the tables currently being copied as <schema>.<table>
+	Tables       []string `json:"tables,omitempty"`
+	TablesCopied int      `json:"tables_copied"`
+	TablesTotal  int      `json:"tables_total"`
+	Bytes        int64    `json:"bytes"`
+	Rows         int64    `json:"rows"`
+	// EstimatedRows is based on the table statistics of the source, which InnoDB only approximates
+	EstimatedRows  int64 `json:"estimated_rows"`
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+	// ETASeconds is zero when the remaining time can not be estimated
+	ETASeconds int64 `json:"eta_seconds,omitempty"`
+	// BinlogPosition is the position of the source binlog the recipient caught up to in online migrations
+	BinlogPosition string `json:"binlog_position,omitempty"`
+	// LagBytes is the amount of binlog still to be applied to the recipient
+	LagBytes int64 `json:"lag_bytes,omitempty"`
+	Done     bool  `json:"done,omitempty"`
+	// Schemas, CopiedTables and SkippedViews are only reported once the task is done
+	Schemas      []string `json:"schemas,omitempty"`
+	CopiedTables []string `json:"copied_tables,omitempty"`
+	SkippedViews []string `json:"skipped_views,omitempty"`
+	// RecipientFingerprint is the fingerprint of the recipient's data once the task is done, to tell whether it was
+	// written to since
+	RecipientFingerprint string `json:"recipient_fingerprint,omitempty"`
+}
+
+// Parse extracts the update from a line containing a progress line, for instance one returned by cf logs
+func Parse(line string) (Update, bool) {
+	i := strings.Index(line, Prefix)
+	if i < 0 {
+		return Update{}, false
+	}
+
+	var update Update
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line[i+len(Prefix):])), &update); err != nil {
+		return Update{}, false
+	}
+
+	return update, true
+}
+
+// Tracker sums up the progress of every Stream copying data, and reports it as progress lines
+type Tracker struct {
+	out   io.Writer
+	clock func() time.Time
+	start time.Time
+
+	mu            sync.Mutex
+	active        map[string]bool
+	tablesCopied  int
+	tablesTotal   int
+	bytes         int64
+	rows          int64
+	estimatedRows int64
+	position      string
+	lagBytes      int64
+	done          bool
+	schemas       []string
+	copied        []string
+	skippedViews  []string
+	fingerprint   string
+}
+
+func NewTracker(out io.Writer, tables []discovery.Table, clock func() time.Time) *Tracker {
+	t := &Tracker{
+		out:         out,
+		clock:       clock,
+		start:       clock(),
+		active:      map[string]bool{},
+		tablesTotal: len(tables),
+	}
+
+	for _, table := range tables {
+		t.estimatedRows += table.Rows
+	}
+
+	return t
+}
+
+func (t *Tracker) Update() Update {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elapsed := t.clock().Sub(t.start)
+
+	update := Update{
+		TablesCopied:   t.tablesCopied,
+		TablesTotal:    t.tablesTotal,
+		Bytes:          t.bytes,
+		Rows:           t.rows,
+		EstimatedRows:  t.estimatedRows,
+		ElapsedSeconds: int64(elapsed / time.Second),
+		BinlogPosition: t.position,
+		LagBytes:       t.lagBytes,
+		Done:           t.done,
+	}
+
+	if t.done {
+		update.Schemas = t.schemas
+		update.CopiedTables = t.copied
+		update.SkippedViews = t.skippedViews
+		update.RecipientFingerprint = t.fingerprint
+	}
+
+	for table := range t.active {
+		update.Tables = append(update.Tables, table)
+	}
+	sort.Strings(update.Tables)
+
+	if !t.done && t.rows > 0 && t.rows < t.estimatedRows {
+		remaining := elapsed.Seconds() * float64(t.estimatedRows-t.rows) / float64(t.rows)
+		update.ETASeconds = int64(remaining) + 1
+	}
+
+	return update
+}
+
+// Report writes the current progress as a single progress line
+func (t *Tracker) Report() {
+	line, err := json.Marshal(t.Update())
+	if err != nil {
+		return
+	}
+
+	_, _ = fmt.Fprintf(t.out, "%s%s\n", Prefix, line)
+}
+
+// ReportEvery reports the progress at the given interval until the returned function is called, which reports it
+// one last time
+func (t *Tracker) ReportEvery(interval time.Duration) (stop func()) {
+	quit := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+				t.Report()
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-stopped
+		t.Report()
+	}
+}
+
+// CatchingUp records how far the recipient is behind the source while changes made since the copy are applied
+func (t *Tracker) CatchingUp(position string, lagBytes int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.position = position
+	t.lagBytes = lagBytes
+}
+
+// Migrating records the schemas being migrated, and the invalid views skipped, to report once the task is done
+func (t *Tracker) Migrating(schemas []string, skippedViews []discovery.View) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.schemas = schemas
+	t.skippedViews = nil
+	for _, view := range skippedViews {
+		t.skippedViews = append(t.skippedViews, view.String())
+	}
+}
+
+// RecipientFingerprint records the fingerprint of the recipient's data, to report once the task is done
+func (t *Tracker) RecipientFingerprint(fingerprint string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.fingerprint = fingerprint
+}
+
+// Finish reports that the migration task completed
+func (t *Tracker) Finish() {
+	t.mu.Lock()
+	t.done = true
+	t.mu.Unlock()
+
+	t.Report()
+}
+
+// Stream returns a writer to tee the output of mysqldump into. schema is the schema being dumped, unless the dump
+// names it in a "Current Database" comment.
+func (t *Tracker) Stream(schema string) *Stream {
+	return &Stream{tracker: t, schema: schema}
+}
+
+func (t *Tracker) startTable(table string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.active[table] = true
+}
+
+func (t *Tracker) finishTable(table string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.active, table)
+	t.tablesCopied++
+	t.copied = append(t.copied, table)
+}
+
+func (t *Tracker) add(bytes, rows int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.bytes += bytes
+	t.rows += rows
+}
+
+const (
+	insertPrefix          = "INSERT INTO "
+	valuesKeyword         = "VALUES "
+	currentDatabasePrefix = "-- Current Database: "
+	dumpingDataPrefix     = "-- Dumping data for table "
+
+	// Only the start of each line is kept, which is enough to recognize statements and comments
+	maxLinePrefix = 512
+)
+
+// Stream counts the bytes and rows of a mysqldump output written to it. Tables are recognized by the comments
+// mysqldump writes before their data, and rows by the value lists following VALUES in its INSERT statements.
+type Stream struct {
+	tracker *Tracker
+	schema  string
+	table   string
+
+	line     []byte
+	insert   bool
+	values   bool
+	matched  int
+	inString bool
+	inIdent  bool
+	escaped  bool
+}
+
+func (s *Stream) Write(p []byte) (int, error) {
+	var rows int64
+
+	for _, b := range p {
+		if b == '\n' {
+			s.endLine()
+			continue
+		}
+
+		if len(s.line) < maxLinePrefix {
+			s.line = append(s.line, b)
+			if len(s.line) == len(insertPrefix) && string(s.line) == insertPrefix {
+				s.insert = true
+				continue
+			}
+		}
+
+		if !s.insert {
+			continue
+		}
+
+		// mysqldump escapes quotes and newlines in strings with a backslash
+		switch {
+		case s.inString:
+			switch {
+			case s.escaped:
+				s.escaped = false
+			case b == '\\':
+				s.escaped = true
+			case b == '\'':
+				s.inString = false
+			}
+		case s.inIdent:
+			if b == '`' {
+				s.inIdent = false
+			}
+		case b == '\'':
+			s.inString = true
+		case b == '`':
+			s.inIdent = true
+		case !s.values:
+			// A column list may precede the values
+			s.matchValuesKeyword(b)
+		case b == '(':
+			rows++
+		}
+	}
+
+	s.tracker.add(int64(len(p)), rows)
+
+	return len(p), nil
+}
+
+// Close marks the table being dumped as copied
+func (s *Stream) Close() error {
+	s.finishTable()
+	return nil
+}
+
+func (s *Stream) endLine() {
+	line := string(s.line)
+
+	switch {
+	case strings.HasPrefix(line, currentDatabasePrefix):
+		s.schema = unquoteIdentifier(strings.TrimPrefix(line, currentDatabasePrefix))
+	case strings.HasPrefix(line, dumpingDataPrefix):
+		s.finishTable()
+		s.table = unquoteIdentifier(strings.TrimPrefix(line, dumpingDataPrefix))
+		s.tracker.startTable(s.tableName())
+	}
+
+	s.line = s.line[:0]
+	s.insert, s.values, s.inString, s.inIdent, s.escaped = false, false, false, false, false
+	s.matched = 0
+}
+
+func (s *Stream) matchValuesKeyword(b byte) {
+	switch {
+	case b == valuesKeyword[s.matched]:
+		s.matched++
+	case b == valuesKeyword[0]:
+		s.matched = 1
+	default:
+		s.matched = 0
+	}
+
+	s.values = s.matched == len(valuesKeyword)
+}
+
+func (s *Stream) finishTable() {
+	if s.table == "" {
+		return
+	}
+
+	s.tracker.finishTable(s.tableName())
+	s.table = ""
+}
+
+func (s *Stream) tableName() string {
+	return s.schema + "." + s.table
+}
+
+func unquoteIdentifier(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '`' && s[len(s)-1] == '`' {
+		s = strings.ReplaceAll(s[1:len(s)-1], "``", "`")
+	}
+
+	return s
+}
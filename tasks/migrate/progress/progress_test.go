@@ -0,0 +1,225 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package progress_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	. "github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/progress"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		out     *bytes.Buffer
+		now     time.Time
+		tracker *Tracker
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		tracker = NewTracker(out, []discovery.Table{
+			{Schema: "app", Name: "users", Rows: 3},
+			{Schema: "app", Name: "orders", Rows: 5},
+		}, func() time.Time { return now })
+	})
+
+	Context("Stream", func() {
+		It("counts the bytes and rows of a mysqldump output", func() {
+			dump := "-- MySQL dump\n" +
+				"--\n" +
+				"-- Dumping data for table `users`\n" +
+				"--\n" +
+				"INSERT INTO `users` VALUES (1,'a'),(2,'b (c),(d)'),(3,'it\\'s (e)');\n" +
+				"--\n" +
+				"-- Dumping data for table `orders`\n" +
+				"--\n" +
+				"INSERT INTO `orders` VALUES (1,'x\\\\'),(2,NULL);\n"
+
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, dump)
+			Expect(err).NotTo(HaveOccurred())
+
+			update := tracker.Update()
+			Expect(update.Bytes).To(BeEquivalentTo(len(dump)))
+			Expect(update.Rows).To(BeEquivalentTo(5))
+			Expect(update.Tables).To(Equal([]string{"app.orders"}))
+			Expect(update.TablesCopied).To(Equal(1))
+			Expect(update.TablesTotal).To(Equal(2))
+
+			Expect(stream.Close()).To(Succeed())
+
+			update = tracker.Update()
+			Expect(update.Tables).To(BeEmpty())
+			Expect(update.TablesCopied).To(Equal(2))
+		})
+
+		It("recognizes statements and comments split across writes", func() {
+			dump := "-- Current Database: `shop`\n" +
+				"-- Dumping data for table `a``b`\n" +
+				"INSERT INTO `a``b` VALUES (1),(2);\n"
+
+			stream := tracker.Stream("")
+			for _, b := range []byte(dump) {
+				_, err := stream.Write([]byte{b})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			update := tracker.Update()
+			Expect(update.Rows).To(BeEquivalentTo(2))
+			Expect(update.Tables).To(Equal([]string{"shop.a`b"}))
+		})
+
+		It("does not count column lists as rows", func() {
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, "INSERT INTO `users` (`id`,`VALUES (x)`) VALUES (1,'VALUES (y)'),(2,NULL);\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tracker.Update().Rows).To(BeEquivalentTo(2))
+		})
+
+		It("does not count rows outside of INSERT statements", func() {
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, "CREATE TABLE `t` (\n  `id` int(11) DEFAULT (1)\n);\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tracker.Update().Rows).To(BeZero())
+		})
+	})
+
+	Context("Update", func() {
+		It("estimates the remaining time from the rows copied so far", func() {
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, "INSERT INTO `users` VALUES (1),(2);\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(30 * time.Second)
+
+			update := tracker.Update()
+			Expect(update.EstimatedRows).To(BeEquivalentTo(8))
+			Expect(update.ElapsedSeconds).To(BeEquivalentTo(30))
+			Expect(update.ETASeconds).To(BeNumerically("~", 90, 1))
+		})
+
+		It("does not estimate the remaining time once more rows than estimated were copied", func() {
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, "INSERT INTO `users` VALUES (1),(2),(3),(4),(5),(6),(7),(8),(9);\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(time.Minute)
+
+			Expect(tracker.Update().ETASeconds).To(BeZero())
+		})
+	})
+
+	Context("Report", func() {
+		It("writes a progress line that can be parsed back", func() {
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, "-- Dumping data for table `users`\nINSERT INTO `users` VALUES (1);\n")
+			Expect(err).NotTo(HaveOccurred())
+
+			tracker.Report()
+
+			Expect(out.String()).To(HavePrefix(Prefix + "{"))
+			Expect(out.String()).To(HaveSuffix("}\n"))
+
+			update, ok := Parse("2024-01-01T00:00:05.00+0000 [APP/TASK/migrate/0] OUT " + strings.TrimSpace(out.String()))
+			Expect(ok).To(BeTrue())
+			Expect(update).To(Equal(tracker.Update()))
+		})
+	})
+
+	Context("CatchingUp", func() {
+		It("reports the binlog position and lag of the recipient", func() {
+			tracker.CatchingUp("mysql-bin.000002:154", 4096)
+
+			update := tracker.Update()
+			Expect(update.BinlogPosition).To(Equal("mysql-bin.000002:154"))
+			Expect(update.LagBytes).To(BeEquivalentTo(4096))
+		})
+	})
+
+	Context("Finish", func() {
+		It("reports that the task is done", func() {
+			tracker.Finish()
+
+			update, ok := Parse(out.String())
+			Expect(ok).To(BeTrue())
+			Expect(update.Done).To(BeTrue())
+		})
+
+		It("reports the schemas migrated, the tables copied and the views skipped", func() {
+			tracker.Migrating([]string{"app"}, []discovery.View{{Schema: "app", TableName: "broken"}})
+			stream := tracker.Stream("app")
+			_, err := io.WriteString(stream, "-- Dumping data for table `users`\n"+
+				"-- Dumping data for table `orders`\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stream.Close()).To(Succeed())
+			Expect(tracker.Update().CopiedTables).To(BeEmpty())
+
+			tracker.Finish()
+
+			update, ok := Parse(out.String())
+			Expect(ok).To(BeTrue())
+			Expect(update.Schemas).To(Equal([]string{"app"}))
+			Expect(update.CopiedTables).To(Equal([]string{"app.users", "app.orders"}))
+			Expect(update.SkippedViews).To(Equal([]string{"app.broken"}))
+		})
+
+		It("reports the fingerprint of the recipient", func() {
+			tracker.RecipientFingerprint("some-fingerprint")
+			Expect(tracker.Update().RecipientFingerprint).To(BeEmpty())
+
+			tracker.Finish()
+
+			update, ok := Parse(out.String())
+			Expect(ok).To(BeTrue())
+			Expect(update.RecipientFingerprint).To(Equal("some-fingerprint"))
+		})
+	})
+
+	Context("ReportEvery", func() {
+		It("reports periodically, and once more when stopped", func() {
+			buffer := gbytes.NewBuffer()
+			lines := func() int { return strings.Count(string(buffer.Contents()), "\n") }
+			tracker = NewTracker(buffer, nil, time.Now)
+
+			stop := tracker.ReportEvery(10 * time.Millisecond)
+			Eventually(lines).Should(BeNumerically(">=", 2))
+
+			stop()
+			reported := lines()
+			Consistently(lines, 50*time.Millisecond).Should(Equal(reported))
+		})
+	})
+})
+
+var _ = Describe("Parse", func() {
+	It("ignores other lines", func() {
+		_, ok := Parse("2024-01-01T00:00:05.00+0000 [APP/TASK/migrate/0] ERR Migrating schemas: app")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("ignores progress lines that are not valid", func() {
+		_, ok := Parse(Prefix + "{")
+		Expect(ok).To(BeFalse())
+	})
+})
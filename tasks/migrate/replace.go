@@ -0,0 +1,52 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+// errUnknownDatabase is the error number of a statement referencing a schema that does not exist
+const errUnknownDatabase = 1049
+
+// ReplaceSchemas drops and re-creates the recipient schemas with their current definition, so that a copy replaces
+// their content instead of being merged into it. Schemas that do not exist yet are left to the copy to create.
+func ReplaceSchemas(db *sql.DB, schemas []string) error {
+	for _, schema := range schemas {
+		var name, definition string
+		err := db.QueryRow("SHOW CREATE DATABASE "+discovery.QuoteIdentifier(schema)).Scan(&name, &definition)
+		if err != nil {
+			var mysqlErr *mysql.MySQLError
+			if errors.As(err, &mysqlErr) && mysqlErr.Number == errUnknownDatabase {
+				continue
+			}
+			return fmt.Errorf("failed to read the definition of schema %s: %w", schema, err)
+		}
+
+		if _, err := db.Exec("DROP DATABASE " + discovery.QuoteIdentifier(schema)); err != nil {
+			return fmt.Errorf("failed to drop schema %s: %w", schema, err)
+		}
+
+		if _, err := db.Exec(definition); err != nil {
+			return fmt.Errorf("failed to re-create schema %s: %w", schema, err)
+		}
+	}
+
+	return nil
+}
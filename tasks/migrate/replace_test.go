@@ -0,0 +1,70 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplaceSchemas", func() {
+	var (
+		db   *sql.DB
+		mock sqlmock.Sqlmock
+	)
+
+	BeforeEach(func() {
+		var err error
+		db, mock, err = sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(mock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	It("drops and re-creates every schema with its definition", func() {
+		mock.ExpectQuery("SHOW CREATE DATABASE `service_instance_db`").
+			WillReturnRows(sqlmock.NewRows([]string{"Database", "Create Database"}).
+				AddRow("service_instance_db", "CREATE DATABASE `service_instance_db` /*!40100 DEFAULT CHARACTER SET utf8mb4 */"))
+		mock.ExpectExec("DROP DATABASE `service_instance_db`").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec("CREATE DATABASE `service_instance_db` /*!40100 DEFAULT CHARACTER SET utf8mb4 */").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		Expect(ReplaceSchemas(db, []string{"service_instance_db"})).To(Succeed())
+	})
+
+	It("skips schemas that do not exist", func() {
+		mock.ExpectQuery("SHOW CREATE DATABASE `sakila`").
+			WillReturnError(&mysql.MySQLError{Number: 1049, Message: "Unknown database 'sakila'"})
+
+		Expect(ReplaceSchemas(db, []string{"sakila"})).To(Succeed())
+	})
+
+	It("returns an error when a schema can not be dropped", func() {
+		mock.ExpectQuery("SHOW CREATE DATABASE `sakila`").
+			WillReturnRows(sqlmock.NewRows([]string{"Database", "Create Database"}).
+				AddRow("sakila", "CREATE DATABASE `sakila`"))
+		mock.ExpectExec("DROP DATABASE `sakila`").
+			WillReturnError(errors.New("some-error"))
+
+		Expect(ReplaceSchemas(db, []string{"sakila"})).
+			To(MatchError("failed to drop schema sakila: some-error"))
+	})
+})
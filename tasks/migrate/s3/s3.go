@@ -0,0 +1,346 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package s3 uploads and downloads objects of S3-compatible object stores, such as AWS S3 or MinIO
+package s3
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AccessKeyIDEnv and SecretAccessKeyEnv are the environment variables holding the credentials of the object
+	// store, as used by the AWS CLI
+	AccessKeyIDEnv     = "AWS_ACCESS_KEY_ID"
+	SecretAccessKeyEnv = "AWS_SECRET_ACCESS_KEY"
+
+	DefaultEndpoint = "https://s3.amazonaws.com"
+	DefaultRegion   = "us-east-1"
+
+	// DefaultPartSize is the smallest size of the parts of multipart uploads
+	DefaultPartSize = 8 * 1024 * 1024
+	// MaxParts is the number of parts S3 allows per object, and MaxPartSize the largest part it accepts
+	MaxParts    = 10000
+	MaxPartSize = 5 * 1024 * 1024 * 1024
+
+	urlScheme = "s3://"
+)
+
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// CredentialsFromEnv reads the credentials of the object store from the environment
+func CredentialsFromEnv() (Credentials, error) {
+	credentials := Credentials{
+		AccessKeyID:     os.Getenv(AccessKeyIDEnv),
+		SecretAccessKey: os.Getenv(SecretAccessKeyEnv),
+	}
+
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("%s and %s must be set to access S3", AccessKeyIDEnv, SecretAccessKeyEnv)
+	}
+
+	return credentials, nil
+}
+
+// Location is an object in a bucket
+type Location struct {
+	Bucket string
+	Key    string
+}
+
+// IsURL reports whether s is an s3://<bucket>/<key> URL rather than a local path
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, urlScheme)
+}
+
+// ParseURL parses an s3://<bucket>/<key> URL
+func ParseURL(s string) (Location, error) {
+	if !IsURL(s) {
+		return Location{}, fmt.Errorf("invalid S3 URL %q, expected s3://<bucket>/<key>", s)
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(s, urlScheme), "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return Location{}, fmt.Errorf("invalid S3 URL %q, expected s3://<bucket>/<key>", s)
+	}
+
+	return Location{Bucket: bucket, Key: key}, nil
+}
+
+func (l Location) String() string {
+	return urlScheme + l.Bucket + "/" + l.Key
+}
+
+// Client accesses objects with path-style requests, which every S3-compatible store supports
+type Client struct {
+	endpoint    *url.URL
+	region      string
+	credentials Credentials
+	httpClient  *http.Client
+
+	PartSize int
+	MaxParts int
+	Now      func() time.Time
+}
+
+func NewClient(endpoint, region string, credentials Credentials, skipTLSValidation bool) (*Client, error) {
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	if region == "" {
+		region = DefaultRegion
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q, expected an http or https URL", endpoint)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if skipTLSValidation {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &Client{
+		endpoint:    u,
+		region:      region,
+		credentials: credentials,
+		httpClient:  &http.Client{Transport: transport},
+		PartSize:    DefaultPartSize,
+		MaxParts:    MaxParts,
+		Now:         time.Now,
+	}, nil
+}
+
+// Upload writes everything read from r to the object. Objects larger than a part are uploaded in parts, so that they
+// are never held in memory at once. sizeHint is the expected size of the object, which the parts are sized for.
+func (c *Client) Upload(location Location, r io.Reader, sizeHint int64) error {
+	partSize, err := c.UploadPartSize(sizeHint)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", location, err)
+	}
+	part := make([]byte, partSize)
+
+	n, err := io.ReadFull(r, part)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	if n < partSize {
+		if _, err := c.do(http.MethodPut, location, nil, part[:n]); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", location, err)
+		}
+		return nil
+	}
+
+	uploadID, err := c.createMultipartUpload(location)
+	if err != nil {
+		return fmt.Errorf("failed to start uploading %s: %w", location, err)
+	}
+
+	if err := c.uploadParts(location, uploadID, part, r); err != nil {
+		// Parts of an incomplete upload are stored until it is aborted
+		_, _ = c.do(http.MethodDelete, location, url.Values{"uploadId": {uploadID}}, nil)
+		return fmt.Errorf("failed to upload %s: %w", location, err)
+	}
+
+	return nil
+}
+
+// Download returns the content of the object
+func (c *Client) Download(location Location) (io.ReadCloser, error) {
+	req, err := c.newRequest(http.MethodGet, location, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", location, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: %w", location, responseError(resp))
+	}
+
+	return resp.Body, nil
+}
+
+// UploadPartSize returns the size of the parts of an upload of about sizeHint bytes, which is at least PartSize. The
+// hint is an estimate, so the parts leave room for twice as much. It fails when the parts would be larger than S3
+// accepts.
+func (c *Client) UploadPartSize(sizeHint int64) (int, error) {
+	size := int64(c.PartSize)
+	if needed := (2*sizeHint + int64(c.MaxParts) - 1) / int64(c.MaxParts); needed > size {
+		size = needed
+	}
+
+	if size > MaxPartSize {
+		return 0, fmt.Errorf("an object of about %d bytes needs parts of %d bytes, but S3 accepts at most %d parts of %d bytes", sizeHint, size, c.MaxParts, int64(MaxPartSize))
+	}
+
+	return int(size), nil
+}
+
+func (c *Client) uploadParts(location Location, uploadID string, part []byte, r io.Reader) error {
+	var completed completeMultipartUpload
+
+	for number := 1; len(part) > 0; number++ {
+		if number > c.MaxParts {
+			return fmt.Errorf("the object is larger than expected, and does not fit in %d parts of %d bytes", c.MaxParts, cap(part))
+		}
+
+		resp, err := c.do(http.MethodPut, location, url.Values{
+			"partNumber": {strconv.Itoa(number)},
+			"uploadId":   {uploadID},
+		}, part)
+		if err != nil {
+			return fmt.Errorf("part %d: %w", number, err)
+		}
+		completed.Parts = append(completed.Parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		n, err := io.ReadFull(r, part[:cap(part)])
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return err
+		}
+		part = part[:n]
+	}
+
+	body, err := xml.Marshal(completed)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(http.MethodPost, location, url.Values{"uploadId": {uploadID}}, body)
+	if err != nil {
+		return err
+	}
+
+	// Completing an upload can fail after the response status was sent
+	var result struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if err := xml.Unmarshal(resp.Body, &result); err == nil && result.XMLName.Local == "Error" {
+		return fmt.Errorf("%s: %s", result.Code, result.Message)
+	}
+
+	return nil
+}
+
+func (c *Client) createMultipartUpload(location Location) (string, error) {
+	resp, err := c.do(http.MethodPost, location, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(resp.Body, &result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("unexpected response: %s", resp.Body)
+	}
+
+	return result.UploadID, nil
+}
+
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+type response struct {
+	Header http.Header
+	Body   []byte
+}
+
+// do sends a request with the given body and reads the whole response
+func (c *Client) do(method string, location Location, query url.Values, body []byte) (response, error) {
+	req, err := c.newRequest(method, location, query, body)
+	if err != nil {
+		return response{}, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return response{}, responseError(resp)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, err
+	}
+
+	return response{Header: resp.Header, Body: respBody}, nil
+}
+
+func (c *Client) newRequest(method string, location Location, query url.Values, body []byte) (*http.Request, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + location.Bucket + "/" + location.Key
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	payloadHash := emptyPayloadHash
+	if len(body) > 0 {
+		payloadHash = hexSHA256(body)
+	}
+	sign(req, c.credentials, c.region, payloadHash, c.Now())
+
+	return req, nil
+}
+
+// responseError reads the error S3 returned as XML
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var result struct {
+		Code    string
+		Message string
+	}
+	if err := xml.Unmarshal(body, &result); err == nil && result.Code != "" {
+		return fmt.Errorf("%s: %s: %s", resp.Status, result.Code, result.Message)
+	}
+
+	return fmt.Errorf("unexpected response %s", resp.Status)
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+type StoredProgramResult struct {
+	Program  discovery.StoredProgram
+	Migrated bool
+}
+
+// CompareStoredPrograms reports, for every stored program found on the donor, whether it exists on the recipient.
+// recipientSchema maps a donor schema name to the name of the schema it was loaded into on the recipient.
+func CompareStoredPrograms(donorPrograms, recipientPrograms []discovery.StoredProgram, recipientSchema func(string) string) []StoredProgramResult {
+	migrated := map[discovery.StoredProgram]struct{}{}
+	for _, p := range recipientPrograms {
+		migrated[p] = struct{}{}
+	}
+
+	results := make([]StoredProgramResult, 0, len(donorPrograms))
+	for _, p := range donorPrograms {
+		expected := discovery.StoredProgram{Schema: recipientSchema(p.Schema), Name: p.Name, Type: p.Type, Table: p.Table}
+		_, ok := migrated[expected]
+		results = append(results, StoredProgramResult{Program: p, Migrated: ok})
+	}
+
+	return results
+}
+
+func WriteStoredProgramReport(w io.Writer, results []StoredProgramResult) (failures int) {
+	_, _ = fmt.Fprintln(w, "Stored program migration report:")
+	if len(results) == 0 {
+		_, _ = fmt.Fprintln(w, "  No routines, triggers or events found")
+		return 0
+	}
+
+	for _, r := range results {
+		status := "OK"
+		if !r.Migrated {
+			status = "FAILED"
+			failures++
+		}
+		_, _ = fmt.Fprintf(w, "  %-6s %s\n", status, r.Program)
+	}
+
+	return failures
+}
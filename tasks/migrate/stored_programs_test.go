@@ -0,0 +1,93 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+var _ = Describe("Stored programs", func() {
+	var (
+		donorPrograms   []discovery.StoredProgram
+		recipientSchema func(string) string
+	)
+
+	BeforeEach(func() {
+		donorPrograms = []discovery.StoredProgram{
+			{Schema: "cf_some_db", Name: "film_in_stock", Type: "PROCEDURE"},
+			{Schema: "cf_some_db", Name: "ins_film", Type: "TRIGGER"},
+		}
+		recipientSchema = func(string) string { return "service_instance_db" }
+	})
+
+	Describe("CompareStoredPrograms", func() {
+		It("reports stored programs found in the mapped recipient schema as migrated", func() {
+			results := CompareStoredPrograms(donorPrograms, []discovery.StoredProgram{
+				{Schema: "service_instance_db", Name: "film_in_stock", Type: "PROCEDURE"},
+			}, recipientSchema)
+
+			Expect(results).To(Equal([]StoredProgramResult{
+				{Program: donorPrograms[0], Migrated: true},
+				{Program: donorPrograms[1], Migrated: false},
+			}))
+		})
+
+		It("does not match stored programs of a different type", func() {
+			results := CompareStoredPrograms(donorPrograms[:1], []discovery.StoredProgram{
+				{Schema: "service_instance_db", Name: "film_in_stock", Type: "FUNCTION"},
+			}, recipientSchema)
+
+			Expect(results).To(Equal([]StoredProgramResult{
+				{Program: donorPrograms[0], Migrated: false},
+			}))
+		})
+	})
+
+	Describe("WriteStoredProgramReport", func() {
+		It("writes a line per stored program and counts the failures", func() {
+			var buf bytes.Buffer
+			failures := WriteStoredProgramReport(&buf, []StoredProgramResult{
+				{Program: donorPrograms[0], Migrated: true},
+				{Program: donorPrograms[1], Migrated: false},
+			})
+
+			Expect(failures).To(Equal(1))
+			Expect(buf.String()).To(Equal("Stored program migration report:\n" +
+				"  OK     PROCEDURE cf_some_db.film_in_stock\n" +
+				"  FAILED TRIGGER cf_some_db.ins_film\n"))
+		})
+
+		It("notes when there is nothing to migrate", func() {
+			var buf bytes.Buffer
+			Expect(WriteStoredProgramReport(&buf, nil)).To(BeZero())
+			Expect(buf.String()).To(ContainSubstring("No routines, triggers or events found"))
+		})
+	})
+
+	Describe("RecipientSchemaMapper", func() {
+		It("maps a single donor schema to the recipient's default database", func() {
+			recipientSchema := RecipientSchemaMapper([]string{"cf_some_db"}, "service_instance_db")
+			Expect(recipientSchema("cf_some_db")).To(Equal("service_instance_db"))
+		})
+
+		It("keeps schema names when migrating several schemas", func() {
+			recipientSchema := RecipientSchemaMapper([]string{"cf_some_db", "sakila"}, "service_instance_db")
+			Expect(recipientSchema("sakila")).To(Equal("sakila"))
+		})
+	})
+})
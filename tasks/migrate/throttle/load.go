@@ -0,0 +1,106 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package throttle
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Load is how busy the source is
+type Load struct {
+	ThreadsRunning int
+	// ReplicationLag is nil unless the source replicates from another server
+	ReplicationLag *time.Duration
+}
+
+// Probe looks up the load of the source
+type Probe func() (Load, error)
+
+// SourceLoad returns a Probe querying db. The replication lag is only looked up when replication is set, since it
+// requires the REPLICATION CLIENT privilege.
+func SourceLoad(db *sql.DB, replication bool) Probe {
+	return func() (Load, error) {
+		var (
+			load Load
+			name string
+		)
+
+		if err := db.QueryRow("SHOW GLOBAL STATUS LIKE 'Threads_running'").Scan(&name, &load.ThreadsRunning); err != nil {
+			return Load{}, fmt.Errorf("failed to look up Threads_running: %w", err)
+		}
+
+		if replication {
+			lag, err := replicationLag(db)
+			if err != nil {
+				return Load{}, fmt.Errorf("failed to look up the replication lag: %w", err)
+			}
+			load.ReplicationLag = lag
+		}
+
+		return load, nil
+	}
+}
+
+// replicationLag returns how far the server lags behind its replication source, or nil when it does not replicate
+func replicationLag(db *sql.DB) (*time.Duration, error) {
+	// SHOW REPLICA STATUS replaces SHOW SLAVE STATUS as of MySQL 8.0.22
+	rows, err := db.Query("SHOW REPLICA STATUS")
+	if err != nil {
+		if rows, err = db.Query("SHOW SLAVE STATUS"); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]sql.RawBytes, len(columns))
+	dests := make([]any, len(columns))
+	for i := range values {
+		dests[i] = &values[i]
+	}
+
+	if err := rows.Scan(dests...); err != nil {
+		return nil, err
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+
+		// NULL while replication is stopped
+		if values[i] == nil {
+			return nil, nil
+		}
+
+		var seconds int64
+		if _, err := fmt.Sscan(string(values[i]), &seconds); err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %w", column, values[i], err)
+		}
+
+		lag := time.Duration(seconds) * time.Second
+		return &lag, nil
+	}
+
+	return nil, nil
+}
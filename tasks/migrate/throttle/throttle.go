@@ -0,0 +1,235 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+// Package throttle slows down copying rows from the source, so that a migration does not saturate a source that
+// is still serving traffic
+package throttle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CheckInterval is how often the load of the source is checked, and how long copying pauses before checking again
+const CheckInterval = 5 * time.Second
+
+// minSleep keeps the throttle from sleeping for every row. Shorter waits are added up until they are worth it.
+const minSleep = 10 * time.Millisecond
+
+// Limits bound how fast rows are copied. Zero values are unlimited.
+type Limits struct {
+	RowsPerSecond  int64
+	BytesPerSecond int64
+	// MaxThreadsRunning pauses copying while the source runs more threads than this
+	MaxThreadsRunning int
+	// MaxReplicationLag pauses copying while the source replicates from another server and lags further behind
+	MaxReplicationLag time.Duration
+}
+
+// Adaptive reports whether copying backs off depending on the load of the source
+func (l Limits) Adaptive() bool {
+	return l.MaxThreadsRunning > 0 || l.MaxReplicationLag > 0
+}
+
+// Unlimited reports whether copying is never slowed down
+func (l Limits) Unlimited() bool {
+	return l.RowsPerSecond <= 0 && l.BytesPerSecond <= 0 && !l.Adaptive()
+}
+
+func (l Limits) String() string {
+	var parts []string
+	if l.RowsPerSecond > 0 {
+		parts = append(parts, fmt.Sprintf("%d rows per second", l.RowsPerSecond))
+	}
+	if l.BytesPerSecond > 0 {
+		parts = append(parts, fmt.Sprintf("%d bytes per second", l.BytesPerSecond))
+	}
+	if l.MaxThreadsRunning > 0 {
+		parts = append(parts, fmt.Sprintf("at most %d threads running on the source", l.MaxThreadsRunning))
+	}
+	if l.MaxReplicationLag > 0 {
+		parts = append(parts, fmt.Sprintf("at most %s of replication lag", l.MaxReplicationLag))
+	}
+	if len(parts) == 0 {
+		return "unlimited"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// exceeded explains how the load exceeds the limits, or returns an empty string when it does not
+func (l Limits) exceeded(load Load) string {
+	if l.MaxThreadsRunning > 0 && load.ThreadsRunning > l.MaxThreadsRunning {
+		return fmt.Sprintf("the source runs %d threads, more than %d", load.ThreadsRunning, l.MaxThreadsRunning)
+	}
+
+	if l.MaxReplicationLag > 0 && load.ReplicationLag != nil && *load.ReplicationLag > l.MaxReplicationLag {
+		return fmt.Sprintf("the source lags %s behind its replication source, more than %s", *load.ReplicationLag, l.MaxReplicationLag)
+	}
+
+	return ""
+}
+
+// Throttle limits the rate of the rows and bytes reported to Wait, which is shared by every worker copying rows. A
+// nil Throttle does not limit anything.
+type Throttle struct {
+	limits Limits
+	probe  Probe
+
+	mu sync.Mutex
+	// next is when the rows and bytes copied so far are due at the allowed rate
+	next        time.Time
+	checkedAt   time.Time
+	paused      bool
+	probeFailed bool
+
+	Now   func() time.Time
+	Sleep func(time.Duration)
+}
+
+// New returns a Throttle enforcing limits. probe is only used to back off adaptively.
+func New(limits Limits, probe Probe) *Throttle {
+	return &Throttle{
+		limits: limits,
+		probe:  probe,
+		Now:    time.Now,
+		Sleep:  time.Sleep,
+	}
+}
+
+// Wait blocks until rows rows and n bytes may be copied, and while the source is too busy
+func (t *Throttle) Wait(rows, n int) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.backOff()
+
+	// Time spent idle or paused does not allow a burst afterwards
+	now := t.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+
+	t.next = t.next.Add(t.cost(rows, n))
+	if wait := t.next.Sub(now); wait >= minSleep {
+		t.Sleep(wait)
+	}
+}
+
+func (t *Throttle) cost(rows, n int) time.Duration {
+	var cost time.Duration
+
+	if t.limits.RowsPerSecond > 0 {
+		cost = time.Duration(int64(rows) * int64(time.Second) / t.limits.RowsPerSecond)
+	}
+
+	if t.limits.BytesPerSecond > 0 {
+		if c := time.Duration(int64(n) * int64(time.Second) / t.limits.BytesPerSecond); c > cost {
+			cost = c
+		}
+	}
+
+	return cost
+}
+
+// backOff pauses while the load of the source exceeds the limits, checking it at most once per CheckInterval
+func (t *Throttle) backOff() {
+	if !t.limits.Adaptive() {
+		return
+	}
+
+	for {
+		now := t.Now()
+		if now.Sub(t.checkedAt) < CheckInterval {
+			return
+		}
+		t.checkedAt = now
+
+		load, err := t.probe()
+		if err != nil {
+			if !t.probeFailed {
+				log.Printf("WARNING: Failed to check the load of the source, copying without backing off: %v", err)
+				t.probeFailed = true
+			}
+			return
+		}
+
+		reason := t.limits.exceeded(load)
+		if reason == "" {
+			if t.paused {
+				log.Print("Resuming the copy")
+				t.paused = false
+			}
+			return
+		}
+
+		if !t.paused {
+			log.Printf("Pausing the copy, since %s", reason)
+			t.paused = true
+		}
+		t.Sleep(CheckInterval)
+	}
+}
+
+// Reader throttles the output of mysqldump as it is read. Rows are counted as the values of its extended INSERT
+// statements, which are separated by "),(", so the row rate is approximate when string values contain it too.
+func (t *Throttle) Reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+
+	return &reader{r: r, t: t}
+}
+
+var (
+	insertStatement = []byte("INSERT INTO ")
+	rowSeparator    = []byte("),(")
+)
+
+type reader struct {
+	r io.Reader
+	t *Throttle
+	// tail holds the end of the previous read, in case a statement or separator spans two reads
+	tail []byte
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.t.Wait(r.countRows(p[:n]), n)
+	}
+
+	return n, err
+}
+
+func (r *reader) countRows(p []byte) int {
+	// Matches within the tail were counted by the previous read
+	counted := bytes.Count(r.tail, insertStatement) + bytes.Count(r.tail, rowSeparator)
+	buf := append(r.tail, p...)
+	rows := bytes.Count(buf, insertStatement) + bytes.Count(buf, rowSeparator) - counted
+
+	keep := len(insertStatement) - 1
+	if keep > len(buf) {
+		keep = len(buf)
+	}
+	r.tail = append(r.tail[:0], buf[len(buf)-keep:]...)
+
+	return rows
+}
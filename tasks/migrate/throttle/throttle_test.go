@@ -0,0 +1,191 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package throttle_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/throttle"
+)
+
+var _ = Describe("Throttle", func() {
+	var (
+		now    time.Time
+		slept  time.Duration
+		probes int
+		loads  []throttle.Load
+	)
+
+	newThrottle := func(limits throttle.Limits) *throttle.Throttle {
+		t := throttle.New(limits, func() (throttle.Load, error) {
+			load := loads[0]
+			if len(loads) > 1 {
+				loads = loads[1:]
+			}
+			probes++
+			return load, nil
+		})
+		t.Now = func() time.Time { return now }
+		t.Sleep = func(d time.Duration) {
+			slept += d
+			now = now.Add(d)
+		}
+
+		return t
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		slept = 0
+		probes = 0
+		loads = []throttle.Load{{ThreadsRunning: 1}}
+	})
+
+	It("limits the rate of rows", func() {
+		t := newThrottle(throttle.Limits{RowsPerSecond: 100})
+
+		for i := 0; i < 1000; i++ {
+			t.Wait(1, 10)
+		}
+
+		Expect(slept).To(BeNumerically("~", 10*time.Second, 10*time.Millisecond))
+	})
+
+	It("limits the rate of bytes", func() {
+		t := newThrottle(throttle.Limits{BytesPerSecond: 1024})
+
+		for i := 0; i < 8; i++ {
+			t.Wait(1, 1024)
+		}
+
+		Expect(slept).To(Equal(8 * time.Second))
+	})
+
+	It("applies whichever limit is stricter", func() {
+		t := newThrottle(throttle.Limits{RowsPerSecond: 10, BytesPerSecond: 1024 * 1024})
+
+		t.Wait(20, 1024)
+		Expect(slept).To(Equal(2 * time.Second))
+	})
+
+	It("does not allow a burst after being idle", func() {
+		t := newThrottle(throttle.Limits{RowsPerSecond: 10})
+
+		now = now.Add(time.Hour)
+		t.Wait(10, 0)
+		Expect(slept).To(Equal(time.Second))
+	})
+
+	It("does not limit anything when nil", func() {
+		var t *throttle.Throttle
+		t.Wait(1000, 1000)
+
+		r := strings.NewReader("INSERT INTO `t` VALUES (1),(2);\n")
+		Expect(t.Reader(r)).To(BeIdenticalTo(r))
+	})
+
+	Context("when backing off adaptively", func() {
+		It("pauses while the source runs too many threads", func() {
+			loads = []throttle.Load{{ThreadsRunning: 50}, {ThreadsRunning: 40}, {ThreadsRunning: 5}}
+			t := newThrottle(throttle.Limits{MaxThreadsRunning: 20})
+
+			t.Wait(1, 10)
+			Expect(probes).To(Equal(3))
+			Expect(slept).To(Equal(2 * throttle.CheckInterval))
+		})
+
+		It("pauses while the source lags too far behind its replication source", func() {
+			lag, caughtUp := time.Minute, time.Second
+			loads = []throttle.Load{{ReplicationLag: &lag}, {ReplicationLag: &caughtUp}}
+			t := newThrottle(throttle.Limits{MaxReplicationLag: 10 * time.Second})
+
+			t.Wait(1, 10)
+			Expect(slept).To(Equal(throttle.CheckInterval))
+		})
+
+		It("ignores the replication lag of a source that does not replicate", func() {
+			t := newThrottle(throttle.Limits{MaxReplicationLag: 10 * time.Second})
+
+			t.Wait(1, 10)
+			Expect(slept).To(BeZero())
+		})
+
+		It("checks the load of the source at most once per interval", func() {
+			t := newThrottle(throttle.Limits{MaxThreadsRunning: 20})
+
+			for i := 0; i < 100; i++ {
+				t.Wait(1, 10)
+			}
+			Expect(probes).To(Equal(1))
+
+			now = now.Add(throttle.CheckInterval)
+			t.Wait(1, 10)
+			Expect(probes).To(Equal(2))
+		})
+
+		It("keeps copying when the load can not be checked", func() {
+			t := throttle.New(throttle.Limits{MaxThreadsRunning: 20}, func() (throttle.Load, error) {
+				return throttle.Load{}, errors.New("access denied")
+			})
+			t.Sleep = func(d time.Duration) { slept += d }
+
+			t.Wait(1, 10)
+			Expect(slept).To(BeZero())
+		})
+	})
+
+	Describe("Reader", func() {
+		It("throttles the rows and bytes of a dump as it is read", func() {
+			t := newThrottle(throttle.Limits{RowsPerSecond: 1})
+
+			dump := "CREATE TABLE `t` (`id` int);\nINSERT INTO `t` VALUES (1),(2),(3);\nINSERT INTO `t` VALUES (4),(5);\n"
+			contents, err := io.ReadAll(io.LimitReader(t.Reader(strings.NewReader(dump)), int64(len(dump))))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal(dump))
+
+			Expect(slept).To(Equal(5 * time.Second))
+		})
+
+		It("counts rows split across reads", func() {
+			t := newThrottle(throttle.Limits{RowsPerSecond: 1})
+
+			dump := "INSERT INTO `t` VALUES (1),(2),(3);\nINSERT INTO `t` VALUES (4),(5);\n"
+			_, err := io.ReadAll(t.Reader(&oneByteReader{r: strings.NewReader(dump)}))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(slept).To(Equal(5 * time.Second))
+		})
+	})
+})
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	return o.r.Read(p[:1])
+}
+
+var _ = Describe("Limits", func() {
+	It("describes the limits set", func() {
+		Expect(throttle.Limits{}.String()).To(Equal("unlimited"))
+		Expect(throttle.Limits{RowsPerSecond: 500, MaxReplicationLag: 30 * time.Second}.String()).
+			To(Equal("500 rows per second, at most 30s of replication lag"))
+	})
+})
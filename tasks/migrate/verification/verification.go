@@ -0,0 +1,201 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package verification
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+)
+
+type Mode string
+
+const (
+	// ModeRowCount only compares the number of rows in each table
+	ModeRowCount Mode = "rows"
+	// ModeChecksum compares the number of rows and the CHECKSUM TABLE result of each table
+	ModeChecksum Mode = "checksum"
+)
+
+func ParseMode(value string) (Mode, error) {
+	switch Mode(value) {
+	case ModeRowCount, ModeChecksum:
+		return Mode(value), nil
+	default:
+		return "", fmt.Errorf("invalid verification mode %q, expected %q or %q", value, ModeRowCount, ModeChecksum)
+	}
+}
+
+type TableResult struct {
+	Schema             string
+	Table              string
+	RecipientSchema    string
+	DonorRows          int64
+	RecipientRows      int64
+	DonorChecksum      sql.NullString
+	RecipientChecksum  sql.NullString
+	MissingOnRecipient bool
+}
+
+func (r TableResult) Matches() bool {
+	return !r.MissingOnRecipient &&
+		r.DonorRows == r.RecipientRows &&
+		r.DonorChecksum == r.RecipientChecksum
+}
+
+func (r TableResult) String() string {
+	return fmt.Sprintf("%s.%s", r.Schema, r.Table)
+}
+
+// VerifyTables compares every base table of the donor schemas with the table of the same name in the
+// corresponding recipient schema. Tables excluded by the filter are skipped, since they were never migrated.
+func VerifyTables(donor, recipient *sql.DB, schemas []string, filter discovery.Filter, recipientSchema func(string) string, mode Mode) ([]TableResult, error) {
+	var results []TableResult
+
+	for _, schema := range schemas {
+		tables, err := discovery.DiscoverTables(donor, schema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list donor tables: %w", err)
+		}
+
+		recipientTables, err := discovery.DiscoverTables(recipient, recipientSchema(schema))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list recipient tables: %w", err)
+		}
+
+		migrated := map[string]struct{}{}
+		for _, t := range recipientTables {
+			migrated[t] = struct{}{}
+		}
+
+		for _, table := range tables {
+			if !filter.TableIncluded(schema, table) {
+				continue
+			}
+
+			result := TableResult{
+				Schema:          schema,
+				Table:           table,
+				RecipientSchema: recipientSchema(schema),
+			}
+
+			if result.DonorRows, result.DonorChecksum, err = inspectTable(donor, schema, table, mode); err != nil {
+				return nil, fmt.Errorf("failed to inspect donor table %s: %w", result, err)
+			}
+
+			if _, ok := migrated[table]; !ok {
+				result.MissingOnRecipient = true
+				results = append(results, result)
+				continue
+			}
+
+			if result.RecipientRows, result.RecipientChecksum, err = inspectTable(recipient, result.RecipientSchema, table, mode); err != nil {
+				return nil, fmt.Errorf("failed to inspect recipient table %s.%s: %w", result.RecipientSchema, table, err)
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// Fingerprint digests the row count and CHECKSUM TABLE result of every base table in the user schemas of db. Unlike
+// the binlog position, it does not change when accounts are created or dropped, such as when apps are bound or unbound.
+func Fingerprint(db *sql.DB) (string, error) {
+	schemas, err := discovery.DiscoverDatabases(db)
+	if err != nil {
+		return "", fmt.Errorf("failed to list schemas: %w", err)
+	}
+
+	digest := sha256.New()
+	for _, schema := range schemas {
+		tables, err := discovery.DiscoverTables(db, schema)
+		if err != nil {
+			return "", fmt.Errorf("failed to list tables: %w", err)
+		}
+
+		_, _ = fmt.Fprintf(digest, "%s\n", discovery.QuoteIdentifier(schema))
+		for _, table := range tables {
+			rows, checksum, err := inspectTable(db, schema, table, ModeChecksum)
+			if err != nil {
+				return "", fmt.Errorf("failed to inspect table %s.%s: %w", schema, table, err)
+			}
+
+			_, _ = fmt.Fprintf(digest, "%s %d %s\n", discovery.QuoteIdentifier(table), rows, checksumString(checksum))
+		}
+	}
+
+	return hex.EncodeToString(digest.Sum(nil)), nil
+}
+
+func inspectTable(db *sql.DB, schema, table string, mode Mode) (rows int64, checksum sql.NullString, err error) {
+	qualifiedName := discovery.QuoteIdentifier(schema) + "." + discovery.QuoteIdentifier(table)
+
+	if err := db.QueryRow(`SELECT COUNT(*) FROM ` + qualifiedName).Scan(&rows); err != nil {
+		return 0, checksum, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	if mode != ModeChecksum {
+		return rows, checksum, nil
+	}
+
+	var unused string
+	if err := db.QueryRow(`CHECKSUM TABLE `+qualifiedName).Scan(&unused, &checksum); err != nil {
+		return 0, checksum, fmt.Errorf("failed to checksum table: %w", err)
+	}
+
+	return rows, checksum, nil
+}
+
+// WriteReport writes a line for every table that does not match, followed by a summary
+func WriteReport(w io.Writer, results []TableResult) (mismatches int) {
+	_, _ = fmt.Fprintln(w, "Data verification report:")
+
+	for _, r := range results {
+		if r.Matches() {
+			continue
+		}
+		mismatches++
+
+		if r.MissingOnRecipient {
+			_, _ = fmt.Fprintf(w, "  MISSING  %s: table not found in recipient schema %s\n", r, r.RecipientSchema)
+			continue
+		}
+
+		var diffs []string
+		if r.DonorRows != r.RecipientRows {
+			diffs = append(diffs, fmt.Sprintf("rows %d (donor) != %d (recipient)", r.DonorRows, r.RecipientRows))
+		}
+		if r.DonorChecksum != r.RecipientChecksum {
+			diffs = append(diffs, fmt.Sprintf("checksum %s (donor) != %s (recipient)", checksumString(r.DonorChecksum), checksumString(r.RecipientChecksum)))
+		}
+		_, _ = fmt.Fprintf(w, "  MISMATCH %s: %s\n", r, strings.Join(diffs, ", "))
+	}
+
+	_, _ = fmt.Fprintf(w, "  Verified %d tables, %d mismatched\n", len(results), mismatches)
+
+	return mismatches
+}
+
+func checksumString(checksum sql.NullString) string {
+	if !checksum.Valid {
+		return "NULL"
+	}
+	return checksum.String
+}
@@ -0,0 +1,234 @@
+// Copyright (C) 2018-Present Pivotal Software, Inc. All rights reserved.
+//
+// This program and the accompanying materials are made available under the terms of the under the Apache License,
+// Version 2.0 (the "License”); you may not use this file except in compliance with the License. You may obtain a copy
+// of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software distributed under the License is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied. See the License for the
+// specific language governing permissions and limitations under the License.
+
+package verification_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/discovery"
+	. "github.com/pivotal-cf/mysql-cli-plugin/tasks/migrate/verification"
+)
+
+var _ = Describe("Verification", func() {
+	const listTablesQuery = `SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES`
+
+	var (
+		donorDB       *sql.DB
+		donorMock     sqlmock.Sqlmock
+		recipientDB   *sql.DB
+		recipientMock sqlmock.Sqlmock
+		renamed       func(string) string
+	)
+
+	BeforeEach(func() {
+		var err error
+		donorDB, donorMock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+		recipientDB, recipientMock, err = sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		renamed = func(string) string { return "service_instance_db" }
+	})
+
+	AfterEach(func() {
+		Expect(donorMock.ExpectationsWereMet()).To(Succeed())
+		Expect(recipientMock.ExpectationsWereMet()).To(Succeed())
+	})
+
+	Context("ParseMode", func() {
+		It("accepts the supported modes", func() {
+			Expect(ParseMode("rows")).To(Equal(ModeRowCount))
+			Expect(ParseMode("checksum")).To(Equal(ModeChecksum))
+		})
+
+		It("rejects anything else", func() {
+			_, err := ParseMode("md5")
+			Expect(err).To(MatchError(`invalid verification mode "md5", expected "rows" or "checksum"`))
+		})
+	})
+
+	Context("VerifyTables", func() {
+		It("compares the row count of every table", func() {
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1").AddRow("t2"))
+			recipientMock.ExpectQuery(listTablesQuery).WithArgs("service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1").AddRow("t2"))
+
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+			recipientMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `service_instance_db`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t2`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(5))
+			recipientMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `service_instance_db`.`t2`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(4))
+
+			results, err := VerifyTables(donorDB, recipientDB, []string{"foo"}, discovery.Filter{}, renamed, ModeRowCount)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]TableResult{
+				{Schema: "foo", Table: "t1", RecipientSchema: "service_instance_db", DonorRows: 3, RecipientRows: 3},
+				{Schema: "foo", Table: "t2", RecipientSchema: "service_instance_db", DonorRows: 5, RecipientRows: 4},
+			}))
+			Expect(results[0].Matches()).To(BeTrue())
+			Expect(results[1].Matches()).To(BeFalse())
+		})
+
+		It("compares table checksums in checksum mode", func() {
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			recipientMock.ExpectQuery(listTablesQuery).WithArgs("service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+			donorMock.ExpectQuery(regexp.QuoteMeta("CHECKSUM TABLE `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"Table", "Checksum"}).AddRow("foo.t1", "1234"))
+			recipientMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `service_instance_db`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+			recipientMock.ExpectQuery(regexp.QuoteMeta("CHECKSUM TABLE `service_instance_db`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"Table", "Checksum"}).AddRow("service_instance_db.t1", "5678"))
+
+			results, err := VerifyTables(donorDB, recipientDB, []string{"foo"}, discovery.Filter{}, renamed, ModeChecksum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(HaveLen(1))
+			Expect(results[0].DonorChecksum).To(Equal(sql.NullString{String: "1234", Valid: true}))
+			Expect(results[0].RecipientChecksum).To(Equal(sql.NullString{String: "5678", Valid: true}))
+			Expect(results[0].Matches()).To(BeFalse())
+		})
+
+		It("reports tables missing on the recipient", func() {
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			recipientMock.ExpectQuery(listTablesQuery).WithArgs("service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}))
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+
+			results, err := VerifyTables(donorDB, recipientDB, []string{"foo"}, discovery.Filter{}, renamed, ModeRowCount)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]TableResult{
+				{Schema: "foo", Table: "t1", RecipientSchema: "service_instance_db", DonorRows: 3, MissingOnRecipient: true},
+			}))
+			Expect(results[0].Matches()).To(BeFalse())
+		})
+
+		It("skips tables excluded from the migration", func() {
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("audit_log").AddRow("t1"))
+			recipientMock.ExpectQuery(listTablesQuery).WithArgs("service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+			recipientMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `service_instance_db`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+
+			filter := discovery.Filter{ExcludeTables: []string{"foo.audit_*"}}
+			results, err := VerifyTables(donorDB, recipientDB, []string{"foo"}, filter, renamed, ModeRowCount)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]TableResult{
+				{Schema: "foo", Table: "t1", RecipientSchema: "service_instance_db", DonorRows: 3, RecipientRows: 3},
+			}))
+		})
+
+		It("returns an error when a table can not be inspected", func() {
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			recipientMock.ExpectQuery(listTablesQuery).WithArgs("service_instance_db").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnError(errors.New("some database error"))
+
+			_, err := VerifyTables(donorDB, recipientDB, []string{"foo"}, discovery.Filter{}, renamed, ModeRowCount)
+			Expect(err).To(MatchError("failed to inspect donor table foo.t1: failed to count rows: some database error"))
+		})
+
+		It("returns an error when the recipient tables can not be listed", func() {
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			recipientMock.ExpectQuery(listTablesQuery).WithArgs("service_instance_db").
+				WillReturnError(errors.New("some database error"))
+
+			_, err := VerifyTables(donorDB, recipientDB, []string{"foo"}, discovery.Filter{}, renamed, ModeRowCount)
+			Expect(err).To(MatchError(ContainSubstring("failed to list recipient tables: ")))
+		})
+	})
+
+	Context("Fingerprint", func() {
+		expectSchema := func(checksum string) {
+			donorMock.ExpectQuery(`SHOW DATABASES`).
+				WillReturnRows(sqlmock.NewRows([]string{"Database"}).AddRow("mysql").AddRow("foo"))
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"COUNT(*)"}).AddRow(3))
+			donorMock.ExpectQuery(regexp.QuoteMeta("CHECKSUM TABLE `foo`.`t1`")).
+				WillReturnRows(sqlmock.NewRows([]string{"Table", "Checksum"}).AddRow("foo.t1", checksum))
+		}
+
+		It("digests the tables of the user schemas", func() {
+			expectSchema("12")
+			first, err := Fingerprint(donorDB)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(first).To(HaveLen(64))
+
+			expectSchema("12")
+			Expect(Fingerprint(donorDB)).To(Equal(first))
+
+			expectSchema("13")
+			Expect(Fingerprint(donorDB)).NotTo(Equal(first))
+		})
+
+		It("returns an error when a table can not be inspected", func() {
+			donorMock.ExpectQuery(`SHOW DATABASES`).
+				WillReturnRows(sqlmock.NewRows([]string{"Database"}).AddRow("foo"))
+			donorMock.ExpectQuery(listTablesQuery).WithArgs("foo").
+				WillReturnRows(sqlmock.NewRows([]string{"TABLE_NAME"}).AddRow("t1"))
+			donorMock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `foo`.`t1`")).
+				WillReturnError(errors.New("some database error"))
+
+			_, err := Fingerprint(donorDB)
+			Expect(err).To(MatchError("failed to inspect table foo.t1: failed to count rows: some database error"))
+		})
+	})
+
+	Context("WriteReport", func() {
+		It("lists every mismatched table and returns the number of mismatches", func() {
+			var out bytes.Buffer
+			mismatches := WriteReport(&out, []TableResult{
+				{Schema: "foo", Table: "ok", RecipientSchema: "foo", DonorRows: 1, RecipientRows: 1},
+				{Schema: "foo", Table: "rows", RecipientSchema: "foo", DonorRows: 2, RecipientRows: 1},
+				{
+					Schema: "foo", Table: "checksum", RecipientSchema: "foo", DonorRows: 1, RecipientRows: 1,
+					DonorChecksum:     sql.NullString{String: "12", Valid: true},
+					RecipientChecksum: sql.NullString{},
+				},
+				{Schema: "foo", Table: "gone", RecipientSchema: "foo", DonorRows: 1, MissingOnRecipient: true},
+			})
+
+			Expect(mismatches).To(Equal(3))
+			Expect(out.String()).To(Equal(`Data verification report:
+  MISMATCH foo.rows: rows 2 (donor) != 1 (recipient)
+  MISMATCH foo.checksum: checksum 12 (donor) != NULL (recipient)
+  MISSING  foo.gone: table not found in recipient schema foo
+  Verified 4 tables, 3 mismatched
+`))
+		})
+	})
+})